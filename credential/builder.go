@@ -3,6 +3,7 @@ package credential
 import (
 	"fmt"
 	"reflect"
+	"strings"
 
 	"github.com/google/uuid"
 
@@ -13,6 +14,7 @@ import (
 
 const (
 	VerifiableCredentialsLinkedDataContext string = "https://www.w3.org/2018/credentials/v1"
+	VerifiableCredentialsV2Context         string = "https://www.w3.org/ns/credentials/v2"
 	VerifiableCredentialType               string = "VerifiableCredential"
 	VerifiableCredentialIDProperty         string = "id"
 	VerifiablePresentationType             string = "VerifiablePresentation"
@@ -51,6 +53,10 @@ func (vcb *VerifiableCredentialBuilder) Build() (*VerifiableCredential, error) {
 		return nil, errors.New(BuilderEmptyError)
 	}
 
+	if problems := vcb.validateRequiredFields(); len(problems) > 0 {
+		return nil, fmt.Errorf("credential not ready to be built: %d problem(s): %s", len(problems), strings.Join(problems, "; "))
+	}
+
 	if err := vcb.VerifiableCredential.IsValid(); err != nil {
 		return nil, errors.Wrap(err, "credential not ready to be built")
 	}
@@ -58,6 +64,40 @@ func (vcb *VerifiableCredentialBuilder) Build() (*VerifiableCredential, error) {
 	return vcb.VerifiableCredential, nil
 }
 
+// validateRequiredFields returns a description of every required field of the credential that is missing or
+// invalid, so all of them can be reported at once
+func (vcb *VerifiableCredentialBuilder) validateRequiredFields() []string {
+	var problems []string
+	contexts, err := util.InterfaceToStrings(vcb.Context)
+	if err != nil || !(util.Contains(VerifiableCredentialsLinkedDataContext, contexts) || util.Contains(VerifiableCredentialsV2Context, contexts)) {
+		problems = append(problems, fmt.Sprintf("@context must contain %s or %s", VerifiableCredentialsLinkedDataContext, VerifiableCredentialsV2Context))
+	}
+	if types, err := util.InterfaceToStrings(vcb.Type); err != nil || !util.Contains(VerifiableCredentialType, types) {
+		problems = append(problems, fmt.Sprintf("type must contain %s", VerifiableCredentialType))
+	}
+	if vcb.Issuer == nil || vcb.Issuer == "" {
+		problems = append(problems, "issuer is required")
+	}
+	if vcb.IssuanceDate == "" && !vcb.IsVersion2() {
+		problems = append(problems, "issuanceDate is required")
+	}
+	dates := []struct{ property, value string }{
+		{"issuanceDate", vcb.IssuanceDate},
+		{"expirationDate", vcb.ExpirationDate},
+		{"validFrom", vcb.ValidFrom},
+		{"validUntil", vcb.ValidUntil},
+	}
+	for _, date := range dates {
+		if date.value != "" && !util.IsRFC3339Timestamp(date.value) {
+			problems = append(problems, fmt.Sprintf("%s must be an RFC 3339 timestamp: %s", date.property, date.value))
+		}
+	}
+	if len(vcb.CredentialSubject) == 0 {
+		problems = append(problems, "credentialSubject is required")
+	}
+	return problems
+}
+
 func (vcb *VerifiableCredentialBuilder) IsEmpty() bool {
 	if vcb == nil || vcb.VerifiableCredential == nil {
 		return true
@@ -251,6 +251,72 @@ func TestCredentialBuilder(t *testing.T) {
 	assert.Equal(t, terms, cred.TermsOfUse)
 }
 
+func TestCredentialBuilderValidation(t *testing.T) {
+	t.Run("every missing field is reported", func(tt *testing.T) {
+		builder := NewVerifiableCredentialBuilder()
+		builder.IssuanceDate = ""
+		_, err := builder.Build()
+		assert.Error(tt, err)
+		assert.Contains(tt, err.Error(), "credential not ready to be built: 3 problem(s)")
+		assert.Contains(tt, err.Error(), "issuer is required")
+		assert.Contains(tt, err.Error(), "issuanceDate is required")
+		assert.Contains(tt, err.Error(), "credentialSubject is required")
+	})
+
+	t.Run("base context and type are required", func(tt *testing.T) {
+		builder := NewVerifiableCredentialBuilder()
+		assert.NoError(tt, builder.SetIssuer("did:example:issuer"))
+		assert.NoError(tt, builder.SetCredentialSubject(map[string]any{"id": "did:example:subject"}))
+		builder.Context = []string{"https://www.w3.org/2018/credentials/examples/v1"}
+		builder.Type = "AlumniCredential"
+		_, err := builder.Build()
+		assert.Error(tt, err)
+		assert.Contains(tt, err.Error(), "2 problem(s)")
+		assert.Contains(tt, err.Error(), "@context must contain https://www.w3.org/2018/credentials/v1")
+		assert.Contains(tt, err.Error(), "type must contain VerifiableCredential")
+	})
+
+	t.Run("vc 2.0 credentials", func(tt *testing.T) {
+		builder := NewVerifiableCredentialBuilder()
+		assert.NoError(tt, builder.SetIssuer(map[string]any{"id": "did:example:issuer", "name": "Issuer"}))
+		assert.NoError(tt, builder.SetCredentialSubject(map[string]any{"id": "did:example:subject"}))
+		builder.Context = []string{VerifiableCredentialsV2Context}
+		builder.IssuanceDate = ""
+		builder.ValidUntil = "2030"
+		_, err := builder.Build()
+		assert.Error(tt, err)
+		assert.Contains(tt, err.Error(), "1 problem(s): validUntil must be an RFC 3339 timestamp: 2030")
+
+		builder.ValidFrom = "2010-01-01T19:23:24Z"
+		builder.ValidUntil = "2030-01-01T19:23:24Z"
+		cred, err := builder.Build()
+		assert.NoError(tt, err)
+		assert.True(tt, cred.IsVersion2())
+		issuerID, err := cred.IssuerID()
+		assert.NoError(tt, err)
+		assert.Equal(tt, "did:example:issuer", issuerID)
+	})
+
+	t.Run("dates must be RFC 3339", func(tt *testing.T) {
+		builder := NewVerifiableCredentialBuilder()
+		assert.NoError(tt, builder.SetIssuer("did:example:issuer"))
+		assert.NoError(tt, builder.SetCredentialSubject(map[string]any{"id": "did:example:subject"}))
+		builder.IssuanceDate = "01/01/2010"
+		builder.ExpirationDate = "2030-01-01"
+		_, err := builder.Build()
+		assert.Error(tt, err)
+		assert.Contains(tt, err.Error(), "issuanceDate must be an RFC 3339 timestamp: 01/01/2010")
+		assert.Contains(tt, err.Error(), "expirationDate must be an RFC 3339 timestamp: 2030-01-01")
+
+		assert.Error(tt, builder.SetExpirationDate("2030-01-01"))
+		assert.NoError(tt, builder.SetIssuanceDate("2010-01-01T19:23:24Z"))
+		assert.NoError(tt, builder.SetExpirationDate("2030-01-01T19:23:24Z"))
+		cred, err := builder.Build()
+		assert.NoError(tt, err)
+		assert.Equal(tt, "2030-01-01T19:23:24Z", cred.ExpirationDate)
+	})
+}
+
 func TestVerifiablePresentationBuilder(t *testing.T) {
 	badBuilder := VerifiablePresentationBuilder{}
 	_, err := badBuilder.Build()
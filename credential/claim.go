@@ -0,0 +1,90 @@
+package credential
+
+import (
+	"strings"
+
+	"github.com/oliveagle/jsonpath"
+	"github.com/pkg/errors"
+)
+
+var (
+	// ErrNoMatch is returned when a JSONPath expression selects no value of a credential
+	ErrNoMatch = errors.New("no value matches the path")
+	// ErrMultipleMatches is returned when a JSONPath expression selects more than one value of a credential
+	ErrMultipleMatches = errors.New("multiple values match the path")
+)
+
+// GetClaim evaluates a JSONPath expression, such as $.credentialSubject.degree.type, against the credential and
+// returns the value it selects. It returns ErrNoMatch if the path selects no value, and ErrMultipleMatches if the
+// path is ambiguous, selecting more than one value.
+func (v *VerifiableCredential) GetClaim(path string) (any, error) {
+	credJSON, err := ToCredentialJSONMap(v)
+	if err != nil {
+		return nil, errors.Wrap(err, "getting credential as json")
+	}
+	return LookupClaim(credJSON, path)
+}
+
+// LookupClaim evaluates a JSONPath expression against the JSON representation of a credential, as GetClaim does.
+// A path selects more than one value when it has a wildcard, range, union, or filter selector, or when it selects
+// a property of each element of an array, as a path below the credentialSubject of a credential with multiple
+// subjects does. Such a path is only unambiguous if exactly one value matches it.
+func LookupClaim(credJSON map[string]any, path string) (any, error) {
+	compiled, err := jsonpath.Compile(path)
+	if err != nil {
+		return nil, errors.Wrapf(err, "compiling path: %s", path)
+	}
+	value, err := compiled.Lookup(credJSON)
+	if err != nil {
+		return nil, errors.Wrapf(ErrNoMatch, "%s: %s", path, err)
+	}
+	if !selectsMultiple(credJSON, path) {
+		return value, nil
+	}
+	matches, ok := value.([]any)
+	if !ok {
+		return value, nil
+	}
+	switch len(matches) {
+	case 0:
+		return nil, errors.Wrap(ErrNoMatch, path)
+	case 1:
+		return matches[0], nil
+	default:
+		return nil, errors.Wrapf(ErrMultipleMatches, "%d values match %s", len(matches), path)
+	}
+}
+
+// selectsMultiple determines whether a path, which has been compiled, evaluates to the list of values it matches
+// rather than to a single value
+func selectsMultiple(credJSON map[string]any, path string) bool {
+	depth, selectorStart := 0, 0
+	for i, c := range path {
+		switch c {
+		case '[':
+			if depth == 0 {
+				selectorStart = i + 1
+			}
+			depth++
+		case ']':
+			depth--
+			if depth == 0 {
+				selector := strings.TrimSpace(path[selectorStart:i])
+				if selector == "*" || strings.ContainsAny(selector, "?:,") {
+					return true
+				}
+			}
+		case '.':
+			// a property step applied to an array is applied to each of its elements
+			if depth > 0 || i < 2 {
+				continue
+			}
+			if parent, err := jsonpath.JsonPathLookup(credJSON, path[:i]); err == nil {
+				if _, ok := parent.([]any); ok {
+					return true
+				}
+			}
+		}
+	}
+	return false
+}
@@ -0,0 +1,95 @@
+package credential
+
+import (
+	"testing"
+
+	"github.com/goccy/go-json"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGetClaim(t *testing.T) {
+	gotTestVector, err := getTestVector(VCTestVector2)
+	require.NoError(t, err)
+	var vc VerifiableCredential
+	require.NoError(t, json.Unmarshal([]byte(gotTestVector), &vc))
+
+	t.Run("selects a single value", func(tt *testing.T) {
+		degreeType, err := vc.GetClaim("$.credentialSubject.degree.type")
+		assert.NoError(tt, err)
+		assert.Equal(tt, "BachelorDegree", degreeType)
+
+		degree, err := vc.GetClaim("$.credentialSubject.degree")
+		assert.NoError(tt, err)
+		assert.Equal(tt, map[string]any{"type": "BachelorDegree", "name": "Bachelor of Science and Arts"}, degree)
+
+		// an array valued claim is a single value
+		types, err := vc.GetClaim("$.type")
+		assert.NoError(tt, err)
+		assert.Equal(tt, []any{"VerifiableCredential", "UniversityDegreeCredential"}, types)
+
+		credType, err := vc.GetClaim("$.type[1]")
+		assert.NoError(tt, err)
+		assert.Equal(tt, "UniversityDegreeCredential", credType)
+
+		issuer, err := vc.GetClaim("$.issuer")
+		assert.NoError(tt, err)
+		assert.Equal(tt, "https://example.edu", issuer)
+	})
+
+	t.Run("missing path", func(tt *testing.T) {
+		_, err := vc.GetClaim("$.credentialSubject.degree.major")
+		assert.ErrorIs(tt, err, ErrNoMatch)
+
+		_, err = vc.GetClaim("$.credentialSubject.alumniOf.name")
+		assert.ErrorIs(tt, err, ErrNoMatch)
+
+		_, err = vc.GetClaim("$.type[2]")
+		assert.ErrorIs(tt, err, ErrNoMatch)
+	})
+
+	t.Run("ambiguous path", func(tt *testing.T) {
+		_, err := vc.GetClaim("$.type[*]")
+		assert.ErrorIs(tt, err, ErrMultipleMatches)
+
+		_, err = vc.GetClaim("$.type[0,1]")
+		assert.ErrorIs(tt, err, ErrMultipleMatches)
+	})
+
+	t.Run("multiple subjects", func(tt *testing.T) {
+		multi := VerifiableCredential{
+			Context:           []any{VerifiableCredentialsLinkedDataContext},
+			Type:              []any{VerifiableCredentialType},
+			Issuer:            "did:example:issuer",
+			CredentialSubject: CredentialSubject{"id": "did:example:alice", "name": "Alice", "spouse": "did:example:bob"},
+			AdditionalSubjects: []CredentialSubject{
+				{"id": "did:example:bob", "name": "Bob"},
+			},
+		}
+		_, err := multi.GetClaim("$.credentialSubject.name")
+		assert.ErrorIs(tt, err, ErrMultipleMatches)
+
+		name, err := multi.GetClaim("$.credentialSubject[1].name")
+		assert.NoError(tt, err)
+		assert.Equal(tt, "Bob", name)
+
+		// only one of the subjects has the property
+		spouse, err := multi.GetClaim("$.credentialSubject.spouse")
+		assert.NoError(tt, err)
+		assert.Equal(tt, "did:example:bob", spouse)
+
+		// a filter matching a single subject selects it
+		bob, err := multi.GetClaim("$.credentialSubject[?(@.name == 'Bob')]")
+		assert.NoError(tt, err)
+		assert.Equal(tt, map[string]any{"id": "did:example:bob", "name": "Bob"}, bob)
+
+		_, err = multi.GetClaim("$.credentialSubject[?(@.name == 'Carol')]")
+		assert.ErrorIs(tt, err, ErrNoMatch)
+	})
+
+	t.Run("invalid path", func(tt *testing.T) {
+		_, err := vc.GetClaim("credentialSubject.degree")
+		assert.Error(tt, err)
+		assert.NotErrorIs(tt, err, ErrNoMatch)
+	})
+}
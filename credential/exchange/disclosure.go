@@ -0,0 +1,128 @@
+package exchange
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/pkg/errors"
+
+	"github.com/TBD54566975/ssi-sdk/credential"
+)
+
+const credentialSubjectPathPrefix = "$.credentialSubject"
+
+// ApplyLimitDisclosure reduces the credentialSubject of a credential to the id of the subject and the properties
+// referenced by the fields of an input descriptor, when its constraints limit disclosure. For each field, the first of
+// its paths that resolves on the credential is disclosed; paths outside the credentialSubject are left as they are.
+// When limit_disclosure is required, an error is returned if the credential cannot be reduced, such as when a
+// non-optional field does not resolve or uses a path other than a chain of property names in dot notation. When it
+// is preferred, the credential is returned unchanged in those cases. Otherwise, the credential is always returned
+// unchanged.
+// The reduced credential has no proof, as the proof of the original credential does not cover the reduced subject,
+// and no subjects beyond the first.
+// https://identity.foundation/presentation-exchange/spec/v2.0.0/#limited-disclosure-submissions
+func ApplyLimitDisclosure(cred credential.VerifiableCredential, descriptor InputDescriptor) (credential.VerifiableCredential, error) {
+	if descriptor.Constraints == nil || descriptor.Constraints.LimitDisclosure == nil {
+		return cred, nil
+	}
+	switch *descriptor.Constraints.LimitDisclosure {
+	case Required:
+		limited, err := limitDisclosure(cred, descriptor)
+		if err != nil {
+			return cred, errors.Wrapf(err, "limiting disclosure of credential<%s> for input descriptor<%s>", cred.ID, descriptor.ID)
+		}
+		return *limited, nil
+	case Preferred:
+		if limited, err := limitDisclosure(cred, descriptor); err == nil {
+			return *limited, nil
+		}
+		return cred, nil
+	default:
+		return cred, nil
+	}
+}
+
+// limitDisclosure builds a copy of the credential with only the credentialSubject properties a descriptor references
+func limitDisclosure(cred credential.VerifiableCredential, descriptor InputDescriptor) (*credential.VerifiableCredential, error) {
+	credJSON, err := credential.ToCredentialJSONMap(cred)
+	if err != nil {
+		return nil, errors.Wrap(err, "getting credential as json")
+	}
+
+	subject := make(credential.CredentialSubject)
+	if id, ok := cred.CredentialSubject[credential.VerifiableCredentialIDProperty]; ok {
+		subject[credential.VerifiableCredentialIDProperty] = id
+	}
+	for _, field := range descriptor.Constraints.Fields {
+		path, value, ok := resolveFieldPath(field, credJSON)
+		if !ok {
+			if field.Optional {
+				continue
+			}
+			return nil, fmt.Errorf("no path of field<%s> resolves on the credential", strings.Join(field.Path, ", "))
+		}
+		if path != credentialSubjectPathPrefix && !strings.HasPrefix(path, credentialSubjectPathPrefix+".") &&
+			!strings.HasPrefix(path, credentialSubjectPathPrefix+"[") {
+			// the path is outside the credentialSubject
+			continue
+		}
+		properties, err := subjectPathProperties(path)
+		if err != nil {
+			return nil, err
+		}
+		if len(properties) == 0 {
+			return nil, errors.New("field references the whole credentialSubject, which cannot be limited")
+		}
+		setNestedProperty(subject, properties, value)
+	}
+
+	limited := cred
+	limited.CredentialSubject = subject
+	limited.AdditionalSubjects = nil
+	limited.Proof = nil
+	return &limited, nil
+}
+
+// resolveFieldPath returns the first of a field's paths which resolves on a credential, with its value
+func resolveFieldPath(field Field, credJSON map[string]any) (string, any, bool) {
+	for _, path := range field.Path {
+		if value, err := credential.LookupClaim(credJSON, path); err == nil {
+			return path, value, true
+		}
+	}
+	return "", nil, false
+}
+
+// subjectPathProperties splits a JSONPath below the credentialSubject into the names of the properties it selects,
+// supporting only dot notation, as array indices and wildcards cannot be reduced to a subset of the subject
+func subjectPathProperties(path string) ([]string, error) {
+	rest := strings.TrimPrefix(path, credentialSubjectPathPrefix)
+	if rest == "" {
+		return nil, nil
+	}
+	if strings.ContainsAny(rest, "[]*") || !strings.HasPrefix(rest, ".") {
+		return nil, fmt.Errorf("unsupported path for limited disclosure: %s", path)
+	}
+	properties := strings.Split(rest[1:], ".")
+	for _, property := range properties {
+		if property == "" {
+			return nil, fmt.Errorf("unsupported path for limited disclosure: %s", path)
+		}
+	}
+	return properties, nil
+}
+
+// setNestedProperty sets a value in a JSON object at the given chain of property names, creating any objects on the
+// way that do not already exist
+func setNestedProperty(object map[string]any, properties []string, value any) {
+	curr := object
+	for _, property := range properties[:len(properties)-1] {
+		next, ok := curr[property].(map[string]any)
+		if !ok {
+			next = make(map[string]any)
+			curr[property] = next
+		}
+		curr = next
+	}
+	curr[properties[len(properties)-1]] = value
+}
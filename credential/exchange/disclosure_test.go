@@ -0,0 +1,92 @@
+package exchange
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/TBD54566975/ssi-sdk/credential"
+	"github.com/TBD54566975/ssi-sdk/crypto"
+)
+
+func TestApplyLimitDisclosure(t *testing.T) {
+	getCred := func() credential.VerifiableCredential {
+		cred := getTestVerifiableCredential("did:example:issuer", "did:example:subject")
+		cred.CredentialSubject["address"] = map[string]any{
+			"city":    "Minneapolis",
+			"country": "US",
+		}
+		cred.CredentialSubject["date_of_birth"] = "1970-01-01"
+		cred.CredentialSubject["languages"] = []any{"en", "fr"}
+		proof := crypto.Proof(map[string]any{"type": "JsonWebSignature2020"})
+		cred.Proof = &proof
+		return cred
+	}
+	getDescriptor := func(limitDisclosure *Preference, fields ...Field) InputDescriptor {
+		return InputDescriptor{
+			ID: "test-descriptor",
+			Constraints: &Constraints{
+				Fields:          fields,
+				LimitDisclosure: limitDisclosure,
+			},
+		}
+	}
+	fields := []Field{
+		{Path: []string{"$.credentialSubject.employer", "$.credentialSubject.company"}},
+		{Path: []string{"$.credentialSubject.address.city"}},
+		{Path: []string{"$.credentialSubject.date_of_birth"}},
+		{Path: []string{"$.credentialSubject.phone"}, Optional: true},
+		{Path: []string{"$.issuer"}},
+	}
+
+	t.Run("required limits the subject to referenced claims", func(tt *testing.T) {
+		cred := getCred()
+		limited, err := ApplyLimitDisclosure(cred, getDescriptor(Required.Ptr(), fields...))
+		require.NoError(tt, err)
+
+		assert.Equal(tt, credential.CredentialSubject{
+			"id":            "did:example:subject",
+			"company":       "Block",
+			"address":       map[string]any{"city": "Minneapolis"},
+			"date_of_birth": "1970-01-01",
+		}, limited.CredentialSubject)
+		assert.NotContains(tt, limited.CredentialSubject, "website")
+		assert.NotContains(tt, limited.CredentialSubject, "languages")
+		assert.Nil(tt, limited.Proof)
+		assert.Equal(tt, cred.Issuer, limited.Issuer)
+
+		// the original credential is unchanged
+		assert.Equal(tt, getCred(), cred)
+	})
+
+	t.Run("required fails when the credential cannot be limited", func(tt *testing.T) {
+		_, err := ApplyLimitDisclosure(getCred(), getDescriptor(Required.Ptr(), Field{Path: []string{"$.credentialSubject.phone"}}))
+		assert.ErrorContains(tt, err, "no path of field<$.credentialSubject.phone> resolves on the credential")
+
+		_, err = ApplyLimitDisclosure(getCred(), getDescriptor(Required.Ptr(), Field{Path: []string{"$.credentialSubject.languages[0]"}}))
+		assert.ErrorContains(tt, err, "unsupported path for limited disclosure: $.credentialSubject.languages[0]")
+	})
+
+	t.Run("preferred attempts without failing", func(tt *testing.T) {
+		limited, err := ApplyLimitDisclosure(getCred(), getDescriptor(Preferred.Ptr(), fields...))
+		require.NoError(tt, err)
+		assert.NotContains(tt, limited.CredentialSubject, "website")
+
+		cred := getCred()
+		unchanged, err := ApplyLimitDisclosure(cred, getDescriptor(Preferred.Ptr(), Field{Path: []string{"$.credentialSubject.phone"}}))
+		assert.NoError(tt, err)
+		assert.Equal(tt, cred, unchanged)
+	})
+
+	t.Run("no limit disclosure", func(tt *testing.T) {
+		cred := getCred()
+		unchanged, err := ApplyLimitDisclosure(cred, getDescriptor(nil, fields...))
+		assert.NoError(tt, err)
+		assert.Equal(tt, cred, unchanged)
+
+		unchanged, err = ApplyLimitDisclosure(cred, InputDescriptor{ID: "test-descriptor"})
+		assert.NoError(tt, err)
+		assert.Equal(tt, cred, unchanged)
+	})
+}
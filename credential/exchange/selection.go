@@ -0,0 +1,424 @@
+package exchange
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+
+	"github.com/google/uuid"
+	"github.com/oliveagle/jsonpath"
+	"github.com/pkg/errors"
+
+	"github.com/TBD54566975/ssi-sdk/credential"
+	"github.com/TBD54566975/ssi-sdk/cryptosuite"
+	"github.com/TBD54566975/ssi-sdk/schema"
+	"github.com/TBD54566975/ssi-sdk/util"
+)
+
+// ErrPredicateUnsupported is returned when an input descriptor requires a predicate which the format of the
+// credentials that would otherwise satisfy it cannot support
+var ErrPredicateUnsupported = errors.New("predicate not supported by the credential format")
+
+// SubmissionRequirementsResult is the result of selecting the held credentials that satisfy a presentation definition
+type SubmissionRequirementsResult struct {
+	// Matches holds, for each input descriptor of the definition, the credentials satisfying it. The values of
+	// fields with a predicate are replaced by true, so the credentials are ready to be presented.
+	Matches map[string][]credential.VerifiableCredential
+	// Selected holds the ids of the input descriptors a submission is to fulfill, in the order of the definition.
+	// Absent submission requirements, these are all input descriptors of the definition.
+	Selected []string
+}
+
+// SelectCredentials determines which of the given credentials satisfy each of a presentation definition's input
+// descriptors, and which input descriptors fulfill the definition's submission requirements. A credential satisfies
+// an input descriptor when, for each of its fields that is not optional, one of the field's paths resolves on the
+// credential to a value that passes the field's filter, if any.
+// A field with a predicate must have a filter, and in the matching credential the value of the field is replaced by
+// true, proving the value passes the filter without revealing it. Only credentials with a BBS+ proof, from which a
+// derived proof can be created, can support predicates; others cannot satisfy a required predicate, and present the
+// value itself for a preferred one.
+// An error is returned when the definition cannot be fulfilled by the given credentials, wrapping
+// ErrPredicateUnsupported if an input descriptor was left unsatisfied because of its predicates.
+// https://identity.foundation/presentation-exchange/spec/v2.0.0/#input-evaluation
+// https://identity.foundation/presentation-exchange/spec/v2.0.0/#submission-requirement-rules
+func SelectCredentials(def PresentationDefinition, creds []credential.VerifiableCredential) (*SubmissionRequirementsResult, error) {
+	if def.IsEmpty() {
+		return nil, errors.New("presentation definition cannot be empty")
+	}
+
+	credJSONs := make([]map[string]any, 0, len(creds))
+	for _, cred := range creds {
+		credJSON, err := credential.ToCredentialJSONMap(cred)
+		if err != nil {
+			return nil, errors.Wrapf(err, "getting credential<%s> as json", cred.ID)
+		}
+		credJSONs = append(credJSONs, credJSON)
+	}
+
+	matches := make(map[string][]credential.VerifiableCredential, len(def.InputDescriptors))
+	// input descriptors with credentials that would satisfy them, but for the predicates they require
+	predicateUnsupported := make(map[string]bool)
+	for _, inputDescriptor := range def.InputDescriptors {
+		var matched []credential.VerifiableCredential
+		for i, credJSON := range credJSONs {
+			predicatePaths, satisfies, err := satisfiesInputDescriptor(inputDescriptor, credJSON)
+			if err != nil {
+				return nil, errors.Wrapf(err, "evaluating input descriptor<%s>", inputDescriptor.ID)
+			}
+			if !satisfies {
+				continue
+			}
+			cred := creds[i]
+			if len(predicatePaths.required)+len(predicatePaths.preferred) > 0 {
+				if supportsPredicates(cred) {
+					paths := append(predicatePaths.required, predicatePaths.preferred...)
+					if cred, err = applyPredicates(cred, paths); err != nil {
+						return nil, errors.Wrapf(err, "evaluating input descriptor<%s>", inputDescriptor.ID)
+					}
+				} else if len(predicatePaths.required) > 0 {
+					predicateUnsupported[inputDescriptor.ID] = true
+					continue
+				}
+			}
+			matched = append(matched, cred)
+		}
+		if len(matched) > 0 {
+			matches[inputDescriptor.ID] = matched
+		}
+	}
+	// wraps an error in fulfilling the definition with ErrPredicateUnsupported, if predicates left any input
+	// descriptor unsatisfied
+	predicateErr := func(err error) error {
+		for _, inputDescriptor := range def.InputDescriptors {
+			if _, ok := matches[inputDescriptor.ID]; !ok && predicateUnsupported[inputDescriptor.ID] {
+				return errors.Wrapf(ErrPredicateUnsupported, "%s; input descriptor<%s>", err.Error(), inputDescriptor.ID)
+			}
+		}
+		return err
+	}
+
+	result := SubmissionRequirementsResult{Matches: matches}
+	if len(def.SubmissionRequirements) == 0 {
+		for _, inputDescriptor := range def.InputDescriptors {
+			if _, ok := matches[inputDescriptor.ID]; !ok {
+				return nil, predicateErr(fmt.Errorf("no credentials satisfy input descriptor<%s>", inputDescriptor.ID))
+			}
+			result.Selected = append(result.Selected, inputDescriptor.ID)
+		}
+		return &result, nil
+	}
+
+	fulfilled := make(map[string]bool, len(matches))
+	for id := range matches {
+		fulfilled[id] = true
+	}
+	selected := make(map[string]bool)
+	for i, requirement := range def.SubmissionRequirements {
+		ids, err := evaluateSubmissionRequirement(requirement, def.InputDescriptors, fulfilled)
+		if err != nil {
+			return nil, predicateErr(errors.Wrapf(err, "submission requirement %d%s not fulfilled", i, describeRequirement(requirement)))
+		}
+		for _, id := range ids {
+			selected[id] = true
+		}
+	}
+	for _, inputDescriptor := range def.InputDescriptors {
+		if selected[inputDescriptor.ID] {
+			result.Selected = append(result.Selected, inputDescriptor.ID)
+		}
+	}
+	return &result, nil
+}
+
+// predicatePaths are the paths of the fields of an input descriptor with a predicate, which resolved on a credential
+type predicatePaths struct {
+	required  []string
+	preferred []string
+}
+
+// satisfiesInputDescriptor determines whether a credential satisfies each of the non-optional fields of an input
+// descriptor's constraints, returning the paths of the fields with a predicate
+func satisfiesInputDescriptor(inputDescriptor InputDescriptor, credJSON map[string]any) (*predicatePaths, bool, error) {
+	paths := new(predicatePaths)
+	if inputDescriptor.Constraints == nil {
+		return paths, true, nil
+	}
+	for _, field := range inputDescriptor.Constraints.Fields {
+		if field.Predicate != nil && field.Filter == nil {
+			return nil, false, fmt.Errorf("field<%s> has a predicate without a filter", strings.Join(field.Path, ", "))
+		}
+		path, satisfies, err := satisfiesField(field, credJSON)
+		if err != nil {
+			return nil, false, err
+		}
+		if !satisfies {
+			if field.Optional {
+				continue
+			}
+			return nil, false, nil
+		}
+		if field.Predicate != nil {
+			switch *field.Predicate {
+			case Required:
+				paths.required = append(paths.required, path)
+			case Preferred:
+				paths.preferred = append(paths.preferred, path)
+			}
+		}
+	}
+	return paths, true, nil
+}
+
+// satisfiesField returns the first of a field's paths that resolves on a credential to a value passing its filter
+func satisfiesField(field Field, credJSON map[string]any) (string, bool, error) {
+	var filterJSON string
+	if field.Filter != nil {
+		var err error
+		if filterJSON, err = field.Filter.ToJSON(); err != nil {
+			return "", false, errors.Wrap(err, "turning filter into JSON schema")
+		}
+	}
+	for _, path := range field.Path {
+		pathedData, err := jsonpath.JsonPathLookup(credJSON, path)
+		if err != nil {
+			continue
+		}
+		if filterJSON == "" || schema.IsAnyValidAgainstJSONSchema(pathedData, filterJSON) == nil {
+			return path, true, nil
+		}
+	}
+	return "", false, nil
+}
+
+// supportsPredicates determines whether a credential's format supports predicates, which requires a BBS+ proof from
+// which a derived proof of the credential with predicate values can be created
+func supportsPredicates(cred credential.VerifiableCredential) bool {
+	if cred.Proof == nil {
+		return false
+	}
+	proofs, err := util.InterfaceToInterfaceArray(*cred.Proof)
+	if err != nil {
+		return false
+	}
+	for _, proof := range proofs {
+		proofJSON, err := util.ToJSONMap(proof)
+		if err != nil {
+			continue
+		}
+		switch proofJSON["type"] {
+		case string(cryptosuite.BBSPlusSignature2020), string(cryptosuite.BBSPlusSignatureProof2020):
+			return true
+		}
+	}
+	return false
+}
+
+// applyPredicates returns a copy of a credential whose values at the given credentialSubject paths are replaced by
+// true, leaving the original credential unchanged
+func applyPredicates(cred credential.VerifiableCredential, paths []string) (credential.VerifiableCredential, error) {
+	subject := copyJSONObject(cred.CredentialSubject)
+	for _, path := range paths {
+		if !strings.HasPrefix(path, credentialSubjectPathPrefix+".") {
+			return cred, fmt.Errorf("unsupported path for a predicate: %s", path)
+		}
+		properties, err := subjectPathProperties(path)
+		if err != nil {
+			return cred, errors.Wrap(err, "applying predicate")
+		}
+		// copy the objects on the way to the value, so they are not shared with the original credential
+		curr := subject
+		for _, property := range properties[:len(properties)-1] {
+			next, ok := curr[property].(map[string]any)
+			if !ok {
+				return cred, fmt.Errorf("unsupported path for a predicate: %s", path)
+			}
+			next = copyJSONObject(next)
+			curr[property] = next
+			curr = next
+		}
+		curr[properties[len(properties)-1]] = true
+	}
+	cred.CredentialSubject = subject
+	return cred, nil
+}
+
+func copyJSONObject(object map[string]any) map[string]any {
+	copied := make(map[string]any, len(object))
+	for k, v := range object {
+		copied[k] = v
+	}
+	return copied
+}
+
+// FulfillsSubmissionRequirements determines whether fulfilling the input descriptors with the given ids fulfills the
+// submission requirements of a presentation definition. Without submission requirements, every input descriptor of
+// the definition must be fulfilled.
+func FulfillsSubmissionRequirements(def PresentationDefinition, fulfilledIDs []string) error {
+	fulfilled := make(map[string]bool, len(fulfilledIDs))
+	for _, id := range fulfilledIDs {
+		fulfilled[id] = true
+	}
+	if len(def.SubmissionRequirements) == 0 {
+		for _, inputDescriptor := range def.InputDescriptors {
+			if !fulfilled[inputDescriptor.ID] {
+				return fmt.Errorf("input descriptor<%s> not fulfilled", inputDescriptor.ID)
+			}
+		}
+		return nil
+	}
+	for i, requirement := range def.SubmissionRequirements {
+		if _, err := evaluateSubmissionRequirement(requirement, def.InputDescriptors, fulfilled); err != nil {
+			return errors.Wrapf(err, "submission requirement %d%s not fulfilled", i, describeRequirement(requirement))
+		}
+	}
+	return nil
+}
+
+// evaluateSubmissionRequirement returns the ids of the input descriptors selected to fulfill a submission
+// requirement, or an error if it cannot be fulfilled
+func evaluateSubmissionRequirement(requirement SubmissionRequirement, inputDescriptors []InputDescriptor, fulfilled map[string]bool) ([]string, error) {
+	// each option is an input descriptor of the from group, or a nested submission requirement, and holds the input
+	// descriptors it selects if it can be fulfilled
+	var options [][]string
+	var total int
+	switch {
+	case requirement.From != "" && len(requirement.FromNested) > 0:
+		return nil, errors.New("submission requirement cannot have both from and from_nested")
+	case requirement.From != "":
+		for _, inputDescriptor := range inputDescriptors {
+			if !util.Contains(requirement.From, inputDescriptor.Group) {
+				continue
+			}
+			total++
+			if fulfilled[inputDescriptor.ID] {
+				options = append(options, []string{inputDescriptor.ID})
+			}
+		}
+		if total == 0 {
+			return nil, fmt.Errorf("no input descriptors in group<%s>", requirement.From)
+		}
+	case len(requirement.FromNested) > 0:
+		for _, nested := range requirement.FromNested {
+			total++
+			if ids, err := evaluateSubmissionRequirement(nested, inputDescriptors, fulfilled); err == nil {
+				options = append(options, ids)
+			}
+		}
+	default:
+		return nil, errors.New("submission requirement must have from or from_nested")
+	}
+
+	var selected [][]string
+	switch requirement.Rule {
+	case All:
+		if len(options) != total {
+			return nil, fmt.Errorf("rule<%s> requires %d, but %d can be fulfilled", All, total, len(options))
+		}
+		selected = options
+	case Pick:
+		switch {
+		case requirement.Count > 0:
+			if len(options) < requirement.Count {
+				return nil, fmt.Errorf("rule<%s> requires %d, but %d can be fulfilled", Pick, requirement.Count, len(options))
+			}
+			selected = options[:requirement.Count]
+		default:
+			if len(options) < requirement.Minimum {
+				return nil, fmt.Errorf("rule<%s> requires at least %d, but %d can be fulfilled", Pick, requirement.Minimum, len(options))
+			}
+			selected = options
+			if requirement.Maximum > 0 && len(selected) > requirement.Maximum {
+				selected = selected[:requirement.Maximum]
+			}
+		}
+	default:
+		return nil, fmt.Errorf("unsupported submission requirement rule: %s", requirement.Rule)
+	}
+
+	var ids []string
+	for _, option := range selected {
+		ids = append(ids, option...)
+	}
+	return ids, nil
+}
+
+// describeRequirement names a submission requirement for error messages
+func describeRequirement(requirement SubmissionRequirement) string {
+	if requirement.Name != "" {
+		return " <" + requirement.Name + ">"
+	}
+	return ""
+}
+
+// BuildPresentationSubmissionFromSelection constructs a presentation submission for the input descriptors selected
+// in the result of SelectCredentials, along with the Verifiable Presentation it describes. Each selected input
+// descriptor is fulfilled by the first of its matching credentials, and an identical credential fulfilling more than
+// one input descriptor is included in the presentation once. Each entry of the descriptor_map points directly at the
+// credential's location in the presentation's verifiableCredential array, so no path_nested entries are needed.
+// https://identity.foundation/presentation-exchange/spec/v2.0.0/#presentation-submission
+func BuildPresentationSubmissionFromSelection(def PresentationDefinition, result SubmissionRequirementsResult) (*PresentationSubmission, *credential.VerifiablePresentation, error) {
+	if def.ID == "" {
+		return nil, nil, errors.New("presentation definition must have an id")
+	}
+	if len(result.Selected) == 0 {
+		return nil, nil, errors.New("no input descriptors selected; cannot build a presentation submission")
+	}
+	inputDescriptorIDs := make([]string, 0, len(def.InputDescriptors))
+	for _, inputDescriptor := range def.InputDescriptors {
+		inputDescriptorIDs = append(inputDescriptorIDs, inputDescriptor.ID)
+	}
+
+	builder := credential.NewVerifiablePresentationBuilder()
+	if err := builder.AddContext(PresentationSubmissionContext); err != nil {
+		return nil, nil, err
+	}
+	if err := builder.AddType(PresentationSubmissionType); err != nil {
+		return nil, nil, err
+	}
+
+	submission := PresentationSubmission{
+		ID:           uuid.NewString(),
+		DefinitionID: def.ID,
+	}
+	var included []credential.VerifiableCredential
+	for _, id := range result.Selected {
+		if !util.Contains(id, inputDescriptorIDs) {
+			return nil, nil, fmt.Errorf("selected input descriptor<%s> is not in presentation definition<%s>", id, def.ID)
+		}
+		matches := result.Matches[id]
+		if len(matches) == 0 {
+			return nil, nil, fmt.Errorf("selected input descriptor<%s> has no matching credentials", id)
+		}
+		cred := matches[0]
+
+		// a credential already in the presentation is referenced again rather than duplicated
+		index := -1
+		for i, includedCred := range included {
+			if reflect.DeepEqual(cred, includedCred) {
+				index = i
+				break
+			}
+		}
+		if index == -1 {
+			if err := builder.AddVerifiableCredentials(cred); err != nil {
+				return nil, nil, errors.Wrap(err, "could not add credential to verifiable presentation")
+			}
+			index = len(included)
+			included = append(included, cred)
+		}
+		submission.DescriptorMap = append(submission.DescriptorMap, SubmissionDescriptor{
+			ID:     id,
+			Format: LDPVC.String(),
+			Path:   fmt.Sprintf("$.verifiableCredential[%d]", index),
+		})
+	}
+
+	if err := builder.SetPresentationSubmission(submission); err != nil {
+		return nil, nil, err
+	}
+	vp, err := builder.Build()
+	if err != nil {
+		return nil, nil, errors.Wrap(err, "could not build verifiable presentation")
+	}
+	return &submission, vp, nil
+}
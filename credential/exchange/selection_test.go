@@ -0,0 +1,372 @@
+package exchange
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/oliveagle/jsonpath"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/TBD54566975/ssi-sdk/credential"
+	"github.com/TBD54566975/ssi-sdk/crypto"
+	"github.com/TBD54566975/ssi-sdk/cryptosuite"
+	"github.com/TBD54566975/ssi-sdk/util"
+)
+
+func TestSelectCredentials(t *testing.T) {
+	getCred := func(id string, subject map[string]any) credential.VerifiableCredential {
+		subject["id"] = "did:example:subject"
+		return credential.VerifiableCredential{
+			Context:           []any{"https://www.w3.org/2018/credentials/v1"},
+			ID:                id,
+			Type:              []string{"VerifiableCredential"},
+			Issuer:            "did:example:issuer",
+			IssuanceDate:      "2021-01-01T19:23:24Z",
+			CredentialSubject: subject,
+		}
+	}
+	emailCred := getCred("email-cred", map[string]any{"email": "first.last@example.com"})
+	badEmailCred := getCred("bad-email-cred", map[string]any{"email": "first.last"})
+	nameCred := getCred("name-cred", map[string]any{"name": "First Last"})
+	creds := []credential.VerifiableCredential{emailCred, badEmailCred, nameCred}
+
+	emailDescriptor := InputDescriptor{
+		ID: "email",
+		Constraints: &Constraints{
+			Fields: []Field{
+				{
+					Path:   []string{"$.credentialSubject.email", "$.credentialSubject.emailAddress"},
+					Filter: &Filter{Type: "string", Pattern: "^[^@]+@[^@]+$"},
+				},
+				{
+					Path:     []string{"$.credentialSubject.verified"},
+					Optional: true,
+				},
+			},
+		},
+		Group: []string{"A"},
+	}
+	phoneDescriptor := InputDescriptor{
+		ID: "phone",
+		Constraints: &Constraints{
+			Fields: []Field{{Path: []string{"$.credentialSubject.phone"}}},
+		},
+		Group: []string{"A"},
+	}
+	nameDescriptor := InputDescriptor{
+		ID: "name",
+		Constraints: &Constraints{
+			Fields: []Field{{Path: []string{"$.credentialSubject.name"}}},
+		},
+		Group: []string{"B"},
+	}
+
+	t.Run("pick 1 from group A", func(tt *testing.T) {
+		def := PresentationDefinition{
+			ID:               "test-definition",
+			InputDescriptors: []InputDescriptor{emailDescriptor, phoneDescriptor},
+			SubmissionRequirements: []SubmissionRequirement{
+				{Rule: Pick, Count: 1, FromOption: FromOption{From: "A"}},
+			},
+		}
+		result, err := SelectCredentials(def, creds)
+		require.NoError(tt, err)
+
+		// the optional field does not disqualify the credential, and the filter excludes the malformed email
+		assert.Equal(tt, map[string][]credential.VerifiableCredential{"email": {emailCred}}, result.Matches)
+		assert.Equal(tt, []string{"email"}, result.Selected)
+
+		// neither descriptor can be fulfilled
+		_, err = SelectCredentials(def, []credential.VerifiableCredential{badEmailCred, nameCred})
+		assert.ErrorContains(tt, err, "submission requirement 0 not fulfilled: rule<pick> requires 1, but 0 can be fulfilled")
+	})
+
+	t.Run("all from group A", func(tt *testing.T) {
+		def := PresentationDefinition{
+			ID:               "test-definition",
+			InputDescriptors: []InputDescriptor{emailDescriptor, phoneDescriptor},
+			SubmissionRequirements: []SubmissionRequirement{
+				{Name: "contact", Rule: All, FromOption: FromOption{From: "A"}},
+			},
+		}
+		_, err := SelectCredentials(def, creds)
+		assert.ErrorContains(tt, err, "submission requirement 0 <contact> not fulfilled: rule<all> requires 2, but 1 can be fulfilled")
+
+		phoneCred := getCred("phone-cred", map[string]any{"phone": "555-0100"})
+		result, err := SelectCredentials(def, append(creds, phoneCred))
+		require.NoError(tt, err)
+		assert.Equal(tt, []string{"email", "phone"}, result.Selected)
+		assert.Equal(tt, []credential.VerifiableCredential{phoneCred}, result.Matches["phone"])
+	})
+
+	t.Run("from nested", func(tt *testing.T) {
+		def := PresentationDefinition{
+			ID:               "test-definition",
+			InputDescriptors: []InputDescriptor{emailDescriptor, phoneDescriptor, nameDescriptor},
+			SubmissionRequirements: []SubmissionRequirement{
+				{
+					Rule:    Pick,
+					Minimum: 2,
+					FromOption: FromOption{FromNested: []SubmissionRequirement{
+						{Rule: Pick, Count: 1, FromOption: FromOption{From: "A"}},
+						{Rule: All, FromOption: FromOption{From: "B"}},
+					}},
+				},
+			},
+		}
+		result, err := SelectCredentials(def, creds)
+		require.NoError(tt, err)
+		assert.Equal(tt, []string{"email", "name"}, result.Selected)
+
+		_, err = SelectCredentials(def, []credential.VerifiableCredential{emailCred})
+		assert.ErrorContains(tt, err, "rule<pick> requires at least 2, but 1 can be fulfilled")
+
+		def.SubmissionRequirements[0].Maximum = 1
+		def.SubmissionRequirements[0].Minimum = 1
+		result, err = SelectCredentials(def, creds)
+		require.NoError(tt, err)
+		assert.Equal(tt, []string{"email"}, result.Selected)
+	})
+
+	t.Run("without submission requirements", func(tt *testing.T) {
+		def := PresentationDefinition{
+			ID:               "test-definition",
+			InputDescriptors: []InputDescriptor{emailDescriptor, nameDescriptor},
+		}
+		result, err := SelectCredentials(def, creds)
+		require.NoError(tt, err)
+		assert.Equal(tt, []string{"email", "name"}, result.Selected)
+		assert.Equal(tt, []credential.VerifiableCredential{nameCred}, result.Matches["name"])
+
+		def.InputDescriptors = append(def.InputDescriptors, phoneDescriptor)
+		_, err = SelectCredentials(def, creds)
+		assert.ErrorContains(tt, err, "no credentials satisfy input descriptor<phone>")
+	})
+
+	t.Run("invalid submission requirements", func(tt *testing.T) {
+		def := PresentationDefinition{
+			ID:               "test-definition",
+			InputDescriptors: []InputDescriptor{emailDescriptor},
+			SubmissionRequirements: []SubmissionRequirement{
+				{Rule: Pick, Count: 1, FromOption: FromOption{From: "C"}},
+			},
+		}
+		_, err := SelectCredentials(def, creds)
+		assert.ErrorContains(tt, err, "no input descriptors in group<C>")
+
+		def.SubmissionRequirements[0].From = ""
+		_, err = SelectCredentials(def, creds)
+		assert.ErrorContains(tt, err, "submission requirement must have from or from_nested")
+
+		_, err = SelectCredentials(PresentationDefinition{}, creds)
+		assert.ErrorContains(tt, err, "presentation definition cannot be empty")
+	})
+}
+
+func TestBuildPresentationSubmissionFromSelection(t *testing.T) {
+	emailCred := getTestVerifiableCredential("did:example:issuer", "did:example:subject")
+	emailCred.ID = "email-cred"
+	emailCred.CredentialSubject["email"] = "first.last@example.com"
+	nameCred := getTestVerifiableCredential("did:example:issuer", "did:example:subject")
+	nameCred.ID = "name-cred"
+	nameCred.CredentialSubject["name"] = "First Last"
+	creds := []credential.VerifiableCredential{nameCred, emailCred}
+
+	getDescriptor := func(id, path string) InputDescriptor {
+		return InputDescriptor{
+			ID:          id,
+			Constraints: &Constraints{Fields: []Field{{Path: []string{path}}}},
+		}
+	}
+	def := PresentationDefinition{
+		ID: "test-definition",
+		InputDescriptors: []InputDescriptor{
+			getDescriptor("email", "$.credentialSubject.email"),
+			getDescriptor("name", "$.credentialSubject.name"),
+			getDescriptor("company", "$.credentialSubject.company"),
+		},
+	}
+
+	t.Run("descriptor map paths resolve to the credentials", func(tt *testing.T) {
+		result, err := SelectCredentials(def, creds)
+		require.NoError(tt, err)
+
+		submission, vp, err := BuildPresentationSubmissionFromSelection(def, *result)
+		require.NoError(tt, err)
+		assert.Equal(tt, def.ID, submission.DefinitionID)
+		assert.NoError(tt, submission.IsValid())
+		assert.Equal(tt, *submission, vp.PresentationSubmission)
+
+		// the company descriptor is fulfilled by the name credential, which is not duplicated
+		require.Len(tt, vp.VerifiableCredential, 2)
+		require.Len(tt, submission.DescriptorMap, 3)
+
+		vpJSON, err := util.ToJSONMap(vp)
+		require.NoError(tt, err)
+		expected := map[string]string{"email": "email-cred", "name": "name-cred", "company": "name-cred"}
+		for _, descriptor := range submission.DescriptorMap {
+			assert.Equal(tt, LDPVC.String(), descriptor.Format)
+			assert.Nil(tt, descriptor.PathNested)
+			resolved, err := jsonpath.JsonPathLookup(vpJSON, descriptor.Path)
+			require.NoError(tt, err, descriptor.Path)
+			assert.Equal(tt, expected[descriptor.ID], resolved.(map[string]any)["id"], descriptor.ID)
+		}
+
+		// the presentation verifies against the definition
+		verified, err := VerifyPresentationSubmissionVP(def, *vp)
+		assert.NoError(tt, err)
+		assert.Len(tt, verified, 3)
+	})
+
+	t.Run("only selected descriptors are submitted", func(tt *testing.T) {
+		result := SubmissionRequirementsResult{
+			Matches:  map[string][]credential.VerifiableCredential{"email": {emailCred}, "name": {nameCred}},
+			Selected: []string{"email"},
+		}
+		submission, vp, err := BuildPresentationSubmissionFromSelection(def, result)
+		require.NoError(tt, err)
+		require.Len(tt, submission.DescriptorMap, 1)
+		assert.Equal(tt, "$.verifiableCredential[0]", submission.DescriptorMap[0].Path)
+		assert.Equal(tt, []any{emailCred}, vp.VerifiableCredential)
+	})
+
+	t.Run("invalid selection", func(tt *testing.T) {
+		_, _, err := BuildPresentationSubmissionFromSelection(def, SubmissionRequirementsResult{})
+		assert.ErrorContains(tt, err, "no input descriptors selected")
+
+		_, _, err = BuildPresentationSubmissionFromSelection(def, SubmissionRequirementsResult{Selected: []string{"phone"}})
+		assert.ErrorContains(tt, err, "selected input descriptor<phone> is not in presentation definition<test-definition>")
+
+		_, _, err = BuildPresentationSubmissionFromSelection(def, SubmissionRequirementsResult{Selected: []string{"email"}})
+		assert.ErrorContains(tt, err, "selected input descriptor<email> has no matching credentials")
+
+		_, _, err = BuildPresentationSubmissionFromSelection(PresentationDefinition{}, SubmissionRequirementsResult{Selected: []string{"email"}})
+		assert.ErrorContains(tt, err, "presentation definition must have an id")
+	})
+}
+
+func TestSelectCredentialsWithPredicates(t *testing.T) {
+	getCred := func(age any, proofType string) credential.VerifiableCredential {
+		cred := getTestVerifiableCredential("did:example:issuer", "did:example:subject")
+		cred.CredentialSubject["age"] = age
+		cred.CredentialSubject["details"] = map[string]any{"age": age}
+		if proofType != "" {
+			proof := crypto.Proof(map[string]any{"type": proofType})
+			cred.Proof = &proof
+		}
+		return cred
+	}
+	getDef := func(predicate Preference) PresentationDefinition {
+		return PresentationDefinition{
+			ID: "test-definition",
+			InputDescriptors: []InputDescriptor{
+				{
+					ID: "age",
+					Constraints: &Constraints{
+						Fields: []Field{
+							{
+								Path:      []string{"$.credentialSubject.age"},
+								Predicate: predicate.Ptr(),
+								Filter:    &Filter{Type: "number", ExclusiveMinimum: 18},
+							},
+							{
+								Path:      []string{"$.credentialSubject.details.age"},
+								Predicate: predicate.Ptr(),
+								Filter:    &Filter{Type: "number", ExclusiveMinimum: 18},
+							},
+						},
+					},
+				},
+			},
+		}
+	}
+
+	t.Run("required predicate replaces the value", func(tt *testing.T) {
+		cred := getCred(21, string(cryptosuite.BBSPlusSignature2020))
+		result, err := SelectCredentials(getDef(Required), []credential.VerifiableCredential{cred})
+		require.NoError(tt, err)
+		require.Len(tt, result.Matches["age"], 1)
+		matched := result.Matches["age"][0]
+		assert.Equal(tt, true, matched.CredentialSubject["age"])
+		assert.Equal(tt, map[string]any{"age": true}, matched.CredentialSubject["details"])
+		assert.Equal(tt, "Block", matched.CredentialSubject["company"])
+
+		// the held credential is unchanged
+		assert.Equal(tt, 21, cred.CredentialSubject["age"])
+		assert.Equal(tt, map[string]any{"age": 21}, cred.CredentialSubject["details"])
+
+		// the submission presents the boolean
+		_, vp, err := BuildPresentationSubmissionFromSelection(getDef(Required), *result)
+		require.NoError(tt, err)
+		vpJSON, err := util.ToJSONMap(vp)
+		require.NoError(tt, err)
+		age, err := jsonpath.JsonPathLookup(vpJSON, "$.verifiableCredential[0].credentialSubject.age")
+		require.NoError(tt, err)
+		assert.Equal(tt, true, age)
+	})
+
+	t.Run("required predicate not satisfied", func(tt *testing.T) {
+		_, err := SelectCredentials(getDef(Required), []credential.VerifiableCredential{getCred(18, string(cryptosuite.BBSPlusSignature2020))})
+		assert.ErrorContains(tt, err, "no credentials satisfy input descriptor<age>")
+		assert.False(tt, errors.Is(err, ErrPredicateUnsupported))
+	})
+
+	t.Run("required predicate unsupported by the credential format", func(tt *testing.T) {
+		creds := []credential.VerifiableCredential{getCred(21, ""), getCred(21, string(cryptosuite.JSONWebSignature2020))}
+		_, err := SelectCredentials(getDef(Required), creds)
+		assert.ErrorIs(tt, err, ErrPredicateUnsupported)
+		assert.ErrorContains(tt, err, "no credentials satisfy input descriptor<age>")
+	})
+
+	t.Run("preferred predicate presents the value when unsupported", func(tt *testing.T) {
+		result, err := SelectCredentials(getDef(Preferred), []credential.VerifiableCredential{getCred(21, "")})
+		require.NoError(tt, err)
+		assert.Equal(tt, 21, result.Matches["age"][0].CredentialSubject["age"])
+
+		result, err = SelectCredentials(getDef(Preferred), []credential.VerifiableCredential{getCred(21, string(cryptosuite.BBSPlusSignatureProof2020))})
+		require.NoError(tt, err)
+		assert.Equal(tt, true, result.Matches["age"][0].CredentialSubject["age"])
+	})
+
+	t.Run("predicate without a filter", func(tt *testing.T) {
+		def := getDef(Required)
+		def.InputDescriptors[0].Constraints.Fields[0].Filter = nil
+		_, err := SelectCredentials(def, []credential.VerifiableCredential{getCred(21, string(cryptosuite.BBSPlusSignature2020))})
+		assert.ErrorContains(tt, err, "field<$.credentialSubject.age> has a predicate without a filter")
+	})
+}
+
+func TestFulfillsSubmissionRequirements(t *testing.T) {
+	def := PresentationDefinition{
+		ID: "test-definition",
+		InputDescriptors: []InputDescriptor{
+			{ID: "id-1", Group: []string{"A"}},
+			{ID: "id-2", Group: []string{"A"}},
+		},
+	}
+
+	t.Run("all input descriptors fulfilled without submission requirements", func(tt *testing.T) {
+		assert.NoError(tt, FulfillsSubmissionRequirements(def, []string{"id-1", "id-2"}))
+	})
+
+	t.Run("input descriptor not fulfilled without submission requirements", func(tt *testing.T) {
+		err := FulfillsSubmissionRequirements(def, []string{"id-1"})
+		assert.Error(tt, err)
+		assert.Contains(tt, err.Error(), "input descriptor<id-2> not fulfilled")
+	})
+
+	t.Run("pick requirement fulfilled", func(tt *testing.T) {
+		pickDef := def
+		pickDef.SubmissionRequirements = []SubmissionRequirement{{Rule: Pick, Count: 1, FromOption: FromOption{From: "A"}}}
+		assert.NoError(tt, FulfillsSubmissionRequirements(pickDef, []string{"id-2"}))
+	})
+
+	t.Run("all requirement not fulfilled", func(tt *testing.T) {
+		allDef := def
+		allDef.SubmissionRequirements = []SubmissionRequirement{{Name: "everything", Rule: All, FromOption: FromOption{From: "A"}}}
+		err := FulfillsSubmissionRequirements(allDef, []string{"id-2"})
+		assert.Error(tt, err)
+		assert.Contains(tt, err.Error(), "submission requirement 0 <everything> not fulfilled")
+	})
+}
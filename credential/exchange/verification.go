@@ -179,9 +179,9 @@ func VerifyPresentationSubmissionVP(def PresentationDefinition, vp credential.Ve
 		// check relational constraints if present
 		subjectIsIssuerConstraint := constraints.SubjectIsIssuer
 		if subjectIsIssuerConstraint != nil && *subjectIsIssuerConstraint == Required {
-			issuer, ok := cred.Issuer.(string)
-			if !ok {
-				return nil, fmt.Errorf("unable to get issuer from cred: %s", cred.Issuer)
+			issuer, err := cred.IssuerID()
+			if err != nil {
+				return nil, errors.Wrapf(err, "unable to get issuer from cred: %s", cred.Issuer)
 			}
 			subject, ok := cred.CredentialSubject[credential.VerifiableCredentialIDProperty]
 			if !ok {
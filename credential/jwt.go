@@ -2,11 +2,15 @@ package credential
 
 import (
 	"context"
+	"crypto/sha256"
+	"encoding/base64"
 	"fmt"
+	"strings"
 	"time"
 
 	"github.com/TBD54566975/ssi-sdk/crypto/jwx"
 	"github.com/TBD54566975/ssi-sdk/did"
+	"github.com/TBD54566975/ssi-sdk/util"
 	"github.com/goccy/go-json"
 	"github.com/google/uuid"
 	"github.com/lestrrat-go/jwx/v2/jws"
@@ -18,10 +22,15 @@ const (
 	VCJWTProperty string = "vc"
 	VPJWTProperty string = "vp"
 	NonceProperty string = "nonce"
+	// VCHashesProperty is the claim of a VP JWT holding the digest of each VC JWT it contains, binding the
+	// presentation to those credentials
+	VCHashesProperty string = "vc_hashes"
 )
 
 // SignVerifiableCredentialJWT is prepared according to https://w3c.github.io/vc-jwt/#version-1.1
 // which will soon be deprecated by https://w3c.github.io/vc-jwt/ see: https://github.com/TBD54566975/ssi-sdk/issues/191
+// The credential's properties are mapped to registered JWT claims in addition to, rather than instead of, being part
+// of the credential in the vc claim. The token's header carries the signer's alg and kid, with a typ of JWT.
 func SignVerifiableCredentialJWT(signer jwx.Signer, cred VerifiableCredential) ([]byte, error) {
 	if cred.IsEmpty() {
 		return nil, errors.New("credential cannot be empty")
@@ -35,20 +44,19 @@ func SignVerifiableCredentialJWT(signer jwx.Signer, cred VerifiableCredential) (
 		if err := t.Set(jwt.ExpirationKey, cred.ExpirationDate); err != nil {
 			return nil, errors.Wrap(err, "could not set exp value")
 		}
-
-		// remove the expiration date from the credential
-		cred.ExpirationDate = ""
 	}
 
 	if err := t.Set(NonceProperty, uuid.New().String()); err != nil {
 		return nil, errors.Wrap(err, "setting nonce value")
 	}
 
-	if err := t.Set(jwt.IssuerKey, cred.Issuer); err != nil {
-		return nil, errors.Wrap(err, "could not set exp value")
+	issuerID, err := cred.IssuerID()
+	if err != nil {
+		return nil, errors.Wrap(err, "getting issuer id")
+	}
+	if err = t.Set(jwt.IssuerKey, issuerID); err != nil {
+		return nil, errors.Wrap(err, "could not set iss value")
 	}
-	// remove the issuer from the credential
-	cred.Issuer = ""
 
 	if err := t.Set(jwt.IssuedAtKey, cred.IssuanceDate); err != nil {
 		return nil, errors.Wrap(err, "could not set iat value")
@@ -56,16 +64,12 @@ func SignVerifiableCredentialJWT(signer jwx.Signer, cred VerifiableCredential) (
 	if err := t.Set(jwt.NotBeforeKey, cred.IssuanceDate); err != nil {
 		return nil, errors.Wrap(err, "could not set nbf value")
 	}
-	// remove the issuance date from the credential
-	cred.IssuanceDate = ""
 
 	idVal := cred.ID
 	if idVal != "" {
 		if err := t.Set(jwt.JwtIDKey, idVal); err != nil {
 			return nil, errors.Wrap(err, "could not set jti value")
 		}
-		// remove the id from the credential
-		cred.ID = ""
 	}
 
 	subVal := cred.CredentialSubject.GetID()
@@ -73,8 +77,6 @@ func SignVerifiableCredentialJWT(signer jwx.Signer, cred VerifiableCredential) (
 		if err := t.Set(jwt.SubjectKey, subVal); err != nil {
 			return nil, errors.Wrap(err, "setting subject value")
 		}
-		// remove the id from the credential subject
-		delete(cred.CredentialSubject, "id")
 	}
 
 	if err := t.Set(VCJWTProperty, cred); err != nil {
@@ -152,16 +154,23 @@ func ParseVerifiableCredentialFromToken(token jwt.Token) (*VerifiableCredential,
 		cred.IssuanceDate = iatTime.Format(time.RFC3339)
 	}
 
+	// nbf is the registered claim for the issuance date, and takes precedence over iat
+	nbf, hasNBF := token.Get(jwt.NotBeforeKey)
+	nbfTime, ok := nbf.(time.Time)
+	if hasNBF && ok {
+		cred.IssuanceDate = nbfTime.Format(time.RFC3339)
+	}
+
 	exp, hasExp := token.Get(jwt.ExpirationKey)
 	expTime, ok := exp.(time.Time)
 	if hasExp && ok {
 		cred.ExpirationDate = expTime.Format(time.RFC3339)
 	}
 
-	// Note: we only handle string issuer values, not objects for JWTs
+	// the iss claim only holds the issuer's id, so an issuer object kept in the credential is preferred
 	iss, hasIss := token.Get(jwt.IssuerKey)
 	issStr, ok := iss.(string)
-	if hasIss && ok && issStr != "" {
+	if _, err = cred.IssuerID(); err != nil && hasIss && ok && issStr != "" {
 		cred.Issuer = issStr
 	}
 
@@ -183,6 +192,9 @@ type JWTVVPParameters struct {
 	Audience string `validate:"required"`
 	// Expiration is an optional expiration time of the JWT using the `exp` property.
 	Expiration int
+	// Nonce is an optional nonce of the JWT provided by the verifier, to protect against replay. A random nonce is
+	// used if it is empty.
+	Nonce string
 }
 
 // SignVerifiablePresentationJWT transforms a VP into a VP JWT and signs it
@@ -211,7 +223,11 @@ func SignVerifiablePresentationJWT(signer jwx.Signer, parameters JWTVVPParameter
 		return nil, errors.Wrap(err, "setting nbf value")
 	}
 
-	if err := t.Set(NonceProperty, uuid.New().String()); err != nil {
+	nonce := parameters.Nonce
+	if nonce == "" {
+		nonce = uuid.New().String()
+	}
+	if err := t.Set(NonceProperty, nonce); err != nil {
 		return nil, errors.Wrap(err, "setting nonce value")
 	}
 
@@ -240,6 +256,11 @@ func SignVerifiablePresentationJWT(signer jwx.Signer, parameters JWTVVPParameter
 	if err := t.Set(VPJWTProperty, presentation); err != nil {
 		return nil, errors.Wrap(err, "setting vp value")
 	}
+	if vcHashes := credentialJWTHashes(presentation); len(vcHashes) > 0 {
+		if err := t.Set(VCHashesProperty, vcHashes); err != nil {
+			return nil, errors.Wrap(err, "setting vc_hashes value")
+		}
+	}
 
 	signed, err := jwt.Sign(t, jwt.WithKey(signer.SignatureAlgorithm, signer.Key))
 	if err != nil {
@@ -280,6 +301,9 @@ func VerifyVerifiablePresentationJWT(ctx context.Context, verifier jwx.Verifier,
 	if !audMatch {
 		return nil, nil, nil, errors.Errorf("audience mismatch: expected [%s] or [%s], got %s", verifier.ID, verifier.KeyID(), vpToken.Audience())
 	}
+	if err = verifyCredentialJWTHashes(vpToken, vp); err != nil {
+		return nil, nil, nil, err
+	}
 
 	// verify signature for each credential in the vp
 	for i, cred := range vp.VerifiableCredential {
@@ -297,6 +321,136 @@ func VerifyVerifiablePresentationJWT(ctx context.Context, verifier jwx.Verifier,
 	return headers, vpToken, vp, nil
 }
 
+// VerifyVerifiablePresentationJWTWithResolver verifies the signature of a VP JWT with the key of its holder, and
+// returns the presentation. The holder's DID, given by the iss claim, is resolved with the provided resolver to find
+// the verification method matching the KID in the JWT header. The aud claim must contain the expected audience, and
+// the nonce claim must match the expected nonce. The signature of each credential in the presentation, such as an
+// embedded VC JWT, is then verified.
+func VerifyVerifiablePresentationJWTWithResolver(ctx context.Context, token string, resolver did.Resolver, expectedAudience, expectedNonce string) (*VerifiablePresentation, error) {
+	if token == "" {
+		return nil, errors.New("presentation cannot be empty")
+	}
+	if resolver == nil {
+		return nil, errors.New("resolver cannot be empty")
+	}
+	_, vp, err := verifyPresentationJWTWithHolderKey(ctx, token, resolver, expectedAudience, expectedNonce)
+	if err != nil {
+		return nil, err
+	}
+
+	// verify signature for each credential in the vp
+	for i, cred := range vp.VerifiableCredential {
+		verified, err := VerifyCredentialSignature(ctx, cred, resolver)
+		if err != nil {
+			return nil, errors.Wrapf(err, "verifying credential %d", i)
+		}
+		if !verified {
+			return nil, errors.Errorf("credential %d failed signature verification", i)
+		}
+	}
+	return vp, nil
+}
+
+// verifyPresentationJWTWithHolderKey verifies the signature of a VP JWT with the key of its holder, resolved with the
+// provided resolver, and that it is for the expected audience in response to the expected nonce. It returns the token
+// and the presentation.
+func verifyPresentationJWTWithHolderKey(ctx context.Context, token string, resolver did.Resolver, expectedAudience, expectedNonce string) (jwt.Token, *VerifiablePresentation, error) {
+	if expectedAudience == "" {
+		return nil, nil, errors.New("expected audience cannot be empty")
+	}
+	if expectedNonce == "" {
+		return nil, nil, errors.New("expected nonce cannot be empty")
+	}
+	headers, vpToken, vp, err := ParseVerifiablePresentationFromJWT(token)
+	if err != nil {
+		return nil, nil, errors.Wrap(err, "parsing VP from JWT")
+	}
+
+	// get the holder's key to verify the presentation with
+	holder := vp.Holder
+	holderKID := headers.KeyID()
+	if holderKID == "" {
+		return nil, nil, errors.New("missing kid in header of presentation")
+	}
+	if kidDID, _, found := strings.Cut(holderKID, "#"); found && strings.HasPrefix(kidDID, "did:") && kidDID != holder {
+		return nil, nil, errors.Errorf("kid<%s> is not a key of holder<%s>", holderKID, holder)
+	}
+	holderDID, err := resolver.Resolve(ctx, holder)
+	if err != nil {
+		return nil, nil, errors.Wrapf(err, "resolving holder DID<%s>", holder)
+	}
+	holderKey, err := did.GetKeyFromVerificationMethod(holderDID.Document, holderKID)
+	if err != nil {
+		return nil, nil, errors.Wrap(err, "getting key to verify presentation")
+	}
+	verifier, err := jwx.NewJWXVerifier(holderDID.ID, holderKey)
+	if err != nil {
+		return nil, nil, errors.Wrap(err, "constructing verifier for presentation")
+	}
+	if err = verifier.Verify(token); err != nil {
+		return nil, nil, errors.Wrap(err, "verifying JWT and its signature")
+	}
+
+	// the presentation must be for the verifier, in response to its nonce
+	audMatch := false
+	for _, aud := range vpToken.Audience() {
+		if aud == expectedAudience {
+			audMatch = true
+			break
+		}
+	}
+	if !audMatch {
+		return nil, nil, errors.Errorf("audience mismatch: expected [%s], got %s", expectedAudience, vpToken.Audience())
+	}
+	nonce, _ := vpToken.Get(NonceProperty)
+	if nonceStr, ok := nonce.(string); !ok || nonceStr != expectedNonce {
+		return nil, nil, errors.Errorf("nonce mismatch: expected [%s], got [%v]", expectedNonce, nonce)
+	}
+	if err = verifyCredentialJWTHashes(vpToken, vp); err != nil {
+		return nil, nil, err
+	}
+	return vpToken, vp, nil
+}
+
+// credentialJWTHashes returns the base64url encoded SHA-256 digest of each VC JWT in the presentation
+func credentialJWTHashes(presentation VerifiablePresentation) []string {
+	var hashes []string
+	for _, cred := range presentation.VerifiableCredential {
+		if token, ok := cred.(string); ok {
+			hashes = append(hashes, credentialJWTHash(token))
+		}
+	}
+	return hashes
+}
+
+func credentialJWTHash(token string) string {
+	digest := sha256.Sum256([]byte(token))
+	return base64.RawURLEncoding.EncodeToString(digest[:])
+}
+
+// verifyCredentialJWTHashes checks that each VC JWT in the presentation matches one of the digests of the vc_hashes
+// claim of its token, so that its credentials cannot be swapped. Presentations without the claim are not checked.
+func verifyCredentialJWTHashes(vpToken jwt.Token, vp *VerifiablePresentation) error {
+	vcHashesClaim, ok := vpToken.Get(VCHashesProperty)
+	if !ok {
+		return nil
+	}
+	vcHashes, err := util.InterfaceToStrings(vcHashesClaim)
+	if err != nil {
+		return errors.Wrap(err, "reading vc_hashes claim")
+	}
+	expected := make(map[string]bool, len(vcHashes))
+	for _, vcHash := range vcHashes {
+		expected[vcHash] = true
+	}
+	for i, cred := range vp.VerifiableCredential {
+		if token, ok := cred.(string); ok && !expected[credentialJWTHash(token)] {
+			return errors.Errorf("credential %d does not match any of the vc_hashes of the presentation", i)
+		}
+	}
+	return nil
+}
+
 // ParseVerifiablePresentationFromJWT the JWT is decoded according to the specification.
 // https://www.w3.org/TR/vc-data-model/#jwt-decoding
 // If there are any issues during decoding, an error is returned. As a result, a successfully
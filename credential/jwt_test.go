@@ -9,8 +9,10 @@ import (
 
 	"github.com/TBD54566975/ssi-sdk/crypto"
 	"github.com/TBD54566975/ssi-sdk/crypto/jwx"
+	"github.com/TBD54566975/ssi-sdk/cryptosuite"
 	"github.com/TBD54566975/ssi-sdk/did"
 	"github.com/google/uuid"
+	"github.com/lestrrat-go/jwx/v2/jwt"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 )
@@ -86,6 +88,58 @@ func TestVerifiableCredentialJWT(t *testing.T) {
 		assert.Equal(tt, parsedCred, cred)
 		assert.Equal(tt, parsedHeaders, verifiedHeaders)
 	})
+
+	t.Run("Claim Mapping", func(tt *testing.T) {
+		_, privKey, err := crypto.GenerateEd25519Key()
+		require.NoError(tt, err)
+		signer, err := jwx.NewJWXSigner("test-id", "test-kid", privKey)
+		require.NoError(tt, err)
+
+		cred := testCredential
+		cred.Issuer = map[string]any{"id": "did:example:123", "name": "Example Issuer"}
+		cred.ExpirationDate = "2031-01-01T19:23:24Z"
+		cred.CredentialSubject = map[string]any{"id": "did:example:456", "name": "JimBobertson"}
+		signed, err := SignVerifiableCredentialJWT(*signer, cred)
+		require.NoError(tt, err)
+
+		// the caller's credential is left untouched
+		assert.Equal(tt, "did:example:456", cred.CredentialSubject.GetID())
+
+		verifier, err := signer.ToVerifier(signer.ID)
+		require.NoError(tt, err)
+		headers, token, parsedCred, err := VerifyVerifiableCredentialJWT(*verifier, string(signed))
+		require.NoError(tt, err)
+
+		assert.Equal(tt, "EdDSA", headers.Algorithm().String())
+		assert.Equal(tt, "test-kid", headers.KeyID())
+		assert.Equal(tt, "JWT", headers.Type())
+
+		issuanceDate, err := time.Parse(time.RFC3339, cred.IssuanceDate)
+		require.NoError(tt, err)
+		expirationDate, err := time.Parse(time.RFC3339, cred.ExpirationDate)
+		require.NoError(tt, err)
+		assert.Equal(tt, "did:example:123", token.Issuer())
+		assert.Equal(tt, "did:example:456", token.Subject())
+		assert.Equal(tt, "http://example.edu/credentials/1872", token.JwtID())
+		assert.Equal(tt, issuanceDate, token.NotBefore().UTC())
+		assert.Equal(tt, issuanceDate, token.IssuedAt().UTC())
+		assert.Equal(tt, expirationDate, token.Expiration().UTC())
+
+		// the vc claim still contains the original fields
+		vcClaim, ok := token.Get(VCJWTProperty)
+		require.True(tt, ok)
+		vcMap, ok := vcClaim.(map[string]any)
+		require.True(tt, ok)
+		assert.Equal(tt, "http://example.edu/credentials/1872", vcMap["id"])
+		assert.Equal(tt, map[string]any{"id": "did:example:123", "name": "Example Issuer"}, vcMap["issuer"])
+		assert.Equal(tt, "2021-01-01T19:23:24Z", vcMap["issuanceDate"])
+		assert.Equal(tt, "2031-01-01T19:23:24Z", vcMap["expirationDate"])
+		assert.Equal(tt, map[string]any{"id": "did:example:456", "name": "JimBobertson"}, vcMap["credentialSubject"])
+
+		// types are unmarshaled as an array of any
+		cred.Type = []any{"VerifiableCredential"}
+		assert.Equal(tt, cred, *parsedCred)
+	})
 }
 
 func TestVerifiablePresentationJWT(t *testing.T) {
@@ -230,6 +284,163 @@ func TestVerifiablePresentationJWT(t *testing.T) {
 	})
 }
 
+func TestVerifiablePresentationJWTWithResolver(t *testing.T) {
+	resolver, err := did.NewResolver([]did.Resolver{did.KeyResolver{}}...)
+	require.NoError(t, err)
+
+	holderSigner, holderDID := getTestDIDKeySigner(t)
+	firstIssuerSigner, firstIssuerDID := getTestDIDKeySigner(t)
+	secondIssuerSigner, secondIssuerDID := getTestDIDKeySigner(t)
+	signVC := func(signer jwx.Signer, issuer string) string {
+		vc := VerifiableCredential{
+			ID:           uuid.NewString(),
+			Context:      []any{"https://www.w3.org/2018/credentials/v1"},
+			Type:         []string{"VerifiableCredential"},
+			Issuer:       issuer,
+			IssuanceDate: time.Now().Format(time.RFC3339),
+			CredentialSubject: map[string]any{
+				"id":   holderDID,
+				"name": "Toshi",
+			},
+		}
+		signed, err := SignVerifiableCredentialJWT(signer, vc)
+		require.NoError(t, err)
+		return string(signed)
+	}
+	testPresentation := VerifiablePresentation{
+		Context: []string{"https://www.w3.org/2018/credentials/v1"},
+		Type:    []string{"VerifiablePresentation"},
+		Holder:  holderDID,
+		VerifiableCredential: []any{
+			signVC(firstIssuerSigner, firstIssuerDID),
+			signVC(secondIssuerSigner, secondIssuerDID),
+		},
+	}
+
+	t.Run("valid presentation with two VC JWTs", func(tt *testing.T) {
+		signed, err := SignVerifiablePresentationJWT(holderSigner, JWTVVPParameters{Audience: "did:example:verifier", Nonce: "1234"}, testPresentation)
+		require.NoError(tt, err)
+
+		vp, err := VerifyVerifiablePresentationJWTWithResolver(context.Background(), string(signed), resolver, "did:example:verifier", "1234")
+		assert.NoError(tt, err)
+		require.NotEmpty(tt, vp)
+		assert.Equal(tt, holderDID, vp.Holder)
+		assert.Len(tt, vp.VerifiableCredential, 2)
+	})
+
+	t.Run("audience mismatch", func(tt *testing.T) {
+		signed, err := SignVerifiablePresentationJWT(holderSigner, JWTVVPParameters{Audience: "did:example:other", Nonce: "1234"}, testPresentation)
+		require.NoError(tt, err)
+
+		_, err = VerifyVerifiablePresentationJWTWithResolver(context.Background(), string(signed), resolver, "did:example:verifier", "1234")
+		assert.Error(tt, err)
+		assert.Contains(tt, err.Error(), "audience mismatch")
+	})
+
+	t.Run("nonce mismatch", func(tt *testing.T) {
+		signed, err := SignVerifiablePresentationJWT(holderSigner, JWTVVPParameters{Audience: "did:example:verifier"}, testPresentation)
+		require.NoError(tt, err)
+
+		_, err = VerifyVerifiablePresentationJWTWithResolver(context.Background(), string(signed), resolver, "did:example:verifier", "1234")
+		assert.Error(tt, err)
+		assert.Contains(tt, err.Error(), "nonce mismatch")
+	})
+
+	t.Run("signed by a key other than the holder's", func(tt *testing.T) {
+		otherPresentation := testPresentation
+		otherPresentation.Holder = firstIssuerDID
+		signed, err := SignVerifiablePresentationJWT(holderSigner, JWTVVPParameters{Audience: "did:example:verifier", Nonce: "1234"}, otherPresentation)
+		require.NoError(tt, err)
+
+		_, err = VerifyVerifiablePresentationJWTWithResolver(context.Background(), string(signed), resolver, "did:example:verifier", "1234")
+		assert.Error(tt, err)
+		assert.Contains(tt, err.Error(), "getting key to verify presentation")
+	})
+
+	t.Run("embedded VC JWT with an invalid signature", func(tt *testing.T) {
+		badPresentation := testPresentation
+		badPresentation.VerifiableCredential = []any{
+			signVC(firstIssuerSigner, firstIssuerDID),
+			// signed by the first issuer, claiming to be from the second
+			signVC(firstIssuerSigner, secondIssuerDID),
+		}
+		signed, err := SignVerifiablePresentationJWT(holderSigner, JWTVVPParameters{Audience: "did:example:verifier", Nonce: "1234"}, badPresentation)
+		require.NoError(tt, err)
+
+		_, err = VerifyVerifiablePresentationJWTWithResolver(context.Background(), string(signed), resolver, "did:example:verifier", "1234")
+		assert.Error(tt, err)
+		assert.Contains(tt, err.Error(), "verifying credential 1")
+	})
+
+	t.Run("embedded data integrity credential", func(tt *testing.T) {
+		ldIssuerSigner, ldIssuerDID := getTestLDSigner(tt)
+		ldVC := VerifiableCredential{
+			ID:                "urn:uuid:" + uuid.NewString(),
+			Context:           []any{VerifiableCredentialsLinkedDataContext},
+			Type:              []any{VerifiableCredentialType},
+			Issuer:            ldIssuerDID,
+			IssuanceDate:      time.Now().UTC().Format(time.RFC3339),
+			CredentialSubject: map[string]any{"id": holderDID},
+		}
+		require.NoError(tt, cryptosuite.GetEd25519Signature2020Suite().Sign(ldIssuerSigner, &ldVC))
+
+		ldPresentation := testPresentation
+		ldPresentation.VerifiableCredential = []any{signVC(firstIssuerSigner, firstIssuerDID), ldVC}
+		signed, err := SignVerifiablePresentationJWT(holderSigner, JWTVVPParameters{Audience: "did:example:verifier", Nonce: "1234"}, ldPresentation)
+		require.NoError(tt, err)
+		_, err = VerifyVerifiablePresentationJWTWithResolver(context.Background(), string(signed), resolver, "did:example:verifier", "1234")
+		assert.NoError(tt, err)
+
+		tampered := ldVC
+		tampered.CredentialSubject = map[string]any{"id": firstIssuerDID}
+		ldPresentation.VerifiableCredential = []any{tampered}
+		signed, err = SignVerifiablePresentationJWT(holderSigner, JWTVVPParameters{Audience: "did:example:verifier", Nonce: "1234"}, ldPresentation)
+		require.NoError(tt, err)
+		_, err = VerifyVerifiablePresentationJWTWithResolver(context.Background(), string(signed), resolver, "did:example:verifier", "1234")
+		assert.ErrorContains(tt, err, "verifying credential 0")
+	})
+
+	t.Run("vc_hashes bind the embedded VC JWTs", func(tt *testing.T) {
+		signed, err := SignVerifiablePresentationJWT(holderSigner, JWTVVPParameters{Audience: "did:example:verifier", Nonce: "1234"}, testPresentation)
+		require.NoError(tt, err)
+
+		_, vpToken, _, err := ParseVerifiablePresentationFromJWT(string(signed))
+		require.NoError(tt, err)
+		vcHashes, ok := vpToken.Get(VCHashesProperty)
+		require.True(tt, ok)
+		assert.Len(tt, vcHashes, 2)
+	})
+
+	t.Run("swapped embedded VC JWT", func(tt *testing.T) {
+		signed, err := SignVerifiablePresentationJWT(holderSigner, JWTVVPParameters{Audience: "did:example:verifier", Nonce: "1234"}, testPresentation)
+		require.NoError(tt, err)
+
+		// replace the second credential with another valid one, keeping the vc_hashes of the original presentation
+		vpToken, err := jwt.Parse(signed, jwt.WithVerify(false), jwt.WithValidate(false))
+		require.NoError(tt, err)
+		swapped := testPresentation
+		swapped.Holder = ""
+		swapped.VerifiableCredential = []any{testPresentation.VerifiableCredential[0], signVC(secondIssuerSigner, secondIssuerDID)}
+		require.NoError(tt, vpToken.Set(VPJWTProperty, swapped))
+		resigned, err := jwt.Sign(vpToken, jwt.WithKey(holderSigner.SignatureAlgorithm, holderSigner.Key))
+		require.NoError(tt, err)
+
+		_, err = VerifyVerifiablePresentationJWTWithResolver(context.Background(), string(resigned), resolver, "did:example:verifier", "1234")
+		assert.Error(tt, err)
+		assert.Contains(tt, err.Error(), "credential 1 does not match any of the vc_hashes")
+	})
+}
+
+func getTestDIDKeySigner(t *testing.T) (jwx.Signer, string) {
+	privKey, didKey, err := did.GenerateDIDKey(crypto.Ed25519)
+	require.NoError(t, err)
+	expanded, err := didKey.Expand()
+	require.NoError(t, err)
+	signer, err := jwx.NewJWXSigner(didKey.String(), expanded.VerificationMethod[0].ID, privKey)
+	require.NoError(t, err)
+	return *signer, didKey.String()
+}
+
 func getTestVectorKey0Signer(t *testing.T) jwx.Signer {
 	// https://github.com/decentralized-identity/JWS-Test-Suite/blob/main/data/keys/key-0-ed25519.json
 	knownJWK := jwx.PrivateKeyJWK{
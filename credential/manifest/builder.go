@@ -1,11 +1,13 @@
 package manifest
 
 import (
+	"fmt"
 	"reflect"
 
 	"github.com/google/uuid"
 	"github.com/pkg/errors"
 
+	"github.com/TBD54566975/ssi-sdk/credential"
 	"github.com/TBD54566975/ssi-sdk/credential/exchange"
 	"github.com/TBD54566975/ssi-sdk/util"
 )
@@ -297,3 +299,57 @@ func (crb *CredentialResponseBuilder) SetDenial(reason string, inputDescriptors
 	}
 	return nil
 }
+
+// BuildCredentialResponse constructs a credential response whose fulfillment links each output descriptor of a
+// credential manifest to the issued credential fulfilling it. An issued credential fulfills the first output
+// descriptor without a credential whose schema is the id of the credential's credentialSchema. Every output descriptor
+// must be fulfilled, and every issued credential must fulfill an output descriptor. The descriptor_map paths point
+// at the credentials' locations in the verifiableCredentials array of a CredentialResponseWrapper, which must hold
+// the issued credentials in the order given.
+// https://identity.foundation/credential-manifest/#credential-response
+func BuildCredentialResponse(mfst CredentialManifest, issuedCreds []credential.VerifiableCredential) (*CredentialResponse, error) {
+	if err := mfst.IsValid(); err != nil {
+		return nil, errors.Wrap(err, "credential manifest is not valid")
+	}
+
+	// index of the issued credential fulfilling each output descriptor
+	fulfilledBy := make(map[string]int, len(mfst.OutputDescriptors))
+	for i, cred := range issuedCreds {
+		if cred.CredentialSchema == nil {
+			return nil, fmt.Errorf("issued credential<%s> has no credentialSchema to match an output descriptor", cred.ID)
+		}
+		matched := false
+		for _, outputDescriptor := range mfst.OutputDescriptors {
+			if _, ok := fulfilledBy[outputDescriptor.ID]; ok || outputDescriptor.Schema != cred.CredentialSchema.ID {
+				continue
+			}
+			fulfilledBy[outputDescriptor.ID] = i
+			matched = true
+			break
+		}
+		if !matched {
+			return nil, fmt.Errorf("issued credential<%s> with schema<%s> does not fulfill any output descriptor",
+				cred.ID, cred.CredentialSchema.ID)
+		}
+	}
+
+	descriptors := make([]exchange.SubmissionDescriptor, 0, len(mfst.OutputDescriptors))
+	for _, outputDescriptor := range mfst.OutputDescriptors {
+		i, ok := fulfilledBy[outputDescriptor.ID]
+		if !ok {
+			return nil, fmt.Errorf("no issued credential fulfills output descriptor<%s> with schema<%s>",
+				outputDescriptor.ID, outputDescriptor.Schema)
+		}
+		descriptors = append(descriptors, exchange.SubmissionDescriptor{
+			ID:     outputDescriptor.ID,
+			Format: string(exchange.LDPVC),
+			Path:   fmt.Sprintf("$.verifiableCredentials[%d]", i),
+		})
+	}
+
+	builder := NewCredentialResponseBuilder(mfst.ID)
+	if err := builder.SetFulfillment(descriptors); err != nil {
+		return nil, errors.Wrap(err, "setting fulfillment")
+	}
+	return builder.Build()
+}
@@ -3,8 +3,12 @@ package manifest
 import (
 	"testing"
 
+	"github.com/goccy/go-json"
+	"github.com/oliveagle/jsonpath"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 
+	"github.com/TBD54566975/ssi-sdk/credential"
 	"github.com/TBD54566975/ssi-sdk/credential/exchange"
 	"github.com/TBD54566975/ssi-sdk/crypto"
 )
@@ -254,3 +258,85 @@ func TestCredentialResponseBuilder(t *testing.T) {
 		assert.NotEmpty(tt, denial)
 	})
 }
+
+func TestBuildCredentialResponse(t *testing.T) {
+	cm := CredentialManifest{
+		ID:          "test-manifest",
+		SpecVersion: SpecVersion,
+		Issuer:      Issuer{ID: "did:example:issuer"},
+		OutputDescriptors: []OutputDescriptor{
+			{ID: "drivers-license", Schema: "https://example.com/schemas/drivers-license.json"},
+			{ID: "proof-of-address", Schema: "https://example.com/schemas/proof-of-address.json"},
+		},
+	}
+	issuedCred := func(id, schema string) credential.VerifiableCredential {
+		return credential.VerifiableCredential{
+			Context:           []any{"https://www.w3.org/2018/credentials/v1"},
+			ID:                id,
+			Type:              []string{"VerifiableCredential"},
+			Issuer:            "did:example:issuer",
+			IssuanceDate:      "2022-01-01T00:00:00Z",
+			CredentialSubject: credential.CredentialSubject{"id": "did:example:applicant"},
+			CredentialSchema:  &credential.CredentialSchema{ID: schema, Type: "JsonSchemaValidator2018"},
+		}
+	}
+
+	t.Run("each output descriptor is fulfilled", func(tt *testing.T) {
+		creds := []credential.VerifiableCredential{
+			issuedCred("address-cred", "https://example.com/schemas/proof-of-address.json"),
+			issuedCred("license-cred", "https://example.com/schemas/drivers-license.json"),
+		}
+		response, err := BuildCredentialResponse(cm, creds)
+		require.NoError(tt, err)
+		require.NotEmpty(tt, response)
+		assert.Equal(tt, cm.ID, response.ManifestID)
+		assert.Nil(tt, response.Denial)
+		require.NotNil(tt, response.Fulfillment)
+		require.Len(tt, response.Fulfillment.DescriptorMap, 2)
+
+		// each descriptor_map path resolves to the credential fulfilling the output descriptor
+		wrapper := CredentialResponseWrapper{CredentialResponse: *response, Credentials: []any{creds[0], creds[1]}}
+		wrapperBytes, err := json.Marshal(wrapper)
+		require.NoError(tt, err)
+		var wrapperJSON map[string]any
+		require.NoError(tt, json.Unmarshal(wrapperBytes, &wrapperJSON))
+
+		expected := map[string]string{"drivers-license": "license-cred", "proof-of-address": "address-cred"}
+		for _, descriptor := range response.Fulfillment.DescriptorMap {
+			assert.Equal(tt, string(exchange.LDPVC), descriptor.Format)
+			claim, err := jsonpath.JsonPathLookup(wrapperJSON, descriptor.Path)
+			require.NoError(tt, err)
+			claimJSON, ok := claim.(map[string]any)
+			require.True(tt, ok)
+			assert.Equal(tt, expected[descriptor.ID], claimJSON["id"])
+		}
+	})
+
+	t.Run("output descriptor without an issued credential", func(tt *testing.T) {
+		creds := []credential.VerifiableCredential{
+			issuedCred("license-cred", "https://example.com/schemas/drivers-license.json"),
+		}
+		_, err := BuildCredentialResponse(cm, creds)
+		assert.Error(tt, err)
+		assert.Contains(tt, err.Error(), "no issued credential fulfills output descriptor<proof-of-address>")
+	})
+
+	t.Run("issued credential without an output descriptor", func(tt *testing.T) {
+		creds := []credential.VerifiableCredential{
+			issuedCred("license-cred", "https://example.com/schemas/drivers-license.json"),
+			issuedCred("address-cred", "https://example.com/schemas/proof-of-address.json"),
+			issuedCred("other-cred", "https://example.com/schemas/other.json"),
+		}
+		_, err := BuildCredentialResponse(cm, creds)
+		assert.Error(tt, err)
+		assert.Contains(tt, err.Error(), "issued credential<other-cred> with schema<https://example.com/schemas/other.json> does not fulfill any output descriptor")
+	})
+
+	t.Run("issued credential without a schema", func(tt *testing.T) {
+		cred := issuedCred("license-cred", "")
+		cred.CredentialSchema = nil
+		_, err := BuildCredentialResponse(cm, []credential.VerifiableCredential{cred})
+		assert.Error(tt, err)
+		assert.Contains(tt, err.Error(), "issued credential<license-cred> has no credentialSchema")
+	})
+}
@@ -0,0 +1,168 @@
+package manifest
+
+import (
+	"math"
+	"strconv"
+	"time"
+
+	"github.com/pkg/errors"
+
+	"github.com/TBD54566975/ssi-sdk/credential"
+	"github.com/TBD54566975/ssi-sdk/credential/rendering"
+)
+
+const (
+	dateLayout = "2006-01-02"
+	timeLayout = "15:04:05"
+)
+
+// RenderedCredential is the display of a credential described by an output descriptor's display mappings
+// https://identity.foundation/wallet-rendering/#data-display
+type RenderedCredential struct {
+	Title       string             `json:"title,omitempty"`
+	Subtitle    string             `json:"subtitle,omitempty"`
+	Description string             `json:"description,omitempty"`
+	Properties  []RenderedProperty `json:"properties,omitempty"`
+}
+
+// RenderedProperty is the display of a labeled display mapping of an output descriptor
+type RenderedProperty struct {
+	Label string `json:"label"`
+	Value string `json:"value"`
+}
+
+// RenderCredential resolves the display mappings of an output descriptor against a credential it describes. For
+// each mapping with a path, the first of its paths whose value conforms to the mapping's schema is displayed,
+// formatted according to the schema; when none does, the mapping's fallback is displayed. Mappings with text display
+// the text. A mapping that neither resolves nor has a fallback is not displayed: it is left empty, or for labeled
+// properties, omitted.
+// https://identity.foundation/wallet-rendering/#display-mapping-object
+func RenderCredential(descriptor OutputDescriptor, cred credential.VerifiableCredential) (*RenderedCredential, error) {
+	var rendered RenderedCredential
+	if descriptor.Display == nil {
+		return &rendered, nil
+	}
+	credJSON, err := credential.ToCredentialJSONMap(cred)
+	if err != nil {
+		return nil, errors.Wrap(err, "getting credential as json")
+	}
+
+	display := descriptor.Display
+	mappings := []struct {
+		name    string
+		mapping *rendering.DisplayMappingObject
+		value   *string
+	}{
+		{name: "title", mapping: display.Title, value: &rendered.Title},
+		{name: "subtitle", mapping: display.Subtitle, value: &rendered.Subtitle},
+		{name: "description", mapping: display.Description, value: &rendered.Description},
+	}
+	for _, m := range mappings {
+		if m.mapping == nil {
+			continue
+		}
+		value, _, err := renderDisplayMapping(*m.mapping, credJSON)
+		if err != nil {
+			return nil, errors.Wrapf(err, "rendering %s of output descriptor<%s>", m.name, descriptor.ID)
+		}
+		*m.value = value
+	}
+	for _, property := range display.Properties {
+		if err = property.IsValid(); err != nil {
+			return nil, errors.Wrapf(err, "rendering property<%s> of output descriptor<%s>", property.Label, descriptor.ID)
+		}
+		value, ok, err := renderDisplayMapping(*property.DisplayMappingObject, credJSON)
+		if err != nil {
+			return nil, errors.Wrapf(err, "rendering property<%s> of output descriptor<%s>", property.Label, descriptor.ID)
+		}
+		if ok {
+			rendered.Properties = append(rendered.Properties, RenderedProperty{Label: property.Label, Value: value})
+		}
+	}
+	return &rendered, nil
+}
+
+// renderDisplayMapping returns the display string of a display mapping, and whether it has one
+func renderDisplayMapping(mapping rendering.DisplayMappingObject, credJSON map[string]any) (string, bool, error) {
+	if err := mapping.IsValid(); err != nil {
+		return "", false, err
+	}
+	if mapping.Text != nil {
+		return *mapping.Text, true, nil
+	}
+	for _, path := range mapping.Path {
+		value, err := credential.LookupClaim(credJSON, path)
+		if err != nil {
+			continue
+		}
+		if formatted, ok := formatDisplayValue(value, *mapping.Schema); ok {
+			return formatted, true, nil
+		}
+	}
+	if mapping.Fallback != "" {
+		return mapping.Fallback, true, nil
+	}
+	return "", false, nil
+}
+
+// formatDisplayValue formats a value as a display string according to a display mapping schema, or reports that the
+// value does not conform to the schema
+func formatDisplayValue(value any, schema rendering.DisplayMappingSchema) (string, bool) {
+	switch schema.Type {
+	case rendering.StringType:
+		s, ok := value.(string)
+		if !ok {
+			return "", false
+		}
+		return formatDisplayString(s, schema.Format)
+	case rendering.NumberType:
+		n, ok := value.(float64)
+		if !ok {
+			return "", false
+		}
+		return strconv.FormatFloat(n, 'f', -1, 64), true
+	case rendering.IntegerType:
+		n, ok := value.(float64)
+		if !ok || n != math.Trunc(n) {
+			return "", false
+		}
+		return strconv.FormatFloat(n, 'f', -1, 64), true
+	case rendering.BooleanType:
+		b, ok := value.(bool)
+		if !ok {
+			return "", false
+		}
+		return strconv.FormatBool(b), true
+	default:
+		return "", false
+	}
+}
+
+// formatDisplayString normalizes date and time strings for display; strings of other formats are displayed as is
+func formatDisplayString(s string, format rendering.SchemaFormat) (string, bool) {
+	switch format {
+	case rendering.DateFormat:
+		// accept a full timestamp for a date, displaying only its date
+		for _, layout := range []string{dateLayout, time.RFC3339} {
+			if t, err := time.Parse(layout, s); err == nil {
+				return t.Format(dateLayout), true
+			}
+		}
+		return "", false
+	case rendering.DateTimeFormat:
+		t, err := time.Parse(time.RFC3339, s)
+		if err != nil {
+			return "", false
+		}
+		return t.Format(time.RFC3339), true
+	case rendering.TimeFormat:
+		for _, layout := range []string{timeLayout, timeLayout + "Z07:00"} {
+			if t, err := time.Parse(layout, s); err == nil {
+				return t.Format(layout), true
+			}
+		}
+		return "", false
+	default:
+		return s, true
+	}
+}
@@ -0,0 +1,143 @@
+package manifest
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/TBD54566975/ssi-sdk/credential"
+	"github.com/TBD54566975/ssi-sdk/credential/rendering"
+)
+
+func TestRenderCredential(t *testing.T) {
+	cred := credential.VerifiableCredential{
+		Context:      []any{"https://www.w3.org/2018/credentials/v1"},
+		ID:           "test-credential",
+		Type:         []string{"VerifiableCredential", "DriversLicense"},
+		Issuer:       "did:example:issuer",
+		IssuanceDate: "2022-01-01T00:00:00Z",
+		CredentialSubject: credential.CredentialSubject{
+			"id":         "did:example:applicant",
+			"name":       "Alice Smith",
+			"birthDate":  "1990-05-17T00:00:00Z",
+			"points":     3.5,
+			"endorsed":   true,
+			"licenseNum": 12345,
+		},
+	}
+	text := func(s string) *string { return &s }
+
+	t.Run("output descriptor without display", func(tt *testing.T) {
+		rendered, err := RenderCredential(OutputDescriptor{ID: "test", Schema: "test-schema"}, cred)
+		assert.NoError(tt, err)
+		assert.Empty(tt, rendered)
+	})
+
+	t.Run("display mappings resolve against the credential", func(tt *testing.T) {
+		descriptor := OutputDescriptor{
+			ID:     "drivers-license",
+			Schema: "test-schema",
+			Display: &rendering.DataDisplay{
+				Title: &rendering.DisplayMappingObject{Text: text("Driver's License")},
+				Subtitle: &rendering.DisplayMappingObject{
+					// the first path does not resolve, so the second is used
+					Path:   []string{"$.credentialSubject.fullName", "$.credentialSubject.name"},
+					Schema: &rendering.DisplayMappingSchema{Type: rendering.StringType},
+				},
+				Description: &rendering.DisplayMappingObject{
+					Path:     []string{"$.credentialSubject.description"},
+					Schema:   &rendering.DisplayMappingSchema{Type: rendering.StringType},
+					Fallback: "A license to drive",
+				},
+				Properties: []rendering.LabeledDisplayMappingObject{
+					{
+						Label: "Date of Birth",
+						DisplayMappingObject: &rendering.DisplayMappingObject{
+							Path:   []string{"$.credentialSubject.birthDate"},
+							Schema: &rendering.DisplayMappingSchema{Type: rendering.StringType, Format: rendering.DateFormat},
+						},
+					},
+					{
+						Label: "Points",
+						DisplayMappingObject: &rendering.DisplayMappingObject{
+							Path:   []string{"$.credentialSubject.points"},
+							Schema: &rendering.DisplayMappingSchema{Type: rendering.NumberType},
+						},
+					},
+					{
+						Label: "Endorsed",
+						DisplayMappingObject: &rendering.DisplayMappingObject{
+							Path:   []string{"$.credentialSubject.endorsed"},
+							Schema: &rendering.DisplayMappingSchema{Type: rendering.BooleanType},
+						},
+					},
+					{
+						// the value is not a string, so the fallback is used
+						Label: "License Number",
+						DisplayMappingObject: &rendering.DisplayMappingObject{
+							Path:     []string{"$.credentialSubject.licenseNum"},
+							Schema:   &rendering.DisplayMappingSchema{Type: rendering.StringType},
+							Fallback: "Unknown",
+						},
+					},
+					{
+						// no path resolves and there is no fallback, so the property is not displayed
+						Label: "Restrictions",
+						DisplayMappingObject: &rendering.DisplayMappingObject{
+							Path:   []string{"$.credentialSubject.restrictions"},
+							Schema: &rendering.DisplayMappingSchema{Type: rendering.StringType},
+						},
+					},
+				},
+			},
+		}
+
+		rendered, err := RenderCredential(descriptor, cred)
+		require.NoError(tt, err)
+		assert.Equal(tt, &RenderedCredential{
+			Title:       "Driver's License",
+			Subtitle:    "Alice Smith",
+			Description: "A license to drive",
+			Properties: []RenderedProperty{
+				{Label: "Date of Birth", Value: "1990-05-17"},
+				{Label: "Points", Value: "3.5"},
+				{Label: "Endorsed", Value: "true"},
+				{Label: "License Number", Value: "Unknown"},
+			},
+		}, rendered)
+	})
+
+	t.Run("date that does not conform uses the fallback", func(tt *testing.T) {
+		descriptor := OutputDescriptor{
+			ID:     "drivers-license",
+			Schema: "test-schema",
+			Display: &rendering.DataDisplay{
+				Title: &rendering.DisplayMappingObject{
+					Path:     []string{"$.credentialSubject.name"},
+					Schema:   &rendering.DisplayMappingSchema{Type: rendering.StringType, Format: rendering.DateFormat},
+					Fallback: "No date",
+				},
+			},
+		}
+
+		rendered, err := RenderCredential(descriptor, cred)
+		require.NoError(tt, err)
+		assert.Equal(tt, "No date", rendered.Title)
+	})
+
+	t.Run("invalid display mapping", func(tt *testing.T) {
+		descriptor := OutputDescriptor{
+			ID:     "drivers-license",
+			Schema: "test-schema",
+			Display: &rendering.DataDisplay{
+				Title: &rendering.DisplayMappingObject{Path: []string{"$.credentialSubject.name"}},
+			},
+		}
+
+		_, err := RenderCredential(descriptor, cred)
+		assert.Error(tt, err)
+		assert.Contains(tt, err.Error(), "rendering title of output descriptor<drivers-license>")
+		assert.Contains(tt, err.Error(), "schema cannot be empty when path is present")
+	})
+}
@@ -219,6 +219,142 @@ func IsValidCredentialApplicationForManifest(cm CredentialManifest, applicationA
 	return unfulfilledInputDescriptors, err
 }
 
+// UnexpectedPresentationSubmissionError is returned when a credential application supplies a presentation submission
+// for a credential manifest without a presentation definition
+type UnexpectedPresentationSubmissionError struct {
+	ApplicationID string
+	ManifestID    string
+}
+
+func (e *UnexpectedPresentationSubmissionError) Error() string {
+	return fmt.Sprintf("credential application<%s> has a presentation submission, but credential manifest<%s> has "+
+		"no presentation definition", e.ApplicationID, e.ManifestID)
+}
+
+// ValidationResult is the outcome of validating a credential application against a credential manifest
+type ValidationResult struct {
+	// Satisfied are the ids of the input descriptors of the manifest's presentation definition the application's
+	// presentation submission satisfies, in the order of the presentation definition
+	Satisfied []string
+	// Missing maps the ids of the input descriptors the presentation submission does not satisfy to the reason
+	Missing map[string]string
+}
+
+// ValidateCredentialApplication validates a credential application [app] against the credential manifest [mfst] it
+// applies for, without resolving the claims its presentation submission points at. The application must reference
+// the manifest's id, use formats the manifest supports, and have a presentation submission that satisfies the
+// manifest's presentation definition, if it has one. An input descriptor is satisfied by a submission descriptor
+// with a supported format and a valid JSONPath expression.
+// The result enumerates the satisfied and missing input descriptors. An error is returned alongside it if the
+// presentation submission does not satisfy the presentation definition, and an UnexpectedPresentationSubmissionError
+// if the application has a presentation submission but the manifest has no presentation definition.
+// https://identity.foundation/credential-manifest/#credential-application
+func ValidateCredentialApplication(app CredentialApplication, mfst CredentialManifest) (*ValidationResult, error) {
+	if err := mfst.IsValid(); err != nil {
+		return nil, errresp.NewErrorResponseWithErrorAndMsg(errresp.ApplicationError, err, "credential manifest is not valid")
+	}
+	if err := app.IsValid(); err != nil {
+		return nil, errresp.NewErrorResponseWithErrorAndMsg(errresp.ApplicationError, err, "credential application is not valid")
+	}
+
+	if app.ManifestID != mfst.ID {
+		return nil, errresp.NewErrorResponsef(errresp.ApplicationError, "the credential application's manifest id: "+
+			"%s must be equal to the credential manifest's id: %s", app.ManifestID, mfst.ID)
+	}
+
+	// the application's format must be a subset of the manifest's format, if it has one
+	var manifestFormats []string
+	if !mfst.Format.IsEmpty() {
+		manifestFormats = mfst.Format.FormatValues()
+		for _, format := range app.Format.FormatValues() {
+			if !util.Contains(format, manifestFormats) {
+				return nil, errresp.NewErrorResponsef(errresp.ApplicationError, "credential application's format<%s> "+
+					"is not one of the credential manifest's formats: %s", format, strings.Join(manifestFormats, ", "))
+			}
+		}
+	}
+
+	result := ValidationResult{Missing: make(map[string]string)}
+	if mfst.PresentationDefinition.IsEmpty() {
+		if app.PresentationSubmission != nil {
+			return nil, &UnexpectedPresentationSubmissionError{ApplicationID: app.ID, ManifestID: mfst.ID}
+		}
+		return &result, nil
+	}
+
+	def := *mfst.PresentationDefinition
+	if err := def.IsValid(); err != nil {
+		return nil, errresp.NewErrorResponseWithErrorAndMsg(errresp.ApplicationError, err, "credential manifest's"+
+			" presentation definition is not valid")
+	}
+	submission := app.PresentationSubmission
+	if submission.IsEmpty() {
+		for _, inputDescriptor := range def.InputDescriptors {
+			result.Missing[inputDescriptor.ID] = "no presentation submission provided"
+		}
+		return &result, errresp.NewErrorResponsef(errresp.ApplicationError, "credential application<%s> has no "+
+			"presentation submission for the credential manifest's presentation definition<%s>", app.ID, def.ID)
+	}
+	if err := submission.IsValid(); err != nil {
+		return nil, errresp.NewErrorResponseWithErrorAndMsg(errresp.ApplicationError, err, "credential "+
+			"application's presentation submission is not valid")
+	}
+	if submission.DefinitionID != def.ID {
+		return nil, errresp.NewErrorResponsef(errresp.ApplicationError, "credential application's presentation "+
+			"submission's definition id: %s does not match the presentation definition's id: %s",
+			submission.DefinitionID, def.ID)
+	}
+
+	submissionDescriptors := make(map[string]exchange.SubmissionDescriptor, len(submission.DescriptorMap))
+	for _, d := range submission.DescriptorMap {
+		submissionDescriptors[d.ID] = d
+	}
+	claimFormats := make([]string, 0, len(exchange.SupportedClaimFormats()))
+	for _, format := range exchange.SupportedClaimFormats() {
+		claimFormats = append(claimFormats, string(format))
+	}
+	for _, inputDescriptor := range def.InputDescriptors {
+		submissionDescriptor, ok := submissionDescriptors[inputDescriptor.ID]
+		if !ok {
+			result.Missing[inputDescriptor.ID] = "no submission descriptor found for input descriptor"
+			continue
+		}
+		if reason := unsatisfiedReason(submissionDescriptor, inputDescriptor, claimFormats, manifestFormats); reason != "" {
+			result.Missing[inputDescriptor.ID] = reason
+			continue
+		}
+		result.Satisfied = append(result.Satisfied, inputDescriptor.ID)
+	}
+
+	if err := exchange.FulfillsSubmissionRequirements(def, result.Satisfied); err != nil {
+		return &result, errresp.NewErrorResponseWithErrorAndMsgf(errresp.ApplicationError, err, "credential "+
+			"application not valid; <%d>unsatisfied input descriptor(s)", len(result.Missing))
+	}
+	return &result, nil
+}
+
+// unsatisfiedReason describes why a submission descriptor does not satisfy an input descriptor, or is empty if it does
+func unsatisfiedReason(submissionDescriptor exchange.SubmissionDescriptor, inputDescriptor exchange.InputDescriptor,
+	claimFormats, manifestFormats []string) string {
+	if !util.Contains(submissionDescriptor.Format, claimFormats) {
+		return fmt.Sprintf("the format of submission descriptor<%s> is invalid or not supported", submissionDescriptor.Format)
+	}
+	if len(manifestFormats) > 0 && !util.Contains(submissionDescriptor.Format, manifestFormats) {
+		return fmt.Sprintf("the format of submission descriptor<%s> is not one of the credential manifest's"+
+			" formats: %s", submissionDescriptor.Format, strings.Join(manifestFormats, ", "))
+	}
+	if inputDescriptor.Format != nil && !util.Contains(submissionDescriptor.Format, inputDescriptor.Format.FormatValues()) {
+		return fmt.Sprintf("the format of submission descriptor<%s> is not one of the supported formats: %s",
+			submissionDescriptor.Format, strings.Join(inputDescriptor.Format.FormatValues(), ", "))
+	}
+	for d := &submissionDescriptor; d != nil; d = d.PathNested {
+		if _, err := jsonpath.Compile(d.Path); err != nil {
+			return fmt.Sprintf("invalid json path: %s", d.Path)
+		}
+	}
+	return ""
+}
+
 func findMatchingPath(claim any, paths []string) error {
 	for _, path := range paths {
 		if _, err := jsonpath.JsonPathLookup(claim, path); err == nil {
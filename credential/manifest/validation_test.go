@@ -1,6 +1,7 @@
 package manifest
 
 import (
+	"errors"
 	"testing"
 
 	"github.com/TBD54566975/ssi-sdk/credential"
@@ -339,6 +340,117 @@ func TestIsValidCredentialApplicationForManifest(t *testing.T) {
 	})
 }
 
+func TestValidateCredentialApplication(t *testing.T) {
+	t.Run("Credential Application satisfies Credential Manifest", func(tt *testing.T) {
+		cm, ca := getValidTestCredManifestCredApplication(tt)
+
+		result, err := ValidateCredentialApplication(ca.CredentialApplication, cm)
+		assert.NoError(tt, err)
+		require.NotNil(tt, result)
+		assert.Equal(tt, []string{"kycid1"}, result.Satisfied)
+		assert.Empty(tt, result.Missing)
+	})
+
+	t.Run("Credential Application with wrong manifest id", func(tt *testing.T) {
+		cm, ca := getValidTestCredManifestCredApplication(tt)
+		ca.CredentialApplication.ManifestID = "bad-id"
+
+		result, err := ValidateCredentialApplication(ca.CredentialApplication, cm)
+		assert.Error(tt, err)
+		assert.Contains(tt, err.Error(), "the credential application's manifest id: bad-id must be equal to the credential manifest's id: WA-DL-CLASS-A")
+		assert.Nil(tt, result)
+	})
+
+	t.Run("Credential Application with format the Credential Manifest does not support", func(tt *testing.T) {
+		cm, ca := getValidTestCredManifestCredApplication(tt)
+		cm.Format = &exchange.ClaimFormat{JWTVC: &exchange.JWTType{Alg: []crypto.SignatureAlgorithm{crypto.EdDSA}}}
+
+		result, err := ValidateCredentialApplication(ca.CredentialApplication, cm)
+		assert.Error(tt, err)
+		assert.Contains(tt, err.Error(), "credential application's format<ldp_vc> is not one of the credential manifest's formats: jwt_vc")
+		assert.Nil(tt, result)
+	})
+
+	t.Run("Submission descriptor with format the Credential Manifest does not support", func(tt *testing.T) {
+		cm, ca := getValidTestCredManifestCredApplication(tt)
+		cm.Format = &exchange.ClaimFormat{LDPVC: &exchange.LDPType{ProofType: []cryptosuite.SignatureType{cryptosuite.JSONWebSignature2020}}}
+
+		result, err := ValidateCredentialApplication(ca.CredentialApplication, cm)
+		assert.Error(tt, err)
+		assert.Contains(tt, err.Error(), "<1>unsatisfied input descriptor(s)")
+		require.NotNil(tt, result)
+		assert.Empty(tt, result.Satisfied)
+		assert.Contains(tt, result.Missing["kycid1"], "is not one of the credential manifest's formats: ldp_vc")
+	})
+
+	t.Run("Missing submission descriptor", func(tt *testing.T) {
+		cm, ca := getValidTestCredManifestCredApplication(tt)
+		second := cm.PresentationDefinition.InputDescriptors[0]
+		second.ID = "kycid2"
+		cm.PresentationDefinition.InputDescriptors = append(cm.PresentationDefinition.InputDescriptors, second)
+
+		result, err := ValidateCredentialApplication(ca.CredentialApplication, cm)
+		assert.Error(tt, err)
+		assert.Contains(tt, err.Error(), "input descriptor<kycid2> not fulfilled")
+		require.NotNil(tt, result)
+		assert.Equal(tt, []string{"kycid1"}, result.Satisfied)
+		assert.Equal(tt, map[string]string{"kycid2": "no submission descriptor found for input descriptor"}, result.Missing)
+	})
+
+	t.Run("Missing submission descriptor not needed by submission requirements", func(tt *testing.T) {
+		cm, ca := getValidTestCredManifestCredApplication(tt)
+		def := cm.PresentationDefinition
+		def.InputDescriptors[0].Group = []string{"A"}
+		second := def.InputDescriptors[0]
+		second.ID = "kycid2"
+		def.InputDescriptors = append(def.InputDescriptors, second)
+		def.SubmissionRequirements = []exchange.SubmissionRequirement{{Rule: exchange.Pick, Count: 1, FromOption: exchange.FromOption{From: "A"}}}
+
+		result, err := ValidateCredentialApplication(ca.CredentialApplication, cm)
+		assert.NoError(tt, err)
+		require.NotNil(tt, result)
+		assert.Equal(tt, []string{"kycid1"}, result.Satisfied)
+		assert.Contains(tt, result.Missing, "kycid2")
+	})
+
+	t.Run("Missing presentation submission", func(tt *testing.T) {
+		cm, ca := getValidTestCredManifestCredApplication(tt)
+		ca.CredentialApplication.PresentationSubmission = nil
+
+		result, err := ValidateCredentialApplication(ca.CredentialApplication, cm)
+		assert.Error(tt, err)
+		assert.Contains(tt, err.Error(), "has no presentation submission")
+		require.NotNil(tt, result)
+		assert.Contains(tt, result.Missing, "kycid1")
+	})
+
+	t.Run("Presentation submission without presentation definition", func(tt *testing.T) {
+		cm, ca := getValidTestCredManifestCredApplication(tt)
+		cm.PresentationDefinition = nil
+
+		result, err := ValidateCredentialApplication(ca.CredentialApplication, cm)
+		assert.Error(tt, err)
+		assert.Nil(tt, result)
+
+		var submissionErr *UnexpectedPresentationSubmissionError
+		require.True(tt, errors.As(err, &submissionErr))
+		assert.Equal(tt, ca.CredentialApplication.ID, submissionErr.ApplicationID)
+		assert.Equal(tt, cm.ID, submissionErr.ManifestID)
+	})
+
+	t.Run("No presentation definition or submission", func(tt *testing.T) {
+		cm, ca := getValidTestCredManifestCredApplication(tt)
+		cm.PresentationDefinition = nil
+		ca.CredentialApplication.PresentationSubmission = nil
+
+		result, err := ValidateCredentialApplication(ca.CredentialApplication, cm)
+		assert.NoError(tt, err)
+		require.NotNil(tt, result)
+		assert.Empty(tt, result.Satisfied)
+		assert.Empty(tt, result.Missing)
+	})
+}
+
 func getValidTestCredManifestCredApplication(t *testing.T) (CredentialManifest, CredentialApplicationWrapper) {
 	// manifest
 	manifestJSON, err := getTestVector(FullManifestVector)
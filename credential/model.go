@@ -1,10 +1,21 @@
 package credential
 
 import (
+	"bytes"
 	"reflect"
+	"time"
 
 	"github.com/TBD54566975/ssi-sdk/crypto"
 	"github.com/TBD54566975/ssi-sdk/util"
+	"github.com/goccy/go-json"
+	"github.com/pkg/errors"
+)
+
+var (
+	// ErrNotYetValid is returned for a credential whose validity period has not yet begun
+	ErrNotYetValid = errors.New("credential is not yet valid")
+	// ErrExpired is returned for a credential whose validity period has ended
+	ErrExpired = errors.New("credential has expired")
 )
 
 // VerifiableCredential is the verifiable credential model outlined in the
@@ -18,20 +29,108 @@ type VerifiableCredential struct {
 	// either a URI or an object containing an `id` property.
 	Issuer any `json:"issuer" validate:"required"`
 	// https://www.w3.org/TR/xmlschema11-2/#dateTimes
-	IssuanceDate     string `json:"issuanceDate" validate:"required"`
-	ExpirationDate   string `json:"expirationDate,omitempty"`
+	// required for vc-data-model 1.1 credentials, see IsValid
+	IssuanceDate   string `json:"issuanceDate,omitempty"`
+	ExpirationDate string `json:"expirationDate,omitempty"`
+	// validFrom and validUntil replace issuanceDate and expirationDate in the vc-data-model 2.0
+	// https://www.w3.org/TR/vc-data-model-2.0/#validity-period
+	ValidFrom        string `json:"validFrom,omitempty"`
+	ValidUntil       string `json:"validUntil,omitempty"`
 	CredentialStatus any    `json:"credentialStatus,omitempty" validate:"omitempty,dive"`
-	// This is where the subject's ID *may* be present
-	CredentialSubject CredentialSubject `json:"credentialSubject" validate:"required"`
-	CredentialSchema  *CredentialSchema `json:"credentialSchema,omitempty" validate:"omitempty,dive"`
-	RefreshService    *RefreshService   `json:"refreshService,omitempty" validate:"omitempty,dive"`
-	TermsOfUse        []TermsOfUse      `json:"termsOfUse,omitempty" validate:"omitempty,dive"`
-	Evidence          []any             `json:"evidence,omitempty" validate:"omitempty,dive"`
+	// This is where the subject's ID *may* be present. When credentialSubject is an array, this holds the first
+	// subject and AdditionalSubjects holds the rest https://www.w3.org/TR/2021/REC-vc-data-model-20211109/#credential-subject
+	CredentialSubject  CredentialSubject   `json:"credentialSubject" validate:"required"`
+	AdditionalSubjects []CredentialSubject `json:"-"`
+	CredentialSchema   *CredentialSchema   `json:"credentialSchema,omitempty" validate:"omitempty,dive"`
+	RefreshService     *RefreshService     `json:"refreshService,omitempty" validate:"omitempty,dive"`
+	TermsOfUse         []TermsOfUse        `json:"termsOfUse,omitempty" validate:"omitempty,dive"`
+	Evidence           []any               `json:"evidence,omitempty" validate:"omitempty,dive"`
 	// For embedded proof support
 	// Proof is a digital signature over a credential https://www.w3.org/TR/2021/REC-vc-data-model-20211109/#proofs-signatures
 	Proof *crypto.Proof `json:"proof,omitempty"`
 }
 
+// verifiableCredential is an alias without the custom JSON (un)marshaling of VerifiableCredential
+type verifiableCredential VerifiableCredential
+
+// MarshalJSON emits credentialSubject as a single object when the credential has one subject, and as an array of
+// objects otherwise
+func (v VerifiableCredential) MarshalJSON() ([]byte, error) {
+	credBytes, err := json.Marshal(verifiableCredential(v))
+	if err != nil || len(v.AdditionalSubjects) == 0 {
+		return credBytes, err
+	}
+	var cred map[string]json.RawMessage
+	if err = json.Unmarshal(credBytes, &cred); err != nil {
+		return nil, err
+	}
+	if cred["credentialSubject"], err = json.Marshal(v.subjects()); err != nil {
+		return nil, errors.Wrap(err, "marshaling credential subjects")
+	}
+	return json.Marshal(cred)
+}
+
+// UnmarshalJSON accepts credentialSubject as either a single object or an array of objects. Numbers in properties
+// without a fixed type, such as the claims of the credential subject, are preserved as json.Number rather than
+// float64, so that large integers keep their precision and are marshaled exactly as they were received.
+func (v *VerifiableCredential) UnmarshalJSON(data []byte) error {
+	var cred struct {
+		verifiableCredential
+		CredentialSubject json.RawMessage `json:"credentialSubject"`
+	}
+	if err := unmarshalPreservingNumbers(data, &cred); err != nil {
+		return err
+	}
+	*v = VerifiableCredential(cred.verifiableCredential)
+	subject := bytes.TrimSpace(cred.CredentialSubject)
+	if len(subject) == 0 || bytes.Equal(subject, []byte("null")) {
+		return nil
+	}
+	if subject[0] != '[' {
+		return errors.Wrap(unmarshalPreservingNumbers(subject, &v.CredentialSubject), "unmarshaling credential subject")
+	}
+	var subjects []CredentialSubject
+	if err := unmarshalPreservingNumbers(subject, &subjects); err != nil {
+		return errors.Wrap(err, "unmarshaling credential subjects")
+	}
+	if len(subjects) > 0 {
+		v.CredentialSubject = subjects[0]
+		v.AdditionalSubjects = subjects[1:]
+	}
+	return nil
+}
+
+// unmarshalPreservingNumbers unmarshals JSON as json.Unmarshal does, except that numbers decoded into an interface
+// value are json.Number rather than float64
+func unmarshalPreservingNumbers(data []byte, v any) error {
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+	if err := decoder.Decode(v); err != nil {
+		return err
+	}
+	if decoder.More() {
+		return errors.New("unexpected data after JSON value")
+	}
+	return nil
+}
+
+// Subjects returns all subjects of the credential, whether credentialSubject is a single object or an array
+func (v *VerifiableCredential) Subjects() []map[string]any {
+	subjects := make([]map[string]any, 0, len(v.AdditionalSubjects)+1)
+	for _, subject := range v.subjects() {
+		subjects = append(subjects, subject)
+	}
+	return subjects
+}
+
+func (v VerifiableCredential) subjects() []CredentialSubject {
+	var subjects []CredentialSubject
+	if v.CredentialSubject != nil {
+		subjects = append(subjects, v.CredentialSubject)
+	}
+	return append(subjects, v.AdditionalSubjects...)
+}
+
 func (v *VerifiableCredential) GetProof() *crypto.Proof {
 	return v.Proof
 }
@@ -89,8 +188,91 @@ func (v *VerifiableCredential) IsEmpty() bool {
 	return reflect.DeepEqual(v, &VerifiableCredential{})
 }
 
+// IsValid validates the credential's object model. The issuanceDate is only required for credentials that are not
+// vc-data-model 2.0 credentials.
 func (v *VerifiableCredential) IsValid() error {
-	return util.NewValidator().Struct(v)
+	if err := util.NewValidator().Struct(v); err != nil {
+		return err
+	}
+	if !v.IsVersion2() && v.IssuanceDate == "" {
+		return errors.New("issuanceDate is required for credentials without the vc-data-model 2.0 context")
+	}
+	return nil
+}
+
+// IsVersion2 returns whether the credential has the vc-data-model 2.0 context
+// https://www.w3.org/TR/vc-data-model-2.0/#contexts
+func (v *VerifiableCredential) IsVersion2() bool {
+	contexts, err := util.InterfaceToStrings(v.Context)
+	if err != nil {
+		return false
+	}
+	return util.Contains(VerifiableCredentialsV2Context, contexts)
+}
+
+// IssuerID returns the id of the credential's issuer, which is either a URI or an object containing an `id` property
+// https://www.w3.org/TR/2021/REC-vc-data-model-20211109/#issuer
+func (v *VerifiableCredential) IssuerID() (string, error) {
+	if issuer, ok := v.Issuer.(string); ok {
+		if issuer == "" {
+			return "", errors.New("issuer is empty")
+		}
+		return issuer, nil
+	}
+	issuerMap, err := util.ToJSONMap(v.Issuer)
+	if err != nil {
+		return "", errors.Wrap(err, "issuer must be a string or an object")
+	}
+	id, ok := issuerMap[VerifiableCredentialIDProperty].(string)
+	if !ok || id == "" {
+		return "", errors.New("issuer object did not contain `id` property")
+	}
+	return id, nil
+}
+
+// IsActive checks that the given time is within the validity period of the credential, tolerating the given clock
+// skew on either end. The period starts at the issuanceDate or validFrom, and ends at the expirationDate or
+// validUntil; a credential without either of the latter never expires. It returns ErrNotYetValid if the period starts
+// more than skew after now, and ErrExpired if it ended more than skew before now.
+func (v *VerifiableCredential) IsActive(now time.Time, skew time.Duration) error {
+	starts := []validityDate{{"issuanceDate", v.IssuanceDate}, {"validFrom", v.ValidFrom}}
+	for _, start := range starts {
+		from, err := start.parse()
+		if err != nil {
+			return err
+		}
+		if from != nil && from.After(now.Add(skew)) {
+			return errors.Wrapf(ErrNotYetValid, "%s is %s", start.property, start.value)
+		}
+	}
+	ends := []validityDate{{"expirationDate", v.ExpirationDate}, {"validUntil", v.ValidUntil}}
+	for _, end := range ends {
+		until, err := end.parse()
+		if err != nil {
+			return err
+		}
+		if until != nil && until.Before(now.Add(-skew)) {
+			return errors.Wrapf(ErrExpired, "%s is %s", end.property, end.value)
+		}
+	}
+	return nil
+}
+
+type validityDate struct {
+	property string
+	value    string
+}
+
+// parse returns nil for an unset date
+func (d validityDate) parse() (*time.Time, error) {
+	if d.value == "" {
+		return nil, nil
+	}
+	t, err := time.Parse(time.RFC3339, d.value)
+	if err != nil {
+		return nil, errors.Wrapf(err, "parsing %s", d.property)
+	}
+	return &t, nil
 }
 
 // VerifiablePresentation https://www.w3.org/TR/2021/REC-vc-data-model-20211109/#presentations-0
@@ -3,6 +3,7 @@ package credential
 import (
 	"embed"
 	"testing"
+	"time"
 
 	"github.com/goccy/go-json"
 
@@ -16,14 +17,17 @@ const (
 	VCTestVector2 string = "vc-example-11.json"
 	VCTestVector3 string = "vc-example-20.json"
 	VCTestVector4 string = "vc-example-21.json"
-	VPTestVector1 string = "vp-example-2.json"
-	VPTestVector2 string = "vp-example-22.json"
+	// These test vectors are taken from the vc-data-model 2.0 spec examples https://www.w3.org/TR/vc-data-model-2.0/
+	VC2TestVector1 string = "vc-v2-example-simple.json"
+	VC2TestVector2 string = "vc-v2-example-issuer-object.json"
+	VPTestVector1  string = "vp-example-2.json"
+	VPTestVector2  string = "vp-example-22.json"
 )
 
 var (
 	//go:embed testdata
 	testVectors   embed.FS
-	vcTestVectors = []string{VCTestVector1, VCTestVector2, VCTestVector3, VCTestVector4}
+	vcTestVectors = []string{VCTestVector1, VCTestVector2, VCTestVector3, VCTestVector4, VC2TestVector1, VC2TestVector2}
 	vpTestVectors = []string{VPTestVector1, VPTestVector2}
 )
 
@@ -65,6 +69,262 @@ func TestVPVectors(t *testing.T) {
 	}
 }
 
+func TestVC2Compatibility(t *testing.T) {
+	t.Run("string issuer", func(tt *testing.T) {
+		gotTestVector, err := getTestVector(VC2TestVector1)
+		assert.NoError(tt, err)
+
+		var vc VerifiableCredential
+		assert.NoError(tt, json.Unmarshal([]byte(gotTestVector), &vc))
+		assert.NoError(tt, vc.IsValid())
+		assert.True(tt, vc.IsVersion2())
+		assert.Empty(tt, vc.IssuanceDate)
+		assert.Equal(tt, "2010-01-01T19:23:24Z", vc.ValidFrom)
+		issuerID, err := vc.IssuerID()
+		assert.NoError(tt, err)
+		assert.Equal(tt, "https://university.example/issuers/565049", issuerID)
+		assert.NoError(tt, vc.IsActive(time.Now(), 0))
+	})
+
+	t.Run("object issuer with a validity period", func(tt *testing.T) {
+		gotTestVector, err := getTestVector(VC2TestVector2)
+		assert.NoError(tt, err)
+
+		var vc VerifiableCredential
+		assert.NoError(tt, json.Unmarshal([]byte(gotTestVector), &vc))
+		assert.NoError(tt, vc.IsValid())
+		assert.True(tt, vc.IsVersion2())
+		issuerID, err := vc.IssuerID()
+		assert.NoError(tt, err)
+		assert.Equal(tt, "did:example:76e12ec712ebc6f1c221ebfeb1f", issuerID)
+		assert.NoError(tt, vc.IsActive(time.Date(2015, 1, 1, 0, 0, 0, 0, time.UTC), 0))
+		assert.ErrorIs(tt, vc.IsActive(time.Now(), 0), ErrExpired)
+	})
+
+	t.Run("issuance date is required without the 2.0 context", func(tt *testing.T) {
+		gotTestVector, err := getTestVector(VC2TestVector1)
+		assert.NoError(tt, err)
+
+		var vc VerifiableCredential
+		assert.NoError(tt, json.Unmarshal([]byte(gotTestVector), &vc))
+		vc.Context = []string{"https://www.w3.org/2018/credentials/v1"}
+		assert.False(tt, vc.IsVersion2())
+		assert.ErrorContains(tt, vc.IsValid(), "issuanceDate is required")
+	})
+
+}
+
+func TestIssuer(t *testing.T) {
+	t.Run("string issuer", func(tt *testing.T) {
+		var vc VerifiableCredential
+		err := json.Unmarshal([]byte(`{"issuer": "did:example:issuer"}`), &vc)
+		assert.NoError(tt, err)
+
+		issuerID, err := vc.IssuerID()
+		assert.NoError(tt, err)
+		assert.Equal(tt, "did:example:issuer", issuerID)
+
+		vcBytes, err := json.Marshal(vc)
+		assert.NoError(tt, err)
+		assert.Contains(tt, string(vcBytes), `"issuer":"did:example:issuer"`)
+	})
+
+	t.Run("object issuer with a name", func(tt *testing.T) {
+		issuerJSON := `{"id": "did:example:issuer", "name": "Example University", "image": {"id": "https://example.edu/logo.png"}}`
+		var vc VerifiableCredential
+		err := json.Unmarshal([]byte(`{"issuer": `+issuerJSON+`}`), &vc)
+		assert.NoError(tt, err)
+
+		issuerID, err := vc.IssuerID()
+		assert.NoError(tt, err)
+		assert.Equal(tt, "did:example:issuer", issuerID)
+
+		// all members of the issuer object are preserved
+		vcBytes, err := json.Marshal(vc)
+		assert.NoError(tt, err)
+		var roundTripped map[string]any
+		assert.NoError(tt, json.Unmarshal(vcBytes, &roundTripped))
+		var expectedIssuer map[string]any
+		assert.NoError(tt, json.Unmarshal([]byte(issuerJSON), &expectedIssuer))
+		assert.Equal(tt, expectedIssuer, roundTripped["issuer"])
+	})
+
+	t.Run("typed issuer object", func(tt *testing.T) {
+		vc := VerifiableCredential{Issuer: struct {
+			ID   string `json:"id"`
+			Name string `json:"name"`
+		}{ID: "did:example:issuer", Name: "Issuer"}}
+		issuerID, err := vc.IssuerID()
+		assert.NoError(tt, err)
+		assert.Equal(tt, "did:example:issuer", issuerID)
+	})
+
+	t.Run("invalid issuers", func(tt *testing.T) {
+		for _, issuer := range []any{nil, "", map[string]any{"name": "Issuer"}, map[string]any{"id": 1}, []string{"did:example:issuer"}} {
+			vc := VerifiableCredential{Issuer: issuer}
+			_, err := vc.IssuerID()
+			assert.Error(tt, err, issuer)
+		}
+	})
+}
+
+func TestCredentialSubjects(t *testing.T) {
+	t.Run("single subject", func(tt *testing.T) {
+		credJSON := `{"@context": "https://www.w3.org/2018/credentials/v1", "type": "VerifiableCredential", "issuer": "did:example:issuer", "credentialSubject": {"id": "did:example:alice", "address": {"locality": {"name": "Springfield"}}}}`
+		var vc VerifiableCredential
+		assert.NoError(tt, json.Unmarshal([]byte(credJSON), &vc))
+		assert.Equal(tt, "did:example:alice", vc.CredentialSubject.GetID())
+		assert.Empty(tt, vc.AdditionalSubjects)
+		subjects := vc.Subjects()
+		assert.Len(tt, subjects, 1)
+		assert.Equal(tt, "did:example:alice", subjects[0]["id"])
+
+		// a single subject is emitted as an object
+		vcBytes, err := json.Marshal(vc)
+		assert.NoError(tt, err)
+		assert.JSONEq(tt, credJSON, string(vcBytes))
+	})
+
+	t.Run("array of subjects", func(tt *testing.T) {
+		credJSON := `{
+			"@context": "https://www.w3.org/2018/credentials/v1",
+			"type": "VerifiableCredential",
+			"issuer": "did:example:issuer",
+			"credentialSubject": [
+				{"id": "did:example:alice", "spouse": {"id": "did:example:bob", "name": {"first": "Bob"}}},
+				{"id": "did:example:bob", "spouse": {"id": "did:example:alice", "name": {"first": "Alice"}}}
+			]
+		}`
+		var vc VerifiableCredential
+		assert.NoError(tt, json.Unmarshal([]byte(credJSON), &vc))
+		assert.Equal(tt, "did:example:alice", vc.CredentialSubject.GetID())
+		assert.Len(tt, vc.AdditionalSubjects, 1)
+		subjects := vc.Subjects()
+		assert.Len(tt, subjects, 2)
+		assert.Equal(tt, "did:example:alice", subjects[0]["id"])
+		assert.Equal(tt, "did:example:bob", subjects[1]["id"])
+
+		// nested objects are preserved, and the subjects are emitted as an array
+		vcBytes, err := json.Marshal(vc)
+		assert.NoError(tt, err)
+		assert.JSONEq(tt, credJSON, string(vcBytes))
+
+		// pointers marshal the same way
+		pointerBytes, err := json.Marshal(&vc)
+		assert.NoError(tt, err)
+		assert.JSONEq(tt, credJSON, string(pointerBytes))
+	})
+
+	t.Run("array with a single subject", func(tt *testing.T) {
+		var vc VerifiableCredential
+		assert.NoError(tt, json.Unmarshal([]byte(`{"credentialSubject": [{"id": "did:example:alice"}]}`), &vc))
+		assert.Len(tt, vc.Subjects(), 1)
+
+		// is emitted in its canonical single object form
+		vcBytes, err := json.Marshal(vc)
+		assert.NoError(tt, err)
+		assert.Contains(tt, string(vcBytes), `"credentialSubject":{"id":"did:example:alice"}`)
+	})
+
+	t.Run("no subject", func(tt *testing.T) {
+		var vc VerifiableCredential
+		assert.NoError(tt, json.Unmarshal([]byte(`{"issuer": "did:example:issuer"}`), &vc))
+		assert.Empty(tt, vc.Subjects())
+		assert.Error(tt, vc.IsValid())
+
+		assert.Error(tt, json.Unmarshal([]byte(`{"credentialSubject": "did:example:alice"}`), &vc))
+	})
+}
+
+func TestCredentialNumbers(t *testing.T) {
+	t.Run("large integer claim survives a round trip", func(tt *testing.T) {
+		credJSON := `{"@context": "https://www.w3.org/2018/credentials/v1", "type": "VerifiableCredential", "issuer": "did:example:issuer", "credentialSubject": {"id": "did:example:alice", "accountNumber": 1234567890123456789, "balance": 10.25}}`
+		var vc VerifiableCredential
+		assert.NoError(tt, json.Unmarshal([]byte(credJSON), &vc))
+		assert.Equal(tt, json.Number("1234567890123456789"), vc.CredentialSubject["accountNumber"])
+		assert.Equal(tt, json.Number("10.25"), vc.CredentialSubject["balance"])
+
+		vcBytes, err := json.Marshal(vc)
+		assert.NoError(tt, err)
+		assert.Contains(tt, string(vcBytes), `"accountNumber":1234567890123456789`)
+		assert.JSONEq(tt, credJSON, string(vcBytes))
+	})
+
+	t.Run("numbers in each subject", func(tt *testing.T) {
+		credJSON := `{"credentialSubject": [{"id": "did:example:alice", "serial": 9007199254740993}, {"id": "did:example:bob", "serial": 9007199254740995}]}`
+		var vc VerifiableCredential
+		assert.NoError(tt, json.Unmarshal([]byte(credJSON), &vc))
+		assert.Equal(tt, json.Number("9007199254740993"), vc.Subjects()[0]["serial"])
+		assert.Equal(tt, json.Number("9007199254740995"), vc.Subjects()[1]["serial"])
+
+		vcBytes, err := json.Marshal(vc)
+		assert.NoError(tt, err)
+		assert.Contains(tt, string(vcBytes), `"serial":9007199254740993`)
+		assert.Contains(tt, string(vcBytes), `"serial":9007199254740995`)
+	})
+
+	t.Run("trailing data", func(tt *testing.T) {
+		var vc VerifiableCredential
+		assert.Error(tt, json.Unmarshal([]byte(`{"issuer": "did:example:issuer"} {}`), &vc))
+	})
+}
+
+func TestIsActive(t *testing.T) {
+	now := time.Date(2023, 1, 1, 12, 0, 0, 0, time.UTC)
+	skew := time.Minute
+
+	t.Run("no expiration never expires", func(tt *testing.T) {
+		cred := VerifiableCredential{IssuanceDate: "2010-01-01T19:23:24Z"}
+		assert.NoError(tt, cred.IsActive(now, skew))
+		assert.NoError(tt, cred.IsActive(now.AddDate(100, 0, 0), 0))
+	})
+
+	t.Run("issuance date", func(tt *testing.T) {
+		// exactly on the boundary is valid
+		cred := VerifiableCredential{IssuanceDate: "2023-01-01T12:01:00Z"}
+		assert.NoError(tt, cred.IsActive(now, skew))
+
+		cred.IssuanceDate = "2023-01-01T12:01:01Z"
+		err := cred.IsActive(now, skew)
+		assert.ErrorIs(tt, err, ErrNotYetValid)
+		assert.ErrorContains(tt, err, "issuanceDate is 2023-01-01T12:01:01Z")
+	})
+
+	t.Run("expiration date", func(tt *testing.T) {
+		// exactly on the boundary is valid
+		cred := VerifiableCredential{IssuanceDate: "2010-01-01T19:23:24Z", ExpirationDate: "2023-01-01T11:59:00Z"}
+		assert.NoError(tt, cred.IsActive(now, skew))
+
+		cred.ExpirationDate = "2023-01-01T11:58:59Z"
+		err := cred.IsActive(now, skew)
+		assert.ErrorIs(tt, err, ErrExpired)
+		assert.ErrorContains(tt, err, "expirationDate is 2023-01-01T11:58:59Z")
+
+		// without skew the expiration date is exact
+		cred.ExpirationDate = "2023-01-01T12:00:00Z"
+		assert.NoError(tt, cred.IsActive(now, 0))
+		assert.ErrorIs(tt, cred.IsActive(now.Add(time.Second), 0), ErrExpired)
+	})
+
+	t.Run("vc 2.0 validity period", func(tt *testing.T) {
+		var cred VerifiableCredential
+		err := json.Unmarshal([]byte(`{"validFrom": "2023-01-01T12:01:00Z", "validUntil": "2023-01-02T12:00:00+01:00"}`), &cred)
+		assert.NoError(tt, err)
+		assert.NoError(tt, cred.IsActive(now, skew))
+		assert.ErrorIs(tt, cred.IsActive(now.Add(-time.Second), skew), ErrNotYetValid)
+		assert.NoError(tt, cred.IsActive(now.Add(23*time.Hour), 0))
+		assert.ErrorIs(tt, cred.IsActive(now.Add(23*time.Hour+time.Second), 0), ErrExpired)
+	})
+
+	t.Run("unparseable dates", func(tt *testing.T) {
+		cred := VerifiableCredential{IssuanceDate: "01/01/2010"}
+		assert.ErrorContains(tt, cred.IsActive(now, skew), "parsing issuanceDate")
+
+		cred = VerifiableCredential{IssuanceDate: "2010-01-01T19:23:24Z", ValidUntil: "2030"}
+		assert.ErrorContains(tt, cred.IsActive(now, skew), "parsing validUntil")
+	})
+}
+
 func getTestVector(fileName string) (string, error) {
 	b, err := testVectors.ReadFile("testdata/" + fileName)
 	return string(b), err
@@ -0,0 +1,243 @@
+package credential
+
+import (
+	"context"
+	gocrypto "crypto"
+	"crypto/ed25519"
+	"fmt"
+	"reflect"
+	"strings"
+
+	"github.com/TBD54566975/ssi-sdk/crypto/jwx"
+	"github.com/TBD54566975/ssi-sdk/cryptosuite"
+	"github.com/TBD54566975/ssi-sdk/did"
+	"github.com/TBD54566975/ssi-sdk/util"
+	"github.com/goccy/go-json"
+	"github.com/pkg/errors"
+)
+
+// Format is the securing mechanism of a credential or presentation
+type Format string
+
+const (
+	// JWTFormat is a credential or presentation secured as a JWT
+	JWTFormat Format = "jwt"
+	// DataIntegrityFormat is a JSON-LD credential or presentation secured with one or more data integrity proofs
+	DataIntegrityFormat Format = "data-integrity"
+)
+
+// VerificationReport is the result of verifying a presentation and each of the credentials it contains
+type VerificationReport struct {
+	// Format is the format of the presentation, whose proof has been verified
+	Format Format
+	Holder string
+	// Credentials holds the result of each credential, in the order the presentation lists them
+	Credentials []CredentialVerificationResult
+}
+
+// Verified returns whether every credential of the presentation has been verified
+func (r VerificationReport) Verified() bool {
+	for _, result := range r.Credentials {
+		if !result.Verified {
+			return false
+		}
+	}
+	return true
+}
+
+// CredentialVerificationResult is the result of verifying a single credential of a presentation
+type CredentialVerificationResult struct {
+	ID       string
+	Format   Format
+	Verified bool
+	// Err is the reason the credential failed verification, if it did
+	Err error
+}
+
+// VerifyPresentation verifies a presentation, and each of the credentials it contains, whatever their format. The
+// presentation may be a VP JWT, or a JSON-LD presentation secured with data integrity proofs, given as an object or
+// its JSON. Its proof is verified with the key of its holder, resolved with the provided resolver, and an error is
+// returned if it is not valid. The presentation must be for the expected audience, in response to the expected nonce:
+// a VP JWT by its aud and nonce claims, and a JSON-LD presentation by the domain and challenge of an authentication
+// proof. Each credential is then verified according to its format: a string is verified as a VC JWT, and an object by
+// its data integrity proofs. The result of each credential is listed in the report, rather than failing the
+// presentation.
+func VerifyPresentation(ctx context.Context, vp any, resolver did.Resolver, expectedAudience, expectedNonce string) (*VerificationReport, error) {
+	if vp == nil {
+		return nil, errors.New("presentation cannot be empty")
+	}
+	if resolver == nil {
+		return nil, errors.New("resolver cannot be empty")
+	}
+	if expectedAudience == "" {
+		return nil, errors.New("expected audience cannot be empty")
+	}
+	if expectedNonce == "" {
+		return nil, errors.New("expected nonce cannot be empty")
+	}
+
+	var report VerificationReport
+	var presentation *VerifiablePresentation
+	switch typedVP := vp.(type) {
+	case []byte:
+		return VerifyPresentation(ctx, string(typedVP), resolver, expectedAudience, expectedNonce)
+	case string:
+		// could be a JSON-LD presentation
+		var vpMap map[string]any
+		if err := json.Unmarshal([]byte(typedVP), &vpMap); err == nil {
+			return VerifyPresentation(ctx, vpMap, resolver, expectedAudience, expectedNonce)
+		}
+
+		// could be a JWT
+		_, jwtVP, err := verifyPresentationJWTWithHolderKey(ctx, typedVP, resolver, expectedAudience, expectedNonce)
+		if err != nil {
+			return nil, err
+		}
+		report.Format = JWTFormat
+		presentation = jwtVP
+	case VerifiablePresentation, *VerifiablePresentation, map[string]any:
+		vpMap, err := util.ToJSONMap(typedVP)
+		if err != nil {
+			return nil, errors.Wrap(err, "converting presentation")
+		}
+		vpBytes, err := json.Marshal(vpMap)
+		if err != nil {
+			return nil, errors.Wrap(err, "marshalling presentation")
+		}
+		var ldVP VerifiablePresentation
+		if err = json.Unmarshal(vpBytes, &ldVP); err != nil {
+			return nil, errors.Wrap(err, "parsing presentation")
+		}
+		if ldVP.Holder == "" {
+			return nil, errors.New("presentation must have a holder")
+		}
+		// the holder proves control of its DID, for the verifier and in response to its challenge
+		proofOpts := []cryptosuite.SuiteOption{
+			cryptosuite.WithProofOptions(cryptosuite.ProofOptions{Challenge: expectedNonce, Domain: expectedAudience}),
+			cryptosuite.WithExpectedProofPurpose(ctx, cryptosuite.Authentication, relativeMethodAuthorizer{
+				controller: ldVP.Holder,
+				authorizer: did.NewProofPurposeAuthorizer(resolver),
+			}),
+		}
+		if err = verifyDataIntegrityProofs(ctx, vpMap, resolver, ldVP.Holder, proofOpts...); err != nil {
+			return nil, errors.Wrap(err, "verifying presentation")
+		}
+		report.Format = DataIntegrityFormat
+		presentation = &ldVP
+	default:
+		return nil, fmt.Errorf("invalid presentation type: %s", reflect.TypeOf(vp).Kind().String())
+	}
+
+	report.Holder = presentation.Holder
+	report.Credentials = make([]CredentialVerificationResult, 0, len(presentation.VerifiableCredential))
+	for i, cred := range presentation.VerifiableCredential {
+		result := verifyPresentedCredential(ctx, cred, resolver)
+		if result.Err != nil {
+			result.Err = errors.Wrapf(result.Err, "verifying credential %d", i)
+		}
+		report.Credentials = append(report.Credentials, result)
+	}
+	return &report, nil
+}
+
+// verifyPresentedCredential detects the format of a credential in a presentation, and verifies it accordingly
+func verifyPresentedCredential(ctx context.Context, cred any, resolver did.Resolver) CredentialVerificationResult {
+	if token, ok := cred.(string); ok {
+		result := CredentialVerificationResult{Format: JWTFormat}
+		verified, err := VerifyVerifiableCredentialJWTWithResolver(ctx, token, resolver)
+		if err != nil {
+			result.Err = err
+			return result
+		}
+		result.ID = verified.ID
+		result.Verified = true
+		return result
+	}
+
+	result := CredentialVerificationResult{Format: DataIntegrityFormat}
+	credMap, err := util.ToJSONMap(cred)
+	if err != nil {
+		result.Err = errors.Wrap(err, "converting credential")
+		return result
+	}
+	credBytes, err := json.Marshal(credMap)
+	if err != nil {
+		result.Err = errors.Wrap(err, "marshalling credential")
+		return result
+	}
+	var ldCred VerifiableCredential
+	if err = json.Unmarshal(credBytes, &ldCred); err != nil {
+		result.Err = errors.Wrap(err, "parsing credential")
+		return result
+	}
+	result.ID = ldCred.ID
+	if err = verifyDataIntegrityCredential(ctx, credMap, ldCred, resolver); err != nil {
+		result.Err = err
+		return result
+	}
+	result.Verified = true
+	return result
+}
+
+// verifyDataIntegrityProofs verifies every proof of a document, each of which must be made with a key of the given
+// controller. The key of each proof's verification method is resolved with the provided resolver.
+func verifyDataIntegrityProofs(ctx context.Context, doc map[string]any, resolver did.Resolver, controller string, opts ...cryptosuite.SuiteOption) error {
+	proof, ok := doc["proof"]
+	if !ok || proof == nil {
+		return errors.New("document has no proof")
+	}
+	proofs, err := util.InterfaceToInterfaceArray(proof)
+	if err != nil {
+		return errors.Wrap(err, "getting proofs from document")
+	}
+
+	verifiers := make([]cryptosuite.Verifier, 0, len(proofs))
+	for i, p := range proofs {
+		proofMap, err := util.ToJSONMap(p)
+		if err != nil {
+			return errors.Wrapf(err, "converting proof %d", i)
+		}
+		verificationMethod, _ := proofMap["verificationMethod"].(string)
+		if kidDID, _, found := strings.Cut(verificationMethod, "#"); !found || kidDID != "" && kidDID != controller {
+			return errors.Errorf("verification method<%s> of proof %d is not a key of<%s>", verificationMethod, i, controller)
+		}
+		pubKey, err := did.ResolveKeyForDID(ctx, resolver, controller, verificationMethod)
+		if err != nil {
+			return errors.Wrapf(err, "getting key to verify proof %d", i)
+		}
+		proofType, _ := proofMap["type"].(string)
+		verifier, err := dataIntegrityVerifier(cryptosuite.SignatureType(proofType), verificationMethod, pubKey)
+		if err != nil {
+			return errors.Wrapf(err, "constructing verifier for proof %d", i)
+		}
+		verifiers = append(verifiers, verifier)
+	}
+	return cryptosuite.VerifyProofSet(doc, verifiers, opts...)
+}
+
+// relativeMethodAuthorizer authorizes the verification methods of proofs made with a key of the given controller,
+// whose methods may be referenced relative to its DID, such as the #z6Mk... methods of a did:key
+type relativeMethodAuthorizer struct {
+	controller string
+	authorizer cryptosuite.VerificationMethodAuthorizer
+}
+
+func (a relativeMethodAuthorizer) IsAuthorized(ctx context.Context, verificationMethod string, purpose cryptosuite.ProofPurpose) (bool, error) {
+	if strings.HasPrefix(verificationMethod, "#") {
+		verificationMethod = a.controller + verificationMethod
+	}
+	return a.authorizer.IsAuthorized(ctx, verificationMethod, purpose)
+}
+
+// dataIntegrityVerifier returns a verifier for the resolved key of a proof. JsonWebSignature2020 proofs are detached
+// JWS, verified with the key as a JWK, while the Ed25519 keys of other proofs verify raw signatures.
+func dataIntegrityVerifier(proofType cryptosuite.SignatureType, kid string, pubKey gocrypto.PublicKey) (cryptosuite.Verifier, error) {
+	if edKey, ok := pubKey.(ed25519.PublicKey); ok && proofType != cryptosuite.JSONWebSignature2020 {
+		return cryptosuite.NewEd25519Verifier(kid, edKey), nil
+	}
+	pubKeyJWK, err := jwx.PublicKeyToPublicKeyJWK(pubKey)
+	if err != nil {
+		return nil, errors.Wrap(err, "converting key to JWK")
+	}
+	return cryptosuite.NewJSONWebKeyVerifier(kid, *pubKeyJWK)
+}
@@ -0,0 +1,194 @@
+//go:build jwx_es256k
+
+package credential
+
+import (
+	"context"
+	"crypto/ed25519"
+	"testing"
+	"time"
+
+	"github.com/TBD54566975/ssi-sdk/crypto"
+	"github.com/TBD54566975/ssi-sdk/cryptosuite"
+	"github.com/TBD54566975/ssi-sdk/did"
+	"github.com/TBD54566975/ssi-sdk/util"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestVerifyPresentation(t *testing.T) {
+	resolver, err := did.NewResolver([]did.Resolver{did.KeyResolver{}}...)
+	require.NoError(t, err)
+
+	holderSigner, holderDID := getTestLDSigner(t)
+	holderSigner.SetProofPurpose(cryptosuite.Authentication)
+	audience, nonce := "did:example:verifier", uuid.NewString()
+	jwtIssuerSigner, jwtIssuerDID := getTestDIDKeySigner(t)
+	ldIssuerSigner, ldIssuerDID := getTestLDSigner(t)
+	newVC := func(issuer string) VerifiableCredential {
+		return VerifiableCredential{
+			ID:           "urn:uuid:" + uuid.NewString(),
+			Context:      []any{"https://www.w3.org/2018/credentials/v1"},
+			Type:         []any{"VerifiableCredential"},
+			Issuer:       issuer,
+			IssuanceDate: time.Now().UTC().Format(time.RFC3339),
+			CredentialSubject: map[string]any{
+				"id": holderDID,
+			},
+		}
+	}
+
+	jwtVC := newVC(jwtIssuerDID)
+	signedJWTVC, err := SignVerifiableCredentialJWT(jwtIssuerSigner, jwtVC)
+	require.NoError(t, err)
+
+	suite := cryptosuite.GetEd25519Signature2020Suite()
+	ldVC := newVC(ldIssuerDID)
+	require.NoError(t, suite.Sign(ldIssuerSigner, &ldVC))
+
+	holderSuite := cryptosuite.GetEd25519Signature2020Suite(cryptosuite.WithProofOptions(cryptosuite.ProofOptions{Challenge: nonce, Domain: audience}))
+	signVPWith := func(tt *testing.T, holderSuite cryptosuite.CryptoSuite, holderSigner cryptosuite.Signer, creds ...any) map[string]any {
+		vp := VerifiablePresentation{
+			Context:              []any{"https://www.w3.org/2018/credentials/v1"},
+			ID:                   "urn:uuid:" + uuid.NewString(),
+			Type:                 []any{"VerifiablePresentation"},
+			Holder:               holderDID,
+			VerifiableCredential: creds,
+		}
+		require.NoError(tt, holderSuite.Sign(holderSigner, &vp))
+		vpMap, err := util.ToJSONMap(vp)
+		require.NoError(tt, err)
+		return vpMap
+	}
+	signVP := func(tt *testing.T, creds ...any) map[string]any {
+		return signVPWith(tt, holderSuite, holderSigner, creds...)
+	}
+
+	t.Run("JSON-LD presentation with a JWT and an Ed25519Signature2020 credential", func(tt *testing.T) {
+		vp := signVP(tt, string(signedJWTVC), ldVC)
+
+		report, err := VerifyPresentation(context.Background(), vp, resolver, audience, nonce)
+		require.NoError(tt, err)
+		assert.Equal(tt, DataIntegrityFormat, report.Format)
+		assert.Equal(tt, holderDID, report.Holder)
+		assert.True(tt, report.Verified())
+		require.Len(tt, report.Credentials, 2)
+
+		assert.Equal(tt, jwtVC.ID, report.Credentials[0].ID)
+		assert.Equal(tt, JWTFormat, report.Credentials[0].Format)
+		assert.True(tt, report.Credentials[0].Verified)
+		assert.NoError(tt, report.Credentials[0].Err)
+
+		assert.Equal(tt, ldVC.ID, report.Credentials[1].ID)
+		assert.Equal(tt, DataIntegrityFormat, report.Credentials[1].Format)
+		assert.True(tt, report.Credentials[1].Verified)
+		assert.NoError(tt, report.Credentials[1].Err)
+	})
+
+	t.Run("tampered Ed25519Signature2020 credential", func(tt *testing.T) {
+		tampered := ldVC
+		tampered.CredentialSubject = map[string]any{"id": jwtIssuerDID}
+		vp := signVP(tt, string(signedJWTVC), tampered)
+
+		report, err := VerifyPresentation(context.Background(), vp, resolver, audience, nonce)
+		require.NoError(tt, err)
+		assert.False(tt, report.Verified())
+		require.Len(tt, report.Credentials, 2)
+		assert.True(tt, report.Credentials[0].Verified)
+		assert.False(tt, report.Credentials[1].Verified)
+		assert.ErrorContains(tt, report.Credentials[1].Err, "verifying credential 1")
+	})
+
+	t.Run("tampered presentation", func(tt *testing.T) {
+		vp := signVP(tt, string(signedJWTVC), ldVC)
+		vp["id"] = "urn:uuid:" + uuid.NewString()
+
+		_, err := VerifyPresentation(context.Background(), vp, resolver, audience, nonce)
+		assert.ErrorContains(tt, err, "verifying presentation")
+	})
+
+	t.Run("presentation for another verifier or challenge", func(tt *testing.T) {
+		vp := signVP(tt, string(signedJWTVC), ldVC)
+
+		_, err := VerifyPresentation(context.Background(), vp, resolver, audience, uuid.NewString())
+		assert.ErrorContains(tt, err, "does not match expected challenge")
+
+		_, err = VerifyPresentation(context.Background(), vp, resolver, "did:example:other", nonce)
+		assert.ErrorContains(tt, err, "does not match expected domain")
+
+		_, err = VerifyPresentation(context.Background(), vp, resolver, audience, "")
+		assert.ErrorContains(tt, err, "expected nonce cannot be empty")
+	})
+
+	t.Run("presentation proof not made for authentication", func(tt *testing.T) {
+		assertionSuite := cryptosuite.GetEd25519Signature2020Suite(cryptosuite.WithProofOptions(cryptosuite.ProofOptions{
+			Challenge:    nonce,
+			Domain:       audience,
+			ProofPurpose: cryptosuite.AssertionMethod,
+		}))
+		vp := signVPWith(tt, assertionSuite, holderSigner, ldVC)
+
+		_, err := VerifyPresentation(context.Background(), vp, resolver, audience, nonce)
+		assert.ErrorIs(tt, err, cryptosuite.ErrUnauthorizedProofPurpose)
+	})
+
+	t.Run("embedded JWT credentials are resolved with the context", func(tt *testing.T) {
+		vp := signVP(tt, string(signedJWTVC))
+		type ctxKey struct{}
+		ctx := context.WithValue(context.Background(), ctxKey{}, "verification")
+		recording := &contextRecordingResolver{Resolver: resolver, contexts: make(map[string]context.Context)}
+
+		report, err := VerifyPresentation(ctx, vp, recording, audience, nonce)
+		require.NoError(tt, err)
+		assert.True(tt, report.Verified())
+		require.Contains(tt, recording.contexts, jwtIssuerDID)
+		assert.Equal(tt, "verification", recording.contexts[jwtIssuerDID].Value(ctxKey{}))
+	})
+
+	t.Run("JWT presentation", func(tt *testing.T) {
+		jwtHolderSigner, jwtHolderDID := getTestDIDKeySigner(tt)
+		vp := VerifiablePresentation{
+			Context:              []string{"https://www.w3.org/2018/credentials/v1"},
+			Type:                 []string{"VerifiablePresentation"},
+			Holder:               jwtHolderDID,
+			VerifiableCredential: []any{string(signedJWTVC), ldVC},
+		}
+		signed, err := SignVerifiablePresentationJWT(jwtHolderSigner, JWTVVPParameters{Audience: audience, Nonce: nonce}, vp)
+		require.NoError(tt, err)
+
+		report, err := VerifyPresentation(context.Background(), signed, resolver, audience, nonce)
+		require.NoError(tt, err)
+		assert.Equal(tt, JWTFormat, report.Format)
+		assert.Equal(tt, jwtHolderDID, report.Holder)
+		assert.True(tt, report.Verified())
+		require.Len(tt, report.Credentials, 2)
+		assert.Equal(tt, JWTFormat, report.Credentials[0].Format)
+		assert.Equal(tt, DataIntegrityFormat, report.Credentials[1].Format)
+
+		_, err = VerifyPresentation(context.Background(), signed, resolver, "did:example:other", nonce)
+		assert.ErrorContains(tt, err, "audience mismatch")
+
+		_, err = VerifyPresentation(context.Background(), signed, resolver, audience, uuid.NewString())
+		assert.ErrorContains(tt, err, "nonce mismatch")
+	})
+}
+
+// contextRecordingResolver resolves DIDs with the wrapped resolver, recording the context each DID was resolved with
+type contextRecordingResolver struct {
+	did.Resolver
+	contexts map[string]context.Context
+}
+
+func (r *contextRecordingResolver) Resolve(ctx context.Context, id string, opts ...did.ResolutionOption) (*did.ResolutionResult, error) {
+	r.contexts[id] = ctx
+	return r.Resolver.Resolve(ctx, id, opts...)
+}
+
+func getTestLDSigner(t *testing.T) (*cryptosuite.Ed25519Signer, string) {
+	privKey, didKey, err := did.GenerateDIDKey(crypto.Ed25519)
+	require.NoError(t, err)
+	expanded, err := didKey.Expand()
+	require.NoError(t, err)
+	return cryptosuite.NewEd25519Signer(expanded.VerificationMethod[0].ID, privKey.(ed25519.PrivateKey), cryptosuite.AssertionMethod), didKey.String()
+}
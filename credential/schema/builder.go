@@ -0,0 +1,107 @@
+package schema
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/pkg/errors"
+
+	"github.com/TBD54566975/ssi-sdk/credential"
+)
+
+const (
+	// CredentialSchemaJSONSchemaDraft is the JSON Schema draft of the schemas built by NewCredentialSchema
+	CredentialSchemaJSONSchemaDraft string = "https://json-schema.org/draft/2020-12/schema"
+
+	metadataProperty = "$metadata"
+	schemaProperty   = "$schema"
+
+	// didPattern matches a DID, used for a required credentialSubject.id
+	didPattern = "^did:[a-z0-9]+:.+$"
+)
+
+// Property describes a single credentialSubject property of a credential schema
+type Property struct {
+	Type        string `json:"type,omitempty"`
+	Format      string `json:"format,omitempty"`
+	Pattern     string `json:"pattern,omitempty"`
+	Description string `json:"description,omitempty"`
+	// Required marks the property as one every credential subject must have
+	Required bool `json:"-"`
+}
+
+// CredentialSchemaMetadata is the $metadata of a credential schema
+// https://w3c-ccg.github.io/vc-json-schemas/v1/index.html#metadata
+type CredentialSchemaMetadata struct {
+	Version string `json:"version"`
+	Name    string `json:"name"`
+}
+
+// NewCredentialSchema builds a credential schema with the given name and version, whose credentialSubject has the
+// given properties. A required id property of the credentialSubject must be a DID.
+// https://w3c-ccg.github.io/vc-json-schemas/v1/index.html
+func NewCredentialSchema(name, version string, props map[string]Property) (JSONSchema, error) {
+	if name == "" {
+		return nil, errors.New("credential schema name cannot be empty")
+	}
+	if version == "" {
+		return nil, errors.New("credential schema version cannot be empty")
+	}
+	if len(props) == 0 {
+		return nil, errors.New("credential schema must have at least one property")
+	}
+
+	properties := make(map[string]any, len(props))
+	required := make([]string, 0, len(props))
+	for propName, prop := range props {
+		if propName == "" {
+			return nil, errors.New("credential schema property name cannot be empty")
+		}
+		if propName == credential.VerifiableCredentialIDProperty && prop.Required {
+			if prop.Type != "" && prop.Type != "string" {
+				return nil, fmt.Errorf("credential schema property<%s> must be a string", propName)
+			}
+			prop.Type = "string"
+			prop.Pattern = didPattern
+		}
+		propJSON := make(map[string]any)
+		if prop.Type != "" {
+			propJSON["type"] = prop.Type
+		}
+		if prop.Format != "" {
+			propJSON["format"] = prop.Format
+		}
+		if prop.Pattern != "" {
+			propJSON["pattern"] = prop.Pattern
+		}
+		if prop.Description != "" {
+			propJSON["description"] = prop.Description
+		}
+		properties[propName] = propJSON
+		if prop.Required {
+			required = append(required, propName)
+		}
+	}
+	sort.Strings(required)
+
+	credentialSubject := map[string]any{
+		"type":       "object",
+		"properties": properties,
+	}
+	if len(required) > 0 {
+		credentialSubject["required"] = required
+	}
+	return JSONSchema{
+		schemaProperty: CredentialSchemaJSONSchemaDraft,
+		metadataProperty: map[string]any{
+			"version": version,
+			"name":    name,
+		},
+		"title": name,
+		"type":  "object",
+		"properties": map[string]any{
+			credentialSubjectProperty: credentialSubject,
+		},
+		"required": []string{credentialSubjectProperty},
+	}, nil
+}
@@ -0,0 +1,44 @@
+package schema
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewCredentialSchema(t *testing.T) {
+	t.Run("happy path", func(tt *testing.T) {
+		credentialSchema, err := NewCredentialSchema("Email", "1.0", map[string]Property{
+			"id":           {Required: true},
+			"emailAddress": {Type: "string", Format: "email", Required: true},
+			"nickname":     {Type: "string", Description: "what to call the subject"},
+		})
+		require.NoError(tt, err)
+		assert.Equal(tt, CredentialSchemaJSONSchemaDraft, credentialSchema["$schema"])
+		assert.Equal(tt, map[string]any{"version": "1.0", "name": "Email"}, credentialSchema["$metadata"])
+		assert.Equal(tt, "Email", credentialSchema["title"])
+
+		subjectSchema := credentialSchema["properties"].(map[string]any)["credentialSubject"].(map[string]any)
+		assert.Equal(tt, []string{"emailAddress", "id"}, subjectSchema["required"])
+		properties := subjectSchema["properties"].(map[string]any)
+		assert.Equal(tt, map[string]any{"type": "string", "pattern": didPattern}, properties["id"])
+		assert.Equal(tt, map[string]any{"type": "string", "format": "email"}, properties["emailAddress"])
+		assert.Equal(tt, map[string]any{"type": "string", "description": "what to call the subject"}, properties["nickname"])
+	})
+
+	t.Run("invalid inputs", func(tt *testing.T) {
+		props := map[string]Property{"emailAddress": {Type: "string"}}
+		_, err := NewCredentialSchema("", "1.0", props)
+		assert.ErrorContains(tt, err, "name cannot be empty")
+
+		_, err = NewCredentialSchema("Email", "", props)
+		assert.ErrorContains(tt, err, "version cannot be empty")
+
+		_, err = NewCredentialSchema("Email", "1.0", nil)
+		assert.ErrorContains(tt, err, "at least one property")
+
+		_, err = NewCredentialSchema("Email", "1.0", map[string]Property{"id": {Type: "integer", Required: true}})
+		assert.ErrorContains(tt, err, "credential schema property<id> must be a string")
+	})
+}
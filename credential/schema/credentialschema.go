@@ -0,0 +1,210 @@
+package schema
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"github.com/goccy/go-json"
+	"github.com/pkg/errors"
+	"github.com/santhosh-tekuri/jsonschema/v5"
+
+	"github.com/TBD54566975/ssi-sdk/credential"
+	"github.com/TBD54566975/ssi-sdk/util"
+)
+
+const (
+	// defaultSchemaURL is the location a fetched schema is compiled at when its id is not an http(s) URL
+	defaultSchemaURL = "schema.json"
+	// credentialSubjectProperty is the property a schema describing the whole credential defines
+	credentialSubjectProperty = "credentialSubject"
+)
+
+// ValidateCredentialAgainstSchema validates a credential against the JSON Schema referenced by its credentialSchema
+// property, which must be of type JsonSchema or JsonSchemaValidator2018. The schema, or a VC JSON Schema wrapping
+// it, is retrieved with the given fetch function, as are any schemas it references. Both draft-07 and 2020-12
+// schemas are supported, by their $schema property, with 2020-12 the default.
+// A schema whose properties include credentialSubject validates the whole credential; any other schema validates
+// each of the credential's subjects, without their id property. All validation failures are returned together,
+// each with the JSON path of the value it applies to.
+func ValidateCredentialAgainstSchema(vc credential.VerifiableCredential, fetch func(url string) ([]byte, error)) error {
+	if fetch == nil {
+		return errors.New("fetch function cannot be empty")
+	}
+	credSchema, err := getCredentialSchema(vc)
+	if err != nil {
+		return err
+	}
+	compiled, err := loadCredentialSchema(credSchema.ID, fetch)
+	if err != nil {
+		return err
+	}
+	return compiled.validate(vc)
+}
+
+// compiledCredentialSchema is a compiled credential schema, ready to validate credentials
+type compiledCredentialSchema struct {
+	id                  string
+	schema              *jsonschema.Schema
+	describesCredential bool
+}
+
+// getCredentialSchema returns the credentialSchema property of a credential, if it is of a supported type
+func getCredentialSchema(vc credential.VerifiableCredential) (*credential.CredentialSchema, error) {
+	if vc.CredentialSchema == nil {
+		return nil, errors.New("credential does not have a credentialSchema property")
+	}
+	credSchema := vc.CredentialSchema
+	if credSchema.Type != JSONSchemaType && credSchema.Type != JSONSchemaValidator2018Type {
+		return nil, fmt.Errorf("unsupported credential schema type: %s", credSchema.Type)
+	}
+	return credSchema, nil
+}
+
+// loadCredentialSchema fetches and compiles the credential schema with the given id
+func loadCredentialSchema(id string, fetch func(url string) ([]byte, error)) (*compiledCredentialSchema, error) {
+	schemaBytes, err := fetch(id)
+	if err != nil {
+		return nil, errors.Wrapf(err, "fetching credential schema<%s>", id)
+	}
+	jsonSchema, err := getJSONSchema(schemaBytes)
+	if err != nil {
+		return nil, errors.Wrapf(err, "credential schema<%s> is not a valid JSON Schema", id)
+	}
+	compiled, err := compileJSONSchema(id, jsonSchema, fetch)
+	if err != nil {
+		return nil, errors.Wrapf(err, "compiling credential schema<%s>", id)
+	}
+	return &compiledCredentialSchema{id: id, schema: compiled, describesCredential: describesCredential(jsonSchema)}, nil
+}
+
+// validate validates a credential against the schema, returning all validation failures together
+func (c compiledCredentialSchema) validate(vc credential.VerifiableCredential) error {
+	var problems []string
+	if c.describesCredential {
+		credJSON, err := util.AnyToJSONInterface(vc)
+		if err != nil {
+			return errors.Wrap(err, "could not convert credential to JSON")
+		}
+		problems = validationProblems(c.schema.Validate(credJSON), "$")
+	} else {
+		subjects := vc.Subjects()
+		for i, subject := range subjects {
+			withoutID := make(map[string]any, len(subject))
+			for k, v := range subject {
+				if k != credential.VerifiableCredentialIDProperty {
+					withoutID[k] = v
+				}
+			}
+			subjectJSON, err := util.AnyToJSONInterface(withoutID)
+			if err != nil {
+				return errors.Wrap(err, "could not convert credential subject to JSON")
+			}
+			root := "$." + credentialSubjectProperty
+			if len(subjects) > 1 {
+				root += "[" + strconv.Itoa(i) + "]"
+			}
+			problems = append(problems, validationProblems(c.schema.Validate(subjectJSON), root)...)
+		}
+	}
+	if len(problems) > 0 {
+		return fmt.Errorf("credential not valid for schema<%s>: %d problem(s): %s", c.id, len(problems),
+			strings.Join(problems, "; "))
+	}
+	return nil
+}
+
+// getJSONSchema returns the JSON Schema of a fetched schema, unwrapping it from a VC JSON Schema if need be
+func getJSONSchema(schemaBytes []byte) (map[string]any, error) {
+	var jsonSchema map[string]any
+	if err := json.Unmarshal(schemaBytes, &jsonSchema); err != nil {
+		return nil, errors.Wrap(err, "could not unmarshal schema")
+	}
+	if jsonSchema["type"] == VCJSONSchemaType {
+		wrapped, ok := jsonSchema["schema"].(map[string]any)
+		if !ok {
+			return nil, errors.New("vc json schema did not contain a schema property")
+		}
+		return wrapped, nil
+	}
+	return jsonSchema, nil
+}
+
+// compileJSONSchema compiles a JSON Schema, loading any schemas it references with the given fetch function
+func compileJSONSchema(id string, jsonSchema map[string]any, fetch func(url string) ([]byte, error)) (*jsonschema.Schema, error) {
+	schemaURL := defaultSchemaURL
+	if parsed, err := url.Parse(id); err == nil && (parsed.Scheme == "http" || parsed.Scheme == "https") {
+		schemaURL = id
+	}
+	schemaBytes, err := json.Marshal(jsonSchema)
+	if err != nil {
+		return nil, errors.Wrap(err, "could not marshal schema")
+	}
+	compiler := jsonschema.NewCompiler()
+	compiler.LoadURL = func(s string) (io.ReadCloser, error) {
+		referenced, err := fetch(s)
+		if err != nil {
+			return nil, errors.Wrapf(err, "fetching referenced schema<%s>", s)
+		}
+		return io.NopCloser(bytes.NewReader(referenced)), nil
+	}
+	if err = compiler.AddResource(schemaURL, bytes.NewReader(schemaBytes)); err != nil {
+		return nil, err
+	}
+	return compiler.Compile(schemaURL)
+}
+
+// describesCredential determines whether a schema describes a whole credential, rather than a credential subject
+func describesCredential(jsonSchema map[string]any) bool {
+	properties, ok := jsonSchema["properties"].(map[string]any)
+	if !ok {
+		return false
+	}
+	_, ok = properties[credentialSubjectProperty]
+	return ok
+}
+
+// validationProblems flattens a validation error into the failures of its leaf errors, each prefixed with the JSON
+// path of the value it applies to, relative to the given root path
+func validationProblems(err error, root string) []string {
+	if err == nil {
+		return nil
+	}
+	var validationErr *jsonschema.ValidationError
+	if !errors.As(err, &validationErr) {
+		return []string{root + ": " + err.Error()}
+	}
+	var problems []string
+	var walk func(*jsonschema.ValidationError)
+	walk = func(ve *jsonschema.ValidationError) {
+		if len(ve.Causes) == 0 {
+			problems = append(problems, jsonPointerToPath(root, ve.InstanceLocation)+": "+ve.Message)
+			return
+		}
+		for _, cause := range ve.Causes {
+			walk(cause)
+		}
+	}
+	walk(validationErr)
+	return problems
+}
+
+// jsonPointerToPath converts a JSON pointer https://www.rfc-editor.org/rfc/rfc6901 to a JSON path below a root path
+func jsonPointerToPath(root, pointer string) string {
+	path := root
+	if pointer == "" {
+		return path
+	}
+	for _, token := range strings.Split(strings.TrimPrefix(pointer, "/"), "/") {
+		token = strings.ReplaceAll(strings.ReplaceAll(token, "~1", "/"), "~0", "~")
+		if _, err := strconv.Atoi(token); err == nil {
+			path += "[" + token + "]"
+		} else {
+			path += "." + token
+		}
+	}
+	return path
+}
@@ -0,0 +1,127 @@
+package schema
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/goccy/go-json"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	vc "github.com/TBD54566975/ssi-sdk/credential"
+)
+
+func TestValidateCredentialAgainstSchema(t *testing.T) {
+	credentialSchema2020 := `{
+  "$schema": "https://json-schema.org/draft/2020-12/schema",
+  "type": "object",
+  "properties": {
+    "issuer": {"type": "string"},
+    "credentialSubject": {
+      "type": "object",
+      "properties": {
+        "emailAddress": {"type": "string", "pattern": "^[^@]+@[^@]+$"},
+        "name": {"type": "string"}
+      },
+      "required": ["emailAddress", "name"]
+    }
+  },
+  "required": ["issuer", "credentialSubject"]
+}`
+	subjectSchemaDraft07 := `{
+  "$schema": "http://json-schema.org/draft-07/schema#",
+  "type": "object",
+  "properties": {
+    "emailAddress": {"type": "string", "pattern": "^[^@]+@[^@]+$"}
+  },
+  "required": ["emailAddress"],
+  "additionalProperties": false
+}`
+	schemas := map[string]string{
+		"https://example.com/schemas/credential.json": credentialSchema2020,
+		"https://example.com/schemas/subject.json":    subjectSchemaDraft07,
+	}
+	fetch := func(url string) ([]byte, error) {
+		s, ok := schemas[url]
+		if !ok {
+			return nil, errors.New("not found")
+		}
+		return []byte(s), nil
+	}
+	getCred := func(schemaID string, subject map[string]any) vc.VerifiableCredential {
+		return vc.VerifiableCredential{
+			Context:           []any{vc.VerifiableCredentialsLinkedDataContext},
+			Type:              []any{vc.VerifiableCredentialType},
+			Issuer:            "did:example:issuer",
+			IssuanceDate:      "2021-01-01T00:00:00Z",
+			CredentialSchema:  &vc.CredentialSchema{ID: schemaID, Type: JSONSchemaType},
+			CredentialSubject: subject,
+		}
+	}
+
+	t.Run("vc json schema", func(tt *testing.T) {
+		credential, err := getTestVector(vcJSONCredentialTestVector1)
+		require.NoError(tt, err)
+		var cred vc.VerifiableCredential
+		require.NoError(tt, json.Unmarshal([]byte(credential), &cred))
+		vcJSONSchema, err := getTestVector(vcJSONTestVector1)
+		require.NoError(tt, err)
+
+		assert.NoError(tt, ValidateCredentialAgainstSchema(cred, func(string) ([]byte, error) {
+			return []byte(vcJSONSchema), nil
+		}))
+	})
+
+	t.Run("2020-12 credential schema", func(tt *testing.T) {
+		cred := getCred("https://example.com/schemas/credential.json", map[string]any{
+			"id":           "did:example:subject",
+			"emailAddress": "first.last@example.com",
+			"name":         "First Last",
+		})
+		assert.NoError(tt, ValidateCredentialAgainstSchema(cred, fetch))
+
+		cred = getCred("https://example.com/schemas/credential.json", map[string]any{
+			"id":           "did:example:subject",
+			"emailAddress": "first.last",
+		})
+		err := ValidateCredentialAgainstSchema(cred, fetch)
+		assert.ErrorContains(tt, err, "2 problem(s)")
+		assert.ErrorContains(tt, err, "$.credentialSubject: missing properties: 'name'")
+		assert.ErrorContains(tt, err, "$.credentialSubject.emailAddress: does not match pattern")
+	})
+
+	t.Run("draft-07 subject schema", func(tt *testing.T) {
+		cred := getCred("https://example.com/schemas/subject.json", map[string]any{
+			"id":           "did:example:subject",
+			"emailAddress": "first.last@example.com",
+		})
+		assert.NoError(tt, ValidateCredentialAgainstSchema(cred, fetch))
+
+		cred.CredentialSubject["emailAddress"] = "first.last"
+		err := ValidateCredentialAgainstSchema(cred, fetch)
+		assert.ErrorContains(tt, err, "1 problem(s)")
+		assert.ErrorContains(tt, err, "$.credentialSubject.emailAddress: does not match pattern")
+
+		// each subject is validated
+		cred.CredentialSubject["emailAddress"] = "first.last@example.com"
+		cred.AdditionalSubjects = []vc.CredentialSubject{{"id": "did:example:other"}}
+		err = ValidateCredentialAgainstSchema(cred, fetch)
+		assert.ErrorContains(tt, err, "$.credentialSubject[1]: missing properties: 'emailAddress'")
+	})
+
+	t.Run("invalid credential schema", func(tt *testing.T) {
+		cred := getCred("https://example.com/schemas/subject.json", map[string]any{})
+		cred.CredentialSchema.Type = "ZkpExampleSchema2018"
+		assert.ErrorContains(tt, ValidateCredentialAgainstSchema(cred, fetch), "unsupported credential schema type")
+
+		cred.CredentialSchema = nil
+		assert.ErrorContains(tt, ValidateCredentialAgainstSchema(cred, fetch), "does not have a credentialSchema property")
+
+		cred = getCred("https://example.com/schemas/missing.json", map[string]any{})
+		assert.ErrorContains(tt, ValidateCredentialAgainstSchema(cred, fetch), "fetching credential schema<https://example.com/schemas/missing.json>: not found")
+
+		schemas["https://example.com/schemas/invalid.json"] = `{"type": "object", "required": "name"}`
+		cred = getCred("https://example.com/schemas/invalid.json", map[string]any{})
+		assert.ErrorContains(tt, ValidateCredentialAgainstSchema(cred, fetch), "compiling credential schema")
+	})
+}
@@ -0,0 +1,115 @@
+package schema
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"sync"
+
+	"github.com/pkg/errors"
+
+	"github.com/TBD54566975/ssi-sdk/credential"
+)
+
+// DefaultMaxSchemaSize is the maximum size, in bytes, of a schema fetched by a CachingRemoteSchemaLoader
+const DefaultMaxSchemaSize = 1 << 20
+
+// CachingRemoteSchemaLoader fetches credential schemas over HTTP, caching each by URL so a schema shared by a batch
+// of credentials is only retrieved once. Its Fetch method may be given to ValidateCredentialAgainstSchema, while its
+// ValidateCredential method also reuses the compiled form of each schema.
+type CachingRemoteSchemaLoader struct {
+	client        *http.Client
+	maxSchemaSize int
+
+	// mu guards the caches
+	mu       sync.Mutex
+	fetched  map[string][]byte
+	compiled map[string]*compiledCredentialSchema
+}
+
+// CachingRemoteSchemaLoaderOption configures a CachingRemoteSchemaLoader
+type CachingRemoteSchemaLoaderOption func(*CachingRemoteSchemaLoader)
+
+// WithMaxSchemaSize sets the maximum size, in bytes, of a fetched schema. Larger responses are rejected.
+// A non-positive size disables the limit.
+func WithMaxSchemaSize(n int) CachingRemoteSchemaLoaderOption {
+	return func(l *CachingRemoteSchemaLoader) {
+		l.maxSchemaSize = n
+	}
+}
+
+// NewCachingRemoteSchemaLoader creates a loader fetching schemas with the given client, or http.DefaultClient if nil
+func NewCachingRemoteSchemaLoader(client *http.Client, opts ...CachingRemoteSchemaLoaderOption) *CachingRemoteSchemaLoader {
+	if client == nil {
+		client = http.DefaultClient
+	}
+	l := CachingRemoteSchemaLoader{
+		client:        client,
+		maxSchemaSize: DefaultMaxSchemaSize,
+		fetched:       make(map[string][]byte),
+		compiled:      make(map[string]*compiledCredentialSchema),
+	}
+	for _, opt := range opts {
+		opt(&l)
+	}
+	return &l
+}
+
+// Fetch returns the schema at the URL, retrieving it only if it has not been fetched before
+func (l *CachingRemoteSchemaLoader) Fetch(url string) ([]byte, error) {
+	l.mu.Lock()
+	cached, ok := l.fetched[url]
+	l.mu.Unlock()
+	if ok {
+		return cached, nil
+	}
+
+	resp, err := l.client.Get(url)
+	if err != nil {
+		return nil, errors.Wrapf(err, "fetching schema: %s", url)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("fetching schema<%s>: unexpected status code: %d", url, resp.StatusCode)
+	}
+
+	body := io.Reader(resp.Body)
+	if l.maxSchemaSize > 0 {
+		// read a byte past the limit to tell a schema of exactly the maximum size from a larger one
+		body = io.LimitReader(resp.Body, int64(l.maxSchemaSize)+1)
+	}
+	schemaBytes, err := io.ReadAll(body)
+	if err != nil {
+		return nil, errors.Wrapf(err, "reading schema: %s", url)
+	}
+	if l.maxSchemaSize > 0 && len(schemaBytes) > l.maxSchemaSize {
+		return nil, fmt.Errorf("schema<%s> exceeds the maximum size of %d bytes", url, l.maxSchemaSize)
+	}
+
+	l.mu.Lock()
+	l.fetched[url] = schemaBytes
+	l.mu.Unlock()
+	return schemaBytes, nil
+}
+
+// ValidateCredential validates a credential against its credential schema as ValidateCredentialAgainstSchema does,
+// fetching and compiling each schema once and reusing the compiled schema for later credentials
+func (l *CachingRemoteSchemaLoader) ValidateCredential(vc credential.VerifiableCredential) error {
+	credSchema, err := getCredentialSchema(vc)
+	if err != nil {
+		return err
+	}
+
+	l.mu.Lock()
+	compiled, ok := l.compiled[credSchema.ID]
+	l.mu.Unlock()
+	if !ok {
+		if compiled, err = loadCredentialSchema(credSchema.ID, l.Fetch); err != nil {
+			return err
+		}
+		l.mu.Lock()
+		l.compiled[credSchema.ID] = compiled
+		l.mu.Unlock()
+	}
+	return compiled.validate(vc)
+}
@@ -0,0 +1,92 @@
+package schema
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	vc "github.com/TBD54566975/ssi-sdk/credential"
+)
+
+func TestCachingRemoteSchemaLoader(t *testing.T) {
+	subjectSchema := `{
+  "$schema": "https://json-schema.org/draft/2020-12/schema",
+  "type": "object",
+  "properties": {
+    "emailAddress": {"type": "string", "pattern": "^[^@]+@[^@]+$"}
+  },
+  "required": ["emailAddress"]
+}`
+	var hits atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		hits.Add(1)
+		switch r.URL.Path {
+		case "/schemas/email.json":
+			_, _ = w.Write([]byte(subjectSchema))
+		case "/schemas/large.json":
+			_, _ = w.Write([]byte(`{"description": "` + strings.Repeat("a", 1024) + `"}`))
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer server.Close()
+
+	getCred := func(schemaID, email string) vc.VerifiableCredential {
+		return vc.VerifiableCredential{
+			Context:          []any{vc.VerifiableCredentialsLinkedDataContext},
+			Type:             []any{vc.VerifiableCredentialType},
+			Issuer:           "did:example:issuer",
+			IssuanceDate:     "2021-01-01T00:00:00Z",
+			CredentialSchema: &vc.CredentialSchema{ID: schemaID, Type: JSONSchemaType},
+			CredentialSubject: map[string]any{
+				"id":           "did:example:subject",
+				"emailAddress": email,
+			},
+		}
+	}
+
+	t.Run("fetches a schema once across validations", func(tt *testing.T) {
+		hits.Store(0)
+		loader := NewCachingRemoteSchemaLoader(server.Client())
+		cred := getCred(server.URL+"/schemas/email.json", "first.last@example.com")
+		for i := 0; i < 10; i++ {
+			assert.NoError(tt, loader.ValidateCredential(cred))
+		}
+		assert.EqualValues(tt, 1, hits.Load())
+
+		invalid := getCred(server.URL+"/schemas/email.json", "not an email")
+		assert.ErrorContains(tt, loader.ValidateCredential(invalid), "$.credentialSubject.emailAddress")
+		assert.EqualValues(tt, 1, hits.Load())
+	})
+
+	t.Run("fetch function for ValidateCredentialAgainstSchema", func(tt *testing.T) {
+		hits.Store(0)
+		loader := NewCachingRemoteSchemaLoader(server.Client())
+		cred := getCred(server.URL+"/schemas/email.json", "first.last@example.com")
+		for i := 0; i < 10; i++ {
+			assert.NoError(tt, ValidateCredentialAgainstSchema(cred, loader.Fetch))
+		}
+		assert.EqualValues(tt, 1, hits.Load())
+	})
+
+	t.Run("schema exceeding the maximum size", func(tt *testing.T) {
+		loader := NewCachingRemoteSchemaLoader(server.Client(), WithMaxSchemaSize(1024))
+		_, err := loader.Fetch(server.URL + "/schemas/large.json")
+		assert.ErrorContains(tt, err, "exceeds the maximum size of 1024 bytes")
+
+		schemaBytes, err := NewCachingRemoteSchemaLoader(server.Client(), WithMaxSchemaSize(0)).Fetch(server.URL + "/schemas/large.json")
+		require.NoError(tt, err)
+		assert.Greater(tt, len(schemaBytes), 1024)
+	})
+
+	t.Run("schema not found", func(tt *testing.T) {
+		loader := NewCachingRemoteSchemaLoader(server.Client())
+		err := loader.ValidateCredential(getCred(server.URL+"/schemas/missing.json", "first.last@example.com"))
+		assert.ErrorContains(tt, err, "unexpected status code: 404")
+	})
+}
@@ -5,6 +5,11 @@ import "fmt"
 const (
 	// VCJSONSchemaType https://w3c-ccg.github.io/vc-json-schemas/v2/index.html#credential_schema_definition_metadata
 	VCJSONSchemaType string = "https://w3c-ccg.github.io/vc-json-schemas/schema/2.0/schema.json"
+
+	// JSONSchemaType https://www.w3.org/TR/vc-json-schema/#jsonschema
+	JSONSchemaType string = "JsonSchema"
+	// JSONSchemaValidator2018Type https://w3c-ccg.github.io/vc-json-schemas/v1/index.html
+	JSONSchemaValidator2018Type string = "JsonSchemaValidator2018"
 )
 
 type JSONSchema map[string]any
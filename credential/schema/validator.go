@@ -0,0 +1,134 @@
+package schema
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/goccy/go-json"
+	"github.com/pkg/errors"
+	"github.com/santhosh-tekuri/jsonschema/v5"
+
+	"github.com/TBD54566975/ssi-sdk/credential"
+	"github.com/TBD54566975/ssi-sdk/did"
+	"github.com/TBD54566975/ssi-sdk/util"
+)
+
+// VCJSONSchemaValidator validates credentials against a credential schema, such as one built by NewCredentialSchema
+// https://w3c-ccg.github.io/vc-json-schemas/v1/index.html
+type VCJSONSchemaValidator struct {
+	metadata           CredentialSchemaMetadata
+	schema             *jsonschema.Schema
+	requiresDIDSubject bool
+}
+
+// NewVCJSONSchemaValidator compiles a credential schema, which must have a $schema and a $metadata with a version,
+// and describe the credentialSubject of a credential. Referenced schemas are not loaded.
+func NewVCJSONSchemaValidator(credentialSchema JSONSchema) (*VCJSONSchemaValidator, error) {
+	if _, ok := credentialSchema[schemaProperty].(string); !ok {
+		return nil, fmt.Errorf("credential schema must have a %s property", schemaProperty)
+	}
+	metadataJSON, ok := credentialSchema[metadataProperty]
+	if !ok {
+		return nil, fmt.Errorf("credential schema must have a %s property", metadataProperty)
+	}
+	metadataBytes, err := json.Marshal(metadataJSON)
+	if err != nil {
+		return nil, errors.Wrapf(err, "could not marshal credential schema %s", metadataProperty)
+	}
+	var metadata CredentialSchemaMetadata
+	if err = json.Unmarshal(metadataBytes, &metadata); err != nil {
+		return nil, errors.Wrapf(err, "could not unmarshal credential schema %s", metadataProperty)
+	}
+	if metadata.Version == "" {
+		return nil, fmt.Errorf("credential schema %s must have a version", metadataProperty)
+	}
+
+	jsonSchema := make(map[string]any, len(credentialSchema))
+	for k, v := range credentialSchema {
+		if k != metadataProperty {
+			jsonSchema[k] = v
+		}
+	}
+	if !describesCredential(jsonSchema) {
+		return nil, fmt.Errorf("credential schema must describe the %s property", credentialSubjectProperty)
+	}
+	id, _ := jsonSchema["$id"].(string)
+	compiled, err := compileJSONSchema(id, jsonSchema, func(url string) ([]byte, error) {
+		return nil, fmt.Errorf("cannot load schema<%s> referenced by a credential schema", url)
+	})
+	if err != nil {
+		return nil, errors.Wrap(err, "compiling credential schema")
+	}
+
+	return &VCJSONSchemaValidator{
+		metadata:           metadata,
+		schema:             compiled,
+		requiresDIDSubject: requiresSubjectID(jsonSchema),
+	}, nil
+}
+
+// Metadata returns the $metadata of the validator's credential schema
+func (v VCJSONSchemaValidator) Metadata() CredentialSchemaMetadata {
+	return v.metadata
+}
+
+// Validate validates a credential against the validator's credential schema. When the schema requires the id of the
+// credentialSubject, each subject's id must also be a DID. All failures are returned together, each with the JSON
+// path of the value it applies to.
+func (v VCJSONSchemaValidator) Validate(cred credential.VerifiableCredential) error {
+	credJSON, err := util.AnyToJSONInterface(cred)
+	if err != nil {
+		return errors.Wrap(err, "could not convert credential to JSON")
+	}
+	problems := validationProblems(v.schema.Validate(credJSON), "$")
+
+	if v.requiresDIDSubject {
+		subjects := cred.Subjects()
+		for i, subject := range subjects {
+			idPath := "$." + credentialSubjectProperty
+			if len(subjects) > 1 {
+				idPath += "[" + strconv.Itoa(i) + "]"
+			}
+			idPath += "." + credential.VerifiableCredentialIDProperty
+			id, ok := subject[credential.VerifiableCredentialIDProperty].(string)
+			if !ok || hasProblemAt(problems, idPath) {
+				continue
+			}
+			if parsed, err := did.ParseDID(id); err != nil || parsed.DID() != id {
+				problems = append(problems, idPath+": is not a DID")
+			}
+		}
+	}
+
+	if len(problems) > 0 {
+		return fmt.Errorf("credential not valid for schema<%s>: %d problem(s): %s", v.metadata.Name, len(problems),
+			strings.Join(problems, "; "))
+	}
+	return nil
+}
+
+// requiresSubjectID determines whether a credential schema requires the id of the credentialSubject
+func requiresSubjectID(jsonSchema map[string]any) bool {
+	properties, _ := jsonSchema["properties"].(map[string]any)
+	subjectSchema, _ := properties[credentialSubjectProperty].(map[string]any)
+	required, err := util.InterfaceToStrings(subjectSchema["required"])
+	if err != nil {
+		return false
+	}
+	for _, r := range required {
+		if r == credential.VerifiableCredentialIDProperty {
+			return true
+		}
+	}
+	return false
+}
+
+func hasProblemAt(problems []string, path string) bool {
+	for _, problem := range problems {
+		if strings.HasPrefix(problem, path+":") {
+			return true
+		}
+	}
+	return false
+}
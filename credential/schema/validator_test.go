@@ -0,0 +1,99 @@
+package schema
+
+import (
+	"testing"
+
+	"github.com/goccy/go-json"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	vc "github.com/TBD54566975/ssi-sdk/credential"
+)
+
+func TestVCJSONSchemaValidator(t *testing.T) {
+	credentialSchema, err := NewCredentialSchema("Email", "1.0", map[string]Property{
+		"id":           {Required: true},
+		"emailAddress": {Type: "string", Pattern: "^[^@]+@[^@]+$", Required: true},
+	})
+	require.NoError(t, err)
+	getCred := func(subject map[string]any) vc.VerifiableCredential {
+		return vc.VerifiableCredential{
+			Context:           []any{vc.VerifiableCredentialsLinkedDataContext},
+			Type:              []any{vc.VerifiableCredentialType},
+			Issuer:            "did:example:issuer",
+			IssuanceDate:      "2021-01-01T00:00:00Z",
+			CredentialSubject: subject,
+		}
+	}
+
+	t.Run("round trip", func(tt *testing.T) {
+		schemaBytes, err := json.Marshal(credentialSchema)
+		require.NoError(tt, err)
+		var roundTripped JSONSchema
+		require.NoError(tt, json.Unmarshal(schemaBytes, &roundTripped))
+
+		validator, err := NewVCJSONSchemaValidator(roundTripped)
+		require.NoError(tt, err)
+		assert.Equal(tt, CredentialSchemaMetadata{Version: "1.0", Name: "Email"}, validator.Metadata())
+
+		assert.NoError(tt, validator.Validate(getCred(map[string]any{
+			"id":           "did:example:subject",
+			"emailAddress": "first.last@example.com",
+		})))
+	})
+
+	t.Run("missing required property", func(tt *testing.T) {
+		validator, err := NewVCJSONSchemaValidator(credentialSchema)
+		require.NoError(tt, err)
+
+		err = validator.Validate(getCred(map[string]any{"id": "did:example:subject"}))
+		assert.ErrorContains(tt, err, "credential not valid for schema<Email>: 1 problem(s)")
+		assert.ErrorContains(tt, err, "$.credentialSubject: missing properties: 'emailAddress'")
+
+		err = validator.Validate(getCred(map[string]any{"emailAddress": "first.last@example.com"}))
+		assert.ErrorContains(tt, err, "$.credentialSubject: missing properties: 'id'")
+	})
+
+	t.Run("subject id must be a did", func(tt *testing.T) {
+		validator, err := NewVCJSONSchemaValidator(credentialSchema)
+		require.NoError(tt, err)
+
+		err = validator.Validate(getCred(map[string]any{
+			"id":           "https://example.com/subject",
+			"emailAddress": "first.last@example.com",
+		}))
+		assert.ErrorContains(tt, err, "1 problem(s): $.credentialSubject.id: does not match pattern")
+
+		// a DID URL is not a DID, which the schema's pattern alone allows
+		err = validator.Validate(getCred(map[string]any{
+			"id":           "did:example:subject#key-1",
+			"emailAddress": "first.last@example.com",
+		}))
+		assert.ErrorContains(tt, err, "1 problem(s): $.credentialSubject.id: is not a DID")
+	})
+
+	t.Run("invalid credential schema", func(tt *testing.T) {
+		withoutMetadata := make(JSONSchema)
+		for k, v := range credentialSchema {
+			withoutMetadata[k] = v
+		}
+		delete(withoutMetadata, "$metadata")
+		_, err := NewVCJSONSchemaValidator(withoutMetadata)
+		assert.ErrorContains(tt, err, "credential schema must have a $metadata property")
+
+		withoutMetadata["$metadata"] = map[string]any{"name": "Email"}
+		_, err = NewVCJSONSchemaValidator(withoutMetadata)
+		assert.ErrorContains(tt, err, "credential schema $metadata must have a version")
+
+		delete(withoutMetadata, "$schema")
+		_, err = NewVCJSONSchemaValidator(withoutMetadata)
+		assert.ErrorContains(tt, err, "credential schema must have a $schema property")
+
+		_, err = NewVCJSONSchemaValidator(JSONSchema{
+			"$schema":    CredentialSchemaJSONSchemaDraft,
+			"$metadata":  map[string]any{"version": "1.0"},
+			"properties": map[string]any{"emailAddress": map[string]any{"type": "string"}},
+		})
+		assert.ErrorContains(tt, err, "credential schema must describe the credentialSubject property")
+	})
+}
@@ -0,0 +1,366 @@
+package sdjwt
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/goccy/go-json"
+	"github.com/pkg/errors"
+
+	"github.com/TBD54566975/ssi-sdk/crypto/jwx"
+)
+
+const (
+	// SDClaim is the claim holding the digests of the disclosures of an object's selectively disclosable claims
+	SDClaim = "_sd"
+	// SDAlgClaim is the claim naming the hash algorithm of the digests of disclosures
+	SDAlgClaim = "_sd_alg"
+	// SHA256Alg is the name of the SHA-256 hash algorithm for digests of disclosures
+	SHA256Alg = "sha-256"
+	// ArrayElementDigestKey is the key of the object in place of a selectively disclosable array element, whose value
+	// is the digest of the element's disclosure
+	ArrayElementDigestKey = "..."
+
+	// Separator separates the issuer-signed JWT and the disclosures in the combined serialization of an SD-JWT
+	Separator = "~"
+
+	// saltSize is the number of random bytes of the salt of a disclosure, 128 bits as recommended
+	saltSize = 16
+)
+
+// reservedClaims cannot be selectively disclosable
+var reservedClaims = map[string]bool{SDClaim: true, SDAlgClaim: true, ArrayElementDigestKey: true}
+
+// Disclosure reveals a selectively disclosable claim of an SD-JWT, which is either an object member with a name, or
+// an array element without one
+// https://www.ietf.org/archive/id/draft-ietf-oauth-selective-disclosure-jwt-05.html#name-disclosures
+type Disclosure struct {
+	Salt  string
+	Name  string
+	Value any
+	// Encoded is the base64url encoding of the disclosure's JSON array, [salt, name, value] for an object member or
+	// [salt, value] for an array element, which is digested
+	Encoded string
+	// Path is the selector of the disclosed claim in the SD-JWT's payload, such as address.street or
+	// nationalities[1]. It is set for the disclosures of an issued or parsed SD-JWT.
+	Path string
+}
+
+// Digest returns the base64url encoded SHA-256 digest of the encoded disclosure, as listed in an _sd claim
+func (d Disclosure) Digest() string {
+	return digest(d.Encoded)
+}
+
+// IsArrayElement determines whether the disclosure discloses an array element, rather than an object member
+func (d Disclosure) IsArrayElement() bool {
+	return d.Name == ""
+}
+
+// ParseDisclosure decodes a base64url encoded disclosure of a claim. Its path is not known until it is resolved
+// against the payload of its SD-JWT.
+func ParseDisclosure(encoded string) (*Disclosure, error) {
+	decoded, err := base64.RawURLEncoding.DecodeString(encoded)
+	if err != nil {
+		return nil, errors.Wrap(err, "decoding disclosure")
+	}
+	var disclosure []any
+	if err = json.Unmarshal(decoded, &disclosure); err != nil {
+		return nil, errors.Wrap(err, "unmarshalling disclosure")
+	}
+	if len(disclosure) != 2 && len(disclosure) != 3 {
+		return nil, fmt.Errorf("disclosure must have 2 or 3 elements, has %d", len(disclosure))
+	}
+	salt, ok := disclosure[0].(string)
+	if !ok {
+		return nil, errors.New("disclosure salt must be a string")
+	}
+	if len(disclosure) == 2 {
+		return &Disclosure{Salt: salt, Value: disclosure[1], Encoded: encoded}, nil
+	}
+	name, ok := disclosure[1].(string)
+	if !ok || name == "" {
+		return nil, errors.New("disclosure claim name must be a non-empty string")
+	}
+	return &Disclosure{Salt: salt, Name: name, Value: disclosure[2], Encoded: encoded}, nil
+}
+
+// SDJWT is an issuer-signed JWT with the disclosures of its selectively disclosable claims, and, when presented by a
+// holder, an optional Key Binding JWT
+// https://www.ietf.org/archive/id/draft-ietf-oauth-selective-disclosure-jwt-05.html
+type SDJWT struct {
+	IssuerJWT     string
+	Disclosures   []Disclosure
+	KeyBindingJWT string
+}
+
+// Serialize returns the combined serialization of the SD-JWT, <jwt>~<disclosure>~...~, followed by the Key Binding
+// JWT if it has one
+func (s SDJWT) Serialize() string {
+	var sb strings.Builder
+	sb.WriteString(s.IssuerJWT)
+	sb.WriteString(Separator)
+	for _, disclosure := range s.Disclosures {
+		sb.WriteString(disclosure.Encoded)
+		sb.WriteString(Separator)
+	}
+	sb.WriteString(s.KeyBindingJWT)
+	return sb.String()
+}
+
+// Parse parses the combined serialization of an SD-JWT, <jwt>~<disclosure>~...~, optionally followed by a Key Binding
+// JWT, decoding its disclosures and resolving their paths against the payload of the JWT. No signatures are verified.
+func Parse(serialized string) (*SDJWT, error) {
+	parts := strings.Split(serialized, Separator)
+	if len(parts) < 2 || parts[0] == "" {
+		return nil, errors.New("SD-JWT must be of the form <jwt>~<disclosure>~...~")
+	}
+	parsed := SDJWT{IssuerJWT: parts[0], KeyBindingJWT: parts[len(parts)-1]}
+	for i, encoded := range parts[1 : len(parts)-1] {
+		disclosure, err := ParseDisclosure(encoded)
+		if err != nil {
+			return nil, errors.Wrapf(err, "parsing disclosure %d", i)
+		}
+		parsed.Disclosures = append(parsed.Disclosures, *disclosure)
+	}
+
+	payload, err := decodePayload(parsed.IssuerJWT)
+	if err != nil {
+		return nil, err
+	}
+	if _, err = disclose(payload, parsed.Disclosures); err != nil {
+		return nil, errors.Wrap(err, "resolving disclosures")
+	}
+	return &parsed, nil
+}
+
+// decodePayload decodes the claims of a JWT in compact serialization without verifying it
+func decodePayload(token string) (map[string]any, error) {
+	parts := strings.Split(token, ".")
+	if len(parts) != 3 {
+		return nil, errors.New("JWT must be in compact serialization")
+	}
+	payloadBytes, err := base64.RawURLEncoding.DecodeString(parts[1])
+	if err != nil {
+		return nil, errors.Wrap(err, "decoding JWT payload")
+	}
+	var payload map[string]any
+	if err = json.Unmarshal(payloadBytes, &payload); err != nil {
+		return nil, errors.Wrap(err, "unmarshalling JWT claims")
+	}
+	return payload, nil
+}
+
+// Issue signs the claims as an SD-JWT, in which each of the selectively disclosable claims is replaced by the digest
+// of a salted disclosure. Claims are selected by top-level name, by members of nested objects such as
+// address.street, or by array elements such as nationalities[1], optionally prefixed by the JSONPath root $. The
+// digest of an object member is added to the _sd claim of its object, and an array element is replaced by an object
+// with the digest under the ... key. A claim may be selected along with claims nested within it, whose disclosures
+// are then part of its own. The digests of each object are sorted so that their order reveals nothing about the
+// claims, and the _sd_alg claim is set to sha-256.
+func Issue(signer jwx.Signer, claims map[string]any, selectivelyDisclosable []string) (*SDJWT, error) {
+	// copy the claims, as nested objects and arrays are modified
+	claimsBytes, err := json.Marshal(claims)
+	if err != nil {
+		return nil, errors.Wrap(err, "marshalling claims")
+	}
+	var payload map[string]any
+	if err = json.Unmarshal(claimsBytes, &payload); err != nil {
+		return nil, errors.Wrap(err, "unmarshalling claims")
+	}
+	if payload == nil {
+		payload = make(map[string]any)
+	}
+	if _, ok := payload[SDAlgClaim]; ok {
+		return nil, fmt.Errorf("claims cannot contain the reserved claim %s", SDAlgClaim)
+	}
+	if err = checkReservedClaims(payload); err != nil {
+		return nil, err
+	}
+
+	selectors := make([]selector, 0, len(selectivelyDisclosable))
+	selected := make(map[string]bool, len(selectivelyDisclosable))
+	for _, s := range selectivelyDisclosable {
+		sel, err := parseSelector(s)
+		if err != nil {
+			return nil, err
+		}
+		if last := sel[len(sel)-1]; !last.isIndex && reservedClaims[last.name] {
+			return nil, fmt.Errorf("reserved claim %s cannot be selectively disclosable", last.name)
+		}
+		if selected[sel.String()] {
+			return nil, fmt.Errorf("claim<%s> selected more than once", sel)
+		}
+		selected[sel.String()] = true
+		selectors = append(selectors, sel)
+	}
+	// nested claims are hidden first, so their disclosures become part of the values of the claims containing them
+	sort.SliceStable(selectors, func(i, j int) bool { return len(selectors[i]) > len(selectors[j]) })
+
+	issued := SDJWT{Disclosures: make([]Disclosure, 0, len(selectors))}
+	for _, sel := range selectors {
+		disclosure, found, err := hideClaim(payload, sel)
+		if err != nil {
+			return nil, errors.Wrapf(err, "selectively disclosable claim<%s>", sel)
+		}
+		if !found {
+			return nil, fmt.Errorf("selectively disclosable claim<%s> not found in claims", sel)
+		}
+		issued.Disclosures = append(issued.Disclosures, *disclosure)
+	}
+	payload[SDAlgClaim] = SHA256Alg
+
+	signed, err := signer.SignWithHeaders(payload, nil)
+	if err != nil {
+		return nil, errors.Wrap(err, "signing SD-JWT")
+	}
+	issued.IssuerJWT = signed
+	return &issued, nil
+}
+
+// checkReservedClaims makes sure no objects of the claims have members with the names reserved for digests
+func checkReservedClaims(value any) error {
+	switch v := value.(type) {
+	case map[string]any:
+		for k, member := range v {
+			if k == SDClaim || k == ArrayElementDigestKey {
+				return fmt.Errorf("claims cannot contain the reserved claim %s", k)
+			}
+			if err := checkReservedClaims(member); err != nil {
+				return err
+			}
+		}
+	case []any:
+		for _, element := range v {
+			if err := checkReservedClaims(element); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// hideClaim replaces the selected claim of a payload by the digest of a new disclosure of it, if the claim is found
+func hideClaim(payload map[string]any, sel selector) (*Disclosure, bool, error) {
+	var parent any = payload
+	for _, segment := range sel[:len(sel)-1] {
+		var ok bool
+		if parent, ok = selectClaim(parent, segment); !ok {
+			return nil, false, nil
+		}
+	}
+	last := sel[len(sel)-1]
+	value, ok := selectClaim(parent, last)
+	if !ok {
+		return nil, false, nil
+	}
+	name := last.name
+	if last.isIndex {
+		name = ""
+	}
+	disclosure, err := newDisclosure(name, value)
+	if err != nil {
+		return nil, false, errors.Wrap(err, "creating disclosure")
+	}
+	disclosure.Path = sel.String()
+
+	if last.isIndex {
+		parent.([]any)[last.index] = map[string]any{ArrayElementDigestKey: disclosure.Digest()}
+		return disclosure, true, nil
+	}
+	object := parent.(map[string]any)
+	delete(object, last.name)
+	digests, _ := object[SDClaim].([]string)
+	digests = append(digests, disclosure.Digest())
+	sort.Strings(digests)
+	object[SDClaim] = digests
+	return disclosure, true, nil
+}
+
+// selectClaim returns the member or element of an object or array, if it is not already selectively disclosable
+func selectClaim(value any, segment selectorSegment) (any, bool) {
+	if segment.isIndex {
+		array, ok := value.([]any)
+		if !ok || segment.index >= len(array) {
+			return nil, false
+		}
+		if _, ok = arrayElementDigest(array[segment.index]); ok {
+			return nil, false
+		}
+		return array[segment.index], true
+	}
+	object, ok := value.(map[string]any)
+	if !ok {
+		return nil, false
+	}
+	member, ok := object[segment.name]
+	return member, ok
+}
+
+// newDisclosure creates a disclosure of a claim with a random salt, which is an array element if it has no name
+func newDisclosure(name string, value any) (*Disclosure, error) {
+	saltBytes := make([]byte, saltSize)
+	if _, err := rand.Read(saltBytes); err != nil {
+		return nil, errors.Wrap(err, "generating salt")
+	}
+	salt := base64.RawURLEncoding.EncodeToString(saltBytes)
+	disclosure := []any{salt, name, value}
+	if name == "" {
+		disclosure = []any{salt, value}
+	}
+	disclosureBytes, err := json.Marshal(disclosure)
+	if err != nil {
+		return nil, errors.Wrap(err, "marshalling disclosure")
+	}
+	return &Disclosure{
+		Salt:    salt,
+		Name:    name,
+		Value:   value,
+		Encoded: base64.RawURLEncoding.EncodeToString(disclosureBytes),
+	}, nil
+}
+
+// digest returns the base64url encoded SHA-256 digest of an encoded disclosure
+func digest(encoded string) string {
+	sum := sha256.Sum256([]byte(encoded))
+	return base64.RawURLEncoding.EncodeToString(sum[:])
+}
+
+// Present returns the combined serialization of an issued SD-JWT with only the disclosures of the selected claims,
+// for a holder to selectively disclose them. Claims are selected as they were when issued, and the disclosures of any
+// selectively disclosable claims containing them are kept as well, as they are needed to reveal them. The
+// issuer-signed JWT is left intact. An error is returned if any of the selected claims has no disclosure.
+func Present(issued *SDJWT, reveal []string) (string, error) {
+	if issued == nil {
+		return "", errors.New("SD-JWT cannot be empty")
+	}
+	disclosed := make(map[string]bool, len(issued.Disclosures))
+	for _, disclosure := range issued.Disclosures {
+		disclosed[disclosure.Path] = true
+	}
+	revealed := make(map[string]bool, len(reveal))
+	for _, s := range reveal {
+		sel, err := parseSelector(s)
+		if err != nil {
+			return "", err
+		}
+		if !disclosed[sel.String()] {
+			return "", fmt.Errorf("SD-JWT has no disclosure of claim<%s>", s)
+		}
+		for i := 1; i <= len(sel); i++ {
+			revealed[sel[:i].String()] = true
+		}
+	}
+
+	// any key binding is for a presentation, so it is not kept
+	presented := SDJWT{IssuerJWT: issued.IssuerJWT}
+	for _, disclosure := range issued.Disclosures {
+		if revealed[disclosure.Path] {
+			presented.Disclosures = append(presented.Disclosures, disclosure)
+		}
+	}
+	return presented.Serialize(), nil
+}
@@ -0,0 +1,274 @@
+package sdjwt
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/TBD54566975/ssi-sdk/crypto"
+	"github.com/TBD54566975/ssi-sdk/crypto/jwx"
+)
+
+func TestIssue(t *testing.T) {
+	claims := map[string]any{
+		"iss":         "did:example:issuer",
+		"given_name":  "Alice",
+		"family_name": "Smith",
+		"age":         float64(34),
+		"address": map[string]any{
+			"locality": "Anytown",
+			"country":  "US",
+		},
+	}
+
+	t.Run("selectively disclosable claims are replaced by digests", func(tt *testing.T) {
+		signer, verifier := getTestSignerVerifier(tt)
+		issued, err := Issue(*signer, claims, []string{"given_name", "age", "address"})
+		require.NoError(tt, err)
+		require.Len(tt, issued.Disclosures, 3)
+
+		// the combined serialization is the jwt followed by each disclosure, each terminated by a tilde
+		serialized := issued.Serialize()
+		assert.True(tt, strings.HasSuffix(serialized, Separator))
+		parts := strings.Split(serialized, Separator)
+		require.Len(tt, parts, 5)
+		assert.Equal(tt, issued.IssuerJWT, parts[0])
+		assert.Empty(tt, parts[4])
+
+		_, payload, err := verifier.VerifyWithHeaders(parts[0])
+		require.NoError(tt, err)
+		assert.Equal(tt, SHA256Alg, payload[SDAlgClaim])
+		assert.Equal(tt, "Smith", payload["family_name"])
+		assert.NotContains(tt, payload, "given_name")
+		assert.NotContains(tt, payload, "age")
+		assert.NotContains(tt, payload, "address")
+		digests, ok := payload[SDClaim].([]any)
+		require.True(tt, ok)
+		assert.Len(tt, digests, 3)
+
+		// decoding the disclosures reconstructs the original claims
+		reconstructed := make(map[string]any)
+		for k, v := range payload {
+			if k != SDClaim && k != SDAlgClaim {
+				reconstructed[k] = v
+			}
+		}
+		for _, encoded := range parts[1:4] {
+			disclosure, err := ParseDisclosure(encoded)
+			require.NoError(tt, err)
+			assert.NotEmpty(tt, disclosure.Salt)
+			assert.Contains(tt, digests, disclosure.Digest())
+			reconstructed[disclosure.Name] = disclosure.Value
+		}
+		assert.Equal(tt, claims, reconstructed)
+	})
+
+	t.Run("disclosures are salted", func(tt *testing.T) {
+		signer, _ := getTestSignerVerifier(tt)
+		first, err := Issue(*signer, claims, []string{"given_name"})
+		require.NoError(tt, err)
+		second, err := Issue(*signer, claims, []string{"given_name"})
+		require.NoError(tt, err)
+		assert.NotEqual(tt, first.Disclosures[0].Digest(), second.Disclosures[0].Digest())
+	})
+
+	t.Run("no selectively disclosable claims", func(tt *testing.T) {
+		signer, verifier := getTestSignerVerifier(tt)
+		issued, err := Issue(*signer, claims, nil)
+		require.NoError(tt, err)
+		assert.Empty(tt, issued.Disclosures)
+		assert.Equal(tt, issued.IssuerJWT+Separator, issued.Serialize())
+
+		_, payload, err := verifier.VerifyWithHeaders(issued.IssuerJWT)
+		require.NoError(tt, err)
+		assert.Equal(tt, SHA256Alg, payload[SDAlgClaim])
+		assert.NotContains(tt, payload, SDClaim)
+	})
+
+	t.Run("unknown selectively disclosable claim", func(tt *testing.T) {
+		signer, _ := getTestSignerVerifier(tt)
+		_, err := Issue(*signer, claims, []string{"email"})
+		assert.Error(tt, err)
+		assert.Contains(tt, err.Error(), "selectively disclosable claim<email> not found in claims")
+	})
+
+	t.Run("nested claims that cannot be selected", func(tt *testing.T) {
+		signer, _ := getTestSignerVerifier(tt)
+		_, err := Issue(*signer, claims, []string{"address.street"})
+		assert.Error(tt, err)
+		assert.Contains(tt, err.Error(), "selectively disclosable claim<address.street> not found in claims")
+
+		_, err = Issue(*signer, claims, []string{"given_name[0]"})
+		assert.Error(tt, err)
+		assert.Contains(tt, err.Error(), "selectively disclosable claim<given_name[0]> not found in claims")
+
+		_, err = Issue(*signer, claims, []string{"address.country", "$.address.country"})
+		assert.Error(tt, err)
+		assert.Contains(tt, err.Error(), "claim<address.country> selected more than once")
+	})
+
+	t.Run("reserved claims", func(tt *testing.T) {
+		signer, _ := getTestSignerVerifier(tt)
+		_, err := Issue(*signer, map[string]any{SDClaim: []string{}}, nil)
+		assert.Error(tt, err)
+		assert.Contains(tt, err.Error(), "reserved claim _sd")
+
+		_, err = Issue(*signer, claims, []string{SDAlgClaim})
+		assert.Error(tt, err)
+		assert.Contains(tt, err.Error(), "reserved claim _sd_alg cannot be selectively disclosable")
+	})
+}
+
+func TestParseDisclosure(t *testing.T) {
+	t.Run("disclosure from the specification", func(tt *testing.T) {
+		// https://www.ietf.org/archive/id/draft-ietf-oauth-selective-disclosure-jwt-05.html#name-disclosures-for-object-prop
+		encoded := "WyI2cU1RdlJMNWhhaiIsICJmYW1pbHlfbmFtZSIsICJNw7ZiaXVzIl0"
+		disclosure, err := ParseDisclosure(encoded)
+		require.NoError(tt, err)
+		assert.Equal(tt, "6qMQvRL5haj", disclosure.Salt)
+		assert.Equal(tt, "family_name", disclosure.Name)
+		assert.Equal(tt, "Möbius", disclosure.Value)
+		assert.Equal(tt, "uutlBuYeMDyjLLTpf6Jxi7yNkEF35jdyWMn9U7b_RYY", disclosure.Digest())
+	})
+
+	t.Run("bad disclosures", func(tt *testing.T) {
+		_, err := ParseDisclosure("not base64!")
+		assert.Error(tt, err)
+
+		// ["salt"]
+		_, err = ParseDisclosure("WyJzYWx0Il0")
+		assert.Error(tt, err)
+		assert.Contains(tt, err.Error(), "disclosure must have 2 or 3 elements")
+
+		// ["salt", "", "value"]
+		_, err = ParseDisclosure("WyJzYWx0IiwgIiIsICJ2YWx1ZSJd")
+		assert.Error(tt, err)
+		assert.Contains(tt, err.Error(), "disclosure claim name must be a non-empty string")
+	})
+}
+
+func TestParse(t *testing.T) {
+	t.Run("parses an issued SD-JWT", func(tt *testing.T) {
+		signer, _ := getTestSignerVerifier(tt)
+		issued, err := Issue(*signer, map[string]any{"given_name": "Alice", "family_name": "Smith"},
+			[]string{"given_name", "family_name"})
+		require.NoError(tt, err)
+
+		parsed, err := Parse(issued.Serialize())
+		require.NoError(tt, err)
+		assert.Equal(tt, issued, parsed)
+	})
+
+	t.Run("parses a Key Binding JWT", func(tt *testing.T) {
+		signer, _ := getTestSignerVerifier(tt)
+		issued, err := Issue(*signer, map[string]any{"given_name": "Alice"}, []string{"given_name"})
+		require.NoError(tt, err)
+
+		parsed, err := Parse(issued.Serialize() + "kb.payload.signature")
+		require.NoError(tt, err)
+		assert.Equal(tt, issued.IssuerJWT, parsed.IssuerJWT)
+		assert.Equal(tt, issued.Disclosures, parsed.Disclosures)
+		assert.Equal(tt, "kb.payload.signature", parsed.KeyBindingJWT)
+	})
+
+	t.Run("bad serializations", func(tt *testing.T) {
+		_, err := Parse("")
+		assert.Error(tt, err)
+
+		_, err = Parse("header.payload.signature")
+		assert.Error(tt, err)
+
+		_, err = Parse("header.payload.signature~WyJzYWx0Il0~")
+		assert.Error(tt, err)
+		assert.Contains(tt, err.Error(), "parsing disclosure 0")
+
+		_, err = Parse("not-a-jwt~WyI2cU1RdlJMNWhhaiIsICJmYW1pbHlfbmFtZSIsICJNw7ZiaXVzIl0~")
+		assert.Error(tt, err)
+		assert.Contains(tt, err.Error(), "JWT must be in compact serialization")
+
+		// a disclosure without a digest in the payload
+		signer, _ := getTestSignerVerifier(tt)
+		issued, err := Issue(*signer, map[string]any{"given_name": "Alice"}, nil)
+		require.NoError(tt, err)
+		_, err = Parse(issued.Serialize() + "WyI2cU1RdlJMNWhhaiIsICJmYW1pbHlfbmFtZSIsICJNw7ZiaXVzIl0~")
+		assert.Error(tt, err)
+		assert.Contains(tt, err.Error(), "resolving disclosures")
+	})
+}
+
+func TestPresent(t *testing.T) {
+	claims := map[string]any{
+		"iss":         "did:example:issuer",
+		"given_name":  "Alice",
+		"family_name": "Smith",
+		"email":       "alice@example.com",
+	}
+
+	t.Run("only revealed claims can be reconstructed", func(tt *testing.T) {
+		signer, verifier := getTestSignerVerifier(tt)
+		issued, err := Issue(*signer, claims, []string{"given_name", "family_name", "email"})
+		require.NoError(tt, err)
+
+		presentation, err := Present(issued, []string{"email"})
+		require.NoError(tt, err)
+
+		parsed, err := Parse(presentation)
+		require.NoError(tt, err)
+		assert.Equal(tt, issued.IssuerJWT, parsed.IssuerJWT)
+		require.Len(tt, parsed.Disclosures, 1)
+
+		// the issuer-signed JWT is intact
+		_, payload, err := verifier.VerifyWithHeaders(parsed.IssuerJWT)
+		require.NoError(tt, err)
+		digests, ok := payload[SDClaim].([]any)
+		require.True(tt, ok)
+		assert.Len(tt, digests, 3)
+
+		reconstructed := make(map[string]any)
+		for k, v := range payload {
+			if k != SDClaim && k != SDAlgClaim {
+				reconstructed[k] = v
+			}
+		}
+		for _, disclosure := range parsed.Disclosures {
+			assert.Contains(tt, digests, disclosure.Digest())
+			reconstructed[disclosure.Name] = disclosure.Value
+		}
+		assert.Equal(tt, map[string]any{"iss": "did:example:issuer", "email": "alice@example.com"}, reconstructed)
+	})
+
+	t.Run("reveal nothing", func(tt *testing.T) {
+		signer, _ := getTestSignerVerifier(tt)
+		issued, err := Issue(*signer, claims, []string{"given_name"})
+		require.NoError(tt, err)
+
+		presentation, err := Present(issued, nil)
+		require.NoError(tt, err)
+		assert.Equal(tt, issued.IssuerJWT+Separator, presentation)
+	})
+
+	t.Run("unknown claim", func(tt *testing.T) {
+		signer, _ := getTestSignerVerifier(tt)
+		issued, err := Issue(*signer, claims, []string{"given_name"})
+		require.NoError(tt, err)
+
+		_, err = Present(issued, []string{"given_name", "family_name"})
+		assert.Error(tt, err)
+		assert.Contains(tt, err.Error(), "SD-JWT has no disclosure of claim<family_name>")
+
+		_, err = Present(nil, nil)
+		assert.Error(tt, err)
+	})
+}
+
+func getTestSignerVerifier(t *testing.T) (*jwx.Signer, *jwx.Verifier) {
+	_, privKey, err := crypto.GenerateEd25519Key()
+	require.NoError(t, err)
+	signer, err := jwx.NewJWXSigner("did:example:issuer", "did:example:issuer#key-1", privKey)
+	require.NoError(t, err)
+	verifier, err := signer.ToVerifier("did:example:issuer")
+	require.NoError(t, err)
+	return signer, verifier
+}
@@ -0,0 +1,75 @@
+package sdjwt
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// selectorSegment is a step of a selector, either the name of an object member or the index of an array element
+type selectorSegment struct {
+	name    string
+	index   int
+	isIndex bool
+}
+
+// selector selects a claim of an SD-JWT payload, such as a top-level claim, an object member with address.street, or
+// an array element with nationalities[1]
+type selector []selectorSegment
+
+// parseSelector parses a selector in dot notation with array indices, optionally prefixed by the JSONPath root $
+func parseSelector(s string) (selector, error) {
+	rest := strings.TrimPrefix(strings.TrimPrefix(s, "$"), ".")
+	if rest == "" {
+		return nil, fmt.Errorf("selector<%s> selects no claim", s)
+	}
+	var segments selector
+	for _, part := range strings.Split(rest, ".") {
+		name, indices, hasIndex := strings.Cut(part, "[")
+		if name == "" {
+			return nil, fmt.Errorf("selector<%s> has an empty claim name", s)
+		}
+		if strings.ContainsAny(name, "]*") {
+			return nil, fmt.Errorf("selector<%s> has an invalid claim name: %s", s, name)
+		}
+		segments = append(segments, selectorSegment{name: name})
+		if !hasIndex {
+			continue
+		}
+		// each index is terminated by ], and followed by the next, if any
+		if !strings.HasSuffix(indices, "]") {
+			return nil, fmt.Errorf("selector<%s> has an unterminated array index", s)
+		}
+		for _, index := range strings.Split(strings.TrimSuffix(indices, "]"), "][") {
+			i, err := strconv.Atoi(index)
+			if err != nil || i < 0 {
+				return nil, fmt.Errorf("selector<%s> has an invalid array index: %s", s, index)
+			}
+			segments = append(segments, selectorSegment{index: i, isIndex: true})
+		}
+	}
+	return segments, nil
+}
+
+// String returns the selector in dot notation with array indices, without the JSONPath root
+func (s selector) String() string {
+	var sb strings.Builder
+	for i, segment := range s {
+		switch {
+		case segment.isIndex:
+			sb.WriteString("[" + strconv.Itoa(segment.index) + "]")
+		case i > 0:
+			sb.WriteString("." + segment.name)
+		default:
+			sb.WriteString(segment.name)
+		}
+	}
+	return sb.String()
+}
+
+// child returns the selector of a member or element of the claim the selector selects
+func (s selector) child(segment selectorSegment) selector {
+	child := make(selector, len(s), len(s)+1)
+	copy(child, s)
+	return append(child, segment)
+}
@@ -0,0 +1,48 @@
+package sdjwt
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseSelector(t *testing.T) {
+	t.Run("valid selectors", func(tt *testing.T) {
+		tests := map[string]struct {
+			expected   selector
+			normalized string
+		}{
+			"given_name": {
+				expected:   selector{{name: "given_name"}},
+				normalized: "given_name",
+			},
+			"$.address.street": {
+				expected:   selector{{name: "address"}, {name: "street"}},
+				normalized: "address.street",
+			},
+			"nationalities[1]": {
+				expected:   selector{{name: "nationalities"}, {index: 1, isIndex: true}},
+				normalized: "nationalities[1]",
+			},
+			"matrix[0][2].value": {
+				expected:   selector{{name: "matrix"}, {index: 0, isIndex: true}, {index: 2, isIndex: true}, {name: "value"}},
+				normalized: "matrix[0][2].value",
+			},
+		}
+		for s, test := range tests {
+			sel, err := parseSelector(s)
+			require.NoError(tt, err, s)
+			assert.Equal(tt, test.expected, sel, s)
+			assert.Equal(tt, test.normalized, sel.String(), s)
+		}
+	})
+
+	t.Run("invalid selectors", func(tt *testing.T) {
+		for _, s := range []string{"", "$", "address..street", "[0]", "nationalities[", "nationalities[-1]",
+			"nationalities[a]", "nationalities[*]", "address.*"} {
+			_, err := parseSelector(s)
+			assert.Error(tt, err, s)
+		}
+	})
+}
@@ -0,0 +1,317 @@
+package sdjwt
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/goccy/go-json"
+	"github.com/lestrrat-go/jwx/v2/jws"
+	"github.com/pkg/errors"
+
+	"github.com/TBD54566975/ssi-sdk/crypto/jwx"
+	"github.com/TBD54566975/ssi-sdk/util"
+)
+
+const (
+	// KeyBindingJWTType is the typ header of a Key Binding JWT
+	KeyBindingJWTType = "kb+jwt"
+
+	// ConfirmationClaim is the claim of an SD-JWT holding the key of its holder, to which presentations are bound
+	ConfirmationClaim = "cnf"
+
+	audienceClaim = "aud"
+	nonceClaim    = "nonce"
+	issuedAtClaim = "iat"
+	sdHashClaim   = "sd_hash"
+	jwkClaim      = "jwk"
+)
+
+// Option configures the time source of key binding and verification
+type Option func(*options)
+
+type options struct {
+	clock util.Clock
+}
+
+// WithClock is an Option taking the time from the given clock rather than the current time: for the iat claim of a
+// Key Binding JWT when adding one, and to validate the exp, nbf, and iat claims of the issuer's JWT and the Key
+// Binding JWT when verifying
+func WithClock(clock util.Clock) Option {
+	return func(o *options) {
+		o.clock = clock
+	}
+}
+
+func newOptions(opts []Option) options {
+	o := options{clock: util.RealClock{}}
+	for _, opt := range opts {
+		opt(&o)
+	}
+	return o
+}
+
+// AddKeyBinding appends a Key Binding JWT signed by the holder to the combined serialization of a presented SD-JWT,
+// binding the presentation to the audience and nonce of the verifier. The holder's key must be the key in the cnf
+// claim of the SD-JWT for the presentation to be verified.
+func AddKeyBinding(presentation string, holder jwx.Signer, audience, nonce string, opts ...Option) (string, error) {
+	if !strings.HasSuffix(presentation, Separator) {
+		return "", errors.New("presentation must end with a separator, and not already have a Key Binding JWT")
+	}
+	claims := map[string]any{
+		audienceClaim: audience,
+		nonceClaim:    nonce,
+		issuedAtClaim: newOptions(opts).clock.Now().Unix(),
+		sdHashClaim:   digest(presentation),
+	}
+	kbJWT, err := holder.SignWithHeaders(claims, map[string]any{jws.TypeKey: KeyBindingJWTType})
+	if err != nil {
+		return "", errors.Wrap(err, "signing Key Binding JWT")
+	}
+	return presentation + kbJWT, nil
+}
+
+// Verify verifies the issuer's signature of a presented SD-JWT, and that each of its disclosures has a digest in the
+// payload, returning the claims with each disclosed claim in place of its digest, including the members of nested
+// objects and elements of arrays. Digests without a disclosure are removed, as are the _sd and _sd_alg claims.
+// When a Key Binding JWT is appended, it must be signed by the key in the cnf claim of the SD-JWT, and its aud, nonce
+// and sd_hash must match the expected audience and nonce and the rest of the presentation. If an expected audience or
+// nonce is given, the presentation must have a Key Binding JWT. The time claims of both JWTs are validated against the
+// current time, or the clock given with WithClock.
+func Verify(presentation string, issuerKey jwx.PublicKeyJWK, expectedAudience, expectedNonce string, opts ...Option) (map[string]any, error) {
+	parsed, err := Parse(presentation)
+	if err != nil {
+		return nil, errors.Wrap(err, "parsing SD-JWT")
+	}
+
+	clock := newOptions(opts).clock
+	verifier, err := jwx.NewJWXVerifierFromJWK("", issuerKey, jwx.WithClock(clock))
+	if err != nil {
+		return nil, errors.Wrap(err, "creating issuer verifier")
+	}
+	_, payload, err := verifier.VerifyWithHeaders(parsed.IssuerJWT)
+	if err != nil {
+		return nil, errors.Wrap(err, "verifying issuer signature")
+	}
+	if alg, ok := payload[SDAlgClaim]; ok && alg != SHA256Alg {
+		return nil, fmt.Errorf("unsupported %s: %v", SDAlgClaim, alg)
+	}
+
+	if parsed.KeyBindingJWT != "" {
+		sdJWT := strings.TrimSuffix(presentation, parsed.KeyBindingJWT)
+		if err = verifyKeyBinding(parsed.KeyBindingJWT, sdJWT, payload, expectedAudience, expectedNonce, clock); err != nil {
+			return nil, errors.Wrap(err, "verifying Key Binding JWT")
+		}
+	} else if expectedAudience != "" || expectedNonce != "" {
+		return nil, errors.New("presentation has no Key Binding JWT for the expected audience and nonce")
+	}
+
+	claims, err := disclose(payload, parsed.Disclosures)
+	if err != nil {
+		return nil, errors.Wrap(err, "disclosing claims")
+	}
+	return claims, nil
+}
+
+// verifyKeyBinding verifies a Key Binding JWT against the holder's key in the SD-JWT's payload, and that it binds the
+// rest of the presentation to the expected audience and nonce
+func verifyKeyBinding(kbJWT, sdJWT string, payload map[string]any, expectedAudience, expectedNonce string, clock util.Clock) error {
+	cnf, ok := payload[ConfirmationClaim].(map[string]any)
+	if !ok {
+		return fmt.Errorf("SD-JWT has no %s claim with the holder's key", ConfirmationClaim)
+	}
+	holderKeyBytes, err := json.Marshal(cnf[jwkClaim])
+	if err != nil {
+		return errors.Wrap(err, "marshalling holder key")
+	}
+	var holderKey jwx.PublicKeyJWK
+	if err = json.Unmarshal(holderKeyBytes, &holderKey); err != nil {
+		return errors.Wrap(err, "unmarshalling holder key")
+	}
+	verifier, err := jwx.NewJWXVerifierFromJWK("", holderKey, jwx.WithClock(clock))
+	if err != nil {
+		return errors.Wrap(err, "creating holder verifier")
+	}
+	headers, claims, err := verifier.VerifyWithHeaders(kbJWT)
+	if err != nil {
+		return errors.Wrap(err, "verifying holder signature")
+	}
+
+	if headers.Type() != KeyBindingJWTType {
+		return fmt.Errorf("typ header must be %s, got: %s", KeyBindingJWTType, headers.Type())
+	}
+	if _, ok = claims[issuedAtClaim]; !ok {
+		return fmt.Errorf("%s claim is required", issuedAtClaim)
+	}
+	if aud, _ := claims[audienceClaim].(string); aud != expectedAudience {
+		return fmt.Errorf("%s<%s> does not match expected audience<%s>", audienceClaim, aud, expectedAudience)
+	}
+	if nonce, _ := claims[nonceClaim].(string); nonce != expectedNonce {
+		return fmt.Errorf("%s<%s> does not match expected nonce<%s>", nonceClaim, nonce, expectedNonce)
+	}
+	if sdHash, _ := claims[sdHashClaim].(string); sdHash != digest(sdJWT) {
+		return fmt.Errorf("%s does not match the presented SD-JWT", sdHashClaim)
+	}
+	return nil
+}
+
+// disclose replaces the digests of a payload with the claims of their disclosures, recursively, and sets the path of
+// each disclosure. Digests in _sd claims are replaced by object members, and array elements in the form
+// {"...": "<digest>"} by the elements they disclose; digests without a disclosure are removed. Each disclosure must
+// match exactly one digest, and cannot overwrite a claim of the payload.
+func disclose(payload map[string]any, disclosures []Disclosure) (map[string]any, error) {
+	byDigest := make(map[string]int, len(disclosures))
+	for i, disclosure := range disclosures {
+		d := disclosure.Digest()
+		if _, ok := byDigest[d]; ok {
+			return nil, fmt.Errorf("disclosure of %s presented more than once", describeDisclosure(disclosure))
+		}
+		byDigest[d] = i
+	}
+	d := discloser{disclosures: disclosures, byDigest: byDigest, seen: make(map[string]bool)}
+	claims, err := d.discloseObject(payload, nil)
+	if err != nil {
+		return nil, err
+	}
+	delete(claims, SDAlgClaim)
+	for _, disclosure := range disclosures {
+		if !d.seen[disclosure.Digest()] {
+			return nil, fmt.Errorf("digest of disclosure of %s not found in the SD-JWT", describeDisclosure(disclosure))
+		}
+	}
+	return claims, nil
+}
+
+// discloser tracks the digests found while disclosing the claims of a payload
+type discloser struct {
+	disclosures []Disclosure
+	byDigest    map[string]int
+	seen        map[string]bool
+}
+
+// disclosure returns the disclosure of a digest found in the payload, if it was presented
+func (d *discloser) disclosure(digest string) (*Disclosure, bool, error) {
+	if d.seen[digest] {
+		return nil, false, fmt.Errorf("digest<%s> appears more than once in the SD-JWT", digest)
+	}
+	d.seen[digest] = true
+	i, ok := d.byDigest[digest]
+	if !ok {
+		return nil, false, nil
+	}
+	return &d.disclosures[i], true, nil
+}
+
+func (d *discloser) disclose(value any, path selector) (any, error) {
+	switch v := value.(type) {
+	case map[string]any:
+		return d.discloseObject(v, path)
+	case []any:
+		return d.discloseArray(v, path)
+	default:
+		return value, nil
+	}
+}
+
+func (d *discloser) discloseObject(object map[string]any, path selector) (map[string]any, error) {
+	disclosed := make(map[string]any, len(object))
+	for k, v := range object {
+		if k == SDClaim {
+			continue
+		}
+		member, err := d.disclose(v, path.child(selectorSegment{name: k}))
+		if err != nil {
+			return nil, err
+		}
+		disclosed[k] = member
+	}
+
+	sd, ok := object[SDClaim]
+	if !ok {
+		return disclosed, nil
+	}
+	digests, ok := sd.([]any)
+	if !ok {
+		return nil, fmt.Errorf("%s claim must be an array", SDClaim)
+	}
+	for _, digestValue := range digests {
+		digestString, ok := digestValue.(string)
+		if !ok {
+			return nil, fmt.Errorf("%s claim must be an array of strings", SDClaim)
+		}
+		disclosure, ok, err := d.disclosure(digestString)
+		if err != nil {
+			return nil, err
+		}
+		if !ok {
+			continue
+		}
+		if disclosure.IsArrayElement() {
+			return nil, errors.New("disclosure of an array element cannot disclose an object member")
+		}
+		if reservedClaims[disclosure.Name] {
+			return nil, fmt.Errorf("disclosure cannot disclose the reserved claim %s", disclosure.Name)
+		}
+		if _, ok = object[disclosure.Name]; ok {
+			return nil, fmt.Errorf("disclosure of claim<%s> overwrites an existing claim", disclosure.Name)
+		}
+		memberPath := path.child(selectorSegment{name: disclosure.Name})
+		disclosure.Path = memberPath.String()
+		member, err := d.disclose(disclosure.Value, memberPath)
+		if err != nil {
+			return nil, err
+		}
+		disclosed[disclosure.Name] = member
+	}
+	return disclosed, nil
+}
+
+func (d *discloser) discloseArray(array []any, path selector) ([]any, error) {
+	disclosed := make([]any, 0, len(array))
+	for i, element := range array {
+		elementPath := path.child(selectorSegment{index: i, isIndex: true})
+		digestString, isDigest := arrayElementDigest(element)
+		if !isDigest {
+			disclosedElement, err := d.disclose(element, elementPath)
+			if err != nil {
+				return nil, err
+			}
+			disclosed = append(disclosed, disclosedElement)
+			continue
+		}
+		disclosure, ok, err := d.disclosure(digestString)
+		if err != nil {
+			return nil, err
+		}
+		if !ok {
+			continue
+		}
+		if !disclosure.IsArrayElement() {
+			return nil, fmt.Errorf("disclosure of claim<%s> cannot disclose an array element", disclosure.Name)
+		}
+		disclosure.Path = elementPath.String()
+		disclosedElement, err := d.disclose(disclosure.Value, elementPath)
+		if err != nil {
+			return nil, err
+		}
+		disclosed = append(disclosed, disclosedElement)
+	}
+	return disclosed, nil
+}
+
+// arrayElementDigest returns the digest of a selectively disclosable array element, if the element is one
+func arrayElementDigest(element any) (string, bool) {
+	object, ok := element.(map[string]any)
+	if !ok || len(object) != 1 {
+		return "", false
+	}
+	digestString, ok := object[ArrayElementDigestKey].(string)
+	return digestString, ok
+}
+
+// describeDisclosure names the claim of a disclosure for error messages
+func describeDisclosure(disclosure Disclosure) string {
+	if disclosure.IsArrayElement() {
+		return "an array element"
+	}
+	return "claim<" + disclosure.Name + ">"
+}
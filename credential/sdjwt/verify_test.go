@@ -0,0 +1,280 @@
+package sdjwt
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/TBD54566975/ssi-sdk/crypto"
+	"github.com/TBD54566975/ssi-sdk/crypto/jwx"
+	"github.com/TBD54566975/ssi-sdk/util"
+)
+
+func TestVerify(t *testing.T) {
+	issuerSigner, issuerKey := getTestSignerAndKey(t, "did:example:issuer")
+	holderSigner, holderKey := getTestSignerAndKey(t, "did:example:holder")
+	claims := map[string]any{
+		"iss":         "did:example:issuer",
+		"given_name":  "Alice",
+		"family_name": "Smith",
+		"email":       "alice@example.com",
+		"cnf":         map[string]any{"jwk": holderKey},
+	}
+	issued, err := Issue(*issuerSigner, claims, []string{"given_name", "family_name", "email"})
+	require.NoError(t, err)
+
+	t.Run("presentation with key binding", func(tt *testing.T) {
+		presentation, err := Present(issued, []string{"given_name", "email"})
+		require.NoError(tt, err)
+		presentation, err = AddKeyBinding(presentation, *holderSigner, "did:example:verifier", "1234")
+		require.NoError(tt, err)
+
+		disclosed, err := Verify(presentation, *issuerKey, "did:example:verifier", "1234")
+		require.NoError(tt, err)
+		assert.Equal(tt, "did:example:issuer", disclosed["iss"])
+		assert.Equal(tt, "Alice", disclosed["given_name"])
+		assert.Equal(tt, "alice@example.com", disclosed["email"])
+		assert.NotContains(tt, disclosed, "family_name")
+		assert.NotContains(tt, disclosed, SDClaim)
+		assert.NotContains(tt, disclosed, SDAlgClaim)
+	})
+
+	t.Run("presentation without key binding", func(tt *testing.T) {
+		disclosed, err := Verify(issued.Serialize(), *issuerKey, "", "")
+		require.NoError(tt, err)
+		assert.Equal(tt, "Smith", disclosed["family_name"])
+
+		_, err = Verify(issued.Serialize(), *issuerKey, "did:example:verifier", "1234")
+		assert.Error(tt, err)
+		assert.Contains(tt, err.Error(), "presentation has no Key Binding JWT")
+	})
+
+	t.Run("wrong issuer key", func(tt *testing.T) {
+		_, err := Verify(issued.Serialize(), *holderKey, "", "")
+		assert.Error(tt, err)
+		assert.Contains(tt, err.Error(), "verifying issuer signature")
+	})
+
+	t.Run("tampered disclosure", func(tt *testing.T) {
+		// forge a disclosure of a disclosed claim with another value
+		forged, err := newDisclosure(issued.Disclosures[0].Name, "Mallory")
+		require.NoError(tt, err)
+		tampered := *issued
+		tampered.Disclosures = []Disclosure{*forged}
+
+		_, err = Verify(tampered.Serialize(), *issuerKey, "", "")
+		assert.Error(tt, err)
+		assert.Contains(tt, err.Error(), "not found in the SD-JWT")
+	})
+
+	t.Run("disclosure presented twice", func(tt *testing.T) {
+		duplicated := *issued
+		duplicated.Disclosures = []Disclosure{issued.Disclosures[0], issued.Disclosures[0]}
+		_, err := Verify(duplicated.Serialize(), *issuerKey, "", "")
+		assert.Error(tt, err)
+		assert.Contains(tt, err.Error(), "disclosure of claim<given_name> presented more than once")
+	})
+
+	t.Run("key binding with wrong audience", func(tt *testing.T) {
+		presentation, err := Present(issued, []string{"email"})
+		require.NoError(tt, err)
+		presentation, err = AddKeyBinding(presentation, *holderSigner, "did:example:other", "1234")
+		require.NoError(tt, err)
+
+		_, err = Verify(presentation, *issuerKey, "did:example:verifier", "1234")
+		assert.Error(tt, err)
+		assert.Contains(tt, err.Error(), "aud<did:example:other> does not match expected audience<did:example:verifier>")
+	})
+
+	t.Run("key binding with wrong nonce", func(tt *testing.T) {
+		presentation, err := Present(issued, []string{"email"})
+		require.NoError(tt, err)
+		presentation, err = AddKeyBinding(presentation, *holderSigner, "did:example:verifier", "5678")
+		require.NoError(tt, err)
+
+		_, err = Verify(presentation, *issuerKey, "did:example:verifier", "1234")
+		assert.Error(tt, err)
+		assert.Contains(tt, err.Error(), "nonce<5678> does not match expected nonce<1234>")
+	})
+
+	t.Run("key binding for other disclosures", func(tt *testing.T) {
+		presentation, err := Present(issued, []string{"email"})
+		require.NoError(tt, err)
+		bound, err := AddKeyBinding(presentation, *holderSigner, "did:example:verifier", "1234")
+		require.NoError(tt, err)
+		parsed, err := Parse(bound)
+		require.NoError(tt, err)
+
+		// reveal another claim under the same key binding
+		other, err := Present(issued, []string{"email", "given_name"})
+		require.NoError(tt, err)
+		_, err = Verify(other+parsed.KeyBindingJWT, *issuerKey, "did:example:verifier", "1234")
+		assert.Error(tt, err)
+		assert.Contains(tt, err.Error(), "sd_hash does not match the presented SD-JWT")
+	})
+
+	t.Run("key binding signed by another key", func(tt *testing.T) {
+		otherSigner, _ := getTestSignerAndKey(tt, "did:example:other")
+		presentation, err := Present(issued, []string{"email"})
+		require.NoError(tt, err)
+		presentation, err = AddKeyBinding(presentation, *otherSigner, "did:example:verifier", "1234")
+		require.NoError(tt, err)
+
+		_, err = Verify(presentation, *issuerKey, "did:example:verifier", "1234")
+		assert.Error(tt, err)
+		assert.Contains(tt, err.Error(), "verifying holder signature")
+	})
+
+	t.Run("key binding without holder key", func(tt *testing.T) {
+		unbound, err := Issue(*issuerSigner, map[string]any{"email": "alice@example.com"}, []string{"email"})
+		require.NoError(tt, err)
+		presentation, err := AddKeyBinding(unbound.Serialize(), *holderSigner, "did:example:verifier", "1234")
+		require.NoError(tt, err)
+
+		_, err = Verify(presentation, *issuerKey, "did:example:verifier", "1234")
+		assert.Error(tt, err)
+		assert.Contains(tt, err.Error(), "SD-JWT has no cnf claim")
+	})
+
+	t.Run("validated against the given clock", func(tt *testing.T) {
+		now := time.Now()
+		expired, err := Issue(*issuerSigner, map[string]any{
+			"email": "alice@example.com",
+			"exp":   now.Add(-time.Hour).Unix(),
+			"cnf":   map[string]any{"jwk": holderKey},
+		}, []string{"email"})
+		require.NoError(tt, err)
+		presentation, err := AddKeyBinding(expired.Serialize(), *holderSigner, "did:example:verifier", "1234",
+			WithClock(util.FixedClock(now.Add(-2*time.Hour))))
+		require.NoError(tt, err)
+
+		_, err = Verify(presentation, *issuerKey, "did:example:verifier", "1234")
+		assert.Error(tt, err)
+		assert.Contains(tt, err.Error(), "verifying issuer signature")
+
+		disclosed, err := Verify(presentation, *issuerKey, "did:example:verifier", "1234",
+			WithClock(util.FixedClock(now.Add(-90*time.Minute))))
+		require.NoError(tt, err)
+		assert.Equal(tt, "alice@example.com", disclosed["email"])
+
+		// the Key Binding JWT was issued after the time of the clock
+		_, err = Verify(presentation, *issuerKey, "did:example:verifier", "1234",
+			WithClock(util.FixedClock(now.Add(-3*time.Hour))))
+		assert.Error(tt, err)
+		assert.Contains(tt, err.Error(), "verifying Key Binding JWT")
+	})
+}
+
+func TestVerifyNestedDisclosures(t *testing.T) {
+	issuerSigner, issuerKey := getTestSignerAndKey(t, "did:example:issuer")
+	claims := map[string]any{
+		"iss":           "did:example:issuer",
+		"nationalities": []any{"US", "DE", "FR"},
+		"address": map[string]any{
+			"street":   "123 Main St",
+			"locality": "Anytown",
+			"country":  "US",
+		},
+	}
+	issued, err := Issue(*issuerSigner, claims, []string{"nationalities[1]", "address.street"})
+	require.NoError(t, err)
+	require.Len(t, issued.Disclosures, 2)
+
+	t.Run("payload holds digests in place of the hidden claims", func(tt *testing.T) {
+		payload, err := decodePayload(issued.IssuerJWT)
+		require.NoError(tt, err)
+		assert.NotContains(tt, payload, SDClaim)
+
+		nationalities, ok := payload["nationalities"].([]any)
+		require.True(tt, ok)
+		require.Len(tt, nationalities, 3)
+		assert.Equal(tt, "US", nationalities[0])
+		assert.Equal(tt, "FR", nationalities[2])
+		elementDigest, ok := arrayElementDigest(nationalities[1])
+		require.True(tt, ok)
+
+		address, ok := payload["address"].(map[string]any)
+		require.True(tt, ok)
+		assert.NotContains(tt, address, "street")
+		assert.Equal(tt, []any{issued.Disclosures[1].Digest()}, address[SDClaim])
+
+		assert.Equal(tt, "nationalities[1]", issued.Disclosures[0].Path)
+		assert.True(tt, issued.Disclosures[0].IsArrayElement())
+		assert.Equal(tt, issued.Disclosures[0].Digest(), elementDigest)
+		assert.Equal(tt, "address.street", issued.Disclosures[1].Path)
+		assert.Equal(tt, "street", issued.Disclosures[1].Name)
+	})
+
+	t.Run("all claims revealed", func(tt *testing.T) {
+		presentation, err := Present(issued, []string{"nationalities[1]", "address.street"})
+		require.NoError(tt, err)
+
+		disclosed, err := Verify(presentation, *issuerKey, "", "")
+		require.NoError(tt, err)
+		assert.Equal(tt, claims, disclosed)
+	})
+
+	t.Run("only the array element revealed", func(tt *testing.T) {
+		presentation, err := Present(issued, []string{"$.nationalities[1]"})
+		require.NoError(tt, err)
+
+		disclosed, err := Verify(presentation, *issuerKey, "", "")
+		require.NoError(tt, err)
+		assert.Equal(tt, []any{"US", "DE", "FR"}, disclosed["nationalities"])
+		assert.Equal(tt, map[string]any{"locality": "Anytown", "country": "US"}, disclosed["address"])
+	})
+
+	t.Run("nothing revealed", func(tt *testing.T) {
+		presentation, err := Present(issued, nil)
+		require.NoError(tt, err)
+
+		disclosed, err := Verify(presentation, *issuerKey, "", "")
+		require.NoError(tt, err)
+		assert.Equal(tt, []any{"US", "FR"}, disclosed["nationalities"])
+		assert.Equal(tt, map[string]any{"locality": "Anytown", "country": "US"}, disclosed["address"])
+	})
+
+	t.Run("claim within a selectively disclosable claim", func(tt *testing.T) {
+		nested, err := Issue(*issuerSigner, claims, []string{"address", "address.street"})
+		require.NoError(tt, err)
+
+		// revealing the street reveals the address containing it
+		presentation, err := Present(nested, []string{"address.street"})
+		require.NoError(tt, err)
+		parsed, err := Parse(presentation)
+		require.NoError(tt, err)
+		assert.Len(tt, parsed.Disclosures, 2)
+		disclosed, err := Verify(presentation, *issuerKey, "", "")
+		require.NoError(tt, err)
+		assert.Equal(tt, claims["address"], disclosed["address"])
+
+		// revealing only the address hides the street
+		presentation, err = Present(nested, []string{"address"})
+		require.NoError(tt, err)
+		disclosed, err = Verify(presentation, *issuerKey, "", "")
+		require.NoError(tt, err)
+		assert.Equal(tt, map[string]any{"locality": "Anytown", "country": "US"}, disclosed["address"])
+	})
+
+	t.Run("forged array element disclosure", func(tt *testing.T) {
+		forged, err := newDisclosure("", "Mallory")
+		require.NoError(tt, err)
+		tampered := SDJWT{IssuerJWT: issued.IssuerJWT, Disclosures: []Disclosure{issued.Disclosures[0], *forged}}
+
+		_, err = Verify(tampered.Serialize(), *issuerKey, "", "")
+		assert.Error(tt, err)
+		assert.Contains(tt, err.Error(), "digest of disclosure of an array element not found in the SD-JWT")
+	})
+}
+
+func getTestSignerAndKey(t *testing.T, id string) (*jwx.Signer, *jwx.PublicKeyJWK) {
+	pubKey, privKey, err := crypto.GenerateEd25519Key()
+	require.NoError(t, err)
+	signer, err := jwx.NewJWXSigner(id, id+"#key-1", privKey)
+	require.NoError(t, err)
+	key, err := jwx.PublicKeyToPublicKeyJWK(pubKey)
+	require.NoError(t, err)
+	return signer, key
+}
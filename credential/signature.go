@@ -5,15 +5,18 @@ import (
 	"encoding/json"
 	"fmt"
 	"reflect"
+	"strings"
 
 	"github.com/TBD54566975/ssi-sdk/crypto/jwx"
+	"github.com/TBD54566975/ssi-sdk/cryptosuite"
 	"github.com/TBD54566975/ssi-sdk/did"
+	"github.com/TBD54566975/ssi-sdk/util"
 	"github.com/pkg/errors"
 )
 
-// VerifyCredentialSignature verifies the signature of a credential of any type
-// TODO(gabe) support other types of credentials https://github.com/TBD54566975/ssi-sdk/issues/352
-func VerifyCredentialSignature(ctx context.Context, genericCred any, resolver did.Resolver) (bool, error) {
+// VerifyCredentialSignature verifies the signature of a credential of any type. A string is verified as a VC JWT, or
+// as the JSON of a credential, which, like a credential given as an object, is verified by its data integrity proofs.
+func VerifyCredentialSignature(ctx context.Context, genericCred any, resolver did.Resolver, opts ...VerifyOption) (bool, error) {
 	if genericCred == nil {
 		return false, errors.New("credential cannot be empty")
 	}
@@ -35,60 +38,196 @@ func VerifyCredentialSignature(ctx context.Context, genericCred any, resolver di
 		if cred.GetProof() == nil {
 			return false, errors.New("credential must have a proof")
 		}
-		return false, errors.New("data integrity signature verification not yet implemented")
+		credMap, err := util.ToJSONMap(genericCred)
+		if err != nil {
+			return false, errors.Wrap(err, "converting credential")
+		}
+		if err = verifyDataIntegrityCredential(ctx, credMap, *cred, resolver); err != nil {
+			return false, err
+		}
+		return true, nil
 	case []byte:
 		// turn it into a string and try again
-		return VerifyCredentialSignature(ctx, string(genericCred.([]byte)), resolver)
+		return VerifyCredentialSignature(ctx, string(genericCred.([]byte)), resolver, opts...)
 	case string:
-		// could be a Data Integrity credential
-		var cred VerifiableCredential
-		if err := json.Unmarshal([]byte(genericCred.(string)), &cred); err == nil {
-			return VerifyCredentialSignature(ctx, cred, resolver)
+		// could be a Data Integrity credential, whose properties are all covered by its proofs
+		var credMap map[string]any
+		if err := json.Unmarshal([]byte(genericCred.(string)), &credMap); err == nil {
+			return VerifyCredentialSignature(ctx, credMap, resolver, opts...)
 		}
 
 		// could be a JWT
-		return VerifyJWTCredential(genericCred.(string), resolver)
+		return VerifyJWTCredential(ctx, genericCred.(string), resolver, opts...)
 	}
 	return false, fmt.Errorf("invalid credential type: %s", reflect.TypeOf(genericCred).Kind().String())
 }
 
+// verifyDataIntegrityCredential verifies the data integrity proofs of a credential, given as both its JSON and the
+// credential, with the keys of its issuer, each of which must be authorized for assertionMethod
+func verifyDataIntegrityCredential(ctx context.Context, credMap map[string]any, cred VerifiableCredential, resolver did.Resolver) error {
+	issuer, err := cred.IssuerID()
+	if err != nil {
+		return errors.Wrap(err, "getting issuer of credential")
+	}
+	return verifyDataIntegrityProofs(ctx, credMap, resolver, issuer,
+		cryptosuite.WithExpectedProofPurpose(ctx, cryptosuite.AssertionMethod, relativeMethodAuthorizer{
+			controller: issuer,
+			authorizer: did.NewProofPurposeAuthorizer(resolver),
+		}))
+}
+
+// ErrIssuerDeactivated is returned when the issuer DID of a credential resolves to a deactivated document
+var ErrIssuerDeactivated = errors.New("issuer DID is deactivated")
+
+// JWTVerificationErrorType categorizes why a JWT credential failed verification
+type JWTVerificationErrorType string
+
+const (
+	// IssuerResolutionError means the issuer's DID could not be resolved
+	IssuerResolutionError JWTVerificationErrorType = "IssuerResolutionError"
+	// KeyMismatchError means the key identified by the kid header is not a key of the issuer
+	KeyMismatchError JWTVerificationErrorType = "KeyMismatchError"
+	// SignatureError means the signature of the token is not valid for the issuer's key
+	SignatureError JWTVerificationErrorType = "SignatureError"
+	// IssuerMismatchError means the iss claim does not match the issuer of the embedded credential
+	IssuerMismatchError JWTVerificationErrorType = "IssuerMismatchError"
+)
+
+// JWTVerificationError is returned when a JWT credential fails verification, identifying the step that failed
+type JWTVerificationError struct {
+	Type JWTVerificationErrorType
+	Err  error
+}
+
+func (e *JWTVerificationError) Error() string {
+	return e.Err.Error()
+}
+
+func (e *JWTVerificationError) Unwrap() error {
+	return e.Err
+}
+
+// IsJWTVerificationError returns whether the error is, or wraps, a JWTVerificationError of the given type
+func IsJWTVerificationError(err error, errType JWTVerificationErrorType) bool {
+	var verificationErr *JWTVerificationError
+	return errors.As(err, &verificationErr) && verificationErr.Type == errType
+}
+
+// VerifyOption configures how a credential is verified
+type VerifyOption func(*verifyOptions)
+
+type verifyOptions struct {
+	clock util.Clock
+}
+
+// WithClock is a VerifyOption validating the validity period of a credential against the given clock rather than the
+// current time, such as to verify a credential as of a time when it was valid
+func WithClock(clock util.Clock) VerifyOption {
+	return func(o *verifyOptions) {
+		o.clock = clock
+	}
+}
+
+func newVerifyOptions(opts []VerifyOption) verifyOptions {
+	options := verifyOptions{clock: util.RealClock{}}
+	for _, opt := range opts {
+		opt(&options)
+	}
+	return options
+}
+
 // VerifyJWTCredential verifies the signature of a JWT credential after parsing it to resolve the issuer DID
 // The issuer DID is resolver from the provided resolver, and used to find the issuer's public key matching
-// the KID in the JWT header.
-func VerifyJWTCredential(cred string, resolver did.Resolver) (bool, error) {
-	if cred == "" {
-		return false, errors.New("credential cannot be empty")
+// the KID in the JWT header. The context bounds the resolution of the issuer DID.
+func VerifyJWTCredential(ctx context.Context, cred string, resolver did.Resolver, opts ...VerifyOption) (bool, error) {
+	if _, err := VerifyVerifiableCredentialJWTWithResolver(ctx, cred, resolver, opts...); err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+// VerifyVerifiableCredentialJWTWithResolver verifies the signature of a JWT credential with the key of its issuer,
+// and returns the credential. The issuer's DID, given by the iss claim, is resolved with the provided resolver to
+// find the verification method matching the KID in the JWT header, bounded by the context. The credential is
+// reconstructed from the vc claim, with the registered JWT claims taking precedence where present, and the iss claim
+// must match its issuer.
+// A credential whose issuer DID has been deactivated is rejected with an error wrapping ErrIssuerDeactivated. The exp
+// and nbf claims are validated against the current time, or the clock given with WithClock.
+// Failures are returned as a JWTVerificationError identifying the step that failed.
+func VerifyVerifiableCredentialJWTWithResolver(ctx context.Context, token string, resolver did.Resolver, opts ...VerifyOption) (*VerifiableCredential, error) {
+	if token == "" {
+		return nil, errors.New("credential cannot be empty")
 	}
 	if resolver == nil {
-		return false, errors.New("resolver cannot be empty")
+		return nil, errors.New("resolver cannot be empty")
 	}
-	headers, token, _, err := ParseVerifiableCredentialFromJWT(cred)
+	headers, parsed, cred, err := ParseVerifiableCredentialFromJWT(token)
+	if err != nil {
+		return nil, errors.Wrap(err, "parsing JWT")
+	}
+
+	// the issuer of the embedded credential must be the one that signed the token
+	issuer := parsed.Issuer()
+	credIssuer, err := cred.IssuerID()
 	if err != nil {
-		return false, errors.Wrap(err, "parsing JWT")
+		return nil, errors.Wrapf(err, "getting issuer of credential<%s>", parsed.JwtID())
+	}
+	if issuer == "" {
+		issuer = credIssuer
+	}
+	if issuer != credIssuer {
+		return nil, &JWTVerificationError{
+			Type: IssuerMismatchError,
+			Err:  errors.Errorf("iss<%s> does not match issuer<%s> of credential<%s>", issuer, credIssuer, parsed.JwtID()),
+		}
 	}
 
 	// get key to verify the credential with
 	issuerKID := headers.KeyID()
 	if issuerKID == "" {
-		return false, errors.Errorf("missing kid in header of credential<%s>", token.JwtID())
+		return nil, &JWTVerificationError{
+			Type: KeyMismatchError,
+			Err:  errors.Errorf("missing kid in header of credential<%s>", parsed.JwtID()),
+		}
+	}
+	if kidDID, _, found := strings.Cut(issuerKID, "#"); found && strings.HasPrefix(kidDID, "did:") && kidDID != issuer {
+		return nil, &JWTVerificationError{
+			Type: KeyMismatchError,
+			Err:  errors.Errorf("kid<%s> is not a key of issuer<%s> of credential<%s>", issuerKID, issuer, parsed.JwtID()),
+		}
 	}
-	issuerDID, err := resolver.Resolve(context.Background(), token.Issuer())
+	issuerDID, err := resolver.Resolve(ctx, issuer)
 	if err != nil {
-		return false, errors.Wrapf(err, "error getting issuer DID<%s> to verify credential<%s>", token.Issuer(), token.JwtID())
+		return nil, &JWTVerificationError{
+			Type: IssuerResolutionError,
+			Err:  errors.Wrapf(err, "error getting issuer DID<%s> to verify credential<%s>", issuer, parsed.JwtID()),
+		}
+	}
+	if issuerDID.IsDeactivated() {
+		return nil, &JWTVerificationError{
+			Type: IssuerResolutionError,
+			Err:  errors.Wrapf(ErrIssuerDeactivated, "issuer DID<%s> of credential<%s>", issuer, parsed.JwtID()),
+		}
 	}
 	issuerKey, err := did.GetKeyFromVerificationMethod(issuerDID.Document, issuerKID)
 	if err != nil {
-		return false, errors.Wrapf(err, "error getting key to verify credential<%s>", token.JwtID())
+		return nil, &JWTVerificationError{
+			Type: KeyMismatchError,
+			Err:  errors.Wrapf(err, "error getting key to verify credential<%s>", parsed.JwtID()),
+		}
 	}
 
 	// construct a verifier
-	credVerifier, err := jwx.NewJWXVerifier(issuerDID.ID, issuerKey)
+	credVerifier, err := jwx.NewJWXVerifier(issuerDID.ID, issuerKey, jwx.WithClock(newVerifyOptions(opts).clock))
 	if err != nil {
-		return false, errors.Wrapf(err, "error constructing verifier for credential<%s>", token.JwtID())
+		return nil, errors.Wrapf(err, "error constructing verifier for credential<%s>", parsed.JwtID())
 	}
 	// verify the signature
-	if err = credVerifier.Verify(cred); err != nil {
-		return false, errors.Wrapf(err, "error verifying credential<%s>", token.JwtID())
+	if err = credVerifier.Verify(token); err != nil {
+		return nil, &JWTVerificationError{
+			Type: SignatureError,
+			Err:  errors.Wrapf(err, "error verifying credential<%s>", parsed.JwtID()),
+		}
 	}
-	return true, nil
+	return cred, nil
 }
@@ -8,8 +8,10 @@ import (
 	"github.com/TBD54566975/ssi-sdk/crypto"
 	"github.com/TBD54566975/ssi-sdk/crypto/jwx"
 	"github.com/TBD54566975/ssi-sdk/did"
+	"github.com/TBD54566975/ssi-sdk/util"
 	"github.com/goccy/go-json"
 	"github.com/google/uuid"
+	"github.com/lestrrat-go/jwx/v2/jwt"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 )
@@ -116,13 +118,13 @@ func TestVerifyCredentialSignature(t *testing.T) {
 
 func TestVerifyJWTCredential(t *testing.T) {
 	t.Run("empty credential", func(tt *testing.T) {
-		_, err := VerifyJWTCredential("", nil)
+		_, err := VerifyJWTCredential(context.Background(), "", nil)
 		assert.Error(tt, err)
 		assert.Contains(tt, err.Error(), "credential cannot be empty")
 	})
 
 	t.Run("empty resolver", func(tt *testing.T) {
-		_, err := VerifyJWTCredential("not-empty", nil)
+		_, err := VerifyJWTCredential(context.Background(), "not-empty", nil)
 		assert.Error(tt, err)
 		assert.Contains(tt, err.Error(), "resolver cannot be empty")
 	})
@@ -130,7 +132,7 @@ func TestVerifyJWTCredential(t *testing.T) {
 	t.Run("invalid credential", func(tt *testing.T) {
 		resolver, err := did.NewResolver([]did.Resolver{did.KeyResolver{}}...)
 		assert.NoError(tt, err)
-		_, err = VerifyJWTCredential("not-empty", resolver)
+		_, err = VerifyJWTCredential(context.Background(), "not-empty", resolver)
 		assert.Error(tt, err)
 		assert.Contains(tt, err.Error(), "invalid JWT")
 	})
@@ -145,7 +147,7 @@ func TestVerifyJWTCredential(t *testing.T) {
 		assert.NoError(tt, err)
 
 		jwtCred := getTestJWTCredential(tt, *signer)
-		_, err = VerifyJWTCredential(jwtCred, resolver)
+		_, err = VerifyJWTCredential(context.Background(), jwtCred, resolver)
 		assert.Error(tt, err)
 		assert.Contains(tt, err.Error(), "error getting issuer DID<test-id> to verify credential")
 	})
@@ -163,7 +165,7 @@ func TestVerifyJWTCredential(t *testing.T) {
 		assert.NoError(tt, err)
 
 		jwtCred := getTestJWTCredential(tt, *signer)
-		_, err = VerifyJWTCredential(jwtCred, resolver)
+		_, err = VerifyJWTCredential(context.Background(), jwtCred, resolver)
 		assert.Error(tt, err)
 		assert.Contains(tt, err.Error(), "unsupported method: key")
 	})
@@ -178,7 +180,7 @@ func TestVerifyJWTCredential(t *testing.T) {
 		assert.NoError(tt, err)
 
 		jwtCred := getTestJWTCredential(tt, *signer)
-		_, err = VerifyJWTCredential(jwtCred, resolver)
+		_, err = VerifyJWTCredential(context.Background(), jwtCred, resolver)
 		assert.Error(tt, err)
 		assert.Contains(tt, err.Error(), "has no verification methods with kid: missing")
 	})
@@ -200,7 +202,7 @@ func TestVerifyJWTCredential(t *testing.T) {
 		// modify the signature to make it invalid
 		jwtCred = jwtCred[:len(jwtCred)-5] + "baddata"
 
-		verified, err := VerifyJWTCredential(jwtCred, resolver)
+		verified, err := VerifyJWTCredential(context.Background(), jwtCred, resolver)
 		assert.Error(tt, err)
 		assert.False(tt, verified)
 	})
@@ -218,12 +220,223 @@ func TestVerifyJWTCredential(t *testing.T) {
 		assert.NoError(tt, err)
 
 		jwtCred := getTestJWTCredential(tt, *signer)
-		verified, err := VerifyJWTCredential(jwtCred, resolver)
+		verified, err := VerifyJWTCredential(context.Background(), jwtCred, resolver)
 		assert.NoError(tt, err)
 		assert.True(tt, verified)
 	})
 }
 
+func TestVerifyVerifiableCredentialJWTWithResolver(t *testing.T) {
+	resolver, err := did.NewResolver([]did.Resolver{did.KeyResolver{}}...)
+	require.NoError(t, err)
+
+	getDIDKeySigner := func(tt *testing.T) *jwx.Signer {
+		privKey, didKey, err := did.GenerateDIDKey(crypto.Ed25519)
+		require.NoError(tt, err)
+		expanded, err := didKey.Expand()
+		require.NoError(tt, err)
+		signer, err := jwx.NewJWXSigner(didKey.String(), expanded.VerificationMethod[0].ID, privKey)
+		require.NoError(tt, err)
+		return signer
+	}
+
+	t.Run("valid credential", func(tt *testing.T) {
+		signer := getDIDKeySigner(tt)
+		cred, err := VerifyVerifiableCredentialJWTWithResolver(context.Background(), getTestJWTCredential(tt, *signer), resolver)
+		assert.NoError(tt, err)
+		require.NotEmpty(tt, cred)
+
+		issuerID, err := cred.IssuerID()
+		assert.NoError(tt, err)
+		assert.Equal(tt, signer.ID, issuerID)
+		assert.Equal(tt, "did:example:123", cred.CredentialSubject.GetID())
+		assert.Equal(tt, "pizza", cred.CredentialSubject["favoriteFood"])
+	})
+
+	t.Run("registered claims take precedence", func(tt *testing.T) {
+		signer := getDIDKeySigner(tt)
+		token := signTestJWT(tt, *signer, map[string]any{
+			"iss": signer.ID,
+			"jti": "urn:uuid:from-claim",
+			"nbf": time.Date(2022, 1, 1, 0, 0, 0, 0, time.UTC).Unix(),
+			"sub": "did:example:from-claim",
+			"vc": map[string]any{
+				"@context":          []any{"https://www.w3.org/2018/credentials/v1"},
+				"type":              []any{"VerifiableCredential"},
+				"id":                "urn:uuid:from-vc",
+				"issuer":            map[string]any{"id": signer.ID, "name": "Issuer"},
+				"issuanceDate":      "2021-01-01T00:00:00Z",
+				"credentialSubject": map[string]any{"id": "did:example:from-vc"},
+			},
+		})
+		cred, err := VerifyVerifiableCredentialJWTWithResolver(context.Background(), token, resolver)
+		assert.NoError(tt, err)
+		require.NotEmpty(tt, cred)
+		assert.Equal(tt, "urn:uuid:from-claim", cred.ID)
+		assert.Equal(tt, "2022-01-01T00:00:00Z", cred.IssuanceDate)
+		assert.Equal(tt, "did:example:from-claim", cred.CredentialSubject.GetID())
+		assert.Equal(tt, map[string]any{"id": signer.ID, "name": "Issuer"}, cred.Issuer)
+	})
+
+	t.Run("iss does not match the credential's issuer", func(tt *testing.T) {
+		signer := getDIDKeySigner(tt)
+		token := signTestJWT(tt, *signer, map[string]any{
+			"iss": signer.ID,
+			"vc": map[string]any{
+				"@context":          []any{"https://www.w3.org/2018/credentials/v1"},
+				"type":              []any{"VerifiableCredential"},
+				"issuer":            "did:example:someone-else",
+				"issuanceDate":      "2021-01-01T00:00:00Z",
+				"credentialSubject": map[string]any{"id": "did:example:123"},
+			},
+		})
+		_, err := VerifyVerifiableCredentialJWTWithResolver(context.Background(), token, resolver)
+		assert.Error(tt, err)
+		assert.True(tt, IsJWTVerificationError(err, IssuerMismatchError))
+		assert.Contains(tt, err.Error(), "does not match issuer<did:example:someone-else>")
+	})
+
+	t.Run("resolution failure", func(tt *testing.T) {
+		_, privKey, err := crypto.GenerateEd25519Key()
+		require.NoError(tt, err)
+		signer, err := jwx.NewJWXSigner("did:example:unresolvable", "did:example:unresolvable#key-1", privKey)
+		require.NoError(tt, err)
+
+		_, err = VerifyVerifiableCredentialJWTWithResolver(context.Background(), getTestJWTCredential(tt, *signer), resolver)
+		assert.Error(tt, err)
+		assert.True(tt, IsJWTVerificationError(err, IssuerResolutionError))
+		assert.Contains(tt, err.Error(), "error getting issuer DID<did:example:unresolvable>")
+	})
+
+	t.Run("key mismatch", func(tt *testing.T) {
+		// the kid identifies a key of another DID
+		signer := getDIDKeySigner(tt)
+		require.NoError(tt, signer.Key.Set("kid", getDIDKeySigner(tt).ID+"#key-1"))
+		_, err := VerifyVerifiableCredentialJWTWithResolver(context.Background(), getTestJWTCredential(tt, *signer), resolver)
+		assert.Error(tt, err)
+		assert.True(tt, IsJWTVerificationError(err, KeyMismatchError))
+		assert.Contains(tt, err.Error(), "is not a key of issuer")
+
+		// the kid is not found in the issuer's DID document
+		signer = getDIDKeySigner(tt)
+		require.NoError(tt, signer.Key.Set("kid", "#missing"))
+		_, err = VerifyVerifiableCredentialJWTWithResolver(context.Background(), getTestJWTCredential(tt, *signer), resolver)
+		assert.Error(tt, err)
+		assert.True(tt, IsJWTVerificationError(err, KeyMismatchError))
+		assert.Contains(tt, err.Error(), "has no verification methods with kid: #missing")
+	})
+
+	t.Run("signature failure", func(tt *testing.T) {
+		// signed by another key than the one identified by the kid
+		signer := getDIDKeySigner(tt)
+		otherSigner := getDIDKeySigner(tt)
+		signer.Key = otherSigner.Key
+		kid := getDIDKeySignerKID(tt, signer.ID, resolver)
+		require.NoError(tt, signer.Key.Set("kid", kid))
+
+		_, err := VerifyVerifiableCredentialJWTWithResolver(context.Background(), getTestJWTCredential(tt, *signer), resolver)
+		assert.Error(tt, err)
+		assert.True(tt, IsJWTVerificationError(err, SignatureError))
+		assert.False(tt, IsJWTVerificationError(err, KeyMismatchError))
+	})
+
+	t.Run("expired credential verifies as of its validity period", func(tt *testing.T) {
+		signer := getDIDKeySigner(tt)
+		issued := time.Date(2020, time.January, 1, 0, 0, 0, 0, time.UTC)
+		expired := time.Date(2021, time.January, 1, 0, 0, 0, 0, time.UTC)
+		token, err := SignVerifiableCredentialJWT(*signer, VerifiableCredential{
+			ID:                uuid.NewString(),
+			Context:           []any{"https://www.w3.org/2018/credentials/v1"},
+			Type:              []string{"VerifiableCredential"},
+			Issuer:            signer.ID,
+			IssuanceDate:      issued.Format(time.RFC3339),
+			ExpirationDate:    expired.Format(time.RFC3339),
+			CredentialSubject: map[string]any{"id": "did:example:123"},
+		})
+		require.NoError(tt, err)
+
+		_, err = VerifyVerifiableCredentialJWTWithResolver(context.Background(), string(token), resolver)
+		assert.ErrorContains(tt, err, `"exp" not satisfied`)
+		assert.True(tt, IsJWTVerificationError(err, SignatureError))
+
+		cred, err := VerifyVerifiableCredentialJWTWithResolver(context.Background(), string(token), resolver, WithClock(util.FixedClock(issued.AddDate(0, 6, 0))))
+		assert.NoError(tt, err)
+		require.NotEmpty(tt, cred)
+		assert.Equal(tt, "did:example:123", cred.CredentialSubject.GetID())
+
+		verified, err := VerifyJWTCredential(context.Background(), string(token), resolver, WithClock(util.FixedClock(issued.AddDate(0, 6, 0))))
+		assert.NoError(tt, err)
+		assert.True(tt, verified)
+
+		// before the credential was issued
+		_, err = VerifyVerifiableCredentialJWTWithResolver(context.Background(), string(token), resolver, WithClock(util.FixedClock(issued.AddDate(0, 0, -1))))
+		assert.ErrorContains(tt, err, "not satisfied")
+	})
+
+	t.Run("deactivated issuer", func(tt *testing.T) {
+		signer := getDIDKeySigner(tt)
+		deactivatedResolver := deactivatingResolver{Resolver: did.KeyResolver{}}
+		_, err := VerifyVerifiableCredentialJWTWithResolver(context.Background(), getTestJWTCredential(tt, *signer), deactivatedResolver)
+		assert.Error(tt, err)
+		assert.ErrorIs(tt, err, ErrIssuerDeactivated)
+		assert.True(tt, IsJWTVerificationError(err, IssuerResolutionError))
+	})
+
+	t.Run("resolution is bound by the context", func(tt *testing.T) {
+		signer := getDIDKeySigner(tt)
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+		_, err := VerifyVerifiableCredentialJWTWithResolver(ctx, getTestJWTCredential(tt, *signer), contextResolver{Resolver: did.KeyResolver{}})
+		assert.ErrorIs(tt, err, context.Canceled)
+		assert.True(tt, IsJWTVerificationError(err, IssuerResolutionError))
+
+		_, err = VerifyJWTCredential(ctx, getTestJWTCredential(tt, *signer), contextResolver{Resolver: did.KeyResolver{}})
+		assert.ErrorIs(tt, err, context.Canceled)
+	})
+}
+
+// contextResolver resolves DIDs with the wrapped resolver, failing once the context is done as a network resolver would
+type contextResolver struct {
+	did.Resolver
+}
+
+func (r contextResolver) Resolve(ctx context.Context, id string, opts ...did.ResolutionOption) (*did.ResolutionResult, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	return r.Resolver.Resolve(ctx, id, opts...)
+}
+
+// deactivatingResolver resolves DIDs with the wrapped resolver, marking every resolved DID as deactivated
+type deactivatingResolver struct {
+	did.Resolver
+}
+
+func (r deactivatingResolver) Resolve(ctx context.Context, id string, opts ...did.ResolutionOption) (*did.ResolutionResult, error) {
+	resolved, err := r.Resolver.Resolve(ctx, id, opts...)
+	if err != nil {
+		return nil, err
+	}
+	resolved.DocumentMetadata.Deactivated = true
+	return resolved, nil
+}
+
+func getDIDKeySignerKID(t *testing.T, didKey string, resolver did.Resolver) string {
+	resolved, err := resolver.Resolve(context.Background(), didKey)
+	require.NoError(t, err)
+	return resolved.Document.VerificationMethod[0].ID
+}
+
+func signTestJWT(t *testing.T, signer jwx.Signer, claims map[string]any) string {
+	token := jwt.New()
+	for k, v := range claims {
+		require.NoError(t, token.Set(k, v))
+	}
+	signed, err := jwt.Sign(token, jwt.WithKey(signer.SignatureAlgorithm, signer.Key))
+	require.NoError(t, err)
+	return string(signed)
+}
+
 func getTestJWTCredential(t *testing.T, signer jwx.Signer) string {
 	cred := VerifiableCredential{
 		ID:           uuid.NewString(),
@@ -0,0 +1,174 @@
+package status
+
+import (
+	"fmt"
+
+	"github.com/goccy/go-json"
+	"github.com/pkg/errors"
+
+	"github.com/TBD54566975/ssi-sdk/credential"
+	"github.com/TBD54566975/ssi-sdk/util"
+)
+
+// CheckOption configures how the status of a credential is checked
+type CheckOption func(*checkOptions)
+
+type checkOptions struct {
+	clock util.Clock
+}
+
+// WithClock is a CheckOption validating the validity period of status list credentials against the given clock rather
+// than the current time, such as to check the status of a credential as of a past time
+func WithClock(clock util.Clock) CheckOption {
+	return func(o *checkOptions) {
+		o.clock = clock
+	}
+}
+
+func newCheckOptions(opts []CheckOption) checkOptions {
+	options := checkOptions{clock: util.RealClock{}}
+	for _, opt := range opts {
+		opt(&options)
+	}
+	return options
+}
+
+// checkStatusCredentialValidity checks that a status list credential is within its validity period, so that a stale
+// status list is not relied upon
+func (o checkOptions) checkStatusCredentialValidity(statusCredential credential.VerifiableCredential) error {
+	if err := statusCredential.IsActive(o.clock.Now(), 0); err != nil {
+		return errors.Wrapf(err, "status list credential<%s> is not valid", statusCredential.ID)
+	}
+	return nil
+}
+
+// AggregateResult is the combined status of every credentialStatus entry of a credential
+type AggregateResult struct {
+	// Valid is false if the credential has been revoked by any of its entries
+	Valid bool
+	// Revoked is true if the status of any revocation entry is set
+	Revoked bool
+	// Suspended is true if the status of any suspension entry is set
+	Suspended bool
+	// Entries holds the result of each credentialStatus entry, in the order the credential lists them
+	Entries []EntryResult
+}
+
+// EntryResult is the status of a single credentialStatus entry
+type EntryResult struct {
+	Type                 string
+	StatusPurpose        StatusPurpose
+	StatusListCredential string
+	// Status is the value of the entry's status, which is 1 for a set bit of a single bit status
+	Status int
+	// Message describes the status, for bitstring status list entries with a statusMessage
+	Message string
+}
+
+// CheckAllStatuses checks every credentialStatus entry of a credential, which may be a single entry or an array of
+// StatusList2021Entry and BitstringStatusListEntry values, such as one for revocation and another for suspension.
+// Each status list credential is retrieved once with the given fetch function, and must be within its validity
+// period. An error is returned if any entry cannot be checked.
+// NOTE: this method does not perform signature/proof verification of any credential
+func CheckAllStatuses(vc credential.VerifiableCredential, fetch func(url string) (*credential.VerifiableCredential, error), opts ...CheckOption) (*AggregateResult, error) {
+	if fetch == nil {
+		return nil, errors.New("fetch function cannot be empty")
+	}
+	entries, err := credentialStatusEntries(vc.CredentialStatus)
+	if err != nil {
+		return nil, errors.Wrapf(err, "getting credentialStatus entries of credential<%s>", vc.ID)
+	}
+	if len(entries) == 0 {
+		return nil, fmt.Errorf("credential<%s> has no credentialStatus", vc.ID)
+	}
+
+	// entries commonly share a status list credential, or are checked against one of a handful, so fetch each once
+	fetched := make(map[string]*credential.VerifiableCredential)
+	cachedFetch := func(url string) (*credential.VerifiableCredential, error) {
+		if statusCredential, ok := fetched[url]; ok {
+			return statusCredential, nil
+		}
+		statusCredential, err := fetch(url)
+		if err != nil {
+			return nil, err
+		}
+		fetched[url] = statusCredential
+		return statusCredential, nil
+	}
+
+	result := AggregateResult{Entries: make([]EntryResult, 0, len(entries))}
+	for i, entry := range entries {
+		entryResult, err := checkStatusEntry(vc, entry, cachedFetch, opts)
+		if err != nil {
+			return nil, errors.Wrapf(err, "checking credentialStatus entry<%d> of credential<%s>", i, vc.ID)
+		}
+		if entryResult.Status != 0 {
+			switch entryResult.StatusPurpose {
+			case StatusRevocation:
+				result.Revoked = true
+			case StatusSuspension:
+				result.Suspended = true
+			}
+		}
+		result.Entries = append(result.Entries, *entryResult)
+	}
+	result.Valid = !result.Revoked
+	return &result, nil
+}
+
+// checkStatusEntry checks a single credentialStatus entry of the credential by the type of the entry
+func checkStatusEntry(vc credential.VerifiableCredential, entry map[string]any, fetch func(url string) (*credential.VerifiableCredential, error), opts []CheckOption) (*EntryResult, error) {
+	entryType, _ := entry["type"].(string)
+	purpose, _ := entry["statusPurpose"].(string)
+	listCredential, _ := entry["statusListCredential"].(string)
+	result := EntryResult{
+		Type:                 entryType,
+		StatusPurpose:        StatusPurpose(purpose),
+		StatusListCredential: listCredential,
+	}
+
+	vc.CredentialStatus = entry
+	switch entryType {
+	case StatusList2021EntryType:
+		set, err := CheckStatus(vc, fetch, opts...)
+		if err != nil {
+			return nil, err
+		}
+		if set {
+			result.Status = 1
+		}
+	case BitstringStatusListEntryType:
+		status, message, err := CheckBitstringStatus(vc, fetch, opts...)
+		if err != nil {
+			return nil, err
+		}
+		result.Status, result.Message = status, message
+	default:
+		return nil, fmt.Errorf("unsupported credentialStatus type<%s>", entryType)
+	}
+	return &result, nil
+}
+
+// credentialStatusEntries returns the entries of a credentialStatus property, which is either a single entry or an
+// array of entries
+func credentialStatusEntries(credentialStatus any) ([]map[string]any, error) {
+	if credentialStatus == nil {
+		return nil, nil
+	}
+	statusBytes, err := json.Marshal(credentialStatus)
+	if err != nil {
+		return nil, errors.Wrap(err, "could not marshal credential status property")
+	}
+	var entries []map[string]any
+	if len(statusBytes) > 0 && statusBytes[0] == '[' {
+		if err = json.Unmarshal(statusBytes, &entries); err != nil {
+			return nil, errors.Wrap(err, "could not unmarshal credential status entries")
+		}
+		return entries, nil
+	}
+	var entry map[string]any
+	if err = json.Unmarshal(statusBytes, &entry); err != nil {
+		return nil, errors.Wrap(err, "could not unmarshal credential status property")
+	}
+	return []map[string]any{entry}, nil
+}
@@ -0,0 +1,206 @@
+package status
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/TBD54566975/ssi-sdk/credential"
+	"github.com/TBD54566975/ssi-sdk/util"
+)
+
+func TestCheckAllStatuses(t *testing.T) {
+	revocationURL := "https://example.com/credentials/status/revocation"
+	suspensionURL := "https://example.com/credentials/status/suspension"
+	revocationCred, err := GenerateBitstringStatusListCredential(revocationURL, "did:example:issuer", StatusRevocation, 1,
+		map[int]int{42: 1})
+	require.NoError(t, err)
+	suspensionCred, err := GenerateBitstringStatusListCredential(suspensionURL, "did:example:issuer", StatusSuspension, 1,
+		map[int]int{7: 1})
+	require.NoError(t, err)
+
+	fetches := make(map[string]int)
+	fetch := func(url string) (*credential.VerifiableCredential, error) {
+		fetches[url]++
+		switch url {
+		case revocationURL:
+			return revocationCred, nil
+		case suspensionURL:
+			return suspensionCred, nil
+		default:
+			return nil, errors.New("not found")
+		}
+	}
+	entry := func(url string, purpose StatusPurpose, index string) map[string]any {
+		return map[string]any{
+			"id":                   url + "#" + index,
+			"type":                 BitstringStatusListEntryType,
+			"statusPurpose":        string(purpose),
+			"statusListIndex":      index,
+			"statusListCredential": url,
+		}
+	}
+
+	t.Run("revocation clear and suspension set", func(tt *testing.T) {
+		cred := credential.VerifiableCredential{
+			ID: "test-cred",
+			CredentialStatus: []any{
+				entry(revocationURL, StatusRevocation, "7"),
+				entry(suspensionURL, StatusSuspension, "7"),
+			},
+		}
+		result, err := CheckAllStatuses(cred, fetch)
+		require.NoError(tt, err)
+		assert.True(tt, result.Suspended)
+		assert.False(tt, result.Revoked)
+		assert.True(tt, result.Valid)
+		require.Len(tt, result.Entries, 2)
+		assert.Equal(tt, EntryResult{
+			Type:                 BitstringStatusListEntryType,
+			StatusPurpose:        StatusRevocation,
+			StatusListCredential: revocationURL,
+			Status:               0,
+		}, result.Entries[0])
+		assert.Equal(tt, StatusSuspension, result.Entries[1].StatusPurpose)
+		assert.Equal(tt, 1, result.Entries[1].Status)
+	})
+
+	t.Run("revoked is not valid", func(tt *testing.T) {
+		cred := credential.VerifiableCredential{
+			ID: "test-cred",
+			CredentialStatus: []BitstringStatusListEntry{
+				{
+					Type:                 BitstringStatusListEntryType,
+					StatusPurpose:        StatusRevocation,
+					StatusListIndex:      "42",
+					StatusListCredential: revocationURL,
+				},
+				{
+					Type:                 BitstringStatusListEntryType,
+					StatusPurpose:        StatusSuspension,
+					StatusListIndex:      "42",
+					StatusListCredential: suspensionURL,
+				},
+			},
+		}
+		result, err := CheckAllStatuses(cred, fetch)
+		require.NoError(tt, err)
+		assert.True(tt, result.Revoked)
+		assert.False(tt, result.Suspended)
+		assert.False(tt, result.Valid)
+	})
+
+	t.Run("single entry and status list credentials fetched once", func(tt *testing.T) {
+		for url := range fetches {
+			delete(fetches, url)
+		}
+		cred := credential.VerifiableCredential{ID: "test-cred", CredentialStatus: entry(suspensionURL, StatusSuspension, "1")}
+		result, err := CheckAllStatuses(cred, fetch)
+		require.NoError(tt, err)
+		assert.True(tt, result.Valid)
+		assert.False(tt, result.Suspended)
+		assert.Len(tt, result.Entries, 1)
+
+		cred.CredentialStatus = []any{
+			entry(suspensionURL, StatusSuspension, "1"),
+			entry(suspensionURL, StatusSuspension, "7"),
+		}
+		result, err = CheckAllStatuses(cred, fetch)
+		require.NoError(tt, err)
+		assert.True(tt, result.Suspended)
+		assert.Equal(tt, 2, fetches[suspensionURL])
+	})
+
+	t.Run("status list 2021 entry", func(tt *testing.T) {
+		statusListURL := "https://example.com/credentials/status/2021"
+		statusList2021Cred, err := GenerateStatusList2021CredentialFromIndices(statusListURL, "did:example:issuer",
+			StatusRevocation, []int{3})
+		require.NoError(tt, err)
+		cred := credential.VerifiableCredential{
+			ID: "test-cred",
+			CredentialStatus: []any{
+				StatusList2021Entry{
+					ID:                   statusListURL + "#3",
+					Type:                 StatusList2021EntryType,
+					StatusPurpose:        StatusRevocation,
+					StatusListIndex:      "3",
+					StatusListCredential: statusListURL,
+				},
+				entry(suspensionURL, StatusSuspension, "1"),
+			},
+		}
+		result, err := CheckAllStatuses(cred, func(url string) (*credential.VerifiableCredential, error) {
+			if url == statusListURL {
+				return statusList2021Cred, nil
+			}
+			return fetch(url)
+		})
+		require.NoError(tt, err)
+		assert.True(tt, result.Revoked)
+		assert.False(tt, result.Valid)
+		assert.Equal(tt, StatusList2021EntryType, result.Entries[0].Type)
+	})
+
+	t.Run("status list credential validity period", func(tt *testing.T) {
+		expiredCred := *revocationCred
+		expiredCred.ValidFrom = "2020-01-01T00:00:00Z"
+		expiredCred.ValidUntil = "2021-01-01T00:00:00Z"
+		expiredFetch := func(string) (*credential.VerifiableCredential, error) {
+			return &expiredCred, nil
+		}
+		cred := credential.VerifiableCredential{ID: "test-cred", CredentialStatus: entry(revocationURL, StatusRevocation, "42")}
+
+		_, err := CheckAllStatuses(cred, expiredFetch)
+		assert.ErrorIs(tt, err, credential.ErrExpired)
+		assert.ErrorContains(tt, err, "status list credential<"+revocationURL+"> is not valid")
+
+		// as of a time within its validity period, the status list credential may be relied upon
+		asOf := WithClock(util.FixedClock(time.Date(2020, time.June, 1, 0, 0, 0, 0, time.UTC)))
+		result, err := CheckAllStatuses(cred, expiredFetch, asOf)
+		require.NoError(tt, err)
+		assert.True(tt, result.Revoked)
+
+		_, _, err = CheckBitstringStatus(cred, expiredFetch, WithClock(util.FixedClock(time.Date(2019, time.June, 1, 0, 0, 0, 0, time.UTC))))
+		assert.ErrorIs(tt, err, credential.ErrNotYetValid)
+
+		statusListURL := "https://example.com/credentials/status/2021"
+		statusList2021Cred, err := GenerateStatusList2021CredentialFromIndices(statusListURL, "did:example:issuer",
+			StatusRevocation, []int{3})
+		require.NoError(tt, err)
+		statusList2021Cred.ExpirationDate = "2021-01-01T00:00:00Z"
+		cred.CredentialStatus = StatusList2021Entry{
+			ID:                   statusListURL + "#3",
+			Type:                 StatusList2021EntryType,
+			StatusPurpose:        StatusRevocation,
+			StatusListIndex:      "3",
+			StatusListCredential: statusListURL,
+		}
+		_, err = CheckStatus(cred, func(string) (*credential.VerifiableCredential, error) {
+			return statusList2021Cred, nil
+		})
+		assert.ErrorIs(tt, err, credential.ErrExpired)
+	})
+
+	t.Run("errors", func(tt *testing.T) {
+		cred := credential.VerifiableCredential{ID: "test-cred", CredentialStatus: entry(revocationURL, StatusRevocation, "1")}
+		_, err := CheckAllStatuses(cred, nil)
+		assert.ErrorContains(tt, err, "fetch function cannot be empty")
+
+		_, err = CheckAllStatuses(credential.VerifiableCredential{ID: "test-cred"}, fetch)
+		assert.ErrorContains(tt, err, "credential<test-cred> has no credentialStatus")
+
+		cred.CredentialStatus = []any{
+			entry(revocationURL, StatusRevocation, "1"),
+			entry("https://example.com/credentials/status/missing", StatusSuspension, "1"),
+		}
+		_, err = CheckAllStatuses(cred, fetch)
+		assert.ErrorContains(tt, err, "checking credentialStatus entry<1> of credential<test-cred>")
+
+		cred.CredentialStatus = map[string]any{"type": "UnknownStatusEntry"}
+		_, err = CheckAllStatuses(cred, fetch)
+		assert.ErrorContains(tt, err, "unsupported credentialStatus type<UnknownStatusEntry>")
+	})
+}
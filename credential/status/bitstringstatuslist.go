@@ -0,0 +1,286 @@
+package status
+
+import (
+	"bytes"
+	"compress/gzip"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/goccy/go-json"
+
+	"github.com/TBD54566975/ssi-sdk/credential"
+	"github.com/TBD54566975/ssi-sdk/crypto"
+	"github.com/TBD54566975/ssi-sdk/util"
+	"github.com/pkg/errors"
+)
+
+// https://www.w3.org/TR/vc-bitstring-status-list/
+
+const (
+	StatusRefresh StatusPurpose = "refresh"
+	StatusMessage StatusPurpose = "message"
+
+	BitstringStatusListCredentialType string = "BitstringStatusListCredential"
+	BitstringStatusListEntryType      string = "BitstringStatusListEntry"
+	BitstringStatusListType           string = "BitstringStatusList"
+
+	// bitstringStatusListMinEntries is the minimum number of entries of a status list, 16KB of single bit entries,
+	// which provides group privacy for the credentials in the list
+	bitstringStatusListMinEntries = 16 * KB * 8
+)
+
+// BitstringStatusListEntry the representation within a credential that is associated with a bitstring status list
+// https://www.w3.org/TR/vc-bitstring-status-list/#bitstringstatuslistentry
+type BitstringStatusListEntry struct {
+	ID                   string        `json:"id,omitempty"`
+	Type                 string        `json:"type" validate:"required"`
+	StatusPurpose        StatusPurpose `json:"statusPurpose" validate:"required"`
+	StatusListIndex      string        `json:"statusListIndex" validate:"required"`
+	StatusListCredential string        `json:"statusListCredential" validate:"required"`
+	// StatusSize is the number of bits of the status, defaulting to 1
+	StatusSize int `json:"statusSize,omitempty"`
+	// StatusMessage describes each possible value of a status of more than one bit
+	StatusMessage   []BitstringStatusMessage `json:"statusMessage,omitempty"`
+	StatusReference any                      `json:"statusReference,omitempty"`
+}
+
+// BitstringStatusMessage describes a status value, given as a hexadecimal string such as 0x2
+type BitstringStatusMessage struct {
+	Status  string `json:"status" validate:"required"`
+	Message string `json:"message" validate:"required"`
+}
+
+// BitstringStatusList the credential subject value of a bitstring status list credential
+// https://www.w3.org/TR/vc-bitstring-status-list/#bitstringstatuslistcredential
+type BitstringStatusList struct {
+	ID            string        `json:"id" validate:"required"`
+	Type          string        `json:"type" validate:"required"`
+	StatusPurpose StatusPurpose `json:"statusPurpose" validate:"required"`
+	EncodedList   string        `json:"encodedList" validate:"required"`
+	TTL           int           `json:"ttl,omitempty"`
+}
+
+// GenerateBitstringStatusListCredential generates a bitstring status list credential given an ID (the URI where this
+// entity will be hosted), the issuer DID, the purpose of the list, the number of bits of each status, and the
+// status values of the list's entries by their status list index. Entries without a value have a status of 0.
+// https://www.w3.org/TR/vc-bitstring-status-list/#bitstring-generation-algorithm
+func GenerateBitstringStatusListCredential(id string, issuer string, purpose StatusPurpose, statusSize int, statuses map[int]int) (*credential.VerifiableCredential, error) {
+	switch purpose {
+	case StatusRevocation, StatusSuspension, StatusRefresh, StatusMessage:
+	default:
+		return nil, fmt.Errorf("could not generate bitstring status list credential: invalid status purpose: %s", purpose)
+	}
+
+	encodedList, err := generateMultiBitstring(statusSize, statuses)
+	if err != nil {
+		return nil, errors.Wrap(err, "could not generate bitstring for status list credential")
+	}
+
+	statusList := BitstringStatusList{
+		ID:            id,
+		Type:          BitstringStatusListType,
+		StatusPurpose: purpose,
+		EncodedList:   encodedList,
+	}
+
+	builder := credential.NewVerifiableCredentialBuilder()
+	errMsgFragment := "could not generate bitstring status list credential: error setting "
+	if err = builder.SetID(id); err != nil {
+		return nil, errors.Wrap(err, errMsgFragment+"id")
+	}
+	if err = builder.SetIssuer(issuer); err != nil {
+		return nil, errors.Wrap(err, errMsgFragment+"issuer")
+	}
+	// bitstring status lists are defined by the vc-data-model 2.0 context, which replaces the 1.1 context and
+	// issuanceDate of the builder's defaults
+	builder.Context = []string{credential.VerifiableCredentialsV2Context}
+	builder.ValidFrom = builder.IssuanceDate
+	builder.IssuanceDate = ""
+	if err = builder.AddType(BitstringStatusListCredentialType); err != nil {
+		return nil, errors.Wrap(err, errMsgFragment+"type")
+	}
+	statusListJSON, err := util.ToJSONMap(statusList)
+	if err != nil {
+		return nil, errors.Wrap(err, "could not turn status list to JSON")
+	}
+	if err = builder.SetCredentialSubject(statusListJSON); err != nil {
+		return nil, errors.Wrap(err, errMsgFragment+"subject")
+	}
+
+	statusListCredential, err := builder.Build()
+	if err != nil {
+		return nil, errors.Wrap(err, "could not build bitstring status list credential")
+	}
+	return statusListCredential, nil
+}
+
+// CheckBitstringStatus returns the status value of a credential with a BitstringStatusListEntry credentialStatus,
+// along with the message describing the value if the entry has a statusMessage. The status list credential named by
+// the entry is retrieved with the given fetch function, and must have the same status purpose as the entry and be
+// within its validity period.
+// https://www.w3.org/TR/vc-bitstring-status-list/#validate-algorithm
+// NOTE: this method does not perform signature/proof verification of either credential
+func CheckBitstringStatus(vc credential.VerifiableCredential, fetch func(url string) (*credential.VerifiableCredential, error), opts ...CheckOption) (status int, message string, err error) {
+	if fetch == nil {
+		return 0, "", errors.New("fetch function cannot be empty")
+	}
+	entry, err := getBitstringStatusListEntry(vc.CredentialStatus)
+	if err != nil {
+		return 0, "", errors.Wrapf(err, "credential<%s> not using the BitstringStatusList credentialStatus property", vc.ID)
+	}
+	statusSize := entry.StatusSize
+	if statusSize == 0 {
+		statusSize = 1
+	}
+	if statusSize < 0 || statusSize > 30 {
+		return 0, "", fmt.Errorf("credential<%s> has an invalid status size: %d", vc.ID, entry.StatusSize)
+	}
+	if len(entry.StatusMessage) > 0 && len(entry.StatusMessage) != 1<<statusSize {
+		return 0, "", fmt.Errorf("credential<%s> has %d status messages, expected %d for a status size of %d",
+			vc.ID, len(entry.StatusMessage), 1<<statusSize, statusSize)
+	}
+	index, err := strconv.Atoi(entry.StatusListIndex)
+	if err != nil || index < 0 {
+		return 0, "", fmt.Errorf("invalid status list index value, not a valid positive integer: %s", entry.StatusListIndex)
+	}
+
+	statusCredential, err := fetch(entry.StatusListCredential)
+	if err != nil {
+		return 0, "", errors.Wrapf(err, "fetching status list credential<%s>", entry.StatusListCredential)
+	}
+	if statusCredential == nil {
+		return 0, "", fmt.Errorf("status list credential<%s> not found", entry.StatusListCredential)
+	}
+	if err = newCheckOptions(opts).checkStatusCredentialValidity(*statusCredential); err != nil {
+		return 0, "", err
+	}
+	var statusList BitstringStatusList
+	subjectBytes, err := json.Marshal(statusCredential.CredentialSubject)
+	if err != nil {
+		return 0, "", errors.Wrapf(err, "could not marshal status credential<%s> subject value", statusCredential.ID)
+	}
+	if err = json.Unmarshal(subjectBytes, &statusList); err != nil {
+		return 0, "", errors.Wrapf(err, "could not unmarshal status credential<%s> subject value into "+
+			"BitstringStatusList", statusCredential.ID)
+	}
+	if err = util.IsValidStruct(statusList); err != nil {
+		return 0, "", errors.Wrapf(err, "credential<%s> is not a valid status credential", statusCredential.ID)
+	}
+	if statusList.Type != BitstringStatusListType {
+		return 0, "", fmt.Errorf("status credential<%s> has a subject of type<%s>, not %s", statusCredential.ID,
+			statusList.Type, BitstringStatusListType)
+	}
+	if entry.StatusPurpose != statusList.StatusPurpose {
+		return 0, "", fmt.Errorf("purpose of credential to validate<%s>: %s, did not match purpose of status "+
+			"credential<%s>: %s", vc.ID, entry.StatusPurpose, statusCredential.ID, statusList.StatusPurpose)
+	}
+
+	bitstring, err := expandMultiBitstring(statusList.EncodedList)
+	if err != nil {
+		return 0, "", errors.Wrapf(err, "could not expand compressed bitstring of status credential<%s>", statusCredential.ID)
+	}
+	if len(bitstring)*8 < bitstringStatusListMinEntries*statusSize {
+		return 0, "", fmt.Errorf("status credential<%s> has a bitstring shorter than the minimum of %d entries",
+			statusCredential.ID, bitstringStatusListMinEntries)
+	}
+	if (index+1)*statusSize > len(bitstring)*8 {
+		return 0, "", fmt.Errorf("status list index<%d> is out of range of status credential<%s>", index, statusCredential.ID)
+	}
+
+	// the status is the value of the statusSize bits at the index, the first bit being the most significant
+	for bit := index * statusSize; bit < (index+1)*statusSize; bit++ {
+		status <<= 1
+		if bitstring[bit/8]&(0x80>>(bit%8)) != 0 {
+			status |= 1
+		}
+	}
+
+	for _, statusMessage := range entry.StatusMessage {
+		value, err := strconv.ParseInt(strings.TrimPrefix(strings.ToLower(statusMessage.Status), "0x"), 16, 64)
+		if err != nil {
+			return 0, "", errors.Wrapf(err, "invalid status message value: %s", statusMessage.Status)
+		}
+		if int(value) == status {
+			message = statusMessage.Message
+		}
+	}
+	return status, message, nil
+}
+
+// getBitstringStatusListEntry determines whether the credential status property is a bitstring status list entry
+func getBitstringStatusListEntry(maybeCredentialStatus any) (*BitstringStatusListEntry, error) {
+	statusBytes, err := json.Marshal(maybeCredentialStatus)
+	if err != nil {
+		return nil, errors.Wrap(err, "could not marshal credential status property")
+	}
+	var entry BitstringStatusListEntry
+	if err = json.Unmarshal(statusBytes, &entry); err != nil {
+		return nil, errors.Wrap(err, "could not unmarshal credential status property")
+	}
+	if err = util.IsValidStruct(entry); err != nil {
+		return nil, err
+	}
+	if entry.Type != BitstringStatusListEntryType {
+		return nil, fmt.Errorf("credential status of type<%s>, not %s", entry.Type, BitstringStatusListEntryType)
+	}
+	return &entry, nil
+}
+
+// generateMultiBitstring returns the GZIP compressed, multibase base64url encoded bitstring holding each status in
+// statusSize bits at the position of its index
+func generateMultiBitstring(statusSize int, statuses map[int]int) (string, error) {
+	if statusSize < 1 || statusSize > 30 {
+		return "", fmt.Errorf("invalid status size: %d", statusSize)
+	}
+
+	// the bitstring holds at least the minimum number of entries, growing to hold the largest index
+	entries := bitstringStatusListMinEntries
+	for index, status := range statuses {
+		if index < 0 {
+			return "", fmt.Errorf("invalid status list index value, not a valid positive integer: %d", index)
+		}
+		if status < 0 || status >= 1<<statusSize {
+			return "", fmt.Errorf("status value<%d> of index<%d> does not fit in a status size of %d", status, index, statusSize)
+		}
+		if index >= entries {
+			entries = index + 1
+		}
+	}
+	bitstring := make([]byte, (entries*statusSize+7)/8)
+	for index, status := range statuses {
+		for i := 0; i < statusSize; i++ {
+			if status&(1<<(statusSize-1-i)) != 0 {
+				bit := index*statusSize + i
+				bitstring[bit/8] |= 0x80 >> (bit % 8)
+			}
+		}
+	}
+
+	var buf bytes.Buffer
+	zw := gzip.NewWriter(&buf)
+	if _, err := zw.Write(bitstring); err != nil {
+		return "", errors.Wrap(err, "could not compress status list bitstring using GZIP")
+	}
+	if err := zw.Close(); err != nil {
+		return "", errors.Wrap(err, "could not close gzip writer")
+	}
+	encoded, err := crypto.MultibaseEncode(crypto.Base64URLMultibase, buf.Bytes())
+	if err != nil {
+		return "", errors.Wrap(err, "could not encode status list bitstring")
+	}
+	return encoded, nil
+}
+
+// expandMultiBitstring decodes and decompresses a bitstring produced by generateMultiBitstring, failing if it expands
+// beyond maxBitstringSize
+func expandMultiBitstring(encodedList string) ([]byte, error) {
+	encoding, compressed, err := crypto.MultibaseDecode(encodedList)
+	if err != nil {
+		return nil, errors.Wrap(err, "could not decode compressed bitstring")
+	}
+	if encoding != crypto.Base64URLMultibase {
+		return nil, fmt.Errorf("compressed bitstring must be multibase base64url encoded, got: %c", encoding)
+	}
+	return decompressBitstring(compressed)
+}
@@ -0,0 +1,209 @@
+package status
+
+import (
+	"bytes"
+	"compress/gzip"
+	"errors"
+	"strconv"
+	"testing"
+
+	"github.com/goccy/go-json"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/TBD54566975/ssi-sdk/credential"
+	"github.com/TBD54566975/ssi-sdk/crypto"
+)
+
+func TestGenerateBitstringStatusListCredential(t *testing.T) {
+	t.Run("happy path", func(tt *testing.T) {
+		statusCred, err := GenerateBitstringStatusListCredential("https://example.com/status/1", "did:example:issuer",
+			StatusRevocation, 1, map[int]int{3: 1})
+		assert.NoError(tt, err)
+		assert.NoError(tt, statusCred.IsValid())
+		assert.True(tt, statusCred.IsVersion2())
+		assert.NotEmpty(tt, statusCred.ValidFrom)
+		assert.Empty(tt, statusCred.IssuanceDate)
+		assert.Contains(tt, statusCred.Type, BitstringStatusListCredentialType)
+
+		var statusList BitstringStatusList
+		subjectBytes, err := json.Marshal(statusCred.CredentialSubject)
+		require.NoError(tt, err)
+		require.NoError(tt, json.Unmarshal(subjectBytes, &statusList))
+		assert.Equal(tt, BitstringStatusListType, statusList.Type)
+		assert.Equal(tt, StatusRevocation, statusList.StatusPurpose)
+		assert.Equal(tt, byte('u'), statusList.EncodedList[0])
+
+		bitstring, err := expandMultiBitstring(statusList.EncodedList)
+		assert.NoError(tt, err)
+		assert.Len(tt, bitstring, 16*KB)
+		assert.Equal(tt, byte(0x10), bitstring[0])
+	})
+
+	t.Run("the list grows with its statuses", func(tt *testing.T) {
+		encodedList, err := generateMultiBitstring(2, map[int]int{bitstringStatusListMinEntries: 3})
+		assert.NoError(tt, err)
+		bitstring, err := expandMultiBitstring(encodedList)
+		assert.NoError(tt, err)
+		assert.Len(tt, bitstring, 32*KB+1)
+		assert.Equal(tt, byte(0xc0), bitstring[32*KB])
+	})
+
+	t.Run("list expanding beyond the maximum size", func(tt *testing.T) {
+		var buf bytes.Buffer
+		zw := gzip.NewWriter(&buf)
+		_, err := zw.Write(make([]byte, maxBitstringSize+1))
+		require.NoError(tt, err)
+		require.NoError(tt, zw.Close())
+		encodedList, err := crypto.MultibaseEncode(crypto.Base64URLMultibase, buf.Bytes())
+		require.NoError(tt, err)
+
+		_, err = expandMultiBitstring(encodedList)
+		assert.ErrorContains(tt, err, "status list bitstring expands beyond the maximum size of 4194304 bytes")
+	})
+
+	t.Run("invalid inputs", func(tt *testing.T) {
+		_, err := GenerateBitstringStatusListCredential("https://example.com/status/1", "did:example:issuer",
+			"bad", 1, nil)
+		assert.ErrorContains(tt, err, "invalid status purpose: bad")
+
+		_, err = GenerateBitstringStatusListCredential("https://example.com/status/1", "did:example:issuer",
+			StatusMessage, 0, nil)
+		assert.ErrorContains(tt, err, "invalid status size: 0")
+
+		_, err = GenerateBitstringStatusListCredential("https://example.com/status/1", "did:example:issuer",
+			StatusMessage, 2, map[int]int{1: 4})
+		assert.ErrorContains(tt, err, "status value<4> of index<1> does not fit in a status size of 2")
+
+		_, err = GenerateBitstringStatusListCredential("https://example.com/status/1", "did:example:issuer",
+			StatusMessage, 2, map[int]int{-1: 1})
+		assert.ErrorContains(tt, err, "not a valid positive integer: -1")
+	})
+}
+
+func TestCheckBitstringStatus(t *testing.T) {
+	statusListURL := "https://example.com/credentials/status/3"
+	statusCred, err := GenerateBitstringStatusListCredential(statusListURL, "did:example:issuer", StatusMessage, 2,
+		map[int]int{1: 1, 2: 2, 3: 3, 100: 2})
+	require.NoError(t, err)
+	fetch := func(url string) (*credential.VerifiableCredential, error) {
+		if url != statusListURL {
+			return nil, errors.New("not found")
+		}
+		return statusCred, nil
+	}
+	statusMessages := []any{
+		map[string]any{"status": "0x0", "message": "pending_review"},
+		map[string]any{"status": "0x1", "message": "accepted"},
+		map[string]any{"status": "0x2", "message": "rejected"},
+		map[string]any{"status": "0x3", "message": "undefined"},
+	}
+	getCred := func(index int) credential.VerifiableCredential {
+		return credential.VerifiableCredential{
+			ID: "test-cred",
+			CredentialStatus: map[string]any{
+				"id":                   statusListURL + "#" + strconv.Itoa(index),
+				"type":                 BitstringStatusListEntryType,
+				"statusPurpose":        string(StatusMessage),
+				"statusListIndex":      strconv.Itoa(index),
+				"statusListCredential": statusListURL,
+				"statusSize":           2,
+				"statusMessage":        statusMessages,
+			},
+		}
+	}
+
+	t.Run("four status values", func(tt *testing.T) {
+		tests := []struct {
+			index   int
+			status  int
+			message string
+		}{
+			{0, 0, "pending_review"},
+			{1, 1, "accepted"},
+			{2, 2, "rejected"},
+			{3, 3, "undefined"},
+			{100, 2, "rejected"},
+		}
+		for _, test := range tests {
+			status, message, err := CheckBitstringStatus(getCred(test.index), fetch)
+			assert.NoError(tt, err)
+			assert.Equal(tt, test.status, status, test.index)
+			assert.Equal(tt, test.message, message, test.index)
+		}
+	})
+
+	t.Run("without status messages", func(tt *testing.T) {
+		cred := getCred(3)
+		delete(cred.CredentialStatus.(map[string]any), "statusMessage")
+		status, message, err := CheckBitstringStatus(cred, fetch)
+		assert.NoError(tt, err)
+		assert.Equal(tt, 3, status)
+		assert.Empty(tt, message)
+	})
+
+	t.Run("single bit statuses", func(tt *testing.T) {
+		revocationCred, err := GenerateBitstringStatusListCredential(statusListURL, "did:example:issuer",
+			StatusRevocation, 1, map[int]int{42: 1})
+		require.NoError(tt, err)
+		fetchRevocation := func(string) (*credential.VerifiableCredential, error) { return revocationCred, nil }
+		for index, expected := range map[int]int{41: 0, 42: 1, 43: 0} {
+			cred := getCred(index)
+			entry := cred.CredentialStatus.(map[string]any)
+			entry["statusPurpose"] = string(StatusRevocation)
+			delete(entry, "statusSize")
+			delete(entry, "statusMessage")
+			status, _, err := CheckBitstringStatus(cred, fetchRevocation)
+			assert.NoError(tt, err)
+			assert.Equal(tt, expected, status, index)
+		}
+	})
+
+	t.Run("purpose mismatch", func(tt *testing.T) {
+		cred := getCred(1)
+		cred.CredentialStatus.(map[string]any)["statusPurpose"] = string(StatusSuspension)
+		_, _, err := CheckBitstringStatus(cred, fetch)
+		assert.ErrorContains(tt, err, "did not match purpose of status credential")
+	})
+
+	t.Run("wrong number of status messages", func(tt *testing.T) {
+		cred := getCred(1)
+		cred.CredentialStatus.(map[string]any)["statusMessage"] = statusMessages[:2]
+		_, _, err := CheckBitstringStatus(cred, fetch)
+		assert.ErrorContains(tt, err, "has 2 status messages, expected 4 for a status size of 2")
+	})
+
+	t.Run("index out of range", func(tt *testing.T) {
+		_, _, err := CheckBitstringStatus(getCred(bitstringStatusListMinEntries), fetch)
+		assert.ErrorContains(tt, err, "is out of range of status credential")
+	})
+
+	t.Run("fetch failure", func(tt *testing.T) {
+		cred := getCred(1)
+		cred.CredentialStatus.(map[string]any)["statusListCredential"] = "https://example.com/credentials/status/4"
+		_, _, err := CheckBitstringStatus(cred, fetch)
+		assert.ErrorContains(tt, err, "fetching status list credential<https://example.com/credentials/status/4>: not found")
+
+		_, _, err = CheckBitstringStatus(getCred(1), nil)
+		assert.ErrorContains(tt, err, "fetch function cannot be empty")
+	})
+
+	t.Run("invalid status entry", func(tt *testing.T) {
+		cred := getCred(1)
+		cred.CredentialStatus.(map[string]any)["type"] = StatusList2021EntryType
+		_, _, err := CheckBitstringStatus(cred, fetch)
+		assert.ErrorContains(tt, err, "not using the BitstringStatusList credentialStatus property")
+	})
+
+	t.Run("status list 2021 credential", func(tt *testing.T) {
+		statusList2021Cred, err := GenerateStatusList2021CredentialFromIndices(statusListURL, "did:example:issuer",
+			StatusRevocation, []int{1})
+		require.NoError(tt, err)
+		cred := getCred(1)
+		cred.CredentialStatus.(map[string]any)["statusPurpose"] = string(StatusRevocation)
+		_, _, err = CheckBitstringStatus(cred, func(string) (*credential.VerifiableCredential, error) {
+			return statusList2021Cred, nil
+		})
+		assert.ErrorContains(tt, err, "not BitstringStatusList")
+	})
+}
@@ -4,6 +4,7 @@ import (
 	"bytes"
 	"compress/gzip"
 	"encoding/base64"
+	"encoding/binary"
 	"fmt"
 	"io"
 	"strconv"
@@ -13,7 +14,6 @@ import (
 
 	"github.com/TBD54566975/ssi-sdk/credential"
 	"github.com/TBD54566975/ssi-sdk/util"
-	"github.com/bits-and-blooms/bitset"
 	"github.com/pkg/errors"
 )
 
@@ -31,6 +31,10 @@ const (
 
 	// KB represents the size of a KB
 	KB = 1 << 10
+
+	// maxBitstringSize is the maximum size of an expanded status list bitstring, 4MB or over 33 million indices, so
+	// that a small compressed list fetched from a remote source may not expand to exhaust memory
+	maxBitstringSize = 4 * KB * KB
 )
 
 // StatusList2021Entry the representation within a credential that is associated with a status list
@@ -60,8 +64,23 @@ func GenerateStatusList2021Credential(id string, issuer string, purpose StatusPu
 	if err != nil {
 		return nil, errors.Wrap(err, "could not generate status list credential")
 	}
+	indices, err := parseStatusListIndices(statusListIndices)
+	if err != nil {
+		return nil, errors.Wrap(err, "could not generate bitstring for status list credential")
+	}
+	return GenerateStatusList2021CredentialFromIndices(id, issuer, purpose, indices)
+}
+
+// GenerateStatusList2021CredentialFromIndices generates a status list credential given an ID (the URI where this
+// entity will be hosted), the issuer DID, the purpose of the list, which must be revocation or suspension, and the
+// status list indices of the credentials whose bits are set in the list.
+// https://w3c-ccg.github.io/vc-status-list-2021/#generate-algorithm
+func GenerateStatusList2021CredentialFromIndices(id string, issuer string, purpose StatusPurpose, revokedIndices []int) (*credential.VerifiableCredential, error) {
+	if purpose != StatusRevocation && purpose != StatusSuspension {
+		return nil, fmt.Errorf("could not generate status list credential: invalid status purpose: %s", purpose)
+	}
 
-	bitString, err := bitstringGeneration(statusListIndices)
+	bitString, err := generateBitstring(revokedIndices)
 	if err != nil {
 		return nil, errors.Wrap(err, "could not generate bitstring for status list credential")
 	}
@@ -162,90 +181,159 @@ func getStatusEntry(maybeCredentialStatus any) (*StatusList2021Entry, error) {
 
 // https://w3c-ccg.github.io/vc-status-list-2021/#bitstring-generation-algorithm
 func bitstringGeneration(statusListCredentialIndices []string) (string, error) {
-	// check to see there are no duplicate index values
-	duplicateCheck := make(map[uint]bool)
-
-	// 1. Let bitstring be a list of bits with a minimum size of 16KB, where each bit is initialized to 0 (zero).
-	b := bitset.New(16 * KB)
+	indices, err := parseStatusListIndices(statusListCredentialIndices)
+	if err != nil {
+		return "", err
+	}
+	return generateBitstring(indices)
+}
 
-	// 2. For each bit in bitstring, if there is a corresponding statusListIndex value in a revoked credential in
-	// issuedCredentials, set the bit to 1 (one), otherwise set the bit to 0 (zero).
+func parseStatusListIndices(statusListCredentialIndices []string) ([]int, error) {
+	indices := make([]int, 0, len(statusListCredentialIndices))
 	for _, index := range statusListCredentialIndices {
 		indexInt, err := strconv.Atoi(index)
 		if indexInt < 0 || err != nil {
-			return "", fmt.Errorf("invalid status list index value, not a valid positive integer: %s", index)
+			return nil, fmt.Errorf("invalid status list index value, not a valid positive integer: %s", index)
 		}
-		indexValue := uint(indexInt)
-		if _, ok := duplicateCheck[indexValue]; ok {
-			return "", fmt.Errorf("duplicate status list index value found: %d", indexValue)
+		indices = append(indices, indexInt)
+	}
+	return indices, nil
+}
+
+func generateBitstring(indices []int) (string, error) {
+	// check to see there are no duplicate index values
+	duplicateCheck := make(map[int]bool)
+
+	// 1. Let bitstring be a list of bits with a minimum size of 16KB, where each bit is initialized to 0 (zero).
+	// The list grows beyond 16KB to hold the largest index.
+	size := 16 * KB
+	for _, index := range indices {
+		if index < 0 {
+			return "", fmt.Errorf("invalid status list index value, not a valid positive integer: %d", index)
+		}
+		if index/8 >= size {
+			size = index/8 + 1
 		}
-		duplicateCheck[indexValue] = true
-		b.Set(indexValue)
 	}
+	bitstring := make([]byte, size)
 
-	bitstringBinary, err := b.MarshalBinary()
-	if err != nil {
-		return "", errors.Wrap(err, "could not generate bitstring binary representation")
+	// 2. For each bit in bitstring, if there is a corresponding statusListIndex value in a revoked credential in
+	// issuedCredentials, set the bit to 1 (one), otherwise set the bit to 0 (zero). The first index is the
+	// left-most bit of the bitstring.
+	for _, index := range indices {
+		if _, ok := duplicateCheck[index]; ok {
+			return "", fmt.Errorf("duplicate status list index value found: %d", index)
+		}
+		duplicateCheck[index] = true
+		bitstring[index/8] |= 0x80 >> (index % 8)
 	}
 
 	// 3. Generate a compressed bitstring by using the GZIP compression algorithm [RFC1952] on the bitstring and then
-	// base64-encoding [RFC4648] the result.
+	// base64url-encoding [RFC4648] the result.
 	var buf bytes.Buffer
 	zw := gzip.NewWriter(&buf)
-	if _, err = zw.Write(bitstringBinary); err != nil {
+	if _, err := zw.Write(bitstring); err != nil {
 		return "", errors.Wrap(err, "could not compress status list bitstring using GZIP")
 	}
 
-	if err = zw.Close(); err != nil {
+	if err := zw.Close(); err != nil {
 		return "", errors.Wrap(err, "could not close gzip writer")
 	}
 
-	base64Bitstring := base64.StdEncoding.EncodeToString(buf.Bytes())
+	base64Bitstring := base64.RawURLEncoding.EncodeToString(buf.Bytes())
 
 	// 4. Return the compressed bitstring.
 	return base64Bitstring, nil
 }
 
 // https://w3c-ccg.github.io/vc-status-list-2021/#bitstring-expansion-algorithm
+// Status lists encoded by earlier versions of this package, in the legacy format expanded by expandLegacyBitstring,
+// are expanded to the same indices.
 func bitstringExpansion(compressedBitstring string) ([]string, error) {
 	// 1. Let compressed bitstring be a compressed status list bitstring.
 
-	// 2. Generate an uncompressed bitstring by using the base64-decoding [RFC4648] algorithm on the compressed
-	// bitstring and then expanding the output using the GZIP decompression algorithm [RFC1952].
-	decoded, err := base64.StdEncoding.DecodeString(compressedBitstring)
+	// 2. Generate an uncompressed bitstring by using the base64url-decoding [RFC4648] algorithm on the compressed
+	// bitstring and then expanding the output using the GZIP decompression algorithm [RFC1952]. Legacy status lists
+	// are standard base64 encoded, so are decoded the same way once mapped to the base64url alphabet.
+	decoded, err := base64.RawURLEncoding.DecodeString(legacyBase64Replacer.Replace(strings.TrimRight(compressedBitstring, "=")))
 	if err != nil {
 		return nil, errors.Wrap(err, "could not decode compressed bitstring")
 	}
 
-	bitstringReader := bytes.NewReader(decoded)
-	zr, err := gzip.NewReader(bitstringReader)
+	unzipped, err := decompressBitstring(decoded)
+	if err != nil {
+		return nil, err
+	}
+
+	if expanded, ok := expandLegacyBitstring(compressedBitstring, unzipped); ok {
+		return expanded, nil
+	}
+
+	// find set bits to reconstruct the status list indices
+	var expanded []string
+	for i, b := range unzipped {
+		for bit := 0; bit < 8; bit++ {
+			if b&(0x80>>bit) != 0 {
+				expanded = append(expanded, strconv.Itoa(i*8+bit))
+			}
+		}
+	}
+	return expanded, nil
+}
+
+// decompressBitstring expands a GZIP-compressed status list bitstring, failing if it expands beyond maxBitstringSize
+func decompressBitstring(compressed []byte) ([]byte, error) {
+	zr, err := gzip.NewReader(bytes.NewReader(compressed))
 	if err != nil {
 		return nil, errors.Wrap(err, "could not unzip status list bitstring using GZIP")
 	}
 
-	unzipped, err := io.ReadAll(zr)
+	// read a byte past the limit to tell a bitstring of exactly the maximum size from a larger one
+	unzipped, err := io.ReadAll(io.LimitReader(zr, maxBitstringSize+1))
 	if err != nil {
 		return nil, errors.Wrap(err, "could not expand status list bitstring using GZIP")
 	}
+	if len(unzipped) > maxBitstringSize {
+		return nil, fmt.Errorf("status list bitstring expands beyond the maximum size of %d bytes", maxBitstringSize)
+	}
 
-	if err := zr.Close(); err != nil {
+	if err = zr.Close(); err != nil {
 		return nil, errors.Wrap(err, "could not close gzip reader")
 	}
+	return unzipped, nil
+}
+
+// legacyBase64Replacer maps the standard base64 alphabet of legacy status lists to the base64url alphabet
+var legacyBase64Replacer = strings.NewReplacer("+", "-", "/", "_")
 
-	b := bitset.New(uint(len(unzipped)))
-	if err := b.UnmarshalBinary(unzipped); err != nil {
-		return nil, errors.Wrap(err, "could not unmarshal binary bitstring")
+// expandLegacyBitstring expands a status list in the format of earlier versions of this package: the binary encoding
+// of a bits-and-blooms/bitset, a big-endian uint64 count of bits followed by big-endian uint64 words whose least
+// significant bit is the first index of the word, GZIP-compressed and standard base64 encoded. It reports false for a
+// bitstring not in that format. Spec-conformant lists are at least 16KB, while legacy lists were 16K bits unless an
+// index beyond them was set, so a larger list is only taken to be a legacy list when it is standard base64 encoded.
+func expandLegacyBitstring(compressedBitstring string, unzipped []byte) ([]string, bool) {
+	if len(unzipped) < 8 || len(unzipped)%8 != 0 {
+		return nil, false
+	}
+	bitCount := binary.BigEndian.Uint64(unzipped[:8])
+	words := unzipped[8:]
+	if bitCount > uint64(len(words))*8 || (bitCount+63)/64 != uint64(len(words)/8) {
+		return nil, false
+	}
+	if len(unzipped) >= 16*KB && !strings.ContainsAny(compressedBitstring, "+/=") {
+		return nil, false
 	}
 
-	// find set bits to reconstruct the status list indices
 	var expanded []string
-	var i uint
-	for i = 0; i < b.Len(); i++ {
-		if b.Test(i) {
-			expanded = append(expanded, strconv.Itoa(int(i)))
+	for i := 0; i < len(words)/8; i++ {
+		word := binary.BigEndian.Uint64(words[i*8 : i*8+8])
+		for bit := 0; bit < 64; bit++ {
+			if word&(1<<bit) != 0 {
+				expanded = append(expanded, strconv.Itoa(i*64+bit))
+			}
 		}
 	}
-	return expanded, nil
+	return expanded, true
 }
 
 // ValidateCredentialInStatusList determines whether a credential is contained in a status list 2021 credential
@@ -305,6 +393,38 @@ func ValidateCredentialInStatusList(credentialToValidate credential.VerifiableCr
 	return false, nil
 }
 
+// CheckStatus determines whether the status bit of a credential with a StatusList2021Entry credentialStatus is set,
+// meaning it is revoked or suspended, depending on the purpose of the entry. The status list credential named by the
+// entry is retrieved with the given fetch function, and must have the same status purpose as the entry and be within
+// its validity period.
+// NOTE: this method does not perform signature/proof verification of either credential
+func CheckStatus(vc credential.VerifiableCredential, fetch func(url string) (*credential.VerifiableCredential, error), opts ...CheckOption) (revoked bool, err error) {
+	if fetch == nil {
+		return false, errors.New("fetch function cannot be empty")
+	}
+	entry, err := getStatusEntry(vc.CredentialStatus)
+	if err != nil {
+		return false, errors.Wrapf(err, "credential<%s> not using the StatusList2021 credentialStatus property", vc.ID)
+	}
+	if entry.Type != StatusList2021EntryType {
+		return false, fmt.Errorf("credential<%s> has a credentialStatus of type<%s>, not %s", vc.ID, entry.Type, StatusList2021EntryType)
+	}
+
+	statusCredential, err := fetch(entry.StatusListCredential)
+	if err != nil {
+		return false, errors.Wrapf(err, "fetching status list credential<%s>", entry.StatusListCredential)
+	}
+	if statusCredential == nil {
+		return false, fmt.Errorf("status list credential<%s> not found", entry.StatusListCredential)
+	}
+	if err = newCheckOptions(opts).checkStatusCredentialValidity(*statusCredential); err != nil {
+		return false, err
+	}
+
+	vc.CredentialStatus = *entry
+	return ValidateCredentialInStatusList(vc, *statusCredential)
+}
+
 func toStatusList2021Entry(credStatus any) (*StatusList2021Entry, bool) {
 	statusListEntryValue, ok := credStatus.(StatusList2021Entry)
 	if ok {
@@ -1,11 +1,18 @@
 package status
 
 import (
+	"bytes"
+	"compress/gzip"
+	"encoding/base64"
+	"encoding/binary"
+	"errors"
+	"io"
 	"sort"
 	"testing"
 
 	"github.com/goccy/go-json"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 
 	"github.com/TBD54566975/ssi-sdk/credential"
 )
@@ -240,6 +247,151 @@ func TestGenerateStatusList2021Credential(t *testing.T) {
 	})
 }
 
+func TestGenerateStatusList2021CredentialFromIndices(t *testing.T) {
+	revocationID := "revocation-id"
+	testIssuer := "test-issuer"
+
+	t.Run("happy path", func(tt *testing.T) {
+		revokedIndices := []int{0, 7, 8, 1000, 131071}
+		statusCred, err := GenerateStatusList2021CredentialFromIndices(revocationID, testIssuer, StatusSuspension, revokedIndices)
+		assert.NoError(tt, err)
+		require.NotEmpty(tt, statusCred)
+		assert.Contains(tt, statusCred.Type, StatusList2021CreddentialType)
+		assert.Contains(tt, statusCred.Context, StatusList2021Context)
+		assert.Equal(tt, StatusList2021Type, statusCred.CredentialSubject["type"])
+		assert.Equal(tt, string(StatusSuspension), statusCred.CredentialSubject["statusPurpose"])
+
+		// decode the list by hand
+		encodedList, ok := statusCred.CredentialSubject["encodedList"].(string)
+		require.True(tt, ok)
+		bitstring := decodeTestBitstring(tt, encodedList)
+		assert.Len(tt, bitstring, 16*KB)
+
+		var setBits []int
+		for i, b := range bitstring {
+			for bit := 0; bit < 8; bit++ {
+				if b&(0x80>>bit) != 0 {
+					setBits = append(setBits, i*8+bit)
+				}
+			}
+		}
+		assert.Equal(tt, revokedIndices, setBits)
+
+		// the first index is the left-most bit
+		assert.Equal(tt, byte(0b10000001), bitstring[0])
+		assert.Equal(tt, byte(0b10000000), bitstring[1])
+	})
+
+	t.Run("list grows to hold large indices", func(tt *testing.T) {
+		statusCred, err := GenerateStatusList2021CredentialFromIndices(revocationID, testIssuer, StatusRevocation, []int{131072})
+		assert.NoError(tt, err)
+		bitstring := decodeTestBitstring(tt, statusCred.CredentialSubject["encodedList"].(string))
+		assert.Len(tt, bitstring, 16*KB+1)
+		assert.Equal(tt, byte(0b10000000), bitstring[16*KB])
+	})
+
+	t.Run("no revoked indices", func(tt *testing.T) {
+		statusCred, err := GenerateStatusList2021CredentialFromIndices(revocationID, testIssuer, StatusRevocation, nil)
+		assert.NoError(tt, err)
+		bitstring := decodeTestBitstring(tt, statusCred.CredentialSubject["encodedList"].(string))
+		assert.Equal(tt, make([]byte, 16*KB), bitstring)
+	})
+
+	t.Run("invalid purpose", func(tt *testing.T) {
+		_, err := GenerateStatusList2021CredentialFromIndices(revocationID, testIssuer, "bad", []int{1})
+		assert.Error(tt, err)
+		assert.Contains(tt, err.Error(), "invalid status purpose: bad")
+	})
+
+	t.Run("invalid indices", func(tt *testing.T) {
+		_, err := GenerateStatusList2021CredentialFromIndices(revocationID, testIssuer, StatusRevocation, []int{-1})
+		assert.Error(tt, err)
+		assert.Contains(tt, err.Error(), "not a valid positive integer: -1")
+
+		_, err = GenerateStatusList2021CredentialFromIndices(revocationID, testIssuer, StatusRevocation, []int{3, 3})
+		assert.Error(tt, err)
+		assert.Contains(tt, err.Error(), "duplicate status list index value found: 3")
+	})
+}
+
+func TestCheckStatus(t *testing.T) {
+	statusListURL := "https://example.com/credentials/status/3"
+	statusCred, err := GenerateStatusList2021CredentialFromIndices(statusListURL, "did:example:issuer", StatusRevocation, []int{5, 42})
+	require.NoError(t, err)
+	fetch := func(url string) (*credential.VerifiableCredential, error) {
+		if url != statusListURL {
+			return nil, errors.New("not found")
+		}
+		return statusCred, nil
+	}
+	getCred := func(purpose StatusPurpose, index string) credential.VerifiableCredential {
+		return credential.VerifiableCredential{
+			ID: "test-cred",
+			CredentialStatus: map[string]any{
+				"id":                   statusListURL + "#" + index,
+				"type":                 StatusList2021EntryType,
+				"statusPurpose":        string(purpose),
+				"statusListIndex":      index,
+				"statusListCredential": statusListURL,
+			},
+		}
+	}
+
+	t.Run("revoked index", func(tt *testing.T) {
+		revoked, err := CheckStatus(getCred(StatusRevocation, "42"), fetch)
+		assert.NoError(tt, err)
+		assert.True(tt, revoked)
+	})
+
+	t.Run("non-revoked index", func(tt *testing.T) {
+		revoked, err := CheckStatus(getCred(StatusRevocation, "43"), fetch)
+		assert.NoError(tt, err)
+		assert.False(tt, revoked)
+	})
+
+	t.Run("purpose mismatch", func(tt *testing.T) {
+		_, err := CheckStatus(getCred(StatusSuspension, "42"), fetch)
+		assert.Error(tt, err)
+		assert.Contains(tt, err.Error(), "did not match purpose of status credential")
+	})
+
+	t.Run("fetch failure", func(tt *testing.T) {
+		cred := getCred(StatusRevocation, "42")
+		cred.CredentialStatus.(map[string]any)["statusListCredential"] = "https://example.com/credentials/status/4"
+		_, err := CheckStatus(cred, fetch)
+		assert.Error(tt, err)
+		assert.Contains(tt, err.Error(), "fetching status list credential<https://example.com/credentials/status/4>: not found")
+
+		_, err = CheckStatus(getCred(StatusRevocation, "42"), nil)
+		assert.Error(tt, err)
+		assert.Contains(tt, err.Error(), "fetch function cannot be empty")
+	})
+
+	t.Run("invalid status entry", func(tt *testing.T) {
+		cred := getCred(StatusRevocation, "42")
+		delete(cred.CredentialStatus.(map[string]any), "statusListIndex")
+		_, err := CheckStatus(cred, fetch)
+		assert.Error(tt, err)
+		assert.Contains(tt, err.Error(), "not using the StatusList2021 credentialStatus property")
+
+		cred = getCred(StatusRevocation, "42")
+		cred.CredentialStatus.(map[string]any)["type"] = "RevocationList2020Status"
+		_, err = CheckStatus(cred, fetch)
+		assert.Error(tt, err)
+		assert.Contains(tt, err.Error(), "has a credentialStatus of type<RevocationList2020Status>")
+	})
+}
+
+func decodeTestBitstring(t *testing.T, encodedList string) []byte {
+	compressed, err := base64.RawURLEncoding.DecodeString(encodedList)
+	require.NoError(t, err)
+	zr, err := gzip.NewReader(bytes.NewReader(compressed))
+	require.NoError(t, err)
+	bitstring, err := io.ReadAll(zr)
+	require.NoError(t, err)
+	return bitstring
+}
+
 func TestValidateCredentialInStatusList(t *testing.T) {
 	t.Run("happy path validation", func(tt *testing.T) {
 		revocationID := "revocation-id"
@@ -434,4 +586,46 @@ func TestBitstringGenerationAndExpansion(t *testing.T) {
 		assert.Contains(tt, err.Error(), "duplicate status list index value found: 2")
 		assert.Empty(tt, bitString)
 	})
+
+	t.Run("bitstring expanding beyond the maximum size", func(tt *testing.T) {
+		var buf bytes.Buffer
+		zw := gzip.NewWriter(&buf)
+		_, err := zw.Write(make([]byte, maxBitstringSize+1))
+		require.NoError(tt, err)
+		require.NoError(tt, zw.Close())
+
+		_, err = bitstringExpansion(base64.RawURLEncoding.EncodeToString(buf.Bytes()))
+		assert.ErrorContains(tt, err, "status list bitstring expands beyond the maximum size of 4194304 bytes")
+	})
+
+	t.Run("legacy status lists", func(tt *testing.T) {
+		credIndices := []string{"0", "63", "64", "9999"}
+		expanded, err := bitstringExpansion(legacyBitstring(tt, 16*KB, 0, 63, 64, 9999))
+		assert.NoError(tt, err)
+		assert.EqualValues(tt, credIndices, expanded)
+
+		// legacy lists grew to hold larger indices
+		expanded, err = bitstringExpansion(legacyBitstring(tt, 200000, 7, 199999))
+		assert.NoError(tt, err)
+		assert.EqualValues(tt, []string{"7", "199999"}, expanded)
+	})
+}
+
+// legacyBitstring encodes a status list in the format of earlier versions of this package, the binary encoding of a
+// bitset of the given number of bits, GZIP-compressed and standard base64 encoded
+func legacyBitstring(t *testing.T, bitCount int, indices ...int) string {
+	words := make([]uint64, (bitCount+63)/64)
+	for _, index := range indices {
+		words[index/64] |= 1 << (index % 64)
+	}
+	var binaryBitset bytes.Buffer
+	require.NoError(t, binary.Write(&binaryBitset, binary.BigEndian, uint64(bitCount)))
+	require.NoError(t, binary.Write(&binaryBitset, binary.BigEndian, words))
+
+	var buf bytes.Buffer
+	zw := gzip.NewWriter(&buf)
+	_, err := zw.Write(binaryBitset.Bytes())
+	require.NoError(t, err)
+	require.NoError(t, zw.Close())
+	return base64.StdEncoding.EncodeToString(buf.Bytes())
 }
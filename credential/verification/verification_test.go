@@ -2,8 +2,10 @@ package verification
 
 import (
 	"testing"
+	"time"
 
 	"github.com/TBD54566975/ssi-sdk/credential"
+	"github.com/TBD54566975/ssi-sdk/util"
 	"github.com/stretchr/testify/assert"
 )
 
@@ -48,6 +50,24 @@ func TestVerifier(t *testing.T) {
 		err = verifier.VerifyCredential(sampleCredential)
 		assert.Error(tt, err)
 		assert.Contains(tt, err.Error(), "credential has expired as of 2021-01-01 00:00:00 +0000 UTC")
+
+		// vc-data-model 2.0 validUntil is treated as an expiration date
+		sampleCredential.ExpirationDate = ""
+		sampleCredential.ValidUntil = "2022-01-01T00:00:00Z"
+		err = verifier.VerifyCredential(sampleCredential)
+		assert.Error(tt, err)
+		assert.Contains(tt, err.Error(), "credential has expired as of 2022-01-01 00:00:00 +0000 UTC")
+
+		sampleCredential.ValidUntil = ""
+		assert.NoError(tt, verifier.VerifyCredential(sampleCredential))
+
+		// as of a time before it expired, the credential is not expired
+		sampleCredential.ExpirationDate = "2021-01-01T00:00:00Z"
+		asOf := WithClock(util.FixedClock(time.Date(2020, time.June, 1, 0, 0, 0, 0, time.UTC)))
+		assert.NoError(tt, verifier.VerifyCredential(sampleCredential, asOf))
+
+		err = verifier.VerifyCredential(sampleCredential, Option{ID: ClockOption, Option: "2020-06-01T00:00:00Z"})
+		assert.ErrorContains(tt, err, "the clock option provided must be a util.Clock")
 	})
 
 	t.Run("Schema Verifier", func(tt *testing.T) {
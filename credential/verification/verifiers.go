@@ -6,11 +6,13 @@ import (
 
 	"github.com/TBD54566975/ssi-sdk/credential"
 	credschema "github.com/TBD54566975/ssi-sdk/credential/schema"
+	"github.com/TBD54566975/ssi-sdk/util"
 	"github.com/pkg/errors"
 )
 
 const (
 	SchemaOption OptionKey = "schema"
+	ClockOption  OptionKey = "clock"
 )
 
 // VerifyValidCredential verifies a credential's object model depending on the struct tags used on VerifiableCredential
@@ -18,18 +20,38 @@ func VerifyValidCredential(cred credential.VerifiableCredential, _ ...Option) er
 	return cred.IsValid()
 }
 
-// VerifyExpiry verifies a credential's expiry date is not in the past. We assume the date is parseable as
-// an RFC3339 date time value.
-func VerifyExpiry(cred credential.VerifiableCredential, _ ...Option) error {
-	if cred.ExpirationDate == "" {
-		return nil
+// WithClock provides the clock the expiry of a credential is verified against as a verification option, such as to
+// verify a credential as of a past time
+func WithClock(clock util.Clock) Option {
+	return Option{
+		ID:     ClockOption,
+		Option: clock,
 	}
-	expiryTime, err := time.Parse(time.RFC3339, cred.ExpirationDate)
-	if err != nil {
-		return errors.Wrapf(err, "failed to parse expiry date: %s", cred.ExpirationDate)
+}
+
+// VerifyExpiry verifies a credential's expiry date, its expirationDate or vc-data-model 2.0 validUntil, is not in
+// the past, as of the current time or that of the clock given with WithClock. We assume the date is parseable as an
+// RFC3339 date time value.
+func VerifyExpiry(cred credential.VerifiableCredential, opts ...Option) error {
+	var clock util.Clock = util.RealClock{}
+	if maybeClock, err := GetVerificationOption(opts, ClockOption); err == nil {
+		optionClock, ok := maybeClock.(util.Clock)
+		if !ok {
+			return errors.New("the clock option provided must be a util.Clock")
+		}
+		clock = optionClock
 	}
-	if expiryTime.Before(time.Now()) {
-		return fmt.Errorf("credential has expired as of %s", expiryTime.String())
+	for _, expiry := range []string{cred.ExpirationDate, cred.ValidUntil} {
+		if expiry == "" {
+			continue
+		}
+		expiryTime, err := time.Parse(time.RFC3339, expiry)
+		if err != nil {
+			return errors.Wrapf(err, "failed to parse expiry date: %s", expiry)
+		}
+		if expiryTime.Before(clock.Now()) {
+			return fmt.Errorf("credential has expired as of %s", expiryTime.String())
+		}
 	}
 	return nil
 }
@@ -4,6 +4,7 @@ import (
 	"crypto/rand"
 	"crypto/sha256"
 	"fmt"
+	"io"
 	"strings"
 
 	bbsg2 "github.com/hyperledger/aries-framework-go/pkg/crypto/primitive/bbs12381g2pub"
@@ -11,11 +12,40 @@ import (
 
 // GenerateBBSKeyPair https://w3c-ccg.github.io/ldp-bbs2020
 func GenerateBBSKeyPair() (*bbsg2.PublicKey, *bbsg2.PrivateKey, error) {
+	return GenerateBBSKeyPairFromReader(rand.Reader)
+}
+
+// GenerateBBSKeyPairFromReader generates a BBS+ key pair from a seed read from the given reader, so the same
+// seed always yields the same key pair
+func GenerateBBSKeyPairFromReader(r io.Reader) (*bbsg2.PublicKey, *bbsg2.PrivateKey, error) {
 	seed := make([]byte, 32)
-	if _, err := rand.Read(seed); err != nil {
+	if _, err := io.ReadFull(r, seed); err != nil {
+		return nil, nil, err
+	}
+	pubKey, privKey, err := bbsg2.GenerateKeyPair(sha256.New, seed)
+	if err != nil {
+		return nil, nil, err
+	}
+	// round trip the public key through its compressed form so the point is in affine coordinates, which makes
+	// generated keys equal to the same keys after serialization
+	pubKeyBytes, err := pubKey.Marshal()
+	if err != nil {
 		return nil, nil, err
 	}
-	return bbsg2.GenerateKeyPair(sha256.New, seed)
+	pubKey, err = bbsg2.UnmarshalPublicKey(pubKeyBytes)
+	if err != nil {
+		return nil, nil, err
+	}
+	return pubKey, privKey, nil
+}
+
+// GenerateBLS12381G2Key generates a BLS12-381 G2 key pair for BBS+ signatures
+func GenerateBLS12381G2Key() (bbsg2.PublicKey, bbsg2.PrivateKey, error) {
+	pubKey, privKey, err := GenerateBBSKeyPair()
+	if err != nil {
+		return bbsg2.PublicKey{}, bbsg2.PrivateKey{}, err
+	}
+	return *pubKey, *privKey, nil
 }
 
 type BBSPlusSigner struct {
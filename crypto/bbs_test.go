@@ -1,6 +1,7 @@
 package crypto
 
 import (
+	"bytes"
 	"encoding/base64"
 	"testing"
 
@@ -18,6 +19,44 @@ func TestGenerateBBSKeyPair(t *testing.T) {
 		assert.NoError(tt, err)
 	})
 
+	t.Run("deterministic from a seeded reader", func(tt *testing.T) {
+		seed := bytes.Repeat([]byte{0x2a}, 32)
+		pubKey, privKey, err := GenerateBBSKeyPairFromReader(bytes.NewReader(seed))
+		assert.NoError(tt, err)
+		samePubKey, samePrivKey, err := GenerateBBSKeyPairFromReader(bytes.NewReader(seed))
+		assert.NoError(tt, err)
+		assert.Equal(tt, pubKey, samePubKey)
+		assert.Equal(tt, privKey, samePrivKey)
+
+		otherPubKey, _, err := GenerateBBSKeyPairFromReader(bytes.NewReader(bytes.Repeat([]byte{0x2b}, 32)))
+		assert.NoError(tt, err)
+		assert.NotEqual(tt, pubKey, otherPubKey)
+
+		// the public key is derived from the private key
+		derivedBytes, err := privKey.PublicKey().Marshal()
+		assert.NoError(tt, err)
+		pubKeyBytes, err := pubKey.Marshal()
+		assert.NoError(tt, err)
+		assert.Equal(tt, pubKeyBytes, derivedBytes)
+
+		_, _, err = GenerateBBSKeyPairFromReader(bytes.NewReader(seed[:16]))
+		assert.Error(tt, err)
+	})
+
+	t.Run("generate by key type", func(tt *testing.T) {
+		pubKey, privKey, err := GenerateKeyByKeyType(BLS12381G2)
+		assert.NoError(tt, err)
+		blsPubKey, ok := pubKey.(bbs.PublicKey)
+		assert.True(tt, ok)
+		blsPrivKey, ok := privKey.(bbs.PrivateKey)
+		assert.True(tt, ok)
+
+		msg := []byte("hello world")
+		signature, err := SignBBSMessage(&blsPrivKey, msg)
+		assert.NoError(tt, err)
+		assert.NoError(tt, VerifyBBSMessage(&blsPubKey, signature, msg))
+	})
+
 	t.Run("sign and verify message", func(tt *testing.T) {
 		pubKey, privKey, err := GenerateBBSKeyPair()
 		assert.NoError(tt, err)
@@ -0,0 +1,98 @@
+package crypto
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/sha256"
+	"encoding/binary"
+	"fmt"
+	"math"
+	"reflect"
+
+	"github.com/lestrrat-go/jwx/v2/x25519"
+	"github.com/pkg/errors"
+	"golang.org/x/crypto/curve25519"
+)
+
+// DeriveSharedSecret performs Elliptic Curve Diffie-Hellman between the private key and the public key of the given
+// key type, returning the raw shared secret Z. For the NIST curves Z is the x-coordinate of the shared point, encoded
+// at the curve's byte length. This is the input to the key derivation of ECDH-ES.
+// https://www.rfc-editor.org/rfc/rfc7518#section-4.6
+func DeriveSharedSecret(kt KeyType, privKey crypto.PrivateKey, pubKey crypto.PublicKey) ([]byte, error) {
+	if privKey == nil || pubKey == nil {
+		return nil, errors.New("private and public keys cannot be nil")
+	}
+	// dereference the ptrs
+	if reflect.ValueOf(privKey).Kind() == reflect.Ptr {
+		privKey = reflect.ValueOf(privKey).Elem().Interface().(crypto.PrivateKey)
+	}
+	if reflect.ValueOf(pubKey).Kind() == reflect.Ptr {
+		pubKey = reflect.ValueOf(pubKey).Elem().Interface().(crypto.PublicKey)
+	}
+	switch kt {
+	case X25519:
+		xPrivKey, ok := privKey.(x25519.PrivateKey)
+		if !ok || len(xPrivKey) != x25519.PrivateKeySize {
+			return nil, fmt.Errorf("private key is not of type %s", kt)
+		}
+		xPubKey, ok := pubKey.(x25519.PublicKey)
+		if !ok || len(xPubKey) != x25519.PublicKeySize {
+			return nil, fmt.Errorf("public key is not of type %s", kt)
+		}
+		secret, err := curve25519.X25519(xPrivKey.Seed(), xPubKey)
+		if err != nil {
+			return nil, errors.Wrap(err, "deriving x25519 shared secret")
+		}
+		return secret, nil
+	case P256, P384, P521:
+		curve := ellipticCurveForKeyType(kt)
+		ecdsaPrivKey, ok := privKey.(ecdsa.PrivateKey)
+		if !ok || ecdsaPrivKey.Curve != curve {
+			return nil, fmt.Errorf("private key is not of type %s", kt)
+		}
+		ecdsaPubKey, ok := pubKey.(ecdsa.PublicKey)
+		if !ok || ecdsaPubKey.Curve != curve {
+			return nil, fmt.Errorf("public key is not of type %s", kt)
+		}
+		if ecdsaPubKey.X == nil || ecdsaPubKey.Y == nil || !curve.IsOnCurve(ecdsaPubKey.X, ecdsaPubKey.Y) {
+			return nil, fmt.Errorf("public key is not on the %s curve", kt)
+		}
+		x, y := curve.ScalarMult(ecdsaPubKey.X, ecdsaPubKey.Y, ecdsaPrivKey.D.Bytes())
+		if x.Sign() == 0 && y.Sign() == 0 {
+			return nil, errors.New("shared secret is the point at infinity")
+		}
+		return x.FillBytes(make([]byte, (curve.Params().BitSize+7)/8)), nil
+	default:
+		return nil, fmt.Errorf("key agreement not supported for key type: %s", kt)
+	}
+}
+
+// ConcatKDF derives a key of keyLen bytes from the shared secret Z using the single-step Concat KDF of NIST SP 800-56A
+// with SHA-256, as used by ECDH-ES. The algorithm ID and party info are each prefixed with their 32-bit length, and the
+// supplementary public info is the key length in bits.
+// https://www.rfc-editor.org/rfc/rfc7518#section-4.6.2
+func ConcatKDF(z []byte, keyLen int, algID, partyUInfo, partyVInfo []byte) ([]byte, error) {
+	if len(z) == 0 {
+		return nil, errors.New("shared secret cannot be empty")
+	}
+	if keyLen <= 0 || keyLen > math.MaxInt32/8 {
+		return nil, fmt.Errorf("invalid key length: %d", keyLen)
+	}
+
+	otherInfo := make([]byte, 0, 16+len(algID)+len(partyUInfo)+len(partyVInfo))
+	for _, data := range [][]byte{algID, partyUInfo, partyVInfo} {
+		otherInfo = binary.BigEndian.AppendUint32(otherInfo, uint32(len(data)))
+		otherInfo = append(otherInfo, data...)
+	}
+	otherInfo = binary.BigEndian.AppendUint32(otherInfo, uint32(keyLen*8))
+
+	derived := make([]byte, 0, keyLen+sha256.Size)
+	for counter := uint32(1); len(derived) < keyLen; counter++ {
+		h := sha256.New()
+		_ = binary.Write(h, binary.BigEndian, counter)
+		h.Write(z)
+		h.Write(otherInfo)
+		derived = h.Sum(derived)
+	}
+	return derived[:keyLen], nil
+}
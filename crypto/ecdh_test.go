@@ -0,0 +1,149 @@
+package crypto
+
+import (
+	"encoding/base64"
+	"encoding/hex"
+	"testing"
+
+	"github.com/lestrrat-go/jwx/v2/x25519"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDeriveSharedSecret(t *testing.T) {
+	tests := []struct {
+		kt         KeyType
+		secretSize int
+	}{
+		{kt: X25519, secretSize: 32},
+		{kt: P256, secretSize: 32},
+		{kt: P384, secretSize: 48},
+		{kt: P521, secretSize: 66},
+	}
+	for _, test := range tests {
+		t.Run(string(test.kt), func(tt *testing.T) {
+			pubKeyA, privKeyA, err := GenerateKeyByKeyType(test.kt)
+			require.NoError(tt, err)
+			pubKeyB, privKeyB, err := GenerateKeyByKeyType(test.kt)
+			require.NoError(tt, err)
+
+			secretAB, err := DeriveSharedSecret(test.kt, privKeyA, pubKeyB)
+			assert.NoError(tt, err)
+			assert.Len(tt, secretAB, test.secretSize)
+
+			secretBA, err := DeriveSharedSecret(test.kt, privKeyB, pubKeyA)
+			assert.NoError(tt, err)
+			assert.Equal(tt, secretAB, secretBA)
+
+			secretPtr, err := DeriveSharedSecret(test.kt, &privKeyB, &pubKeyA)
+			assert.NoError(tt, err)
+			assert.Equal(tt, secretAB, secretPtr)
+
+			// a different party yields a different secret
+			pubKeyC, _, err := GenerateKeyByKeyType(test.kt)
+			require.NoError(tt, err)
+			secretAC, err := DeriveSharedSecret(test.kt, privKeyA, pubKeyC)
+			assert.NoError(tt, err)
+			assert.NotEqual(tt, secretAB, secretAC)
+		})
+	}
+
+	// https://www.rfc-editor.org/rfc/rfc7748#section-6.1
+	t.Run("x25519 vector", func(tt *testing.T) {
+		seedA, err := hex.DecodeString("77076d0a7318a57d3c16c17251b26645df4c2f87ebc0992ab177fba51db92c2a")
+		require.NoError(tt, err)
+		seedB, err := hex.DecodeString("5dab087e624a8a4b79e17f8b83800ee66f3bb1292618b6fd1c2f8b27ff88e0eb")
+		require.NoError(tt, err)
+		privKeyA, err := x25519.NewKeyFromSeed(seedA)
+		require.NoError(tt, err)
+		privKeyB, err := x25519.NewKeyFromSeed(seedB)
+		require.NoError(tt, err)
+		pubKeyA := privKeyA.Public().(x25519.PublicKey)
+		pubKeyB := privKeyB.Public().(x25519.PublicKey)
+		assert.Equal(tt, "8520f0098930a754748b7ddcb43ef75a0dbf3a0d26381af4eba4a98eaa9b4e6a", hex.EncodeToString(pubKeyA))
+		assert.Equal(tt, "de9edb7d7b7dc1b4d35b61c2ece435373f8343c85b78674dadfc7e146f882b4f", hex.EncodeToString(pubKeyB))
+
+		secretAB, err := DeriveSharedSecret(X25519, privKeyA, pubKeyB)
+		assert.NoError(tt, err)
+		secretBA, err := DeriveSharedSecret(X25519, privKeyB, pubKeyA)
+		assert.NoError(tt, err)
+		assert.Equal(tt, "4a5d9d5ba4ce2de1728e3bf480350f25e07e21c947d19e3376f09b3c1e161742", hex.EncodeToString(secretAB))
+		assert.Equal(tt, secretAB, secretBA)
+	})
+
+	t.Run("mismatched keys", func(tt *testing.T) {
+		_, privKey, err := GenerateP256Key()
+		require.NoError(tt, err)
+		pubKey, _, err := GenerateP384Key()
+		require.NoError(tt, err)
+		_, err = DeriveSharedSecret(P256, privKey, pubKey)
+		assert.Error(tt, err)
+		assert.Contains(tt, err.Error(), "public key is not of type P-256")
+
+		_, err = DeriveSharedSecret(P384, privKey, pubKey)
+		assert.Error(tt, err)
+		assert.Contains(tt, err.Error(), "private key is not of type P-384")
+
+		// the public key must be on the curve
+		offCurve, _, err := GenerateP256Key()
+		require.NoError(tt, err)
+		offCurve.Y.Add(offCurve.Y, offCurve.Y)
+		_, err = DeriveSharedSecret(P256, privKey, offCurve)
+		assert.Error(tt, err)
+		assert.Contains(tt, err.Error(), "not on the P-256 curve")
+
+		// low order x25519 points are rejected
+		_, xPrivKey, err := GenerateX25519Key()
+		require.NoError(tt, err)
+		_, err = DeriveSharedSecret(X25519, xPrivKey, make(x25519.PublicKey, x25519.PublicKeySize))
+		assert.Error(tt, err)
+	})
+
+	t.Run("unsupported key types", func(tt *testing.T) {
+		pubKey, privKey, err := GenerateEd25519Key()
+		require.NoError(tt, err)
+		_, err = DeriveSharedSecret(Ed25519, privKey, pubKey)
+		assert.Error(tt, err)
+		assert.Contains(tt, err.Error(), "key agreement not supported for key type: Ed25519")
+
+		_, err = DeriveSharedSecret(X25519, nil, nil)
+		assert.Error(tt, err)
+	})
+}
+
+func TestConcatKDF(t *testing.T) {
+	// https://www.rfc-editor.org/rfc/rfc7518#appendix-C
+	t.Run("rfc 7518 vector", func(tt *testing.T) {
+		z := []byte{158, 86, 217, 29, 129, 113, 53, 211, 114, 131, 66, 131, 191, 132, 38, 156, 251, 49, 110, 163,
+			218, 128, 106, 72, 246, 218, 167, 121, 140, 254, 144, 196}
+		derived, err := ConcatKDF(z, 16, []byte("A128GCM"), []byte("Alice"), []byte("Bob"))
+		assert.NoError(tt, err)
+		assert.Equal(tt, []byte{86, 170, 141, 234, 248, 35, 109, 32, 92, 34, 40, 205, 113, 167, 16, 26}, derived)
+		assert.Equal(tt, "VqqN6vgjbSBcIijNcacQGg", base64.RawURLEncoding.EncodeToString(derived))
+	})
+
+	t.Run("derives keys longer than the hash", func(tt *testing.T) {
+		z := []byte("shared secret")
+		derived, err := ConcatKDF(z, 64, []byte("A256CBC-HS512"), nil, nil)
+		assert.NoError(tt, err)
+		assert.Len(tt, derived, 64)
+
+		again, err := ConcatKDF(z, 64, []byte("A256CBC-HS512"), nil, nil)
+		assert.NoError(tt, err)
+		assert.Equal(tt, derived, again)
+
+		// the key length is bound into the derivation, so a shorter key is not a prefix
+		shorter, err := ConcatKDF(z, 32, []byte("A256CBC-HS512"), nil, nil)
+		assert.NoError(tt, err)
+		assert.NotEqual(tt, derived[:32], shorter)
+	})
+
+	t.Run("bad input", func(tt *testing.T) {
+		_, err := ConcatKDF(nil, 16, []byte("A128GCM"), nil, nil)
+		assert.Error(tt, err)
+
+		_, err = ConcatKDF([]byte("shared secret"), 0, []byte("A128GCM"), nil, nil)
+		assert.Error(tt, err)
+		assert.Contains(tt, err.Error(), "invalid key length")
+	})
+}
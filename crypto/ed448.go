@@ -0,0 +1,36 @@
+package crypto
+
+import (
+	"crypto/rand"
+	"fmt"
+
+	"github.com/cloudflare/circl/sign/ed448"
+	"github.com/pkg/errors"
+)
+
+// GenerateEd448Key generates a new Ed448 key pair
+func GenerateEd448Key() (ed448.PublicKey, ed448.PrivateKey, error) {
+	return ed448.GenerateKey(rand.Reader)
+}
+
+// SignEd448 signs the message with the private key using pure Ed448 with an empty context, returning the signature
+// https://www.rfc-editor.org/rfc/rfc8032#section-5.2.6
+func SignEd448(privKey ed448.PrivateKey, message []byte) ([]byte, error) {
+	if len(privKey) != ed448.PrivateKeySize {
+		return nil, fmt.Errorf("invalid ed448 private key size: %d", len(privKey))
+	}
+	return ed448.Sign(privKey, message, ""), nil
+}
+
+// VerifyEd448 verifies a pure Ed448 signature with an empty context over the message, returning an error if the
+// signature is invalid
+// https://www.rfc-editor.org/rfc/rfc8032#section-5.2.7
+func VerifyEd448(pubKey ed448.PublicKey, message, signature []byte) error {
+	if len(pubKey) != ed448.PublicKeySize {
+		return fmt.Errorf("invalid ed448 public key size: %d", len(pubKey))
+	}
+	if !ed448.Verify(pubKey, message, signature, "") {
+		return errors.New("invalid ed448 signature")
+	}
+	return nil
+}
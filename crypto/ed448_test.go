@@ -0,0 +1,85 @@
+package crypto
+
+import (
+	"encoding/hex"
+	"testing"
+
+	"github.com/cloudflare/circl/sign/ed448"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// https://www.rfc-editor.org/rfc/rfc8032#section-7.4
+func TestEd448Vectors(t *testing.T) {
+	vectors := []struct {
+		name    string
+		seed    string
+		pubKey  string
+		message string
+		sig     string
+	}{
+		{
+			name:    "blank",
+			seed:    "6c82a562cb808d10d632be89c8513ebf6c929f34ddfa8c9f63c9960ef6e348a3528c8a3fcc2f044e39a3fc5b94492f8f032e7549a20098f95b",
+			pubKey:  "5fd7449b59b461fd2ce787ec616ad46a1da1342485a70e1f8a0ea75d80e96778edf124769b46c7061bd6783df1e50f6cd1fa1abeafe8256180",
+			message: "",
+			sig:     "533a37f6bbe457251f023c0d88f976ae2dfb504a843e34d2074fd823d41a591f2b233f034f628281f2fd7a22ddd47d7828c59bd0a21bfd3980ff0d2028d4b18a9df63e006c5d1c2d345b925d8dc00b4104852db99ac5c7cdda8530a113a0f4dbb61149f05a7363268c71d95808ff2e652600",
+		},
+		{
+			name:    "1 octet",
+			seed:    "c4eab05d357007c632f3dbb48489924d552b08fe0c353a0d4a1f00acda2c463afbea67c5e8d2877c5e3bc397a659949ef8021e954e0a12274e",
+			pubKey:  "43ba28f430cdff456ae531545f7ecd0ac834a55d9358c0372bfa0c6c6798c0866aea01eb00742802b8438ea4cb82169c235160627b4c3a9480",
+			message: "03",
+			sig:     "26b8f91727bd62897af15e41eb43c377efb9c610d48f2335cb0bd0087810f4352541b143c4b981b7e18f62de8ccdf633fc1bf037ab7cd779805e0dbcc0aae1cbcee1afb2e027df36bc04dcecbf154336c19f0af7e0a6472905e799f1953d2a0ff3348ab21aa4adafd1d234441cf807c03a00",
+		},
+	}
+
+	for _, v := range vectors {
+		t.Run(v.name, func(tt *testing.T) {
+			seed, err := hex.DecodeString(v.seed)
+			require.NoError(tt, err)
+			message, err := hex.DecodeString(v.message)
+			require.NoError(tt, err)
+			expectedSig, err := hex.DecodeString(v.sig)
+			require.NoError(tt, err)
+
+			privKey := ed448.NewKeyFromSeed(seed)
+			pubKey := privKey.Public().(ed448.PublicKey)
+			assert.Equal(tt, v.pubKey, hex.EncodeToString(pubKey))
+
+			sig, err := SignEd448(privKey, message)
+			assert.NoError(tt, err)
+			assert.Equal(tt, expectedSig, sig)
+			assert.NoError(tt, VerifyEd448(pubKey, message, sig))
+
+			tampered := append([]byte{}, sig...)
+			tampered[0] ^= 0xff
+			assert.Error(tt, VerifyEd448(pubKey, message, tampered))
+		})
+	}
+}
+
+func TestEd448(t *testing.T) {
+	t.Run("generate by key type", func(tt *testing.T) {
+		pubKey, privKey, err := GenerateKeyByKeyType(Ed448)
+		require.NoError(tt, err)
+		edPubKey, ok := pubKey.(ed448.PublicKey)
+		assert.True(tt, ok)
+		edPrivKey, ok := privKey.(ed448.PrivateKey)
+		assert.True(tt, ok)
+
+		message := []byte("hello world")
+		sig, err := SignEd448(edPrivKey, message)
+		assert.NoError(tt, err)
+		assert.NoError(tt, VerifyEd448(edPubKey, message, sig))
+		assert.Error(tt, VerifyEd448(edPubKey, []byte("goodbye world"), sig))
+	})
+
+	t.Run("invalid keys", func(tt *testing.T) {
+		_, err := SignEd448(ed448.PrivateKey{1, 2, 3}, []byte("hello world"))
+		assert.Error(tt, err)
+
+		err = VerifyEd448(ed448.PublicKey{1, 2, 3}, []byte("hello world"), nil)
+		assert.Error(tt, err)
+	})
+}
@@ -0,0 +1,534 @@
+package jwx
+
+import (
+	gocrypto "crypto"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/base64"
+	"encoding/binary"
+	"fmt"
+	"strings"
+
+	"github.com/TBD54566975/ssi-sdk/crypto"
+	"github.com/goccy/go-json"
+	"github.com/lestrrat-go/jwx/v2/jwa"
+	"github.com/lestrrat-go/jwx/v2/jwk"
+	"github.com/pkg/errors"
+)
+
+const (
+	// aesKeyWrapBlockSize is the size of the 64-bit blocks of AES Key Wrap https://www.rfc-editor.org/rfc/rfc3394
+	aesKeyWrapBlockSize = 8
+	// a256KeySize is the size of the content encryption and key wrapping keys for A256GCM and A256KW
+	a256KeySize = 32
+)
+
+// aesKeyWrapIV is the default initial value of AES Key Wrap https://www.rfc-editor.org/rfc/rfc3394#section-2.2.3.1
+var aesKeyWrapIV = []byte{0xa6, 0xa6, 0xa6, 0xa6, 0xa6, 0xa6, 0xa6, 0xa6}
+
+// jweHeader holds the header parameters of a JWE https://www.rfc-editor.org/rfc/rfc7516#section-4.1
+type jweHeader struct {
+	Alg  string        `json:"alg,omitempty"`
+	Enc  string        `json:"enc,omitempty"`
+	EPK  *PublicKeyJWK `json:"epk,omitempty"`
+	APU  string        `json:"apu,omitempty"`
+	APV  string        `json:"apv,omitempty"`
+	KID  string        `json:"kid,omitempty"`
+	Zip  string        `json:"zip,omitempty"`
+	Crit []string      `json:"crit,omitempty"`
+}
+
+// generalJWE is the general JSON serialization of a JWE https://www.rfc-editor.org/rfc/rfc7516#section-7.2.1
+type generalJWE struct {
+	Protected   string                `json:"protected,omitempty"`
+	Unprotected *jweHeader            `json:"unprotected,omitempty"`
+	Recipients  []generalJWERecipient `json:"recipients"`
+	AAD         string                `json:"aad,omitempty"`
+	IV          string                `json:"iv"`
+	Ciphertext  string                `json:"ciphertext"`
+	Tag         string                `json:"tag"`
+}
+
+type generalJWERecipient struct {
+	Header       *jweHeader `json:"header,omitempty"`
+	EncryptedKey string     `json:"encrypted_key,omitempty"`
+}
+
+// EncryptJWE encrypts the plaintext to the recipient's public key, returning a compact JWE. The alg must be
+// ECDH-ES+A256KW and the enc must be A256GCM; the recipient must be a P-256, P-384, P-521, or X25519 key. A fresh
+// ephemeral key on the recipient's curve is generated for each message and placed in the epk header.
+// https://www.rfc-editor.org/rfc/rfc7518#section-4.6
+func EncryptJWE(recipient PublicKeyJWK, plaintext []byte, alg, enc string) (string, error) {
+	if err := checkJWEAlgorithms(alg, enc); err != nil {
+		return "", err
+	}
+	cek, err := generateContentEncryptionKey()
+	if err != nil {
+		return "", err
+	}
+	epk, encryptedKey, err := wrapContentEncryptionKey(recipient, alg, cek)
+	if err != nil {
+		return "", err
+	}
+
+	headerBytes, err := json.Marshal(jweHeader{Alg: alg, Enc: enc, EPK: epk, KID: recipient.KID})
+	if err != nil {
+		return "", errors.Wrap(err, "marshalling JWE header")
+	}
+	protected := base64.RawURLEncoding.EncodeToString(headerBytes)
+	iv, ciphertext, tag, err := encryptContent(cek, plaintext, []byte(protected))
+	if err != nil {
+		return "", err
+	}
+
+	return strings.Join([]string{
+		protected,
+		base64.RawURLEncoding.EncodeToString(encryptedKey),
+		base64.RawURLEncoding.EncodeToString(iv),
+		base64.RawURLEncoding.EncodeToString(ciphertext),
+		base64.RawURLEncoding.EncodeToString(tag),
+	}, "."), nil
+}
+
+// DecryptJWE decrypts a compact JWE encrypted with ECDH-ES+A256KW and A256GCM to the recipient's private key, returning
+// the plaintext. An error is returned if the JWE has been tampered with or was not encrypted to the recipient.
+func DecryptJWE(recipientPriv PrivateKeyJWK, jwe string) ([]byte, error) {
+	parts := strings.Split(jwe, ".")
+	if len(parts) != 5 {
+		return nil, fmt.Errorf("invalid compact JWE: expected 5 parts, got %d", len(parts))
+	}
+	decoded := make([][]byte, len(parts))
+	for i, part := range parts {
+		b, err := base64.RawURLEncoding.DecodeString(part)
+		if err != nil {
+			return nil, errors.Wrapf(err, "decoding JWE part %d", i)
+		}
+		decoded[i] = b
+	}
+
+	var header jweHeader
+	if err := json.Unmarshal(decoded[0], &header); err != nil {
+		return nil, errors.Wrap(err, "unmarshalling JWE header")
+	}
+	cek, err := unwrapContentEncryptionKey(recipientPriv, header, decoded[1])
+	if err != nil {
+		return nil, err
+	}
+	return decryptContent(cek, decoded[2], decoded[3], decoded[4], []byte(parts[0]))
+}
+
+// EncryptJWEMulti encrypts the plaintext to each of the recipients' public keys, returning a general JSON serialized
+// JWE. A single content encryption key, encrypted with enc, is wrapped for each recipient with ECDH-ES+A256KW, and each
+// recipient's header carries its own epk and the kid of its key.
+func EncryptJWEMulti(recipients []PublicKeyJWK, plaintext []byte, enc string) ([]byte, error) {
+	if len(recipients) == 0 {
+		return nil, errors.New("at least one recipient is required")
+	}
+	alg := jwa.ECDH_ES_A256KW.String()
+	if err := checkJWEAlgorithms(alg, enc); err != nil {
+		return nil, err
+	}
+	cek, err := generateContentEncryptionKey()
+	if err != nil {
+		return nil, err
+	}
+
+	jweRecipients := make([]generalJWERecipient, 0, len(recipients))
+	for i, recipient := range recipients {
+		epk, encryptedKey, err := wrapContentEncryptionKey(recipient, alg, cek)
+		if err != nil {
+			return nil, errors.Wrapf(err, "wrapping key for recipient %d", i)
+		}
+		jweRecipients = append(jweRecipients, generalJWERecipient{
+			Header:       &jweHeader{Alg: alg, KID: recipient.KID, EPK: epk},
+			EncryptedKey: base64.RawURLEncoding.EncodeToString(encryptedKey),
+		})
+	}
+
+	headerBytes, err := json.Marshal(jweHeader{Enc: enc})
+	if err != nil {
+		return nil, errors.Wrap(err, "marshalling JWE header")
+	}
+	protected := base64.RawURLEncoding.EncodeToString(headerBytes)
+	iv, ciphertext, tag, err := encryptContent(cek, plaintext, []byte(protected))
+	if err != nil {
+		return nil, err
+	}
+
+	return json.Marshal(generalJWE{
+		Protected:  protected,
+		Recipients: jweRecipients,
+		IV:         base64.RawURLEncoding.EncodeToString(iv),
+		Ciphertext: base64.RawURLEncoding.EncodeToString(ciphertext),
+		Tag:        base64.RawURLEncoding.EncodeToString(tag),
+	})
+}
+
+// DecryptJWEMulti decrypts a general JSON serialized JWE with the private key of one of its recipients, returning the
+// plaintext. The recipient is found by the kid of the private key; if no recipient has a matching kid, the content
+// encryption key of each recipient with a key of the same type is unwrapped in turn until one succeeds.
+func DecryptJWEMulti(priv PrivateKeyJWK, jwe []byte) ([]byte, error) {
+	var general generalJWE
+	if err := json.Unmarshal(jwe, &general); err != nil {
+		return nil, errors.Wrap(err, "unmarshalling JWE")
+	}
+	if len(general.Recipients) == 0 {
+		return nil, errors.New("JWE has no recipients")
+	}
+	var protected jweHeader
+	if general.Protected != "" {
+		headerBytes, err := base64.RawURLEncoding.DecodeString(general.Protected)
+		if err != nil {
+			return nil, errors.Wrap(err, "decoding JWE protected header")
+		}
+		if err = json.Unmarshal(headerBytes, &protected); err != nil {
+			return nil, errors.Wrap(err, "unmarshalling JWE protected header")
+		}
+	}
+	var decoded [3][]byte
+	for i, part := range []string{general.IV, general.Ciphertext, general.Tag} {
+		b, err := base64.RawURLEncoding.DecodeString(part)
+		if err != nil {
+			return nil, errors.Wrapf(err, "decoding JWE part %d", i)
+		}
+		decoded[i] = b
+	}
+	aad := general.Protected
+	if general.AAD != "" {
+		aad += "." + general.AAD
+	}
+
+	// prefer the recipients addressed by kid, falling back to trying every recipient
+	var candidates []generalJWERecipient
+	if priv.KID != "" {
+		for _, recipient := range general.Recipients {
+			if recipient.Header != nil && recipient.Header.KID == priv.KID {
+				candidates = append(candidates, recipient)
+			}
+		}
+	}
+	if len(candidates) == 0 {
+		candidates = general.Recipients
+	}
+
+	var lastErr error
+	for _, recipient := range candidates {
+		header := mergeJWEHeaders(&protected, general.Unprotected, recipient.Header)
+		if header.EPK != nil && (header.EPK.KTY != priv.KTY || header.EPK.CRV != priv.CRV) {
+			continue
+		}
+		encryptedKey, err := base64.RawURLEncoding.DecodeString(recipient.EncryptedKey)
+		if err != nil {
+			lastErr = errors.Wrap(err, "decoding encrypted key")
+			continue
+		}
+		cek, err := unwrapContentEncryptionKey(priv, header, encryptedKey)
+		if err != nil {
+			lastErr = err
+			continue
+		}
+		return decryptContent(cek, decoded[0], decoded[1], decoded[2], []byte(aad))
+	}
+	if lastErr != nil {
+		return nil, errors.Wrap(lastErr, "no recipient could be decrypted with the key")
+	}
+	return nil, fmt.Errorf("no recipient with a key of type kty<%s> crv<%s>", priv.KTY, priv.CRV)
+}
+
+// mergeJWEHeaders combines the protected, shared unprotected, and per-recipient headers of a general JSON serialized
+// JWE, whose parameter names must be disjoint https://www.rfc-editor.org/rfc/rfc7516#section-7.2.1
+func mergeJWEHeaders(headers ...*jweHeader) jweHeader {
+	var merged jweHeader
+	for _, header := range headers {
+		if header == nil {
+			continue
+		}
+		if merged.Alg == "" {
+			merged.Alg = header.Alg
+		}
+		if merged.Enc == "" {
+			merged.Enc = header.Enc
+		}
+		if merged.EPK == nil {
+			merged.EPK = header.EPK
+		}
+		if merged.APU == "" {
+			merged.APU = header.APU
+		}
+		if merged.APV == "" {
+			merged.APV = header.APV
+		}
+		if merged.KID == "" {
+			merged.KID = header.KID
+		}
+		if merged.Zip == "" {
+			merged.Zip = header.Zip
+		}
+		merged.Crit = append(merged.Crit, header.Crit...)
+	}
+	return merged
+}
+
+// generateContentEncryptionKey generates a random A256GCM content encryption key
+func generateContentEncryptionKey() ([]byte, error) {
+	cek := make([]byte, a256KeySize)
+	if _, err := rand.Read(cek); err != nil {
+		return nil, errors.Wrap(err, "generating content encryption key")
+	}
+	return cek, nil
+}
+
+// wrapContentEncryptionKey wraps the content encryption key for the recipient with a fresh ephemeral key, returning the
+// ephemeral public key for the epk header and the encrypted key
+func wrapContentEncryptionKey(recipient PublicKeyJWK, alg string, cek []byte) (*PublicKeyJWK, []byte, error) {
+	kt, err := ecdhKeyTypeForJWK(recipient.KTY, recipient.CRV)
+	if err != nil {
+		return nil, nil, err
+	}
+	recipientKey, err := recipient.ToPublicKey()
+	if err != nil {
+		return nil, nil, errors.Wrap(err, "converting recipient JWK to public key")
+	}
+
+	ephemeralPubKey, ephemeralPrivKey, err := crypto.GenerateKeyByKeyType(kt)
+	if err != nil {
+		return nil, nil, errors.Wrap(err, "generating ephemeral key")
+	}
+	epk, err := ephemeralPublicKeyJWK(ephemeralPubKey)
+	if err != nil {
+		return nil, nil, err
+	}
+	kek, err := deriveKeyEncryptionKey(kt, alg, ephemeralPrivKey, recipientKey, nil, nil)
+	if err != nil {
+		return nil, nil, err
+	}
+	encryptedKey, err := aesKeyWrap(kek, cek)
+	if err != nil {
+		return nil, nil, errors.Wrap(err, "wrapping content encryption key")
+	}
+	return epk, encryptedKey, nil
+}
+
+// unwrapContentEncryptionKey unwraps the encrypted key with the recipient's private key, using the algorithms and epk
+// of the header
+func unwrapContentEncryptionKey(recipientPriv PrivateKeyJWK, header jweHeader, encryptedKey []byte) ([]byte, error) {
+	if err := checkJWEAlgorithms(header.Alg, header.Enc); err != nil {
+		return nil, err
+	}
+	if header.Zip != "" {
+		return nil, fmt.Errorf("unsupported JWE compression: %s", header.Zip)
+	}
+	if len(header.Crit) > 0 {
+		return nil, fmt.Errorf("%w: %s", ErrUnunderstoodCritical, strings.Join(header.Crit, ", "))
+	}
+	if header.EPK == nil {
+		return nil, errors.New("JWE header is missing epk")
+	}
+
+	kt, err := ecdhKeyTypeForJWK(recipientPriv.KTY, recipientPriv.CRV)
+	if err != nil {
+		return nil, err
+	}
+	if header.EPK.KTY != recipientPriv.KTY || header.EPK.CRV != recipientPriv.CRV {
+		return nil, fmt.Errorf("epk of type %s %s does not match recipient key of type %s %s",
+			header.EPK.KTY, header.EPK.CRV, recipientPriv.KTY, recipientPriv.CRV)
+	}
+	recipientKey, err := recipientPriv.ToPrivateKey()
+	if err != nil {
+		return nil, errors.Wrap(err, "converting recipient JWK to private key")
+	}
+	ephemeralKey, err := header.EPK.ToPublicKey()
+	if err != nil {
+		return nil, errors.Wrap(err, "converting epk to public key")
+	}
+	apu, err := base64.RawURLEncoding.DecodeString(header.APU)
+	if err != nil {
+		return nil, errors.Wrap(err, "decoding apu")
+	}
+	apv, err := base64.RawURLEncoding.DecodeString(header.APV)
+	if err != nil {
+		return nil, errors.Wrap(err, "decoding apv")
+	}
+	kek, err := deriveKeyEncryptionKey(kt, header.Alg, recipientKey, ephemeralKey, apu, apv)
+	if err != nil {
+		return nil, err
+	}
+
+	cek, err := aesKeyUnwrap(kek, encryptedKey)
+	if err != nil {
+		return nil, errors.Wrap(err, "unwrapping content encryption key")
+	}
+	if len(cek) != a256KeySize {
+		return nil, fmt.Errorf("invalid content encryption key size: %d", len(cek))
+	}
+	return cek, nil
+}
+
+// encryptContent encrypts the plaintext with A256GCM, authenticating the additional data, and returning the random
+// initialization vector, ciphertext, and authentication tag
+func encryptContent(cek, plaintext, aad []byte) (iv, ciphertext, tag []byte, err error) {
+	gcm, err := newGCM(cek)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	iv = make([]byte, gcm.NonceSize())
+	if _, err = rand.Read(iv); err != nil {
+		return nil, nil, nil, errors.Wrap(err, "generating initialization vector")
+	}
+	sealed := gcm.Seal(nil, iv, plaintext, aad)
+	ciphertext, tag = sealed[:len(sealed)-gcm.Overhead()], sealed[len(sealed)-gcm.Overhead():]
+	return iv, ciphertext, tag, nil
+}
+
+// decryptContent decrypts the ciphertext with A256GCM, checking the authentication tag over it and the additional data
+func decryptContent(cek, iv, ciphertext, tag, aad []byte) ([]byte, error) {
+	gcm, err := newGCM(cek)
+	if err != nil {
+		return nil, err
+	}
+	if len(iv) != gcm.NonceSize() {
+		return nil, fmt.Errorf("invalid initialization vector size: %d", len(iv))
+	}
+	if len(tag) != gcm.Overhead() {
+		return nil, fmt.Errorf("invalid authentication tag size: %d", len(tag))
+	}
+	sealed := make([]byte, 0, len(ciphertext)+len(tag))
+	sealed = append(append(sealed, ciphertext...), tag...)
+	plaintext, err := gcm.Open(nil, iv, sealed, aad)
+	if err != nil {
+		return nil, errors.Wrap(err, "decrypting JWE")
+	}
+	return plaintext, nil
+}
+
+// checkJWEAlgorithms makes sure the key management and content encryption algorithms are supported
+func checkJWEAlgorithms(alg, enc string) error {
+	if alg != jwa.ECDH_ES_A256KW.String() {
+		return fmt.Errorf("unsupported JWE key management algorithm: %s", alg)
+	}
+	if enc != jwa.A256GCM.String() {
+		return fmt.Errorf("unsupported JWE content encryption algorithm: %s", enc)
+	}
+	return nil
+}
+
+// ecdhKeyTypeForJWK returns the key type of a JWK that can be used for ECDH-ES
+func ecdhKeyTypeForJWK(kty, crv string) (crypto.KeyType, error) {
+	switch {
+	case kty == jwa.EC.String() && crv == jwa.P256.String():
+		return crypto.P256, nil
+	case kty == jwa.EC.String() && crv == jwa.P384.String():
+		return crypto.P384, nil
+	case kty == jwa.EC.String() && crv == jwa.P521.String():
+		return crypto.P521, nil
+	case kty == jwa.OKP.String() && crv == jwa.X25519.String():
+		return crypto.X25519, nil
+	default:
+		return "", fmt.Errorf("unsupported JWE recipient key: kty<%s> crv<%s>", kty, crv)
+	}
+}
+
+// ephemeralPublicKeyJWK converts the ephemeral public key to the JWK placed in the epk header, which only carries the
+// members identifying the key
+func ephemeralPublicKeyJWK(key gocrypto.PublicKey) (*PublicKeyJWK, error) {
+	epkJWK, err := jwk.FromRaw(key)
+	if err != nil {
+		return nil, errors.Wrap(err, "creating epk JWK")
+	}
+	epk, err := JWKToPublicKeyJWK(epkJWK)
+	if err != nil {
+		return nil, errors.Wrap(err, "converting epk JWK")
+	}
+	return &PublicKeyJWK{KTY: epk.KTY, CRV: epk.CRV, X: epk.X, Y: epk.Y}, nil
+}
+
+// deriveKeyEncryptionKey derives the key wrapping key from the ECDH shared secret using Concat KDF, with the alg as the
+// algorithm ID https://www.rfc-editor.org/rfc/rfc7518#section-4.6.2
+func deriveKeyEncryptionKey(kt crypto.KeyType, alg string, privKey gocrypto.PrivateKey, pubKey gocrypto.PublicKey, apu, apv []byte) ([]byte, error) {
+	z, err := crypto.DeriveSharedSecret(kt, privKey, pubKey)
+	if err != nil {
+		return nil, errors.Wrap(err, "deriving shared secret")
+	}
+	kek, err := crypto.ConcatKDF(z, a256KeySize, []byte(alg), apu, apv)
+	if err != nil {
+		return nil, errors.Wrap(err, "deriving key encryption key")
+	}
+	return kek, nil
+}
+
+func newGCM(key []byte) (cipher.AEAD, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, errors.Wrap(err, "creating AES cipher")
+	}
+	gcm, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, errors.Wrap(err, "creating GCM")
+	}
+	return gcm, nil
+}
+
+// aesKeyWrap wraps the key with the key encryption key using AES Key Wrap https://www.rfc-editor.org/rfc/rfc3394#section-2.2.1
+func aesKeyWrap(kek, key []byte) ([]byte, error) {
+	if len(key) < 2*aesKeyWrapBlockSize || len(key)%aesKeyWrapBlockSize != 0 {
+		return nil, fmt.Errorf("invalid key size for AES key wrap: %d", len(key))
+	}
+	block, err := aes.NewCipher(kek)
+	if err != nil {
+		return nil, errors.Wrap(err, "creating AES cipher")
+	}
+
+	n := len(key) / aesKeyWrapBlockSize
+	wrapped := make([]byte, (n+1)*aesKeyWrapBlockSize)
+	copy(wrapped, aesKeyWrapIV)
+	copy(wrapped[aesKeyWrapBlockSize:], key)
+
+	b := make([]byte, aes.BlockSize)
+	for j := 0; j < 6; j++ {
+		for i := 1; i <= n; i++ {
+			copy(b, wrapped[:aesKeyWrapBlockSize])
+			copy(b[aesKeyWrapBlockSize:], wrapped[i*aesKeyWrapBlockSize:(i+1)*aesKeyWrapBlockSize])
+			block.Encrypt(b, b)
+			t := binary.BigEndian.Uint64(b[:aesKeyWrapBlockSize]) ^ uint64(n*j+i)
+			binary.BigEndian.PutUint64(wrapped[:aesKeyWrapBlockSize], t)
+			copy(wrapped[i*aesKeyWrapBlockSize:], b[aesKeyWrapBlockSize:])
+		}
+	}
+	return wrapped, nil
+}
+
+// aesKeyUnwrap unwraps the key with the key encryption key using AES Key Wrap, checking its integrity
+// https://www.rfc-editor.org/rfc/rfc3394#section-2.2.2
+func aesKeyUnwrap(kek, wrapped []byte) ([]byte, error) {
+	if len(wrapped) < 3*aesKeyWrapBlockSize || len(wrapped)%aesKeyWrapBlockSize != 0 {
+		return nil, fmt.Errorf("invalid wrapped key size: %d", len(wrapped))
+	}
+	block, err := aes.NewCipher(kek)
+	if err != nil {
+		return nil, errors.Wrap(err, "creating AES cipher")
+	}
+
+	n := len(wrapped)/aesKeyWrapBlockSize - 1
+	a := make([]byte, aesKeyWrapBlockSize)
+	copy(a, wrapped[:aesKeyWrapBlockSize])
+	key := make([]byte, n*aesKeyWrapBlockSize)
+	copy(key, wrapped[aesKeyWrapBlockSize:])
+
+	b := make([]byte, aes.BlockSize)
+	for j := 5; j >= 0; j-- {
+		for i := n; i >= 1; i-- {
+			t := binary.BigEndian.Uint64(a) ^ uint64(n*j+i)
+			binary.BigEndian.PutUint64(b[:aesKeyWrapBlockSize], t)
+			copy(b[aesKeyWrapBlockSize:], key[(i-1)*aesKeyWrapBlockSize:i*aesKeyWrapBlockSize])
+			block.Decrypt(b, b)
+			copy(a, b[:aesKeyWrapBlockSize])
+			copy(key[(i-1)*aesKeyWrapBlockSize:], b[aesKeyWrapBlockSize:])
+		}
+	}
+	if subtle.ConstantTimeCompare(a, aesKeyWrapIV) != 1 {
+		return nil, errors.New("integrity check failed")
+	}
+	return key, nil
+}
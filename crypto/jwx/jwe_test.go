@@ -0,0 +1,265 @@
+package jwx
+
+import (
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/TBD54566975/ssi-sdk/crypto"
+	"github.com/goccy/go-json"
+	"github.com/lestrrat-go/jwx/v2/jwa"
+	"github.com/lestrrat-go/jwx/v2/jwe"
+	"github.com/lestrrat-go/jwx/v2/jwk"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestJWE(t *testing.T) {
+	plaintext := []byte(`{"credentialSubject":{"id":"did:example:123"}}`)
+	alg, enc := jwa.ECDH_ES_A256KW.String(), jwa.A256GCM.String()
+
+	for _, kt := range []crypto.KeyType{crypto.P256, crypto.P384, crypto.P521, crypto.X25519} {
+		t.Run(kt.String(), func(tt *testing.T) {
+			privKey, pubJWK, privJWK := getJWERecipient(tt, kt)
+
+			token, err := EncryptJWE(*pubJWK, plaintext, alg, enc)
+			require.NoError(tt, err)
+			parts := strings.Split(token, ".")
+			require.Len(tt, parts, 5)
+
+			headerBytes, err := base64.RawURLEncoding.DecodeString(parts[0])
+			require.NoError(tt, err)
+			var header jweHeader
+			require.NoError(tt, json.Unmarshal(headerBytes, &header))
+			assert.Equal(tt, alg, header.Alg)
+			assert.Equal(tt, enc, header.Enc)
+			require.NotEmpty(tt, header.EPK)
+			assert.Equal(tt, pubJWK.KTY, header.EPK.KTY)
+			assert.Equal(tt, pubJWK.CRV, header.EPK.CRV)
+			assert.NotEqual(tt, pubJWK.X, header.EPK.X)
+
+			decrypted, err := DecryptJWE(*privJWK, token)
+			assert.NoError(tt, err)
+			assert.Equal(tt, plaintext, decrypted)
+
+			// interoperates with lestrrat in both directions
+			decrypted, err = jwe.Decrypt([]byte(token), jwe.WithKey(jwa.ECDH_ES_A256KW, privKey))
+			assert.NoError(tt, err)
+			assert.Equal(tt, plaintext, decrypted)
+
+			recipientKey, err := JWKFromPublicKeyJWK(*pubJWK)
+			require.NoError(tt, err)
+			encrypted, err := jwe.Encrypt(plaintext, jwe.WithKey(jwa.ECDH_ES_A256KW, recipientKey), jwe.WithContentEncryption(jwa.A256GCM))
+			require.NoError(tt, err)
+			decrypted, err = DecryptJWE(*privJWK, string(encrypted))
+			assert.NoError(tt, err)
+			assert.Equal(tt, plaintext, decrypted)
+
+			// each encryption uses a fresh ephemeral key
+			again, err := EncryptJWE(*pubJWK, plaintext, alg, enc)
+			assert.NoError(tt, err)
+			assert.NotEqual(tt, token, again)
+		})
+	}
+
+	t.Run("tampered", func(tt *testing.T) {
+		_, pubJWK, privJWK := getJWERecipient(tt, crypto.P256)
+		token, err := EncryptJWE(*pubJWK, plaintext, alg, enc)
+		require.NoError(tt, err)
+
+		for i, name := range []string{"header", "encrypted key", "iv", "ciphertext", "tag"} {
+			parts := strings.Split(token, ".")
+			decoded, err := base64.RawURLEncoding.DecodeString(parts[i])
+			require.NoError(tt, err)
+			if i == 0 {
+				decoded = []byte(strings.Replace(string(decoded), `"enc"`, ` "enc"`, 1))
+			} else {
+				decoded[0] ^= 0x01
+			}
+			parts[i] = base64.RawURLEncoding.EncodeToString(decoded)
+
+			_, err = DecryptJWE(*privJWK, strings.Join(parts, "."))
+			assert.Error(tt, err, name)
+		}
+	})
+
+	t.Run("wrong recipient", func(tt *testing.T) {
+		_, pubJWK, _ := getJWERecipient(tt, crypto.X25519)
+		_, _, otherPrivJWK := getJWERecipient(tt, crypto.X25519)
+		token, err := EncryptJWE(*pubJWK, plaintext, alg, enc)
+		require.NoError(tt, err)
+
+		_, err = DecryptJWE(*otherPrivJWK, token)
+		assert.Error(tt, err)
+
+		_, _, p256PrivJWK := getJWERecipient(tt, crypto.P256)
+		_, err = DecryptJWE(*p256PrivJWK, token)
+		assert.ErrorContains(tt, err, "does not match recipient key")
+	})
+
+	t.Run("unsupported", func(tt *testing.T) {
+		_, pubJWK, _ := getJWERecipient(tt, crypto.P256)
+		_, err := EncryptJWE(*pubJWK, plaintext, jwa.ECDH_ES.String(), enc)
+		assert.ErrorContains(tt, err, "unsupported JWE key management algorithm")
+		_, err = EncryptJWE(*pubJWK, plaintext, alg, jwa.A128CBC_HS256.String())
+		assert.ErrorContains(tt, err, "unsupported JWE content encryption algorithm")
+
+		edPubKey, _, err := crypto.GenerateEd25519Key()
+		require.NoError(tt, err)
+		edJWK, err := PublicKeyToPublicKeyJWK(edPubKey)
+		require.NoError(tt, err)
+		_, err = EncryptJWE(*edJWK, plaintext, alg, enc)
+		assert.ErrorContains(tt, err, "unsupported JWE recipient key")
+
+		_, err = DecryptJWE(PrivateKeyJWK{}, "not.a.jwe")
+		assert.ErrorContains(tt, err, "expected 5 parts")
+	})
+}
+
+func TestJWEMulti(t *testing.T) {
+	plaintext := []byte(`{"credentialSubject":{"id":"did:example:123"}}`)
+	enc := jwa.A256GCM.String()
+
+	type recipient struct {
+		privKey any
+		pubJWK  *PublicKeyJWK
+		privJWK *PrivateKeyJWK
+	}
+	var recipients []recipient
+	var pubJWKs []PublicKeyJWK
+	for i, kt := range []crypto.KeyType{crypto.P256, crypto.X25519, crypto.P384} {
+		privKey, pubJWK, privJWK := getJWERecipient(t, kt)
+		pubJWK.KID = fmt.Sprintf("key-%d", i)
+		privJWK.KID = pubJWK.KID
+		recipients = append(recipients, recipient{privKey: privKey, pubJWK: pubJWK, privJWK: privJWK})
+		pubJWKs = append(pubJWKs, *pubJWK)
+	}
+
+	encrypted, err := EncryptJWEMulti(pubJWKs, plaintext, enc)
+	require.NoError(t, err)
+
+	var general generalJWE
+	require.NoError(t, json.Unmarshal(encrypted, &general))
+	require.Len(t, general.Recipients, 3)
+	for i, r := range general.Recipients {
+		require.NotEmpty(t, r.Header)
+		assert.Equal(t, jwa.ECDH_ES_A256KW.String(), r.Header.Alg)
+		assert.Equal(t, pubJWKs[i].KID, r.Header.KID)
+		require.NotEmpty(t, r.Header.EPK)
+		assert.Equal(t, pubJWKs[i].CRV, r.Header.EPK.CRV)
+		assert.NotEmpty(t, r.EncryptedKey)
+	}
+
+	t.Run("each recipient decrypts", func(tt *testing.T) {
+		for _, r := range recipients {
+			decrypted, err := DecryptJWEMulti(*r.privJWK, encrypted)
+			assert.NoError(tt, err)
+			assert.Equal(tt, plaintext, decrypted)
+
+			// without a kid the recipient is found by trial unwrap
+			withoutKID := *r.privJWK
+			withoutKID.KID = ""
+			decrypted, err = DecryptJWEMulti(withoutKID, encrypted)
+			assert.NoError(tt, err)
+			assert.Equal(tt, plaintext, decrypted)
+
+			decrypted, err = jwe.Decrypt(encrypted, jwe.WithKey(jwa.ECDH_ES_A256KW, r.privKey))
+			assert.NoError(tt, err)
+			assert.Equal(tt, plaintext, decrypted)
+		}
+	})
+
+	t.Run("trial unwrap with a kid that does not match", func(tt *testing.T) {
+		otherKID := *recipients[2].privJWK
+		otherKID.KID = "unknown"
+		decrypted, err := DecryptJWEMulti(otherKID, encrypted)
+		assert.NoError(tt, err)
+		assert.Equal(tt, plaintext, decrypted)
+	})
+
+	t.Run("decrypts lestrrat multi-recipient JWE", func(tt *testing.T) {
+		var opts []jwe.EncryptOption
+		for _, pubJWK := range pubJWKs {
+			key, err := JWKFromPublicKeyJWK(pubJWK)
+			require.NoError(tt, err)
+			opts = append(opts, jwe.WithKey(jwa.ECDH_ES_A256KW, key))
+		}
+		opts = append(opts, jwe.WithContentEncryption(jwa.A256GCM), jwe.WithJSON())
+		lestrratEncrypted, err := jwe.Encrypt(plaintext, opts...)
+		require.NoError(tt, err)
+
+		for _, r := range recipients {
+			decrypted, err := DecryptJWEMulti(*r.privJWK, lestrratEncrypted)
+			assert.NoError(tt, err)
+			assert.Equal(tt, plaintext, decrypted)
+		}
+	})
+
+	t.Run("not a recipient", func(tt *testing.T) {
+		_, _, otherP256 := getJWERecipient(tt, crypto.P256)
+		_, err := DecryptJWEMulti(*otherP256, encrypted)
+		assert.ErrorContains(tt, err, "no recipient could be decrypted")
+
+		_, _, otherP521 := getJWERecipient(tt, crypto.P521)
+		_, err = DecryptJWEMulti(*otherP521, encrypted)
+		assert.ErrorContains(tt, err, "no recipient with a key of type")
+	})
+
+	t.Run("tampered ciphertext", func(tt *testing.T) {
+		tampered := general
+		ciphertext, err := base64.RawURLEncoding.DecodeString(tampered.Ciphertext)
+		require.NoError(tt, err)
+		ciphertext[0] ^= 0x01
+		tampered.Ciphertext = base64.RawURLEncoding.EncodeToString(ciphertext)
+		tamperedBytes, err := json.Marshal(tampered)
+		require.NoError(tt, err)
+
+		_, err = DecryptJWEMulti(*recipients[0].privJWK, tamperedBytes)
+		assert.ErrorContains(tt, err, "decrypting JWE")
+	})
+
+	t.Run("invalid input", func(tt *testing.T) {
+		_, err := EncryptJWEMulti(nil, plaintext, enc)
+		assert.ErrorContains(tt, err, "at least one recipient is required")
+		_, err = EncryptJWEMulti(pubJWKs, plaintext, jwa.A128GCM.String())
+		assert.ErrorContains(tt, err, "unsupported JWE content encryption algorithm")
+		_, err = DecryptJWEMulti(*recipients[0].privJWK, []byte(`{"recipients":[]}`))
+		assert.ErrorContains(tt, err, "JWE has no recipients")
+	})
+}
+
+// https://www.rfc-editor.org/rfc/rfc3394#section-4.6
+func TestAESKeyWrap(t *testing.T) {
+	kek, err := hex.DecodeString("000102030405060708090A0B0C0D0E0F101112131415161718191A1B1C1D1E1F")
+	require.NoError(t, err)
+	key, err := hex.DecodeString("00112233445566778899AABBCCDDEEFF000102030405060708090A0B0C0D0E0F")
+	require.NoError(t, err)
+	expected, err := hex.DecodeString("28C9F404C4B810F4CBCCB35CFB87F8263F5786E2D80ED326CBC7F0E71A99F43BFB988B9B7A02DD21")
+	require.NoError(t, err)
+
+	wrapped, err := aesKeyWrap(kek, key)
+	assert.NoError(t, err)
+	assert.Equal(t, expected, wrapped)
+
+	unwrapped, err := aesKeyUnwrap(kek, wrapped)
+	assert.NoError(t, err)
+	assert.Equal(t, key, unwrapped)
+
+	wrapped[len(wrapped)-1] ^= 0x01
+	_, err = aesKeyUnwrap(kek, wrapped)
+	assert.ErrorContains(t, err, "integrity check failed")
+}
+
+// getJWERecipient generates a recipient key, returning it with its public and private JWKs
+func getJWERecipient(t *testing.T, kt crypto.KeyType) (any, *PublicKeyJWK, *PrivateKeyJWK) {
+	_, privKey, err := crypto.GenerateKeyByKeyType(kt)
+	require.NoError(t, err)
+	key, err := jwk.FromRaw(privKey)
+	require.NoError(t, err)
+	privJWK, err := JWKToPrivateKeyJWK(key)
+	require.NoError(t, err)
+	pubJWK := privJWK.ToPublicKeyJWK()
+	return privKey, &pubJWK, privJWK
+}
@@ -14,8 +14,10 @@ import (
 	"github.com/cloudflare/circl/sign/dilithium/mode2"
 	"github.com/cloudflare/circl/sign/dilithium/mode3"
 	"github.com/cloudflare/circl/sign/dilithium/mode5"
+	"github.com/cloudflare/circl/sign/ed448"
 	"github.com/decred/dcrd/dcrec/secp256k1/v4"
 	"github.com/goccy/go-json"
+	bbsg2 "github.com/hyperledger/aries-framework-go/pkg/crypto/primitive/bbs12381g2pub"
 	"github.com/lestrrat-go/jwx/v2/jwa"
 	"github.com/lestrrat-go/jwx/v2/jwk"
 	"github.com/lestrrat-go/jwx/v2/x25519"
@@ -24,22 +26,22 @@ import (
 
 // PrivateKeyJWK complies with RFC7517 https://datatracker.ietf.org/doc/html/rfc7517
 type PrivateKeyJWK struct {
-	KTY    string `json:"kty,omitempty" validate:"required"`
-	CRV    string `json:"crv,omitempty"`
-	X      string `json:"x,omitempty"`
-	Y      string `json:"y,omitempty"`
-	N      string `json:"n,omitempty"`
-	E      string `json:"e,omitempty"`
-	Use    string `json:"use,omitempty"`
-	KeyOps string `json:"key_ops,omitempty"`
-	Alg    string `json:"alg,omitempty"`
-	KID    string `json:"kid,omitempty"`
-	D      string `json:"d,omitempty"`
-	DP     string `json:"dp,omitempty"`
-	DQ     string `json:"dq,omitempty"`
-	P      string `json:"p,omitempty"`
-	Q      string `json:"q,omitempty"`
-	QI     string `json:"qi,omitempty"`
+	KTY    string   `json:"kty,omitempty" validate:"required"`
+	CRV    string   `json:"crv,omitempty"`
+	X      string   `json:"x,omitempty"`
+	Y      string   `json:"y,omitempty"`
+	N      string   `json:"n,omitempty"`
+	E      string   `json:"e,omitempty"`
+	Use    string   `json:"use,omitempty"`
+	KeyOps []string `json:"key_ops,omitempty"`
+	Alg    string   `json:"alg,omitempty"`
+	KID    string   `json:"kid,omitempty"`
+	D      string   `json:"d,omitempty"`
+	DP     string   `json:"dp,omitempty"`
+	DQ     string   `json:"dq,omitempty"`
+	P      string   `json:"p,omitempty"`
+	Q      string   `json:"q,omitempty"`
+	QI     string   `json:"qi,omitempty"`
 }
 
 // ToPublicKeyJWK converts a PrivateKeyJWK to a PublicKeyJWK
@@ -63,6 +65,14 @@ func (k PrivateKeyJWK) ToPrivateKey() (gocrypto.PrivateKey, error) {
 	if k.KTY == DilithiumKTY {
 		return k.toDilithiumPrivateKey()
 	}
+	// handle BLS12-381 separately since it's not supported by our jwx library
+	if isBLS12381G2JWK(k.KTY, k.CRV) {
+		return k.toBLS12381G2PrivateKey()
+	}
+	// handle Ed448 separately since it's not supported by our jwx library
+	if isEd448JWK(k.KTY, k.CRV) {
+		return k.toEd448PrivateKey()
+	}
 	gotJWK, err := JWKFromPrivateKeyJWK(k)
 	if err != nil {
 		return nil, errors.Wrap(err, "creating JWK from private key")
@@ -104,16 +114,16 @@ func (k PrivateKeyJWK) toDilithiumPrivateKey() (gocrypto.PrivateKey, error) {
 
 // PublicKeyJWK complies with RFC7517 https://datatracker.ietf.org/doc/html/rfc7517
 type PublicKeyJWK struct {
-	KTY    string `json:"kty,omitempty" validate:"required"`
-	CRV    string `json:"crv,omitempty"`
-	X      string `json:"x,omitempty"`
-	Y      string `json:"y,omitempty"`
-	N      string `json:"n,omitempty"`
-	E      string `json:"e,omitempty"`
-	Use    string `json:"use,omitempty"`
-	KeyOps string `json:"key_ops,omitempty"`
-	Alg    string `json:"alg,omitempty"`
-	KID    string `json:"kid,omitempty"`
+	KTY    string   `json:"kty,omitempty" validate:"required"`
+	CRV    string   `json:"crv,omitempty"`
+	X      string   `json:"x,omitempty"`
+	Y      string   `json:"y,omitempty"`
+	N      string   `json:"n,omitempty"`
+	E      string   `json:"e,omitempty"`
+	Use    string   `json:"use,omitempty"`
+	KeyOps []string `json:"key_ops,omitempty"`
+	Alg    string   `json:"alg,omitempty"`
+	KID    string   `json:"kid,omitempty"`
 }
 
 func (k PublicKeyJWK) ToPublicKey() (gocrypto.PublicKey, error) {
@@ -121,6 +131,14 @@ func (k PublicKeyJWK) ToPublicKey() (gocrypto.PublicKey, error) {
 	if k.KTY == DilithiumKTY {
 		return k.toDilithiumPublicKey()
 	}
+	// handle BLS12-381 separately since it's not supported by our jwx library
+	if isBLS12381G2JWK(k.KTY, k.CRV) {
+		return k.toBLS12381G2PublicKey()
+	}
+	// handle Ed448 separately since it's not supported by our jwx library
+	if isEd448JWK(k.KTY, k.CRV) {
+		return k.toEd448PublicKey()
+	}
 	gotJWK, err := JWKFromPublicKeyJWK(k)
 	if err != nil {
 		return nil, errors.Wrap(err, "creating JWK from public key")
@@ -156,6 +174,46 @@ func (k PublicKeyJWK) toDilithiumPublicKey() (gocrypto.PublicKey, error) {
 	}
 }
 
+// Thumbprint computes the base64url encoded SHA-256 RFC 7638 thumbprint of the JWK
+func (k PublicKeyJWK) Thumbprint() (string, error) {
+	return k.ThumbprintWithHash(gocrypto.SHA256)
+}
+
+// ThumbprintWithHash computes the base64url encoded RFC 7638 thumbprint of the JWK with the given hash, over the
+// lexicographically ordered required members of its key type
+// https://www.rfc-editor.org/rfc/rfc7638#section-3.2
+func (k PublicKeyJWK) ThumbprintWithHash(h gocrypto.Hash) (string, error) {
+	if !h.Available() {
+		return "", fmt.Errorf("hash not available: %s", h)
+	}
+	var members map[string]string
+	switch k.KTY {
+	case jwa.EC.String():
+		members = map[string]string{"crv": k.CRV, "kty": k.KTY, "x": k.X, "y": k.Y}
+	case jwa.RSA.String():
+		members = map[string]string{"e": k.E, "kty": k.KTY, "n": k.N}
+	case jwa.OKP.String():
+		// https://www.rfc-editor.org/rfc/rfc8037#section-2
+		members = map[string]string{"crv": k.CRV, "kty": k.KTY, "x": k.X}
+	default:
+		return "", fmt.Errorf("unsupported kty for thumbprint: %s", k.KTY)
+	}
+	for member, value := range members {
+		if value == "" {
+			return "", fmt.Errorf("missing required member for thumbprint: %s", member)
+		}
+	}
+
+	// maps are marshaled with their keys sorted and without whitespace, which is the canonical form
+	canonical, err := json.Marshal(members)
+	if err != nil {
+		return "", errors.Wrap(err, "marshalling thumbprint members")
+	}
+	hash := h.New()
+	hash.Write(canonical)
+	return base64.RawURLEncoding.EncodeToString(hash.Sum(nil)), nil
+}
+
 // JWKToPrivateKeyJWK converts a JWK to a PrivateKeyJWK
 func JWKToPrivateKeyJWK(key jwk.Key) (*PrivateKeyJWK, error) {
 	keyBytes, err := json.Marshal(key)
@@ -211,17 +269,84 @@ func PublicKeyToJWK(key gocrypto.PublicKey) (jwk.Key, error) {
 		return jwkKeyFromRSAPublicKey(k)
 	case ed25519.PublicKey:
 		return jwkKeyFromEd25519PublicKey(k)
+	case ed448.PublicKey:
+		return jwkKeyFromEd448PublicKey(k)
 	case x25519.PublicKey:
 		return jwkKeyFromX25519PublicKey(k)
 	case secp256k1.PublicKey:
 		return jwkKeyFromSECP256k1PublicKey(k)
 	case ecdsa.PublicKey:
 		return jwkKeyFromECDSAPublicKey(k)
+	case bbsg2.PublicKey:
+		return jwkKeyFromBLS12381G2PublicKey(k)
 	default:
 		return nil, fmt.Errorf("unsupported public key type: %T", k)
 	}
 }
 
+// JWK use values https://www.rfc-editor.org/rfc/rfc7517#section-4.2
+const (
+	JWKUseSignature  = "sig"
+	JWKUseEncryption = "enc"
+)
+
+// PublicKeyToJWKWithUse converts a public key to a JWK intended for the given use, setting the use member along with
+// the alg the key is to be used with: the signing algorithm of the key for JWKUseSignature, and ECDH-ES for
+// JWKUseEncryption. X25519 keys can only be used for key agreement, so their use defaults to JWKUseEncryption and
+// their JWK carries the X25519 curve. The key_ops member is not set, since RFC7517 discourages using it alongside use.
+func PublicKeyToJWKWithUse(key gocrypto.PublicKey, use string) (jwk.Key, error) {
+	// dereference the ptr
+	if reflect.ValueOf(key).Kind() == reflect.Ptr {
+		key = reflect.ValueOf(key).Elem().Interface().(gocrypto.PublicKey)
+	}
+
+	var jwkKey jwk.Key
+	var err error
+	if x25519Key, ok := key.(x25519.PublicKey); ok {
+		if use == "" {
+			use = JWKUseEncryption
+		}
+		jwkKey, err = jwk.FromRaw(x25519Key)
+		if err != nil {
+			return nil, errors.Wrap(err, "generating x25519 jwk")
+		}
+	} else {
+		jwkKey, err = PublicKeyToJWK(key)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	crv, err := GetCRVFromJWK(jwkKey)
+	if err != nil {
+		return nil, err
+	}
+	var alg string
+	switch use {
+	case JWKUseSignature:
+		sigAlg, err := AlgFromKeyAndCurve(jwkKey.KeyType(), jwa.EllipticCurveAlgorithm(crv))
+		if err != nil {
+			return nil, errors.Wrapf(err, "key cannot be used for %s", use)
+		}
+		alg = sigAlg.String()
+	case JWKUseEncryption:
+		if _, err = ecdhKeyTypeForJWK(jwkKey.KeyType().String(), crv); err != nil {
+			return nil, errors.Wrapf(err, "key cannot be used for %s", use)
+		}
+		alg = jwa.ECDH_ES.String()
+	default:
+		return nil, fmt.Errorf("unsupported jwk use<%s>, must be %s or %s", use, JWKUseSignature, JWKUseEncryption)
+	}
+
+	if err = jwkKey.Set(jwk.KeyUsageKey, use); err != nil {
+		return nil, errors.Wrap(err, "setting jwk use")
+	}
+	if err = jwkKey.Set(jwk.AlgorithmKey, alg); err != nil {
+		return nil, errors.Wrap(err, "setting jwk alg")
+	}
+	return jwkKey, nil
+}
+
 // PublicKeyToPublicKeyJWK converts a public key to a PublicKeyJWK
 func PublicKeyToPublicKeyJWK(key gocrypto.PublicKey) (*PublicKeyJWK, error) {
 	// dereference the ptr, which could be a nested ptr
@@ -233,6 +358,8 @@ func PublicKeyToPublicKeyJWK(key gocrypto.PublicKey) (*PublicKeyJWK, error) {
 		return jwkFromRSAPublicKey(k)
 	case ed25519.PublicKey:
 		return jwkFromEd25519PublicKey(k)
+	case ed448.PublicKey:
+		return jwkFromEd448PublicKey(k)
 	case x25519.PublicKey:
 		return jwkFromX25519PublicKey(k)
 	case secp256k1.PublicKey:
@@ -248,6 +375,8 @@ func PublicKeyToPublicKeyJWK(key gocrypto.PublicKey) (*PublicKeyJWK, error) {
 	case mode5.PublicKey:
 		pubKey := dilithium.Mode5.PublicKeyFromBytes(k.Bytes())
 		return jwkFromDilithiumPublicKey(crypto.Dilithium5, pubKey)
+	case bbsg2.PublicKey:
+		return jwkFromBLS12381G2PublicKey(k)
 	default:
 		return nil, fmt.Errorf("unsupported public key type: %T", k)
 	}
@@ -264,12 +393,16 @@ func PrivateKeyToJWK(key gocrypto.PrivateKey) (jwk.Key, error) {
 		return jwkKeyFromRSAPrivateKey(k)
 	case ed25519.PrivateKey:
 		return jwkKeyFromEd25519PrivateKey(k)
+	case ed448.PrivateKey:
+		return jwkKeyFromEd448PrivateKey(k)
 	case x25519.PrivateKey:
 		return jwkKeyFromX25519PrivateKey(k)
 	case secp256k1.PrivateKey:
 		return jwkKeyFromSECP256k1PrivateKey(k)
 	case ecdsa.PrivateKey:
 		return jwkKeyFromECDSAPrivateKey(k)
+	case bbsg2.PrivateKey:
+		return jwkKeyFromBLS12381G2PrivateKey(k)
 	default:
 		return nil, fmt.Errorf("unsupported private key type: %T", k)
 	}
@@ -286,6 +419,8 @@ func PrivateKeyToPrivateKeyJWK(key gocrypto.PrivateKey) (*PublicKeyJWK, *Private
 		return jwkFromRSAPrivateKey(k)
 	case ed25519.PrivateKey:
 		return jwkFromEd25519PrivateKey(k)
+	case ed448.PrivateKey:
+		return jwkFromEd448PrivateKey(k)
 	case x25519.PrivateKey:
 		return jwkFromX25519PrivateKey(k)
 	case secp256k1.PrivateKey:
@@ -301,6 +436,8 @@ func PrivateKeyToPrivateKeyJWK(key gocrypto.PrivateKey) (*PublicKeyJWK, *Private
 	case mode5.PrivateKey:
 		privKey := dilithium.Mode5.PrivateKeyFromBytes(k.Bytes())
 		return jwkFromDilithiumPrivateKey(crypto.Dilithium5, privKey)
+	case bbsg2.PrivateKey:
+		return jwkFromBLS12381G2PrivateKey(k)
 	default:
 		return nil, nil, fmt.Errorf("unsupported private key type: %T", k)
 	}
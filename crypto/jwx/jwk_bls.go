@@ -0,0 +1,114 @@
+package jwx
+
+import (
+	"encoding/base64"
+	"fmt"
+
+	"github.com/goccy/go-json"
+	bbsg2 "github.com/hyperledger/aries-framework-go/pkg/crypto/primitive/bbs12381g2pub"
+	"github.com/lestrrat-go/jwx/v2/jwa"
+	"github.com/lestrrat-go/jwx/v2/jwk"
+	"github.com/pkg/errors"
+)
+
+const (
+	// BLS12381G2KTY is the kty of BLS12-381 keys, which are represented as octet key pairs
+	// https://datatracker.ietf.org/doc/html/draft-ietf-cose-bls-key-representations#section-2.2
+	BLS12381G2KTY = jwa.OKP
+	// BLS12381G2CRV is the crv of BLS12-381 G2 keys, whose x is the compressed point
+	BLS12381G2CRV jwa.EllipticCurveAlgorithm = "Bls12381G2"
+)
+
+func init() {
+	// allows our jwx library to parse and serialize BLS12-381 G2 keys, though it cannot sign or verify with them
+	jwa.RegisterEllipticCurveAlgorithm(BLS12381G2CRV)
+}
+
+// isBLS12381G2JWK checks whether the kty and crv identify a BLS12-381 G2 key
+func isBLS12381G2JWK(kty, crv string) bool {
+	return kty == BLS12381G2KTY.String() && crv == BLS12381G2CRV.String()
+}
+
+func (k PublicKeyJWK) toBLS12381G2PublicKey() (bbsg2.PublicKey, error) {
+	if k.X == "" {
+		return bbsg2.PublicKey{}, fmt.Errorf("missing public key X")
+	}
+	decodedPubKey, err := base64.RawURLEncoding.DecodeString(k.X)
+	if err != nil {
+		return bbsg2.PublicKey{}, errors.Wrap(err, "decoding public key")
+	}
+	pubKey, err := bbsg2.UnmarshalPublicKey(decodedPubKey)
+	if err != nil {
+		return bbsg2.PublicKey{}, errors.Wrap(err, "unmarshalling bls12381g2 public key")
+	}
+	return *pubKey, nil
+}
+
+func (k PrivateKeyJWK) toBLS12381G2PrivateKey() (bbsg2.PrivateKey, error) {
+	if k.D == "" {
+		return bbsg2.PrivateKey{}, fmt.Errorf("missing private key D")
+	}
+	decodedPrivKey, err := base64.RawURLEncoding.DecodeString(k.D)
+	if err != nil {
+		return bbsg2.PrivateKey{}, errors.Wrap(err, "decoding private key")
+	}
+	privKey, err := bbsg2.UnmarshalPrivateKey(decodedPrivKey)
+	if err != nil {
+		return bbsg2.PrivateKey{}, errors.Wrap(err, "unmarshalling bls12381g2 private key")
+	}
+	return *privKey, nil
+}
+
+// jwkKeyFromBLS12381G2PublicKey converts a BLS12-381 G2 public key to a JWK
+func jwkKeyFromBLS12381G2PublicKey(key bbsg2.PublicKey) (jwk.Key, error) {
+	pubKeyJWK, err := jwkFromBLS12381G2PublicKey(key)
+	if err != nil {
+		return nil, err
+	}
+	return JWKFromPublicKeyJWK(*pubKeyJWK)
+}
+
+// jwkFromBLS12381G2PublicKey converts a BLS12-381 G2 public key to a PublicKeyJWK
+func jwkFromBLS12381G2PublicKey(key bbsg2.PublicKey) (*PublicKeyJWK, error) {
+	pubKeyBytes, err := key.Marshal()
+	if err != nil {
+		return nil, errors.Wrap(err, "marshalling bls12381g2 public key")
+	}
+	return &PublicKeyJWK{
+		KTY: BLS12381G2KTY.String(),
+		CRV: BLS12381G2CRV.String(),
+		X:   base64.RawURLEncoding.EncodeToString(pubKeyBytes),
+	}, nil
+}
+
+// jwkKeyFromBLS12381G2PrivateKey converts a BLS12-381 G2 private key to a JWK
+func jwkKeyFromBLS12381G2PrivateKey(key bbsg2.PrivateKey) (jwk.Key, error) {
+	_, privKeyJWK, err := jwkFromBLS12381G2PrivateKey(key)
+	if err != nil {
+		return nil, err
+	}
+	privKeyJWKBytes, err := json.Marshal(privKeyJWK)
+	if err != nil {
+		return nil, errors.Wrap(err, "marshalling bls12381g2 private jwk")
+	}
+	return jwk.ParseKey(privKeyJWKBytes)
+}
+
+// jwkFromBLS12381G2PrivateKey converts a BLS12-381 G2 private key to a PublicKeyJWK and PrivateKeyJWK
+func jwkFromBLS12381G2PrivateKey(key bbsg2.PrivateKey) (*PublicKeyJWK, *PrivateKeyJWK, error) {
+	privKeyBytes, err := key.Marshal()
+	if err != nil {
+		return nil, nil, errors.Wrap(err, "marshalling bls12381g2 private key")
+	}
+	pubKeyJWK, err := jwkFromBLS12381G2PublicKey(*key.PublicKey())
+	if err != nil {
+		return nil, nil, err
+	}
+	privKeyJWK := PrivateKeyJWK{
+		KTY: pubKeyJWK.KTY,
+		CRV: pubKeyJWK.CRV,
+		X:   pubKeyJWK.X,
+		D:   base64.RawURLEncoding.EncodeToString(privKeyBytes),
+	}
+	return pubKeyJWK, &privKeyJWK, nil
+}
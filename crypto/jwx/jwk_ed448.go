@@ -0,0 +1,99 @@
+package jwx
+
+import (
+	"encoding/base64"
+	"fmt"
+
+	"github.com/cloudflare/circl/sign/ed448"
+	"github.com/goccy/go-json"
+	"github.com/lestrrat-go/jwx/v2/jwa"
+	"github.com/lestrrat-go/jwx/v2/jwk"
+	"github.com/pkg/errors"
+)
+
+// isEd448JWK checks whether the kty and crv identify an Ed448 key
+// https://www.rfc-editor.org/rfc/rfc8037#section-2
+func isEd448JWK(kty, crv string) bool {
+	return kty == jwa.OKP.String() && crv == jwa.Ed448.String()
+}
+
+func (k PublicKeyJWK) toEd448PublicKey() (ed448.PublicKey, error) {
+	if k.X == "" {
+		return nil, fmt.Errorf("missing public key X")
+	}
+	decodedPubKey, err := base64.RawURLEncoding.DecodeString(k.X)
+	if err != nil {
+		return nil, errors.Wrap(err, "decoding public key")
+	}
+	if len(decodedPubKey) != ed448.PublicKeySize {
+		return nil, fmt.Errorf("invalid ed448 public key size: %d", len(decodedPubKey))
+	}
+	return decodedPubKey, nil
+}
+
+// toEd448PrivateKey reconstructs the private key from its seed, which is the d of the JWK
+func (k PrivateKeyJWK) toEd448PrivateKey() (ed448.PrivateKey, error) {
+	if k.D == "" {
+		return nil, fmt.Errorf("missing private key D")
+	}
+	seed, err := base64.RawURLEncoding.DecodeString(k.D)
+	if err != nil {
+		return nil, errors.Wrap(err, "decoding private key")
+	}
+	if len(seed) != ed448.SeedSize {
+		return nil, fmt.Errorf("invalid ed448 private key seed size: %d", len(seed))
+	}
+	return ed448.NewKeyFromSeed(seed), nil
+}
+
+// jwkKeyFromEd448PublicKey converts an Ed448 public key to a JWK
+func jwkKeyFromEd448PublicKey(key ed448.PublicKey) (jwk.Key, error) {
+	pubKeyJWK, err := jwkFromEd448PublicKey(key)
+	if err != nil {
+		return nil, err
+	}
+	return JWKFromPublicKeyJWK(*pubKeyJWK)
+}
+
+// jwkFromEd448PublicKey converts an Ed448 public key to a PublicKeyJWK
+func jwkFromEd448PublicKey(key ed448.PublicKey) (*PublicKeyJWK, error) {
+	if len(key) != ed448.PublicKeySize {
+		return nil, fmt.Errorf("invalid ed448 public key size: %d", len(key))
+	}
+	return &PublicKeyJWK{
+		KTY: jwa.OKP.String(),
+		CRV: jwa.Ed448.String(),
+		X:   base64.RawURLEncoding.EncodeToString(key),
+	}, nil
+}
+
+// jwkKeyFromEd448PrivateKey converts an Ed448 private key to a JWK
+func jwkKeyFromEd448PrivateKey(key ed448.PrivateKey) (jwk.Key, error) {
+	_, privKeyJWK, err := jwkFromEd448PrivateKey(key)
+	if err != nil {
+		return nil, err
+	}
+	privKeyJWKBytes, err := json.Marshal(privKeyJWK)
+	if err != nil {
+		return nil, errors.Wrap(err, "marshalling ed448 private jwk")
+	}
+	return jwk.ParseKey(privKeyJWKBytes)
+}
+
+// jwkFromEd448PrivateKey converts an Ed448 private key to a PublicKeyJWK and PrivateKeyJWK
+func jwkFromEd448PrivateKey(key ed448.PrivateKey) (*PublicKeyJWK, *PrivateKeyJWK, error) {
+	if len(key) != ed448.PrivateKeySize {
+		return nil, nil, fmt.Errorf("invalid ed448 private key size: %d", len(key))
+	}
+	pubKeyJWK, err := jwkFromEd448PublicKey(key.Public().(ed448.PublicKey))
+	if err != nil {
+		return nil, nil, err
+	}
+	privKeyJWK := PrivateKeyJWK{
+		KTY: pubKeyJWK.KTY,
+		CRV: pubKeyJWK.CRV,
+		X:   pubKeyJWK.X,
+		D:   base64.RawURLEncoding.EncodeToString(key.Seed()),
+	}
+	return pubKeyJWK, &privKeyJWK, nil
+}
@@ -1,6 +1,8 @@
 package jwx
 
 import (
+	gocrypto "crypto"
+	"encoding/base64"
 	"testing"
 
 	"github.com/TBD54566975/ssi-sdk/crypto"
@@ -328,6 +330,42 @@ func TestPublicKeyToJWK(t *testing.T) {
 		assert.Equal(tt, jwa.EC, jwk2.KeyType())
 	})
 
+	t.Run("Ed448", func(tt *testing.T) {
+		pubKey, _, err := crypto.GenerateEd448Key()
+		assert.NoError(t, err)
+
+		jwk, err := PublicKeyToJWK(pubKey)
+		assert.NoError(tt, err)
+		assert.NotEmpty(tt, jwk)
+		assert.Equal(tt, jwa.OKP, jwk.KeyType())
+		crv, err := GetCRVFromJWK(jwk)
+		assert.NoError(tt, err)
+		assert.Equal(tt, jwa.Ed448.String(), crv)
+
+		jwk2, err := PublicKeyToJWK(&pubKey)
+		assert.NoError(tt, err)
+		assert.NotEmpty(tt, jwk2)
+		assert.Equal(tt, jwa.OKP, jwk2.KeyType())
+	})
+
+	t.Run("BLS12381G2", func(tt *testing.T) {
+		pubKey, _, err := crypto.GenerateBLS12381G2Key()
+		assert.NoError(t, err)
+
+		jwk, err := PublicKeyToJWK(pubKey)
+		assert.NoError(tt, err)
+		assert.NotEmpty(tt, jwk)
+		assert.Equal(tt, jwa.OKP, jwk.KeyType())
+		crv, err := GetCRVFromJWK(jwk)
+		assert.NoError(tt, err)
+		assert.Equal(tt, BLS12381G2CRV.String(), crv)
+
+		jwk2, err := PublicKeyToJWK(&pubKey)
+		assert.NoError(tt, err)
+		assert.NotEmpty(tt, jwk2)
+		assert.Equal(tt, jwa.OKP, jwk2.KeyType())
+	})
+
 	t.Run("unsupported", func(tt *testing.T) {
 		jwk, err := PublicKeyToJWK(nil)
 		assert.Error(tt, err)
@@ -335,6 +373,90 @@ func TestPublicKeyToJWK(t *testing.T) {
 	})
 }
 
+func TestPublicKeyToJWKWithUse(t *testing.T) {
+	tests := []struct {
+		keyType crypto.KeyType
+		use     string
+		alg     jwa.KeyAlgorithm
+	}{
+		{keyType: crypto.Ed25519, use: JWKUseSignature, alg: jwa.EdDSA},
+		{keyType: crypto.SECP256k1, use: JWKUseSignature, alg: jwa.ES256K},
+		{keyType: crypto.P256, use: JWKUseSignature, alg: jwa.ES256},
+		{keyType: crypto.P256, use: JWKUseEncryption, alg: jwa.ECDH_ES},
+		{keyType: crypto.P384, use: JWKUseSignature, alg: jwa.ES384},
+		{keyType: crypto.P384, use: JWKUseEncryption, alg: jwa.ECDH_ES},
+		{keyType: crypto.P521, use: JWKUseSignature, alg: jwa.ES512},
+		{keyType: crypto.P521, use: JWKUseEncryption, alg: jwa.ECDH_ES},
+		{keyType: crypto.RSA, use: JWKUseSignature, alg: jwa.PS256},
+		{keyType: crypto.X25519, use: JWKUseEncryption, alg: jwa.ECDH_ES},
+	}
+	for _, test := range tests {
+		t.Run(string(test.keyType)+" "+test.use, func(tt *testing.T) {
+			pubKey, _, err := crypto.GenerateKeyByKeyType(test.keyType)
+			assert.NoError(tt, err)
+
+			key, err := PublicKeyToJWKWithUse(pubKey, test.use)
+			assert.NoError(tt, err)
+			assert.Equal(tt, test.use, key.KeyUsage())
+			assert.Equal(tt, test.alg, key.Algorithm())
+			assert.Empty(tt, key.KeyOps())
+
+			// the key material is unchanged
+			gotJWK, err := JWKToPublicKeyJWK(key)
+			assert.NoError(tt, err)
+			expectedJWK, err := PublicKeyToPublicKeyJWK(pubKey)
+			assert.NoError(tt, err)
+			assert.Equal(tt, []string{expectedJWK.X, expectedJWK.Y, expectedJWK.N, expectedJWK.E},
+				[]string{gotJWK.X, gotJWK.Y, gotJWK.N, gotJWK.E})
+		})
+	}
+
+	t.Run("X25519 defaults to encryption", func(tt *testing.T) {
+		pubKey, _, err := crypto.GenerateX25519Key()
+		assert.NoError(tt, err)
+
+		key, err := PublicKeyToJWKWithUse(&pubKey, "")
+		assert.NoError(tt, err)
+		assert.Equal(tt, JWKUseEncryption, key.KeyUsage())
+		assert.Equal(tt, jwa.ECDH_ES, key.Algorithm())
+		crv, err := GetCRVFromJWK(key)
+		assert.NoError(tt, err)
+		assert.Equal(tt, jwa.X25519.String(), crv)
+	})
+
+	t.Run("use not supported by the key", func(tt *testing.T) {
+		x25519PubKey, _, err := crypto.GenerateX25519Key()
+		assert.NoError(tt, err)
+		_, err = PublicKeyToJWKWithUse(x25519PubKey, JWKUseSignature)
+		assert.Error(tt, err)
+		assert.Contains(tt, err.Error(), "key cannot be used for sig")
+
+		ed25519PubKey, _, err := crypto.GenerateEd25519Key()
+		assert.NoError(tt, err)
+		_, err = PublicKeyToJWKWithUse(ed25519PubKey, JWKUseEncryption)
+		assert.Error(tt, err)
+		assert.Contains(tt, err.Error(), "key cannot be used for enc")
+
+		rsaPubKey, _, err := crypto.GenerateRSA2048Key()
+		assert.NoError(tt, err)
+		_, err = PublicKeyToJWKWithUse(rsaPubKey, JWKUseEncryption)
+		assert.Error(tt, err)
+	})
+
+	t.Run("unsupported use", func(tt *testing.T) {
+		pubKey, _, err := crypto.GenerateEd25519Key()
+		assert.NoError(tt, err)
+
+		_, err = PublicKeyToJWKWithUse(pubKey, "")
+		assert.Error(tt, err)
+		assert.Contains(tt, err.Error(), "unsupported jwk use<>")
+
+		_, err = PublicKeyToJWKWithUse(pubKey, "wrap")
+		assert.Error(tt, err)
+		assert.Contains(tt, err.Error(), "unsupported jwk use<wrap>")
+	})
+}
+
 func TestPublicKeyToPublicKeyJWK(t *testing.T) {
 	t.Run("RSA", func(tt *testing.T) {
 		pubKey, _, err := crypto.GenerateRSA2048Key()
@@ -487,6 +609,38 @@ func TestPublicKeyToPublicKeyJWK(t *testing.T) {
 		assert.EqualValues(tt, DilithiumMode5Alg, jwk2.Alg)
 	})
 
+	t.Run("Ed448", func(tt *testing.T) {
+		pubKey, _, err := crypto.GenerateEd448Key()
+		assert.NoError(t, err)
+
+		jwk, err := PublicKeyToPublicKeyJWK(pubKey)
+		assert.NoError(tt, err)
+		assert.Equal(tt, "OKP", jwk.KTY)
+		assert.Equal(tt, "Ed448", jwk.CRV)
+		assert.NotEmpty(tt, jwk.X)
+
+		// convert back
+		gotPubKey, err := jwk.ToPublicKey()
+		assert.NoError(tt, err)
+		assert.Equal(tt, pubKey, gotPubKey)
+	})
+
+	t.Run("BLS12381G2", func(tt *testing.T) {
+		pubKey, _, err := crypto.GenerateBLS12381G2Key()
+		assert.NoError(t, err)
+
+		jwk, err := PublicKeyToPublicKeyJWK(pubKey)
+		assert.NoError(tt, err)
+		assert.Equal(tt, "OKP", jwk.KTY)
+		assert.Equal(tt, "Bls12381G2", jwk.CRV)
+		assert.NotEmpty(tt, jwk.X)
+
+		// convert back
+		gotPubKey, err := jwk.ToPublicKey()
+		assert.NoError(tt, err)
+		assert.Equal(tt, pubKey, gotPubKey)
+	})
+
 	t.Run("unsupported", func(tt *testing.T) {
 		jwk, err := PublicKeyToPublicKeyJWK(nil)
 		assert.Error(tt, err)
@@ -611,6 +765,49 @@ func TestPrivateKeyToPrivateKeyJWK(t *testing.T) {
 		assert.EqualValues(tt, DilithiumMode5Alg, jwk2.Alg)
 	})
 
+	t.Run("Ed448", func(tt *testing.T) {
+		pubKey, privKey, err := crypto.GenerateEd448Key()
+		assert.NoError(t, err)
+
+		pubKeyJWK, privKeyJWK, err := PrivateKeyToPrivateKeyJWK(privKey)
+		assert.NoError(tt, err)
+		assert.Equal(tt, "OKP", privKeyJWK.KTY)
+		assert.Equal(tt, "Ed448", privKeyJWK.CRV)
+		assert.Equal(tt, privKeyJWK.ToPublicKeyJWK(), *pubKeyJWK)
+
+		privJWK, err := PrivateKeyToJWK(privKey)
+		assert.NoError(tt, err)
+		assert.Equal(tt, jwa.OKP, privJWK.KeyType())
+
+		// convert back
+		gotPrivKey, err := privKeyJWK.ToPrivateKey()
+		assert.NoError(tt, err)
+		assert.Equal(tt, privKey, gotPrivKey)
+		gotPubKey, err := pubKeyJWK.ToPublicKey()
+		assert.NoError(tt, err)
+		assert.Equal(tt, pubKey, gotPubKey)
+	})
+
+	t.Run("BLS12381G2", func(tt *testing.T) {
+		pubKey, privKey, err := crypto.GenerateBLS12381G2Key()
+		assert.NoError(t, err)
+
+		pubKeyJWK, privKeyJWK, err := PrivateKeyToPrivateKeyJWK(privKey)
+		assert.NoError(tt, err)
+		assert.Equal(tt, "OKP", privKeyJWK.KTY)
+		assert.Equal(tt, "Bls12381G2", privKeyJWK.CRV)
+		assert.NotEmpty(tt, privKeyJWK.D)
+		assert.Equal(tt, privKeyJWK.ToPublicKeyJWK(), *pubKeyJWK)
+
+		// convert back
+		gotPrivKey, err := privKeyJWK.ToPrivateKey()
+		assert.NoError(tt, err)
+		assert.Equal(tt, privKey, gotPrivKey)
+		gotPubKey, err := pubKeyJWK.ToPublicKey()
+		assert.NoError(tt, err)
+		assert.Equal(tt, pubKey, gotPubKey)
+	})
+
 	t.Run("unsupported", func(tt *testing.T) {
 		_, jwk, err := PrivateKeyToPrivateKeyJWK(nil)
 		assert.Error(tt, err)
@@ -644,3 +841,79 @@ func TestDilithiumVectors(t *testing.T) {
 		assert.NotEmpty(tt, gotPrivKey)
 	})
 }
+
+func TestPublicKeyJWKThumbprint(t *testing.T) {
+	// https://www.rfc-editor.org/rfc/rfc7638#section-3.1
+	t.Run("rfc 7638 example", func(tt *testing.T) {
+		pubKeyJWK := PublicKeyJWK{
+			KTY: "RSA",
+			N:   "0vx7agoebGcQSuuPiLJXZptN9nndrQmbXEps2aiAFbWhM78LhWx4cbbfAAtVT86zwu1RK7aPFFxuhDR1L6tSoc_BJECPebWKRXjBZCiFV4n3oknjhMstn64tZ_2W-5JsGY4Hc5n9yBXArwl93lqt7_RN5w6Cf0h4QyQ5v-65YGjQR0_FDW2QvzqY368QQMicAtaSqzs8KJZgnYb9c7d0zgdAZHzu6qMQvRL5hajrn1n91CbOpbISD08qNLyrdkt-bFTWhAI4vMQFh6WeZu0fM4lFd2NcRwr3XPksINHaQ-G_xBniIqbw0Ls1jF44-csFCur-kEgU8awapJzKnqDKgw",
+			E:   "AQAB",
+			Alg: "RS256",
+			KID: "2011-04-29",
+		}
+		thumbprint, err := pubKeyJWK.Thumbprint()
+		assert.NoError(tt, err)
+		assert.Equal(tt, "NzbLsXh8uDCcd-6MNwXF4W_7noWXFZAfHkxZsRGC9Xs", thumbprint)
+	})
+
+	t.Run("matches jwx library thumbprints", func(tt *testing.T) {
+		for _, kt := range []crypto.KeyType{crypto.Ed25519, crypto.X25519, crypto.SECP256k1, crypto.P256, crypto.P384, crypto.P521, crypto.RSA} {
+			pubKey, _, err := crypto.GenerateKeyByKeyType(kt)
+			assert.NoError(tt, err)
+			key, err := PublicKeyToJWK(pubKey)
+			assert.NoError(tt, err)
+			pubKeyJWK, err := JWKToPublicKeyJWK(key)
+			assert.NoError(tt, err)
+
+			for _, h := range []gocrypto.Hash{gocrypto.SHA256, gocrypto.SHA512} {
+				expected, err := key.Thumbprint(h)
+				assert.NoError(tt, err)
+				thumbprint, err := pubKeyJWK.ThumbprintWithHash(h)
+				assert.NoError(tt, err)
+				assert.Equal(tt, base64.RawURLEncoding.EncodeToString(expected), thumbprint, kt)
+			}
+		}
+	})
+
+	t.Run("keys without jwx library support", func(tt *testing.T) {
+		for _, kt := range []crypto.KeyType{crypto.Ed448, crypto.BLS12381G2} {
+			pubKey, _, err := crypto.GenerateKeyByKeyType(kt)
+			assert.NoError(tt, err)
+			pubKeyJWK, err := PublicKeyToPublicKeyJWK(pubKey)
+			assert.NoError(tt, err)
+			thumbprint, err := pubKeyJWK.Thumbprint()
+			assert.NoError(tt, err)
+			assert.Len(tt, thumbprint, 43)
+		}
+	})
+
+	t.Run("optional members are ignored", func(tt *testing.T) {
+		pubKeyJWK := PublicKeyJWK{KTY: "OKP", CRV: "Ed25519", X: "11qYAYKxCrfVS_7TyWQHOg7hcvPapiMlrwIaaPcHURo"}
+		expected, err := pubKeyJWK.Thumbprint()
+		assert.NoError(tt, err)
+
+		pubKeyJWK.KID = "key-1"
+		pubKeyJWK.Use = "sig"
+		pubKeyJWK.Alg = "EdDSA"
+		thumbprint, err := pubKeyJWK.Thumbprint()
+		assert.NoError(tt, err)
+		assert.Equal(tt, expected, thumbprint)
+
+		// https://www.rfc-editor.org/rfc/rfc8037#appendix-A.3
+		assert.Equal(tt, "kPrK_qmxVWaYVA9wwBF6Iuo3vVzz7TxHCTwXBygrS4k", thumbprint)
+	})
+
+	t.Run("bad input", func(tt *testing.T) {
+		_, err := PublicKeyJWK{KTY: "EC", CRV: "P-256", X: "abc"}.Thumbprint()
+		assert.Error(tt, err)
+		assert.Contains(tt, err.Error(), "missing required member for thumbprint: y")
+
+		_, err = PublicKeyJWK{KTY: DilithiumKTY, X: "abc"}.Thumbprint()
+		assert.Error(tt, err)
+		assert.Contains(tt, err.Error(), "unsupported kty for thumbprint")
+
+		_, err = PublicKeyJWK{KTY: "OKP", CRV: "Ed25519", X: "abc"}.ThumbprintWithHash(gocrypto.Hash(0))
+		assert.Error(tt, err)
+	})
+}
@@ -0,0 +1,104 @@
+package jwx
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/goccy/go-json"
+	"github.com/lestrrat-go/jwx/v2/jwa"
+	"github.com/pkg/errors"
+)
+
+var (
+	// ErrKeyNotFound is returned when no key in a KeySet matches the selection
+	ErrKeyNotFound = errors.New("key not found")
+	// ErrAmbiguousKey is returned when more than one key in a KeySet matches the selection
+	ErrAmbiguousKey = errors.New("ambiguous key")
+)
+
+// KeySet is a JWK Set of public keys https://www.rfc-editor.org/rfc/rfc7517#section-5
+type KeySet struct {
+	Keys []PublicKeyJWK `json:"keys"`
+}
+
+// ParseJWKS parses a JWK Set, ignoring any private key members, and requiring each key to have a kty
+func ParseJWKS(b []byte) (*KeySet, error) {
+	var keySet KeySet
+	if err := json.Unmarshal(b, &keySet); err != nil {
+		return nil, errors.Wrap(err, "unmarshalling JWKS")
+	}
+	if keySet.Keys == nil {
+		return nil, errors.New("JWKS is missing keys")
+	}
+	for i, key := range keySet.Keys {
+		if key.KTY == "" {
+			return nil, fmt.Errorf("key at index %d is missing kty", i)
+		}
+	}
+	return &keySet, nil
+}
+
+// Select returns the key to verify a signature with the given kid and alg, either of which may be empty. A key whose
+// kid matches exactly is preferred. Without a kid, the single signing key that supports the alg is returned. If no key
+// matches ErrKeyNotFound is returned, and if several keys match ErrAmbiguousKey is returned.
+func (ks *KeySet) Select(kid, alg string) (*PublicKeyJWK, error) {
+	var candidates []PublicKeyJWK
+	for _, key := range ks.Keys {
+		if kid != "" && key.KID != kid {
+			continue
+		}
+		if !isSigningKey(key) || (alg != "" && !keySupportsAlg(key, alg)) {
+			continue
+		}
+		candidates = append(candidates, key)
+	}
+
+	switch len(candidates) {
+	case 0:
+		if kid != "" {
+			return nil, fmt.Errorf("%w: no key with kid<%s> for alg<%s>", ErrKeyNotFound, kid, alg)
+		}
+		return nil, fmt.Errorf("%w: no key for alg<%s>", ErrKeyNotFound, alg)
+	case 1:
+		return &candidates[0], nil
+	default:
+		if kid != "" {
+			return nil, fmt.Errorf("%w: %d keys with kid<%s>", ErrAmbiguousKey, len(candidates), kid)
+		}
+		return nil, fmt.Errorf("%w: %d keys for alg<%s>", ErrAmbiguousKey, len(candidates), alg)
+	}
+}
+
+// isSigningKey checks whether the key may be used for signatures, according to its use and key_ops
+// https://www.rfc-editor.org/rfc/rfc7517#section-4.2
+func isSigningKey(key PublicKeyJWK) bool {
+	if key.Use != "" && key.Use != "sig" {
+		return false
+	}
+	if len(key.KeyOps) == 0 {
+		return true
+	}
+	for _, op := range key.KeyOps {
+		if op == "verify" {
+			return true
+		}
+	}
+	return false
+}
+
+// keySupportsAlg checks whether the key may be used with the signature algorithm, using the alg of the key if present
+// and the algorithms of its key type and curve otherwise
+func keySupportsAlg(key PublicKeyJWK, alg string) bool {
+	if key.Alg != "" {
+		return key.Alg == alg
+	}
+	switch key.KTY {
+	case jwa.RSA.String():
+		return strings.HasPrefix(alg, "RS") || strings.HasPrefix(alg, "PS")
+	case jwa.OKP.String():
+		return alg == jwa.EdDSA.String() && (key.CRV == jwa.Ed25519.String() || key.CRV == jwa.Ed448.String())
+	default:
+		keyAlg, err := AlgFromKeyAndCurve(jwa.KeyType(key.KTY), jwa.EllipticCurveAlgorithm(key.CRV))
+		return err == nil && keyAlg.String() == alg
+	}
+}
@@ -0,0 +1,120 @@
+package jwx
+
+import (
+	"testing"
+
+	"github.com/goccy/go-json"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/TBD54566975/ssi-sdk/crypto"
+)
+
+func TestJWKS(t *testing.T) {
+	// a JWKS with two P-256 keys and one RSA signing key
+	signers := make(map[string]*Signer)
+	var keys []PublicKeyJWK
+	for _, key := range []struct {
+		kid string
+		kt  crypto.KeyType
+		use string
+	}{
+		{kid: "ec-1", kt: crypto.P256},
+		{kid: "ec-2", kt: crypto.P256},
+		{kid: "rsa-1", kt: crypto.RSA, use: "sig"},
+	} {
+		pubKey, privKey, err := crypto.GenerateKeyByKeyType(key.kt)
+		require.NoError(t, err)
+		signer, err := NewJWXSigner("did:example:123", key.kid, privKey)
+		require.NoError(t, err)
+		signers[key.kid] = signer
+		pubKeyJWK, err := PublicKeyToPublicKeyJWK(pubKey)
+		require.NoError(t, err)
+		pubKeyJWK.KID = key.kid
+		pubKeyJWK.Use = key.use
+		keys = append(keys, *pubKeyJWK)
+	}
+	jwksBytes, err := json.Marshal(map[string]any{"keys": keys})
+	require.NoError(t, err)
+
+	keySet, err := ParseJWKS(jwksBytes)
+	require.NoError(t, err)
+	assert.Len(t, keySet.Keys, 3)
+
+	t.Run("exact kid match", func(tt *testing.T) {
+		key, err := keySet.Select("ec-2", "")
+		assert.NoError(tt, err)
+		assert.Equal(tt, keys[1], *key)
+
+		key, err = keySet.Select("ec-1", "ES256")
+		assert.NoError(tt, err)
+		assert.Equal(tt, keys[0], *key)
+	})
+
+	t.Run("kid with incompatible alg", func(tt *testing.T) {
+		_, err := keySet.Select("ec-1", "PS256")
+		assert.ErrorIs(tt, err, ErrKeyNotFound)
+	})
+
+	t.Run("unknown kid", func(tt *testing.T) {
+		_, err := keySet.Select("ec-3", "ES256")
+		assert.ErrorIs(tt, err, ErrKeyNotFound)
+	})
+
+	t.Run("single key for alg", func(tt *testing.T) {
+		key, err := keySet.Select("", "PS256")
+		assert.NoError(tt, err)
+		assert.Equal(tt, keys[2], *key)
+	})
+
+	t.Run("ambiguous alg", func(tt *testing.T) {
+		_, err := keySet.Select("", "ES256")
+		assert.ErrorIs(tt, err, ErrAmbiguousKey)
+
+		_, err = keySet.Select("", "")
+		assert.ErrorIs(tt, err, ErrAmbiguousKey)
+	})
+
+	t.Run("encryption keys are not selected", func(tt *testing.T) {
+		withEncryptionKey := KeySet{Keys: append([]PublicKeyJWK{}, keys...)}
+		withEncryptionKey.Keys[1].Use = "enc"
+
+		key, err := withEncryptionKey.Select("", "ES256")
+		assert.NoError(tt, err)
+		assert.Equal(tt, "ec-1", key.KID)
+
+		_, err = withEncryptionKey.Select("ec-2", "")
+		assert.ErrorIs(tt, err, ErrKeyNotFound)
+	})
+
+	t.Run("verify a JWT by its kid", func(tt *testing.T) {
+		token, err := signers["ec-2"].SignWithDefaults(map[string]any{"vc": "credential"})
+		require.NoError(tt, err)
+		headers, err := GetJWSHeaders(token)
+		require.NoError(tt, err)
+
+		key, err := keySet.Select(headers.KeyID(), headers.Algorithm().String())
+		assert.NoError(tt, err)
+		verifier, err := NewJWXVerifierFromJWK("did:example:123", *key)
+		assert.NoError(tt, err)
+		assert.NoError(tt, verifier.Verify(string(token)))
+	})
+
+	t.Run("bad input", func(tt *testing.T) {
+		_, err := ParseJWKS([]byte("not json"))
+		assert.Error(tt, err)
+
+		_, err = ParseJWKS([]byte(`{}`))
+		assert.Error(tt, err)
+		assert.Contains(tt, err.Error(), "missing keys")
+
+		_, err = ParseJWKS([]byte(`{"keys":[{"kid":"no-kty"}]}`))
+		assert.Error(tt, err)
+		assert.Contains(tt, err.Error(), "missing kty")
+
+		empty, err := ParseJWKS([]byte(`{"keys":[]}`))
+		assert.NoError(tt, err)
+		_, err = empty.Select("", "ES256")
+		assert.ErrorIs(tt, err, ErrKeyNotFound)
+	})
+}
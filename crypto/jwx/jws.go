@@ -1,22 +1,97 @@
 package jwx
 
 import (
+	"encoding/base64"
 	"fmt"
+	"strings"
 
+	"github.com/goccy/go-json"
 	"github.com/lestrrat-go/jwx/v2/jws"
 	"github.com/lestrrat-go/jwx/v2/jwt"
 	"github.com/pkg/errors"
 )
 
+// ErrUnunderstoodCritical is returned when a JWS lists a header parameter in crit that the verifier does not understand
+var ErrUnunderstoodCritical = errors.New("unsupported critical header parameter")
+
+// standardHeaderParameters are the header parameters registered by RFC 7515, which are always understood
+// https://www.rfc-editor.org/rfc/rfc7515#section-4.1
+var standardHeaderParameters = map[string]struct{}{
+	jws.AlgorithmKey:              {},
+	jws.JWKSetURLKey:              {},
+	jws.JWKKey:                    {},
+	jws.KeyIDKey:                  {},
+	jws.X509URLKey:                {},
+	jws.X509CertChainKey:          {},
+	jws.X509CertThumbprintKey:     {},
+	jws.X509CertThumbprintS256Key: {},
+	jws.TypeKey:                   {},
+	jws.ContentTypeKey:            {},
+	jws.CriticalKey:               {},
+}
+
 // SignJWS takes a set of payload and signs it with the key defined in the signer
 func (s *Signer) SignJWS(payload []byte) ([]byte, error) {
 	headers := jws.NewHeaders()
 	if err := headers.Set(jws.AlgorithmKey, s.SignatureAlgorithm); err != nil {
 		return nil, errors.Wrap(err, "setting algorithm header")
 	}
+	if s.keyStore != nil {
+		return s.signWithKeyStore(payload, headers, false)
+	}
 	return jws.Sign(payload, jws.WithKey(s.SignatureAlgorithm, s.Key, jws.WithProtectedHeaders(headers)))
 }
 
+// SignDetached signs the payload with the key defined in the signer, returning a compact JWS with a detached payload,
+// whose payload segment is empty
+// https://www.rfc-editor.org/rfc/rfc7515#appendix-F
+func (s *Signer) SignDetached(payload []byte) (string, error) {
+	headers := jws.NewHeaders()
+	if err := headers.Set(jws.AlgorithmKey, s.SignatureAlgorithm); err != nil {
+		return "", errors.Wrap(err, "setting algorithm header")
+	}
+	if s.keyStore != nil {
+		signed, err := s.signWithKeyStore(payload, headers, true)
+		if err != nil {
+			return "", err
+		}
+		return string(signed), nil
+	}
+	signed, err := jws.Sign(nil, jws.WithKey(s.SignatureAlgorithm, s.Key, jws.WithProtectedHeaders(headers)), jws.WithDetachedPayload(payload))
+	if err != nil {
+		return "", errors.Wrap(err, "signing detached payload")
+	}
+	return string(signed), nil
+}
+
+// signWithKeyStore produces a compact JWS of the payload with the protected headers, delegating the signature over the
+// signing input to the signer's key store. As when signing with a key, the alg header is the signer's and the kid header
+// defaults to the signer's key ID. https://www.rfc-editor.org/rfc/rfc7515#section-5.1
+func (s *Signer) signWithKeyStore(payload []byte, protected jws.Headers, detached bool) ([]byte, error) {
+	if err := protected.Set(jws.AlgorithmKey, s.SignatureAlgorithm); err != nil {
+		return nil, errors.Wrap(err, "setting algorithm header")
+	}
+	if kid := s.Key.KeyID(); kid != "" && protected.KeyID() == "" {
+		if err := protected.Set(jws.KeyIDKey, kid); err != nil {
+			return nil, errors.Wrap(err, "setting kid header")
+		}
+	}
+	headerBytes, err := json.Marshal(protected)
+	if err != nil {
+		return nil, errors.Wrap(err, "marshalling protected headers")
+	}
+	encodedHeader := base64.RawURLEncoding.EncodeToString(headerBytes)
+	encodedPayload := base64.RawURLEncoding.EncodeToString(payload)
+	signature, err := s.keyStore.Sign(s.keyID, []byte(encodedHeader+"."+encodedPayload))
+	if err != nil {
+		return nil, errors.Wrap(err, "signing with key store")
+	}
+	if detached {
+		encodedPayload = ""
+	}
+	return []byte(encodedHeader + "." + encodedPayload + "." + base64.RawURLEncoding.EncodeToString(signature)), nil
+}
+
 // Parse attempts to turn a string into a jwt.Token
 func (*Signer) Parse(token string) (jws.Headers, jwt.Token, error) {
 	parsed, err := jwt.Parse([]byte(token), jwt.WithValidate(false), jwt.WithVerify(false))
@@ -32,6 +107,9 @@ func (*Signer) Parse(token string) (jws.Headers, jwt.Token, error) {
 
 // VerifyJWS parses a token given the verifier's known algorithm and key, and returns an error, which is nil upon success.
 func (v *Verifier) VerifyJWS(token string) error {
+	if err := v.checkCritical(token); err != nil {
+		return err
+	}
 	key := jws.WithKey(v.Algorithm(), v.Key)
 	if _, err := jws.Verify([]byte(token), key); err != nil {
 		return errors.Wrap(err, "verifying JWT")
@@ -39,6 +117,170 @@ func (v *Verifier) VerifyJWS(token string) error {
 	return nil
 }
 
+// VerifyDetached verifies a compact JWS with a detached payload against the key, reconstructing the signing input from
+// the protected header and signature of the JWS and the externally provided payload. It returns an error, which is nil
+// upon success.
+func VerifyDetached(key PublicKeyJWK, protectedAndSig string, payload []byte) error {
+	parts := strings.Split(protectedAndSig, ".")
+	if len(parts) != 3 || parts[0] == "" || parts[2] == "" {
+		return errors.New("detached JWS must be of the form <protected>..<signature>")
+	}
+	if parts[1] != "" {
+		return errors.New("detached JWS must not contain a payload")
+	}
+	verifier, err := NewJWXVerifierFromJWK("", key)
+	if err != nil {
+		return errors.Wrap(err, "creating verifier")
+	}
+	if err = verifier.checkCritical(protectedAndSig); err != nil {
+		return err
+	}
+	if _, err = jws.Verify([]byte(protectedAndSig), jws.WithKey(verifier.Algorithm(), verifier.Key), jws.WithDetachedPayload(payload)); err != nil {
+		return errors.Wrap(err, "verifying detached JWS")
+	}
+	return nil
+}
+
+// Verification is the result of verifying one of the signatures of a JWS in general JSON serialization, identified
+// by the kid of its header. Error is set when the signature could not be verified.
+type Verification struct {
+	KID      string
+	Verified bool
+	Error    error
+}
+
+// generalJWS is a JWS in general JSON serialization
+// https://www.rfc-editor.org/rfc/rfc7515#section-7.2.1
+type generalJWS struct {
+	Payload    string `json:"payload"`
+	Signatures []struct {
+		Protected string         `json:"protected"`
+		Header    map[string]any `json:"header,omitempty"`
+		Signature string         `json:"signature"`
+	} `json:"signatures"`
+}
+
+// SignGeneralJSON signs the payload with each of the signers, returning a JWS in general JSON serialization with one
+// signature per signer. Each signer's key must have a kid, which is set in the protected header of its signature so
+// that verifiers can resolve the corresponding key.
+func SignGeneralJSON(payload []byte, signers []Signer) ([]byte, error) {
+	if len(signers) == 0 {
+		return nil, errors.New("at least one signer is required")
+	}
+	opts := []jws.SignOption{jws.WithJSON()}
+	for i, signer := range signers {
+		if signer.Key == nil {
+			return nil, fmt.Errorf("signer at index %d has no key", i)
+		}
+		kid := signer.Key.KeyID()
+		if kid == "" {
+			return nil, fmt.Errorf("signer at index %d has no kid", i)
+		}
+		headers := jws.NewHeaders()
+		if err := headers.Set(jws.AlgorithmKey, signer.SignatureAlgorithm); err != nil {
+			return nil, errors.Wrap(err, "setting algorithm header")
+		}
+		if err := headers.Set(jws.KeyIDKey, kid); err != nil {
+			return nil, errors.Wrap(err, "setting kid header")
+		}
+		opts = append(opts, jws.WithKey(signer.SignatureAlgorithm, signer.Key, jws.WithProtectedHeaders(headers)))
+	}
+	signed, err := jws.Sign(payload, opts...)
+	if err != nil {
+		return nil, errors.Wrap(err, "signing payload")
+	}
+	return signed, nil
+}
+
+// VerifyGeneralJSON verifies each of the signatures of a JWS in general JSON serialization, resolving the key of each
+// signature from the kid of its header. The verification of every signature is reported independently, in the order
+// of the signatures, so callers can decide whether all or any signatures must be valid. An error is returned only if
+// the JWS cannot be parsed.
+func VerifyGeneralJSON(b []byte, resolve func(kid string) (PublicKeyJWK, error)) ([]Verification, error) {
+	if resolve == nil {
+		return nil, errors.New("key resolver cannot be nil")
+	}
+	var general generalJWS
+	if err := json.Unmarshal(b, &general); err != nil {
+		return nil, errors.Wrap(err, "unmarshalling general JSON JWS")
+	}
+	if len(general.Signatures) == 0 {
+		return nil, errors.New("general JSON JWS has no signatures")
+	}
+
+	verifications := make([]Verification, 0, len(general.Signatures))
+	for _, signature := range general.Signatures {
+		verification := Verification{}
+		// the compact form of each signature shares its signing input
+		compact := signature.Protected + "." + general.Payload + "." + signature.Signature
+		kid, err := generalJWSKeyID(compact, signature.Header)
+		if err != nil {
+			verification.Error = err
+			verifications = append(verifications, verification)
+			continue
+		}
+		verification.KID = kid
+		verification.Error = verifyWithResolvedKey(compact, kid, resolve)
+		verification.Verified = verification.Error == nil
+		verifications = append(verifications, verification)
+	}
+	return verifications, nil
+}
+
+// generalJWSKeyID returns the kid of a signature, preferring the protected header over the unprotected header
+func generalJWSKeyID(compact string, header map[string]any) (string, error) {
+	headers, err := GetJWSHeaders([]byte(compact))
+	if err != nil {
+		return "", errors.Wrap(err, "parsing signature")
+	}
+	if kid := headers.KeyID(); kid != "" {
+		return kid, nil
+	}
+	if kid, ok := header[jws.KeyIDKey].(string); ok && kid != "" {
+		return kid, nil
+	}
+	return "", errors.New("signature has no kid")
+}
+
+func verifyWithResolvedKey(compact, kid string, resolve func(kid string) (PublicKeyJWK, error)) error {
+	key, err := resolve(kid)
+	if err != nil {
+		return errors.Wrapf(err, "resolving key for kid<%s>", kid)
+	}
+	verifier, err := NewJWXVerifierFromJWK("", key)
+	if err != nil {
+		return errors.Wrapf(err, "creating verifier for kid<%s>", kid)
+	}
+	return verifier.VerifyJWS(compact)
+}
+
+// checkCritical rejects a JWS whose protected crit header lists a parameter that is neither a standard header parameter
+// nor understood by the verifier, or that is not present in the protected header
+func (v *Verifier) checkCritical(token string) error {
+	headers, err := GetJWSHeaders([]byte(token))
+	if err != nil {
+		return errors.Wrap(err, "could not get JWS headers")
+	}
+	if _, ok := headers.Get(jws.CriticalKey); !ok {
+		return nil
+	}
+	critical := headers.Critical()
+	if len(critical) == 0 {
+		return errors.New("crit header must not be empty")
+	}
+	for _, name := range critical {
+		_, standard := standardHeaderParameters[name]
+		_, understood := v.understoodCritical[name]
+		if !standard && !understood {
+			return fmt.Errorf("%w: %s", ErrUnunderstoodCritical, name)
+		}
+		if _, ok := headers.Get(name); !ok {
+			return fmt.Errorf("critical header parameter is missing: %s", name)
+		}
+	}
+	return nil
+}
+
 // ParseJWS attempts to pull of a single signature from a token, containing its headers
 func (*Verifier) ParseJWS(token string) (*jws.Signature, error) {
 	parsed, err := jws.Parse([]byte(token))
@@ -0,0 +1,46 @@
+package jwx
+
+import (
+	"fmt"
+
+	"github.com/lestrrat-go/jwx/v2/jwa"
+	"github.com/lestrrat-go/jwx/v2/jwk"
+	"github.com/lestrrat-go/jwx/v2/jws"
+	"github.com/pkg/errors"
+
+	"github.com/TBD54566975/ssi-sdk/crypto"
+)
+
+func init() {
+	// replaces the ES256K signer of our jwx library, whose signatures may have a high S value, which verifiers such as
+	// those of Bitcoin and Ethereum reject
+	jws.RegisterSigner(jwa.ES256K, jws.SignerFactoryFn(func() (jws.Signer, error) { return ES256KSigner{}, nil }))
+}
+
+// ES256KSigner implements the jws.Signer interface for secp256k1 keys, producing deterministic RFC 6979 signatures
+// that are low-S normalized, where S is at most half the curve order
+// https://github.com/bitcoin/bips/blob/master/bip-0062.mediawiki#low-s-values-in-signatures
+type ES256KSigner struct{}
+
+// Algorithm returns the jwa.SignatureAlgorithm value for ES256K
+func (ES256KSigner) Algorithm() jwa.SignatureAlgorithm {
+	return jwa.ES256K
+}
+
+// Sign signs the payload using the provided key, which is either a jwk.Key or a secp256k1 private key
+func (ES256KSigner) Sign(payload []byte, keyif any) ([]byte, error) {
+	if keyif == nil {
+		return nil, errors.New("missing private key while signing payload")
+	}
+	key := keyif
+	if jwkKey, ok := keyif.(jwk.Key); ok {
+		if err := jwkKey.Raw(&key); err != nil {
+			return nil, errors.Wrap(err, "getting raw secp256k1 private key")
+		}
+	}
+	signature, err := crypto.Sign(crypto.SECP256k1, key, payload)
+	if err != nil {
+		return nil, fmt.Errorf("signing with ES256K: %w", err)
+	}
+	return signature, nil
+}
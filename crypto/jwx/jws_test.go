@@ -0,0 +1,344 @@
+package jwx
+
+import (
+	"encoding/base64"
+	"math/big"
+	"strings"
+	"testing"
+
+	"github.com/decred/dcrd/dcrec/secp256k1/v4"
+	"github.com/goccy/go-json"
+	"github.com/lestrrat-go/jwx/v2/jwa"
+	"github.com/lestrrat-go/jwx/v2/jws"
+	"github.com/lestrrat-go/jwx/v2/jwt"
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/TBD54566975/ssi-sdk/crypto"
+)
+
+func TestDetachedJWS(t *testing.T) {
+	payload := []byte(`{"hello":"world"}`)
+
+	for _, kt := range []crypto.KeyType{crypto.Ed25519, crypto.SECP256k1, crypto.P256, crypto.P384, crypto.P521, crypto.RSA} {
+		t.Run(string(kt), func(tt *testing.T) {
+			pubKey, privKey, err := crypto.GenerateKeyByKeyType(kt)
+			require.NoError(tt, err)
+			signer, err := NewJWXSigner("test-id", "test-kid", privKey)
+			require.NoError(tt, err)
+			pubKeyJWK, err := PublicKeyToPublicKeyJWK(pubKey)
+			require.NoError(tt, err)
+
+			detached, err := signer.SignDetached(payload)
+			assert.NoError(tt, err)
+			parts := strings.Split(detached, ".")
+			assert.Len(tt, parts, 3)
+			assert.Empty(tt, parts[1])
+
+			assert.NoError(tt, VerifyDetached(*pubKeyJWK, detached, payload))
+
+			// the signature is over the external payload, so tampering with it fails verification
+			err = VerifyDetached(*pubKeyJWK, detached, []byte(`{"hello":"mallory"}`))
+			assert.Error(tt, err)
+			assert.Contains(tt, err.Error(), "verifying detached JWS")
+		})
+	}
+
+	t.Run("matches the attached signing input", func(tt *testing.T) {
+		pubKey, privKey, err := crypto.GenerateEd25519Key()
+		require.NoError(tt, err)
+		signer, err := NewJWXSigner("test-id", "test-kid", privKey)
+		require.NoError(tt, err)
+		pubKeyJWK, err := PublicKeyToPublicKeyJWK(pubKey)
+		require.NoError(tt, err)
+
+		// Ed25519 signatures are deterministic, so the attached form differs only by its payload segment
+		attached, err := signer.SignJWS(payload)
+		require.NoError(tt, err)
+		detached, err := signer.SignDetached(payload)
+		require.NoError(tt, err)
+		parts := strings.Split(string(attached), ".")
+		assert.Equal(tt, parts[0]+".."+parts[2], detached)
+		assert.Equal(tt, base64.RawURLEncoding.EncodeToString(payload), parts[1])
+
+		// a detached JWS cannot be verified without its payload
+		_, err = jws.Verify([]byte(detached), jws.WithKey(signer.SignatureAlgorithm, signer.Key))
+		assert.Error(tt, err)
+		assert.NoError(tt, VerifyDetached(*pubKeyJWK, detached, payload))
+	})
+
+	t.Run("bad input", func(tt *testing.T) {
+		pubKey, privKey, err := crypto.GenerateEd25519Key()
+		require.NoError(tt, err)
+		signer, err := NewJWXSigner("test-id", "test-kid", privKey)
+		require.NoError(tt, err)
+		pubKeyJWK, err := PublicKeyToPublicKeyJWK(pubKey)
+		require.NoError(tt, err)
+
+		attached, err := signer.SignJWS(payload)
+		require.NoError(tt, err)
+		err = VerifyDetached(*pubKeyJWK, string(attached), payload)
+		assert.Error(tt, err)
+		assert.Contains(tt, err.Error(), "must not contain a payload")
+
+		for _, malformed := range []string{"", "abc", "abc..", "..abc", "a.b"} {
+			assert.Error(tt, VerifyDetached(*pubKeyJWK, malformed, payload))
+		}
+
+		otherPubKey, _, err := crypto.GenerateEd25519Key()
+		require.NoError(tt, err)
+		otherPubKeyJWK, err := PublicKeyToPublicKeyJWK(otherPubKey)
+		require.NoError(tt, err)
+		detached, err := signer.SignDetached(payload)
+		require.NoError(tt, err)
+		assert.Error(tt, VerifyDetached(*otherPubKeyJWK, detached, payload))
+	})
+}
+
+func TestGeneralJSONJWS(t *testing.T) {
+	payload := []byte(`{"hello":"world"}`)
+
+	keys := make(map[string]PublicKeyJWK)
+	var signers []Signer
+	for _, kt := range []crypto.KeyType{crypto.Ed25519, crypto.P256, crypto.RSA} {
+		pubKey, privKey, err := crypto.GenerateKeyByKeyType(kt)
+		require.NoError(t, err)
+		kid := "did:example:123#" + string(kt)
+		signer, err := NewJWXSigner("did:example:123", kid, privKey)
+		require.NoError(t, err)
+		signers = append(signers, *signer)
+		pubKeyJWK, err := PublicKeyToPublicKeyJWK(pubKey)
+		require.NoError(t, err)
+		keys[kid] = *pubKeyJWK
+	}
+	resolve := func(kid string) (PublicKeyJWK, error) {
+		key, ok := keys[kid]
+		if !ok {
+			return PublicKeyJWK{}, errors.New("unknown kid")
+		}
+		return key, nil
+	}
+
+	t.Run("sign and verify", func(tt *testing.T) {
+		signed, err := SignGeneralJSON(payload, signers)
+		assert.NoError(tt, err)
+
+		var general generalJWS
+		require.NoError(tt, json.Unmarshal(signed, &general))
+		assert.Equal(tt, base64.RawURLEncoding.EncodeToString(payload), general.Payload)
+		assert.Len(tt, general.Signatures, len(signers))
+
+		verifications, err := VerifyGeneralJSON(signed, resolve)
+		assert.NoError(tt, err)
+		assert.Len(tt, verifications, len(signers))
+		for i, verification := range verifications {
+			assert.Equal(tt, signers[i].Key.KeyID(), verification.KID)
+			assert.True(tt, verification.Verified)
+			assert.NoError(tt, verification.Error)
+		}
+	})
+
+	t.Run("signatures are verified independently", func(tt *testing.T) {
+		signed, err := SignGeneralJSON(payload, signers)
+		require.NoError(tt, err)
+
+		// corrupt the second signature and drop the key of the third
+		var general map[string]any
+		require.NoError(tt, json.Unmarshal(signed, &general))
+		signatures := general["signatures"].([]any)
+		second := signatures[1].(map[string]any)
+		second["signature"] = signatures[0].(map[string]any)["signature"]
+		tampered, err := json.Marshal(general)
+		require.NoError(tt, err)
+
+		missingKey := func(kid string) (PublicKeyJWK, error) {
+			if kid == signers[2].Key.KeyID() {
+				return PublicKeyJWK{}, errors.New("unknown kid")
+			}
+			return resolve(kid)
+		}
+		verifications, err := VerifyGeneralJSON(tampered, missingKey)
+		assert.NoError(tt, err)
+		assert.Len(tt, verifications, 3)
+
+		assert.True(tt, verifications[0].Verified)
+		assert.False(tt, verifications[1].Verified)
+		assert.Error(tt, verifications[1].Error)
+		assert.False(tt, verifications[2].Verified)
+		assert.Contains(tt, verifications[2].Error.Error(), "resolving key for kid")
+	})
+
+	t.Run("tampered payload fails every signature", func(tt *testing.T) {
+		signed, err := SignGeneralJSON(payload, signers)
+		require.NoError(tt, err)
+
+		var general map[string]any
+		require.NoError(tt, json.Unmarshal(signed, &general))
+		general["payload"] = base64.RawURLEncoding.EncodeToString([]byte(`{"hello":"mallory"}`))
+		tampered, err := json.Marshal(general)
+		require.NoError(tt, err)
+
+		verifications, err := VerifyGeneralJSON(tampered, resolve)
+		assert.NoError(tt, err)
+		for _, verification := range verifications {
+			assert.False(tt, verification.Verified)
+		}
+	})
+
+	t.Run("bad input", func(tt *testing.T) {
+		_, err := SignGeneralJSON(payload, nil)
+		assert.Error(tt, err)
+
+		_, privKey, err := crypto.GenerateEd25519Key()
+		require.NoError(tt, err)
+		noKID, err := NewJWXSigner("did:example:123", "", privKey)
+		require.NoError(tt, err)
+		_, err = SignGeneralJSON(payload, []Signer{*noKID})
+		assert.Error(tt, err)
+		assert.Contains(tt, err.Error(), "has no kid")
+
+		_, err = VerifyGeneralJSON([]byte("not json"), resolve)
+		assert.Error(tt, err)
+		_, err = VerifyGeneralJSON([]byte(`{"payload":"","signatures":[]}`), resolve)
+		assert.Error(tt, err)
+		_, err = VerifyGeneralJSON([]byte(`{}`), nil)
+		assert.Error(tt, err)
+	})
+}
+
+func TestCriticalHeaders(t *testing.T) {
+	_, privKey, err := crypto.GenerateEd25519Key()
+	require.NoError(t, err)
+	signer, err := NewJWXSigner("test-id", "test-kid", privKey)
+	require.NoError(t, err)
+
+	// signWithHeaders signs the payload as a JWS and as a JWT with the given extra protected headers
+	signWithHeaders := func(t *testing.T, extra map[string]any) (string, string) {
+		headers := jws.NewHeaders()
+		require.NoError(t, headers.Set(jws.AlgorithmKey, signer.SignatureAlgorithm))
+		for k, v := range extra {
+			require.NoError(t, headers.Set(k, v))
+		}
+		signedJWS, err := jws.Sign([]byte("hello world"), jws.WithKey(signer.SignatureAlgorithm, signer.Key, jws.WithProtectedHeaders(headers)))
+		require.NoError(t, err)
+
+		token := jwt.New()
+		require.NoError(t, token.Set(jwt.IssuerKey, signer.ID))
+		signedJWT, err := jwt.Sign(token, jwt.WithKey(signer.SignatureAlgorithm, signer.Key, jws.WithProtectedHeaders(headers)))
+		require.NoError(t, err)
+		return string(signedJWS), string(signedJWT)
+	}
+
+	t.Run("no crit header", func(tt *testing.T) {
+		verifier, err := signer.ToVerifier(signer.ID)
+		require.NoError(tt, err)
+		signedJWS, signedJWT := signWithHeaders(tt, nil)
+		assert.NoError(tt, verifier.VerifyJWS(signedJWS))
+		assert.NoError(tt, verifier.Verify(signedJWT))
+	})
+
+	t.Run("understood crit passes", func(tt *testing.T) {
+		key, err := signer.Key.PublicKey()
+		require.NoError(tt, err)
+		verifier, err := NewJWXVerifierFromKey(signer.ID, key, WithUnderstoodCritical("exp-ext"))
+		require.NoError(tt, err)
+
+		signedJWS, signedJWT := signWithHeaders(tt, map[string]any{jws.CriticalKey: []string{"exp-ext"}, "exp-ext": "value"})
+		assert.NoError(tt, verifier.VerifyJWS(signedJWS))
+		assert.NoError(tt, verifier.Verify(signedJWT))
+		_, _, err = verifier.VerifyAndParse(signedJWT)
+		assert.NoError(tt, err)
+	})
+
+	t.Run("unknown crit fails", func(tt *testing.T) {
+		key, err := signer.Key.PublicKey()
+		require.NoError(tt, err)
+		verifier, err := NewJWXVerifierFromKey(signer.ID, key, WithUnderstoodCritical("exp-ext"))
+		require.NoError(tt, err)
+
+		signedJWS, signedJWT := signWithHeaders(tt, map[string]any{jws.CriticalKey: []string{"b64"}, "b64": true})
+		assert.ErrorIs(tt, verifier.VerifyJWS(signedJWS), ErrUnunderstoodCritical)
+		assert.ErrorIs(tt, verifier.Verify(signedJWT), ErrUnunderstoodCritical)
+		_, _, err = verifier.VerifyAndParse(signedJWT)
+		assert.ErrorIs(tt, err, ErrUnunderstoodCritical)
+
+		// verifiers without understood extensions reject any extension
+		defaultVerifier, err := signer.ToVerifier(signer.ID)
+		require.NoError(tt, err)
+		signedJWS, _ = signWithHeaders(tt, map[string]any{jws.CriticalKey: []string{"exp-ext"}, "exp-ext": "value"})
+		err = defaultVerifier.VerifyJWS(signedJWS)
+		assert.ErrorIs(tt, err, ErrUnunderstoodCritical)
+		assert.Contains(tt, err.Error(), "exp-ext")
+	})
+
+	t.Run("standard headers are understood", func(tt *testing.T) {
+		verifier, err := signer.ToVerifier(signer.ID)
+		require.NoError(tt, err)
+		signedJWS, _ := signWithHeaders(tt, map[string]any{jws.CriticalKey: []string{jws.TypeKey}, jws.TypeKey: "JWT"})
+		assert.NoError(tt, verifier.VerifyJWS(signedJWS))
+	})
+
+	t.Run("crit parameters must be present", func(tt *testing.T) {
+		key, err := signer.Key.PublicKey()
+		require.NoError(tt, err)
+		verifier, err := NewJWXVerifierFromKey(signer.ID, key, WithUnderstoodCritical("exp-ext"))
+		require.NoError(tt, err)
+
+		signedJWS, _ := signWithHeaders(tt, map[string]any{jws.CriticalKey: []string{"exp-ext"}})
+		err = verifier.VerifyJWS(signedJWS)
+		assert.Error(tt, err)
+		assert.Contains(tt, err.Error(), "critical header parameter is missing: exp-ext")
+
+		signedJWS, _ = signWithHeaders(tt, map[string]any{jws.CriticalKey: []string{}})
+		err = verifier.VerifyJWS(signedJWS)
+		assert.Error(tt, err)
+	})
+}
+
+func TestES256KSigner(t *testing.T) {
+	halfOrder := new(big.Int).Rsh(secp256k1.S256().N, 1)
+	payload := []byte("hello world")
+
+	for _, kt := range []crypto.KeyType{crypto.SECP256k1, crypto.SECP256k1ECDSA} {
+		t.Run(string(kt), func(tt *testing.T) {
+			_, privKey, err := crypto.GenerateKeyByKeyType(kt)
+			require.NoError(tt, err)
+			signer, err := NewJWXSigner("test-id", "test-kid", privKey)
+			require.NoError(tt, err)
+			assert.Equal(tt, jwa.ES256K, signer.SignatureAlgorithm)
+			verifier, err := signer.ToVerifier(signer.ID)
+			require.NoError(tt, err)
+
+			var signatures []string
+			for i := 0; i < 2; i++ {
+				signed, err := signer.SignJWS(payload)
+				assert.NoError(tt, err)
+				assert.NoError(tt, verifier.VerifyJWS(string(signed)))
+
+				headers, err := GetJWSHeaders(signed)
+				assert.NoError(tt, err)
+				assert.Equal(tt, jwa.ES256K, headers.Algorithm())
+
+				signature, err := base64.RawURLEncoding.DecodeString(strings.Split(string(signed), ".")[2])
+				assert.NoError(tt, err)
+				assert.Len(tt, signature, 64)
+				s := new(big.Int).SetBytes(signature[32:])
+				assert.True(tt, s.Cmp(halfOrder) <= 0, "signature S must be at most N/2")
+				signatures = append(signatures, string(signed))
+			}
+			// signatures are deterministic
+			assert.Equal(tt, signatures[0], signatures[1])
+		})
+	}
+
+	t.Run("invalid key", func(tt *testing.T) {
+		_, err := ES256KSigner{}.Sign(payload, nil)
+		assert.Error(tt, err)
+
+		_, privKey, err := crypto.GenerateP256Key()
+		require.NoError(tt, err)
+		_, err = ES256KSigner{}.Sign(payload, privKey)
+		assert.Error(tt, err)
+	})
+}
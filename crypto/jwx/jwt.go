@@ -2,10 +2,13 @@ package jwx
 
 import (
 	gocrypto "crypto"
+	"encoding/base64"
 	"fmt"
+	"strings"
 	"time"
 
 	"github.com/TBD54566975/ssi-sdk/crypto"
+	"github.com/TBD54566975/ssi-sdk/util"
 	"github.com/goccy/go-json"
 	"github.com/lestrrat-go/jwx/v2/jwa"
 	"github.com/lestrrat-go/jwx/v2/jwk"
@@ -19,6 +22,10 @@ type Signer struct {
 	ID string
 	jwa.SignatureAlgorithm
 	jwk.Key
+
+	// keyStore, when set, holds the private key under keyID and signs on the signer's behalf, and Key is its public key
+	keyStore KeyStore
+	keyID    string
 }
 
 // NewJWXSigner creates a new signer from a private key to sign and produce JWS values
@@ -59,6 +66,26 @@ func NewJWXSignerFromKey(id, kid string, key jwk.Key) (*Signer, error) {
 	return &Signer{ID: id, SignatureAlgorithm: *alg, Key: gotJWK}, nil
 }
 
+// NewJWXSignerFromKeyStore creates a new signer that delegates signing to the key held by the key store under the key
+// ID, so the private key never leaves the store. The signer's Key is the public key.
+func NewJWXSignerFromKeyStore(id, kid string, store KeyStore, keyID string) (*Signer, error) {
+	if store == nil {
+		return nil, errors.New("key store cannot be nil")
+	}
+	publicKeyJWK, err := store.PublicKey(keyID)
+	if err != nil {
+		return nil, errors.Wrapf(err, "getting public key<%s> from key store", keyID)
+	}
+	gotJWK, alg, err := jwxSignerVerifier(id, kid, publicKeyJWK)
+	if err != nil {
+		return nil, err
+	}
+	if !IsSupportedJWXSigningVerificationAlgorithm(*alg) {
+		return nil, fmt.Errorf("unsupported signing algorithm: %s", alg)
+	}
+	return &Signer{ID: id, SignatureAlgorithm: *alg, Key: gotJWK, keyStore: store, keyID: keyID}, nil
+}
+
 // ToVerifier converts a signer to a verifier, where the passed in verifiedID is the intended ID of the verifier for
 // `aud` validation
 func (s *Signer) ToVerifier(verifierID string) (*Verifier, error) {
@@ -73,20 +100,50 @@ func (s *Signer) ToVerifier(verifierID string) (*Verifier, error) {
 type Verifier struct {
 	ID string
 	jwk.Key
+
+	// understoodCritical is the set of extension header parameters the verifier accepts in a crit header
+	understoodCritical map[string]struct{}
+	// clock is the time source the exp, nbf, and iat claims of JWTs are validated against
+	clock util.Clock
+}
+
+// VerifierOption configures how a Verifier verifies JWTs and JWS signatures
+type VerifierOption func(*Verifier)
+
+// WithUnderstoodCritical is a VerifierOption declaring the extension header parameters the caller understands and
+// processes. Verification fails with ErrUnunderstoodCritical for any other parameter listed in a crit header.
+// https://www.rfc-editor.org/rfc/rfc7515#section-4.1.11
+func WithUnderstoodCritical(names ...string) VerifierOption {
+	return func(v *Verifier) {
+		if v.understoodCritical == nil {
+			v.understoodCritical = make(map[string]struct{}, len(names))
+		}
+		for _, name := range names {
+			v.understoodCritical[name] = struct{}{}
+		}
+	}
+}
+
+// WithClock is a VerifierOption validating the exp, nbf, and iat claims of JWTs against the given clock rather than
+// the current time, such as to verify a token as of a time when it was valid
+func WithClock(clock util.Clock) VerifierOption {
+	return func(v *Verifier) {
+		v.clock = clock
+	}
 }
 
 // NewJWXVerifier creates a new verifier from a public key to verify JWTs and JWS signatures
 // TODO(gabe) support keys not in jwk.Key https://github.com/TBD54566975/ssi-sdk/issues/365
-func NewJWXVerifier(id string, key gocrypto.PublicKey) (*Verifier, error) {
+func NewJWXVerifier(id string, key gocrypto.PublicKey, opts ...VerifierOption) (*Verifier, error) {
 	privateKeyJWK, err := PublicKeyToJWK(key)
 	if err != nil {
 		return nil, err
 	}
-	return NewJWXVerifierFromKey(id, privateKeyJWK)
+	return NewJWXVerifierFromKey(id, privateKeyJWK, opts...)
 }
 
 // NewJWXVerifierFromJWK creates a new verifier from a public key to verify JWTs and JWS signatures
-func NewJWXVerifierFromJWK(id string, key PublicKeyJWK) (*Verifier, error) {
+func NewJWXVerifierFromJWK(id string, key PublicKeyJWK, opts ...VerifierOption) (*Verifier, error) {
 	gotJWK, alg, err := jwxVerifier(id, key)
 	if err != nil {
 		return nil, err
@@ -94,11 +151,11 @@ func NewJWXVerifierFromJWK(id string, key PublicKeyJWK) (*Verifier, error) {
 	if !IsSupportedJWXSigningVerificationAlgorithm(*alg) {
 		return nil, fmt.Errorf("unsupported signing/verification algorithm: %s", alg)
 	}
-	return &Verifier{ID: id, Key: gotJWK}, nil
+	return newVerifier(id, gotJWK, opts), nil
 }
 
 // NewJWXVerifierFromKey creates a new verifier from a public key to verify JWTs and JWS signatures
-func NewJWXVerifierFromKey(id string, key jwk.Key) (*Verifier, error) {
+func NewJWXVerifierFromKey(id string, key jwk.Key, opts ...VerifierOption) (*Verifier, error) {
 	gotJWK, alg, err := jwkVerifierFromKey(id, key)
 	if err != nil {
 		return nil, err
@@ -106,7 +163,15 @@ func NewJWXVerifierFromKey(id string, key jwk.Key) (*Verifier, error) {
 	if !IsSupportedJWXSigningVerificationAlgorithm(*alg) {
 		return nil, fmt.Errorf("unsupported signing algorithm: %s", alg)
 	}
-	return &Verifier{ID: id, Key: gotJWK}, nil
+	return newVerifier(id, gotJWK, opts), nil
+}
+
+func newVerifier(id string, key jwk.Key, opts []VerifierOption) *Verifier {
+	verifier := Verifier{ID: id, Key: key}
+	for _, opt := range opts {
+		opt(&verifier)
+	}
+	return &verifier
 }
 
 func jwxSigner(id, kid string, key PrivateKeyJWK) (jwk.Key, *jwa.SignatureAlgorithm, error) {
@@ -181,17 +246,100 @@ func (s *Signer) SignWithDefaults(kvs map[string]any) ([]byte, error) {
 			return nil, errors.Wrapf(err, "could not set %s to value: %v", k, v)
 		}
 	}
+	if s.keyStore != nil {
+		payload, err := json.Marshal(t)
+		if err != nil {
+			return nil, errors.Wrap(err, "marshalling JWT")
+		}
+		headers := jws.NewHeaders()
+		if err = headers.Set(jws.TypeKey, "JWT"); err != nil {
+			return nil, errors.Wrap(err, "setting typ header")
+		}
+		return s.signWithKeyStore(payload, headers, false)
+	}
 	return jwt.Sign(t, jwt.WithKey(s.SignatureAlgorithm, s.Key))
 }
 
+// SignWithHeaders signs the claims as a JWT with the given protected headers, which may include nonstandard headers
+// such as a typ of vc+jwt. The alg header is always the signer's algorithm, kid defaults to the signer's key ID, and typ
+// defaults to JWT. Claims are signed as provided, without validation or defaults, so unknown claims are preserved.
+func (s *Signer) SignWithHeaders(claims map[string]any, headers map[string]any) (string, error) {
+	if claims == nil {
+		claims = make(map[string]any)
+	}
+	payload, err := json.Marshal(claims)
+	if err != nil {
+		return "", errors.Wrap(err, "marshalling claims")
+	}
+
+	protected := jws.NewHeaders()
+	if kid := s.Key.KeyID(); kid != "" {
+		if err = protected.Set(jws.KeyIDKey, kid); err != nil {
+			return "", errors.Wrap(err, "setting kid header")
+		}
+	}
+	if err = protected.Set(jws.TypeKey, "JWT"); err != nil {
+		return "", errors.Wrap(err, "setting typ header")
+	}
+	for k, v := range headers {
+		if k == jws.AlgorithmKey {
+			if alg, ok := v.(string); !ok || alg != s.SignatureAlgorithm.String() {
+				return "", fmt.Errorf("alg header <%v> does not match signer algorithm: %s", v, s.SignatureAlgorithm)
+			}
+			continue
+		}
+		if err = protected.Set(k, v); err != nil {
+			return "", errors.Wrapf(err, "setting %s header", k)
+		}
+	}
+	if err = protected.Set(jws.AlgorithmKey, s.SignatureAlgorithm); err != nil {
+		return "", errors.Wrap(err, "setting algorithm header")
+	}
+	if s.keyStore != nil {
+		signed, err := s.signWithKeyStore(payload, protected, false)
+		if err != nil {
+			return "", err
+		}
+		return string(signed), nil
+	}
+
+	// our jwx library sets the kid header from the key, so remove it from a copy of the key to keep an overridden kid
+	key := s.Key
+	if protected.KeyID() != key.KeyID() {
+		if key, err = key.Clone(); err != nil {
+			return "", errors.Wrap(err, "copying signing key")
+		}
+		if err = key.Remove(jwk.KeyIDKey); err != nil {
+			return "", errors.Wrap(err, "removing kid from signing key")
+		}
+	}
+	signed, err := jws.Sign(payload, jws.WithKey(s.SignatureAlgorithm, key, jws.WithProtectedHeaders(protected)))
+	if err != nil {
+		return "", errors.Wrap(err, "signing JWT")
+	}
+	return string(signed), nil
+}
+
 // Verify parses a token given the verifier's known algorithm and key, and returns an error, which is nil upon success
 func (v *Verifier) Verify(token string) error {
-	if _, err := jwt.Parse([]byte(token), jwt.WithKey(v.Algorithm(), v.Key)); err != nil {
+	if err := v.checkCritical(token); err != nil {
+		return err
+	}
+	if _, err := jwt.Parse([]byte(token), v.parseOptions()...); err != nil {
 		return errors.Wrap(err, "could not verify JWT")
 	}
 	return nil
 }
 
+// parseOptions returns the options verifying a JWT with the verifier's key, and validating it against its clock
+func (v *Verifier) parseOptions() []jwt.ParseOption {
+	opts := []jwt.ParseOption{jwt.WithKey(v.Algorithm(), v.Key)}
+	if v.clock != nil {
+		opts = append(opts, jwt.WithClock(v.clock))
+	}
+	return opts
+}
+
 // Parse attempts to turn a string into a jwt.Token
 func (*Verifier) Parse(token string) (jws.Headers, jwt.Token, error) {
 	parsed, err := jwt.Parse([]byte(token), jwt.WithValidate(false), jwt.WithVerify(false))
@@ -207,7 +355,10 @@ func (*Verifier) Parse(token string) (jws.Headers, jwt.Token, error) {
 
 // VerifyAndParse attempts to turn a string into a jwt.Token and verify its signature using the verifier
 func (v *Verifier) VerifyAndParse(token string) (jws.Headers, jwt.Token, error) {
-	parsed, err := jwt.Parse([]byte(token), jwt.WithKey(v.Algorithm(), v.Key))
+	if err := v.checkCritical(token); err != nil {
+		return nil, nil, err
+	}
+	parsed, err := jwt.Parse([]byte(token), v.parseOptions()...)
 	if err != nil {
 		return nil, nil, errors.Wrap(err, "could not parse and verify JWT")
 	}
@@ -218,6 +369,28 @@ func (v *Verifier) VerifyAndParse(token string) (jws.Headers, jwt.Token, error)
 	return headers, parsed, nil
 }
 
+// VerifyWithHeaders verifies and validates a JWT using the verifier, returning its protected headers, so callers can
+// assert nonstandard headers such as typ, alongside all of its claims exactly as they were encoded
+func (v *Verifier) VerifyWithHeaders(token string) (jws.Headers, map[string]any, error) {
+	headers, _, err := v.VerifyAndParse(token)
+	if err != nil {
+		return nil, nil, err
+	}
+	parts := strings.Split(token, ".")
+	if len(parts) != 3 {
+		return nil, nil, errors.New("JWT must be in compact serialization")
+	}
+	payload, err := base64.RawURLEncoding.DecodeString(parts[1])
+	if err != nil {
+		return nil, nil, errors.Wrap(err, "decoding JWT payload")
+	}
+	var claims map[string]any
+	if err = json.Unmarshal(payload, &claims); err != nil {
+		return nil, nil, errors.Wrap(err, "unmarshalling JWT claims")
+	}
+	return headers, claims, nil
+}
+
 // AlgFromKeyAndCurve returns the supported JSON Web Algorithm for signing for a given key type and curve pair
 // The curve parameter is optional (e.g. "") as in the case of RSA.
 func AlgFromKeyAndCurve(kty jwa.KeyType, crv jwa.EllipticCurveAlgorithm) (jwa.SignatureAlgorithm, error) {
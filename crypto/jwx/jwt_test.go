@@ -2,8 +2,10 @@ package jwx
 
 import (
 	"testing"
+	"time"
 
 	"github.com/TBD54566975/ssi-sdk/crypto"
+	"github.com/TBD54566975/ssi-sdk/util"
 	"github.com/lestrrat-go/jwx/v2/jwt"
 	"github.com/stretchr/testify/assert"
 )
@@ -172,6 +174,108 @@ func TestSignVerifyGenericJWT(t *testing.T) {
 	assert.EqualValues(t, "did:example:123#key-0", jws.ProtectedHeaders().KeyID())
 }
 
+func TestVerifyWithClock(t *testing.T) {
+	signer := getTestVectorKey0Signer(t)
+	issued := time.Date(2020, time.January, 1, 0, 0, 0, 0, time.UTC)
+	token, err := signer.SignWithDefaults(map[string]any{
+		jwt.IssuedAtKey:   issued.Unix(),
+		jwt.NotBeforeKey:  issued.Unix(),
+		jwt.ExpirationKey: issued.AddDate(1, 0, 0).Unix(),
+	})
+	assert.NoError(t, err)
+
+	verifier, err := signer.ToVerifier(signer.ID)
+	assert.NoError(t, err)
+	assert.ErrorContains(t, verifier.Verify(string(token)), `"exp" not satisfied`)
+
+	clockVerifier, err := NewJWXVerifierFromKey(signer.ID, verifier.Key, WithClock(util.FixedClock(issued.AddDate(0, 6, 0))))
+	assert.NoError(t, err)
+	assert.NoError(t, clockVerifier.Verify(string(token)))
+	_, parsed, err := clockVerifier.VerifyAndParse(string(token))
+	assert.NoError(t, err)
+	assert.Equal(t, issued.AddDate(1, 0, 0), parsed.Expiration().UTC())
+
+	clockVerifier, err = NewJWXVerifierFromKey(signer.ID, verifier.Key, WithClock(util.FixedClock(issued.AddDate(0, 0, -1))))
+	assert.NoError(t, err)
+	assert.ErrorContains(t, clockVerifier.Verify(string(token)), "not satisfied")
+}
+
+func TestSignWithHeaders(t *testing.T) {
+	signer := getTestVectorKey0Signer(t)
+	verifier, err := signer.ToVerifier(signer.ID)
+	assert.NoError(t, err)
+
+	t.Run("custom headers and unknown claims round trip", func(tt *testing.T) {
+		claims := map[string]any{
+			"iss": "did:example:123",
+			"vc": map[string]any{
+				"type":              []any{"VerifiableCredential"},
+				"credentialSubject": map[string]any{"id": "did:example:456", "nested": map[string]any{"deep": true}},
+			},
+			"custom_claim": "custom value",
+			"numbers":      []any{1.5, float64(2)},
+		}
+		token, err := signer.SignWithHeaders(claims, map[string]any{"typ": "vc+jwt", "custom_header": "custom"})
+		assert.NoError(tt, err)
+
+		headers, gotClaims, err := verifier.VerifyWithHeaders(token)
+		assert.NoError(tt, err)
+		assert.Equal(tt, claims, gotClaims)
+		assert.EqualValues(tt, "EdDSA", headers.Algorithm())
+		assert.Equal(tt, "vc+jwt", headers.Type())
+		assert.Equal(tt, "did:example:123#key-0", headers.KeyID())
+		customHeader, ok := headers.Get("custom_header")
+		assert.True(tt, ok)
+		assert.Equal(tt, "custom", customHeader)
+	})
+
+	t.Run("defaults", func(tt *testing.T) {
+		token, err := signer.SignWithHeaders(nil, nil)
+		assert.NoError(tt, err)
+		headers, claims, err := verifier.VerifyWithHeaders(token)
+		assert.NoError(tt, err)
+		assert.Empty(tt, claims)
+		assert.Equal(tt, "JWT", headers.Type())
+		assert.Equal(tt, "did:example:123#key-0", headers.KeyID())
+	})
+
+	t.Run("kid can be overridden", func(tt *testing.T) {
+		token, err := signer.SignWithHeaders(map[string]any{"a": "b"}, map[string]any{"kid": "did:example:123#other"})
+		assert.NoError(tt, err)
+		headers, _, err := verifier.VerifyWithHeaders(token)
+		assert.NoError(tt, err)
+		assert.Equal(tt, "did:example:123#other", headers.KeyID())
+	})
+
+	t.Run("alg must match the signer", func(tt *testing.T) {
+		token, err := signer.SignWithHeaders(nil, map[string]any{"alg": "EdDSA"})
+		assert.NoError(tt, err)
+		assert.NotEmpty(tt, token)
+
+		_, err = signer.SignWithHeaders(nil, map[string]any{"alg": "none"})
+		assert.Error(tt, err)
+		assert.Contains(tt, err.Error(), "does not match signer algorithm")
+	})
+
+	t.Run("registered claims are validated", func(tt *testing.T) {
+		token, err := signer.SignWithHeaders(map[string]any{"exp": time.Now().Add(-time.Hour).Unix()}, nil)
+		assert.NoError(tt, err)
+		_, _, err = verifier.VerifyWithHeaders(token)
+		assert.Error(tt, err)
+	})
+
+	t.Run("bad signature", func(tt *testing.T) {
+		_, privKey, err := crypto.GenerateEd25519Key()
+		assert.NoError(tt, err)
+		otherSigner, err := NewJWXSigner("other", "did:example:123#key-0", privKey)
+		assert.NoError(tt, err)
+		token, err := otherSigner.SignWithHeaders(map[string]any{"a": "b"}, nil)
+		assert.NoError(tt, err)
+		_, _, err = verifier.VerifyWithHeaders(token)
+		assert.Error(tt, err)
+	})
+}
+
 func getTestVectorKey0Signer(t *testing.T) Signer {
 	// https://github.com/decentralized-identity/JWS-Test-Suite/blob/main/data/keys/key-0-ed25519.json
 	knownJWK := PrivateKeyJWK{
@@ -0,0 +1,97 @@
+package jwx
+
+import (
+	gocrypto "crypto"
+	"fmt"
+	"sync"
+
+	"github.com/TBD54566975/ssi-sdk/crypto"
+	"github.com/pkg/errors"
+)
+
+// KeyStore holds private keys and signs with them on behalf of callers, so that the keys are referenced by ID rather
+// than passed around. Implementations may keep keys in memory, or in an HSM or KMS.
+type KeyStore interface {
+	// Sign signs the payload with the key identified by the key ID, returning the signature in the form used by JWS
+	// for the key's algorithm (e.g. r||s for ECDSA)
+	Sign(keyID string, payload []byte) ([]byte, error)
+	// PublicKey returns the public key of the key identified by the key ID
+	PublicKey(keyID string) (PublicKeyJWK, error)
+}
+
+// InMemoryKeyStore is a KeyStore that holds its keys in a map in memory. It is safe for concurrent use.
+type InMemoryKeyStore struct {
+	mu   sync.RWMutex
+	keys map[string]inMemoryKey
+}
+
+type inMemoryKey struct {
+	keyType    crypto.KeyType
+	privateKey gocrypto.PrivateKey
+	publicKey  PublicKeyJWK
+}
+
+var _ KeyStore = (*InMemoryKeyStore)(nil)
+
+// NewInMemoryKeyStore creates a new, empty in-memory key store
+func NewInMemoryKeyStore() *InMemoryKeyStore {
+	return &InMemoryKeyStore{keys: make(map[string]inMemoryKey)}
+}
+
+// AddKey adds a signing private key to the store under the key ID, replacing any key already stored under it
+func (s *InMemoryKeyStore) AddKey(keyID string, key gocrypto.PrivateKey) error {
+	if keyID == "" {
+		return errors.New("key id cannot be empty")
+	}
+	if key == nil {
+		return errors.New("private key cannot be nil")
+	}
+	keyType, err := crypto.GetKeyTypeFromPrivateKey(key)
+	if err != nil {
+		return errors.Wrap(err, "getting key type of private key")
+	}
+	if keyType == crypto.X25519 {
+		return errors.New("X25519 keys are for key agreement and cannot sign")
+	}
+	publicKeyJWK, _, err := PrivateKeyToPrivateKeyJWK(key)
+	if err != nil {
+		return errors.Wrap(err, "converting private key to JWK")
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.keys[keyID] = inMemoryKey{keyType: keyType, privateKey: key, publicKey: *publicKeyJWK}
+	return nil
+}
+
+// Sign signs the payload with the key identified by the key ID
+func (s *InMemoryKeyStore) Sign(keyID string, payload []byte) ([]byte, error) {
+	key, err := s.getKey(keyID)
+	if err != nil {
+		return nil, err
+	}
+	signature, err := crypto.Sign(key.keyType, key.privateKey, payload)
+	if err != nil {
+		return nil, errors.Wrapf(err, "signing with key<%s>", keyID)
+	}
+	return signature, nil
+}
+
+// PublicKey returns the public key of the key identified by the key ID
+func (s *InMemoryKeyStore) PublicKey(keyID string) (PublicKeyJWK, error) {
+	key, err := s.getKey(keyID)
+	if err != nil {
+		return PublicKeyJWK{}, err
+	}
+	return key.publicKey, nil
+}
+
+func (s *InMemoryKeyStore) getKey(keyID string) (*inMemoryKey, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	key, ok := s.keys[keyID]
+	if !ok {
+		return nil, fmt.Errorf("key<%s> not found", keyID)
+	}
+	return &key, nil
+}
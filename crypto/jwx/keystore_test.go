@@ -0,0 +1,111 @@
+package jwx
+
+import (
+	"testing"
+
+	"github.com/TBD54566975/ssi-sdk/crypto"
+	"github.com/goccy/go-json"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestInMemoryKeyStore(t *testing.T) {
+	t.Run("sign and verify with each supported key type", func(tt *testing.T) {
+		for _, kt := range []crypto.KeyType{crypto.Ed25519, crypto.SECP256k1, crypto.P256, crypto.P384, crypto.RSA} {
+			pubKey, privKey, err := crypto.GenerateKeyByKeyType(kt)
+			require.NoError(tt, err)
+
+			store := NewInMemoryKeyStore()
+			require.NoError(tt, store.AddKey("key-1", privKey))
+
+			storedJWK, err := store.PublicKey("key-1")
+			require.NoError(tt, err)
+			pubKeyJWK, err := PublicKeyToPublicKeyJWK(pubKey)
+			require.NoError(tt, err)
+			assert.Equal(tt, *pubKeyJWK, storedJWK)
+
+			signer, err := NewJWXSignerFromKeyStore("did:example:123", "did:example:123#key-1", store, "key-1")
+			require.NoError(tt, err, kt)
+			verifier, err := signer.ToVerifier("did:example:456")
+			require.NoError(tt, err)
+
+			token, err := signer.SignWithDefaults(map[string]any{"test": "data"})
+			require.NoError(tt, err, kt)
+			headers, parsed, err := verifier.VerifyAndParse(string(token))
+			require.NoError(tt, err, kt)
+			assert.Equal(tt, "did:example:123#key-1", headers.KeyID())
+			assert.Equal(tt, "JWT", headers.Type())
+			assert.Equal(tt, "did:example:123", parsed.Issuer())
+			claim, ok := parsed.Get("test")
+			assert.True(tt, ok)
+			assert.Equal(tt, "data", claim)
+
+			jwt, err := signer.SignWithHeaders(map[string]any{"test": "data"}, map[string]any{"typ": "vc+jwt"})
+			require.NoError(tt, err, kt)
+			headers, claims, err := verifier.VerifyWithHeaders(jwt)
+			require.NoError(tt, err, kt)
+			assert.Equal(tt, "vc+jwt", headers.Type())
+			assert.Equal(tt, "data", claims["test"])
+
+			payload := []byte(`{"hello":"world"}`)
+			signed, err := signer.SignJWS(payload)
+			require.NoError(tt, err, kt)
+			assert.NoError(tt, verifier.VerifyJWS(string(signed)), kt)
+
+			detached, err := signer.SignDetached(payload)
+			require.NoError(tt, err, kt)
+			assert.NoError(tt, VerifyDetached(storedJWK, detached, payload), kt)
+		}
+	})
+
+	t.Run("signer never exposes the private key", func(tt *testing.T) {
+		_, privKey, err := crypto.GenerateEd25519Key()
+		require.NoError(tt, err)
+		store := NewInMemoryKeyStore()
+		require.NoError(tt, store.AddKey("key-1", privKey))
+
+		signer, err := NewJWXSignerFromKeyStore("did:example:123", "did:example:123#key-1", store, "key-1")
+		require.NoError(tt, err)
+		assert.Equal(tt, "EdDSA", signer.GetSigningAlgorithm())
+
+		keyBytes, err := json.Marshal(signer.Key)
+		require.NoError(tt, err)
+		var members map[string]any
+		require.NoError(tt, json.Unmarshal(keyBytes, &members))
+		assert.NotContains(tt, members, "d")
+
+		signerBytes, err := json.Marshal(signer)
+		require.NoError(tt, err)
+		assert.NotContains(tt, string(signerBytes), `"d"`)
+	})
+
+	t.Run("errors", func(tt *testing.T) {
+		store := NewInMemoryKeyStore()
+		_, privKey, err := crypto.GenerateEd25519Key()
+		require.NoError(tt, err)
+
+		assert.Error(tt, store.AddKey("", privKey))
+		assert.Error(tt, store.AddKey("key-1", nil))
+
+		_, err = store.Sign("missing", []byte("payload"))
+		assert.Error(tt, err)
+		assert.Contains(tt, err.Error(), "key<missing> not found")
+
+		_, err = store.PublicKey("missing")
+		assert.Error(tt, err)
+
+		_, err = NewJWXSignerFromKeyStore("did:example:123", "did:example:123#key-1", store, "missing")
+		assert.Error(tt, err)
+		assert.Contains(tt, err.Error(), "getting public key<missing> from key store")
+
+		_, err = NewJWXSignerFromKeyStore("did:example:123", "did:example:123#key-1", nil, "key-1")
+		assert.Error(tt, err)
+		assert.Contains(tt, err.Error(), "key store cannot be nil")
+
+		_, x25519Key, err := crypto.GenerateX25519Key()
+		require.NoError(tt, err)
+		err = store.AddKey("x25519", x25519Key)
+		assert.Error(tt, err)
+		assert.Contains(tt, err.Error(), "cannot sign")
+	})
+}
@@ -7,29 +7,47 @@ import (
 	"crypto/elliptic"
 	"crypto/rand"
 	"crypto/rsa"
+	"crypto/sha512"
+	"crypto/subtle"
 	"crypto/x509"
 	"fmt"
+	"io"
+	"math/big"
 	"reflect"
 
 	"github.com/btcsuite/btcd/btcec/v2"
+	"github.com/cloudflare/circl/sign/ed448"
 	"github.com/pkg/errors"
 
 	secp "github.com/decred/dcrd/dcrec/secp256k1/v4"
+	bbsg2 "github.com/hyperledger/aries-framework-go/pkg/crypto/primitive/bbs12381g2pub"
 
 	"github.com/lestrrat-go/jwx/v2/x25519"
 )
 
 // GenerateKeyByKeyType creates a brand-new key, returning the public and private key for the given key type
 func GenerateKeyByKeyType(kt KeyType) (crypto.PublicKey, crypto.PrivateKey, error) {
+	return GenerateKeyByKeyTypeWithReader(kt, rand.Reader)
+}
+
+// GenerateKeyByKeyTypeWithReader creates a key from the entropy read from r, returning the public and private key
+// for the given key type. Reading the same entropy yields the same key, which is useful for reproducible fixtures,
+// so r must only be a deterministic source in tests or when deriving keys from a secret seed.
+func GenerateKeyByKeyTypeWithReader(kt KeyType, r io.Reader) (crypto.PublicKey, crypto.PrivateKey, error) {
+	if r == nil {
+		return nil, nil, errors.New("reader cannot be nil")
+	}
 	switch kt {
 	case Ed25519:
-		return GenerateEd25519Key()
+		return ed25519.GenerateKey(r)
+	case Ed448:
+		return ed448.GenerateKey(r)
 	case X25519:
-		return GenerateX25519Key()
+		return x25519.GenerateKey(r)
 	case SECP256k1:
-		return GenerateSECP256k1Key()
+		return generateSECP256k1Key(r)
 	case SECP256k1ECDSA:
-		secpPub, secpPriv, err := GenerateSECP256k1Key()
+		secpPub, secpPriv, err := generateSECP256k1Key(r)
 		if err == nil {
 			ecdsaPub := secpPub.ToECDSA()
 			ecdsaPriv := secpPriv.ToECDSA()
@@ -37,15 +55,21 @@ func GenerateKeyByKeyType(kt KeyType) (crypto.PublicKey, crypto.PrivateKey, erro
 		}
 		return nil, nil, err
 	case P224:
-		return GenerateP224Key()
+		return generateECDSAKey(elliptic.P224(), r)
 	case P256:
-		return GenerateP256Key()
+		return generateECDSAKey(elliptic.P256(), r)
 	case P384:
-		return GenerateP384Key()
+		return generateECDSAKey(elliptic.P384(), r)
 	case P521:
-		return GenerateP521Key()
+		return generateECDSAKey(elliptic.P521(), r)
 	case RSA:
-		return GenerateRSA2048Key()
+		return generateRSAKey(r, RSAKeySize)
+	case BLS12381G2:
+		pubKey, privKey, err := GenerateBBSKeyPairFromReader(r)
+		if err != nil {
+			return nil, nil, err
+		}
+		return *pubKey, *privKey, nil
 	}
 	return nil, nil, fmt.Errorf("unsupported key type: %s", kt)
 }
@@ -61,6 +85,11 @@ func PubKeyToBytes(key crypto.PublicKey) ([]byte, error) {
 		return ed25519Key, nil
 	}
 
+	ed448Key, ok := key.(ed448.PublicKey)
+	if ok {
+		return ed448Key, nil
+	}
+
 	x25519Key, ok := key.(x25519.PublicKey)
 	if ok {
 		return x25519Key, nil
@@ -82,6 +111,11 @@ func PubKeyToBytes(key crypto.PublicKey) ([]byte, error) {
 		return x509.MarshalPKCS1PublicKey(&rsaKey), nil
 	}
 
+	blsKey, ok := key.(bbsg2.PublicKey)
+	if ok {
+		return blsKey.Marshal()
+	}
+
 	return nil, errors.New("unknown public key type; could not convert to bytes")
 }
 
@@ -91,6 +125,8 @@ func BytesToPubKey(keyBytes []byte, kt KeyType) (crypto.PublicKey, error) {
 	switch kt {
 	case Ed25519, X25519:
 		return ed25519.PublicKey(keyBytes), nil
+	case Ed448:
+		return ed448.PublicKey(keyBytes), nil
 	case SECP256k1:
 		pubKey, err := secp.ParsePubKey(keyBytes)
 		if err != nil {
@@ -138,11 +174,129 @@ func BytesToPubKey(keyBytes []byte, kt KeyType) (crypto.PublicKey, error) {
 			return nil, err
 		}
 		return *pubKey, nil
+	case BLS12381G2:
+		pubKey, err := bbsg2.UnmarshalPublicKey(keyBytes)
+		if err != nil {
+			return nil, err
+		}
+		return *pubKey, nil
+	default:
+		return nil, fmt.Errorf("unsupported key type: %s", kt)
+	}
+}
+
+// ParseECPublicKey reconstructs an elliptic curve public key from its SEC1 encoding, which is either compressed
+// (0x02 or 0x03 prefix) or uncompressed (0x04 prefix). Compressed points are decompressed, and points that are not on
+// the curve are rejected. secp256k1 keys are returned as secp256k1 public keys, and all others as ECDSA public keys.
+// https://www.secg.org/sec1-v2.pdf#subsubsection.2.3.4
+func ParseECPublicKey(kt KeyType, b []byte) (crypto.PublicKey, error) {
+	if len(b) == 0 {
+		return nil, errors.New("public key bytes cannot be empty")
+	}
+	switch kt {
+	case SECP256k1, SECP256k1ECDSA:
+		if b[0] != 0x02 && b[0] != 0x03 && b[0] != 0x04 {
+			return nil, fmt.Errorf("invalid point format: %#x", b[0])
+		}
+		pubKey, err := secp.ParsePubKey(b)
+		if err != nil {
+			return nil, errors.Wrapf(err, "parsing %s public key", kt)
+		}
+		if kt == SECP256k1ECDSA {
+			return *pubKey.ToECDSA(), nil
+		}
+		return *pubKey, nil
+	case P224, P256, P384, P521:
+		curve := ellipticCurveForKeyType(kt)
+		var x, y *big.Int
+		switch b[0] {
+		case 0x02, 0x03:
+			x, y = elliptic.UnmarshalCompressed(curve, b)
+		case 0x04:
+			x, y = elliptic.Unmarshal(curve, b)
+		default:
+			return nil, fmt.Errorf("invalid point format: %#x", b[0])
+		}
+		// both unmarshal functions reject points of the wrong length or that are not on the curve
+		if x == nil {
+			return nil, fmt.Errorf("invalid %s public key", kt)
+		}
+		return ecdsa.PublicKey{Curve: curve, X: x, Y: y}, nil
 	default:
 		return nil, fmt.Errorf("unsupported key type: %s", kt)
 	}
 }
 
+// PublicKeysEqual checks whether two public keys are the same key, comparing their encoded bytes in constant time.
+// Keys of different types, including secp256k1 keys represented as both secp256k1 and ECDSA keys, are not equal.
+func PublicKeysEqual(a, b crypto.PublicKey) bool {
+	a, aOK := dereferencePublicKey(a)
+	b, bOK := dereferencePublicKey(b)
+	if !aOK || !bOK {
+		return false
+	}
+	switch aKey := a.(type) {
+	case ed25519.PublicKey:
+		bKey, ok := b.(ed25519.PublicKey)
+		return ok && len(aKey) > 0 && subtle.ConstantTimeCompare(aKey, bKey) == 1
+	case ed448.PublicKey:
+		bKey, ok := b.(ed448.PublicKey)
+		return ok && len(aKey) > 0 && subtle.ConstantTimeCompare(aKey, bKey) == 1
+	case x25519.PublicKey:
+		bKey, ok := b.(x25519.PublicKey)
+		return ok && len(aKey) > 0 && subtle.ConstantTimeCompare(aKey, bKey) == 1
+	case secp.PublicKey:
+		bKey, ok := b.(secp.PublicKey)
+		return ok && subtle.ConstantTimeCompare(aKey.SerializeUncompressed(), bKey.SerializeUncompressed()) == 1
+	case ecdsa.PublicKey:
+		bKey, ok := b.(ecdsa.PublicKey)
+		if !ok || aKey.Curve == nil || aKey.Curve != bKey.Curve {
+			return false
+		}
+		if aKey.X == nil || aKey.Y == nil || bKey.X == nil || bKey.Y == nil {
+			return false
+		}
+		size := (aKey.Curve.Params().BitSize + 7) / 8
+		if aKey.X.BitLen() > size*8 || aKey.Y.BitLen() > size*8 || bKey.X.BitLen() > size*8 || bKey.Y.BitLen() > size*8 {
+			return false
+		}
+		aBytes := append(aKey.X.FillBytes(make([]byte, size)), aKey.Y.FillBytes(make([]byte, size))...)
+		bBytes := append(bKey.X.FillBytes(make([]byte, size)), bKey.Y.FillBytes(make([]byte, size))...)
+		return subtle.ConstantTimeCompare(aBytes, bBytes) == 1
+	case rsa.PublicKey:
+		bKey, ok := b.(rsa.PublicKey)
+		if !ok || aKey.N == nil || bKey.N == nil {
+			return false
+		}
+		return subtle.ConstantTimeCompare(aKey.N.Bytes(), bKey.N.Bytes()) == 1 && aKey.E == bKey.E
+	case bbsg2.PublicKey:
+		bKey, ok := b.(bbsg2.PublicKey)
+		if !ok || aKey.PointG2 == nil || bKey.PointG2 == nil {
+			return false
+		}
+		aBytes, aErr := aKey.Marshal()
+		bBytes, bErr := bKey.Marshal()
+		return aErr == nil && bErr == nil && subtle.ConstantTimeCompare(aBytes, bBytes) == 1
+	default:
+		return false
+	}
+}
+
+// dereferencePublicKey dereferences a pointer to a public key, returning false for nil keys
+func dereferencePublicKey(key crypto.PublicKey) (crypto.PublicKey, bool) {
+	if key == nil {
+		return nil, false
+	}
+	v := reflect.ValueOf(key)
+	if v.Kind() == reflect.Ptr {
+		if v.IsNil() {
+			return nil, false
+		}
+		return v.Elem().Interface().(crypto.PublicKey), true
+	}
+	return key, true
+}
+
 // GetKeyTypeFromPrivateKey returns the key type of a private key for known key types
 func GetKeyTypeFromPrivateKey(key crypto.PrivateKey) (KeyType, error) {
 	// dereference the ptr
@@ -152,6 +306,9 @@ func GetKeyTypeFromPrivateKey(key crypto.PrivateKey) (KeyType, error) {
 	if _, ok := key.(ed25519.PrivateKey); ok {
 		return Ed25519, nil
 	}
+	if _, ok := key.(ed448.PrivateKey); ok {
+		return Ed448, nil
+	}
 	if _, ok := key.(x25519.PrivateKey); ok {
 		return X25519, nil
 	}
@@ -177,6 +334,9 @@ func GetKeyTypeFromPrivateKey(key crypto.PrivateKey) (KeyType, error) {
 	if _, ok := key.(rsa.PrivateKey); ok {
 		return RSA, nil
 	}
+	if _, ok := key.(bbsg2.PrivateKey); ok {
+		return BLS12381G2, nil
+	}
 	return "", errors.New("unknown private key type")
 }
 
@@ -191,6 +351,11 @@ func PrivKeyToBytes(key crypto.PrivateKey) ([]byte, error) {
 		return ed25519Key, nil
 	}
 
+	ed448Key, ok := key.(ed448.PrivateKey)
+	if ok {
+		return ed448Key, nil
+	}
+
 	x25519Key, ok := key.(x25519.PrivateKey)
 	if ok {
 		return x25519Key, nil
@@ -217,6 +382,11 @@ func PrivKeyToBytes(key crypto.PrivateKey) ([]byte, error) {
 		return x509.MarshalPKCS1PrivateKey(&rsaKey), nil
 	}
 
+	blsKey, ok := key.(bbsg2.PrivateKey)
+	if ok {
+		return blsKey.Marshal()
+	}
+
 	return nil, errors.New("unknown private key type; could not convert to bytes")
 }
 
@@ -226,6 +396,8 @@ func BytesToPrivKey(keyBytes []byte, kt KeyType) (crypto.PrivateKey, error) {
 	switch kt {
 	case Ed25519:
 		return ed25519.PrivateKey(keyBytes), nil
+	case Ed448:
+		return ed448.PrivateKey(keyBytes), nil
 	case X25519:
 		return x25519.PrivateKey(keyBytes), nil
 	case SECP256k1:
@@ -245,6 +417,12 @@ func BytesToPrivKey(keyBytes []byte, kt KeyType) (crypto.PrivateKey, error) {
 			return nil, err
 		}
 		return *privKey, nil
+	case BLS12381G2:
+		privKey, err := bbsg2.UnmarshalPrivateKey(keyBytes)
+		if err != nil {
+			return nil, err
+		}
+		return *privKey, nil
 	default:
 		return nil, fmt.Errorf("unsupported key type: %s", kt)
 	}
@@ -258,8 +436,66 @@ func GenerateX25519Key() (x25519.PublicKey, x25519.PrivateKey, error) {
 	return x25519.GenerateKey(rand.Reader)
 }
 
+// curve25519P is the prime 2^255 - 19 over which both the Ed25519 and X25519 curves are defined
+var curve25519P = new(big.Int).Sub(new(big.Int).Lsh(big.NewInt(1), 255), big.NewInt(19))
+
+// Ed25519PublicKeyToX25519 converts an Ed25519 public key to its birationally equivalent X25519 public key,
+// computing the Montgomery u-coordinate u = (1 + y) / (1 - y) from the Edwards y-coordinate.
+// https://www.rfc-editor.org/rfc/rfc7748#section-4.1
+func Ed25519PublicKeyToX25519(key ed25519.PublicKey) (x25519.PublicKey, error) {
+	if len(key) != ed25519.PublicKeySize {
+		return nil, fmt.Errorf("invalid ed25519 public key size: %d", len(key))
+	}
+
+	// the key is the little-endian y-coordinate, with the sign of x in the most significant bit
+	yBytes := make([]byte, ed25519.PublicKeySize)
+	for i := range key {
+		yBytes[ed25519.PublicKeySize-1-i] = key[i]
+	}
+	yBytes[0] &= 0x7f
+	y := new(big.Int).SetBytes(yBytes)
+	if y.Cmp(curve25519P) >= 0 {
+		return nil, errors.New("invalid ed25519 public key: y-coordinate out of range")
+	}
+
+	one := big.NewInt(1)
+	denominator := new(big.Int).Sub(one, y)
+	denominator.Mod(denominator, curve25519P)
+	if denominator.Sign() == 0 {
+		return nil, errors.New("invalid ed25519 public key: cannot convert point to x25519")
+	}
+	u := new(big.Int).Add(one, y)
+	u.Mul(u, new(big.Int).ModInverse(denominator, curve25519P))
+	u.Mod(u, curve25519P)
+
+	uBytes := u.FillBytes(make([]byte, x25519.PublicKeySize))
+	x25519Key := make(x25519.PublicKey, x25519.PublicKeySize)
+	for i := range uBytes {
+		x25519Key[x25519.PublicKeySize-1-i] = uBytes[i]
+	}
+	return x25519Key, nil
+}
+
+// Ed25519PrivateKeyToX25519 converts an Ed25519 private key to its corresponding X25519 private key, which is the
+// clamped first half of the SHA-512 hash of the Ed25519 seed.
+// https://www.rfc-editor.org/rfc/rfc8032#section-5.1.5
+func Ed25519PrivateKeyToX25519(key ed25519.PrivateKey) (x25519.PrivateKey, error) {
+	if len(key) != ed25519.PrivateKeySize {
+		return nil, fmt.Errorf("invalid ed25519 private key size: %d", len(key))
+	}
+	digest := sha512.Sum512(key.Seed())
+	digest[0] &= 248
+	digest[31] &= 127
+	digest[31] |= 64
+	return x25519.NewKeyFromSeed(digest[:x25519.SeedSize])
+}
+
 func GenerateSECP256k1Key() (secp.PublicKey, secp.PrivateKey, error) {
-	privKey, err := secp.GeneratePrivateKey()
+	return generateSECP256k1Key(rand.Reader)
+}
+
+func generateSECP256k1Key(r io.Reader) (secp.PublicKey, secp.PrivateKey, error) {
+	privKey, err := secp.GeneratePrivateKeyFromRand(r)
 	if err != nil {
 		return secp.PublicKey{}, secp.PrivateKey{}, err
 	}
@@ -268,33 +504,136 @@ func GenerateSECP256k1Key() (secp.PublicKey, secp.PrivateKey, error) {
 }
 
 func GenerateP224Key() (ecdsa.PublicKey, ecdsa.PrivateKey, error) {
-	return generateECDSAKey(elliptic.P224())
+	return generateECDSAKey(elliptic.P224(), rand.Reader)
 }
 
 func GenerateP256Key() (ecdsa.PublicKey, ecdsa.PrivateKey, error) {
-	return generateECDSAKey(elliptic.P256())
+	return generateECDSAKey(elliptic.P256(), rand.Reader)
 }
 
 func GenerateP384Key() (ecdsa.PublicKey, ecdsa.PrivateKey, error) {
-	return generateECDSAKey(elliptic.P384())
+	return generateECDSAKey(elliptic.P384(), rand.Reader)
 }
 
 func GenerateP521Key() (ecdsa.PublicKey, ecdsa.PrivateKey, error) {
-	return generateECDSAKey(elliptic.P521())
+	return generateECDSAKey(elliptic.P521(), rand.Reader)
 }
 
-func generateECDSAKey(curve elliptic.Curve) (ecdsa.PublicKey, ecdsa.PrivateKey, error) {
-	privKey, err := ecdsa.GenerateKey(curve, rand.Reader)
-	if err != nil {
-		return ecdsa.PublicKey{}, ecdsa.PrivateKey{}, err
+// generateECDSAKey generates a key on the curve from the entropy read from r. The standard library's generator
+// consumes a random amount of entropy, so for other readers the key is derived here to be reproducible.
+func generateECDSAKey(curve elliptic.Curve, r io.Reader) (ecdsa.PublicKey, ecdsa.PrivateKey, error) {
+	if r == rand.Reader {
+		privKey, err := ecdsa.GenerateKey(curve, r)
+		if err != nil {
+			return ecdsa.PublicKey{}, ecdsa.PrivateKey{}, err
+		}
+		return privKey.PublicKey, *privKey, nil
+	}
+
+	// derive the private scalar from 64 bits more entropy than the order, as per FIPS 186-4 B.4.1
+	n := curve.Params().N
+	entropy := make([]byte, (n.BitLen()+64)/8)
+	if _, err := io.ReadFull(r, entropy); err != nil {
+		return ecdsa.PublicKey{}, ecdsa.PrivateKey{}, errors.Wrap(err, "reading entropy")
+	}
+	nMinusOne := new(big.Int).Sub(n, big.NewInt(1))
+	d := new(big.Int).SetBytes(entropy)
+	d.Mod(d, nMinusOne)
+	d.Add(d, big.NewInt(1))
+
+	x, y := curve.ScalarBaseMult(d.FillBytes(make([]byte, (n.BitLen()+7)/8)))
+	privKey := ecdsa.PrivateKey{
+		PublicKey: ecdsa.PublicKey{Curve: curve, X: x, Y: y},
+		D:         d,
 	}
-	return privKey.PublicKey, *privKey, nil
+	return privKey.PublicKey, privKey, nil
 }
 
 func GenerateRSA2048Key() (rsa.PublicKey, rsa.PrivateKey, error) {
-	privKey, err := rsa.GenerateKey(rand.Reader, RSAKeySize)
-	if err != nil {
-		return rsa.PublicKey{}, rsa.PrivateKey{}, err
+	return generateRSAKey(rand.Reader, RSAKeySize)
+}
+
+// rsaPublicExponent is the public exponent of generated RSA keys, as used by the standard library
+const rsaPublicExponent = 65537
+
+// generateRSAKey generates a two-prime RSA key of the given size from the entropy read from r. The standard
+// library's generator consumes a random amount of entropy, so for other readers the key is derived here to be
+// reproducible.
+func generateRSAKey(r io.Reader, bits int) (rsa.PublicKey, rsa.PrivateKey, error) {
+	if r == rand.Reader {
+		privKey, err := rsa.GenerateKey(r, bits)
+		if err != nil {
+			return rsa.PublicKey{}, rsa.PrivateKey{}, err
+		}
+		return privKey.PublicKey, *privKey, nil
+	}
+
+	e := big.NewInt(rsaPublicExponent)
+	one := big.NewInt(1)
+	for {
+		p, err := generatePrime(r, bits-bits/2)
+		if err != nil {
+			return rsa.PublicKey{}, rsa.PrivateKey{}, err
+		}
+		q, err := generatePrime(r, bits/2)
+		if err != nil {
+			return rsa.PublicKey{}, rsa.PrivateKey{}, err
+		}
+		if p.Cmp(q) == 0 {
+			continue
+		}
+		n := new(big.Int).Mul(p, q)
+		if n.BitLen() != bits {
+			continue
+		}
+		phi := new(big.Int).Mul(new(big.Int).Sub(p, one), new(big.Int).Sub(q, one))
+		d := new(big.Int).ModInverse(e, phi)
+		if d == nil {
+			// e is not coprime with phi, so try other primes
+			continue
+		}
+
+		privKey := rsa.PrivateKey{
+			PublicKey: rsa.PublicKey{N: n, E: rsaPublicExponent},
+			D:         d,
+			Primes:    []*big.Int{p, q},
+		}
+		privKey.Precompute()
+		if err = privKey.Validate(); err != nil {
+			return rsa.PublicKey{}, rsa.PrivateKey{}, errors.Wrap(err, "validating rsa key")
+		}
+		return privKey.PublicKey, privKey, nil
+	}
+}
+
+// generatePrime returns a prime of the given size from the entropy read from r, with its top two bits set so the
+// product of two such primes has twice the size
+func generatePrime(r io.Reader, bits int) (*big.Int, error) {
+	if bits < 2 {
+		return nil, errors.New("prime size must be at least 2 bits")
+	}
+	topBits := uint(bits % 8)
+	if topBits == 0 {
+		topBits = 8
+	}
+	candidate := make([]byte, (bits+7)/8)
+	p := new(big.Int)
+	for {
+		if _, err := io.ReadFull(r, candidate); err != nil {
+			return nil, errors.Wrap(err, "reading entropy")
+		}
+		// clear the bits above the size, then set the top two bits and make the candidate odd
+		candidate[0] &= uint8(int(1<<topBits) - 1)
+		if topBits >= 2 {
+			candidate[0] |= 3 << (topBits - 2)
+		} else {
+			candidate[0] |= 1
+			candidate[1] |= 0x80
+		}
+		candidate[len(candidate)-1] |= 1
+		p.SetBytes(candidate)
+		if p.ProbablyPrime(20) {
+			return p, nil
+		}
 	}
-	return privKey.PublicKey, *privKey, nil
 }
@@ -1,9 +1,22 @@
 package crypto
 
 import (
+	"bytes"
+	gocrypto "crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	mathrand "math/rand"
 	"testing"
 
+	"github.com/lestrrat-go/jwx/v2/x25519"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	secp "github.com/decred/dcrd/dcrec/secp256k1/v4"
 )
 
 func TestKeyToBytes(t *testing.T) {
@@ -71,3 +84,206 @@ func TestKeyToBytes(t *testing.T) {
 		})
 	}
 }
+
+func TestGenerateKeyByKeyTypeWithReader(t *testing.T) {
+	for _, keyType := range GetSupportedKeyTypes() {
+		t.Run(string(keyType), func(tt *testing.T) {
+			pub, priv, err := GenerateKeyByKeyTypeWithReader(keyType, mathrand.New(mathrand.NewSource(1)))
+			assert.NoError(tt, err)
+			assert.NotEmpty(tt, pub)
+			assert.NotEmpty(tt, priv)
+
+			// the same seed yields the same key
+			samePub, samePriv, err := GenerateKeyByKeyTypeWithReader(keyType, mathrand.New(mathrand.NewSource(1)))
+			assert.NoError(tt, err)
+			assert.EqualValues(tt, pub, samePub)
+			assert.EqualValues(tt, priv, samePriv)
+
+			// a different seed yields a different key
+			otherPub, _, err := GenerateKeyByKeyTypeWithReader(keyType, mathrand.New(mathrand.NewSource(2)))
+			assert.NoError(tt, err)
+			assert.NotEqualValues(tt, pub, otherPub)
+
+			kt, err := GetKeyTypeFromPrivateKey(priv)
+			assert.NoError(tt, err)
+			assert.Equal(tt, keyType, kt)
+		})
+	}
+
+	t.Run("generated keys are valid", func(tt *testing.T) {
+		_, priv, err := GenerateKeyByKeyTypeWithReader(P256, mathrand.New(mathrand.NewSource(1)))
+		assert.NoError(tt, err)
+		ecdsaKey := priv.(ecdsa.PrivateKey)
+		assert.True(tt, ecdsaKey.Curve.IsOnCurve(ecdsaKey.X, ecdsaKey.Y))
+		digest := sha256.Sum256([]byte("hello world"))
+		sig, err := ecdsa.SignASN1(rand.Reader, &ecdsaKey, digest[:])
+		assert.NoError(tt, err)
+		assert.True(tt, ecdsa.VerifyASN1(&ecdsaKey.PublicKey, digest[:], sig))
+
+		_, priv, err = GenerateKeyByKeyTypeWithReader(RSA, mathrand.New(mathrand.NewSource(1)))
+		assert.NoError(tt, err)
+		rsaKey := priv.(rsa.PrivateKey)
+		assert.Equal(tt, RSAKeySize, rsaKey.N.BitLen())
+		assert.NoError(tt, rsaKey.Validate())
+		sig, err = rsa.SignPKCS1v15(rand.Reader, &rsaKey, gocrypto.SHA256, digest[:])
+		assert.NoError(tt, err)
+		assert.NoError(tt, rsa.VerifyPKCS1v15(&rsaKey.PublicKey, gocrypto.SHA256, digest[:], sig))
+	})
+
+	t.Run("errors", func(tt *testing.T) {
+		_, _, err := GenerateKeyByKeyTypeWithReader(Ed25519, nil)
+		assert.Error(tt, err)
+
+		_, _, err = GenerateKeyByKeyTypeWithReader(P256, bytes.NewReader([]byte{1, 2, 3}))
+		assert.Error(tt, err)
+
+		_, _, err = GenerateKeyByKeyTypeWithReader("unsupported", rand.Reader)
+		assert.Error(tt, err)
+	})
+}
+
+func TestParseECPublicKey(t *testing.T) {
+	for _, kt := range []KeyType{SECP256k1, SECP256k1ECDSA, P224, P256, P384, P521} {
+		t.Run(string(kt), func(tt *testing.T) {
+			pubKey, _, err := GenerateKeyByKeyType(kt)
+			require.NoError(tt, err)
+
+			var ecdsaPubKey ecdsa.PublicKey
+			if secpPubKey, ok := pubKey.(secp.PublicKey); ok {
+				ecdsaPubKey = *secpPubKey.ToECDSA()
+			} else {
+				ecdsaPubKey = pubKey.(ecdsa.PublicKey)
+			}
+
+			uncompressed := elliptic.Marshal(ecdsaPubKey.Curve, ecdsaPubKey.X, ecdsaPubKey.Y)
+			parsed, err := ParseECPublicKey(kt, uncompressed)
+			assert.NoError(tt, err)
+			assert.Equal(tt, pubKey, parsed)
+
+			compressed := elliptic.MarshalCompressed(ecdsaPubKey.Curve, ecdsaPubKey.X, ecdsaPubKey.Y)
+			parsed, err = ParseECPublicKey(kt, compressed)
+			assert.NoError(tt, err)
+			assert.Equal(tt, pubKey, parsed)
+
+			// flipping the parity of a compressed point yields the negated point
+			flipped := append([]byte{}, compressed...)
+			flipped[0] ^= 0x01
+			parsed, err = ParseECPublicKey(kt, flipped)
+			assert.NoError(tt, err)
+			assert.NotEqual(tt, pubKey, parsed)
+
+			offCurve := append([]byte{}, uncompressed...)
+			offCurve[len(offCurve)-1] ^= 0x01
+			_, err = ParseECPublicKey(kt, offCurve)
+			assert.Error(tt, err)
+
+			_, err = ParseECPublicKey(kt, compressed[:len(compressed)-1])
+			assert.Error(tt, err)
+
+			badPrefix := append([]byte{}, uncompressed...)
+			badPrefix[0] = 0x05
+			_, err = ParseECPublicKey(kt, badPrefix)
+			assert.Error(tt, err)
+			assert.Contains(tt, err.Error(), "invalid point format")
+		})
+	}
+
+	t.Run("unsupported input", func(tt *testing.T) {
+		_, err := ParseECPublicKey(P256, nil)
+		assert.Error(tt, err)
+
+		pubKey, _, err := GenerateEd25519Key()
+		require.NoError(tt, err)
+		_, err = ParseECPublicKey(Ed25519, pubKey)
+		assert.Error(tt, err)
+		assert.Contains(tt, err.Error(), "unsupported key type: Ed25519")
+	})
+}
+
+func TestPublicKeysEqual(t *testing.T) {
+	for _, kt := range GetSupportedKeyTypes() {
+		t.Run(string(kt), func(tt *testing.T) {
+			pubKey, _, err := GenerateKeyByKeyType(kt)
+			require.NoError(tt, err)
+			otherPubKey, _, err := GenerateKeyByKeyType(kt)
+			require.NoError(tt, err)
+
+			assert.True(tt, PublicKeysEqual(pubKey, pubKey))
+			assert.True(tt, PublicKeysEqual(pubKey, &pubKey))
+			assert.False(tt, PublicKeysEqual(pubKey, otherPubKey))
+
+			// a copy reconstructed from bytes is equal
+			keyBytes, err := PubKeyToBytes(pubKey)
+			require.NoError(tt, err)
+			copied, err := BytesToPubKey(keyBytes, kt)
+			require.NoError(tt, err)
+			// X25519 bytes are reconstructed as an Ed25519 key, so they are of a different type
+			if kt != X25519 {
+				assert.True(tt, PublicKeysEqual(pubKey, copied))
+			}
+		})
+	}
+
+	t.Run("mismatched types", func(tt *testing.T) {
+		edPubKey, _, err := GenerateEd25519Key()
+		require.NoError(tt, err)
+		xPubKey, err := Ed25519PublicKeyToX25519(edPubKey)
+		require.NoError(tt, err)
+		p256PubKey, _, err := GenerateP256Key()
+		require.NoError(tt, err)
+		secpPubKey, _, err := GenerateSECP256k1Key()
+		require.NoError(tt, err)
+
+		assert.False(tt, PublicKeysEqual(edPubKey, xPubKey))
+		assert.False(tt, PublicKeysEqual(edPubKey, p256PubKey))
+		assert.False(tt, PublicKeysEqual(p256PubKey, edPubKey))
+		assert.False(tt, PublicKeysEqual(secpPubKey, *secpPubKey.ToECDSA()))
+
+		// keys with the same coordinates on different curves differ
+		p384PubKey := ecdsa.PublicKey{Curve: elliptic.P384(), X: p256PubKey.X, Y: p256PubKey.Y}
+		assert.False(tt, PublicKeysEqual(p256PubKey, p384PubKey))
+	})
+
+	t.Run("rsa exponent", func(tt *testing.T) {
+		pubKey, _, err := GenerateRSA2048Key()
+		require.NoError(tt, err)
+		otherExponent := pubKey
+		otherExponent.E = 3
+		assert.False(tt, PublicKeysEqual(pubKey, otherExponent))
+	})
+
+	t.Run("empty keys", func(tt *testing.T) {
+		var nilPtr *ecdsa.PublicKey
+		assert.False(tt, PublicKeysEqual(nil, nil))
+		assert.False(tt, PublicKeysEqual(nilPtr, nilPtr))
+		assert.False(tt, PublicKeysEqual(ed25519.PublicKey{}, ed25519.PublicKey{}))
+		assert.False(tt, PublicKeysEqual(ecdsa.PublicKey{}, ecdsa.PublicKey{}))
+		assert.False(tt, PublicKeysEqual(rsa.PublicKey{}, rsa.PublicKey{}))
+		assert.False(tt, PublicKeysEqual("not a key", "not a key"))
+	})
+}
+
+func TestEd25519ToX25519(t *testing.T) {
+	t.Run("converted keys form a key pair", func(tt *testing.T) {
+		pub, priv, err := GenerateEd25519Key()
+		assert.NoError(tt, err)
+
+		x25519Pub, err := Ed25519PublicKeyToX25519(pub)
+		assert.NoError(tt, err)
+		assert.Len(tt, x25519Pub, x25519.PublicKeySize)
+
+		x25519Priv, err := Ed25519PrivateKeyToX25519(priv)
+		assert.NoError(tt, err)
+		assert.Equal(tt, x25519Pub, x25519Priv.Public())
+	})
+
+	t.Run("invalid key sizes", func(tt *testing.T) {
+		_, err := Ed25519PublicKeyToX25519(ed25519.PublicKey{1, 2, 3})
+		assert.Error(tt, err)
+		assert.Contains(tt, err.Error(), "invalid ed25519 public key size")
+
+		_, err = Ed25519PrivateKeyToX25519(ed25519.PrivateKey{1, 2, 3})
+		assert.Error(tt, err)
+		assert.Contains(tt, err.Error(), "invalid ed25519 private key size")
+	})
+}
@@ -9,6 +9,7 @@ type (
 
 const (
 	Ed25519        KeyType = "Ed25519"
+	Ed448          KeyType = "Ed448"
 	X25519         KeyType = "X25519"
 	SECP256k1      KeyType = "secp256k1"
 	SECP256k1ECDSA KeyType = "secp256k1-ECDSA"
@@ -17,6 +18,8 @@ const (
 	P384           KeyType = "P-384"
 	P521           KeyType = "P-521"
 	RSA            KeyType = "RSA"
+	// BLS12381G2 is a BLS12-381 key in G2, used for BBS+ signatures
+	BLS12381G2 KeyType = "BLS12381G2"
 
 	RSAKeySize int = 2048
 )
@@ -53,7 +56,7 @@ func IsSupportedKeyType(kt KeyType) bool {
 }
 
 func GetSupportedKeyTypes() []KeyType {
-	return []KeyType{Ed25519, X25519, SECP256k1, SECP256k1ECDSA, P224, P256, P384, P521, RSA}
+	return []KeyType{Ed25519, Ed448, X25519, SECP256k1, SECP256k1ECDSA, P224, P256, P384, P521, RSA, BLS12381G2}
 }
 
 func IsSupportedSignatureAlg(sa SignatureAlgorithm) bool {
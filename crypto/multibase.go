@@ -0,0 +1,76 @@
+package crypto
+
+import (
+	"encoding/base32"
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+
+	"github.com/mr-tron/base58"
+	"github.com/pkg/errors"
+)
+
+// MultibaseEncoding is the prefix character identifying the base encoding of a multibase string
+// https://datatracker.ietf.org/doc/html/draft-multiformats-multibase
+type MultibaseEncoding byte
+
+const (
+	// Base58BTCMultibase is base58 with the Bitcoin alphabet, used by did:key, did:peer, and data integrity proofs
+	Base58BTCMultibase MultibaseEncoding = 'z'
+	// Base64URLMultibase is base64 with the URL and filename safe alphabet, without padding
+	Base64URLMultibase MultibaseEncoding = 'u'
+	// Base16Multibase is lowercase hexadecimal
+	Base16Multibase MultibaseEncoding = 'f'
+	// Base32Multibase is lowercase base32 without padding
+	Base32Multibase MultibaseEncoding = 'b'
+)
+
+var base32Encoding = base32.NewEncoding("abcdefghijklmnopqrstuvwxyz234567").WithPadding(base32.NoPadding)
+
+// MultibaseEncode encodes the data with the given encoding, prefixed by the character identifying the encoding
+func MultibaseEncode(encoding MultibaseEncoding, data []byte) (string, error) {
+	var encoded string
+	switch encoding {
+	case Base58BTCMultibase:
+		encoded = base58.Encode(data)
+	case Base64URLMultibase:
+		encoded = base64.RawURLEncoding.EncodeToString(data)
+	case Base16Multibase:
+		encoded = hex.EncodeToString(data)
+	case Base32Multibase:
+		encoded = base32Encoding.EncodeToString(data)
+	default:
+		return "", fmt.Errorf("unsupported multibase encoding: %q", encoding)
+	}
+	return string(encoding) + encoded, nil
+}
+
+// MultibaseDecode decodes a multibase string, returning the encoding identified by its leading prefix character
+// along with the decoded data
+func MultibaseDecode(s string) (MultibaseEncoding, []byte, error) {
+	if s == "" {
+		return 0, nil, errors.New("multibase string cannot be empty")
+	}
+	encoding, encoded := MultibaseEncoding(s[0]), s[1:]
+	var decoded []byte
+	var err error
+	switch encoding {
+	case Base58BTCMultibase:
+		// the base58 library rejects empty strings, which encode empty data
+		if encoded != "" {
+			decoded, err = base58.Decode(encoded)
+		}
+	case Base64URLMultibase:
+		decoded, err = base64.RawURLEncoding.DecodeString(encoded)
+	case Base16Multibase:
+		decoded, err = hex.DecodeString(encoded)
+	case Base32Multibase:
+		decoded, err = base32Encoding.DecodeString(encoded)
+	default:
+		return 0, nil, fmt.Errorf("unsupported multibase encoding: %q", encoding)
+	}
+	if err != nil {
+		return 0, nil, errors.Wrapf(err, "decoding %q multibase string", encoding)
+	}
+	return encoding, decoded, nil
+}
@@ -0,0 +1,67 @@
+package crypto
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMultibase(t *testing.T) {
+	data := []byte("Decentralize everything!!")
+
+	t.Run("round trips each encoding", func(tt *testing.T) {
+		// https://github.com/multiformats/multibase/blob/master/tests/test1.csv
+		vectors := []struct {
+			encoding MultibaseEncoding
+			encoded  string
+		}{
+			{encoding: Base58BTCMultibase, encoded: "zUXE7GvtEk8XTXs1GF8HSGbVA9FCX9SEBPe"},
+			{encoding: Base64URLMultibase, encoded: "uRGVjZW50cmFsaXplIGV2ZXJ5dGhpbmchIQ"},
+			{encoding: Base16Multibase, encoded: "f446563656e7472616c697a652065766572797468696e672121"},
+			{encoding: Base32Multibase, encoded: "birswgzloorzgc3djpjssazlwmvzhs5dinfxgoijb"},
+		}
+		for _, v := range vectors {
+			encoded, err := MultibaseEncode(v.encoding, data)
+			require.NoError(tt, err)
+			assert.Equal(tt, v.encoded, encoded)
+
+			encoding, decoded, err := MultibaseDecode(encoded)
+			require.NoError(tt, err)
+			assert.Equal(tt, v.encoding, encoding)
+			assert.Equal(tt, data, decoded)
+		}
+	})
+
+	t.Run("empty data", func(tt *testing.T) {
+		for _, encoding := range []MultibaseEncoding{Base58BTCMultibase, Base64URLMultibase, Base16Multibase, Base32Multibase} {
+			encoded, err := MultibaseEncode(encoding, nil)
+			require.NoError(tt, err)
+			assert.Equal(tt, string(encoding), encoded)
+
+			gotEncoding, decoded, err := MultibaseDecode(encoded)
+			require.NoError(tt, err)
+			assert.Equal(tt, encoding, gotEncoding)
+			assert.Empty(tt, decoded)
+		}
+	})
+
+	t.Run("unsupported encoding", func(tt *testing.T) {
+		_, err := MultibaseEncode('m', data)
+		assert.ErrorContains(tt, err, "unsupported multibase encoding")
+
+		_, _, err = MultibaseDecode("mRGVjZW50cmFsaXplIGV2ZXJ5dGhpbmchIQ")
+		assert.ErrorContains(tt, err, "unsupported multibase encoding")
+	})
+
+	t.Run("invalid strings", func(tt *testing.T) {
+		_, _, err := MultibaseDecode("")
+		assert.Error(tt, err)
+
+		_, _, err = MultibaseDecode("z0OIl")
+		assert.Error(tt, err)
+
+		_, _, err = MultibaseDecode("fzz")
+		assert.Error(tt, err)
+	})
+}
@@ -0,0 +1,46 @@
+package crypto
+
+import (
+	"encoding/binary"
+
+	"github.com/pkg/errors"
+)
+
+// MulticodecCode identifies the type of multicodec tagged data, such as the key type of public key bytes
+// https://github.com/multiformats/multicodec
+type MulticodecCode uint64
+
+// Multicodec reference https://github.com/multiformats/multicodec/blob/master/table.csv
+const (
+	Ed25519Multicodec    MulticodecCode = 0xed
+	X25519Multicodec     MulticodecCode = 0xec
+	SECP256k1Multicodec  MulticodecCode = 0xe7
+	BLS12381G2Multicodec MulticodecCode = 0xeb
+	P256Multicodec       MulticodecCode = 0x1200
+	P384Multicodec       MulticodecCode = 0x1201
+	P521Multicodec       MulticodecCode = 0x1202
+	Ed448Multicodec      MulticodecCode = 0x1203
+	RSAMulticodec        MulticodecCode = 0x1205
+)
+
+// MulticodecWrap tags the data with the code, prefixing it with the code as an unsigned varint
+func MulticodecWrap(code MulticodecCode, data []byte) []byte {
+	wrapped := binary.AppendUvarint(make([]byte, 0, binary.MaxVarintLen64+len(data)), uint64(code))
+	return append(wrapped, data...)
+}
+
+// MulticodecUnwrap splits multicodec tagged data into its code and the data it tags
+func MulticodecUnwrap(b []byte) (MulticodecCode, []byte, error) {
+	code, n := binary.Uvarint(b)
+	if n == 0 {
+		return 0, nil, errors.New("multicodec varint is truncated")
+	}
+	if n < 0 {
+		return 0, nil, errors.New("multicodec varint overflows 64 bits")
+	}
+	// varints must be minimally encoded, so that each code has a single prefix
+	if n != len(binary.AppendUvarint(nil, code)) {
+		return 0, nil, errors.New("multicodec varint is not minimally encoded")
+	}
+	return MulticodecCode(code), b[n:], nil
+}
@@ -0,0 +1,48 @@
+package crypto
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMulticodec(t *testing.T) {
+	key := []byte("public key bytes")
+
+	t.Run("round trips single and two byte codes", func(tt *testing.T) {
+		vectors := []struct {
+			code   MulticodecCode
+			prefix []byte
+		}{
+			{code: Ed25519Multicodec, prefix: []byte{0xed, 0x01}},
+			{code: X25519Multicodec, prefix: []byte{0xec, 0x01}},
+			{code: 0x12, prefix: []byte{0x12}},
+			{code: P256Multicodec, prefix: []byte{0x80, 0x24}},
+			{code: RSAMulticodec, prefix: []byte{0x85, 0x24}},
+		}
+		for _, v := range vectors {
+			wrapped := MulticodecWrap(v.code, key)
+			assert.Equal(tt, append(v.prefix, key...), wrapped)
+
+			code, unwrapped, err := MulticodecUnwrap(wrapped)
+			require.NoError(tt, err)
+			assert.Equal(tt, v.code, code)
+			assert.Equal(tt, key, unwrapped)
+		}
+	})
+
+	t.Run("invalid varints", func(tt *testing.T) {
+		_, _, err := MulticodecUnwrap(nil)
+		assert.ErrorContains(tt, err, "truncated")
+
+		_, _, err = MulticodecUnwrap([]byte{0x80})
+		assert.ErrorContains(tt, err, "truncated")
+
+		_, _, err = MulticodecUnwrap([]byte{0xed, 0x81, 0x00})
+		assert.ErrorContains(tt, err, "not minimally encoded")
+
+		_, _, err = MulticodecUnwrap([]byte{0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0x01})
+		assert.ErrorContains(tt, err, "overflows")
+	})
+}
@@ -0,0 +1,364 @@
+package crypto
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rsa"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/asn1"
+	"encoding/pem"
+	"fmt"
+	"reflect"
+
+	"github.com/cloudflare/circl/sign/ed448"
+	"github.com/lestrrat-go/jwx/v2/x25519"
+	"github.com/pkg/errors"
+
+	secp "github.com/decred/dcrd/dcrec/secp256k1/v4"
+	bbsg2 "github.com/hyperledger/aries-framework-go/pkg/crypto/primitive/bbs12381g2pub"
+)
+
+const (
+	// PEM block types for keys in PKCS#8 and SPKI form, as well as the legacy SEC1 and PKCS#1 forms
+	pemPrivateKeyType    = "PRIVATE KEY"
+	pemPublicKeyType     = "PUBLIC KEY"
+	pemECPrivateKeyType  = "EC PRIVATE KEY"
+	pemRSAPrivateKeyType = "RSA PRIVATE KEY"
+
+	// PEM block types for keys that have no standard PKCS#8 or SPKI form, which hold the raw key bytes
+	pemSECP256k1PrivateKeyType  = "SECP256K1 PRIVATE KEY"
+	pemSECP256k1PublicKeyType   = "SECP256K1 PUBLIC KEY"
+	pemBLS12381G2PrivateKeyType = "BLS12381G2 PRIVATE KEY"
+	pemBLS12381G2PublicKeyType  = "BLS12381G2 PUBLIC KEY"
+)
+
+var (
+	// https://www.rfc-editor.org/rfc/rfc8410#section-3
+	oidX25519 = asn1.ObjectIdentifier{1, 3, 101, 110}
+	oidEd448  = asn1.ObjectIdentifier{1, 3, 101, 113}
+)
+
+// pkcs8 is the PKCS#8 private key structure, used for the RFC 8410 keys the x509 package does not support
+// https://www.rfc-editor.org/rfc/rfc5208#section-5
+type pkcs8 struct {
+	Version    int
+	Algo       pkix.AlgorithmIdentifier
+	PrivateKey []byte
+}
+
+// subjectPublicKeyInfo is the SPKI public key structure, used for the RFC 8410 keys the x509 package does not support
+// https://www.rfc-editor.org/rfc/rfc5280#section-4.1
+type subjectPublicKeyInfo struct {
+	Algorithm pkix.AlgorithmIdentifier
+	PublicKey asn1.BitString
+}
+
+// PrivateKeyToPEM encodes a private key of the given key type as PEM. Keys are encoded as PKCS#8 where a standard
+// encoding exists, which covers Ed25519, Ed448, X25519, the NIST curves, and RSA. secp256k1 and BLS12381G2 keys are
+// encoded as their raw bytes in a custom block type.
+func PrivateKeyToPEM(kt KeyType, key crypto.PrivateKey) ([]byte, error) {
+	if key == nil {
+		return nil, errors.New("private key cannot be nil")
+	}
+	// dereference the ptr
+	if reflect.ValueOf(key).Kind() == reflect.Ptr {
+		key = reflect.ValueOf(key).Elem().Interface().(crypto.PrivateKey)
+	}
+	keyType, err := GetKeyTypeFromPrivateKey(key)
+	if err != nil {
+		return nil, err
+	}
+	if !sameKeyType(kt, keyType) {
+		return nil, fmt.Errorf("private key is not of type %s", kt)
+	}
+
+	block := pem.Block{Type: pemPrivateKeyType}
+	switch kt {
+	case Ed25519, P224, P256, P384, P521, RSA:
+		block.Bytes, err = marshalPKCS8PrivateKey(key)
+	case X25519:
+		block.Bytes, err = marshalRFC8410PrivateKey(oidX25519, key.(x25519.PrivateKey).Seed())
+	case Ed448:
+		block.Bytes, err = marshalRFC8410PrivateKey(oidEd448, key.(ed448.PrivateKey).Seed())
+	case SECP256k1, SECP256k1ECDSA:
+		block.Type = pemSECP256k1PrivateKeyType
+		block.Bytes, err = PrivKeyToBytes(key)
+	case BLS12381G2:
+		block.Type = pemBLS12381G2PrivateKeyType
+		block.Bytes, err = PrivKeyToBytes(key)
+	default:
+		return nil, fmt.Errorf("unsupported key type: %s", kt)
+	}
+	if err != nil {
+		return nil, errors.Wrapf(err, "encoding %s private key", kt)
+	}
+	return pem.EncodeToMemory(&block), nil
+}
+
+// PrivateKeyFromPEM decodes a PEM encoded private key, detecting and returning its key type. In addition to the
+// encodings of PrivateKeyToPEM, SEC1 EC and PKCS#1 RSA private keys are accepted. secp256k1 keys are always returned
+// as the SECP256k1 key type.
+func PrivateKeyFromPEM(b []byte) (KeyType, crypto.PrivateKey, error) {
+	block, _ := pem.Decode(b)
+	if block == nil {
+		return "", nil, errors.New("no PEM block found")
+	}
+
+	var key crypto.PrivateKey
+	var err error
+	switch block.Type {
+	case pemPrivateKeyType:
+		key, err = parsePKCS8PrivateKey(block.Bytes)
+	case pemECPrivateKeyType:
+		key, err = x509.ParseECPrivateKey(block.Bytes)
+	case pemRSAPrivateKeyType:
+		key, err = x509.ParsePKCS1PrivateKey(block.Bytes)
+	case pemSECP256k1PrivateKeyType:
+		if len(block.Bytes) != secp.PrivKeyBytesLen {
+			return "", nil, fmt.Errorf("invalid secp256k1 private key size: %d", len(block.Bytes))
+		}
+		return SECP256k1, *secp.PrivKeyFromBytes(block.Bytes), nil
+	case pemBLS12381G2PrivateKeyType:
+		key, err = BytesToPrivKey(block.Bytes, BLS12381G2)
+	default:
+		return "", nil, fmt.Errorf("unsupported PEM block type: %s", block.Type)
+	}
+	if err != nil {
+		return "", nil, errors.Wrap(err, "parsing private key")
+	}
+
+	// dereference the ptr
+	if reflect.ValueOf(key).Kind() == reflect.Ptr {
+		key = reflect.ValueOf(key).Elem().Interface().(crypto.PrivateKey)
+	}
+	kt, err := GetKeyTypeFromPrivateKey(key)
+	if err != nil {
+		return "", nil, err
+	}
+	return kt, key, nil
+}
+
+// PublicKeyToPEM encodes a public key of the given key type as PEM. Keys are encoded as SPKI where a standard
+// encoding exists, which covers Ed25519, Ed448, X25519, the NIST curves, and RSA. secp256k1 keys are encoded as their
+// compressed point and BLS12381G2 keys as their raw bytes in a custom block type.
+func PublicKeyToPEM(kt KeyType, key crypto.PublicKey) ([]byte, error) {
+	if key == nil {
+		return nil, errors.New("public key cannot be nil")
+	}
+	// dereference the ptr
+	if reflect.ValueOf(key).Kind() == reflect.Ptr {
+		key = reflect.ValueOf(key).Elem().Interface().(crypto.PublicKey)
+	}
+	keyType, err := getKeyTypeFromPublicKey(key)
+	if err != nil {
+		return nil, err
+	}
+	if !sameKeyType(kt, keyType) {
+		return nil, fmt.Errorf("public key is not of type %s", kt)
+	}
+
+	block := pem.Block{Type: pemPublicKeyType}
+	switch kt {
+	case Ed25519, P224, P256, P384, P521, RSA:
+		block.Bytes, err = marshalPKIXPublicKey(key)
+	case X25519:
+		block.Bytes, err = marshalRFC8410PublicKey(oidX25519, key.(x25519.PublicKey))
+	case Ed448:
+		block.Bytes, err = marshalRFC8410PublicKey(oidEd448, key.(ed448.PublicKey))
+	case SECP256k1, SECP256k1ECDSA:
+		block.Type = pemSECP256k1PublicKeyType
+		if ecdsaKey, ok := key.(ecdsa.PublicKey); ok {
+			block.Bytes = elliptic.MarshalCompressed(ecdsaKey.Curve, ecdsaKey.X, ecdsaKey.Y)
+		} else {
+			block.Bytes, err = PubKeyToBytes(key)
+		}
+	case BLS12381G2:
+		block.Type = pemBLS12381G2PublicKeyType
+		block.Bytes, err = PubKeyToBytes(key)
+	default:
+		return nil, fmt.Errorf("unsupported key type: %s", kt)
+	}
+	if err != nil {
+		return nil, errors.Wrapf(err, "encoding %s public key", kt)
+	}
+	return pem.EncodeToMemory(&block), nil
+}
+
+// PublicKeyFromPEM decodes a PEM encoded public key, detecting and returning its key type. secp256k1 keys are always
+// returned as the SECP256k1 key type.
+func PublicKeyFromPEM(b []byte) (KeyType, crypto.PublicKey, error) {
+	block, _ := pem.Decode(b)
+	if block == nil {
+		return "", nil, errors.New("no PEM block found")
+	}
+
+	var key crypto.PublicKey
+	var err error
+	switch block.Type {
+	case pemPublicKeyType:
+		key, err = parsePKIXPublicKey(block.Bytes)
+	case pemSECP256k1PublicKeyType:
+		key, err = ParseECPublicKey(SECP256k1, block.Bytes)
+	case pemBLS12381G2PublicKeyType:
+		key, err = BytesToPubKey(block.Bytes, BLS12381G2)
+	default:
+		return "", nil, fmt.Errorf("unsupported PEM block type: %s", block.Type)
+	}
+	if err != nil {
+		return "", nil, errors.Wrap(err, "parsing public key")
+	}
+
+	// dereference the ptr
+	if reflect.ValueOf(key).Kind() == reflect.Ptr {
+		key = reflect.ValueOf(key).Elem().Interface().(crypto.PublicKey)
+	}
+	kt, err := getKeyTypeFromPublicKey(key)
+	if err != nil {
+		return "", nil, err
+	}
+	return kt, key, nil
+}
+
+// sameKeyType checks whether two key types are the same, treating both secp256k1 key types as equivalent
+func sameKeyType(a, b KeyType) bool {
+	isSECP256k1 := func(kt KeyType) bool { return kt == SECP256k1 || kt == SECP256k1ECDSA }
+	return a == b || (isSECP256k1(a) && isSECP256k1(b))
+}
+
+// getKeyTypeFromPublicKey returns the key type of a public key for known key types
+func getKeyTypeFromPublicKey(key crypto.PublicKey) (KeyType, error) {
+	switch k := key.(type) {
+	case ed25519.PublicKey:
+		return Ed25519, nil
+	case ed448.PublicKey:
+		return Ed448, nil
+	case x25519.PublicKey:
+		return X25519, nil
+	case secp.PublicKey:
+		return SECP256k1, nil
+	case ecdsa.PublicKey:
+		switch k.Curve {
+		case elliptic.P224():
+			return P224, nil
+		case elliptic.P256():
+			return P256, nil
+		case elliptic.P384():
+			return P384, nil
+		case elliptic.P521():
+			return P521, nil
+		case secp.S256():
+			return SECP256k1ECDSA, nil
+		default:
+			return "", fmt.Errorf("unsupported curve: %s", k.Curve)
+		}
+	case rsa.PublicKey:
+		return RSA, nil
+	case bbsg2.PublicKey:
+		return BLS12381G2, nil
+	default:
+		return "", errors.New("unknown public key type")
+	}
+}
+
+// marshalPKCS8PrivateKey passes pointers for the key types the x509 package expects as pointers
+func marshalPKCS8PrivateKey(key crypto.PrivateKey) ([]byte, error) {
+	switch k := key.(type) {
+	case ecdsa.PrivateKey:
+		return x509.MarshalPKCS8PrivateKey(&k)
+	case rsa.PrivateKey:
+		return x509.MarshalPKCS8PrivateKey(&k)
+	default:
+		return x509.MarshalPKCS8PrivateKey(k)
+	}
+}
+
+// marshalPKIXPublicKey passes pointers for the key types the x509 package expects as pointers
+func marshalPKIXPublicKey(key crypto.PublicKey) ([]byte, error) {
+	switch k := key.(type) {
+	case ecdsa.PublicKey:
+		return x509.MarshalPKIXPublicKey(&k)
+	case rsa.PublicKey:
+		return x509.MarshalPKIXPublicKey(&k)
+	default:
+		return x509.MarshalPKIXPublicKey(k)
+	}
+}
+
+// marshalRFC8410PrivateKey encodes the private key as PKCS#8, where the private key is the DER encoded seed
+// https://www.rfc-editor.org/rfc/rfc8410#section-7
+func marshalRFC8410PrivateKey(oid asn1.ObjectIdentifier, seed []byte) ([]byte, error) {
+	curvePrivateKey, err := asn1.Marshal(seed)
+	if err != nil {
+		return nil, err
+	}
+	return asn1.Marshal(pkcs8{
+		Algo:       pkix.AlgorithmIdentifier{Algorithm: oid},
+		PrivateKey: curvePrivateKey,
+	})
+}
+
+// marshalRFC8410PublicKey encodes the public key as SPKI
+// https://www.rfc-editor.org/rfc/rfc8410#section-4
+func marshalRFC8410PublicKey(oid asn1.ObjectIdentifier, key []byte) ([]byte, error) {
+	return asn1.Marshal(subjectPublicKeyInfo{
+		Algorithm: pkix.AlgorithmIdentifier{Algorithm: oid},
+		PublicKey: asn1.BitString{Bytes: key, BitLength: 8 * len(key)},
+	})
+}
+
+// parsePKCS8PrivateKey parses the RFC 8410 keys the x509 package does not support, deferring to it for all others
+func parsePKCS8PrivateKey(der []byte) (crypto.PrivateKey, error) {
+	var privKey pkcs8
+	if rest, err := asn1.Unmarshal(der, &privKey); err != nil {
+		return nil, err
+	} else if len(rest) > 0 {
+		return nil, errors.New("trailing data after PKCS#8 private key")
+	}
+
+	oid := privKey.Algo.Algorithm
+	if !oid.Equal(oidX25519) && !oid.Equal(oidEd448) {
+		return x509.ParsePKCS8PrivateKey(der)
+	}
+	var seed []byte
+	if rest, err := asn1.Unmarshal(privKey.PrivateKey, &seed); err != nil {
+		return nil, errors.Wrap(err, "parsing curve private key")
+	} else if len(rest) > 0 {
+		return nil, errors.New("trailing data after curve private key")
+	}
+	if oid.Equal(oidX25519) {
+		return x25519.NewKeyFromSeed(seed)
+	}
+	if len(seed) != ed448.SeedSize {
+		return nil, fmt.Errorf("invalid ed448 private key seed size: %d", len(seed))
+	}
+	return ed448.NewKeyFromSeed(seed), nil
+}
+
+// parsePKIXPublicKey parses the RFC 8410 keys the x509 package does not support, deferring to it for all others
+func parsePKIXPublicKey(der []byte) (crypto.PublicKey, error) {
+	var pubKey subjectPublicKeyInfo
+	if rest, err := asn1.Unmarshal(der, &pubKey); err != nil {
+		return nil, err
+	} else if len(rest) > 0 {
+		return nil, errors.New("trailing data after SPKI public key")
+	}
+
+	oid := pubKey.Algorithm.Algorithm
+	switch {
+	case oid.Equal(oidX25519):
+		if len(pubKey.PublicKey.Bytes) != x25519.PublicKeySize {
+			return nil, fmt.Errorf("invalid x25519 public key size: %d", len(pubKey.PublicKey.Bytes))
+		}
+		return x25519.PublicKey(pubKey.PublicKey.Bytes), nil
+	case oid.Equal(oidEd448):
+		if len(pubKey.PublicKey.Bytes) != ed448.PublicKeySize {
+			return nil, fmt.Errorf("invalid ed448 public key size: %d", len(pubKey.PublicKey.Bytes))
+		}
+		return ed448.PublicKey(pubKey.PublicKey.Bytes), nil
+	default:
+		return x509.ParsePKIXPublicKey(der)
+	}
+}
@@ -0,0 +1,301 @@
+package crypto
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	// registers the SHA-224 and SHA-256 hashes used by hashPayload
+	_ "crypto/sha256"
+	"fmt"
+	"math/big"
+	"reflect"
+
+	"github.com/cloudflare/circl/sign/ed448"
+	"github.com/pkg/errors"
+
+	secp "github.com/decred/dcrd/dcrec/secp256k1/v4"
+	secpecdsa "github.com/decred/dcrd/dcrec/secp256k1/v4/ecdsa"
+)
+
+// SignOption configures how Sign signs a payload
+type SignOption func(*signOptions)
+
+type signOptions struct {
+	deterministicNonce bool
+}
+
+// WithDeterministicNonce is a SignOption deriving the nonce of ECDSA signatures from the private key and the payload's
+// hash as specified by RFC 6979, rather than generating it randomly, so the same key and payload always produce the
+// same signature. secp256k1 signatures are deterministic regardless.
+// When built with Go 1.24 or later, P-curve nonces are generated and used by the standard library's constant-time
+// implementation. Older toolchains fall back to math/big arithmetic, which is not constant-time and may leak bits of
+// the nonce, and so of the private key, through timing; only use this option there when signing cannot be observed.
+// https://www.rfc-editor.org/rfc/rfc6979
+func WithDeterministicNonce() SignOption {
+	return func(o *signOptions) {
+		o.deterministicNonce = true
+	}
+}
+
+// Sign signs the payload with the private key using the raw signature algorithm for the given key type:
+// EdDSA for Ed25519 and Ed448, ECDSA with the hash matching the curve for P-curves and secp256k1, and RSASSA-PSS with
+// SHA-256 for RSA. ECDSA signatures are the fixed-width concatenation r||s, as used by JOSE, rather than ASN.1, and
+// secp256k1 signatures are deterministic and low-S normalized. P-curve signatures use a random nonce unless
+// WithDeterministicNonce is given.
+// https://www.rfc-editor.org/rfc/rfc7518#section-3.4
+func Sign(kt KeyType, privKey crypto.PrivateKey, payload []byte, opts ...SignOption) ([]byte, error) {
+	if privKey == nil {
+		return nil, errors.New("private key cannot be nil")
+	}
+	var options signOptions
+	for _, opt := range opts {
+		opt(&options)
+	}
+	// dereference the ptr
+	if reflect.ValueOf(privKey).Kind() == reflect.Ptr {
+		privKey = reflect.ValueOf(privKey).Elem().Interface().(crypto.PrivateKey)
+	}
+	switch kt {
+	case Ed25519:
+		edKey, ok := privKey.(ed25519.PrivateKey)
+		if !ok {
+			return nil, fmt.Errorf("private key is not of type %s", kt)
+		}
+		if len(edKey) != ed25519.PrivateKeySize {
+			return nil, fmt.Errorf("invalid ed25519 private key size: %d", len(edKey))
+		}
+		return ed25519.Sign(edKey, payload), nil
+	case Ed448:
+		edKey, ok := privKey.(ed448.PrivateKey)
+		if !ok {
+			return nil, fmt.Errorf("private key is not of type %s", kt)
+		}
+		return SignEd448(edKey, payload)
+	case SECP256k1, SECP256k1ECDSA:
+		secpKey, err := toSECP256k1PrivateKey(privKey)
+		if err != nil {
+			return nil, errors.Wrapf(err, "private key is not of type %s", kt)
+		}
+		hash, err := hashPayload(crypto.SHA256, payload)
+		if err != nil {
+			return nil, err
+		}
+		// the compact format is a recovery code followed by r||s, where s is normalized to be at most half the curve
+		// order as required by BIP 62
+		compact := secpecdsa.SignCompact(secpKey, hash, true)
+		return compact[1:], nil
+	case P224, P256, P384, P521:
+		ecdsaKey, ok := privKey.(ecdsa.PrivateKey)
+		if !ok || ecdsaKey.Curve != ellipticCurveForKeyType(kt) {
+			return nil, fmt.Errorf("private key is not of type %s", kt)
+		}
+		hash, err := hashPayload(hashForKeyType(kt), payload)
+		if err != nil {
+			return nil, err
+		}
+		var r, s *big.Int
+		if options.deterministicNonce {
+			r, s, err = signECDSADeterministic(&ecdsaKey, hashForKeyType(kt), hash)
+		} else {
+			r, s, err = ecdsa.Sign(rand.Reader, &ecdsaKey, hash)
+		}
+		if err != nil {
+			return nil, errors.Wrap(err, "signing payload")
+		}
+		size := (ecdsaKey.Curve.Params().BitSize + 7) / 8
+		sig := make([]byte, 2*size)
+		r.FillBytes(sig[:size])
+		s.FillBytes(sig[size:])
+		return sig, nil
+	case RSA:
+		rsaKey, ok := privKey.(rsa.PrivateKey)
+		if !ok {
+			return nil, fmt.Errorf("private key is not of type %s", kt)
+		}
+		hash, err := hashPayload(crypto.SHA256, payload)
+		if err != nil {
+			return nil, err
+		}
+		sig, err := rsa.SignPSS(rand.Reader, &rsaKey, crypto.SHA256, hash, &rsa.PSSOptions{SaltLength: rsa.PSSSaltLengthEqualsHash})
+		if err != nil {
+			return nil, errors.Wrap(err, "signing payload")
+		}
+		return sig, nil
+	default:
+		return nil, fmt.Errorf("signing not supported for key type: %s", kt)
+	}
+}
+
+// Verify verifies the signature over the payload with the public key using the raw signature algorithm for the given
+// key type, returning an error if the signature is invalid. Signatures are expected in the format produced by Sign,
+// though RSASSA-PKCS1-v1_5 signatures with SHA-256 are also accepted for RSA keys.
+func Verify(kt KeyType, pubKey crypto.PublicKey, payload, sig []byte) error {
+	if pubKey == nil {
+		return errors.New("public key cannot be nil")
+	}
+	// dereference the ptr
+	if reflect.ValueOf(pubKey).Kind() == reflect.Ptr {
+		pubKey = reflect.ValueOf(pubKey).Elem().Interface().(crypto.PublicKey)
+	}
+	switch kt {
+	case Ed25519:
+		edKey, ok := pubKey.(ed25519.PublicKey)
+		if !ok {
+			return fmt.Errorf("public key is not of type %s", kt)
+		}
+		if len(edKey) != ed25519.PublicKeySize {
+			return fmt.Errorf("invalid ed25519 public key size: %d", len(edKey))
+		}
+		if !ed25519.Verify(edKey, payload, sig) {
+			return errors.New("invalid ed25519 signature")
+		}
+		return nil
+	case Ed448:
+		edKey, ok := pubKey.(ed448.PublicKey)
+		if !ok {
+			return fmt.Errorf("public key is not of type %s", kt)
+		}
+		return VerifyEd448(edKey, payload, sig)
+	case SECP256k1, SECP256k1ECDSA:
+		secpKey, err := toSECP256k1PublicKey(pubKey)
+		if err != nil {
+			return errors.Wrapf(err, "public key is not of type %s", kt)
+		}
+		if len(sig) != 64 {
+			return fmt.Errorf("invalid secp256k1 signature size: %d", len(sig))
+		}
+		var r, s secp.ModNScalar
+		if overflow := r.SetByteSlice(sig[:32]); overflow {
+			return errors.New("invalid secp256k1 signature r")
+		}
+		if overflow := s.SetByteSlice(sig[32:]); overflow {
+			return errors.New("invalid secp256k1 signature s")
+		}
+		hash, err := hashPayload(crypto.SHA256, payload)
+		if err != nil {
+			return err
+		}
+		if !secpecdsa.NewSignature(&r, &s).Verify(hash, secpKey) {
+			return errors.New("invalid secp256k1 signature")
+		}
+		return nil
+	case P224, P256, P384, P521:
+		ecdsaKey, ok := pubKey.(ecdsa.PublicKey)
+		if !ok || ecdsaKey.Curve != ellipticCurveForKeyType(kt) {
+			return fmt.Errorf("public key is not of type %s", kt)
+		}
+		size := (ecdsaKey.Curve.Params().BitSize + 7) / 8
+		if len(sig) != 2*size {
+			return fmt.Errorf("invalid %s signature size: %d", kt, len(sig))
+		}
+		hash, err := hashPayload(hashForKeyType(kt), payload)
+		if err != nil {
+			return err
+		}
+		r := new(big.Int).SetBytes(sig[:size])
+		s := new(big.Int).SetBytes(sig[size:])
+		if !ecdsa.Verify(&ecdsaKey, hash, r, s) {
+			return fmt.Errorf("invalid %s signature", kt)
+		}
+		return nil
+	case RSA:
+		rsaKey, ok := pubKey.(rsa.PublicKey)
+		if !ok {
+			return fmt.Errorf("public key is not of type %s", kt)
+		}
+		hash, err := hashPayload(crypto.SHA256, payload)
+		if err != nil {
+			return err
+		}
+		pssErr := rsa.VerifyPSS(&rsaKey, crypto.SHA256, hash, sig, &rsa.PSSOptions{SaltLength: rsa.PSSSaltLengthAuto})
+		if pssErr == nil {
+			return nil
+		}
+		if err = rsa.VerifyPKCS1v15(&rsaKey, crypto.SHA256, hash, sig); err != nil {
+			return errors.Wrap(pssErr, "invalid rsa signature")
+		}
+		return nil
+	default:
+		return fmt.Errorf("verification not supported for key type: %s", kt)
+	}
+}
+
+// ellipticCurveForKeyType returns the NIST curve for the given P-curve key type
+func ellipticCurveForKeyType(kt KeyType) elliptic.Curve {
+	switch kt {
+	case P224:
+		return elliptic.P224()
+	case P256:
+		return elliptic.P256()
+	case P384:
+		return elliptic.P384()
+	case P521:
+		return elliptic.P521()
+	default:
+		return nil
+	}
+}
+
+// hashForKeyType returns the hash paired with the given P-curve key type, following JOSE for P-256, P-384, and P-521
+// https://www.rfc-editor.org/rfc/rfc7518#section-3.4
+func hashForKeyType(kt KeyType) crypto.Hash {
+	switch kt {
+	case P224:
+		return crypto.SHA224
+	case P384:
+		return crypto.SHA384
+	case P521:
+		return crypto.SHA512
+	default:
+		return crypto.SHA256
+	}
+}
+
+func hashPayload(hash crypto.Hash, payload []byte) ([]byte, error) {
+	if !hash.Available() {
+		return nil, fmt.Errorf("hash not available: %s", hash)
+	}
+	h := hash.New()
+	h.Write(payload)
+	return h.Sum(nil), nil
+}
+
+// toSECP256k1PrivateKey accepts either a secp256k1 private key or an ECDSA private key on the secp256k1 curve
+func toSECP256k1PrivateKey(key crypto.PrivateKey) (*secp.PrivateKey, error) {
+	switch k := key.(type) {
+	case secp.PrivateKey:
+		return &k, nil
+	case ecdsa.PrivateKey:
+		if k.Curve != secp.S256() {
+			return nil, fmt.Errorf("unsupported curve: %s", k.Curve.Params().Name)
+		}
+		return secp.PrivKeyFromBytes(k.D.FillBytes(make([]byte, 32))), nil
+	default:
+		return nil, errors.New("unknown private key type")
+	}
+}
+
+// toSECP256k1PublicKey accepts either a secp256k1 public key or an ECDSA public key on the secp256k1 curve
+func toSECP256k1PublicKey(key crypto.PublicKey) (*secp.PublicKey, error) {
+	switch k := key.(type) {
+	case secp.PublicKey:
+		return &k, nil
+	case ecdsa.PublicKey:
+		if k.Curve != secp.S256() {
+			return nil, fmt.Errorf("unsupported curve: %s", k.Curve.Params().Name)
+		}
+		var x, y secp.FieldVal
+		if overflow := x.SetByteSlice(k.X.Bytes()); overflow {
+			return nil, errors.New("invalid public key x")
+		}
+		if overflow := y.SetByteSlice(k.Y.Bytes()); overflow {
+			return nil, errors.New("invalid public key y")
+		}
+		return secp.NewPublicKey(&x, &y), nil
+	default:
+		return nil, errors.New("unknown public key type")
+	}
+}
@@ -0,0 +1,33 @@
+//go:build go1.24
+
+package crypto
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"encoding/asn1"
+	"math/big"
+
+	"github.com/pkg/errors"
+)
+
+// signECDSADeterministic signs the digest, computed with the given hash, with a nonce derived as specified by RFC 6979
+// https://www.rfc-editor.org/rfc/rfc6979#section-3.2
+// A nil random source makes the standard library sign deterministically, with constant-time scalar arithmetic.
+func signECDSADeterministic(key *ecdsa.PrivateKey, hash crypto.Hash, digest []byte) (r, s *big.Int, err error) {
+	der, err := key.Sign(nil, digest, hash)
+	if err != nil {
+		return nil, nil, err
+	}
+	var sig struct {
+		R, S *big.Int
+	}
+	rest, err := asn1.Unmarshal(der, &sig)
+	if err != nil {
+		return nil, nil, errors.Wrap(err, "parsing signature")
+	}
+	if len(rest) != 0 {
+		return nil, nil, errors.New("trailing data after signature")
+	}
+	return sig.R, sig.S, nil
+}
@@ -0,0 +1,72 @@
+//go:build !go1.24
+
+package crypto
+
+import (
+	"bytes"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/hmac"
+	"math/big"
+)
+
+// signECDSADeterministic signs the digest, computed with the given hash, with a nonce generated by the HMAC_DRBG of
+// RFC 6979 seeded with the private key and the digest https://www.rfc-editor.org/rfc/rfc6979#section-3.2
+// The standard library only signs deterministically from Go 1.24, so older toolchains compute the signature with
+// math/big, whose arithmetic on the nonce and private key is not constant-time.
+func signECDSADeterministic(key *ecdsa.PrivateKey, hash crypto.Hash, digest []byte) (r, s *big.Int, err error) {
+	n := key.Curve.Params().N
+	qlen := n.BitLen()
+	rolen := (qlen + 7) / 8
+	bits2int := func(b []byte) *big.Int {
+		v := new(big.Int).SetBytes(b)
+		if blen := len(b) * 8; blen > qlen {
+			v.Rsh(v, uint(blen-qlen))
+		}
+		return v
+	}
+	int2octets := func(v *big.Int) []byte {
+		return v.FillBytes(make([]byte, rolen))
+	}
+	hmacOf := func(key []byte, data ...[]byte) []byte {
+		mac := hmac.New(hash.New, key)
+		for _, d := range data {
+			mac.Write(d)
+		}
+		return mac.Sum(nil)
+	}
+
+	x := int2octets(key.D)
+	e := bits2int(digest)
+	h := int2octets(new(big.Int).Mod(e, n))
+	v := bytes.Repeat([]byte{0x01}, hash.Size())
+	k := make([]byte, hash.Size())
+	k = hmacOf(k, v, []byte{0x00}, x, h)
+	v = hmacOf(k, v)
+	k = hmacOf(k, v, []byte{0x01}, x, h)
+	v = hmacOf(k, v)
+	for {
+		var t []byte
+		for len(t)*8 < qlen {
+			v = hmacOf(k, v)
+			t = append(t, v...)
+		}
+		// candidates outside [1, n-1], or yielding a zero r or s, are discarded and the generator is advanced
+		nonce := bits2int(t)
+		if nonce.Sign() > 0 && nonce.Cmp(n) < 0 {
+			rx, _ := key.Curve.ScalarBaseMult(int2octets(nonce))
+			r = new(big.Int).Mod(rx, n)
+			if r.Sign() != 0 {
+				s = new(big.Int).Mul(key.D, r)
+				s.Add(s, e)
+				s.Mul(s, new(big.Int).ModInverse(nonce, n))
+				s.Mod(s, n)
+				if s.Sign() != 0 {
+					return r, s, nil
+				}
+			}
+		}
+		k = hmacOf(k, v, []byte{0x00})
+		v = hmacOf(k, v)
+	}
+}
@@ -0,0 +1,165 @@
+package crypto
+
+import (
+	gocrypto "crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/hex"
+	"math/big"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSignVerify(t *testing.T) {
+	payload := []byte("hello world")
+
+	tests := []struct {
+		kt      KeyType
+		sigSize int
+	}{
+		{kt: Ed25519, sigSize: 64},
+		{kt: Ed448, sigSize: 114},
+		{kt: SECP256k1, sigSize: 64},
+		{kt: SECP256k1ECDSA, sigSize: 64},
+		{kt: P224, sigSize: 56},
+		{kt: P256, sigSize: 64},
+		{kt: P384, sigSize: 96},
+		{kt: P521, sigSize: 132},
+		{kt: RSA, sigSize: 256},
+	}
+	for _, test := range tests {
+		t.Run(string(test.kt), func(tt *testing.T) {
+			pubKey, privKey, err := GenerateKeyByKeyType(test.kt)
+			require.NoError(tt, err)
+
+			sig, err := Sign(test.kt, privKey, payload)
+			assert.NoError(tt, err)
+			assert.Len(tt, sig, test.sigSize)
+			assert.NoError(tt, Verify(test.kt, pubKey, payload, sig))
+			assert.NoError(tt, Verify(test.kt, &pubKey, payload, sig))
+
+			assert.Error(tt, Verify(test.kt, pubKey, []byte("goodbye world"), sig))
+			tampered := append([]byte{}, sig...)
+			tampered[len(tampered)-1] ^= 0xff
+			assert.Error(tt, Verify(test.kt, pubKey, payload, tampered))
+		})
+	}
+
+	t.Run("pointer keys", func(tt *testing.T) {
+		pubKey, privKey, err := GenerateP256Key()
+		require.NoError(tt, err)
+		sig, err := Sign(P256, &privKey, payload)
+		assert.NoError(tt, err)
+		assert.NoError(tt, Verify(P256, &pubKey, payload, sig))
+	})
+
+	t.Run("rsa pkcs1 signatures verify", func(tt *testing.T) {
+		pubKey, privKey, err := GenerateRSA2048Key()
+		require.NoError(tt, err)
+		hash := sha256.Sum256(payload)
+		sig, err := rsa.SignPKCS1v15(rand.Reader, &privKey, gocrypto.SHA256, hash[:])
+		require.NoError(tt, err)
+		assert.NoError(tt, Verify(RSA, pubKey, payload, sig))
+	})
+
+	t.Run("asn.1 ecdsa signatures are rejected", func(tt *testing.T) {
+		pubKey, privKey, err := GenerateP256Key()
+		require.NoError(tt, err)
+		hash := sha256.Sum256(payload)
+		sig, err := ecdsa.SignASN1(rand.Reader, &privKey, hash[:])
+		require.NoError(tt, err)
+		assert.Error(tt, Verify(P256, pubKey, payload, sig))
+	})
+
+	t.Run("mismatched key types", func(tt *testing.T) {
+		_, privKey, err := GenerateP256Key()
+		require.NoError(tt, err)
+		_, err = Sign(P384, privKey, payload)
+		assert.Error(tt, err)
+		assert.Contains(tt, err.Error(), "private key is not of type P-384")
+
+		_, err = Sign(Ed25519, privKey, payload)
+		assert.Error(tt, err)
+
+		edPubKey, _, err := GenerateEd25519Key()
+		require.NoError(tt, err)
+		err = Verify(SECP256k1, edPubKey, payload, make([]byte, 64))
+		assert.Error(tt, err)
+	})
+
+	t.Run("unsupported key types", func(tt *testing.T) {
+		pubKey, privKey, err := GenerateX25519Key()
+		require.NoError(tt, err)
+		_, err = Sign(X25519, privKey, payload)
+		assert.Error(tt, err)
+		assert.Contains(tt, err.Error(), "signing not supported for key type: X25519")
+
+		err = Verify(X25519, pubKey, payload, nil)
+		assert.Error(tt, err)
+		assert.Contains(tt, err.Error(), "verification not supported for key type: X25519")
+
+		_, err = Sign(Ed25519, nil, payload)
+		assert.Error(tt, err)
+		assert.Error(tt, Verify(Ed25519, nil, payload, nil))
+	})
+}
+
+func TestSignDeterministicNonce(t *testing.T) {
+	t.Run("RFC 6979 test vectors", func(tt *testing.T) {
+		// https://www.rfc-editor.org/rfc/rfc6979#appendix-A.2.5
+		d, ok := new(big.Int).SetString("C9AFA9D845BA75166B5C215767B1D6934E50C3DB36E89B127B8A622B120F6721", 16)
+		require.True(tt, ok)
+		privKey := ecdsa.PrivateKey{PublicKey: ecdsa.PublicKey{Curve: elliptic.P256()}, D: d}
+		privKey.PublicKey.X, privKey.PublicKey.Y = elliptic.P256().ScalarBaseMult(d.Bytes())
+
+		vectors := map[string]string{
+			"sample": "EFD48B2AACB6A8FD1140DD9CD45E81D69D2C877B56AAF991C34D0EA84EAF3716" +
+				"F7CB1C942D657C41D436C7A1B6E29F65F3E900DBB9AFF4064DC4AB2F843ACDA8",
+			"test": "F1ABB023518351CD71D881567B1EA663ED3EFCF6C5132B354F28D3B0B7D38367" +
+				"019F4113742A2B14BD25926B49C649155F267E60D3814B4C0CC84250E46F0083",
+		}
+		for message, expected := range vectors {
+			sig, err := Sign(P256, privKey, []byte(message), WithDeterministicNonce())
+			require.NoError(tt, err)
+			assert.Equal(tt, strings.ToLower(expected), hex.EncodeToString(sig), message)
+			assert.NoError(tt, Verify(P256, privKey.PublicKey, []byte(message), sig))
+		}
+	})
+
+	t.Run("signatures are stable", func(tt *testing.T) {
+		payload := []byte("hello world")
+		for _, kt := range []KeyType{P256, P384, P521, SECP256k1, SECP256k1ECDSA} {
+			pubKey, privKey, err := GenerateKeyByKeyType(kt)
+			require.NoError(tt, err)
+
+			first, err := Sign(kt, privKey, payload, WithDeterministicNonce())
+			require.NoError(tt, err)
+			require.NoError(tt, Verify(kt, pubKey, payload, first))
+			for i := 0; i < 100; i++ {
+				sig, err := Sign(kt, privKey, payload, WithDeterministicNonce())
+				require.NoError(tt, err)
+				require.Equal(tt, first, sig, "signature %d with %s differs", i, kt)
+			}
+
+			other, err := Sign(kt, privKey, []byte("goodbye world"), WithDeterministicNonce())
+			require.NoError(tt, err)
+			assert.NotEqual(tt, first, other)
+		}
+	})
+
+	t.Run("random nonce by default", func(tt *testing.T) {
+		_, privKey, err := GenerateKeyByKeyType(P256)
+		require.NoError(tt, err)
+		first, err := Sign(P256, privKey, []byte("hello world"))
+		require.NoError(tt, err)
+		second, err := Sign(P256, privKey, []byte("hello world"))
+		require.NoError(tt, err)
+		assert.NotEqual(tt, first, second)
+	})
+}
@@ -15,10 +15,12 @@ const (
 	BBSPlusSignatureProof2020 SignatureType = "BbsBlsSignatureProof2020"
 )
 
-type BBSPlusSignatureProofSuite struct{}
+type BBSPlusSignatureProofSuite struct {
+	suiteOptions
+}
 
-func GetBBSPlusSignatureProofSuite() *BBSPlusSignatureProofSuite {
-	return new(BBSPlusSignatureProofSuite)
+func GetBBSPlusSignatureProofSuite(opts ...SuiteOption) *BBSPlusSignatureProofSuite {
+	return &BBSPlusSignatureProofSuite{suiteOptions: newSuiteOptions(opts)}
 }
 
 // CryptoSuiteInfo interface
@@ -87,10 +89,14 @@ func (b BBSPlusSignatureProofSuite) SelectivelyDisclose(v BBSPlusVerifier, p Pro
 
 	// attach the proof to the derived credential
 	derivedProof := &BBSPlusSignature2020Proof{
+		ID:                 bbsPlusProof.ID,
 		Type:               BBSPlusSignatureProof2020,
 		Created:            bbsPlusProof.Created,
 		VerificationMethod: bbsPlusProof.VerificationMethod,
 		ProofPurpose:       bbsPlusProof.ProofPurpose,
+		Challenge:          bbsPlusProof.Challenge,
+		Domain:             bbsPlusProof.Domain,
+		PreviousProof:      bbsPlusProof.PreviousProof,
 		ProofValue:         base64.StdEncoding.EncodeToString(derivedProofValue),
 		Nonce:              base64.StdEncoding.EncodeToString(nonce),
 	}
@@ -99,7 +105,7 @@ func (b BBSPlusSignatureProofSuite) SelectivelyDisclose(v BBSPlusVerifier, p Pro
 	return derivedCred, nil
 }
 
-func (BBSPlusSignatureProofSuite) compactProvable(p Provable) (Provable, *crypto.Proof, error) {
+func (b BBSPlusSignatureProofSuite) compactProvable(p Provable) (Provable, *crypto.Proof, error) {
 	var genericProvable map[string]any
 	provableBytes, err := json.Marshal(p)
 	if err != nil {
@@ -108,7 +114,7 @@ func (BBSPlusSignatureProofSuite) compactProvable(p Provable) (Provable, *crypto
 	if err = json.Unmarshal(provableBytes, &genericProvable); err != nil {
 		return nil, nil, errors.Wrap(err, "unmarshalling provable to generic map")
 	}
-	compactProvable, err := LDCompact(genericProvable, W3CSecurityContext)
+	compactProvable, err := LDCompact(genericProvable, W3CSecurityContext, b.ldOption())
 	if err != nil {
 		return nil, nil, errors.Wrap(err, "compacting provable")
 	}
@@ -213,7 +219,7 @@ func (b BBSPlusSignatureProofSuite) CreateDeriveProof(inputProofDocument any, re
 
 	// 3. Apply the framing algorithm to the input proof document.
 	// Let the product of the framing algorithm be known as the revealed document.
-	revealedDocument, err := LDFrame(inputProofDocument, revealDocument)
+	revealedDocument, err := LDFrame(inputProofDocument, revealDocument, b.ldOption())
 	if err != nil {
 		return nil, err
 	}
@@ -265,6 +271,12 @@ func (b BBSPlusSignatureProofSuite) Verify(v Verifier, p Provable) error {
 	if err != nil {
 		return errors.Wrap(err, "coercing proof into BBSPlusSignature2020Proof proof")
 	}
+	if err = b.checkProofBinding(gotProof.Challenge, gotProof.Domain); err != nil {
+		return err
+	}
+	if err = b.checkProofPurpose(gotProof.ProofPurpose, gotProof.VerificationMethod); err != nil {
+		return err
+	}
 
 	// remove proof before verifying
 	p.SetProof(nil)
@@ -331,13 +343,13 @@ func (BBSPlusSignatureProofSuite) Marshal(data any) ([]byte, error) {
 	return jsonBytes, nil
 }
 
-func (BBSPlusSignatureProofSuite) Canonicalize(marshaled []byte) (*string, error) {
+func (b BBSPlusSignatureProofSuite) Canonicalize(marshaled []byte) (*string, error) {
 	// the LD library anticipates a generic golang json object to normalize
 	var generic map[string]any
 	if err := json.Unmarshal(marshaled, &generic); err != nil {
 		return nil, err
 	}
-	normalized, err := LDNormalize(generic)
+	normalized, err := LDNormalize(generic, b.ldOption())
 	if err != nil {
 		return nil, errors.Wrap(err, "canonicalizing provable document")
 	}
@@ -439,7 +451,7 @@ func (b BBSPlusSignatureProofSuite) prepareProof(proof crypto.Proof, opts *Proof
 		// if none provided, make sure the proof has a context value for this suite
 		contexts = ArrayStrToInterface(b.RequiredContexts())
 	}
-	genericProof["@context"] = contexts
+	genericProof["@context"] = withProofChainContext(contexts, genericProof)
 	p := crypto.Proof(genericProof)
 	return &p, nil
 }
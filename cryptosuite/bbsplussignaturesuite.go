@@ -2,7 +2,9 @@ package cryptosuite
 
 import (
 	gocrypto "crypto"
+	"crypto/rand"
 	"encoding/base64"
+	"fmt"
 
 	"github.com/TBD54566975/ssi-sdk/crypto"
 	. "github.com/TBD54566975/ssi-sdk/util"
@@ -12,18 +14,24 @@ import (
 
 const (
 	BBSSecurityContext                             string        = "https://w3c.github.io/vc-di-bbs/contexts/v1"
+	BBSSecurityV1Context                           string        = "https://w3id.org/security/bbs/v1"
 	BBSPlusSignature2020                           SignatureType = "BbsBlsSignature2020"
 	BBSPlusSignatureSuiteID                        string        = "https://w3c-ccg.github.io/ldp-bbs2020/#the-bbs-signature-suite-2020"
 	BBSPlusSignatureSuiteType                      LDKeyType     = BLS12381G2Key2020
 	BBSPlusSignatureSuiteCanonicalizationAlgorithm string        = "https://w3id.org/security#URDNA2015"
 	// BBSPlusSignatureSuiteDigestAlgorithm uses https://www.rfc-editor.org/rfc/rfc4634
 	BBSPlusSignatureSuiteDigestAlgorithm gocrypto.Hash = gocrypto.BLAKE2b_384
+
+	// bbsPlusNonceSize is the size of the random nonce used when deriving a proof without one
+	bbsPlusNonceSize = 32
 )
 
-type BBSPlusSignatureSuite struct{}
+type BBSPlusSignatureSuite struct {
+	suiteOptions
+}
 
-func GetBBSPlusSignatureSuite() CryptoSuite {
-	return new(BBSPlusSignatureSuite)
+func GetBBSPlusSignatureSuite(opts ...SuiteOption) CryptoSuite {
+	return &BBSPlusSignatureSuite{suiteOptions: newSuiteOptions(opts)}
 }
 
 // CryptoSuiteInfo interface
@@ -57,7 +65,7 @@ func (BBSPlusSignatureSuite) RequiredContexts() []string {
 func (b BBSPlusSignatureSuite) Sign(s Signer, p Provable) error {
 	// create proof before running the create verify hash algorithm
 	// TODO(gabe) support required reveal values
-	proof := b.createProof(s.GetKeyID(), s.GetProofPurpose(), nil)
+	proof := b.createProof(b.newProofOptions(s, p), nil)
 
 	// prepare proof options
 	contexts, err := GetContextsFromProvable(p)
@@ -123,7 +131,7 @@ func (b BBSPlusSignatureSuite) prepareProof(proof crypto.Proof, opts *ProofOptio
 		// if none provided, make sure the proof has a context value for this suite
 		contexts = ArrayStrToInterface(b.RequiredContexts())
 	}
-	genericProof["@context"] = contexts
+	genericProof["@context"] = withProofChainContext(contexts, genericProof)
 	p := crypto.Proof(genericProof)
 	return &p, nil
 }
@@ -134,6 +142,12 @@ func (b BBSPlusSignatureSuite) Verify(v Verifier, p Provable) error {
 	if err != nil {
 		return errors.Wrap(err, "coercing proof into BBSPlusSignature2020Proof proof")
 	}
+	if err = b.checkProofBinding(gotProof.Challenge, gotProof.Domain); err != nil {
+		return err
+	}
+	if err = b.checkProofPurpose(gotProof.ProofPurpose, gotProof.VerificationMethod); err != nil {
+		return err
+	}
 
 	// remove proof before verifying
 	p.SetProof(nil)
@@ -178,6 +192,37 @@ func (b BBSPlusSignatureSuite) Verify(v Verifier, p Provable) error {
 	return nil
 }
 
+// CreateProof signs all statements of the provable, adding a BbsBlsSignature2020 proof from which selective
+// disclosure proofs can later be derived with DeriveProof
+func (b BBSPlusSignatureSuite) CreateProof(s Signer, p Provable) error {
+	return b.Sign(s, p)
+}
+
+// DeriveProof derives a BbsBlsSignatureProof2020 credential from a provable signed with CreateProof, revealing only the
+// statements selected by the reveal document, a JSON-LD frame, while proving knowledge of the signature over all other
+// statements. The verifier must hold the original signer's public key. If no nonce is given a random one is used.
+// https://w3c-ccg.github.io/vc-di-bbs/#create-derive-proof-data-algorithm
+func (b BBSPlusSignatureSuite) DeriveProof(v BBSPlusVerifier, p Provable, revealDocument map[string]any, nonce []byte) (map[string]any, error) {
+	proof := p.GetProof()
+	if proof == nil {
+		return nil, errors.New("provable has no proof to derive from")
+	}
+	bbsPlusProof, err := BBSPlusProofFromGenericProof(*proof)
+	if err != nil {
+		return nil, errors.Wrap(err, "coercing proof into BBSPlusSignature2020Proof proof")
+	}
+	if bbsPlusProof.Type != BBSPlusSignature2020 {
+		return nil, fmt.Errorf("cannot derive a proof from a proof of type: %s", bbsPlusProof.Type)
+	}
+	if len(nonce) == 0 {
+		nonce = make([]byte, bbsPlusNonceSize)
+		if _, err = rand.Read(nonce); err != nil {
+			return nil, errors.Wrap(err, "generating nonce")
+		}
+	}
+	return BBSPlusSignatureProofSuite{suiteOptions: b.suiteOptions}.SelectivelyDisclose(v, p, revealDocument, nonce)
+}
+
 // decodeProofValue because the proof could have been encoded in a variety of manners we must try them all
 // https://github.com/w3c-ccg/ldp-bbs2020/issues/16#issuecomment-1436148820
 func decodeProofValue(proofValue string) ([]byte, error) {
@@ -205,13 +250,13 @@ func (BBSPlusSignatureSuite) Marshal(data any) ([]byte, error) {
 	return jsonBytes, nil
 }
 
-func (BBSPlusSignatureSuite) Canonicalize(marshaled []byte) (*string, error) {
+func (b BBSPlusSignatureSuite) Canonicalize(marshaled []byte) (*string, error) {
 	// the LD library anticipates a generic golang json object to normalize
 	var generic map[string]any
 	if err := json.Unmarshal(marshaled, &generic); err != nil {
 		return nil, err
 	}
-	normalized, err := LDNormalize(generic)
+	normalized, err := LDNormalize(generic, b.ldOption())
 	if err != nil {
 		return nil, errors.Wrap(err, "ld normalizing")
 	}
@@ -276,21 +321,29 @@ func (BBSPlusSignatureSuite) Digest(tbd []byte) ([]byte, error) {
 	return tbd, nil
 }
 
-func (b BBSPlusSignatureSuite) createProof(verificationMethod string, purpose ProofPurpose, requiredRevealStatements []int) BBSPlusSignature2020Proof {
+func (b BBSPlusSignatureSuite) createProof(opts ProofOptions, requiredRevealStatements []int) BBSPlusSignature2020Proof {
 	return BBSPlusSignature2020Proof{
+		ID:                       opts.ID,
 		Type:                     b.SignatureAlgorithm(),
-		Created:                  GetRFC3339Timestamp(),
-		VerificationMethod:       verificationMethod,
-		ProofPurpose:             purpose,
+		Created:                  opts.Created,
+		VerificationMethod:       opts.VerificationMethod,
+		ProofPurpose:             opts.ProofPurpose,
+		Challenge:                opts.Challenge,
+		Domain:                   opts.Domain,
+		PreviousProof:            opts.PreviousProof,
 		RequiredRevealStatements: requiredRevealStatements,
 	}
 }
 
 type BBSPlusSignature2020Proof struct {
+	ID                       string        `json:"id,omitempty"`
 	Type                     SignatureType `json:"type,omitempty"`
 	Created                  string        `json:"created,omitempty"`
 	VerificationMethod       string        `json:"verificationMethod,omitempty"`
 	ProofPurpose             ProofPurpose  `json:"proofPurpose,omitempty"`
+	Challenge                string        `json:"challenge,omitempty"`
+	Domain                   string        `json:"domain,omitempty"`
+	PreviousProof            any           `json:"previousProof,omitempty"`
 	ProofValue               string        `json:"proofValue,omitempty"`
 	Nonce                    string        `json:"nonce,omitempty"`
 	RequiredRevealStatements []int         `json:"requiredRevealStatements,omitempty"`
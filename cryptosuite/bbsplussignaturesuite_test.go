@@ -8,6 +8,7 @@ import (
 	bbs "github.com/hyperledger/aries-framework-go/pkg/crypto/primitive/bbs12381g2pub"
 	"github.com/mr-tron/base58"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 const (
@@ -15,7 +16,7 @@ const (
 )
 
 func TestBBSPlusSignatureSuite(t *testing.T) {
-	suite := GetBBSPlusSignatureSuite()
+	suite := GetBBSPlusSignatureSuite(WithProofOptions(ProofOptions{Challenge: "abc"}))
 	testCred := TestCredential{
 		Context: []any{"https://www.w3.org/2018/credentials/v1",
 			"https://w3c.github.io/vc-di-bbs/contexts/v1"},
@@ -43,6 +44,90 @@ func TestBBSPlusSignatureSuite(t *testing.T) {
 
 	err = suite.Verify(signer, &testCred)
 	assert.NoError(t, err)
+
+	// an authentication proof is bound to its challenge
+	err = GetBBSPlusSignatureSuite().Verify(signer, &testCred)
+	assert.ErrorContains(t, err, "proof challenge<abc> was not expected")
+}
+
+func TestBBSPlusSignatureSuiteDeriveProof(t *testing.T) {
+	contexts := []any{"https://www.w3.org/2018/credentials/v1", "https://w3c.github.io/vc-di-bbs/contexts/v1",
+		map[string]any{"@vocab": "https://example.com/#"}}
+	claims := map[string]any{
+		"givenName":   "Alice",
+		"familyName":  "Smith",
+		"birthDate":   "1990-01-01",
+		"nationality": "Example",
+		"email":       "alice@example.com",
+	}
+	subject := map[string]any{"id": "did:example:abcd"}
+	for k, v := range claims {
+		subject[k] = v
+	}
+	testCred := TestCredential{
+		Context:           contexts,
+		Type:              []string{"VerifiableCredential"},
+		Issuer:            "did:example:123",
+		IssuanceDate:      "2021-01-01T19:23:24Z",
+		CredentialSubject: subject,
+	}
+
+	key, err := GenerateBLSKey2020(BLS12381G2Key2020)
+	require.NoError(t, err)
+	privKey, err := key.GetPrivateKey()
+	require.NoError(t, err)
+	signer := NewBBSPlusSigner("did:example:123#key-1", privKey, AssertionMethod)
+	verifier := NewBBSPlusVerifier("did:example:123#key-1", privKey.PublicKey())
+
+	suite := BBSPlusSignatureSuite{}
+	require.NoError(t, suite.CreateProof(signer, &testCred))
+	require.NoError(t, suite.Verify(verifier, &testCred))
+
+	// reveal 2 of the 5 claims
+	revealDoc := map[string]any{
+		"@context": contexts,
+		"type":     "VerifiableCredential",
+		"credentialSubject": map[string]any{
+			"@explicit":  true,
+			"givenName":  map[string]any{},
+			"familyName": map[string]any{},
+		},
+	}
+	derived, err := suite.DeriveProof(*verifier, &testCred, revealDoc, nil)
+	require.NoError(t, err)
+
+	derivedSubject, ok := derived["credentialSubject"].(map[string]any)
+	require.True(t, ok)
+	assert.Equal(t, "Alice", derivedSubject["givenName"])
+	assert.Equal(t, "Smith", derivedSubject["familyName"])
+	for _, hidden := range []string{"birthDate", "nationality", "email"} {
+		assert.NotContains(t, derivedSubject, hidden)
+	}
+
+	derivedProof, err := BBSPlusProofFromGenericProof(derived["proof"])
+	require.NoError(t, err)
+	assert.Equal(t, BBSPlusSignatureProof2020, derivedProof.Type)
+	assert.NotEmpty(t, derivedProof.Nonce)
+
+	// the derived proof verifies against the original signer's key
+	derivedCred := GenericProvable(derived)
+	proofSuite := GetBBSPlusSignatureProofSuite()
+	assert.NoError(t, proofSuite.Verify(verifier, &derivedCred))
+
+	// but not against another key
+	otherKey, err := GenerateBLSKey2020(BLS12381G2Key2020)
+	require.NoError(t, err)
+	otherPrivKey, err := otherKey.GetPrivateKey()
+	require.NoError(t, err)
+	assert.Error(t, proofSuite.Verify(NewBBSPlusVerifier("did:example:123#key-1", otherPrivKey.PublicKey()), &derivedCred))
+
+	// and a revealed claim cannot be changed
+	derivedSubject["givenName"] = "Mallory"
+	assert.Error(t, proofSuite.Verify(verifier, &derivedCred))
+
+	// a derived proof cannot be derived from again
+	_, err = suite.DeriveProof(*verifier, &derivedCred, revealDoc, nil)
+	assert.ErrorContains(t, err, "cannot derive a proof from a proof of type")
 }
 
 // Case 16: https://github.com/w3c-ccg/vc-api/pull/128/files#diff-df503c1c03bdbbb0eba7241edcad059467116947346f8f89d9b49a064c9f00c3
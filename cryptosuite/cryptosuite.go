@@ -1,6 +1,7 @@
 package cryptosuite
 
 import (
+	"context"
 	gocrypto "crypto"
 	"embed"
 
@@ -74,12 +75,35 @@ type Verifier interface {
 	GetKeyID() string
 }
 
+// VerificationMethodAuthorizer determines whether a verification method is authorized for a proof purpose by its
+// controller, which lists the methods it authorizes for each purpose in the verification relationship of the same
+// name of its DID Document https://www.w3.org/TR/did-core/#verification-relationships
+type VerificationMethodAuthorizer interface {
+	IsAuthorized(ctx context.Context, verificationMethod string, purpose ProofPurpose) (bool, error)
+}
+
 type ProofOptions struct {
 	// JSON-LD contexts to add to the proof
 	Contexts []any
 
 	// Indexes of the credential subject to require be revealed in BBS+ signatures
 	RevealIndexes []int
+
+	// Challenge and Domain bind a proof to a verifier's nonce and security domain, such as those of a presentation
+	// request, so that it may not be replayed. Both are covered by the signature of the proof.
+	Challenge string
+	Domain    string
+
+	// ProofPurpose, Created, and VerificationMethod override those of a proof otherwise taken from its signer and
+	// the time it is created
+	ProofPurpose       ProofPurpose
+	Created            string
+	VerificationMethod string
+
+	// ID identifies a proof so that later proofs of a chain may reference it, and PreviousProof is the id, or array of
+	// ids, of the proofs of the chain a proof follows. Both are covered by the signature of the proof.
+	ID            string
+	PreviousProof any
 }
 
 // GenericProvable represents a provable that is not constrained by a specific type
@@ -103,7 +127,11 @@ func (g *GenericProvable) SetProof(p *crypto.Proof) {
 		return
 	}
 	provable := *g
-	provable["proof"] = p
+	if p == nil {
+		delete(provable, "proof")
+	} else {
+		provable["proof"] = p
+	}
 	*g = provable
 }
 
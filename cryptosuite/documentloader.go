@@ -0,0 +1,112 @@
+package cryptosuite
+
+import (
+	"context"
+	"strings"
+	"sync"
+
+	. "github.com/TBD54566975/ssi-sdk/util"
+	"github.com/piprate/json-gold/ld"
+	"github.com/pkg/errors"
+)
+
+const (
+	W3CCredentialsContext         string = "https://www.w3.org/2018/credentials/v1"
+	W3CCredentialsExamplesContext string = "https://www.w3.org/2018/credentials/examples/v1"
+	W3CSecurityV1Context          string = "https://w3id.org/security/v1"
+
+	// odrlContext is imported by the W3C VC v1 examples context
+	odrlContext string = "https://www.w3.org/ns/odrl.jsonld"
+)
+
+// embeddedContexts maps the URLs of the contexts served by the default document loader to their files in the
+// context directory
+var embeddedContexts = map[string]string{
+	W3CCredentialsContext:              "credentials-v1.jsonld",
+	W3CCredentialsExamplesContext:      "credentials-examples-v1.jsonld",
+	odrlContext:                        "odrl.jsonld",
+	W3CSecurityV1Context:               "security-v1.jsonld",
+	W3CSecurityContext:                 "security-v2.jsonld",
+	JSONWebSignature2020Context:        "lds-jws2020-v1.json",
+	Ed25519Signature2020Context:        "ed25519-2020-v1.jsonld",
+	EcdsaSecp256k1Signature2019Context: "secp256k1-2019-v1.jsonld",
+	BBSSecurityContext:                 "bbs-v1.jsonld",
+	BBSSecurityV1Context:               "bbs-v1.jsonld",
+}
+
+var (
+	// sharedDocumentLoader is the default document loader, created on first use, whose cache is shared by all suites
+	// without WithDocumentLoader
+	sharedDocumentLoader     ld.DocumentLoader
+	sharedDocumentLoaderOnce sync.Once
+)
+
+// SuiteOption configures the processing of a CryptoSuite
+type SuiteOption func(*suiteOptions)
+
+type suiteOptions struct {
+	documentLoader ld.DocumentLoader
+	proofOptions   ProofOptions
+
+	authorizationCtx context.Context
+	expectedPurpose  ProofPurpose
+	authorizer       VerificationMethodAuthorizer
+}
+
+// WithDocumentLoader sets the loader a suite uses to retrieve the JSON-LD contexts of the documents it canonicalizes.
+// By default, suites serve the W3C VC v1, VC v1 examples and security contexts, and the contexts of the suites in this
+// package, from an embedded copy, and retrieve all others from the network.
+func WithDocumentLoader(loader ld.DocumentLoader) SuiteOption {
+	return func(o *suiteOptions) {
+		o.documentLoader = loader
+	}
+}
+
+func newSuiteOptions(opts []SuiteOption) suiteOptions {
+	var o suiteOptions
+	for _, opt := range opts {
+		opt(&o)
+	}
+	return o
+}
+
+// ldOption returns the option passing the suite's document loader to the LD helpers
+func (o suiteOptions) ldOption() LDOption {
+	if o.documentLoader == nil {
+		sharedDocumentLoaderOnce.Do(func() { sharedDocumentLoader = NewDefaultDocumentLoader() })
+		return WithLDDocumentLoader(sharedDocumentLoader)
+	}
+	return WithLDDocumentLoader(o.documentLoader)
+}
+
+// embeddedDocumentLoader serves the embedded contexts, delegating all other URLs to the next loader
+type embeddedDocumentLoader struct {
+	next ld.DocumentLoader
+	// mu serializes access to the next loader, whose cache is not safe for concurrent use
+	mu sync.Mutex
+}
+
+// NewDefaultDocumentLoader returns a document loader like the one used by suites without WithDocumentLoader. It
+// serves the W3C VC v1, VC v1 examples and security contexts, and the contexts of the suites in this package, without
+// network access, and retrieves all others with a caching loader. It is safe for concurrent use.
+func NewDefaultDocumentLoader() ld.DocumentLoader {
+	return &embeddedDocumentLoader{next: ld.NewRFC7324CachingDocumentLoader(nil)}
+}
+
+func (l *embeddedDocumentLoader) LoadDocument(u string) (*ld.RemoteDocument, error) {
+	fileName, ok := embeddedContexts[u]
+	if !ok {
+		l.mu.Lock()
+		defer l.mu.Unlock()
+		return l.next.LoadDocument(u)
+	}
+	knownContext, err := getKnownContext(fileName)
+	if err != nil {
+		return nil, errors.Wrapf(err, "reading embedded context: %s", u)
+	}
+	doc, err := ld.DocumentFromReader(strings.NewReader(knownContext))
+	if err != nil {
+		return nil, errors.Wrapf(err, "parsing embedded context: %s", u)
+	}
+	return &ld.RemoteDocument{DocumentURL: u, Document: doc}, nil
+}
@@ -0,0 +1,144 @@
+package cryptosuite
+
+import (
+	"errors"
+	"net/http"
+	"testing"
+
+	"github.com/TBD54566975/ssi-sdk/crypto"
+	"github.com/TBD54566975/ssi-sdk/crypto/jwx"
+	"github.com/piprate/json-gold/ld"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type offlineTransport struct{}
+
+func (offlineTransport) RoundTrip(*http.Request) (*http.Response, error) {
+	return nil, errors.New("network disabled")
+}
+
+// disableNetwork fails all requests made with the default HTTP client for the rest of the test
+func disableNetwork(t *testing.T) {
+	transport := http.DefaultTransport
+	http.DefaultTransport = offlineTransport{}
+	t.Cleanup(func() { http.DefaultTransport = transport })
+}
+
+// staticDocumentLoader serves a fixed set of documents, delegating all others to the default document loader
+type staticDocumentLoader map[string]any
+
+func (l staticDocumentLoader) LoadDocument(u string) (*ld.RemoteDocument, error) {
+	if doc, ok := l[u]; ok {
+		return &ld.RemoteDocument{DocumentURL: u, Document: doc}, nil
+	}
+	return NewDefaultDocumentLoader().LoadDocument(u)
+}
+
+func TestDocumentLoader(t *testing.T) {
+	disableNetwork(t)
+
+	t.Run("embedded contexts", func(tt *testing.T) {
+		loader := NewDefaultDocumentLoader()
+		for u := range embeddedContexts {
+			doc, err := loader.LoadDocument(u)
+			assert.NoError(tt, err, u)
+			assert.NotEmpty(tt, doc.Document, u)
+		}
+
+		_, err := loader.LoadDocument("https://w3id.org/citizenship/v1")
+		assert.ErrorContains(tt, err, "network disabled")
+	})
+
+	t.Run("canonicalization with the network disabled", func(tt *testing.T) {
+		suite := GetJSONWebSignature2020Suite()
+		signer, jwk := getTestVectorKey0Signer(tt, AssertionMethod)
+		verifier, err := NewJSONWebKeyVerifier(jwk.ID, jwk.PublicKeyJWK)
+		require.NoError(tt, err)
+
+		testCred := TestCredential{
+			Context:           []any{W3CCredentialsContext, JSONWebSignature2020Context},
+			Type:              []string{"VerifiableCredential"},
+			Issuer:            "did:example:123",
+			IssuanceDate:      "2021-01-01T19:23:24Z",
+			CredentialSubject: map[string]any{"id": "did:example:abcd"},
+		}
+		require.NoError(tt, suite.Sign(&signer, &testCred))
+		assert.NoError(tt, suite.Verify(verifier, &testCred))
+
+		// as are the contexts of the Ed25519 and BBS+ suites
+		pubKey, privKey, err := crypto.GenerateEd25519Key()
+		require.NoError(tt, err)
+		edCred := testCred
+		edCred.Context = []any{W3CCredentialsContext, Ed25519Signature2020Context}
+		edCred.Proof = nil
+		require.NoError(tt, GetEd25519Signature2020Suite().Sign(NewEd25519Signer("did:example:123#key-1", privKey, AssertionMethod), &edCred))
+		assert.NoError(tt, GetEd25519Signature2020Suite().Verify(NewEd25519Verifier("did:example:123#key-1", pubKey), &edCred))
+
+		blsKey, err := GenerateBLSKey2020(BLS12381G2Key2020)
+		require.NoError(tt, err)
+		blsPrivKey, err := blsKey.GetPrivateKey()
+		require.NoError(tt, err)
+		bbsSigner := NewBBSPlusSigner("did:example:123#key-2", blsPrivKey, AssertionMethod)
+		bbsCred := testCred
+		bbsCred.Context = []any{W3CCredentialsContext, BBSSecurityContext}
+		bbsCred.Proof = nil
+		require.NoError(tt, GetBBSPlusSignatureSuite().Sign(bbsSigner, &bbsCred))
+		assert.NoError(tt, GetBBSPlusSignatureSuite().Verify(bbsSigner, &bbsCred))
+
+		// the security contexts are embedded too
+		canonical, err := JWSSignatureSuite{}.Canonicalize([]byte(`{"@context": "https://w3id.org/security/v2", "type": "Ed25519Signature2018"}`))
+		require.NoError(tt, err)
+		assert.Contains(tt, *canonical, "<https://w3id.org/security#Ed25519Signature2018>")
+	})
+
+	t.Run("with document loader", func(tt *testing.T) {
+		exampleContext := "https://example.com/context/v1"
+		loader := staticDocumentLoader{
+			exampleContext: map[string]any{
+				"@context": map[string]any{"@vocab": "https://example.com/vocab#"},
+			},
+		}
+		testCred := func() TestCredential {
+			return TestCredential{
+				Context:           []any{W3CCredentialsContext, exampleContext},
+				Type:              []string{"VerifiableCredential"},
+				Issuer:            "did:example:123",
+				IssuanceDate:      "2021-01-01T19:23:24Z",
+				CredentialSubject: map[string]any{"id": "did:example:abcd", "name": "Alice"},
+			}
+		}
+
+		_, privKey, err := crypto.GenerateP256Key()
+		require.NoError(tt, err)
+		pubJWK, privJWK, err := jwx.PrivateKeyToPrivateKeyJWK(privKey)
+		require.NoError(tt, err)
+		signer, err := NewJSONWebKeySigner("did:example:123", "did:example:123#key-1", *privJWK, AssertionMethod)
+		require.NoError(tt, err)
+		verifier, err := NewJSONWebKeyVerifier("did:example:123#key-1", *pubJWK)
+		require.NoError(tt, err)
+
+		// the example context can only be retrieved by the given loader
+		cred := testCred()
+		assert.ErrorContains(tt, GetJSONWebSignature2020Suite().Sign(signer, &cred), "loading remote context failed")
+
+		suite := GetJSONWebSignature2020Suite(WithDocumentLoader(loader))
+		cred = testCred()
+		require.NoError(tt, suite.Sign(signer, &cred))
+		assert.NoError(tt, suite.Verify(verifier, &cred))
+
+		// the loader is used by every suite
+		suites := map[string]CryptoSuiteProofType{
+			"JsonWebSignature2020":        suite.(CryptoSuiteProofType),
+			"Ed25519Signature2020":        GetEd25519Signature2020Suite(WithDocumentLoader(loader)).(CryptoSuiteProofType),
+			"EcdsaSecp256k1Signature2019": GetEcdsaSecp256k1Signature2019Suite(WithDocumentLoader(loader)).(CryptoSuiteProofType),
+			"BbsBlsSignature2020":         GetBBSPlusSignatureSuite(WithDocumentLoader(loader)).(CryptoSuiteProofType),
+			"BbsBlsSignatureProof2020":    GetBBSPlusSignatureProofSuite(WithDocumentLoader(loader)),
+		}
+		for name, s := range suites {
+			canonical, err := s.Canonicalize([]byte(`{"@context": "` + exampleContext + `", "name": "Alice"}`))
+			require.NoError(tt, err, name)
+			assert.Contains(tt, *canonical, "<https://example.com/vocab#name> \"Alice\"", name)
+		}
+	})
+}
@@ -0,0 +1,210 @@
+package cryptosuite
+
+import (
+	"fmt"
+
+	"github.com/TBD54566975/ssi-sdk/crypto"
+	"github.com/TBD54566975/ssi-sdk/crypto/jwx"
+	. "github.com/TBD54566975/ssi-sdk/util"
+	"github.com/goccy/go-json"
+	"github.com/lestrrat-go/jwx/v2/jwa"
+	"github.com/pkg/errors"
+)
+
+// https://w3c-ccg.github.io/lds-ecdsa-secp256k1-2019/
+
+const (
+	EcdsaSecp256k1Signature2019Context   string        = "https://w3id.org/security/suites/secp256k1-2019/v1"
+	EcdsaSecp256k1Signature2019          SignatureType = "EcdsaSecp256k1Signature2019"
+	EcdsaSecp256k1Signature2019SuiteID   string        = "https://w3c-ccg.github.io/lds-ecdsa-secp256k1-2019/#ecdsasecp256k1signature2019"
+	EcdsaSecp256k1Signature2019SuiteType LDKeyType     = ECDSASECP256k1VerificationKey2019
+)
+
+// EcdsaSecp256k1Signature2019Suite signs with secp256k1 keys, producing a detached ES256K JWS proof. It shares the
+// canonicalization and hashing of JsonWebSignature2020.
+type EcdsaSecp256k1Signature2019Suite struct {
+	JWSSignatureSuite
+}
+
+func GetEcdsaSecp256k1Signature2019Suite(opts ...SuiteOption) CryptoSuite {
+	return &EcdsaSecp256k1Signature2019Suite{JWSSignatureSuite{suiteOptions: newSuiteOptions(opts)}}
+}
+
+// CryptoSuiteInfo interface
+
+var _ CryptoSuiteInfo = (*EcdsaSecp256k1Signature2019Suite)(nil)
+
+func (EcdsaSecp256k1Signature2019Suite) ID() string {
+	return EcdsaSecp256k1Signature2019SuiteID
+}
+
+func (EcdsaSecp256k1Signature2019Suite) Type() LDKeyType {
+	return EcdsaSecp256k1Signature2019SuiteType
+}
+
+func (EcdsaSecp256k1Signature2019Suite) SignatureAlgorithm() SignatureType {
+	return EcdsaSecp256k1Signature2019
+}
+
+func (EcdsaSecp256k1Signature2019Suite) RequiredContexts() []string {
+	return []string{EcdsaSecp256k1Signature2019Context}
+}
+
+func (e EcdsaSecp256k1Signature2019Suite) Sign(s Signer, p Provable) error {
+	// the JWS must be ES256K, whose signatures are low-S normalized
+	if s.GetSigningAlgorithm() != jwa.ES256K.String() {
+		return fmt.Errorf("signer must use %s, got: %s", jwa.ES256K, s.GetSigningAlgorithm())
+	}
+
+	// create proof before running the create verify hash algorithm
+	proof := e.createProof(e.newProofOptions(s, p))
+
+	// prepare proof options
+	opts, err := e.proofOptions(p)
+	if err != nil {
+		return err
+	}
+
+	// 3. tbs value as a result of create verify hash
+	var genericProvable map[string]any
+	pBytes, err := json.Marshal(p)
+	if err != nil {
+		return errors.Wrap(err, "marshaling provable")
+	}
+	if err = json.Unmarshal(pBytes, &genericProvable); err != nil {
+		return errors.Wrap(err, "unmarshaling provable")
+	}
+	tbs, err := e.CreateVerifyHash(genericProvable, proof, opts)
+	if err != nil {
+		return errors.Wrap(err, "running create verify hash algorithm")
+	}
+
+	// 4 & 5. create the signature over the provable data as a JWS
+	signature, err := s.Sign(tbs)
+	if err != nil {
+		return errors.Wrap(err, "signing provable value")
+	}
+
+	// set the signature on the proof object and return
+	proof.SetDetachedJWS(string(signature))
+	genericProof := crypto.Proof(proof)
+	p.SetProof(&genericProof)
+	return nil
+}
+
+func (e EcdsaSecp256k1Signature2019Suite) Verify(v Verifier, p Provable) error {
+	proof := p.GetProof()
+	if proof == nil {
+		return errors.New("provable has no proof")
+	}
+	gotProof, err := JSONWebSignatureProofFromGenericProof(*proof)
+	if err != nil {
+		return errors.Wrap(err, "coercing proof into EcdsaSecp256k1Signature2019 proof")
+	}
+	if gotProof.Type != e.SignatureAlgorithm() {
+		return fmt.Errorf("unexpected proof type: %s", gotProof.Type)
+	}
+	if err = e.checkProofBinding(gotProof.Challenge, gotProof.Domain); err != nil {
+		return err
+	}
+	if err = e.checkProofPurpose(gotProof.ProofPurpose, gotProof.VerificationMethod); err != nil {
+		return err
+	}
+
+	// remove proof before verifying
+	p.SetProof(nil)
+
+	// make sure we set it back after we're done verifying
+	defer p.SetProof(proof)
+
+	// remove the JWS value in the proof before verification
+	jwsCopy := []byte(gotProof.JWS)
+	gotProof.SetDetachedJWS("")
+
+	// prepare proof options
+	opts, err := e.proofOptions(p)
+	if err != nil {
+		return err
+	}
+
+	// run the create verify hash algorithm on both provable and the proof
+	var genericProvable map[string]any
+	pBytes, err := json.Marshal(p)
+	if err != nil {
+		return errors.Wrap(err, "marshaling provable")
+	}
+	if err = json.Unmarshal(pBytes, &genericProvable); err != nil {
+		return errors.Wrap(err, "unmarshaling provable")
+	}
+	tbv, err := e.CreateVerifyHash(genericProvable, gotProof, opts)
+	if err != nil {
+		return errors.Wrap(err, "running create verify hash algorithm")
+	}
+
+	if err = v.Verify(tbv, jwsCopy); err != nil {
+		return errors.Wrap(err, "verifying JWS")
+	}
+	return nil
+}
+
+// proofOptions returns the contexts of the provable, adding the suite's context unless the provable uses the W3C VC
+// v1 context, which already defines the suite's terms as protected, differently from the suite's own context
+func (e EcdsaSecp256k1Signature2019Suite) proofOptions(p Provable) (*ProofOptions, error) {
+	contexts, err := GetContextsFromProvable(p)
+	if err != nil {
+		return nil, errors.Wrap(err, "getting contexts from provable")
+	}
+	for _, c := range contexts {
+		if c == W3CCredentialsContext {
+			return &ProofOptions{Contexts: contexts}, nil
+		}
+	}
+
+	// make sure the suite's context(s) are included
+	contexts = ensureRequiredContexts(contexts, e.RequiredContexts())
+	return &ProofOptions{Contexts: contexts}, nil
+}
+
+// CreateVerifyHash runs the create verify hash algorithm of JsonWebSignature2020, defaulting the proof's contexts to
+// those of this suite
+func (e EcdsaSecp256k1Signature2019Suite) CreateVerifyHash(doc map[string]any, proof crypto.Proof, opts *ProofOptions) ([]byte, error) {
+	if opts == nil || len(opts.Contexts) == 0 {
+		opts = &ProofOptions{Contexts: ArrayStrToInterface(e.RequiredContexts())}
+	}
+	return e.JWSSignatureSuite.CreateVerifyHash(doc, proof, opts)
+}
+
+func (e EcdsaSecp256k1Signature2019Suite) createProof(opts ProofOptions) JSONWebSignature2020Proof {
+	proof := e.JWSSignatureSuite.createProof(opts)
+	proof.Type = e.SignatureAlgorithm()
+	return proof
+}
+
+// EcdsaSecp256k1VerificationKey2019 is a verification method for EcdsaSecp256k1Signature2019 proofs
+// https://w3c-ccg.github.io/lds-ecdsa-secp256k1-2019/#ecdsasecp256k1verificationkey2019
+type EcdsaSecp256k1VerificationKey2019 struct {
+	ID           string           `json:"id,omitempty"`
+	Type         LDKeyType        `json:"type,omitempty"`
+	Controller   string           `json:"controller,omitempty"`
+	PublicKeyJWK jwx.PublicKeyJWK `json:"publicKeyJwk"`
+}
+
+// NewEcdsaSecp256k1Signer creates a signer for EcdsaSecp256k1Signature2019 proofs from a secp256k1 private key JWK
+func NewEcdsaSecp256k1Signer(id, kid string, key jwx.PrivateKeyJWK, purpose ProofPurpose) (*JSONWebKeySigner, error) {
+	if key.CRV != string(crypto.SECP256k1) {
+		return nil, fmt.Errorf("key must be on the %s curve, got: %s", crypto.SECP256k1, key.CRV)
+	}
+	return NewJSONWebKeySigner(id, kid, key, purpose)
+}
+
+// NewEcdsaSecp256k1Verifier creates a verifier for EcdsaSecp256k1Signature2019 proofs from the publicKeyJwk of an
+// EcdsaSecp256k1VerificationKey2019 verification method
+func NewEcdsaSecp256k1Verifier(key EcdsaSecp256k1VerificationKey2019) (*JSONWebKeyVerifier, error) {
+	if key.Type != ECDSASECP256k1VerificationKey2019 {
+		return nil, fmt.Errorf("verification method must be of type %s, got: %s", ECDSASECP256k1VerificationKey2019, key.Type)
+	}
+	if key.PublicKeyJWK.CRV != string(crypto.SECP256k1) {
+		return nil, fmt.Errorf("key must be on the %s curve, got: %s", crypto.SECP256k1, key.PublicKeyJWK.CRV)
+	}
+	return NewJSONWebKeyVerifier(key.ID, key.PublicKeyJWK)
+}
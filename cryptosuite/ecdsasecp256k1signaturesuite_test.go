@@ -0,0 +1,205 @@
+//go:build jwx_es256k
+
+package cryptosuite
+
+import (
+	"encoding/base64"
+	"encoding/hex"
+	"math/big"
+	"strings"
+	"testing"
+
+	"github.com/TBD54566975/ssi-sdk/crypto"
+	"github.com/TBD54566975/ssi-sdk/crypto/jwx"
+	"github.com/decred/dcrd/dcrec/secp256k1/v4"
+	"github.com/goccy/go-json"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestEcdsaSecp256k1Signature2019Suite(t *testing.T) {
+	suite := GetEcdsaSecp256k1Signature2019Suite()
+
+	_, privKey, err := crypto.GenerateSECP256k1Key()
+	require.NoError(t, err)
+	pubJWK, privJWK, err := jwx.PrivateKeyToPrivateKeyJWK(privKey)
+	require.NoError(t, err)
+
+	signer, err := NewEcdsaSecp256k1Signer("did:example:123", "did:example:123#key-1", *privJWK, AssertionMethod)
+	require.NoError(t, err)
+	verifier, err := NewEcdsaSecp256k1Verifier(EcdsaSecp256k1VerificationKey2019{
+		ID:           "did:example:123#key-1",
+		Type:         ECDSASECP256k1VerificationKey2019,
+		Controller:   "did:example:123",
+		PublicKeyJWK: *pubJWK,
+	})
+	require.NoError(t, err)
+
+	getSignedCred := func(tt *testing.T) TestCredential {
+		testCred := TestCredential{
+			Context:      []any{"https://www.w3.org/2018/credentials/v1"},
+			Type:         []string{"VerifiableCredential"},
+			Issuer:       "did:example:123",
+			IssuanceDate: "2021-01-01T19:23:24Z",
+			CredentialSubject: map[string]any{
+				"id": "did:example:abcd",
+			},
+		}
+		require.NoError(tt, suite.Sign(signer, &testCred))
+		return testCred
+	}
+
+	t.Run("sign and verify", func(tt *testing.T) {
+		testCred := getSignedCred(tt)
+		require.NotEmpty(tt, testCred.Proof)
+
+		proof, err := JSONWebSignatureProofFromGenericProof(*testCred.Proof)
+		require.NoError(tt, err)
+		assert.Equal(tt, EcdsaSecp256k1Signature2019, proof.Type)
+		assert.Equal(tt, "did:example:123#key-1", proof.VerificationMethod)
+		assert.NotEmpty(tt, proof.Created)
+
+		parts := strings.Split(proof.JWS, ".")
+		require.Len(tt, parts, 3)
+		assert.Empty(tt, parts[1])
+		headerBytes, err := base64.RawURLEncoding.DecodeString(parts[0])
+		require.NoError(tt, err)
+		var header map[string]any
+		require.NoError(tt, json.Unmarshal(headerBytes, &header))
+		assert.Equal(tt, "ES256K", header["alg"])
+		assert.Equal(tt, "did:example:123#key-1", header["kid"])
+
+		// the signature is low-S normalized
+		sig, err := base64.RawURLEncoding.DecodeString(parts[2])
+		require.NoError(tt, err)
+		require.Len(tt, sig, 64)
+		halfOrder := new(big.Int).Rsh(secp256k1.S256().N, 1)
+		assert.True(tt, new(big.Int).SetBytes(sig[32:]).Cmp(halfOrder) <= 0)
+
+		assert.NoError(tt, suite.Verify(verifier, &testCred))
+	})
+
+	t.Run("document without the credentials context", func(tt *testing.T) {
+		testDoc := TestCredential{
+			Context:           []any{map[string]any{"@vocab": "https://example.com/vocab#"}},
+			Type:              []string{"Example"},
+			Issuer:            "did:example:123",
+			CredentialSubject: map[string]any{"id": "did:example:abcd"},
+		}
+		require.NoError(tt, suite.Sign(signer, &testDoc))
+		assert.NoError(tt, suite.Verify(verifier, &testDoc))
+	})
+
+	t.Run("tampered document", func(tt *testing.T) {
+		testCred := getSignedCred(tt)
+		testCred.Issuer = "did:example:456"
+		assert.ErrorContains(tt, suite.Verify(verifier, &testCred), "verifying JWS")
+	})
+
+	t.Run("wrong proof type", func(tt *testing.T) {
+		testCred := TestCredential{
+			Context:           []any{"https://www.w3.org/2018/credentials/v1", "https://w3id.org/security/suites/jws-2020/v1"},
+			Type:              []string{"VerifiableCredential"},
+			Issuer:            "did:example:123",
+			IssuanceDate:      "2021-01-01T19:23:24Z",
+			CredentialSubject: map[string]any{"id": "did:example:abcd"},
+		}
+		require.NoError(tt, GetJSONWebSignature2020Suite().Sign(signer, &testCred))
+		assert.ErrorContains(tt, suite.Verify(verifier, &testCred), "unexpected proof type")
+	})
+
+	t.Run("non-secp256k1 keys", func(tt *testing.T) {
+		_, p256Key, err := crypto.GenerateP256Key()
+		require.NoError(tt, err)
+		p256PubJWK, p256PrivJWK, err := jwx.PrivateKeyToPrivateKeyJWK(p256Key)
+		require.NoError(tt, err)
+
+		_, err = NewEcdsaSecp256k1Signer("did:example:123", "did:example:123#key-1", *p256PrivJWK, AssertionMethod)
+		assert.ErrorContains(tt, err, "key must be on the secp256k1 curve")
+		_, err = NewEcdsaSecp256k1Verifier(EcdsaSecp256k1VerificationKey2019{
+			ID:           "did:example:123#key-1",
+			Type:         ECDSASECP256k1VerificationKey2019,
+			PublicKeyJWK: *p256PubJWK,
+		})
+		assert.ErrorContains(tt, err, "key must be on the secp256k1 curve")
+		_, err = NewEcdsaSecp256k1Verifier(EcdsaSecp256k1VerificationKey2019{
+			ID:           "did:example:123#key-1",
+			Type:         JSONWebKey2020Type,
+			PublicKeyJWK: *pubJWK,
+		})
+		assert.ErrorContains(tt, err, "verification method must be of type")
+
+		p256Signer, err := NewJSONWebKeySigner("did:example:123", "did:example:123#key-1", *p256PrivJWK, AssertionMethod)
+		require.NoError(tt, err)
+		testCred := TestCredential{
+			Context:           []any{"https://www.w3.org/2018/credentials/v1"},
+			Type:              []string{"VerifiableCredential"},
+			Issuer:            "did:example:123",
+			IssuanceDate:      "2021-01-01T19:23:24Z",
+			CredentialSubject: map[string]any{"id": "did:example:abcd"},
+		}
+		assert.ErrorContains(tt, suite.Sign(p256Signer, &testCred), "signer must use ES256K")
+	})
+}
+
+// A known answer for a fixed key and proof creation time. ES256K signing uses RFC 6979 deterministic nonces, so the
+// same inputs reproduce the same JWS.
+func TestEcdsaSecp256k1Signature2019TestVector(t *testing.T) {
+	keyBytes, err := hex.DecodeString("c85ef7d79691fe79573b1a7064c19c1a9819ebdbd1faaab1a8ec92344438aaf4")
+	require.NoError(t, err)
+	privKey := secp256k1.PrivKeyFromBytes(keyBytes)
+	pubJWK, privJWK, err := jwx.PrivateKeyToPrivateKeyJWK(*privKey)
+	require.NoError(t, err)
+
+	credJSON := `{
+  "@context": [
+    "https://www.w3.org/2018/credentials/v1"
+  ],
+  "type": ["VerifiableCredential"],
+  "issuer": "did:example:123",
+  "issuanceDate": "2021-01-01T19:23:24Z",
+  "credentialSubject": {
+    "id": "did:example:456"
+  },
+  "proof": {
+    "type": "EcdsaSecp256k1Signature2019",
+    "created": "2021-01-02T19:23:24Z",
+    "verificationMethod": "did:example:123#key-1",
+    "proofPurpose": "assertionMethod",
+    "jws": "eyJhbGciOiJFUzI1NksiLCJraWQiOiJkaWQ6ZXhhbXBsZToxMjMja2V5LTEifQ..-rNzYLRd31ciROrUQuNnugzPUM8kU0j02JxypnGxt4EU8J7mdSWALK5rFJMq32nEfJZvWi5w45rM-9DlCBFIWg"
+  }
+}`
+	var cred TestCredential
+	require.NoError(t, json.Unmarshal([]byte(credJSON), &cred))
+
+	suite := EcdsaSecp256k1Signature2019Suite{}
+	verifier, err := NewEcdsaSecp256k1Verifier(EcdsaSecp256k1VerificationKey2019{
+		ID:           "did:example:123#key-1",
+		Type:         ECDSASECP256k1VerificationKey2019,
+		PublicKeyJWK: *pubJWK,
+	})
+	require.NoError(t, err)
+	assert.NoError(t, suite.Verify(verifier, &cred))
+
+	// re-signing with the same proof options reproduces the JWS
+	knownProof, err := JSONWebSignatureProofFromGenericProof(*cred.Proof)
+	require.NoError(t, err)
+	expectedJWS := knownProof.JWS
+	knownProof.SetDetachedJWS("")
+	cred.SetProof(nil)
+
+	var genericCred map[string]any
+	credBytes, err := json.Marshal(cred)
+	require.NoError(t, err)
+	require.NoError(t, json.Unmarshal(credBytes, &genericCred))
+	contexts, err := GetContextsFromProvable(&cred)
+	require.NoError(t, err)
+	tbs, err := suite.CreateVerifyHash(genericCred, knownProof, &ProofOptions{Contexts: contexts})
+	require.NoError(t, err)
+
+	signer, err := NewEcdsaSecp256k1Signer("did:example:123", "did:example:123#key-1", *privJWK, AssertionMethod)
+	require.NoError(t, err)
+	jws, err := signer.Sign(tbs)
+	require.NoError(t, err)
+	assert.Equal(t, expectedJWS, string(jws))
+}
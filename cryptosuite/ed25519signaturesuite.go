@@ -0,0 +1,329 @@
+package cryptosuite
+
+import (
+	gocrypto "crypto"
+	"crypto/sha256"
+	"fmt"
+
+	"github.com/TBD54566975/ssi-sdk/crypto"
+	. "github.com/TBD54566975/ssi-sdk/util"
+	"github.com/goccy/go-json"
+	"github.com/pkg/errors"
+)
+
+// https://w3c-ccg.github.io/di-eddsa-2020/#ed25519signature2020
+
+const (
+	Ed25519Signature2020Context                   string        = "https://w3id.org/security/suites/ed25519-2020/v1"
+	Ed25519Signature2020                          SignatureType = "Ed25519Signature2020"
+	Ed25519Signature2020SuiteID                   string        = "https://w3c-ccg.github.io/di-eddsa-2020/#ed25519signature2020"
+	Ed25519Signature2020SuiteType                 LDKeyType     = Ed25519VerificationKey2020
+	Ed25519Signature2020CanonicalizationAlgorithm string        = "https://w3id.org/security#URDNA2015"
+	// Ed25519Signature2020DigestAlgorithm uses https://www.rfc-editor.org/rfc/rfc4634
+	Ed25519Signature2020DigestAlgorithm gocrypto.Hash = gocrypto.SHA256
+)
+
+type Ed25519Signature2020Suite struct {
+	suiteOptions
+}
+
+func GetEd25519Signature2020Suite(opts ...SuiteOption) CryptoSuite {
+	return &Ed25519Signature2020Suite{suiteOptions: newSuiteOptions(opts)}
+}
+
+// CryptoSuiteInfo interface
+
+var _ CryptoSuiteInfo = (*Ed25519Signature2020Suite)(nil)
+
+func (Ed25519Signature2020Suite) ID() string {
+	return Ed25519Signature2020SuiteID
+}
+
+func (Ed25519Signature2020Suite) Type() LDKeyType {
+	return Ed25519Signature2020SuiteType
+}
+
+func (Ed25519Signature2020Suite) CanonicalizationAlgorithm() string {
+	return Ed25519Signature2020CanonicalizationAlgorithm
+}
+
+func (Ed25519Signature2020Suite) MessageDigestAlgorithm() gocrypto.Hash {
+	return Ed25519Signature2020DigestAlgorithm
+}
+
+func (Ed25519Signature2020Suite) SignatureAlgorithm() SignatureType {
+	return Ed25519Signature2020
+}
+
+func (Ed25519Signature2020Suite) RequiredContexts() []string {
+	return []string{Ed25519Signature2020Context}
+}
+
+func (e Ed25519Signature2020Suite) Sign(s Signer, p Provable) error {
+	// create proof before running the create verify hash algorithm
+	proof := e.createProof(e.newProofOptions(s, p))
+
+	// prepare proof options
+	contexts, err := GetContextsFromProvable(p)
+	if err != nil {
+		return errors.Wrap(err, "getting contexts from provable")
+	}
+
+	// make sure the suite's context(s) are included
+	contexts = ensureRequiredContexts(contexts, e.RequiredContexts())
+	opts := &ProofOptions{Contexts: contexts}
+
+	// 3. tbs value as a result of create verify hash
+	var genericProvable map[string]any
+	pBytes, err := json.Marshal(p)
+	if err != nil {
+		return errors.Wrap(err, "marshaling provable")
+	}
+	if err = json.Unmarshal(pBytes, &genericProvable); err != nil {
+		return errors.Wrap(err, "unmarshaling provable")
+	}
+	tbs, err := e.CreateVerifyHash(genericProvable, proof, opts)
+	if err != nil {
+		return errors.Wrap(err, "running create verify hash algorithm")
+	}
+
+	// 4 & 5. create the signature over the provable data and encode it as a multibase proof value
+	signature, err := s.Sign(tbs)
+	if err != nil {
+		return errors.Wrap(err, "signing provable value")
+	}
+	proofValue, err := crypto.MultibaseEncode(crypto.Base58BTCMultibase, signature)
+	if err != nil {
+		return errors.Wrap(err, "encoding proof value")
+	}
+
+	// set the signature on the proof object and return
+	proof.ProofValue = proofValue
+	genericProof := crypto.Proof(proof)
+	p.SetProof(&genericProof)
+	return nil
+}
+
+func (e Ed25519Signature2020Suite) Verify(v Verifier, p Provable) error {
+	proof := p.GetProof()
+	if proof == nil {
+		return errors.New("provable has no proof")
+	}
+	gotProof, err := Ed25519Signature2020ProofFromGenericProof(*proof)
+	if err != nil {
+		return errors.Wrap(err, "coercing proof into Ed25519Signature2020 proof")
+	}
+	if gotProof.Type != e.SignatureAlgorithm() {
+		return fmt.Errorf("unexpected proof type: %s", gotProof.Type)
+	}
+	if err = e.checkProofBinding(gotProof.Challenge, gotProof.Domain); err != nil {
+		return err
+	}
+	if err = e.checkProofPurpose(gotProof.ProofPurpose, gotProof.VerificationMethod); err != nil {
+		return err
+	}
+
+	// remove proof before verifying
+	p.SetProof(nil)
+
+	// make sure we set it back after we're done verifying
+	defer p.SetProof(proof)
+
+	// remove the proof value in the proof before verification
+	encoding, signature, err := crypto.MultibaseDecode(gotProof.ProofValue)
+	if err != nil {
+		return errors.Wrap(err, "decoding proof value")
+	}
+	if encoding != crypto.Base58BTCMultibase {
+		return fmt.Errorf("proof value must be base58btc multibase encoded, got: %c", encoding)
+	}
+	gotProof.ProofValue = ""
+
+	// prepare proof options
+	contexts, err := GetContextsFromProvable(p)
+	if err != nil {
+		return errors.Wrap(err, "getting contexts from provable")
+	}
+
+	// make sure the suite's context(s) are included
+	contexts = ensureRequiredContexts(contexts, e.RequiredContexts())
+	opts := &ProofOptions{Contexts: contexts}
+
+	// run the create verify hash algorithm on both provable and the proof
+	var genericProvable map[string]any
+	pBytes, err := json.Marshal(p)
+	if err != nil {
+		return errors.Wrap(err, "marshaling provable")
+	}
+	if err = json.Unmarshal(pBytes, &genericProvable); err != nil {
+		return errors.Wrap(err, "unmarshaling provable")
+	}
+	tbv, err := e.CreateVerifyHash(genericProvable, gotProof, opts)
+	if err != nil {
+		return errors.Wrap(err, "running create verify hash algorithm")
+	}
+
+	if err = v.Verify(tbv, signature); err != nil {
+		return errors.Wrap(err, "verifying Ed25519 signature")
+	}
+	return nil
+}
+
+// CryptoSuiteProofType interface
+
+var _ CryptoSuiteProofType = (*Ed25519Signature2020Suite)(nil)
+
+func (Ed25519Signature2020Suite) Marshal(data any) ([]byte, error) {
+	// JSONify the provable object
+	jsonBytes, err := json.Marshal(data)
+	if err != nil {
+		return nil, err
+	}
+	return jsonBytes, nil
+}
+
+func (e Ed25519Signature2020Suite) Canonicalize(marshaled []byte) (*string, error) {
+	// the LD library anticipates a generic golang json object to normalize
+	var generic map[string]any
+	if err := json.Unmarshal(marshaled, &generic); err != nil {
+		return nil, err
+	}
+	normalized, err := LDNormalize(generic, e.ldOption())
+	if err != nil {
+		return nil, errors.Wrap(err, "canonicalizing provable document")
+	}
+	canonicalString := normalized.(string)
+	return &canonicalString, nil
+}
+
+// CreateVerifyHash hashes the canonicalized proof options, which include the proof's verification method, created
+// timestamp, and proof purpose, and appends the hash of the canonicalized document, so that the signature covers both
+// https://w3c-ccg.github.io/di-eddsa-2020/#hashing-ed25519signature2020
+func (e Ed25519Signature2020Suite) CreateVerifyHash(doc map[string]any, proof crypto.Proof, opts *ProofOptions) ([]byte, error) {
+	// first, make sure "created" exists in the proof and insert an LD context property for the proof vocabulary
+	preparedProof, err := e.prepareProof(proof, opts)
+	if err != nil {
+		return nil, errors.Wrap(err, "preparing proof for the create verify hash algorithm")
+	}
+
+	// marshal doc to prepare for canonicalizaiton
+	marshaledProvable, err := e.Marshal(doc)
+	if err != nil {
+		return nil, errors.Wrap(err, "marshalling doc")
+	}
+
+	// canonicalize doc using the suite's method
+	canonicalProvable, err := e.Canonicalize(marshaledProvable)
+	if err != nil {
+		return nil, errors.Wrap(err, "canonicalizing doc")
+	}
+
+	// marshal proof to prepare for canonicalizaiton
+	marshaledOptions, err := e.Marshal(preparedProof)
+	if err != nil {
+		return nil, errors.Wrap(err, "marshalling proof")
+	}
+
+	// 4.1 canonicalize  proof using the suite's method
+	canonicalizedOptions, err := e.Canonicalize(marshaledOptions)
+	if err != nil {
+		return nil, errors.Wrap(err, "canonicalizing proof")
+	}
+
+	// 4.2 set output to the result of the hash of the canonicalized options document
+	optionsDigest, err := e.Digest([]byte(*canonicalizedOptions))
+	if err != nil {
+		return nil, errors.Wrap(err, "taking digest of proof")
+	}
+
+	// 4.3 hash the canonicalized doc and append it to the output
+	documentDigest, err := e.Digest([]byte(*canonicalProvable))
+	if err != nil {
+		return nil, errors.Wrap(err, "taking digest of doc")
+	}
+
+	// 5. return the output
+	output := append(optionsDigest, documentDigest...)
+	return output, nil
+}
+
+func (e Ed25519Signature2020Suite) Digest(tbd []byte) ([]byte, error) {
+	if e.MessageDigestAlgorithm() != gocrypto.SHA256 {
+		return nil, fmt.Errorf("unexpected digest algorithm: %s", e.MessageDigestAlgorithm().String())
+	}
+	hash := sha256.Sum256(tbd)
+	return hash[:], nil
+}
+
+func (e Ed25519Signature2020Suite) prepareProof(proof crypto.Proof, opts *ProofOptions) (*crypto.Proof, error) {
+	proofBytes, err := json.Marshal(proof)
+	if err != nil {
+		return nil, err
+	}
+
+	var genericProof map[string]any
+	if err = json.Unmarshal(proofBytes, &genericProof); err != nil {
+		return nil, err
+	}
+
+	// proof cannot have a proof value
+	delete(genericProof, "proofValue")
+
+	// make sure the proof has a timestamp
+	created, ok := genericProof["created"]
+	if !ok || created == "" {
+		genericProof["created"] = GetRFC3339Timestamp()
+	}
+
+	var contexts []any
+	if opts != nil && len(opts.Contexts) > 0 {
+		contexts = opts.Contexts
+	} else {
+		// if none provided, make sure the proof has a context value for this suite
+		contexts = ArrayStrToInterface(e.RequiredContexts())
+	}
+	genericProof["@context"] = withProofChainContext(contexts, genericProof)
+	p := crypto.Proof(genericProof)
+	return &p, nil
+}
+
+type Ed25519Signature2020Proof struct {
+	ID                 string        `json:"id,omitempty"`
+	Type               SignatureType `json:"type,omitempty"`
+	Created            string        `json:"created,omitempty"`
+	VerificationMethod string        `json:"verificationMethod,omitempty"`
+	ProofPurpose       ProofPurpose  `json:"proofPurpose,omitempty"`
+	Challenge          string        `json:"challenge,omitempty"`
+	Domain             string        `json:"domain,omitempty"`
+	PreviousProof      any           `json:"previousProof,omitempty"`
+	ProofValue         string        `json:"proofValue,omitempty"`
+}
+
+func Ed25519Signature2020ProofFromGenericProof(p crypto.Proof) (*Ed25519Signature2020Proof, error) {
+	proofBytes, err := json.Marshal(p)
+	if err != nil {
+		return nil, err
+	}
+	var result Ed25519Signature2020Proof
+	if err = json.Unmarshal(proofBytes, &result); err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
+
+func (e *Ed25519Signature2020Proof) ToGenericProof() crypto.Proof {
+	return e
+}
+
+func (e Ed25519Signature2020Suite) createProof(opts ProofOptions) Ed25519Signature2020Proof {
+	return Ed25519Signature2020Proof{
+		ID:                 opts.ID,
+		Type:               e.SignatureAlgorithm(),
+		Created:            opts.Created,
+		VerificationMethod: opts.VerificationMethod,
+		ProofPurpose:       opts.ProofPurpose,
+		Challenge:          opts.Challenge,
+		Domain:             opts.Domain,
+		PreviousProof:      opts.PreviousProof,
+	}
+}
@@ -0,0 +1,206 @@
+package cryptosuite
+
+import (
+	"crypto/ed25519"
+	"encoding/hex"
+	"testing"
+	"time"
+
+	"github.com/TBD54566975/ssi-sdk/crypto"
+	"github.com/goccy/go-json"
+	ariesjsonld "github.com/hyperledger/aries-framework-go/pkg/doc/signature/jsonld"
+	ariesproof "github.com/hyperledger/aries-framework-go/pkg/doc/signature/proof"
+	ariessigner "github.com/hyperledger/aries-framework-go/pkg/doc/signature/signer"
+	ariessuite "github.com/hyperledger/aries-framework-go/pkg/doc/signature/suite"
+	ariesed25519 "github.com/hyperledger/aries-framework-go/pkg/doc/signature/suite/ed25519signature2020"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestEd25519Signature2020Suite(t *testing.T) {
+	suite := GetEd25519Signature2020Suite()
+
+	pubKey, privKey, err := crypto.GenerateEd25519Key()
+	require.NoError(t, err)
+	signer := NewEd25519Signer("did:example:123#key-1", privKey, AssertionMethod)
+	verifier := NewEd25519Verifier("did:example:123#key-1", pubKey)
+
+	getSignedCred := func(tt *testing.T) TestCredential {
+		testCred := TestCredential{
+			Context:      []any{"https://www.w3.org/2018/credentials/v1"},
+			Type:         []string{"VerifiableCredential"},
+			Issuer:       "did:example:123",
+			IssuanceDate: "2021-01-01T19:23:24Z",
+			CredentialSubject: map[string]any{
+				"id": "did:example:abcd",
+			},
+		}
+		require.NoError(tt, suite.Sign(signer, &testCred))
+		return testCred
+	}
+
+	t.Run("sign and verify", func(tt *testing.T) {
+		testCred := getSignedCred(tt)
+		require.NotEmpty(tt, testCred.Proof)
+
+		proof, err := Ed25519Signature2020ProofFromGenericProof(*testCred.Proof)
+		assert.NoError(tt, err)
+		assert.Equal(tt, Ed25519Signature2020, proof.Type)
+		assert.Equal(tt, "did:example:123#key-1", proof.VerificationMethod)
+		assert.Equal(tt, AssertionMethod, proof.ProofPurpose)
+		assert.NotEmpty(tt, proof.Created)
+		assert.Equal(tt, byte('z'), proof.ProofValue[0])
+
+		assert.NoError(tt, suite.Verify(verifier, &testCred))
+	})
+
+	t.Run("wrong key", func(tt *testing.T) {
+		testCred := getSignedCred(tt)
+		otherPubKey, _, err := crypto.GenerateEd25519Key()
+		require.NoError(tt, err)
+		assert.Error(tt, suite.Verify(NewEd25519Verifier("did:example:123#key-1", otherPubKey), &testCred))
+	})
+
+	t.Run("tampered document", func(tt *testing.T) {
+		testCred := getSignedCred(tt)
+		testCred.Issuer = "did:example:456"
+		assert.ErrorContains(tt, suite.Verify(verifier, &testCred), "verifying Ed25519 signature")
+	})
+
+	t.Run("proof options are covered by the signature", func(tt *testing.T) {
+		tamperings := map[string]func(p *Ed25519Signature2020Proof){
+			"verification method": func(p *Ed25519Signature2020Proof) { p.VerificationMethod = "did:example:123#key-2" },
+			"created":             func(p *Ed25519Signature2020Proof) { p.Created = "2000-01-01T00:00:00Z" },
+			"proof purpose":       func(p *Ed25519Signature2020Proof) { p.ProofPurpose = Authentication },
+		}
+		for name, tamper := range tamperings {
+			testCred := getSignedCred(tt)
+			proof, err := Ed25519Signature2020ProofFromGenericProof(*testCred.Proof)
+			require.NoError(tt, err)
+			tamper(proof)
+			genericProof := proof.ToGenericProof()
+			testCred.SetProof(&genericProof)
+			assert.ErrorContains(tt, suite.Verify(verifier, &testCred), "verifying Ed25519 signature", name)
+		}
+	})
+
+	t.Run("invalid proof value", func(tt *testing.T) {
+		testCred := getSignedCred(tt)
+		proof, err := Ed25519Signature2020ProofFromGenericProof(*testCred.Proof)
+		require.NoError(tt, err)
+		proof.ProofValue = "u" + proof.ProofValue[1:]
+		genericProof := proof.ToGenericProof()
+		testCred.SetProof(&genericProof)
+		assert.Error(tt, suite.Verify(verifier, &testCred))
+	})
+}
+
+// The credential and proof options of the example in https://w3c-ccg.github.io/di-eddsa-2020/#example, signed with
+// the key of https://www.rfc-editor.org/rfc/rfc8032#section-7.1 TEST 1. The expected proof value is checked against the
+// independent Ed25519Signature2020 implementation of aries-framework-go, so the vector does not only check this suite
+// against itself. All contexts are served by the embedded document loader.
+func TestEd25519Signature2020TestVector(t *testing.T) {
+	disableNetwork(t)
+
+	seed, err := hex.DecodeString("9d61b19deffd5a60ba844af492ec2cc44449c5697b326919703bac031cae7f60")
+	require.NoError(t, err)
+	privKey := ed25519.NewKeyFromSeed(seed)
+	pubKey := privKey.Public().(ed25519.PublicKey)
+
+	const (
+		verificationMethod = "https://example.com/issuer/123#key-0"
+		created            = "2019-12-11T03:50:55Z"
+		expectedProofValue = "z2627PR4eH2sD12JJp1ki5pWibaRvQTDDc7ZhRWkK56mVu4ZT4N5168DTYwWgQYoft1SURkowJayjLC1oz4Qgh9Gm"
+	)
+	unsignedCredJSON := `{
+  "@context": [
+    "https://www.w3.org/2018/credentials/v1",
+    "https://www.w3.org/2018/credentials/examples/v1",
+    "https://w3id.org/security/suites/ed25519-2020/v1"
+  ],
+  "id": "http://example.gov/credentials/3732",
+  "type": ["VerifiableCredential", "UniversityDegreeCredential"],
+  "issuer": "https://example.com/issuer/123",
+  "issuanceDate": "2020-03-10T04:24:12.164Z",
+  "credentialSubject": {
+    "id": "did:example:456",
+    "degree": {
+      "type": "BachelorDegree",
+      "name": "Bachelor of Science and Arts"
+    }
+  }
+}`
+
+	t.Run("matches aries-framework-go", func(tt *testing.T) {
+		createdTime, err := time.Parse(time.RFC3339, created)
+		require.NoError(tt, err)
+		ariesSigner := ariessigner.New(ariesed25519.New(ariessuite.WithSigner(ed25519KeySigner(privKey))))
+		signedCredJSON, err := ariesSigner.Sign(&ariessigner.Context{
+			SignatureType:           ariesed25519.SignatureType,
+			SignatureRepresentation: ariesproof.SignatureProofValue,
+			Created:                 &createdTime,
+			VerificationMethod:      verificationMethod,
+			Purpose:                 string(AssertionMethod),
+		}, []byte(unsignedCredJSON), ariesjsonld.WithDocumentLoader(NewDefaultDocumentLoader()))
+		require.NoError(tt, err)
+
+		var signedCred struct {
+			Proof []struct {
+				ProofValue string `json:"proofValue"`
+			} `json:"proof"`
+		}
+		require.NoError(tt, json.Unmarshal(signedCredJSON, &signedCred))
+		require.Len(tt, signedCred.Proof, 1)
+		assert.Equal(tt, expectedProofValue, signedCred.Proof[0].ProofValue)
+	})
+
+	var cred TestCredential
+	require.NoError(t, json.Unmarshal([]byte(unsignedCredJSON), &cred))
+	knownProof := Ed25519Signature2020Proof{
+		Type:               Ed25519Signature2020,
+		Created:            created,
+		VerificationMethod: verificationMethod,
+		ProofPurpose:       AssertionMethod,
+	}
+
+	t.Run("verifies", func(tt *testing.T) {
+		signedCred := cred
+		signedProof := knownProof
+		signedProof.ProofValue = expectedProofValue
+		genericProof := signedProof.ToGenericProof()
+		signedCred.SetProof(&genericProof)
+
+		suite := Ed25519Signature2020Suite{}
+		verifier := NewEd25519Verifier(verificationMethod, pubKey)
+		assert.NoError(tt, suite.Verify(verifier, &signedCred))
+	})
+
+	t.Run("signing reproduces the proof value", func(tt *testing.T) {
+		var genericCred map[string]any
+		credBytes, err := json.Marshal(cred)
+		require.NoError(tt, err)
+		require.NoError(tt, json.Unmarshal(credBytes, &genericCred))
+
+		suite := Ed25519Signature2020Suite{}
+		contexts, err := GetContextsFromProvable(&cred)
+		require.NoError(tt, err)
+		tbs, err := suite.CreateVerifyHash(genericCred, knownProof, &ProofOptions{Contexts: contexts})
+		require.NoError(tt, err)
+		signature, err := NewEd25519Signer(verificationMethod, privKey, AssertionMethod).Sign(tbs)
+		require.NoError(tt, err)
+		proofValue, err := crypto.MultibaseEncode(crypto.Base58BTCMultibase, signature)
+		require.NoError(tt, err)
+		assert.Equal(tt, expectedProofValue, proofValue)
+	})
+}
+
+// ed25519KeySigner signs with an Ed25519 private key for the aries-framework-go signature suites
+type ed25519KeySigner ed25519.PrivateKey
+
+func (s ed25519KeySigner) Sign(data []byte) ([]byte, error) {
+	return ed25519.Sign(ed25519.PrivateKey(s), data), nil
+}
+
+func (ed25519KeySigner) Alg() string {
+	return ""
+}
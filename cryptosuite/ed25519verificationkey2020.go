@@ -0,0 +1,80 @@
+package cryptosuite
+
+import (
+	"crypto/ed25519"
+
+	"github.com/TBD54566975/ssi-sdk/crypto"
+)
+
+// Ed25519Signer signs with an Ed25519 private key, producing the raw signatures used by Ed25519Signature2020
+// https://w3c-ccg.github.io/di-eddsa-2020/#ed25519verificationkey2020
+type Ed25519Signer struct {
+	kid     string
+	privKey ed25519.PrivateKey
+	purpose ProofPurpose
+	format  PayloadFormat
+}
+
+func NewEd25519Signer(kid string, privKey ed25519.PrivateKey, purpose ProofPurpose) *Ed25519Signer {
+	return &Ed25519Signer{
+		kid:     kid,
+		privKey: privKey,
+		purpose: purpose,
+	}
+}
+
+// Sign returns the Ed25519 signature of the message `tbs`
+func (s *Ed25519Signer) Sign(tbs []byte) ([]byte, error) {
+	return crypto.Sign(crypto.Ed25519, s.privKey, tbs)
+}
+
+func (s *Ed25519Signer) GetKeyID() string {
+	return s.kid
+}
+
+func (*Ed25519Signer) GetSignatureType() SignatureType {
+	return Ed25519Signature2020
+}
+
+func (*Ed25519Signer) GetSigningAlgorithm() string {
+	return string(Ed25519Signature2020)
+}
+
+func (s *Ed25519Signer) SetProofPurpose(purpose ProofPurpose) {
+	s.purpose = purpose
+}
+
+func (s *Ed25519Signer) GetProofPurpose() ProofPurpose {
+	return s.purpose
+}
+
+func (s *Ed25519Signer) SetPayloadFormat(format PayloadFormat) {
+	s.format = format
+}
+
+func (s *Ed25519Signer) GetPayloadFormat() PayloadFormat {
+	return s.format
+}
+
+// Ed25519Verifier verifies the raw Ed25519 signatures used by Ed25519Signature2020
+type Ed25519Verifier struct {
+	kid    string
+	pubKey ed25519.PublicKey
+}
+
+func NewEd25519Verifier(kid string, pubKey ed25519.PublicKey) *Ed25519Verifier {
+	return &Ed25519Verifier{
+		kid:    kid,
+		pubKey: pubKey,
+	}
+}
+
+// Verify attempts to verify a `signature` against a given `message`, returning nil if the verification is successful
+// and an error should it fail.
+func (v Ed25519Verifier) Verify(message, signature []byte) error {
+	return crypto.Verify(crypto.Ed25519, v.pubKey, message, signature)
+}
+
+func (v Ed25519Verifier) GetKeyID() string {
+	return v.kid
+}
@@ -0,0 +1,300 @@
+package cryptosuite
+
+import (
+	gocrypto "crypto"
+	"crypto/sha256"
+	"fmt"
+
+	"github.com/TBD54566975/ssi-sdk/crypto"
+	. "github.com/TBD54566975/ssi-sdk/util"
+	"github.com/goccy/go-json"
+	"github.com/gowebpki/jcs"
+	"github.com/pkg/errors"
+)
+
+// https://www.w3.org/TR/vc-di-eddsa/#eddsa-jcs-2022
+
+const (
+	DataIntegrityProofType                SignatureType = "DataIntegrityProof"
+	EdDSAJCS2022Cryptosuite               string        = "eddsa-jcs-2022"
+	EdDSAJCS2022SuiteID                   string        = "https://www.w3.org/TR/vc-di-eddsa/#eddsa-jcs-2022"
+	EdDSAJCS2022SuiteType                 LDKeyType     = MultikeyType
+	EdDSAJCS2022CanonicalizationAlgorithm string        = "https://www.rfc-editor.org/rfc/rfc8785"
+	EdDSAJCS2022DigestAlgorithm           gocrypto.Hash = gocrypto.SHA256
+)
+
+// JCSCanonicalize serializes a document with the JSON Canonicalization Scheme https://www.rfc-editor.org/rfc/rfc8785
+func JCSCanonicalize(doc map[string]any) ([]byte, error) {
+	docBytes, err := json.Marshal(doc)
+	if err != nil {
+		return nil, errors.Wrap(err, "marshaling doc")
+	}
+	canonical, err := jcs.Transform(docBytes)
+	if err != nil {
+		return nil, errors.Wrap(err, "canonicalizing doc")
+	}
+	return canonical, nil
+}
+
+// EdDSAJCS2022Suite signs with Ed25519 keys over the JCS serialization of a document and its proof options, so it
+// does not require JSON-LD processing and may be used with documents that have no @context
+type EdDSAJCS2022Suite struct {
+	suiteOptions
+}
+
+func GetEdDSAJCS2022Suite(opts ...SuiteOption) CryptoSuite {
+	return &EdDSAJCS2022Suite{suiteOptions: newSuiteOptions(opts)}
+}
+
+// CryptoSuiteInfo interface
+
+var _ CryptoSuiteInfo = (*EdDSAJCS2022Suite)(nil)
+
+func (EdDSAJCS2022Suite) ID() string {
+	return EdDSAJCS2022SuiteID
+}
+
+func (EdDSAJCS2022Suite) Type() LDKeyType {
+	return EdDSAJCS2022SuiteType
+}
+
+func (EdDSAJCS2022Suite) CanonicalizationAlgorithm() string {
+	return EdDSAJCS2022CanonicalizationAlgorithm
+}
+
+func (EdDSAJCS2022Suite) MessageDigestAlgorithm() gocrypto.Hash {
+	return EdDSAJCS2022DigestAlgorithm
+}
+
+func (EdDSAJCS2022Suite) SignatureAlgorithm() SignatureType {
+	return DataIntegrityProofType
+}
+
+// RequiredContexts is empty, as no JSON-LD processing takes place
+func (EdDSAJCS2022Suite) RequiredContexts() []string {
+	return nil
+}
+
+func (e EdDSAJCS2022Suite) Sign(s Signer, p Provable) error {
+	// create proof before running the create verify hash algorithm
+	proof := e.createProof(e.newProofOptions(s, p))
+
+	// 3. tbs value as a result of create verify hash
+	var genericProvable map[string]any
+	pBytes, err := json.Marshal(p)
+	if err != nil {
+		return errors.Wrap(err, "marshaling provable")
+	}
+	if err = json.Unmarshal(pBytes, &genericProvable); err != nil {
+		return errors.Wrap(err, "unmarshaling provable")
+	}
+	tbs, err := e.CreateVerifyHash(genericProvable, proof, nil)
+	if err != nil {
+		return errors.Wrap(err, "running create verify hash algorithm")
+	}
+
+	// 4 & 5. create the signature over the provable data and encode it as a multibase proof value
+	signature, err := s.Sign(tbs)
+	if err != nil {
+		return errors.Wrap(err, "signing provable value")
+	}
+	proofValue, err := crypto.MultibaseEncode(crypto.Base58BTCMultibase, signature)
+	if err != nil {
+		return errors.Wrap(err, "encoding proof value")
+	}
+
+	// set the signature on the proof object and return
+	proof.ProofValue = proofValue
+	genericProof := crypto.Proof(proof)
+	p.SetProof(&genericProof)
+	return nil
+}
+
+func (e EdDSAJCS2022Suite) Verify(v Verifier, p Provable) error {
+	proof := p.GetProof()
+	if proof == nil {
+		return errors.New("provable has no proof")
+	}
+	gotProof, err := DataIntegrityProofFromGenericProof(*proof)
+	if err != nil {
+		return errors.Wrap(err, "coercing proof into DataIntegrityProof proof")
+	}
+	if gotProof.Type != e.SignatureAlgorithm() {
+		return fmt.Errorf("unexpected proof type: %s", gotProof.Type)
+	}
+	if gotProof.Cryptosuite != EdDSAJCS2022Cryptosuite {
+		return fmt.Errorf("unexpected cryptosuite: %s", gotProof.Cryptosuite)
+	}
+	if err = e.checkProofBinding(gotProof.Challenge, gotProof.Domain); err != nil {
+		return err
+	}
+	if err = e.checkProofPurpose(gotProof.ProofPurpose, gotProof.VerificationMethod); err != nil {
+		return err
+	}
+
+	// remove proof before verifying
+	p.SetProof(nil)
+
+	// make sure we set it back after we're done verifying
+	defer p.SetProof(proof)
+
+	// remove the proof value in the proof before verification
+	encoding, signature, err := crypto.MultibaseDecode(gotProof.ProofValue)
+	if err != nil {
+		return errors.Wrap(err, "decoding proof value")
+	}
+	if encoding != crypto.Base58BTCMultibase {
+		return fmt.Errorf("proof value must be base58btc multibase encoded, got: %c", encoding)
+	}
+	gotProof.ProofValue = ""
+
+	// run the create verify hash algorithm on both provable and the proof
+	var genericProvable map[string]any
+	pBytes, err := json.Marshal(p)
+	if err != nil {
+		return errors.Wrap(err, "marshaling provable")
+	}
+	if err = json.Unmarshal(pBytes, &genericProvable); err != nil {
+		return errors.Wrap(err, "unmarshaling provable")
+	}
+	tbv, err := e.CreateVerifyHash(genericProvable, gotProof, nil)
+	if err != nil {
+		return errors.Wrap(err, "running create verify hash algorithm")
+	}
+
+	if err = v.Verify(tbv, signature); err != nil {
+		return errors.Wrap(err, "verifying Ed25519 signature")
+	}
+	return nil
+}
+
+// CryptoSuiteProofType interface
+
+var _ CryptoSuiteProofType = (*EdDSAJCS2022Suite)(nil)
+
+func (EdDSAJCS2022Suite) Marshal(data any) ([]byte, error) {
+	// JSONify the provable object
+	jsonBytes, err := json.Marshal(data)
+	if err != nil {
+		return nil, err
+	}
+	return jsonBytes, nil
+}
+
+func (EdDSAJCS2022Suite) Canonicalize(marshaled []byte) (*string, error) {
+	canonical, err := jcs.Transform(marshaled)
+	if err != nil {
+		return nil, errors.Wrap(err, "canonicalizing provable document")
+	}
+	canonicalString := string(canonical)
+	return &canonicalString, nil
+}
+
+// CreateVerifyHash hashes the JCS serialization of the proof configuration, which is the proof without its proof
+// value and with the @context of the document, and appends the hash of the JCS serialization of the document
+// https://www.w3.org/TR/vc-di-eddsa/#hashing-eddsa-jcs-2022
+func (e EdDSAJCS2022Suite) CreateVerifyHash(doc map[string]any, proof crypto.Proof, _ *ProofOptions) ([]byte, error) {
+	// make sure "created" exists in the proof, and remove its proof value
+	proofConfig, err := e.prepareProof(proof, doc["@context"])
+	if err != nil {
+		return nil, errors.Wrap(err, "preparing proof for the create verify hash algorithm")
+	}
+
+	canonicalProvable, err := JCSCanonicalize(doc)
+	if err != nil {
+		return nil, errors.Wrap(err, "canonicalizing doc")
+	}
+	canonicalProofConfig, err := JCSCanonicalize(proofConfig)
+	if err != nil {
+		return nil, errors.Wrap(err, "canonicalizing proof")
+	}
+
+	proofConfigDigest, err := e.Digest(canonicalProofConfig)
+	if err != nil {
+		return nil, errors.Wrap(err, "taking digest of proof")
+	}
+	documentDigest, err := e.Digest(canonicalProvable)
+	if err != nil {
+		return nil, errors.Wrap(err, "taking digest of doc")
+	}
+	return append(proofConfigDigest, documentDigest...), nil
+}
+
+func (e EdDSAJCS2022Suite) Digest(tbd []byte) ([]byte, error) {
+	if e.MessageDigestAlgorithm() != gocrypto.SHA256 {
+		return nil, fmt.Errorf("unexpected digest algorithm: %s", e.MessageDigestAlgorithm().String())
+	}
+	hash := sha256.Sum256(tbd)
+	return hash[:], nil
+}
+
+// prepareProof returns the proof configuration: the proof without a proof value or jws, carrying the document's
+// @context if it has one
+func (e EdDSAJCS2022Suite) prepareProof(proof crypto.Proof, context any) (map[string]any, error) {
+	genericProof, err := ToJSONMap(proof)
+	if err != nil {
+		return nil, err
+	}
+
+	// proof cannot have a proof value
+	delete(genericProof, "proofValue")
+	delete(genericProof, "jws")
+
+	// make sure the proof has a timestamp
+	created, ok := genericProof["created"]
+	if !ok || created == "" {
+		genericProof["created"] = GetRFC3339Timestamp()
+	}
+
+	if context != nil {
+		genericProof["@context"] = context
+	} else {
+		delete(genericProof, "@context")
+	}
+	return genericProof, nil
+}
+
+// DataIntegrityProof is a proof of the DataIntegrityProof type, whose cryptosuite property names the suite
+// https://www.w3.org/TR/vc-data-integrity/#dataintegrityproof
+type DataIntegrityProof struct {
+	ID                 string        `json:"id,omitempty"`
+	Type               SignatureType `json:"type,omitempty"`
+	Cryptosuite        string        `json:"cryptosuite,omitempty"`
+	Created            string        `json:"created,omitempty"`
+	VerificationMethod string        `json:"verificationMethod,omitempty"`
+	ProofPurpose       ProofPurpose  `json:"proofPurpose,omitempty"`
+	Challenge          string        `json:"challenge,omitempty"`
+	Domain             string        `json:"domain,omitempty"`
+	PreviousProof      any           `json:"previousProof,omitempty"`
+	ProofValue         string        `json:"proofValue,omitempty"`
+}
+
+func DataIntegrityProofFromGenericProof(p crypto.Proof) (*DataIntegrityProof, error) {
+	proofBytes, err := json.Marshal(p)
+	if err != nil {
+		return nil, err
+	}
+	var result DataIntegrityProof
+	if err = json.Unmarshal(proofBytes, &result); err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
+
+func (d *DataIntegrityProof) ToGenericProof() crypto.Proof {
+	return d
+}
+
+func (e EdDSAJCS2022Suite) createProof(opts ProofOptions) DataIntegrityProof {
+	return DataIntegrityProof{
+		ID:                 opts.ID,
+		Type:               e.SignatureAlgorithm(),
+		Cryptosuite:        EdDSAJCS2022Cryptosuite,
+		Created:            opts.Created,
+		VerificationMethod: opts.VerificationMethod,
+		ProofPurpose:       opts.ProofPurpose,
+		Challenge:          opts.Challenge,
+		Domain:             opts.Domain,
+		PreviousProof:      opts.PreviousProof,
+	}
+}
@@ -0,0 +1,212 @@
+package cryptosuite
+
+import (
+	"math"
+	"testing"
+
+	"github.com/TBD54566975/ssi-sdk/crypto"
+	"github.com/goccy/go-json"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestJCSCanonicalize(t *testing.T) {
+	t.Run("rfc 8785 example", func(tt *testing.T) {
+		// https://www.rfc-editor.org/rfc/rfc8785#section-3.2.2
+		input := `{
+  "numbers": [333333333.33333329, 1E30, 4.50, 2e-3, 0.000000000000000000000000001],
+  "string": "\u20ac$\u000F\u000aA'\u0042\u0022\u005c\\\"\/",
+  "literals": [null, true, false]
+}`
+		var doc map[string]any
+		require.NoError(tt, json.Unmarshal([]byte(input), &doc))
+		canonical, err := JCSCanonicalize(doc)
+		require.NoError(tt, err)
+		assert.Equal(tt, `{"literals":[null,true,false],"numbers":[333333333.3333333,1e+30,4.5,0.002,1e-27],"string":"€$\u000f\nA'B\"\\\\\"/"}`, string(canonical))
+	})
+
+	t.Run("properties are sorted by their utf-16 code units", func(tt *testing.T) {
+		// https://www.rfc-editor.org/rfc/rfc8785#section-3.2.3
+		doc := map[string]any{
+			"\u20ac":       "Euro Sign",
+			"\r":           "Carriage Return",
+			"\ufb33":       "Hebrew Letter Dalet With Dagesh",
+			"1":            "One",
+			"\U0001f600":   "Emoji: Grinning Face",
+			"\u0080":       "Control",
+			"\u00f6":       "Latin Small Letter O With Diaeresis",
+			"nested":       map[string]any{"b": 1, "a": []any{map[string]any{"d": true, "c": nil}}},
+			"\u00e9\u00e9": "",
+		}
+		canonical, err := JCSCanonicalize(doc)
+		require.NoError(tt, err)
+		assert.Equal(tt, "{\"\\r\":\"Carriage Return\",\"1\":\"One\",\"nested\":{\"a\":[{\"c\":null,\"d\":true}],\"b\":1},"+
+			"\"\u0080\":\"Control\",\"\u00e9\u00e9\":\"\",\"\u00f6\":\"Latin Small Letter O With Diaeresis\",\"\u20ac\":\"Euro Sign\","+
+			"\"\U0001f600\":\"Emoji: Grinning Face\",\"\ufb33\":\"Hebrew Letter Dalet With Dagesh\"}", string(canonical))
+	})
+
+	t.Run("number formatting", func(tt *testing.T) {
+		// https://www.rfc-editor.org/rfc/rfc8785#appendix-B
+		tests := []struct {
+			bits     uint64
+			expected string
+		}{
+			{0x0000000000000000, "0"},
+			{0x8000000000000000, "0"}, // negative zero
+			{0x0000000000000001, "5e-324"},
+			{0x8000000000000001, "-5e-324"},
+			{0x7fefffffffffffff, "1.7976931348623157e+308"},
+			{0xffefffffffffffff, "-1.7976931348623157e+308"},
+			{0x4340000000000000, "9007199254740992"},
+			{0xc340000000000000, "-9007199254740992"},
+			{0x4430000000000000, "295147905179352830000"},
+			{0x44b52d02c7e14af5, "9.999999999999997e+22"},
+			{0x44b52d02c7e14af6, "1e+23"},
+			{0x44b52d02c7e14af7, "1.0000000000000001e+23"},
+			{0x444b1ae4d6e2ef4e, "999999999999999700000"},
+			{0x444b1ae4d6e2ef4f, "999999999999999900000"},
+			{0x444b1ae4d6e2ef50, "1e+21"},
+			{0x3eb0c6f7a0b5ed8c, "9.999999999999997e-7"},
+			{0x3eb0c6f7a0b5ed8d, "0.000001"},
+			{0x41b3de4355555553, "333333333.3333332"},
+			{0x41b3de4355555554, "333333333.33333325"},
+			{0x41b3de4355555555, "333333333.3333333"},
+			{0x41b3de4355555556, "333333333.3333334"},
+			{0x41b3de4355555557, "333333333.33333343"},
+			{0xbecbf647612f3696, "-0.0000033333333333333333"},
+			{0x43143ff3c1cb0959, "1424953923781206.2"},
+		}
+		for _, test := range tests {
+			canonical, err := JCSCanonicalize(map[string]any{"n": math.Float64frombits(test.bits)})
+			require.NoError(tt, err, test.expected)
+			assert.Equal(tt, `{"n":`+test.expected+`}`, string(canonical))
+		}
+	})
+
+	t.Run("large integers are serialized as doubles", func(tt *testing.T) {
+		canonical, err := JCSCanonicalize(map[string]any{
+			"exact":   int64(9007199254740992),
+			"rounded": int64(9007199254740993),
+			"big":     uint64(18446744073709551615),
+		})
+		require.NoError(tt, err)
+		assert.Equal(tt, `{"big":18446744073709552000,"exact":9007199254740992,"rounded":9007199254740992}`, string(canonical))
+	})
+
+	t.Run("invalid numbers", func(tt *testing.T) {
+		for _, n := range []float64{math.NaN(), math.Inf(1), math.Inf(-1)} {
+			_, err := JCSCanonicalize(map[string]any{"n": n})
+			assert.Error(tt, err)
+		}
+	})
+}
+
+func TestEdDSAJCS2022Suite(t *testing.T) {
+	suite := GetEdDSAJCS2022Suite()
+
+	pubKey, privKey, err := crypto.GenerateEd25519Key()
+	require.NoError(t, err)
+	signer := NewEd25519Signer("did:example:123#key-1", privKey, AssertionMethod)
+	verifier := NewEd25519Verifier("did:example:123#key-1", pubKey)
+
+	getSignedDoc := func(tt *testing.T) GenericProvable {
+		doc := GenericProvable{
+			"type":         []any{"VerifiableCredential"},
+			"issuer":       "did:example:123",
+			"issuanceDate": "2021-01-01T19:23:24Z",
+			"credentialSubject": map[string]any{
+				"id":    "did:example:abcd",
+				"score": 1e21,
+			},
+		}
+		require.NoError(tt, suite.Sign(signer, &doc))
+		return doc
+	}
+
+	t.Run("sign and verify without a context", func(tt *testing.T) {
+		doc := getSignedDoc(tt)
+		require.NotNil(tt, doc.GetProof())
+
+		proof, err := DataIntegrityProofFromGenericProof(*doc.GetProof())
+		require.NoError(tt, err)
+		assert.Equal(tt, DataIntegrityProofType, proof.Type)
+		assert.Equal(tt, EdDSAJCS2022Cryptosuite, proof.Cryptosuite)
+		assert.Equal(tt, "did:example:123#key-1", proof.VerificationMethod)
+		assert.Equal(tt, AssertionMethod, proof.ProofPurpose)
+		assert.Equal(tt, byte('z'), proof.ProofValue[0])
+
+		assert.NoError(tt, suite.Verify(verifier, &doc))
+
+		// a JSON round trip, which reorders properties, does not affect the proof
+		docBytes, err := json.Marshal(doc)
+		require.NoError(tt, err)
+		var roundTripped GenericProvable
+		require.NoError(tt, json.Unmarshal(docBytes, &roundTripped))
+		assert.NoError(tt, suite.Verify(verifier, &roundTripped))
+	})
+
+	t.Run("sign and verify with a context", func(tt *testing.T) {
+		doc := GenericProvable{
+			"@context": []any{W3CCredentialsContext},
+			"type":     []any{"VerifiableCredential"},
+			"issuer":   "did:example:123",
+		}
+		require.NoError(tt, suite.Sign(signer, &doc))
+		assert.NoError(tt, suite.Verify(verifier, &doc))
+
+		// the proof configuration carries the context of the document
+		doc["@context"] = []any{W3CCredentialsContext, "https://example.com/context/v1"}
+		assert.ErrorContains(tt, suite.Verify(verifier, &doc), "verifying Ed25519 signature")
+	})
+
+	t.Run("proof value is excluded from the proof configuration", func(tt *testing.T) {
+		doc := getSignedDoc(tt)
+		proof, err := DataIntegrityProofFromGenericProof(*doc.GetProof())
+		require.NoError(tt, err)
+
+		withProofValue, err := EdDSAJCS2022Suite{}.CreateVerifyHash(withoutProof(doc), proof, nil)
+		require.NoError(tt, err)
+		proof.ProofValue = ""
+		withoutProofValue, err := EdDSAJCS2022Suite{}.CreateVerifyHash(withoutProof(doc), proof, nil)
+		require.NoError(tt, err)
+		assert.Equal(tt, withoutProofValue, withProofValue)
+	})
+
+	t.Run("tampered document", func(tt *testing.T) {
+		doc := getSignedDoc(tt)
+		doc["credentialSubject"].(map[string]any)["score"] = 1e21 + 1e6
+		assert.ErrorContains(tt, suite.Verify(verifier, &doc), "verifying Ed25519 signature")
+	})
+
+	t.Run("tampered proof", func(tt *testing.T) {
+		doc := getSignedDoc(tt)
+		proof, err := DataIntegrityProofFromGenericProof(*doc.GetProof())
+		require.NoError(tt, err)
+		proof.Created = "2000-01-01T00:00:00Z"
+		genericProof := proof.ToGenericProof()
+		doc.SetProof(&genericProof)
+		assert.ErrorContains(tt, suite.Verify(verifier, &doc), "verifying Ed25519 signature")
+	})
+
+	t.Run("wrong key", func(tt *testing.T) {
+		doc := getSignedDoc(tt)
+		otherPubKey, _, err := crypto.GenerateEd25519Key()
+		require.NoError(tt, err)
+		assert.Error(tt, suite.Verify(NewEd25519Verifier("did:example:123#key-1", otherPubKey), &doc))
+	})
+
+	t.Run("wrong cryptosuite", func(tt *testing.T) {
+		doc := getSignedDoc(tt)
+		proof, err := DataIntegrityProofFromGenericProof(*doc.GetProof())
+		require.NoError(tt, err)
+		proof.Cryptosuite = "ecdsa-jcs-2019"
+		genericProof := proof.ToGenericProof()
+		doc.SetProof(&genericProof)
+		assert.ErrorContains(tt, suite.Verify(verifier, &doc), "unexpected cryptosuite")
+	})
+
+	t.Run("verifies as part of a proof set", func(tt *testing.T) {
+		doc := map[string]any(getSignedDoc(tt))
+		assert.NoError(tt, VerifyProofSet(doc, []Verifier{verifier}))
+	})
+}
@@ -0,0 +1,167 @@
+package cryptosuite
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sync"
+
+	"github.com/goccy/go-json"
+	"github.com/piprate/json-gold/ld"
+	"github.com/pkg/errors"
+)
+
+// contextAcceptHeader prefers JSON-LD, falling back to JSON, as the JSON-LD processors' loaders do
+const contextAcceptHeader = "application/ld+json, application/json;q=0.9, */*;q=0.1"
+
+// HTTPContextLoader is a document loader retrieving JSON-LD contexts over HTTP, which caches each context on disk
+// along with its ETag. A cached context is revalidated with If-None-Match each time it is loaded, and the cached copy
+// is returned when the server responds 304 Not Modified, so a long-running service picks up changes to a context
+// without downloading it again while it is unchanged. Contexts served without an ETag are not cached.
+type HTTPContextLoader struct {
+	client   *http.Client
+	cacheDir string
+	// mu serializes access to the cache directory
+	mu sync.Mutex
+}
+
+// cachedContext is the representation of a context in the cache directory
+type cachedContext struct {
+	URL         string          `json:"url"`
+	DocumentURL string          `json:"documentUrl"`
+	ETag        string          `json:"etag"`
+	Document    json.RawMessage `json:"document"`
+}
+
+var _ ld.DocumentLoader = (*HTTPContextLoader)(nil)
+
+// NewHTTPContextLoader creates a loader retrieving contexts with the given client, or http.DefaultClient if nil,
+// and caching them in the given directory, which is created when the first context is cached
+func NewHTTPContextLoader(client *http.Client, cacheDir string) *HTTPContextLoader {
+	if client == nil {
+		client = http.DefaultClient
+	}
+	return &HTTPContextLoader{client: client, cacheDir: cacheDir}
+}
+
+// LoadDocument retrieves the context at the URL, revalidating any cached copy of it
+func (l *HTTPContextLoader) LoadDocument(u string) (*ld.RemoteDocument, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	cached, err := l.readCache(u)
+	if err != nil {
+		return nil, err
+	}
+
+	req, err := http.NewRequest(http.MethodGet, u, http.NoBody)
+	if err != nil {
+		return nil, errors.Wrapf(err, "creating request for context: %s", u)
+	}
+	req.Header.Set("Accept", contextAcceptHeader)
+	if cached != nil && cached.ETag != "" {
+		req.Header.Set("If-None-Match", cached.ETag)
+	}
+	resp, err := l.client.Do(req)
+	if err != nil {
+		return nil, errors.Wrapf(err, "fetching context: %s", u)
+	}
+	defer resp.Body.Close()
+
+	switch {
+	case resp.StatusCode == http.StatusNotModified && cached != nil:
+		return cached.remoteDocument()
+	case resp.StatusCode != http.StatusOK:
+		return nil, fmt.Errorf("fetching context<%s>: unexpected status code: %d", u, resp.StatusCode)
+	}
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, errors.Wrapf(err, "reading context: %s", u)
+	}
+	fetched := cachedContext{
+		URL:         u,
+		DocumentURL: resp.Request.URL.String(),
+		ETag:        resp.Header.Get("ETag"),
+		Document:    body,
+	}
+	doc, err := fetched.remoteDocument()
+	if err != nil {
+		return nil, err
+	}
+	if fetched.ETag == "" {
+		// without an ETag the context cannot be revalidated, so drop any copy cached under a previous ETag
+		if err = os.Remove(l.cachePath(u)); err != nil && !os.IsNotExist(err) {
+			return nil, errors.Wrapf(err, "removing cached context: %s", u)
+		}
+		return doc, nil
+	}
+	if err = l.writeCache(fetched); err != nil {
+		return nil, err
+	}
+	return doc, nil
+}
+
+// cachePath returns the path of the cache file of the context at the URL, named by the URL's hash
+func (l *HTTPContextLoader) cachePath(u string) string {
+	hash := sha256.Sum256([]byte(u))
+	return filepath.Join(l.cacheDir, hex.EncodeToString(hash[:])+".json")
+}
+
+// readCache returns the cached copy of the context at the URL, or nil if it is not cached
+func (l *HTTPContextLoader) readCache(u string) (*cachedContext, error) {
+	cacheBytes, err := os.ReadFile(l.cachePath(u))
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, errors.Wrapf(err, "reading cached context: %s", u)
+	}
+	var cached cachedContext
+	if err = json.Unmarshal(cacheBytes, &cached); err != nil || cached.URL != u {
+		// a corrupt or colliding cache file is treated as a miss, and replaced once the context is fetched
+		return nil, nil
+	}
+	return &cached, nil
+}
+
+// writeCache stores the context in the cache directory, replacing the file atomically so that readers never see a
+// partially written context
+func (l *HTTPContextLoader) writeCache(cached cachedContext) error {
+	cacheBytes, err := json.Marshal(cached)
+	if err != nil {
+		return errors.Wrapf(err, "marshalling cached context: %s", cached.URL)
+	}
+	if err = os.MkdirAll(l.cacheDir, 0o700); err != nil {
+		return errors.Wrapf(err, "creating context cache directory: %s", l.cacheDir)
+	}
+	tmp, err := os.CreateTemp(l.cacheDir, "context-*.tmp")
+	if err != nil {
+		return errors.Wrap(err, "creating cached context file")
+	}
+	defer os.Remove(tmp.Name())
+	if _, err = tmp.Write(cacheBytes); err != nil {
+		_ = tmp.Close()
+		return errors.Wrapf(err, "writing cached context: %s", cached.URL)
+	}
+	if err = tmp.Close(); err != nil {
+		return errors.Wrapf(err, "writing cached context: %s", cached.URL)
+	}
+	if err = os.Rename(tmp.Name(), l.cachePath(cached.URL)); err != nil {
+		return errors.Wrapf(err, "writing cached context: %s", cached.URL)
+	}
+	return nil
+}
+
+func (c cachedContext) remoteDocument() (*ld.RemoteDocument, error) {
+	doc, err := ld.DocumentFromReader(bytes.NewReader(c.Document))
+	if err != nil {
+		return nil, errors.Wrapf(err, "parsing context: %s", c.URL)
+	}
+	return &ld.RemoteDocument{DocumentURL: c.DocumentURL, Document: doc}, nil
+}
@@ -0,0 +1,131 @@
+package cryptosuite
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// contextServer serves a context with its ETag, responding 304 when a request's If-None-Match matches it
+type contextServer struct {
+	mu       sync.Mutex
+	etag     string
+	body     string
+	requests []*http.Request
+	served   int
+}
+
+func (s *contextServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.requests = append(s.requests, r)
+	if s.etag != "" && r.Header.Get("If-None-Match") == s.etag {
+		w.WriteHeader(http.StatusNotModified)
+		return
+	}
+	s.served++
+	if s.etag != "" {
+		w.Header().Set("ETag", s.etag)
+	}
+	w.Header().Set("Content-Type", "application/ld+json")
+	_, _ = w.Write([]byte(s.body))
+}
+
+func TestHTTPContextLoader(t *testing.T) {
+	t.Run("cached context reused on 304", func(tt *testing.T) {
+		contexts := &contextServer{etag: `"v1"`, body: `{"@context":{"name":"https://schema.org/name"}}`}
+		server := httptest.NewServer(contexts)
+		defer server.Close()
+		contextURL := server.URL + "/contexts/v1"
+
+		loader := NewHTTPContextLoader(server.Client(), tt.TempDir())
+		first, err := loader.LoadDocument(contextURL)
+		require.NoError(tt, err)
+		assert.Equal(tt, contextURL, first.DocumentURL)
+		assert.Equal(tt, map[string]any{"@context": map[string]any{"name": "https://schema.org/name"}}, first.Document)
+
+		second, err := loader.LoadDocument(contextURL)
+		require.NoError(tt, err)
+		assert.Equal(tt, first, second)
+
+		require.Len(tt, contexts.requests, 2)
+		assert.Empty(tt, contexts.requests[0].Header.Get("If-None-Match"))
+		assert.Equal(tt, `"v1"`, contexts.requests[1].Header.Get("If-None-Match"))
+		assert.Contains(tt, contexts.requests[1].Header.Get("Accept"), "application/ld+json")
+		assert.Equal(tt, 1, contexts.served)
+	})
+
+	t.Run("cache persists across loaders", func(tt *testing.T) {
+		contexts := &contextServer{etag: `"v1"`, body: `{"@context":{"name":"https://schema.org/name"}}`}
+		server := httptest.NewServer(contexts)
+		defer server.Close()
+		cacheDir := tt.TempDir()
+
+		_, err := NewHTTPContextLoader(server.Client(), cacheDir).LoadDocument(server.URL)
+		require.NoError(tt, err)
+		doc, err := NewHTTPContextLoader(server.Client(), cacheDir).LoadDocument(server.URL)
+		require.NoError(tt, err)
+		assert.Equal(tt, map[string]any{"@context": map[string]any{"name": "https://schema.org/name"}}, doc.Document)
+		assert.Equal(tt, 1, contexts.served)
+	})
+
+	t.Run("changed context refetched", func(tt *testing.T) {
+		contexts := &contextServer{etag: `"v1"`, body: `{"@context":{"name":"https://schema.org/name"}}`}
+		server := httptest.NewServer(contexts)
+		defer server.Close()
+
+		loader := NewHTTPContextLoader(server.Client(), tt.TempDir())
+		_, err := loader.LoadDocument(server.URL)
+		require.NoError(tt, err)
+
+		contexts.mu.Lock()
+		contexts.etag, contexts.body = `"v2"`, `{"@context":{"name":"https://schema.org/givenName"}}`
+		contexts.mu.Unlock()
+		for i := 0; i < 2; i++ {
+			doc, err := loader.LoadDocument(server.URL)
+			require.NoError(tt, err)
+			assert.Equal(tt, map[string]any{"@context": map[string]any{"name": "https://schema.org/givenName"}}, doc.Document)
+		}
+		assert.Equal(tt, 2, contexts.served)
+	})
+
+	t.Run("context without ETag not cached", func(tt *testing.T) {
+		contexts := &contextServer{body: `{"@context":{}}`}
+		server := httptest.NewServer(contexts)
+		defer server.Close()
+		cacheDir := tt.TempDir()
+
+		loader := NewHTTPContextLoader(server.Client(), cacheDir)
+		for i := 0; i < 2; i++ {
+			_, err := loader.LoadDocument(server.URL)
+			require.NoError(tt, err)
+		}
+		assert.Equal(tt, 2, contexts.served)
+		entries, err := os.ReadDir(cacheDir)
+		require.NoError(tt, err)
+		assert.Empty(tt, entries)
+	})
+
+	t.Run("errors", func(tt *testing.T) {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if r.URL.Path == "/invalid" {
+				_, _ = w.Write([]byte("not json"))
+				return
+			}
+			w.WriteHeader(http.StatusNotFound)
+		}))
+		defer server.Close()
+
+		loader := NewHTTPContextLoader(server.Client(), tt.TempDir())
+		_, err := loader.LoadDocument(server.URL + "/missing")
+		assert.ErrorContains(tt, err, "unexpected status code: 404")
+
+		_, err = loader.LoadDocument(server.URL + "/invalid")
+		assert.ErrorContains(tt, err, "parsing context")
+	})
+}
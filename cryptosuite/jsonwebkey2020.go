@@ -243,7 +243,11 @@ func (v JSONWebKeyVerifier) Verify(message, signature []byte) error {
 	return err
 }
 
+// GetKeyID returns the id of the verification method the verifier was created for, falling back to the kid of the key
 func (v JSONWebKeyVerifier) GetKeyID() string {
+	if v.ID != "" {
+		return v.ID
+	}
 	return v.Key.KeyID()
 }
 
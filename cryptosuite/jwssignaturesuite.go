@@ -10,7 +10,6 @@ import (
 	"github.com/TBD54566975/ssi-sdk/crypto"
 	. "github.com/TBD54566975/ssi-sdk/util"
 	"github.com/goccy/go-json"
-	"github.com/google/uuid"
 	"github.com/pkg/errors"
 )
 
@@ -28,10 +27,12 @@ const (
 	JWSSignatureSuiteProofAlgorithm = JSONWebSignature2020
 )
 
-type JWSSignatureSuite struct{}
+type JWSSignatureSuite struct {
+	suiteOptions
+}
 
-func GetJSONWebSignature2020Suite() CryptoSuite {
-	return new(JWSSignatureSuite)
+func GetJSONWebSignature2020Suite(opts ...SuiteOption) CryptoSuite {
+	return &JWSSignatureSuite{suiteOptions: newSuiteOptions(opts)}
 }
 
 // CryptoSuiteInfo interface
@@ -64,7 +65,7 @@ func (JWSSignatureSuite) RequiredContexts() []string {
 
 func (j JWSSignatureSuite) Sign(s Signer, p Provable) error {
 	// create proof before running the create verify hash algorithm
-	proof := j.createProof(s.GetKeyID(), s.GetProofPurpose())
+	proof := j.createProof(j.newProofOptions(s, p))
 
 	// prepare proof options
 	contexts, err := GetContextsFromProvable(p)
@@ -109,6 +110,12 @@ func (j JWSSignatureSuite) Verify(v Verifier, p Provable) error {
 	if err != nil {
 		return errors.Wrap(err, "could not prepare proof for verification; error coercing proof into JsonWebSignature2020 proof")
 	}
+	if err = j.checkProofBinding(gotProof.Challenge, gotProof.Domain); err != nil {
+		return err
+	}
+	if err = j.checkProofPurpose(gotProof.ProofPurpose, gotProof.VerificationMethod); err != nil {
+		return err
+	}
 
 	// remove proof before verifying
 	p.SetProof(nil)
@@ -163,13 +170,13 @@ func (JWSSignatureSuite) Marshal(data any) ([]byte, error) {
 	return jsonBytes, nil
 }
 
-func (JWSSignatureSuite) Canonicalize(marshaled []byte) (*string, error) {
+func (j JWSSignatureSuite) Canonicalize(marshaled []byte) (*string, error) {
 	// the LD library anticipates a generic golang json object to normalize
 	var generic map[string]any
 	if err := json.Unmarshal(marshaled, &generic); err != nil {
 		return nil, err
 	}
-	normalized, err := LDNormalize(generic)
+	normalized, err := LDNormalize(generic, j.ldOption())
 	if err != nil {
 		return nil, errors.Wrap(err, "could not canonicalize provable document")
 	}
@@ -262,18 +269,21 @@ func (j JWSSignatureSuite) prepareProof(proof crypto.Proof, opts *ProofOptions)
 		// if none provided, make sure the proof has a context value for this suite
 		contexts = ArrayStrToInterface(j.RequiredContexts())
 	}
-	genericProof["@context"] = contexts
+	genericProof["@context"] = withProofChainContext(contexts, genericProof)
 	p := crypto.Proof(genericProof)
 	return &p, nil
 }
 
 type JSONWebSignature2020Proof struct {
+	ID                 string        `json:"id,omitempty"`
 	Type               SignatureType `json:"type,omitempty"`
 	Created            string        `json:"created,omitempty"`
 	JWS                string        `json:"jws,omitempty"`
 	ProofPurpose       ProofPurpose  `json:"proofPurpose,omitempty"`
 	Challenge          string        `json:"challenge,omitempty"`
+	Domain             string        `json:"domain,omitempty"`
 	VerificationMethod string        `json:"verificationMethod,omitempty"`
+	PreviousProof      any           `json:"previousProof,omitempty"`
 }
 
 func JSONWebSignatureProofFromGenericProof(p crypto.Proof) (*JSONWebSignature2020Proof, error) {
@@ -316,16 +326,15 @@ func (j *JSONWebSignature2020Proof) DecodeJWS() ([]byte, error) {
 	return base64.RawURLEncoding.DecodeString(jwsParts[2])
 }
 
-func (j JWSSignatureSuite) createProof(verificationMethod string, purpose ProofPurpose) JSONWebSignature2020Proof {
-	var challenge string
-	if purpose == Authentication {
-		challenge = uuid.NewString()
-	}
+func (j JWSSignatureSuite) createProof(opts ProofOptions) JSONWebSignature2020Proof {
 	return JSONWebSignature2020Proof{
+		ID:                 opts.ID,
 		Type:               j.SignatureAlgorithm(),
-		Created:            GetRFC3339Timestamp(),
-		ProofPurpose:       purpose,
-		Challenge:          challenge,
-		VerificationMethod: verificationMethod,
+		Created:            opts.Created,
+		ProofPurpose:       opts.ProofPurpose,
+		Challenge:          opts.Challenge,
+		Domain:             opts.Domain,
+		VerificationMethod: opts.VerificationMethod,
+		PreviousProof:      opts.PreviousProof,
 	}
 }
@@ -302,7 +302,8 @@ func TestJsonWebSignature2020TestVectorPresentation0(t *testing.T) {
 	}
 
 	// sign known pres
-	suite := GetJSONWebSignature2020Suite()
+	// the known proof is bound to the challenge 123
+	suite := GetJSONWebSignature2020Suite(WithProofOptions(ProofOptions{Challenge: "123"}))
 	err := suite.Sign(&signer, &knownPres)
 	assert.NoError(t, err)
 
@@ -386,7 +387,8 @@ func TestJsonWebSignature2020TestVectorPresentation1(t *testing.T) {
 	}
 
 	// sign known pres
-	suite := GetJSONWebSignature2020Suite()
+	// the known proof is bound to the challenge 123
+	suite := GetJSONWebSignature2020Suite(WithProofOptions(ProofOptions{Challenge: "123"}))
 	err := suite.Sign(&signer, &knownPres)
 	assert.NoError(t, err)
 
@@ -24,4 +24,5 @@ const (
 	X25519KeyAgreementKey2019         LDKeyType = "X25519KeyAgreementKey2019"
 	Ed25519VerificationKey2018        LDKeyType = "Ed25519VerificationKey2018"
 	ECDSASECP256k1VerificationKey2019 LDKeyType = "EcdsaSecp256k1VerificationKey2019"
+	MultikeyType                      LDKeyType = "Multikey"
 )
@@ -0,0 +1,60 @@
+package cryptosuite
+
+import (
+	"fmt"
+
+	. "github.com/TBD54566975/ssi-sdk/util"
+	"github.com/google/uuid"
+)
+
+// WithProofOptions sets the challenge, domain, proof purpose, created timestamp, and verification method of the proofs
+// a suite creates. When verifying, a suite fails any proof whose challenge or domain does not match those set,
+// including a proof with a challenge or domain when none is set, so that a proof bound to one verifier's request may
+// not be replayed to another.
+func WithProofOptions(proofOptions ProofOptions) SuiteOption {
+	return func(o *suiteOptions) {
+		o.proofOptions = proofOptions
+	}
+}
+
+// newProofOptions returns the options of a proof created with the given signer over the given provable. Options set
+// with WithProofOptions take precedence over the signer's key ID and proof purpose. Authentication proofs are given a
+// random challenge if none is set. A provable of a proof chain gives the id and previous proof of the proof.
+func (o suiteOptions) newProofOptions(s Signer, p Provable) ProofOptions {
+	proofOptions := o.proofOptions
+	if link, ok := p.(proofLinker); ok {
+		proofOptions.ID, proofOptions.PreviousProof = link.proofLink()
+	}
+	if proofOptions.VerificationMethod == "" {
+		proofOptions.VerificationMethod = s.GetKeyID()
+	}
+	if proofOptions.ProofPurpose == "" {
+		proofOptions.ProofPurpose = s.GetProofPurpose()
+	}
+	if proofOptions.Created == "" {
+		proofOptions.Created = GetRFC3339Timestamp()
+	}
+	if proofOptions.Challenge == "" && proofOptions.ProofPurpose == Authentication {
+		proofOptions.Challenge = uuid.NewString()
+	}
+	return proofOptions
+}
+
+// checkProofBinding checks the challenge and domain of a proof against those expected with WithProofOptions. A proof
+// with a challenge or domain the verifier did not expect fails, as it was bound to another verifier's request.
+func (o suiteOptions) checkProofBinding(challenge, domain string) error {
+	if err := checkProofBindingValue("challenge", challenge, o.proofOptions.Challenge); err != nil {
+		return err
+	}
+	return checkProofBindingValue("domain", domain, o.proofOptions.Domain)
+}
+
+func checkProofBindingValue(name, actual, expected string) error {
+	if actual == expected {
+		return nil
+	}
+	if expected == "" {
+		return fmt.Errorf("proof %s<%s> was not expected", name, actual)
+	}
+	return fmt.Errorf("proof %s<%s> does not match expected %s<%s>", name, actual, name, expected)
+}
@@ -0,0 +1,150 @@
+package cryptosuite
+
+import (
+	"testing"
+
+	"github.com/TBD54566975/ssi-sdk/crypto"
+	"github.com/TBD54566975/ssi-sdk/util"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWithProofOptions(t *testing.T) {
+	disableNetwork(t)
+
+	signer, jwk := getTestVectorKey0Signer(t, AssertionMethod)
+	verifier, err := NewJSONWebKeyVerifier(jwk.ID, jwk.PublicKeyJWK)
+	require.NoError(t, err)
+	proofOptions := ProofOptions{
+		Challenge:          "abc",
+		Domain:             "https://verifier.example.com",
+		ProofPurpose:       Authentication,
+		Created:            "2023-01-01T00:00:00Z",
+		VerificationMethod: jwk.ID,
+	}
+	suite := GetJSONWebSignature2020Suite(WithProofOptions(proofOptions))
+	getSignedPres := func(tt *testing.T) TestVerifiablePresentation {
+		pres := TestVerifiablePresentation{
+			Context: []string{W3CCredentialsContext, JSONWebSignature2020Context},
+			Type:    []string{"VerifiablePresentation"},
+			Holder:  "did:example:123",
+		}
+		require.NoError(tt, suite.Sign(&signer, &pres))
+		return pres
+	}
+
+	t.Run("proof carries the options", func(tt *testing.T) {
+		pres := getSignedPres(tt)
+		proof, err := JSONWebSignatureProofFromGenericProof(*pres.GetProof())
+		require.NoError(tt, err)
+		assert.Equal(tt, "abc", proof.Challenge)
+		assert.Equal(tt, "https://verifier.example.com", proof.Domain)
+		assert.Equal(tt, Authentication, proof.ProofPurpose)
+		assert.Equal(tt, "2023-01-01T00:00:00Z", proof.Created)
+		assert.Equal(tt, jwk.ID, proof.VerificationMethod)
+
+		assert.NoError(tt, suite.Verify(verifier, &pres))
+	})
+
+	t.Run("challenge mismatch", func(tt *testing.T) {
+		pres := getSignedPres(tt)
+		otherSuite := GetJSONWebSignature2020Suite(WithProofOptions(ProofOptions{Challenge: "xyz", Domain: proofOptions.Domain}))
+		assert.ErrorContains(tt, otherSuite.Verify(verifier, &pres), "does not match expected challenge<xyz>")
+	})
+
+	t.Run("challenge not expected", func(tt *testing.T) {
+		pres := getSignedPres(tt)
+		assert.ErrorContains(tt, GetJSONWebSignature2020Suite().Verify(verifier, &pres), "proof challenge<abc> was not expected")
+
+		otherSuite := GetJSONWebSignature2020Suite(WithProofOptions(ProofOptions{Domain: proofOptions.Domain}))
+		assert.ErrorContains(tt, otherSuite.Verify(verifier, &pres), "proof challenge<abc> was not expected")
+	})
+
+	t.Run("domain not expected", func(tt *testing.T) {
+		pres := getSignedPres(tt)
+		otherSuite := GetJSONWebSignature2020Suite(WithProofOptions(ProofOptions{Challenge: proofOptions.Challenge}))
+		assert.ErrorContains(tt, otherSuite.Verify(verifier, &pres), "proof domain<https://verifier.example.com> was not expected")
+	})
+
+	t.Run("domain mismatch", func(tt *testing.T) {
+		pres := getSignedPres(tt)
+		otherSuite := GetJSONWebSignature2020Suite(WithProofOptions(ProofOptions{Challenge: "abc", Domain: "https://other.example.com"}))
+		assert.ErrorContains(tt, otherSuite.Verify(verifier, &pres), "does not match expected domain")
+	})
+
+	t.Run("challenge is covered by the signature", func(tt *testing.T) {
+		pres := getSignedPres(tt)
+		proof, err := util.ToJSONMap(*pres.GetProof())
+		require.NoError(tt, err)
+		proof["challenge"] = "xyz"
+		tampered := crypto.Proof(proof)
+		pres.SetProof(&tampered)
+
+		otherSuite := GetJSONWebSignature2020Suite(WithProofOptions(ProofOptions{Challenge: "xyz"}))
+		assert.Error(tt, otherSuite.Verify(verifier, &pres))
+	})
+
+	t.Run("bbs+", func(tt *testing.T) {
+		blsKey, err := GenerateBLSKey2020(BLS12381G2Key2020)
+		require.NoError(tt, err)
+		blsPrivKey, err := blsKey.GetPrivateKey()
+		require.NoError(tt, err)
+		bbsSigner := NewBBSPlusSigner("did:example:123#key-1", blsPrivKey, Authentication)
+		bbsVerifier := NewBBSPlusVerifier("did:example:123#key-1", blsPrivKey.PublicKey())
+
+		contexts := []any{W3CCredentialsContext, BBSSecurityContext, map[string]any{"@vocab": "https://example.com/#"}}
+		cred := TestCredential{
+			Context:           contexts,
+			Type:              []string{"VerifiableCredential"},
+			Issuer:            "did:example:123",
+			IssuanceDate:      "2021-01-01T19:23:24Z",
+			CredentialSubject: map[string]any{"id": "did:example:abcd", "givenName": "Alice"},
+		}
+		bbsOptions := ProofOptions{Challenge: "abc", Domain: "https://verifier.example.com"}
+		require.NoError(tt, GetBBSPlusSignatureSuite(WithProofOptions(bbsOptions)).Sign(bbsSigner, &cred))
+		proof, err := BBSPlusProofFromGenericProof(*cred.GetProof())
+		require.NoError(tt, err)
+		assert.Equal(tt, "abc", proof.Challenge)
+		assert.Equal(tt, "https://verifier.example.com", proof.Domain)
+
+		assert.NoError(tt, GetBBSPlusSignatureSuite(WithProofOptions(bbsOptions)).Verify(bbsVerifier, &cred))
+		assert.ErrorContains(tt, GetBBSPlusSignatureSuite(WithProofOptions(ProofOptions{Challenge: "xyz"})).Verify(bbsVerifier, &cred), "does not match expected challenge<xyz>")
+		assert.ErrorContains(tt, GetBBSPlusSignatureSuite(WithProofOptions(ProofOptions{Challenge: "abc", Domain: "https://other.example.com"})).Verify(bbsVerifier, &cred), "does not match expected domain")
+		assert.ErrorContains(tt, GetBBSPlusSignatureSuite().Verify(bbsVerifier, &cred), "was not expected")
+
+		// a derived proof carries the challenge and domain of the proof it is derived from
+		revealDoc := map[string]any{
+			"@context":          contexts,
+			"type":              "VerifiableCredential",
+			"credentialSubject": map[string]any{"@explicit": true, "givenName": map[string]any{}},
+		}
+		derived, err := BBSPlusSignatureSuite{}.DeriveProof(*bbsVerifier, &cred, revealDoc, nil)
+		require.NoError(tt, err)
+		derivedCred := GenericProvable(derived)
+		assert.NoError(tt, GetBBSPlusSignatureProofSuite(WithProofOptions(bbsOptions)).Verify(bbsVerifier, &derivedCred))
+		assert.ErrorContains(tt, GetBBSPlusSignatureProofSuite(WithProofOptions(ProofOptions{Challenge: "xyz"})).Verify(bbsVerifier, &derivedCred), "does not match expected challenge<xyz>")
+		assert.ErrorContains(tt, GetBBSPlusSignatureProofSuite().Verify(bbsVerifier, &derivedCred), "was not expected")
+	})
+
+	t.Run("eddsa-jcs-2022", func(tt *testing.T) {
+		pubKey, privKey, err := crypto.GenerateEd25519Key()
+		require.NoError(tt, err)
+		edSigner := NewEd25519Signer("did:example:123#key-1", privKey, AssertionMethod)
+		edVerifier := NewEd25519Verifier("did:example:123#key-1", pubKey)
+
+		doc := GenericProvable{"type": []any{"VerifiablePresentation"}, "holder": "did:example:123"}
+		require.NoError(tt, GetEdDSAJCS2022Suite(WithProofOptions(ProofOptions{Challenge: "abc"})).Sign(edSigner, &doc))
+
+		assert.NoError(tt, GetEdDSAJCS2022Suite(WithProofOptions(ProofOptions{Challenge: "abc"})).Verify(edVerifier, &doc))
+		assert.ErrorContains(tt, GetEdDSAJCS2022Suite(WithProofOptions(ProofOptions{Challenge: "xyz"})).Verify(edVerifier, &doc), "challenge")
+		assert.ErrorContains(tt, GetEdDSAJCS2022Suite().Verify(edVerifier, &doc), "proof challenge<abc> was not expected")
+
+		// the challenge is part of the signed proof configuration
+		proof, err := util.ToJSONMap(*doc.GetProof())
+		require.NoError(tt, err)
+		proof["challenge"] = "xyz"
+		tampered := crypto.Proof(proof)
+		doc.SetProof(&tampered)
+		assert.ErrorContains(tt, GetEdDSAJCS2022Suite(WithProofOptions(ProofOptions{Challenge: "xyz"})).Verify(edVerifier, &doc), "verifying")
+	})
+}
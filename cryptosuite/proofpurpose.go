@@ -0,0 +1,49 @@
+package cryptosuite
+
+import (
+	"context"
+
+	"github.com/pkg/errors"
+)
+
+// ErrUnauthorizedProofPurpose is returned when verifying a proof whose purpose is not the one expected, or whose
+// verification method is not authorized for that purpose by its controller
+var ErrUnauthorizedProofPurpose = errors.New("unauthorized proof purpose")
+
+// WithExpectedProofPurpose sets the purpose a suite requires of the proofs it verifies, such as authentication for
+// the proof of a presentation. When verifying, a suite fails any proof with another purpose, or whose verification
+// method the authorizer, if given, does not find authorized for the purpose by its controller, with an error
+// wrapping ErrUnauthorizedProofPurpose. The context bounds the authorizer's resolution of each method's controller.
+func WithExpectedProofPurpose(ctx context.Context, expectedPurpose ProofPurpose, authorizer VerificationMethodAuthorizer) SuiteOption {
+	return func(o *suiteOptions) {
+		o.authorizationCtx = ctx
+		o.expectedPurpose = expectedPurpose
+		o.authorizer = authorizer
+	}
+}
+
+// checkProofPurpose checks the purpose of a proof, and the authorization of its verification method for that purpose,
+// against the purpose expected with WithExpectedProofPurpose
+func (o suiteOptions) checkProofPurpose(purpose ProofPurpose, verificationMethod string) error {
+	if o.expectedPurpose == "" {
+		return nil
+	}
+	if purpose != o.expectedPurpose {
+		return errors.Wrapf(ErrUnauthorizedProofPurpose, "proof purpose<%s> does not match expected purpose<%s>", purpose, o.expectedPurpose)
+	}
+	if o.authorizer == nil {
+		return nil
+	}
+	ctx := o.authorizationCtx
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	authorized, err := o.authorizer.IsAuthorized(ctx, verificationMethod, purpose)
+	if err != nil {
+		return errors.Wrapf(err, "authorizing verification method<%s> for %s", verificationMethod, purpose)
+	}
+	if !authorized {
+		return errors.Wrapf(ErrUnauthorizedProofPurpose, "verification method<%s> is not authorized for %s by its controller", verificationMethod, purpose)
+	}
+	return nil
+}
@@ -0,0 +1,107 @@
+package cryptosuite
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/TBD54566975/ssi-sdk/crypto"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// staticAuthorizer authorizes the verification methods listed for each proof purpose
+type staticAuthorizer map[ProofPurpose][]string
+
+func (a staticAuthorizer) IsAuthorized(ctx context.Context, verificationMethod string, purpose ProofPurpose) (bool, error) {
+	if err := ctx.Err(); err != nil {
+		return false, err
+	}
+	methods, ok := a[purpose]
+	if !ok {
+		return false, fmt.Errorf("unknown proof purpose: %s", purpose)
+	}
+	for _, method := range methods {
+		if method == verificationMethod {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+func TestWithExpectedProofPurpose(t *testing.T) {
+	disableNetwork(t)
+
+	// authentication proofs are bound to a challenge, which the verifier must expect
+	bound := WithProofOptions(ProofOptions{Challenge: "abc"})
+
+	getSignedCred := func(tt *testing.T, purpose ProofPurpose) (TestCredential, Verifier) {
+		signer, jwk := getTestVectorKey0Signer(tt, purpose)
+		verifier, err := NewJSONWebKeyVerifier(jwk.ID, jwk.PublicKeyJWK)
+		require.NoError(tt, err)
+		cred := TestCredential{
+			Context:           []any{W3CCredentialsContext, JSONWebSignature2020Context},
+			Type:              []any{"VerifiableCredential"},
+			Issuer:            "did:example:123",
+			IssuanceDate:      "2021-01-01T19:23:24Z",
+			CredentialSubject: map[string]any{"id": "did:example:456"},
+		}
+		require.NoError(tt, GetJSONWebSignature2020Suite(bound).Sign(&signer, &cred))
+		return cred, verifier
+	}
+	authorizer := staticAuthorizer{
+		AssertionMethod: {"did:example:123#key-0"},
+		Authentication:  {"did:example:123#key-1"},
+	}
+
+	t.Run("purpose matches", func(tt *testing.T) {
+		cred, verifier := getSignedCred(tt, AssertionMethod)
+		assert.NoError(tt, GetJSONWebSignature2020Suite(bound, WithExpectedProofPurpose(context.Background(), AssertionMethod, nil)).Verify(verifier, &cred))
+		assert.NoError(tt, GetJSONWebSignature2020Suite(bound, WithExpectedProofPurpose(context.Background(), AssertionMethod, authorizer)).Verify(verifier, &cred))
+	})
+
+	t.Run("purpose mismatch", func(tt *testing.T) {
+		cred, verifier := getSignedCred(tt, AssertionMethod)
+		err := GetJSONWebSignature2020Suite(bound, WithExpectedProofPurpose(context.Background(), Authentication, nil)).Verify(verifier, &cred)
+		assert.ErrorIs(tt, err, ErrUnauthorizedProofPurpose)
+		assert.ErrorContains(tt, err, "proof purpose<assertionMethod> does not match expected purpose<authentication>")
+	})
+
+	t.Run("method not authorized for the purpose", func(tt *testing.T) {
+		cred, verifier := getSignedCred(tt, Authentication)
+		assert.NoError(tt, GetJSONWebSignature2020Suite(bound, WithExpectedProofPurpose(context.Background(), Authentication, nil)).Verify(verifier, &cred))
+
+		err := GetJSONWebSignature2020Suite(bound, WithExpectedProofPurpose(context.Background(), Authentication, authorizer)).Verify(verifier, &cred)
+		assert.ErrorIs(tt, err, ErrUnauthorizedProofPurpose)
+		assert.ErrorContains(tt, err, "verification method<did:example:123#key-0> is not authorized for authentication")
+	})
+
+	t.Run("authorization fails", func(tt *testing.T) {
+		cred, verifier := getSignedCred(tt, "capabilityInvocation")
+		err := GetJSONWebSignature2020Suite(bound, WithExpectedProofPurpose(context.Background(), "capabilityInvocation", authorizer)).Verify(verifier, &cred)
+		assert.ErrorContains(tt, err, "unknown proof purpose: capabilityInvocation")
+		assert.NotErrorIs(tt, err, ErrUnauthorizedProofPurpose)
+	})
+
+	t.Run("authorization is bound by the context", func(tt *testing.T) {
+		cred, verifier := getSignedCred(tt, AssertionMethod)
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+		err := GetJSONWebSignature2020Suite(bound, WithExpectedProofPurpose(ctx, AssertionMethod, authorizer)).Verify(verifier, &cred)
+		assert.ErrorIs(tt, err, context.Canceled)
+	})
+
+	t.Run("eddsa-jcs-2022", func(tt *testing.T) {
+		pubKey, privKey, err := crypto.GenerateEd25519Key()
+		require.NoError(tt, err)
+		edSigner := NewEd25519Signer("did:example:123#key-0", privKey, AssertionMethod)
+		edVerifier := NewEd25519Verifier("did:example:123#key-0", pubKey)
+
+		doc := GenericProvable{"type": []any{"VerifiableCredential"}, "issuer": "did:example:123"}
+		require.NoError(tt, GetEdDSAJCS2022Suite().Sign(edSigner, &doc))
+
+		assert.NoError(tt, GetEdDSAJCS2022Suite(WithExpectedProofPurpose(context.Background(), AssertionMethod, authorizer)).Verify(edVerifier, &doc))
+		err = GetEdDSAJCS2022Suite(WithExpectedProofPurpose(context.Background(), Authentication, authorizer)).Verify(edVerifier, &doc)
+		assert.ErrorIs(tt, err, ErrUnauthorizedProofPurpose)
+	})
+}
@@ -0,0 +1,323 @@
+package cryptosuite
+
+import (
+	"fmt"
+
+	"github.com/TBD54566975/ssi-sdk/crypto"
+	. "github.com/TBD54566975/ssi-sdk/util"
+	"github.com/goccy/go-json"
+	"github.com/google/uuid"
+	"github.com/pkg/errors"
+)
+
+// https://www.w3.org/TR/vc-data-integrity/#proof-sets
+// https://www.w3.org/TR/vc-data-integrity/#proof-chains
+
+const (
+	proofIDProperty       = "id"
+	previousProofProperty = "previousProof"
+)
+
+// proofChainContext defines the previousProof term, which the contexts of the JSON-LD suites predate, with its IRI in
+// the Data Integrity vocabulary, so that canonicalization keeps it in the signed proof configuration
+var proofChainContext = map[string]any{
+	previousProofProperty: map[string]any{"@id": "https://w3id.org/security#previousProof", "@type": "@id"},
+}
+
+// proofVerifier is implemented by every suite, including the BBS+ proof suite, which only verifies
+type proofVerifier interface {
+	Verify(v Verifier, p Provable) error
+}
+
+// AddProofToSet signs a document with the given suite, adding the proof to the set of proofs the document already
+// carries. The proof covers the document without any of the other proofs in the set.
+func AddProofToSet(suite CryptoSuite, s Signer, doc map[string]any) error {
+	proofs, err := getProofs(doc)
+	if err != nil {
+		return err
+	}
+	provable := proofProvable{doc: withoutProof(doc)}
+	if err = suite.Sign(s, &provable); err != nil {
+		return errors.Wrap(err, "signing document")
+	}
+	proof, err := ToJSONMap(provable.proof)
+	if err != nil {
+		return errors.Wrap(err, "converting proof")
+	}
+	doc["proof"] = append(proofs, proof)
+	return nil
+}
+
+// AddProofToChain signs a document with the given suite, adding the proof to the end of the chain of proofs the
+// document already carries. The proof references the last proof of the chain as its previous proof, and covers the
+// document including that proof. Every proof of the chain is given an id by which later proofs reference it. The id
+// and previous proof are set before signing, so both are covered by the signature of the proof.
+func AddProofToChain(suite CryptoSuite, s Signer, doc map[string]any) error {
+	proofs, err := getProofs(doc)
+	if err != nil {
+		return err
+	}
+	var previous []any
+	var previousID string
+	if len(proofs) > 0 {
+		last := proofs[len(proofs)-1]
+		previousID, err = getProofID(last)
+		if err != nil {
+			return errors.Wrap(err, "getting the id of the previous proof")
+		}
+		previous = []any{last}
+	}
+	provable := proofProvable{
+		doc:           withoutProof(doc),
+		previous:      previous,
+		id:            "urn:uuid:" + uuid.NewString(),
+		previousProof: previousID,
+	}
+	if err = suite.Sign(s, &provable); err != nil {
+		return errors.Wrap(err, "signing document")
+	}
+	proof, err := ToJSONMap(provable.proof)
+	if err != nil {
+		return errors.Wrap(err, "converting proof")
+	}
+	if proof[proofIDProperty] != provable.id {
+		return fmt.Errorf("suite %s does not support proof chains", suite.SignatureAlgorithm())
+	}
+	doc["proof"] = append(proofs, proof)
+	return nil
+}
+
+// VerifyProofSet verifies every proof of a document carrying a set of proofs, each of which covers the document
+// without the others. The verifier for each proof is the one whose key ID matches its verification method. A single
+// failing proof fails the set, with an error naming the index and verification method of the proof.
+func VerifyProofSet(doc map[string]any, verifiers []Verifier, opts ...SuiteOption) error {
+	proofs, err := getProofs(doc)
+	if err != nil {
+		return err
+	}
+	if len(proofs) == 0 {
+		return errors.New("document has no proof")
+	}
+	unsecured := withoutProof(doc)
+	for i, proof := range proofs {
+		if err = verifyProof(proofProvable{doc: unsecured}, proof, verifiers, opts); err != nil {
+			return errors.Wrapf(err, "verifying proof %d%s", i, describeProof(proof))
+		}
+	}
+	return nil
+}
+
+// VerifyProofChain verifies every proof of a document carrying a chain of proofs, in order. A proof with a
+// previousProof covers the document including the proof(s) it references, which must precede it in the chain. The
+// id and previousProof of each proof are part of its signed proof configuration, so the chain may not be relinked. The
+// verifier for each proof is the one whose key ID matches its verification method. A single failing proof fails the
+// chain, with an error naming the index and verification method of the proof.
+func VerifyProofChain(doc map[string]any, verifiers []Verifier, opts ...SuiteOption) error {
+	proofs, err := getProofs(doc)
+	if err != nil {
+		return err
+	}
+	if len(proofs) == 0 {
+		return errors.New("document has no proof")
+	}
+	unsecured := withoutProof(doc)
+	precedingProofs := make(map[string]any, len(proofs))
+	for i, proof := range proofs {
+		previous, err := getPreviousProofs(proof, precedingProofs)
+		if err != nil {
+			return errors.Wrapf(err, "verifying proof %d%s", i, describeProof(proof))
+		}
+		if err = verifyProof(proofProvable{doc: unsecured, previous: previous}, proof, verifiers, opts); err != nil {
+			return errors.Wrapf(err, "verifying proof %d%s", i, describeProof(proof))
+		}
+		if id, err := getProofID(proof); err == nil {
+			precedingProofs[id] = proof
+		}
+	}
+	return nil
+}
+
+// verifyProof verifies a single proof over the given provable, with the suite for the proof's type
+func verifyProof(provable proofProvable, proof any, verifiers []Verifier, opts []SuiteOption) error {
+	proofMap, err := ToJSONMap(proof)
+	if err != nil {
+		return errors.Wrap(err, "converting proof")
+	}
+	suite, err := getProofVerifier(proofMap, opts)
+	if err != nil {
+		return err
+	}
+	verificationMethod, _ := proofMap["verificationMethod"].(string)
+	var verifier Verifier
+	for _, v := range verifiers {
+		if v.GetKeyID() == verificationMethod {
+			verifier = v
+			break
+		}
+	}
+	if verifier == nil {
+		return fmt.Errorf("no verifier for verification method: %s", verificationMethod)
+	}
+	genericProof := crypto.Proof(proofMap)
+	provable.proof = &genericProof
+	return suite.Verify(verifier, &provable)
+}
+
+// getProofVerifier returns the suite for a proof's type, and for DataIntegrityProof proofs, its cryptosuite
+func getProofVerifier(proof map[string]any, opts []SuiteOption) (proofVerifier, error) {
+	proofType, _ := proof["type"].(string)
+	switch SignatureType(proofType) {
+	case JSONWebSignature2020:
+		return GetJSONWebSignature2020Suite(opts...), nil
+	case Ed25519Signature2020:
+		return GetEd25519Signature2020Suite(opts...), nil
+	case EcdsaSecp256k1Signature2019:
+		return GetEcdsaSecp256k1Signature2019Suite(opts...), nil
+	case BBSPlusSignature2020:
+		return GetBBSPlusSignatureSuite(opts...), nil
+	case BBSPlusSignatureProof2020:
+		return GetBBSPlusSignatureProofSuite(opts...), nil
+	case DataIntegrityProofType:
+		cryptosuite, _ := proof["cryptosuite"].(string)
+		if cryptosuite != EdDSAJCS2022Cryptosuite {
+			return nil, fmt.Errorf("unsupported cryptosuite: %s", cryptosuite)
+		}
+		return GetEdDSAJCS2022Suite(opts...), nil
+	default:
+		return nil, fmt.Errorf("unsupported proof type: %s", proofType)
+	}
+}
+
+// getProofs returns the proofs of a document, whose proof may be a single proof or an array of proofs
+func getProofs(doc map[string]any) ([]any, error) {
+	proof, ok := doc["proof"]
+	if !ok || proof == nil {
+		return nil, nil
+	}
+	proofs, err := InterfaceToInterfaceArray(proof)
+	if err != nil {
+		return nil, errors.Wrap(err, "getting proofs from document")
+	}
+	return proofs, nil
+}
+
+// getPreviousProofs returns the proofs referenced by the previousProof of a proof, which may be a single id or an
+// array of ids of the proofs preceding it
+func getPreviousProofs(proof any, precedingProofs map[string]any) ([]any, error) {
+	proofMap, err := ToJSONMap(proof)
+	if err != nil {
+		return nil, errors.Wrap(err, "converting proof")
+	}
+	previousProof, ok := proofMap[previousProofProperty]
+	if !ok {
+		return nil, nil
+	}
+	previousIDs, err := InterfaceToStrings(previousProof)
+	if err != nil {
+		return nil, errors.Wrap(err, "getting previous proof ids")
+	}
+	previous := make([]any, 0, len(previousIDs))
+	for _, id := range previousIDs {
+		p, ok := precedingProofs[id]
+		if !ok {
+			return nil, fmt.Errorf("previous proof %s does not precede the proof", id)
+		}
+		previous = append(previous, p)
+	}
+	return previous, nil
+}
+
+func getProofID(proof any) (string, error) {
+	proofMap, err := ToJSONMap(proof)
+	if err != nil {
+		return "", errors.Wrap(err, "converting proof")
+	}
+	id, ok := proofMap[proofIDProperty].(string)
+	if !ok || id == "" {
+		return "", errors.New("proof has no id")
+	}
+	return id, nil
+}
+
+// describeProof names the verification method of a proof for error messages
+func describeProof(proof any) string {
+	proofMap, err := ToJSONMap(proof)
+	if err != nil {
+		return ""
+	}
+	if verificationMethod, ok := proofMap["verificationMethod"].(string); ok {
+		return " with verification method " + verificationMethod
+	}
+	return ""
+}
+
+func withoutProof(doc map[string]any) map[string]any {
+	unsecured := make(map[string]any, len(doc))
+	for k, v := range doc {
+		if k != "proof" {
+			unsecured[k] = v
+		}
+	}
+	return unsecured
+}
+
+// withProofChainContext adds the definition of the previousProof term to the contexts of a proof configuration that
+// has a previous proof
+func withProofChainContext(contexts []any, proof map[string]any) []any {
+	if _, ok := proof[previousProofProperty]; !ok {
+		return contexts
+	}
+	return append(append([]any{}, contexts...), proofChainContext)
+}
+
+// proofLinker is implemented by provables of a proof chain, giving the id of the proof a suite creates over them and
+// the id of the proof it follows
+type proofLinker interface {
+	proofLink() (id string, previousProof any)
+}
+
+// proofProvable presents a single proof of a set or chain to a suite. The document it marshals to carries the
+// previous proofs the proof covers, along with the proof itself, if set. When signing a proof of a chain, id and
+// previousProof link the proof to the chain.
+type proofProvable struct {
+	doc      map[string]any
+	previous []any
+	proof    *crypto.Proof
+
+	id            string
+	previousProof string
+}
+
+func (p *proofProvable) proofLink() (string, any) {
+	if p.previousProof == "" {
+		return p.id, nil
+	}
+	return p.id, p.previousProof
+}
+
+func (p *proofProvable) GetProof() *crypto.Proof {
+	return p.proof
+}
+
+func (p *proofProvable) SetProof(proof *crypto.Proof) {
+	p.proof = proof
+}
+
+func (p *proofProvable) MarshalJSON() ([]byte, error) {
+	doc := make(map[string]any, len(p.doc)+1)
+	for k, v := range p.doc {
+		doc[k] = v
+	}
+	proofs := append([]any{}, p.previous...)
+	if p.proof != nil {
+		proofs = append(proofs, *p.proof)
+	}
+	switch len(proofs) {
+	case 0:
+	case 1:
+		doc["proof"] = proofs[0]
+	default:
+		doc["proof"] = proofs
+	}
+	return json.Marshal(doc)
+}
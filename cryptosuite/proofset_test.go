@@ -0,0 +1,200 @@
+package cryptosuite
+
+import (
+	"testing"
+
+	"github.com/TBD54566975/ssi-sdk/crypto"
+	"github.com/TBD54566975/ssi-sdk/crypto/jwx"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestProofSet(t *testing.T) {
+	suite := GetJSONWebSignature2020Suite()
+	signerA, verifierA := getProofSetSignerVerifier(t, "did:example:alice#key-1")
+	signerB, verifierB := getProofSetSignerVerifier(t, "did:example:bob#key-1")
+	verifiers := []Verifier{verifierA, verifierB}
+
+	getSignedDoc := func(tt *testing.T) map[string]any {
+		doc := getProofSetTestDoc()
+		require.NoError(tt, AddProofToSet(suite, signerA, doc))
+		require.NoError(tt, AddProofToSet(suite, signerB, doc))
+		return doc
+	}
+
+	t.Run("two co-signers", func(tt *testing.T) {
+		doc := getSignedDoc(tt)
+		proofs, err := getProofs(doc)
+		require.NoError(tt, err)
+		require.Len(tt, proofs, 2)
+		assert.NoError(tt, VerifyProofSet(doc, verifiers))
+
+		// each proof verifies alone
+		for _, proof := range proofs {
+			single := withoutProof(doc)
+			single["proof"] = proof
+			assert.NoError(tt, VerifyProofSet(single, verifiers))
+		}
+
+		// the order of a set does not matter
+		doc["proof"] = []any{proofs[1], proofs[0]}
+		assert.NoError(tt, VerifyProofSet(doc, verifiers))
+	})
+
+	t.Run("a failing proof fails the set", func(tt *testing.T) {
+		doc := getSignedDoc(tt)
+		proofs, err := getProofs(doc)
+		require.NoError(tt, err)
+		first := proofs[0].(map[string]any)
+		proofs[1].(map[string]any)["jws"] = first["jws"]
+		err = VerifyProofSet(doc, verifiers)
+		assert.ErrorContains(tt, err, "verifying proof 1 with verification method did:example:bob#key-1")
+	})
+
+	t.Run("tampered document", func(tt *testing.T) {
+		doc := getSignedDoc(tt)
+		doc["issuer"] = "did:example:mallory"
+		assert.ErrorContains(tt, VerifyProofSet(doc, verifiers), "verifying proof 0 with verification method did:example:alice#key-1")
+	})
+
+	t.Run("missing verifier", func(tt *testing.T) {
+		doc := getSignedDoc(tt)
+		err := VerifyProofSet(doc, []Verifier{verifierA})
+		assert.ErrorContains(tt, err, "verifying proof 1")
+		assert.ErrorContains(tt, err, "no verifier for verification method: did:example:bob#key-1")
+	})
+
+	t.Run("no proof", func(tt *testing.T) {
+		assert.ErrorContains(tt, VerifyProofSet(getProofSetTestDoc(), verifiers), "document has no proof")
+	})
+}
+
+func TestProofChain(t *testing.T) {
+	suite := GetJSONWebSignature2020Suite()
+	signerA, verifierA := getProofSetSignerVerifier(t, "did:example:alice#key-1")
+	signerB, verifierB := getProofSetSignerVerifier(t, "did:example:bob#key-1")
+	verifiers := []Verifier{verifierA, verifierB}
+
+	getSignedDoc := func(tt *testing.T) map[string]any {
+		doc := getProofSetTestDoc()
+		require.NoError(tt, AddProofToChain(suite, signerA, doc))
+		require.NoError(tt, AddProofToChain(suite, signerB, doc))
+		return doc
+	}
+
+	t.Run("two sequential signers", func(tt *testing.T) {
+		doc := getSignedDoc(tt)
+		proofs, err := getProofs(doc)
+		require.NoError(tt, err)
+		require.Len(tt, proofs, 2)
+		first := proofs[0].(map[string]any)
+		second := proofs[1].(map[string]any)
+		assert.NotEmpty(tt, first["id"])
+		assert.NotContains(tt, first, "previousProof")
+		assert.Equal(tt, first["id"], second["previousProof"])
+
+		assert.NoError(tt, VerifyProofChain(doc, verifiers))
+
+		// the second proof covers the first, so it does not verify as a member of a set
+		assert.ErrorContains(tt, VerifyProofSet(doc, verifiers), "verifying proof 1 with verification method did:example:bob#key-1")
+	})
+
+	t.Run("previous proofs must precede", func(tt *testing.T) {
+		doc := getSignedDoc(tt)
+		proofs, err := getProofs(doc)
+		require.NoError(tt, err)
+		doc["proof"] = []any{proofs[1], proofs[0]}
+		err = VerifyProofChain(doc, verifiers)
+		assert.ErrorContains(tt, err, "verifying proof 0 with verification method did:example:bob#key-1")
+		assert.ErrorContains(tt, err, "does not precede the proof")
+
+		doc["proof"] = []any{proofs[1]}
+		assert.ErrorContains(tt, VerifyProofChain(doc, verifiers), "does not precede the proof")
+	})
+
+	t.Run("tampered previous proof", func(tt *testing.T) {
+		doc := getSignedDoc(tt)
+		proofs, err := getProofs(doc)
+		require.NoError(tt, err)
+		proofs[0].(map[string]any)["created"] = "2000-01-01T00:00:00Z"
+		assert.ErrorContains(tt, VerifyProofChain(doc, verifiers), "verifying proof 0 with verification method did:example:alice#key-1")
+	})
+
+	t.Run("tampered document", func(tt *testing.T) {
+		doc := getSignedDoc(tt)
+		doc["issuer"] = "did:example:mallory"
+		assert.Error(tt, VerifyProofChain(doc, verifiers))
+	})
+
+	t.Run("chain links are covered by the signatures", func(tt *testing.T) {
+		doc := getSignedDoc(tt)
+		proofs, err := getProofs(doc)
+		require.NoError(tt, err)
+		second, err := JSONWebSignatureProofFromGenericProof(proofs[1])
+		require.NoError(tt, err)
+		assert.Equal(tt, proofs[0].(map[string]any)["id"], second.PreviousProof)
+
+		// the second proof covers the same document and previous proof whatever its previousProof, so only its
+		// signature binds the link
+		covered := proofProvable{doc: withoutProof(doc), previous: proofs[:1]}
+		assert.NoError(tt, verifyProof(covered, proofs[1], verifiers, nil))
+		proofs[1].(map[string]any)["previousProof"] = "urn:uuid:other"
+		assert.ErrorContains(tt, verifyProof(covered, proofs[1], verifiers, nil), "could not verify JWS")
+		delete(proofs[1].(map[string]any), "previousProof")
+		assert.ErrorContains(tt, verifyProof(covered, proofs[1], verifiers, nil), "could not verify JWS")
+
+		// relinking the chain to a proof with another id
+		doc = getSignedDoc(tt)
+		proofs, err = getProofs(doc)
+		require.NoError(tt, err)
+		proofs[0].(map[string]any)["id"] = "urn:uuid:other"
+		proofs[1].(map[string]any)["previousProof"] = "urn:uuid:other"
+		assert.ErrorContains(tt, VerifyProofChain(doc, verifiers), "verifying proof 0 with verification method did:example:alice#key-1")
+	})
+
+	t.Run("data integrity proofs", func(tt *testing.T) {
+		pubKey, privKey, err := crypto.GenerateEd25519Key()
+		require.NoError(tt, err)
+		edSigner := NewEd25519Signer("did:example:bob#key-2", privKey, AssertionMethod)
+		edVerifier := NewEd25519Verifier("did:example:bob#key-2", pubKey)
+
+		doc := getProofSetTestDoc()
+		require.NoError(tt, AddProofToChain(suite, signerA, doc))
+		require.NoError(tt, AddProofToChain(GetEdDSAJCS2022Suite(), edSigner, doc))
+		assert.NoError(tt, VerifyProofChain(doc, []Verifier{verifierA, edVerifier}))
+
+		proofs, err := getProofs(doc)
+		require.NoError(tt, err)
+		covered := proofProvable{doc: withoutProof(doc), previous: proofs[:1]}
+		proofs[1].(map[string]any)["previousProof"] = "urn:uuid:other"
+		assert.ErrorContains(tt, verifyProof(covered, proofs[1], []Verifier{edVerifier}, nil), "verifying")
+	})
+
+	t.Run("previous proof without an id", func(tt *testing.T) {
+		doc := getProofSetTestDoc()
+		require.NoError(tt, AddProofToSet(suite, signerA, doc))
+		assert.ErrorContains(tt, AddProofToChain(suite, signerB, doc), "proof has no id")
+	})
+}
+
+func getProofSetTestDoc() map[string]any {
+	return map[string]any{
+		"@context":          []any{W3CCredentialsContext, JSONWebSignature2020Context},
+		"type":              []any{"VerifiableCredential"},
+		"issuer":            "did:example:alice",
+		"issuanceDate":      "2021-01-01T19:23:24Z",
+		"credentialSubject": map[string]any{"id": "did:example:abcd"},
+	}
+}
+
+func getProofSetSignerVerifier(t *testing.T, kid string) (Signer, Verifier) {
+	_, privKey, err := crypto.GenerateP256Key()
+	require.NoError(t, err)
+	pubJWK, privJWK, err := jwx.PrivateKeyToPrivateKeyJWK(privKey)
+	require.NoError(t, err)
+	signer, err := NewJSONWebKeySigner(kid, kid, *privJWK, AssertionMethod)
+	require.NoError(t, err)
+	verifier, err := NewJSONWebKeyVerifier(kid, *pubJWK)
+	require.NoError(t, err)
+	return signer, verifier
+}
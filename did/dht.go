@@ -0,0 +1,732 @@
+package did
+
+import (
+	"context"
+	gocrypto "crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"encoding/base64"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"github.com/decred/dcrd/dcrec/secp256k1/v4"
+	"github.com/pkg/errors"
+
+	"github.com/TBD54566975/ssi-sdk/crypto"
+	"github.com/TBD54566975/ssi-sdk/crypto/jwx"
+	"github.com/TBD54566975/ssi-sdk/cryptosuite"
+)
+
+// did:dht method specification https://did-dht.com
+// DIDs are the z-base-32 encoded Ed25519 identity key, with the DID Document stored as a DNS packet in a BEP44
+// mutable item signed by that key. Only resolution through a gateway is implemented in this package.
+type (
+	DIDDHT string
+)
+
+const (
+	// DHTPrefix did:dht prefix
+	DHTPrefix = "did:dht"
+
+	// dhtSignatureSize, dhtSeqSize are the sizes of the signature and sequence number preceding the DNS packet
+	// in a BEP44 signed packet https://www.bittorrent.org/beps/bep_0044.html
+	dhtSignatureSize = ed25519.SignatureSize
+	dhtSeqSize       = 8
+	// dhtMaxValueSize is the maximum size of the DNS packet, the v of a BEP44 mutable item, and dhtMaxPacketSize that
+	// of a signed packet returned by a gateway
+	dhtMaxValueSize  = 1000
+	dhtMaxPacketSize = dhtSignatureSize + dhtSeqSize + dhtMaxValueSize
+)
+
+// ErrInvalidDHTSignature is returned when the signed packet for a did:dht DID is not signed by its identity key
+var ErrInvalidDHTSignature = errors.New("invalid did:dht signature")
+
+func (d DIDDHT) IsValid() bool {
+	_, err := d.IdentityKey()
+	return err == nil
+}
+
+func (d DIDDHT) String() string {
+	return string(d)
+}
+
+// Suffix returns the z-base-32 encoded identity key
+func (d DIDDHT) Suffix() (string, error) {
+	if !strings.HasPrefix(string(d), DHTPrefix+":") {
+		return "", fmt.Errorf("not a did:dht DID, invalid prefix: %s", d)
+	}
+	return strings.TrimPrefix(string(d), DHTPrefix+":"), nil
+}
+
+func (DIDDHT) Method() Method {
+	return DHTMethod
+}
+
+// IdentityKey decodes the Ed25519 identity key encoded in the DID
+func (d DIDDHT) IdentityKey() (ed25519.PublicKey, error) {
+	suffix, err := d.Suffix()
+	if err != nil {
+		return nil, err
+	}
+	decoded, err := zBase32Decode(suffix)
+	if err != nil {
+		return nil, errors.Wrap(err, "decoding did:dht identity key")
+	}
+	if len(decoded) != ed25519.PublicKeySize {
+		return nil, fmt.Errorf("invalid did:dht identity key size: %d", len(decoded))
+	}
+	return decoded, nil
+}
+
+// DHTResolver resolves did:dht DIDs by fetching their signed packets from a gateway, such as a Pkarr relay
+type DHTResolver struct {
+	client     *http.Client
+	gatewayURL url.URL
+}
+
+var _ Resolver = (*DHTResolver)(nil)
+
+// NewDHTResolver creates a new resolver for the did:dht method using the given gateway. The resolver requests
+// the signed packet for a DID from the gateway by appending the DID's suffix, for example:
+//
+//	https://diddht.tbddev.org/<z-base-32-identity-key>
+func NewDHTResolver(client *http.Client, gatewayURL string) (*DHTResolver, error) {
+	if client == nil {
+		return nil, errors.New("client cannot be nil")
+	}
+	parsedURL, err := url.ParseRequestURI(gatewayURL)
+	if err != nil {
+		return nil, errors.Wrap(err, "invalid gateway URL")
+	}
+	return &DHTResolver{
+		client:     client,
+		gatewayURL: *parsedURL,
+	}, nil
+}
+
+func (DHTResolver) Methods() []Method {
+	return []Method{DHTMethod}
+}
+
+// Resolve fetches the signed packet for the DID from the gateway, verifies it was signed by the DID's identity key,
+// and decodes the DID Document from the DNS packet it contains. Failures are returned as a *ResolutionError, with
+// code InvalidDIDErrorCode for a malformed DID and NotFoundErrorCode if the gateway has no packet for it; one wrapping
+// ErrInvalidDHTSignature is returned if verification fails. A DID whose packet has no records has been deactivated,
+// and is resolved as such.
+func (r DHTResolver) Resolve(ctx context.Context, did string, _ ...ResolutionOption) (*ResolutionResult, error) {
+	didDHT := DIDDHT(did)
+	identityKey, err := didDHT.IdentityKey()
+	if err != nil {
+		return nil, NewResolutionError(InvalidDIDErrorCode, did, err)
+	}
+	suffix, err := didDHT.Suffix()
+	if err != nil {
+		return nil, NewResolutionError(InvalidDIDErrorCode, did, err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, strings.TrimSuffix(r.gatewayURL.String(), "/")+"/"+suffix, nil)
+	if err != nil {
+		return nil, NewResolutionError(InternalErrorCode, did, errors.Wrap(err, "creating request"))
+	}
+	resp, err := r.client.Do(req)
+	if err != nil {
+		return nil, NewResolutionError(InternalErrorCode, did, errors.Wrapf(err, "requesting gateway: %s", r.gatewayURL.String()))
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode == http.StatusNotFound {
+		return nil, NewResolutionError(NotFoundErrorCode, did, errors.New("gateway has no signed packet for the DID"))
+	}
+	if resp.StatusCode < http.StatusOK || resp.StatusCode >= http.StatusMultipleChoices {
+		return nil, NewResolutionError(InternalErrorCode, did, fmt.Errorf("gateway responded with status code %d", resp.StatusCode))
+	}
+	// read a byte past the limit to tell a packet of exactly the maximum size from a larger one
+	body, err := io.ReadAll(io.LimitReader(resp.Body, dhtMaxPacketSize+1))
+	if err != nil {
+		return nil, NewResolutionError(InternalErrorCode, did, errors.Wrap(err, "reading gateway response"))
+	}
+	if len(body) > dhtMaxPacketSize {
+		return nil, NewResolutionError(InternalErrorCode, did, fmt.Errorf("signed packet exceeds the maximum size of %d bytes", dhtMaxPacketSize))
+	}
+
+	dnsPacket, err := verifyDHTSignedPacket(identityKey, body)
+	if err != nil {
+		return nil, NewResolutionError(InternalErrorCode, did, err)
+	}
+	records, err := decodeDNSTXTRecords(dnsPacket)
+	if err != nil {
+		return nil, NewResolutionError(InternalErrorCode, did, errors.Wrap(err, "decoding dns packet"))
+	}
+	if len(records) == 0 {
+		return &ResolutionResult{Document: Document{ID: did}, DocumentMetadata: DocumentMetadata{Deactivated: true}}, nil
+	}
+	doc, err := documentFromDHTRecords(did, records)
+	if err != nil {
+		return nil, NewResolutionError(InternalErrorCode, did, errors.Wrap(err, "decoding document"))
+	}
+	return &ResolutionResult{Document: *doc}, nil
+}
+
+// verifyDHTSignedPacket verifies a signed packet of the form signature || seq || v against the identity key,
+// returning v. The signature is over the bencoded seq and v, as specified by BEP44.
+func verifyDHTSignedPacket(identityKey ed25519.PublicKey, packet []byte) ([]byte, error) {
+	if len(packet) < dhtSignatureSize+dhtSeqSize {
+		return nil, fmt.Errorf("signed packet is too short: %d bytes", len(packet))
+	}
+	signature := packet[:dhtSignatureSize]
+	seq := binary.BigEndian.Uint64(packet[dhtSignatureSize : dhtSignatureSize+dhtSeqSize])
+	v := packet[dhtSignatureSize+dhtSeqSize:]
+	if !ed25519.Verify(identityKey, bep44Signable(seq, v), signature) {
+		return nil, ErrInvalidDHTSignature
+	}
+	return v, nil
+}
+
+// bep44Signable returns the bencoded seq and v values signed in a BEP44 mutable item
+func bep44Signable(seq uint64, v []byte) []byte {
+	return append([]byte(fmt.Sprintf("3:seqi%de1:v%d:", seq, len(v))), v...)
+}
+
+// did:dht TXT record conventions https://did-dht.com/#dids-as-dns-records
+const (
+	dhtRootRecordName = "_did"
+	dhtVersionKey     = "v"
+	dhtVMKey          = "vm"
+	dhtAuthKey        = "auth"
+	dhtAssertionKey   = "asm"
+	dhtAgreementKey   = "agm"
+	dhtInvocationKey  = "inv"
+	dhtDelegationKey  = "del"
+	dhtServiceKey     = "svc"
+)
+
+// dhtKeyTypes maps the did:dht key type indices to key types
+var dhtKeyTypes = map[string]crypto.KeyType{
+	"0": crypto.Ed25519,
+	"1": crypto.SECP256k1,
+	"2": crypto.P256,
+}
+
+// DocumentFromDHTPacket decodes a DID Document from a did:dht DNS packet, the inverse of ToDHTPacket. The DID is
+// taken from the name of the root record, _did.<z-base-32-identity-key>.
+func DocumentFromDHTPacket(b []byte) (*Document, error) {
+	records, err := decodeDNSTXTRecords(b)
+	if err != nil {
+		return nil, errors.Wrap(err, "decoding dns packet")
+	}
+	return documentFromDHTRecords("", records)
+}
+
+// documentFromDHTRecords decodes a DID Document from the TXT records of a did:dht DNS packet. The root record
+// lists the key and service records, which are referenced by name from the relationship properties. If the DID
+// is empty, it is taken from the name of the root record, otherwise the root record must belong to the DID.
+func documentFromDHTRecords(did string, records []dnsTXTRecord) (*Document, error) {
+	recordsByName := make(map[string]string, len(records))
+	var root string
+	var hasRoot bool
+	for _, record := range records {
+		name := strings.TrimSuffix(record.Name, ".")
+		value := strings.Join(record.Values, "")
+		recordsByName[name] = value
+
+		// the root record is named either _did or _did.<z-base-32-identity-key>
+		rootName, suffix, _ := strings.Cut(name, ".")
+		if rootName != dhtRootRecordName || strings.Contains(suffix, ".") {
+			continue
+		}
+		if suffix != "" {
+			rootDID := DHTPrefix + ":" + suffix
+			if did != "" && did != rootDID {
+				return nil, fmt.Errorf("root record for DID<%s> does not match DID<%s>", rootDID, did)
+			}
+			did = rootDID
+		}
+		root, hasRoot = value, true
+	}
+	if !hasRoot {
+		return nil, errors.New("root record not found")
+	}
+	if did == "" {
+		return nil, errors.New("could not determine DID from root record")
+	}
+	rootProperties := parseDHTProperties(root)
+
+	doc := Document{
+		Context: KnownDIDContext,
+		ID:      did,
+	}
+	vmIDs := make(map[string]string)
+	for _, name := range splitDHTList(rootProperties[dhtVMKey]) {
+		record, ok := recordsByName["_"+name+"."+dhtRootRecordName]
+		if !ok {
+			return nil, fmt.Errorf("verification method record<%s> not found", name)
+		}
+		vm, err := dhtVerificationMethod(did, parseDHTProperties(record))
+		if err != nil {
+			return nil, errors.Wrapf(err, "decoding verification method record<%s>", name)
+		}
+		vmIDs[name] = vm.ID
+		doc.VerificationMethod = append(doc.VerificationMethod, *vm)
+	}
+
+	relationships := []struct {
+		key string
+		set *[]VerificationMethodSet
+	}{
+		{dhtAuthKey, &doc.Authentication},
+		{dhtAssertionKey, &doc.AssertionMethod},
+		{dhtAgreementKey, &doc.KeyAgreement},
+		{dhtInvocationKey, &doc.CapabilityInvocation},
+		{dhtDelegationKey, &doc.CapabilityDelegation},
+	}
+	for _, relationship := range relationships {
+		for _, name := range splitDHTList(rootProperties[relationship.key]) {
+			id, ok := vmIDs[name]
+			if !ok {
+				return nil, fmt.Errorf("%s references unknown verification method<%s>", relationship.key, name)
+			}
+			*relationship.set = append(*relationship.set, id)
+		}
+	}
+
+	for _, name := range splitDHTList(rootProperties[dhtServiceKey]) {
+		record, ok := recordsByName["_"+name+"."+dhtRootRecordName]
+		if !ok {
+			return nil, fmt.Errorf("service record<%s> not found", name)
+		}
+		properties := parseDHTProperties(record)
+		service := Service{
+			ID:   did + "#" + properties["id"],
+			Type: properties["t"],
+		}
+		if endpoints := splitDHTList(properties["se"]); len(endpoints) == 1 {
+			service.ServiceEndpoint = endpoints[0]
+		} else {
+			service.ServiceEndpoint = endpoints
+		}
+		doc.Services = append(doc.Services, service)
+	}
+	return &doc, nil
+}
+
+// dhtRecordTTL is the TTL of the records in packets created by ToDHTPacket
+const dhtRecordTTL = 7200
+
+// ToDHTPacket encodes the Document as a did:dht DNS packet, which may then be signed and published to the DHT as a
+// BEP44 mutable item. Verification methods and services are encoded in order as the records _k0._did., _k1._did.,
+// and _s0._did., and so on, which are referenced from the root record _did.<z-base-32-identity-key>.
+// Verification methods must be Ed25519, secp256k1, or P-256 keys, and relationships must reference them by id.
+// https://did-dht.com/#dids-as-dns-records
+func (d *Document) ToDHTPacket() ([]byte, error) {
+	suffix, err := DIDDHT(d.ID).Suffix()
+	if err != nil {
+		return nil, err
+	}
+
+	rootProperties := []string{dhtVersionKey + "=0"}
+	var records []dnsTXTRecord
+	vmNames := make(map[string]string, len(d.VerificationMethod))
+	var names []string
+	for i, vm := range d.VerificationMethod {
+		name := fmt.Sprintf("k%d", i)
+		properties, err := dhtKeyRecordProperties(vm)
+		if err != nil {
+			return nil, errors.Wrapf(err, "encoding verification method<%s>", vm.ID)
+		}
+		records = append(records, dnsTXTRecord{Name: "_" + name + "." + dhtRootRecordName + ".", TTL: dhtRecordTTL, Values: []string{properties}})
+		vmNames[dhtFragment(vm.ID)] = name
+		names = append(names, name)
+	}
+	if len(names) > 0 {
+		rootProperties = append(rootProperties, dhtVMKey+"="+strings.Join(names, ","))
+	}
+
+	relationships := []struct {
+		key string
+		set []VerificationMethodSet
+	}{
+		{dhtAuthKey, d.Authentication},
+		{dhtAssertionKey, d.AssertionMethod},
+		{dhtAgreementKey, d.KeyAgreement},
+		{dhtInvocationKey, d.CapabilityInvocation},
+		{dhtDelegationKey, d.CapabilityDelegation},
+	}
+	for _, relationship := range relationships {
+		var refs []string
+		for _, entry := range relationship.set {
+			ids, err := verificationMethodSetIDs(entry)
+			if err != nil {
+				return nil, errors.Wrapf(err, "encoding %s", relationship.key)
+			}
+			for _, id := range ids {
+				name, ok := vmNames[dhtFragment(id)]
+				if !ok {
+					return nil, fmt.Errorf("%s references unknown verification method<%s>", relationship.key, id)
+				}
+				refs = append(refs, name)
+			}
+		}
+		if len(refs) > 0 {
+			rootProperties = append(rootProperties, relationship.key+"="+strings.Join(refs, ","))
+		}
+	}
+
+	names = nil
+	for i, service := range d.Services {
+		name := fmt.Sprintf("s%d", i)
+		endpoints, err := serviceEndpointStrings(service.ServiceEndpoint)
+		if err != nil {
+			return nil, errors.Wrapf(err, "encoding service<%s>", service.ID)
+		}
+		properties := fmt.Sprintf("id=%s;t=%s;se=%s", dhtFragment(service.ID), service.Type, strings.Join(endpoints, ","))
+		records = append(records, dnsTXTRecord{Name: "_" + name + "." + dhtRootRecordName + ".", TTL: dhtRecordTTL, Values: []string{properties}})
+		names = append(names, name)
+	}
+	if len(names) > 0 {
+		rootProperties = append(rootProperties, dhtServiceKey+"="+strings.Join(names, ","))
+	}
+
+	root := dnsTXTRecord{Name: dhtRootRecordName + "." + suffix + ".", TTL: dhtRecordTTL, Values: []string{strings.Join(rootProperties, ";")}}
+	return encodeDNSTXTRecords(append([]dnsTXTRecord{root}, records...))
+}
+
+// dhtKeyRecordProperties encodes the verification method's key as the properties of a key record
+func dhtKeyRecordProperties(vm VerificationMethod) (string, error) {
+	pubKey, err := extractKeyFromVerificationMethod(vm)
+	if err != nil {
+		return "", err
+	}
+	var keyType string
+	var keyBytes []byte
+	switch key := pubKey.(type) {
+	case ed25519.PublicKey:
+		keyType, keyBytes = "0", key
+	case *ecdsa.PublicKey:
+		switch key.Curve.Params().Name {
+		case secp256k1.S256().Params().Name:
+			keyType = "1"
+		case elliptic.P256().Params().Name:
+			keyType = "2"
+		default:
+			return "", fmt.Errorf("unsupported curve<%s>", key.Curve.Params().Name)
+		}
+		keyBytes = elliptic.MarshalCompressed(key.Curve, key.X, key.Y)
+	default:
+		return "", fmt.Errorf("unsupported key type<%T>", pubKey)
+	}
+	return fmt.Sprintf("id=%s;t=%s;k=%s", dhtFragment(vm.ID), keyType, base64.RawURLEncoding.EncodeToString(keyBytes)), nil
+}
+
+// dhtFragment returns the fragment of an absolute or relative DID URL
+func dhtFragment(id string) string {
+	if i := strings.LastIndex(id, "#"); i >= 0 {
+		return id[i+1:]
+	}
+	return id
+}
+
+// verificationMethodSetIDs returns the ids referenced by a relationship entry, which may be an id, a list of ids,
+// or an embedded verification method
+func verificationMethodSetIDs(entry VerificationMethodSet) ([]string, error) {
+	switch e := entry.(type) {
+	case string:
+		return []string{e}, nil
+	case []string:
+		return e, nil
+	case VerificationMethod:
+		return []string{e.ID}, nil
+	case *VerificationMethod:
+		return []string{e.ID}, nil
+	case []any:
+		var ids []string
+		for _, item := range e {
+			itemIDs, err := verificationMethodSetIDs(item)
+			if err != nil {
+				return nil, err
+			}
+			ids = append(ids, itemIDs...)
+		}
+		return ids, nil
+	default:
+		return nil, fmt.Errorf("unsupported verification method set<%T>", entry)
+	}
+}
+
+// serviceEndpointStrings returns the service endpoint as a list of strings, as only string endpoints can be encoded
+func serviceEndpointStrings(endpoint any) ([]string, error) {
+	switch e := endpoint.(type) {
+	case string:
+		return []string{e}, nil
+	case []string:
+		return e, nil
+	case []any:
+		endpoints := make([]string, 0, len(e))
+		for _, item := range e {
+			s, ok := item.(string)
+			if !ok {
+				return nil, fmt.Errorf("unsupported service endpoint<%T>", item)
+			}
+			endpoints = append(endpoints, s)
+		}
+		return endpoints, nil
+	default:
+		return nil, fmt.Errorf("unsupported service endpoint<%T>", endpoint)
+	}
+}
+
+// dhtVerificationMethod decodes a verification method from the properties of a key record
+func dhtVerificationMethod(did string, properties map[string]string) (*VerificationMethod, error) {
+	kt, ok := dhtKeyTypes[properties["t"]]
+	if !ok {
+		return nil, fmt.Errorf("unsupported key type<%s>", properties["t"])
+	}
+	keyBytes, err := base64.RawURLEncoding.DecodeString(properties["k"])
+	if err != nil {
+		return nil, errors.Wrap(err, "decoding key")
+	}
+	pubKey, err := dhtPublicKey(kt, keyBytes)
+	if err != nil {
+		return nil, err
+	}
+	pubKeyJWK, err := jwx.PublicKeyToPublicKeyJWK(pubKey)
+	if err != nil {
+		return nil, errors.Wrap(err, "converting key to JWK")
+	}
+	return &VerificationMethod{
+		ID:           did + "#" + properties["id"],
+		Type:         cryptosuite.JSONWebKey2020Type,
+		Controller:   did,
+		PublicKeyJWK: pubKeyJWK,
+	}, nil
+}
+
+// dhtPublicKey parses a key, whose EC keys are expected in their compressed form
+func dhtPublicKey(kt crypto.KeyType, keyBytes []byte) (gocrypto.PublicKey, error) {
+	switch kt {
+	case crypto.Ed25519:
+		if len(keyBytes) != ed25519.PublicKeySize {
+			return nil, fmt.Errorf("invalid ed25519 key size: %d", len(keyBytes))
+		}
+		return ed25519.PublicKey(keyBytes), nil
+	case crypto.SECP256k1:
+		return crypto.ParseECPublicKey(crypto.SECP256k1ECDSA, keyBytes)
+	case crypto.P256:
+		return crypto.ParseECPublicKey(crypto.P256, keyBytes)
+	default:
+		return nil, fmt.Errorf("unsupported key type<%s>", kt)
+	}
+}
+
+// parseDHTProperties parses the semicolon separated key=value properties of a TXT record
+func parseDHTProperties(record string) map[string]string {
+	properties := make(map[string]string)
+	for _, property := range strings.Split(record, ";") {
+		if key, value, ok := strings.Cut(property, "="); ok {
+			properties[key] = value
+		}
+	}
+	return properties
+}
+
+// splitDHTList splits a comma separated property value, returning nil for an empty value
+func splitDHTList(value string) []string {
+	if value == "" {
+		return nil
+	}
+	return strings.Split(value, ",")
+}
+
+// dnsTXTRecord is a DNS TXT resource record, whose value may be split over multiple character strings
+type dnsTXTRecord struct {
+	Name   string
+	TTL    uint32
+	Values []string
+}
+
+const (
+	dnsHeaderSize    = 12
+	dnsTypeTXT       = 16
+	dnsClassINET     = 1
+	dnsMaxStringSize = 255
+	// dnsResponseFlags marks the message as an authoritative response
+	dnsResponseFlags = 0x8400
+)
+
+// encodeDNSTXTRecords encodes the records as the answers of a DNS response message
+// https://www.rfc-editor.org/rfc/rfc1035#section-4.1
+func encodeDNSTXTRecords(records []dnsTXTRecord) ([]byte, error) {
+	if len(records) > 0xffff {
+		return nil, fmt.Errorf("too many records: %d", len(records))
+	}
+	msg := make([]byte, dnsHeaderSize)
+	binary.BigEndian.PutUint16(msg[2:], dnsResponseFlags)
+	binary.BigEndian.PutUint16(msg[6:], uint16(len(records)))
+
+	for _, record := range records {
+		for _, label := range strings.Split(strings.TrimSuffix(record.Name, "."), ".") {
+			if len(label) == 0 || len(label) > 63 {
+				return nil, fmt.Errorf("invalid label<%s> in name<%s>", label, record.Name)
+			}
+			msg = append(msg, byte(len(label)))
+			msg = append(msg, label...)
+		}
+		msg = append(msg, 0)
+
+		var rdata []byte
+		for _, value := range record.Values {
+			// character strings are limited in length, so long values are split over several strings
+			for len(value) > dnsMaxStringSize {
+				rdata = append(rdata, dnsMaxStringSize)
+				rdata = append(rdata, value[:dnsMaxStringSize]...)
+				value = value[dnsMaxStringSize:]
+			}
+			rdata = append(rdata, byte(len(value)))
+			rdata = append(rdata, value...)
+		}
+		if len(rdata) > 0xffff {
+			return nil, fmt.Errorf("record<%s> is too large", record.Name)
+		}
+
+		fixed := make([]byte, 10)
+		binary.BigEndian.PutUint16(fixed[0:], dnsTypeTXT)
+		binary.BigEndian.PutUint16(fixed[2:], dnsClassINET)
+		binary.BigEndian.PutUint32(fixed[4:], record.TTL)
+		binary.BigEndian.PutUint16(fixed[8:], uint16(len(rdata)))
+		msg = append(msg, fixed...)
+		msg = append(msg, rdata...)
+	}
+	return msg, nil
+}
+
+// decodeDNSTXTRecords decodes the TXT records from the answers of a DNS message, ignoring records of other types
+// https://www.rfc-editor.org/rfc/rfc1035#section-4.1
+func decodeDNSTXTRecords(msg []byte) ([]dnsTXTRecord, error) {
+	if len(msg) < dnsHeaderSize {
+		return nil, errors.New("dns message is too short")
+	}
+	questions := int(binary.BigEndian.Uint16(msg[4:]))
+	answers := int(binary.BigEndian.Uint16(msg[6:]))
+
+	offset := dnsHeaderSize
+	for i := 0; i < questions; i++ {
+		_, next, err := decodeDNSName(msg, offset)
+		if err != nil {
+			return nil, errors.Wrap(err, "decoding question")
+		}
+		// skip the question type and class
+		offset = next + 4
+	}
+
+	var records []dnsTXTRecord
+	for i := 0; i < answers; i++ {
+		name, next, err := decodeDNSName(msg, offset)
+		if err != nil {
+			return nil, errors.Wrap(err, "decoding answer")
+		}
+		if next+10 > len(msg) {
+			return nil, errors.New("answer is truncated")
+		}
+		rrType := binary.BigEndian.Uint16(msg[next:])
+		ttl := binary.BigEndian.Uint32(msg[next+4:])
+		rdLength := int(binary.BigEndian.Uint16(msg[next+8:]))
+		rdata := next + 10
+		offset = rdata + rdLength
+		if offset > len(msg) {
+			return nil, errors.New("answer data is truncated")
+		}
+		if rrType != dnsTypeTXT {
+			continue
+		}
+
+		record := dnsTXTRecord{Name: name, TTL: ttl}
+		for pos := rdata; pos < offset; {
+			size := int(msg[pos])
+			if pos+1+size > offset {
+				return nil, errors.New("txt character string is truncated")
+			}
+			record.Values = append(record.Values, string(msg[pos+1:pos+1+size]))
+			pos += 1 + size
+		}
+		records = append(records, record)
+	}
+	return records, nil
+}
+
+// decodeDNSName decodes the possibly compressed name at the offset, returning the name and the offset following it
+func decodeDNSName(msg []byte, offset int) (string, int, error) {
+	var labels []string
+	next := -1
+	for hops := 0; ; hops++ {
+		if offset >= len(msg) || hops > len(msg) {
+			return "", 0, errors.New("invalid name")
+		}
+		size := int(msg[offset])
+		switch {
+		case size == 0:
+			if next < 0 {
+				next = offset + 1
+			}
+			return strings.Join(labels, ".") + ".", next, nil
+		case size&0xc0 == 0xc0:
+			// a pointer to a name elsewhere in the message
+			if offset+1 >= len(msg) {
+				return "", 0, errors.New("invalid name pointer")
+			}
+			if next < 0 {
+				next = offset + 2
+			}
+			offset = int(binary.BigEndian.Uint16(msg[offset:]) & 0x3fff)
+		default:
+			if offset+1+size > len(msg) {
+				return "", 0, errors.New("name label is truncated")
+			}
+			labels = append(labels, string(msg[offset+1:offset+1+size]))
+			offset += 1 + size
+		}
+	}
+}
+
+// zBase32Alphabet https://philzimmermann.com/docs/human-oriented-base-32-encoding.txt
+const zBase32Alphabet = "ybndrfg8ejkmcpqxot1uwisza345h769"
+
+// zBase32Encode encodes the bytes using z-base-32, without padding
+func zBase32Encode(data []byte) string {
+	var sb strings.Builder
+	var buffer, bits uint
+	for _, b := range data {
+		buffer = buffer<<8 | uint(b)
+		bits += 8
+		for bits >= 5 {
+			bits -= 5
+			sb.WriteByte(zBase32Alphabet[(buffer>>bits)&0x1f])
+		}
+	}
+	if bits > 0 {
+		sb.WriteByte(zBase32Alphabet[(buffer<<(5-bits))&0x1f])
+	}
+	return sb.String()
+}
+
+// zBase32Decode decodes an unpadded z-base-32 string, discarding any trailing partial byte
+func zBase32Decode(encoded string) ([]byte, error) {
+	decoded := make([]byte, 0, len(encoded)*5/8)
+	var buffer, bits uint
+	for _, c := range encoded {
+		value := strings.IndexRune(zBase32Alphabet, c)
+		if value < 0 {
+			return nil, fmt.Errorf("invalid z-base-32 character: %s", strconv.QuoteRune(c))
+		}
+		buffer = buffer<<5 | uint(value)
+		bits += 5
+		if bits >= 8 {
+			bits -= 8
+			decoded = append(decoded, byte(buffer>>bits))
+		}
+	}
+	return decoded, nil
+}
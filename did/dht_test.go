@@ -0,0 +1,307 @@
+package did
+
+import (
+	"context"
+	"crypto/ed25519"
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/binary"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/TBD54566975/ssi-sdk/crypto"
+	"github.com/TBD54566975/ssi-sdk/crypto/jwx"
+	"github.com/TBD54566975/ssi-sdk/cryptosuite"
+)
+
+// newDHTSignedPacket signs the DNS packet encoding the records as a BEP44 mutable item
+func newDHTSignedPacket(t *testing.T, privKey ed25519.PrivateKey, seq uint64, records []dnsTXTRecord) []byte {
+	v, err := encodeDNSTXTRecords(records)
+	assert.NoError(t, err)
+	packet := ed25519.Sign(privKey, bep44Signable(seq, v))
+	packet = binary.BigEndian.AppendUint64(packet, seq)
+	return append(packet, v...)
+}
+
+func TestDHTResolver(t *testing.T) {
+	pubKey, privKey, err := ed25519.GenerateKey(rand.Reader)
+	assert.NoError(t, err)
+	suffix := zBase32Encode(pubKey)
+	didDHT := DHTPrefix + ":" + suffix
+
+	secpPubKey, _, err := crypto.GenerateSECP256k1Key()
+	assert.NoError(t, err)
+
+	records := []dnsTXTRecord{
+		{Name: "_did.", TTL: 7200, Values: []string{"v=0;vm=k0,k1;auth=k0;asm=k0,k1;agm=k1;inv=k0;del=k0;svc=s0"}},
+		{Name: "_k0._did.", TTL: 7200, Values: []string{"id=0;t=0;k=" + base64.RawURLEncoding.EncodeToString(pubKey)}},
+		{Name: "_k1._did.", TTL: 7200, Values: []string{"id=sig;t=1;k=" + base64.RawURLEncoding.EncodeToString(secpPubKey.SerializeCompressed())}},
+		{Name: "_s0._did.", TTL: 7200, Values: []string{"id=dwn;t=DecentralizedWebNode;se=https://example.com/dwn"}},
+	}
+
+	newServer := func(t *testing.T, packet []byte) *httptest.Server {
+		server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if r.URL.Path != "/"+suffix {
+				http.NotFound(w, r)
+				return
+			}
+			_, _ = w.Write(packet)
+		}))
+		t.Cleanup(server.Close)
+		return server
+	}
+
+	t.Run("resolves a signed packet", func(tt *testing.T) {
+		server := newServer(tt, newDHTSignedPacket(tt, privKey, 1, records))
+		resolver, err := NewDHTResolver(server.Client(), server.URL)
+		assert.NoError(tt, err)
+
+		resolved, err := resolver.Resolve(context.Background(), didDHT)
+		assert.NoError(tt, err)
+		doc := resolved.Document
+		assert.Equal(tt, didDHT, doc.ID)
+		assert.NoError(tt, doc.IsValid())
+
+		assert.Len(tt, doc.VerificationMethod, 2)
+		assert.Equal(tt, didDHT+"#0", doc.VerificationMethod[0].ID)
+		assert.Equal(tt, cryptosuite.JSONWebKey2020Type, doc.VerificationMethod[0].Type)
+		assert.Equal(tt, "Ed25519", doc.VerificationMethod[0].PublicKeyJWK.CRV)
+		assert.Equal(tt, didDHT+"#sig", doc.VerificationMethod[1].ID)
+		assert.Equal(tt, "secp256k1", doc.VerificationMethod[1].PublicKeyJWK.CRV)
+
+		assert.Equal(tt, []VerificationMethodSet{didDHT + "#0"}, doc.Authentication)
+		assert.Equal(tt, []VerificationMethodSet{didDHT + "#0", didDHT + "#sig"}, doc.AssertionMethod)
+		assert.Equal(tt, []VerificationMethodSet{didDHT + "#sig"}, doc.KeyAgreement)
+		assert.Equal(tt, []VerificationMethodSet{didDHT + "#0"}, doc.CapabilityInvocation)
+		assert.Equal(tt, []VerificationMethodSet{didDHT + "#0"}, doc.CapabilityDelegation)
+
+		assert.Len(tt, doc.Services, 1)
+		assert.Equal(tt, didDHT+"#dwn", doc.Services[0].ID)
+		assert.Equal(tt, "DecentralizedWebNode", doc.Services[0].Type)
+		assert.Equal(tt, "https://example.com/dwn", doc.Services[0].ServiceEndpoint)
+	})
+
+	t.Run("resolves an empty packet as deactivated", func(tt *testing.T) {
+		server := newServer(tt, newDHTSignedPacket(tt, privKey, 2, nil))
+		resolver, err := NewDHTResolver(server.Client(), server.URL)
+		assert.NoError(tt, err)
+
+		resolved, err := resolver.Resolve(context.Background(), didDHT)
+		assert.NoError(tt, err)
+		assert.True(tt, resolved.IsDeactivated())
+		assert.Equal(tt, didDHT, resolved.Document.ID)
+	})
+
+	t.Run("rejects a packet signed by another key", func(tt *testing.T) {
+		_, otherKey, err := ed25519.GenerateKey(rand.Reader)
+		assert.NoError(tt, err)
+		server := newServer(tt, newDHTSignedPacket(tt, otherKey, 1, records))
+		resolver, err := NewDHTResolver(server.Client(), server.URL)
+		assert.NoError(tt, err)
+
+		_, err = resolver.Resolve(context.Background(), didDHT)
+		assert.Error(tt, err)
+		assert.True(tt, errors.Is(err, ErrInvalidDHTSignature))
+	})
+
+	t.Run("rejects a tampered packet", func(tt *testing.T) {
+		packet := newDHTSignedPacket(tt, privKey, 1, records)
+		packet[len(packet)-1] ^= 0xff
+		server := newServer(tt, packet)
+		resolver, err := NewDHTResolver(server.Client(), server.URL)
+		assert.NoError(tt, err)
+
+		_, err = resolver.Resolve(context.Background(), didDHT)
+		assert.ErrorIs(tt, err, ErrInvalidDHTSignature)
+	})
+
+	t.Run("rejects an oversized packet", func(tt *testing.T) {
+		server := newServer(tt, make([]byte, dhtMaxPacketSize+1))
+		resolver, err := NewDHTResolver(server.Client(), server.URL)
+		assert.NoError(tt, err)
+
+		_, err = resolver.Resolve(context.Background(), didDHT)
+		assert.ErrorContains(tt, err, "exceeds the maximum size of 1072 bytes")
+		assert.NotErrorIs(tt, err, ErrInvalidDHTSignature)
+	})
+
+	t.Run("transport failures are not signature failures", func(tt *testing.T) {
+		server := newServer(tt, nil)
+		resolver, err := NewDHTResolver(server.Client(), server.URL)
+		assert.NoError(tt, err)
+		server.Close()
+
+		_, err = resolver.Resolve(context.Background(), didDHT)
+		assert.Error(tt, err)
+		assert.NotErrorIs(tt, err, ErrInvalidDHTSignature)
+	})
+
+	t.Run("not found", func(tt *testing.T) {
+		otherPubKey, _, err := ed25519.GenerateKey(rand.Reader)
+		assert.NoError(tt, err)
+		server := newServer(tt, nil)
+		resolver, err := NewDHTResolver(server.Client(), server.URL)
+		assert.NoError(tt, err)
+
+		_, err = resolver.Resolve(context.Background(), DHTPrefix+":"+zBase32Encode(otherPubKey))
+		var resolutionErr *ResolutionError
+		assert.True(tt, errors.As(err, &resolutionErr))
+		assert.Equal(tt, NotFoundErrorCode, resolutionErr.Code)
+	})
+
+	t.Run("invalid DIDs", func(tt *testing.T) {
+		resolver, err := NewDHTResolver(http.DefaultClient, "https://example.com")
+		assert.NoError(tt, err)
+
+		_, err = resolver.Resolve(context.Background(), "did:key:"+suffix)
+		assert.Error(tt, err)
+		assert.Contains(tt, err.Error(), "invalid prefix")
+		var resolutionErr *ResolutionError
+		assert.True(tt, errors.As(err, &resolutionErr))
+		assert.Equal(tt, InvalidDIDErrorCode, resolutionErr.Code)
+
+		_, err = resolver.Resolve(context.Background(), DHTPrefix+":"+suffix[:10])
+		assert.Error(tt, err)
+		assert.Contains(tt, err.Error(), "invalid did:dht identity key size")
+
+		_, err = resolver.Resolve(context.Background(), DHTPrefix+":"+strings.Repeat("l", len(suffix)))
+		assert.Error(tt, err)
+		assert.Contains(tt, err.Error(), "invalid z-base-32 character")
+	})
+
+	t.Run("invalid resolver configuration", func(tt *testing.T) {
+		_, err := NewDHTResolver(nil, "https://example.com")
+		assert.Error(tt, err)
+
+		_, err = NewDHTResolver(http.DefaultClient, "not a url")
+		assert.Error(tt, err)
+	})
+}
+
+func TestZBase32(t *testing.T) {
+	// bits are encoded most significant first, with the final partial symbol padded with zeros
+	assert.Equal(t, "yy", zBase32Encode([]byte{0x00}))
+	assert.Equal(t, "6y", zBase32Encode([]byte{0xf0}))
+	assert.Equal(t, "6n9hq", zBase32Encode([]byte{0xf0, 0xbf, 0xc7}))
+
+	pubKey, _, err := ed25519.GenerateKey(rand.Reader)
+	assert.NoError(t, err)
+	decoded, err := zBase32Decode(zBase32Encode(pubKey))
+	assert.NoError(t, err)
+	assert.Equal(t, []byte(pubKey), decoded)
+}
+
+func TestDNSTXTRecords(t *testing.T) {
+	t.Run("round trips long values", func(tt *testing.T) {
+		records := []dnsTXTRecord{
+			{Name: "_did.", TTL: 7200, Values: []string{strings.Repeat("a", 600)}},
+			{Name: "_k0._did.", TTL: 60, Values: []string{"id=0", "t=0"}},
+		}
+		msg, err := encodeDNSTXTRecords(records)
+		assert.NoError(tt, err)
+
+		decoded, err := decodeDNSTXTRecords(msg)
+		assert.NoError(tt, err)
+		assert.Len(tt, decoded, 2)
+		assert.Equal(tt, "_did.", decoded[0].Name)
+		assert.Equal(tt, uint32(7200), decoded[0].TTL)
+		assert.Equal(tt, strings.Repeat("a", 600), strings.Join(decoded[0].Values, ""))
+		assert.Equal(tt, records[1], decoded[1])
+	})
+
+	t.Run("decodes compressed names", func(tt *testing.T) {
+		msg, err := encodeDNSTXTRecords([]dnsTXTRecord{{Name: "_did.", Values: []string{"a"}}})
+		assert.NoError(tt, err)
+		// append a second answer whose name is a pointer to the first answer's name
+		binary.BigEndian.PutUint16(msg[6:], 2)
+		msg = append(msg, 0xc0, 12, 0, 16, 0, 1, 0, 0, 0, 0, 0, 2, 1, 'b')
+
+		decoded, err := decodeDNSTXTRecords(msg)
+		assert.NoError(tt, err)
+		assert.Len(tt, decoded, 2)
+		assert.Equal(tt, "_did.", decoded[1].Name)
+		assert.Equal(tt, []string{"b"}, decoded[1].Values)
+	})
+
+	t.Run("rejects truncated messages", func(tt *testing.T) {
+		msg, err := encodeDNSTXTRecords([]dnsTXTRecord{{Name: "_did.", Values: []string{"abc"}}})
+		assert.NoError(tt, err)
+
+		_, err = decodeDNSTXTRecords(msg[:len(msg)-1])
+		assert.Error(tt, err)
+		_, err = decodeDNSTXTRecords(msg[:5])
+		assert.Error(tt, err)
+	})
+}
+
+func TestDHTPacket(t *testing.T) {
+	pubKey, _, err := ed25519.GenerateKey(rand.Reader)
+	assert.NoError(t, err)
+	didDHT := DHTPrefix + ":" + zBase32Encode(pubKey)
+
+	identityJWK, err := jwx.PublicKeyToPublicKeyJWK(pubKey)
+	assert.NoError(t, err)
+	secpPubKey, _, err := crypto.GenerateSECP256k1Key()
+	assert.NoError(t, err)
+	secpJWK, err := jwx.PublicKeyToPublicKeyJWK(secpPubKey.ToECDSA())
+	assert.NoError(t, err)
+
+	doc := Document{
+		Context: KnownDIDContext,
+		ID:      didDHT,
+		VerificationMethod: []VerificationMethod{
+			{ID: didDHT + "#0", Type: cryptosuite.JSONWebKey2020Type, Controller: didDHT, PublicKeyJWK: identityJWK},
+			{ID: didDHT + "#sig", Type: cryptosuite.JSONWebKey2020Type, Controller: didDHT, PublicKeyJWK: secpJWK},
+		},
+		Authentication:       []VerificationMethodSet{didDHT + "#0"},
+		AssertionMethod:      []VerificationMethodSet{didDHT + "#0", didDHT + "#sig"},
+		KeyAgreement:         []VerificationMethodSet{didDHT + "#sig"},
+		CapabilityInvocation: []VerificationMethodSet{didDHT + "#0"},
+		Services: []Service{
+			{ID: didDHT + "#dwn", Type: "DecentralizedWebNode", ServiceEndpoint: "https://example.com/dwn"},
+		},
+	}
+
+	t.Run("round trips a document", func(tt *testing.T) {
+		packet, err := doc.ToDHTPacket()
+		assert.NoError(tt, err)
+
+		records, err := decodeDNSTXTRecords(packet)
+		assert.NoError(tt, err)
+		names := make([]string, 0, len(records))
+		for _, record := range records {
+			names = append(names, record.Name)
+		}
+		assert.Equal(tt, []string{"_did." + zBase32Encode(pubKey) + ".", "_k0._did.", "_k1._did.", "_s0._did."}, names)
+		assert.Equal(tt, []string{"v=0;vm=k0,k1;auth=k0;asm=k0,k1;agm=k1;inv=k0;svc=s0"}, records[0].Values)
+
+		decoded, err := DocumentFromDHTPacket(packet)
+		assert.NoError(tt, err)
+		assert.Equal(tt, doc, *decoded)
+
+		// re-encoding the decoded document yields the same packet
+		reencoded, err := decoded.ToDHTPacket()
+		assert.NoError(tt, err)
+		assert.Equal(tt, packet, reencoded)
+	})
+
+	t.Run("rejects unknown relationship references", func(tt *testing.T) {
+		invalid := doc
+		invalid.Authentication = []VerificationMethodSet{didDHT + "#missing"}
+		_, err := invalid.ToDHTPacket()
+		assert.Error(tt, err)
+		assert.Contains(tt, err.Error(), "unknown verification method")
+	})
+
+	t.Run("rejects other DID methods", func(tt *testing.T) {
+		invalid := doc
+		invalid.ID = "did:web:example.com"
+		_, err := invalid.ToDHTPacket()
+		assert.Error(tt, err)
+	})
+}
@@ -1,5 +1,13 @@
 package did
 
+import (
+	"fmt"
+	"net/url"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
 type (
 	Method string
 )
@@ -11,6 +19,7 @@ const (
 	WebMethod  Method = "web"
 	IONMethod  Method = "ion"
 	JWKMethod  Method = "jwk"
+	DHTMethod  Method = "dht"
 )
 
 func (m Method) String() string {
@@ -28,3 +37,106 @@ type DID interface {
 	// Method provides the method for the DID
 	Method() Method
 }
+
+// ErrInvalidDID is returned when a DID or DID URL does not conform to the DID syntax
+var ErrInvalidDID = errors.New("invalid DID")
+
+// ParsedDID is a DID URL decomposed into its components
+// https://www.w3.org/TR/did-core/#did-url-syntax
+type ParsedDID struct {
+	// Method is the DID method name (e.g. key in did:key:abcd)
+	Method Method
+	// ID is the method-specific-id, which retains any percent-encoding as it is significant to the DID
+	ID string
+	// Path is the percent-decoded path of the DID URL, including its leading slash
+	Path string
+	// Query is the parsed query of the DID URL
+	Query url.Values
+	// Fragment is the percent-decoded fragment of the DID URL, without its leading '#'
+	Fragment string
+}
+
+// DID returns the DID of the parsed DID URL, without its path, query, or fragment
+func (p ParsedDID) DID() string {
+	return "did:" + p.Method.String() + ":" + p.ID
+}
+
+// ParseDID parses a DID or DID URL according to the DID syntax, returning an error wrapping ErrInvalidDID if
+// the input is malformed
+// https://www.w3.org/TR/did-core/#did-syntax
+func ParseDID(did string) (*ParsedDID, error) {
+	rest, ok := strings.CutPrefix(did, "did:")
+	if !ok {
+		return nil, fmt.Errorf("%w: missing did scheme: %s", ErrInvalidDID, did)
+	}
+
+	rest, rawFragment, hasFragment := strings.Cut(rest, "#")
+	rest, rawQuery, hasQuery := strings.Cut(rest, "?")
+	rest, rawPath, hasPath := strings.Cut(rest, "/")
+
+	method, id, ok := strings.Cut(rest, ":")
+	if !ok || method == "" {
+		return nil, fmt.Errorf("%w: missing method: %s", ErrInvalidDID, did)
+	}
+	for _, c := range method {
+		if !(c >= 'a' && c <= 'z') && !(c >= '0' && c <= '9') {
+			return nil, fmt.Errorf("%w: invalid method name<%s>: %s", ErrInvalidDID, method, did)
+		}
+	}
+	if err := validateMethodSpecificID(id); err != nil {
+		return nil, fmt.Errorf("%w: %s: %s", ErrInvalidDID, err.Error(), did)
+	}
+
+	parsed := ParsedDID{Method: Method(method), ID: id}
+	if hasPath {
+		path, err := url.PathUnescape("/" + rawPath)
+		if err != nil {
+			return nil, fmt.Errorf("%w: invalid path: %s", ErrInvalidDID, did)
+		}
+		parsed.Path = path
+	}
+	if hasQuery {
+		query, err := url.ParseQuery(rawQuery)
+		if err != nil {
+			return nil, fmt.Errorf("%w: invalid query: %s", ErrInvalidDID, did)
+		}
+		parsed.Query = query
+	}
+	if hasFragment {
+		fragment, err := url.PathUnescape(rawFragment)
+		if err != nil {
+			return nil, fmt.Errorf("%w: invalid fragment: %s", ErrInvalidDID, did)
+		}
+		parsed.Fragment = fragment
+	}
+	return &parsed, nil
+}
+
+// validateMethodSpecificID checks the id against the method-specific-id rule, which is a colon separated list of
+// idchars (ALPHA / DIGIT / "." / "-" / "_" / pct-encoded) whose last segment must not be empty
+func validateMethodSpecificID(id string) error {
+	if id == "" {
+		return errors.New("empty method-specific-id")
+	}
+	if strings.HasSuffix(id, ":") {
+		return errors.New("method-specific-id must not end with a colon")
+	}
+	for i := 0; i < len(id); i++ {
+		c := id[i]
+		switch {
+		case c >= 'a' && c <= 'z', c >= 'A' && c <= 'Z', c >= '0' && c <= '9', c == '.', c == '-', c == '_', c == ':':
+		case c == '%':
+			if i+2 >= len(id) || !isHexDigit(id[i+1]) || !isHexDigit(id[i+2]) {
+				return errors.New("invalid percent-encoding in method-specific-id")
+			}
+			i += 2
+		default:
+			return fmt.Errorf("invalid character<%q> in method-specific-id", c)
+		}
+	}
+	return nil
+}
+
+func isHexDigit(c byte) bool {
+	return (c >= '0' && c <= '9') || (c >= 'a' && c <= 'f') || (c >= 'A' && c <= 'F')
+}
@@ -18,3 +18,66 @@ func TestParseDID(t *testing.T) {
 	_, err = badDIDKey.Suffix()
 	assert.Error(t, err)
 }
+
+func TestParseDIDURL(t *testing.T) {
+	t.Run("did", func(tt *testing.T) {
+		parsed, err := ParseDID("did:example:123456789abcdefghi")
+		assert.NoError(tt, err)
+		assert.Equal(tt, Method("example"), parsed.Method)
+		assert.Equal(tt, "123456789abcdefghi", parsed.ID)
+		assert.Empty(tt, parsed.Path)
+		assert.Empty(tt, parsed.Query)
+		assert.Empty(tt, parsed.Fragment)
+		assert.Equal(tt, "did:example:123456789abcdefghi", parsed.DID())
+	})
+
+	t.Run("did url with path, query, and fragment", func(tt *testing.T) {
+		parsed, err := ParseDID("did:example:123:456/path/to%20resource?service=agent&relativeRef=/credentials#key-1")
+		assert.NoError(tt, err)
+		assert.Equal(tt, Method("example"), parsed.Method)
+		assert.Equal(tt, "123:456", parsed.ID)
+		assert.Equal(tt, "/path/to resource", parsed.Path)
+		assert.Equal(tt, "agent", parsed.Query.Get("service"))
+		assert.Equal(tt, "/credentials", parsed.Query.Get("relativeRef"))
+		assert.Equal(tt, "key-1", parsed.Fragment)
+		assert.Equal(tt, "did:example:123:456", parsed.DID())
+	})
+
+	t.Run("did url with path and fragment", func(tt *testing.T) {
+		parsed, err := ParseDID("did:web:example.com/path#key-1")
+		assert.NoError(tt, err)
+		assert.Equal(tt, WebMethod, parsed.Method)
+		assert.Equal(tt, "example.com", parsed.ID)
+		assert.Equal(tt, "/path", parsed.Path)
+		assert.Nil(tt, parsed.Query)
+		assert.Equal(tt, "key-1", parsed.Fragment)
+	})
+
+	t.Run("percent-encoded id is preserved", func(tt *testing.T) {
+		parsed, err := ParseDID("did:web:localhost%3A8443:user%2Dalice")
+		assert.NoError(tt, err)
+		assert.Equal(tt, "localhost%3A8443:user%2Dalice", parsed.ID)
+		assert.Equal(tt, "did:web:localhost%3A8443:user%2Dalice", parsed.DID())
+	})
+
+	t.Run("malformed", func(tt *testing.T) {
+		for _, did := range []string{
+			"",
+			"example:123",
+			"did:",
+			"did:example",
+			"did::123",
+			"did:Example:123",
+			"did:example:",
+			"did:example:#key-1",
+			"did:example:123:",
+			"did:example:12 3",
+			"did:example:123%2",
+			"did:example:123%zz",
+			"did:example:123/%zz",
+		} {
+			_, err := ParseDID(did)
+			assert.ErrorIs(tt, err, ErrInvalidDID, did)
+		}
+	})
+}
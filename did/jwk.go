@@ -3,14 +3,20 @@ package did
 import (
 	"context"
 	gocrypto "crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
 	"encoding/base64"
 	"fmt"
+	"math/big"
 	"strings"
 
 	"github.com/TBD54566975/ssi-sdk/crypto"
 	"github.com/TBD54566975/ssi-sdk/crypto/jwx"
 	"github.com/TBD54566975/ssi-sdk/cryptosuite"
+	"github.com/decred/dcrd/dcrec/secp256k1/v4"
 	"github.com/goccy/go-json"
+	"github.com/lestrrat-go/jwx/v2/jwa"
 	"github.com/lestrrat-go/jwx/v2/jwk"
 	"github.com/pkg/errors"
 )
@@ -23,6 +29,24 @@ const (
 	// JWKPrefix did:jwk prefix
 	JWKPrefix      = "did:jwk"
 	JWS2020Context = "https://w3id.org/security/suites/jws-2020/v1"
+	// MultikeyContext https://www.w3.org/TR/controller-document/#multikey
+	MultikeyContext = "https://w3id.org/security/multikey/v1"
+)
+
+// Bounds on the attacker controlled input of a did:jwk, to prevent denial of service when decoding or verifying with
+// its key. A non-positive bound disables the check.
+var (
+	// MaxDIDJWKLength is the maximum length, in bytes, of the method-specific id of a did:jwk
+	MaxDIDJWKLength = 64 * 1024
+	// MaxDIDJWKRSAModulusBits is the maximum size, in bits, of the modulus of an RSA did:jwk
+	MaxDIDJWKRSAModulusBits = 8192
+)
+
+var (
+	// ErrDIDTooLarge is returned when a DID exceeds MaxDIDJWKLength
+	ErrDIDTooLarge = errors.New("DID is too large")
+	// ErrKeyTooLarge is returned when the key of a DID exceeds MaxDIDJWKRSAModulusBits
+	ErrKeyTooLarge = errors.New("key is too large")
 )
 
 func (d DIDJWK) IsValid() bool {
@@ -57,7 +81,13 @@ func GenerateDIDJWK(kt crypto.KeyType) (gocrypto.PrivateKey, *DIDJWK, error) {
 	if err != nil {
 		return nil, nil, errors.Wrap(err, "generating key for did:jwk")
 	}
-	pubKeyJWK, err := jwx.PublicKeyToJWK(pubKey)
+	// X25519 keys are marked for encryption, so that their DID Documents only contain a key agreement relationship
+	var pubKeyJWK jwk.Key
+	if kt == crypto.X25519 {
+		pubKeyJWK, err = jwx.PublicKeyToJWKWithUse(pubKey, jwx.JWKUseEncryption)
+	} else {
+		pubKeyJWK, err = jwx.PublicKeyToJWK(pubKey)
+	}
 	if err != nil {
 		return nil, nil, errors.Wrap(err, "converting public key to JWK")
 	}
@@ -72,6 +102,25 @@ func GenerateDIDJWK(kt crypto.KeyType) (gocrypto.PrivateKey, *DIDJWK, error) {
 	return privKey, didJWK, nil
 }
 
+// BatchGenerateDIDJWK generates a did:jwk for each of the provided key types in sequence, returning the private keys
+// and DIDs as parallel slices. Generation stops at the first unsupported key type.
+func BatchGenerateDIDJWK(kts []crypto.KeyType) ([]gocrypto.PrivateKey, []*DIDJWK, error) {
+	privKeys := make([]gocrypto.PrivateKey, 0, len(kts))
+	didJWKs := make([]*DIDJWK, 0, len(kts))
+	for i, kt := range kts {
+		if !isSupportedJWKType(kt) {
+			return nil, nil, fmt.Errorf("unsupported did:jwk type<%s> at index %d", kt, i)
+		}
+		privKey, didJWK, err := GenerateDIDJWK(kt)
+		if err != nil {
+			return nil, nil, errors.Wrapf(err, "generating did:jwk of type<%s> at index %d", kt, i)
+		}
+		privKeys = append(privKeys, privKey)
+		didJWKs = append(didJWKs, didJWK)
+	}
+	return privKeys, didJWKs, nil
+}
+
 // CreateDIDJWK creates a did:jwk from a JWK public key by following the steps in the spec:
 // https://github.com/quartzjer/did-jwk/blob/main/spec.md
 func CreateDIDJWK(publicKeyJWK jwk.Key) (*DIDJWK, error) {
@@ -90,8 +139,58 @@ func CreateDIDJWK(publicKeyJWK jwk.Key) (*DIDJWK, error) {
 	return &didJWK, nil
 }
 
-// Expand turns the DID JWK into a compliant DID Document
-func (d DIDJWK) Expand() (*Document, error) {
+// CreateDIDJWKFromPrivateKey creates a did:jwk from a JWK private key, encoding only its public part into the DID.
+// The matching private key JWK can be recovered with ToPrivateKeyJWK.
+func CreateDIDJWKFromPrivateKey(privateKeyJWK jwk.Key) (*DIDJWK, error) {
+	if privateKeyJWK == nil {
+		return nil, errors.New("private key JWK cannot be empty")
+	}
+	publicKeyJWK, err := privateKeyJWK.PublicKey()
+	if err != nil {
+		return nil, errors.Wrap(err, "getting public key from private key JWK")
+	}
+	return CreateDIDJWK(publicKeyJWK)
+}
+
+// ToPrivateKeyJWK returns the private key JWK for the given key, after checking that its public part is the key
+// embedded in the did:jwk
+func (d DIDJWK) ToPrivateKeyJWK(privateKeyJWK jwk.Key) (*jwx.PrivateKeyJWK, error) {
+	if privateKeyJWK == nil {
+		return nil, errors.New("private key JWK cannot be empty")
+	}
+	privKeyJWK, err := jwx.JWKToPrivateKeyJWK(privateKeyJWK)
+	if err != nil {
+		return nil, errors.Wrap(err, "converting to private key JWK")
+	}
+	if privKeyJWK.D == "" {
+		return nil, errors.New("JWK does not contain private key material")
+	}
+
+	pubKeyJWK, err := d.Decode()
+	if err != nil {
+		return nil, errors.Wrap(err, "decoding did:jwk")
+	}
+	expectedThumbprint, err := pubKeyJWK.Thumbprint()
+	if err != nil {
+		return nil, errors.Wrap(err, "computing did:jwk thumbprint")
+	}
+	gotThumbprint, err := privKeyJWK.ToPublicKeyJWK().Thumbprint()
+	if err != nil {
+		return nil, errors.Wrap(err, "computing private key thumbprint")
+	}
+	if expectedThumbprint != gotThumbprint {
+		return nil, fmt.Errorf("private key does not match did:jwk: %s", d)
+	}
+	return privKeyJWK, nil
+}
+
+// Decode takes a did:jwk and returns the embedded public key JWK
+func (d DIDJWK) Decode() (*jwx.PublicKeyJWK, error) {
+	return d.decode()
+}
+
+// decode decodes the embedded JWK, additionally accepting Ed448 keys when WithEd448 is provided
+func (d DIDJWK) decode(opts ...ResolutionOption) (*jwx.PublicKeyJWK, error) {
 	id := d.String()
 
 	if !strings.HasPrefix(id, JWKPrefix) {
@@ -102,6 +201,9 @@ func (d DIDJWK) Expand() (*Document, error) {
 	if err != nil {
 		return nil, errors.Wrap(err, "reading suffix")
 	}
+	if MaxDIDJWKLength > 0 && len(encodedJWK) > MaxDIDJWKLength {
+		return nil, errors.Wrapf(ErrDIDTooLarge, "did:jwk of %d bytes exceeds the maximum of %d", len(encodedJWK), MaxDIDJWKLength)
+	}
 	decodedPubKeyJWKStr, err := base64.RawURLEncoding.DecodeString(encodedJWK)
 	if err != nil {
 		return nil, errors.Wrap(err, "decoding did:jwk")
@@ -112,20 +214,212 @@ func (d DIDJWK) Expand() (*Document, error) {
 		return nil, errors.Wrap(err, "unmarshalling did:jwk")
 	}
 
+	kt, err := keyTypeForJWK(pubKeyJWK)
+	if err != nil {
+		return nil, errors.Wrap(err, "determining key type of did:jwk")
+	}
+	if !isSupportedJWKType(kt) && !(kt == crypto.Ed448 && hasResolutionOption(opts, jwkEd448{})) {
+		return nil, fmt.Errorf("unsupported did:jwk type: %s", kt)
+	}
+	if kt == crypto.RSA && MaxDIDJWKRSAModulusBits > 0 {
+		modulus, err := base64.RawURLEncoding.DecodeString(pubKeyJWK.N)
+		if err != nil {
+			return nil, errors.Wrap(err, "decoding rsa modulus")
+		}
+		if bits := new(big.Int).SetBytes(modulus).BitLen(); bits > MaxDIDJWKRSAModulusBits {
+			return nil, errors.Wrapf(ErrKeyTooLarge, "rsa modulus of %d bits exceeds the maximum of %d", bits, MaxDIDJWKRSAModulusBits)
+		}
+	}
+	if err = checkJWKPointOnCurve(pubKeyJWK, kt); err != nil {
+		return nil, err
+	}
+	return &pubKeyJWK, nil
+}
+
+// checkJWKPointOnCurve checks that the point of a NIST curve JWK lies on its curve, since operating on an invalid
+// point panics
+func checkJWKPointOnCurve(pubKeyJWK jwx.PublicKeyJWK, kt crypto.KeyType) error {
+	var curve elliptic.Curve
+	switch kt {
+	case crypto.P256:
+		curve = elliptic.P256()
+	case crypto.P384:
+		curve = elliptic.P384()
+	case crypto.P521:
+		curve = elliptic.P521()
+	default:
+		return nil
+	}
+	x, err := base64.RawURLEncoding.DecodeString(pubKeyJWK.X)
+	if err != nil {
+		return errors.Wrap(err, "decoding x coordinate")
+	}
+	y, err := base64.RawURLEncoding.DecodeString(pubKeyJWK.Y)
+	if err != nil {
+		return errors.Wrap(err, "decoding y coordinate")
+	}
+	if !curve.IsOnCurve(new(big.Int).SetBytes(x), new(big.Int).SetBytes(y)) {
+		return fmt.Errorf("point is not on curve %s", kt)
+	}
+	return nil
+}
+
+// keyTypeForJWK maps the kty and crv members of a JWK to the key type they represent
+func keyTypeForJWK(pubKeyJWK jwx.PublicKeyJWK) (crypto.KeyType, error) {
+	switch jwa.KeyType(pubKeyJWK.KTY) {
+	case jwa.OKP:
+		switch jwa.EllipticCurveAlgorithm(pubKeyJWK.CRV) {
+		case jwa.Ed25519:
+			return crypto.Ed25519, nil
+		case jwa.X25519:
+			return crypto.X25519, nil
+		case jwa.Ed448:
+			return crypto.Ed448, nil
+		case jwx.BLS12381G2CRV:
+			return crypto.BLS12381G2, nil
+		}
+	case jwa.EC:
+		switch jwa.EllipticCurveAlgorithm(pubKeyJWK.CRV) {
+		case jwa.EllipticCurveAlgorithm(crypto.SECP256k1):
+			return crypto.SECP256k1, nil
+		case jwa.P256:
+			return crypto.P256, nil
+		case jwa.P384:
+			return crypto.P384, nil
+		case jwa.P521:
+			return crypto.P521, nil
+		}
+	case jwa.RSA:
+		if pubKeyJWK.CRV == "" {
+			return crypto.RSA, nil
+		}
+	default:
+		return "", fmt.Errorf("unsupported kty: %s", pubKeyJWK.KTY)
+	}
+	return "", fmt.Errorf("unsupported kty<%s> and crv<%s> combination", pubKeyJWK.KTY, pubKeyJWK.CRV)
+}
+
+// jwkThumbprintFragment is the ResolutionOption returned by WithJWKThumbprintFragment
+type jwkThumbprintFragment struct{}
+
+// WithJWKThumbprintFragment is a ResolutionOption which, when provided to ExpandWithOptions or JWKResolver.Resolve,
+// uses the RFC 7638 thumbprint of the embedded JWK as the verification method fragment instead of `0`.
+// https://www.rfc-editor.org/rfc/rfc7638
+func WithJWKThumbprintFragment() ResolutionOption {
+	return jwkThumbprintFragment{}
+}
+
+// jwkPublicKeyMultibase is the ResolutionOption returned by WithPublicKeyMultibase
+type jwkPublicKeyMultibase struct{}
+
+// WithPublicKeyMultibase is a ResolutionOption which, when provided to ExpandWithOptions or JWKResolver.Resolve,
+// represents the verification method as a Multikey with a publicKeyMultibase value instead of a JsonWebKey2020
+// with a publicKeyJwk value. The key is multicodec and multibase encoded following the did:key conventions.
+func WithPublicKeyMultibase() ResolutionOption {
+	return jwkPublicKeyMultibase{}
+}
+
+// jwkEd448 is the ResolutionOption returned by WithEd448
+type jwkEd448 struct{}
+
+// WithEd448 is a ResolutionOption which, when provided to ExpandWithOptions or JWKResolver.Resolve, accepts a did:jwk
+// embedding an Ed448 key. Ed448 is not among GetSupportedDIDJWKTypes as it lacks support in browsers and most JOSE
+// libraries, so it must be opted in to.
+func WithEd448() ResolutionOption {
+	return jwkEd448{}
+}
+
+// jwkDerivedKeyAgreement is the ResolutionOption returned by WithDerivedKeyAgreement
+type jwkDerivedKeyAgreement struct{}
+
+// WithDerivedKeyAgreement is a ResolutionOption which, when provided to ExpandWithOptions or JWKResolver.Resolve,
+// adds a second verification method to the DID Document of an Ed25519 did:jwk without a use restriction, carrying the
+// X25519 key derived from the Ed25519 key. The keyAgreement relationship references the derived key, while the
+// Ed25519 key keeps its signing relationships, mirroring did:key.
+func WithDerivedKeyAgreement() ResolutionOption {
+	return jwkDerivedKeyAgreement{}
+}
+
+// jwkToPublicKeyMultibase converts a public key JWK into a multicodec identified, multibase encoded public key
+func jwkToPublicKeyMultibase(pubKeyJWK jwx.PublicKeyJWK) (string, error) {
+	kt, err := keyTypeForJWK(pubKeyJWK)
+	if err != nil {
+		return "", err
+	}
+	pubKey, err := pubKeyJWK.ToPublicKey()
+	if err != nil {
+		return "", errors.Wrap(err, "converting jwk to public key")
+	}
+	// secp256k1 keys from a JWK are ecdsa keys, but did:key conventions use the compressed secp256k1 encoding
+	if ecdsaPubKey, ok := pubKey.(ecdsa.PublicKey); ok && kt == crypto.SECP256k1 {
+		var x, y secp256k1.FieldVal
+		x.SetByteSlice(ecdsaPubKey.X.Bytes())
+		y.SetByteSlice(ecdsaPubKey.Y.Bytes())
+		pubKey = *secp256k1.NewPublicKey(&x, &y)
+	}
+	return encodePublicKeyWithKeyMultiCodecType(kt, pubKey)
+}
+
+// Expand turns the DID JWK into a compliant DID Document
+func (d DIDJWK) Expand() (*Document, error) {
+	return d.ExpandWithOptions()
+}
+
+// ExpandWithOptions turns the DID JWK into a compliant DID Document, honoring any known resolution options.
+// The @context is omitted when WithAccept(DIDJSONMediaType) is given.
+func (d DIDJWK) ExpandWithOptions(opts ...ResolutionOption) (*Document, error) {
+	decoded, err := d.decode(opts...)
+	if err != nil {
+		return nil, err
+	}
+	doc, _, err := d.expand(*decoded, opts...)
+	return doc, err
+}
+
+// expand turns the DID JWK and its already decoded key into a compliant DID Document, returning any warnings
+// encountered along the way
+func (d DIDJWK) expand(pubKeyJWK jwx.PublicKeyJWK, opts ...ResolutionOption) (*Document, []string, error) {
+	id := d.String()
+	options, err := ParseResolutionOptions(opts)
+	if err != nil {
+		return nil, nil, err
+	}
+
 	keyReference := "#0"
+	if hasResolutionOption(opts, jwkThumbprintFragment{}) {
+		thumbprint, err := pubKeyJWK.Thumbprint()
+		if err != nil {
+			return nil, nil, errors.Wrap(err, "computing jwk thumbprint")
+		}
+		keyReference = "#" + thumbprint
+	}
 	keyID := id + keyReference
 
+	docContext := []string{KnownDIDContext, JWS2020Context}
+	verificationMethod := VerificationMethod{
+		ID:           keyID,
+		Type:         cryptosuite.JSONWebKey2020Type,
+		Controller:   id,
+		PublicKeyJWK: &pubKeyJWK,
+	}
+	if hasResolutionOption(opts, jwkPublicKeyMultibase{}) {
+		publicKeyMultibase, err := jwkToPublicKeyMultibase(pubKeyJWK)
+		if err != nil {
+			return nil, nil, errors.Wrap(err, "encoding public key as multibase")
+		}
+		docContext = []string{KnownDIDContext, MultikeyContext}
+		verificationMethod = VerificationMethod{
+			ID:                 keyID,
+			Type:               cryptosuite.MultikeyType,
+			Controller:         id,
+			PublicKeyMultibase: publicKeyMultibase,
+		}
+	}
+
 	doc := Document{
-		Context: []string{KnownDIDContext, JWS2020Context},
-		ID:      id,
-		VerificationMethod: []VerificationMethod{
-			{
-				ID:           keyID,
-				Type:         cryptosuite.JSONWebKey2020Type,
-				Controller:   id,
-				PublicKeyJWK: &pubKeyJWK,
-			},
-		},
+		Context:              docContext,
+		ID:                   id,
+		VerificationMethod:   []VerificationMethod{verificationMethod},
 		Authentication:       []VerificationMethodSet{keyID},
 		AssertionMethod:      []VerificationMethodSet{keyID},
 		KeyAgreement:         []VerificationMethodSet{keyID},
@@ -135,17 +429,116 @@ func (d DIDJWK) Expand() (*Document, error) {
 
 	// If the JWK contains a use property with the value "sig" then the keyAgreement property is not included in the
 	// DID Document. If the use value is "enc" then only the keyAgreement property is included in the DID Document.
-	switch pubKeyJWK.Use {
-	case "sig":
+	// When there is no use property the key_ops property is consulted in the same manner.
+	use, warnings := jwkKeyUse(pubKeyJWK)
+	switch use {
+	case jwx.JWKUseSignature:
 		doc.KeyAgreement = nil
-	case "enc":
+	case jwx.JWKUseEncryption:
 		doc.Authentication = nil
 		doc.AssertionMethod = nil
 		doc.CapabilityInvocation = nil
 		doc.CapabilityDelegation = nil
+	case "":
+		if kt, _ := keyTypeForJWK(pubKeyJWK); kt == crypto.Ed25519 && hasResolutionOption(opts, jwkDerivedKeyAgreement{}) {
+			keyAgreementMethod, err := derivedKeyAgreementMethod(id, pubKeyJWK, opts...)
+			if err != nil {
+				return nil, nil, errors.Wrap(err, "deriving key agreement method")
+			}
+			doc.VerificationMethod = append(doc.VerificationMethod, *keyAgreementMethod)
+			doc.KeyAgreement = []VerificationMethodSet{keyAgreementMethod.ID}
+		}
 	}
 
-	return &doc, nil
+	// the plain JSON representation does not carry a JSON-LD context
+	if !options.IncludeContext() {
+		doc.Context = nil
+	}
+	return &doc, warnings, nil
+}
+
+// derivedKeyAgreementMethod derives an X25519 key from the Ed25519 key of a did:jwk, and returns a verification method
+// for it represented in the same manner as the Ed25519 key
+func derivedKeyAgreementMethod(id string, pubKeyJWK jwx.PublicKeyJWK, opts ...ResolutionOption) (*VerificationMethod, error) {
+	pubKey, err := pubKeyJWK.ToPublicKey()
+	if err != nil {
+		return nil, errors.Wrap(err, "converting jwk to public key")
+	}
+	ed25519PubKey, ok := pubKey.(ed25519.PublicKey)
+	if !ok {
+		return nil, fmt.Errorf("expected ed25519 public key, got %T", pubKey)
+	}
+	x25519PubKey, err := crypto.Ed25519PublicKeyToX25519(ed25519PubKey)
+	if err != nil {
+		return nil, errors.Wrap(err, "converting ed25519 key to x25519")
+	}
+	x25519JWK, err := jwx.PublicKeyToJWKWithUse(x25519PubKey, jwx.JWKUseEncryption)
+	if err != nil {
+		return nil, errors.Wrap(err, "converting x25519 key to JWK")
+	}
+	x25519PubKeyJWK, err := jwx.JWKToPublicKeyJWK(x25519JWK)
+	if err != nil {
+		return nil, errors.Wrap(err, "converting x25519 JWK")
+	}
+
+	keyReference := "#1"
+	if hasResolutionOption(opts, jwkThumbprintFragment{}) {
+		thumbprint, err := x25519PubKeyJWK.Thumbprint()
+		if err != nil {
+			return nil, errors.Wrap(err, "computing x25519 jwk thumbprint")
+		}
+		keyReference = "#" + thumbprint
+	}
+	if hasResolutionOption(opts, jwkPublicKeyMultibase{}) {
+		publicKeyMultibase, err := encodePublicKeyWithKeyMultiCodecType(crypto.X25519, x25519PubKey)
+		if err != nil {
+			return nil, errors.Wrap(err, "encoding x25519 key as multibase")
+		}
+		return &VerificationMethod{
+			ID:                 id + keyReference,
+			Type:               cryptosuite.MultikeyType,
+			Controller:         id,
+			PublicKeyMultibase: publicKeyMultibase,
+		}, nil
+	}
+	return &VerificationMethod{
+		ID:           id + keyReference,
+		Type:         cryptosuite.JSONWebKey2020Type,
+		Controller:   id,
+		PublicKeyJWK: x25519PubKeyJWK,
+	}, nil
+}
+
+// jwkKeyUse determines whether a JWK is intended for signatures or encryption from its use and key_ops members,
+// returning an empty use when the key is unrestricted. The use member takes precedence over key_ops, in which case
+// a warning is returned if the two contradict each other.
+func jwkKeyUse(pubKeyJWK jwx.PublicKeyJWK) (string, []string) {
+	var sigOps, encOps bool
+	for _, op := range pubKeyJWK.KeyOps {
+		switch jwk.KeyOperation(op) {
+		case jwk.KeyOpSign, jwk.KeyOpVerify:
+			sigOps = true
+		case jwk.KeyOpDeriveKey, jwk.KeyOpDeriveBits:
+			encOps = true
+		}
+	}
+
+	var keyOpsUse string
+	switch {
+	case sigOps && !encOps:
+		keyOpsUse = jwx.JWKUseSignature
+	case encOps && !sigOps:
+		keyOpsUse = jwx.JWKUseEncryption
+	}
+
+	if pubKeyJWK.Use == "" {
+		return keyOpsUse, nil
+	}
+	if keyOpsUse != "" && keyOpsUse != pubKeyJWK.Use {
+		warning := fmt.Sprintf("jwk use<%s> contradicts key_ops<%s>, using use", pubKeyJWK.Use, strings.Join(pubKeyJWK.KeyOps, ","))
+		return pubKeyJWK.Use, []string{warning}
+	}
+	return pubKeyJWK.Use, nil
 }
 
 func isSupportedJWKType(kt crypto.KeyType) bool {
@@ -166,13 +559,31 @@ type JWKResolver struct{}
 
 var _ Resolver = (*JWKResolver)(nil)
 
-func (JWKResolver) Resolve(_ context.Context, did string, _ ...ResolutionOption) (*ResolutionResult, error) {
+// Resolve resolves a did:jwk DID. Failures are returned as a *ResolutionError.
+func (JWKResolver) Resolve(_ context.Context, did string, opts ...ResolutionOption) (*ResolutionResult, error) {
+	options, err := ParseResolutionOptions(opts)
+	if err != nil {
+		return nil, NewResolutionError(RepresentationNotSupportedErrorCode, did, err)
+	}
+
 	didJWK := DIDJWK(did)
-	doc, err := didJWK.Expand()
+	decoded, err := didJWK.decode(opts...)
+	if err != nil {
+		return nil, NewResolutionError(InvalidDIDErrorCode, did, errors.Wrap(err, "decoding did:jwk"))
+	}
+	doc, warnings, err := didJWK.expand(*decoded, opts...)
 	if err != nil {
-		return nil, errors.Wrap(err, "expanding did:jwk")
+		return nil, NewResolutionError(InternalErrorCode, did, errors.Wrap(err, "expanding did:jwk"))
 	}
-	return &ResolutionResult{Document: *doc}, nil
+	return &ResolutionResult{
+		ResolutionMetadata: ResolutionMetadata{
+			ContentType: options.Accept,
+			Warnings:    warnings,
+		},
+		Document: *doc,
+		// a did:jwk DID is its key, and cannot be deactivated
+		DocumentMetadata: DocumentMetadata{},
+	}, nil
 }
 
 func (JWKResolver) Methods() []Method {
@@ -0,0 +1,27 @@
+package did
+
+import (
+	"context"
+	"testing"
+)
+
+func FuzzDIDJWKExpand(f *testing.F) {
+	for _, kt := range GetSupportedDIDJWKTypes() {
+		// secp256k1 keys require the jwx_es256k build tag, which fuzzing is run without
+		_, didJWK, err := GenerateDIDJWK(kt)
+		if err != nil {
+			continue
+		}
+		f.Add(didJWK.String())
+	}
+	f.Add("did:jwk:")
+	f.Add("did:jwk:e30")
+	f.Add("did:jwk:eyJrdHkiOiJSU0EiLCJuIjoiIiwiZSI6IiJ9")
+
+	f.Fuzz(func(t *testing.T, d string) {
+		// the result does not matter, only that malformed input is rejected without panicking or hanging
+		_, _ = DIDJWK(d).Expand()
+		_, _ = DIDJWK(d).ExpandWithOptions(WithJWKThumbprintFragment(), WithPublicKeyMultibase(), WithDerivedKeyAgreement())
+		_, _ = JWKResolver{}.Resolve(context.Background(), d)
+	})
+}
@@ -2,15 +2,20 @@ package did
 
 import (
 	"context"
+	gocrypto "crypto"
 	"embed"
+	"encoding/base64"
 	"strings"
 	"testing"
 
 	"github.com/TBD54566975/ssi-sdk/crypto"
+	"github.com/TBD54566975/ssi-sdk/crypto/jwx"
 	"github.com/TBD54566975/ssi-sdk/cryptosuite"
+	"github.com/cloudflare/circl/sign/ed448"
 	"github.com/goccy/go-json"
 	"github.com/lestrrat-go/jwx/v2/jwk"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 const (
@@ -193,3 +198,742 @@ func TestGenerateAndResolveDIDJWK(t *testing.T) {
 		assert.Equal(t, didJWK.String(), doc.Document.ID)
 	}
 }
+
+func TestSignVerifyDIDJWKTypes(t *testing.T) {
+	payload := []byte("hello world")
+	for _, kt := range GetSupportedDIDJWKTypes() {
+		t.Run(string(kt), func(tt *testing.T) {
+			privKey, didJWK, err := GenerateDIDJWK(kt)
+			require.NoError(tt, err)
+			pubKeyJWK, err := didJWK.Decode()
+			require.NoError(tt, err)
+			pubKey, err := pubKeyJWK.ToPublicKey()
+			require.NoError(tt, err)
+
+			sig, err := crypto.Sign(kt, privKey, payload)
+			if kt == crypto.X25519 {
+				// X25519 is a key agreement key and cannot sign
+				assert.Error(tt, err)
+				assert.Error(tt, crypto.Verify(kt, pubKey, payload, sig))
+				return
+			}
+			assert.NoError(tt, err)
+			assert.NoError(tt, crypto.Verify(kt, pubKey, payload, sig))
+			assert.Error(tt, crypto.Verify(kt, pubKey, []byte("goodbye world"), sig))
+		})
+	}
+}
+
+func TestGenerateDIDJWKKeyUse(t *testing.T) {
+	t.Run("X25519 is key agreement only", func(tt *testing.T) {
+		_, didJWK, err := GenerateDIDJWK(crypto.X25519)
+		require.NoError(tt, err)
+
+		decoded, err := didJWK.Decode()
+		require.NoError(tt, err)
+		assert.Equal(tt, jwx.JWKUseEncryption, decoded.Use)
+		assert.Equal(tt, "X25519", decoded.CRV)
+
+		doc, err := didJWK.Expand()
+		require.NoError(tt, err)
+		assert.NoError(tt, doc.IsValid())
+		assert.Len(tt, doc.KeyAgreement, 1)
+		assert.Empty(tt, doc.Authentication)
+		assert.Empty(tt, doc.AssertionMethod)
+		assert.Empty(tt, doc.CapabilityInvocation)
+		assert.Empty(tt, doc.CapabilityDelegation)
+	})
+
+	t.Run("other key types are unrestricted", func(tt *testing.T) {
+		_, didJWK, err := GenerateDIDJWK(crypto.P256)
+		require.NoError(tt, err)
+
+		decoded, err := didJWK.Decode()
+		require.NoError(tt, err)
+		assert.Empty(tt, decoded.Use)
+
+		doc, err := didJWK.Expand()
+		require.NoError(tt, err)
+		assert.Len(tt, doc.KeyAgreement, 1)
+		assert.Len(tt, doc.Authentication, 1)
+	})
+}
+
+func TestPEMDIDJWKTypes(t *testing.T) {
+	for _, kt := range GetSupportedDIDJWKTypes() {
+		t.Run(string(kt), func(tt *testing.T) {
+			pubKey, privKey, err := crypto.GenerateKeyByKeyType(kt)
+			require.NoError(tt, err)
+			pubKeyJWK, err := jwx.PublicKeyToJWK(pubKey)
+			require.NoError(tt, err)
+			didJWK, err := CreateDIDJWK(pubKeyJWK)
+			require.NoError(tt, err)
+
+			privKeyPEM, err := crypto.PrivateKeyToPEM(kt, privKey)
+			assert.NoError(tt, err)
+			gotKT, gotPrivKey, err := crypto.PrivateKeyFromPEM(privKeyPEM)
+			assert.NoError(tt, err)
+			assert.Equal(tt, kt, gotKT)
+
+			// the imported key maps back to the same did:jwk
+			privKeyJWK, err := jwx.PrivateKeyToJWK(gotPrivKey)
+			assert.NoError(tt, err)
+			gotPubKeyJWK, err := privKeyJWK.PublicKey()
+			assert.NoError(tt, err)
+			gotDIDJWK, err := CreateDIDJWK(gotPubKeyJWK)
+			assert.NoError(tt, err)
+			assert.Equal(tt, didJWK.String(), gotDIDJWK.String())
+
+			pubKeyPEM, err := crypto.PublicKeyToPEM(kt, pubKey)
+			assert.NoError(tt, err)
+			gotKT, gotPubKey, err := crypto.PublicKeyFromPEM(pubKeyPEM)
+			assert.NoError(tt, err)
+			assert.Equal(tt, kt, gotKT)
+			assert.Equal(tt, pubKey, gotPubKey)
+		})
+	}
+}
+
+func TestExpandDIDJWKWithThumbprintFragment(t *testing.T) {
+	t.Run("fragment matches jwk thumbprint", func(t *testing.T) {
+		for _, kt := range []crypto.KeyType{crypto.Ed25519, crypto.P256, crypto.RSA} {
+			pk, _, err := crypto.GenerateKeyByKeyType(kt)
+			assert.NoError(t, err)
+
+			gotJWK, err := jwx.PublicKeyToJWK(pk)
+			assert.NoError(t, err)
+
+			didJWK, err := CreateDIDJWK(gotJWK)
+			assert.NoError(t, err)
+
+			doc, err := didJWK.ExpandWithOptions(WithJWKThumbprintFragment())
+			assert.NoError(t, err)
+			assert.NoError(t, doc.IsValid())
+
+			thumbprint, err := gotJWK.Thumbprint(gocrypto.SHA256)
+			assert.NoError(t, err)
+			expectedID := didJWK.String() + "#" + base64.RawURLEncoding.EncodeToString(thumbprint)
+
+			assert.Len(t, doc.VerificationMethod, 1)
+			assert.Equal(t, expectedID, doc.VerificationMethod[0].ID)
+			assert.Equal(t, didJWK.String(), doc.VerificationMethod[0].Controller)
+			assert.Equal(t, []VerificationMethodSet{expectedID}, doc.Authentication)
+		}
+	})
+
+	t.Run("default fragment is 0", func(t *testing.T) {
+		_, didJWK, err := GenerateDIDJWK(crypto.Ed25519)
+		assert.NoError(t, err)
+
+		doc, err := didJWK.Expand()
+		assert.NoError(t, err)
+		assert.Equal(t, didJWK.String()+"#0", doc.VerificationMethod[0].ID)
+	})
+
+	t.Run("resolver honors option", func(t *testing.T) {
+		_, didJWK, err := GenerateDIDJWK(crypto.P256)
+		assert.NoError(t, err)
+
+		resolver, err := NewResolver(JWKResolver{})
+		assert.NoError(t, err)
+		resolved, err := resolver.Resolve(context.Background(), didJWK.String(), WithJWKThumbprintFragment())
+		assert.NoError(t, err)
+		assert.NotEqual(t, didJWK.String()+"#0", resolved.Document.VerificationMethod[0].ID)
+	})
+}
+
+func TestDecodeDIDJWK(t *testing.T) {
+	t.Run("happy path", func(t *testing.T) {
+		pk, _, err := crypto.GenerateEd25519Key()
+		assert.NoError(t, err)
+
+		gotJWK, err := jwx.PublicKeyToJWK(pk)
+		assert.NoError(t, err)
+		expectedJWK, err := jwx.JWKToPublicKeyJWK(gotJWK)
+		assert.NoError(t, err)
+
+		didJWK, err := CreateDIDJWK(gotJWK)
+		assert.NoError(t, err)
+
+		decoded, err := didJWK.Decode()
+		assert.NoError(t, err)
+		assert.Equal(t, expectedJWK, decoded)
+
+		doc, err := didJWK.Expand()
+		assert.NoError(t, err)
+		assert.Equal(t, decoded, doc.VerificationMethod[0].PublicKeyJWK)
+	})
+
+	t.Run("bad prefix", func(t *testing.T) {
+		_, err := DIDJWK("did:key:abcd").Decode()
+		assert.Error(t, err)
+		assert.Contains(t, err.Error(), "not a did:jwk DID, invalid prefix")
+	})
+
+	t.Run("malformed base64", func(t *testing.T) {
+		_, err := DIDJWK("did:jwk:not+base64url!").Decode()
+		assert.Error(t, err)
+		assert.Contains(t, err.Error(), "decoding did:jwk")
+	})
+
+	t.Run("malformed json", func(t *testing.T) {
+		encoded := base64.RawURLEncoding.EncodeToString([]byte(`{"kty":`))
+		_, err := DIDJWK("did:jwk:" + encoded).Decode()
+		assert.Error(t, err)
+		assert.Contains(t, err.Error(), "unmarshalling did:jwk")
+	})
+}
+
+func TestDIDJWKUnsupportedKeyTypes(t *testing.T) {
+	tests := []struct {
+		name string
+		jwk  string
+	}{
+		{
+			name: "OKP with secp256k1",
+			jwk:  `{"kty":"OKP","crv":"secp256k1","x":"3p7bfXt9wbTTW2HC7OQ1Nz-DQ8hbeGdNrfx-FG-IK08"}`,
+		},
+		{
+			name: "EC with Ed25519",
+			jwk:  `{"kty":"EC","crv":"Ed25519","x":"3p7bfXt9wbTTW2HC7OQ1Nz-DQ8hbeGdNrfx-FG-IK08"}`,
+		},
+		{
+			name: "RSA with curve",
+			jwk:  `{"kty":"RSA","crv":"P-256","n":"0vx7agoebGcQSuuPiLJXZptN9nndrQmbXEps2aiAFbWhM78LhWx4cbbfAAtVT86zwu1RK7aPFFxuhDR1L6tSoc_BJECPebWKRXjBZCiFV4n3oknjhMstn64tZ_2W-5JsGY4Hc5n9yBXArwl93lqt7_RN5w6Cf0h4QyQ5v-65YGjQR0_FDW2QvzqY368QQMicAtaSqzs8KJZgnYb9c7d0zgdAZHzu6qMQvRL5hajrn1n91CbOpbISD08qNLyrdkt-bFTWhAI4vMQFh6WeZu0fM4lFd2NcRwr3XPksINHaQ-G_xBniIqbw0Ls1jF44-csFCur-kEgU8awapJzKnqDKgw","e":"AQAB"}`,
+		},
+		{
+			name: "unknown kty",
+			jwk:  `{"kty":"oct","k":"AyM1SysPpbyDfgZld3umj1qzKObwVMkoqQ-EstJQLr_T-1qS0gZH75aKtMN3Yj0iPS4hcgUuTwjAzZr1Z9CAow"}`,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			didJWK := DIDJWK(JWKPrefix + ":" + base64.RawURLEncoding.EncodeToString([]byte(test.jwk)))
+			assert.False(t, didJWK.IsValid())
+
+			_, err := didJWK.Decode()
+			assert.Error(t, err)
+			assert.Contains(t, err.Error(), "determining key type of did:jwk")
+
+			doc, err := didJWK.Expand()
+			assert.Error(t, err)
+			assert.Nil(t, doc)
+		})
+	}
+}
+
+func TestDIDJWKBLS12381G2(t *testing.T) {
+	// BBS+ keys can be embedded in a did:jwk, but are not supported since they cannot be used with JOSE
+	assert.False(t, isSupportedJWKType(crypto.BLS12381G2))
+	_, _, err := GenerateDIDJWK(crypto.BLS12381G2)
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "unsupported did:jwk type: BLS12381G2")
+
+	pubKey, _, err := crypto.GenerateBLS12381G2Key()
+	assert.NoError(t, err)
+	pubKeyJWK, err := jwx.PublicKeyToJWK(pubKey)
+	assert.NoError(t, err)
+	didJWK, err := CreateDIDJWK(pubKeyJWK)
+	assert.NoError(t, err)
+
+	_, err = didJWK.Decode()
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "unsupported did:jwk type: BLS12381G2")
+}
+
+func TestDIDJWKEd448(t *testing.T) {
+	assert.False(t, isSupportedJWKType(crypto.Ed448))
+	_, _, err := GenerateDIDJWK(crypto.Ed448)
+	assert.Error(t, err)
+
+	pubKey, privKey, err := crypto.GenerateEd448Key()
+	require.NoError(t, err)
+	pubKeyJWK, err := jwx.PublicKeyToJWK(pubKey)
+	require.NoError(t, err)
+	didJWK, err := CreateDIDJWK(pubKeyJWK)
+	require.NoError(t, err)
+
+	t.Run("rejected by default", func(tt *testing.T) {
+		_, err := didJWK.Expand()
+		assert.Error(tt, err)
+		assert.Contains(tt, err.Error(), "unsupported did:jwk type: Ed448")
+	})
+
+	t.Run("accepted when opted in", func(tt *testing.T) {
+		resolved, err := JWKResolver{}.Resolve(context.Background(), didJWK.String(), WithEd448())
+		require.NoError(tt, err)
+		assert.Equal(tt, "Ed448", resolved.Document.VerificationMethod[0].PublicKeyJWK.CRV)
+
+		// the resolved key verifies signatures made with the private key
+		message := []byte("hello world")
+		sig, err := crypto.SignEd448(privKey, message)
+		require.NoError(tt, err)
+		resolvedKey, err := GetKeyFromVerificationMethod(resolved.Document, resolved.Document.VerificationMethod[0].ID)
+		require.NoError(tt, err)
+		assert.NoError(tt, crypto.VerifyEd448(resolvedKey.(ed448.PublicKey), message, sig))
+	})
+}
+
+func TestCreateDIDJWKFromPrivateKey(t *testing.T) {
+	t.Run("round trip", func(t *testing.T) {
+		for _, kt := range []crypto.KeyType{crypto.Ed25519, crypto.X25519, crypto.P256, crypto.P384, crypto.RSA} {
+			pubKey, privKey, err := crypto.GenerateKeyByKeyType(kt)
+			assert.NoError(t, err)
+
+			privKeyJWK, err := jwx.PrivateKeyToJWK(privKey)
+			assert.NoError(t, err)
+
+			didJWK, err := CreateDIDJWKFromPrivateKey(privKeyJWK)
+			assert.NoError(t, err)
+			assert.True(t, didJWK.IsValid())
+
+			// the DID must be identical to one created from the public key alone
+			pubKeyJWK, err := jwx.PublicKeyToJWK(pubKey)
+			assert.NoError(t, err)
+			expectedDID, err := CreateDIDJWK(pubKeyJWK)
+			assert.NoError(t, err)
+			assert.Equal(t, *expectedDID, *didJWK)
+
+			// and must not leak the private key
+			decoded, err := didJWK.Decode()
+			assert.NoError(t, err)
+			assert.NotContains(t, didJWK.String(), `"d"`)
+			decodedBytes, err := json.Marshal(decoded)
+			assert.NoError(t, err)
+			assert.NotContains(t, string(decodedBytes), `"d"`)
+
+			recovered, err := didJWK.ToPrivateKeyJWK(privKeyJWK)
+			assert.NoError(t, err)
+			assert.NotEmpty(t, recovered.D)
+
+			expectedPrivKeyJWK, err := jwx.JWKToPrivateKeyJWK(privKeyJWK)
+			assert.NoError(t, err)
+			assert.Equal(t, expectedPrivKeyJWK, recovered)
+		}
+	})
+
+	t.Run("nil key", func(t *testing.T) {
+		_, err := CreateDIDJWKFromPrivateKey(nil)
+		assert.Error(t, err)
+	})
+
+	t.Run("mismatched private key", func(t *testing.T) {
+		_, didJWK, err := GenerateDIDJWK(crypto.Ed25519)
+		assert.NoError(t, err)
+
+		_, otherPrivKey, err := crypto.GenerateEd25519Key()
+		assert.NoError(t, err)
+		otherPrivKeyJWK, err := jwx.PrivateKeyToJWK(otherPrivKey)
+		assert.NoError(t, err)
+
+		_, err = didJWK.ToPrivateKeyJWK(otherPrivKeyJWK)
+		assert.Error(t, err)
+		assert.Contains(t, err.Error(), "private key does not match did:jwk")
+	})
+
+	t.Run("public key only", func(t *testing.T) {
+		pubKey, _, err := crypto.GenerateEd25519Key()
+		assert.NoError(t, err)
+		pubKeyJWK, err := jwx.PublicKeyToJWK(pubKey)
+		assert.NoError(t, err)
+		didJWK, err := CreateDIDJWK(pubKeyJWK)
+		assert.NoError(t, err)
+
+		_, err = didJWK.ToPrivateKeyJWK(pubKeyJWK)
+		assert.Error(t, err)
+		assert.Contains(t, err.Error(), "does not contain private key material")
+	})
+}
+
+func TestExpandDIDJWKKeyOps(t *testing.T) {
+	pk, _, err := crypto.GenerateEd25519Key()
+	assert.NoError(t, err)
+	basePubKeyJWK, err := jwx.PublicKeyToPublicKeyJWK(pk)
+	assert.NoError(t, err)
+
+	toDIDJWK := func(t *testing.T, pubKeyJWK jwx.PublicKeyJWK) DIDJWK {
+		gotJWK, err := jwx.JWKFromPublicKeyJWK(pubKeyJWK)
+		assert.NoError(t, err)
+		didJWK, err := CreateDIDJWK(gotJWK)
+		assert.NoError(t, err)
+		return *didJWK
+	}
+
+	t.Run("sign and verify key_ops exclude keyAgreement", func(t *testing.T) {
+		pubKeyJWK := *basePubKeyJWK
+		pubKeyJWK.KeyOps = []string{"sign", "verify"}
+		doc, err := toDIDJWK(t, pubKeyJWK).Expand()
+		assert.NoError(t, err)
+		assert.Empty(t, doc.KeyAgreement)
+		assert.NotEmpty(t, doc.Authentication)
+		assert.NotEmpty(t, doc.AssertionMethod)
+		assert.NotEmpty(t, doc.CapabilityInvocation)
+		assert.NotEmpty(t, doc.CapabilityDelegation)
+	})
+
+	t.Run("deriveKey and deriveBits key_ops only include keyAgreement", func(t *testing.T) {
+		pubKeyJWK := *basePubKeyJWK
+		pubKeyJWK.KeyOps = []string{"deriveKey", "deriveBits"}
+		doc, err := toDIDJWK(t, pubKeyJWK).Expand()
+		assert.NoError(t, err)
+		assert.NotEmpty(t, doc.KeyAgreement)
+		assert.Empty(t, doc.Authentication)
+		assert.Empty(t, doc.AssertionMethod)
+		assert.Empty(t, doc.CapabilityInvocation)
+		assert.Empty(t, doc.CapabilityDelegation)
+	})
+
+	t.Run("mixed key_ops include all relationships", func(t *testing.T) {
+		pubKeyJWK := *basePubKeyJWK
+		pubKeyJWK.KeyOps = []string{"verify", "deriveKey"}
+		doc, err := toDIDJWK(t, pubKeyJWK).Expand()
+		assert.NoError(t, err)
+		assert.NotEmpty(t, doc.KeyAgreement)
+		assert.NotEmpty(t, doc.Authentication)
+	})
+
+	t.Run("use takes precedence over contradicting key_ops with a warning", func(t *testing.T) {
+		pubKeyJWK := *basePubKeyJWK
+		pubKeyJWK.Use = "sig"
+		pubKeyJWK.KeyOps = []string{"deriveKey"}
+		didJWK := toDIDJWK(t, pubKeyJWK)
+
+		resolved, err := JWKResolver{}.Resolve(context.Background(), didJWK.String())
+		assert.NoError(t, err)
+		assert.Empty(t, resolved.Document.KeyAgreement)
+		assert.NotEmpty(t, resolved.Document.Authentication)
+		assert.Len(t, resolved.ResolutionMetadata.Warnings, 1)
+		assert.Contains(t, resolved.ResolutionMetadata.Warnings[0], "contradicts key_ops")
+	})
+
+	t.Run("consistent use and key_ops have no warning", func(t *testing.T) {
+		pubKeyJWK := *basePubKeyJWK
+		pubKeyJWK.Use = "sig"
+		pubKeyJWK.KeyOps = []string{"sign"}
+		didJWK := toDIDJWK(t, pubKeyJWK)
+
+		resolved, err := JWKResolver{}.Resolve(context.Background(), didJWK.String())
+		assert.NoError(t, err)
+		assert.Empty(t, resolved.Document.KeyAgreement)
+		assert.Empty(t, resolved.ResolutionMetadata.Warnings)
+	})
+}
+
+func TestBatchGenerateDIDJWK(t *testing.T) {
+	t.Run("mixed key types", func(t *testing.T) {
+		kts := []crypto.KeyType{crypto.Ed25519, crypto.P256, crypto.RSA, crypto.Ed25519}
+		privKeys, didJWKs, err := BatchGenerateDIDJWK(kts)
+		assert.NoError(t, err)
+		assert.Len(t, privKeys, len(kts))
+		assert.Len(t, didJWKs, len(kts))
+
+		for i, kt := range kts {
+			gotKT, err := crypto.GetKeyTypeFromPrivateKey(privKeys[i])
+			assert.NoError(t, err)
+			assert.Equal(t, kt, gotKT)
+
+			// the private key must correspond to the DID at the same index
+			privKeyJWK, err := jwx.PrivateKeyToJWK(privKeys[i])
+			assert.NoError(t, err)
+			_, err = didJWKs[i].ToPrivateKeyJWK(privKeyJWK)
+			assert.NoError(t, err)
+		}
+
+		// identities are distinct
+		assert.NotEqual(t, *didJWKs[0], *didJWKs[3])
+	})
+
+	t.Run("empty input", func(t *testing.T) {
+		privKeys, didJWKs, err := BatchGenerateDIDJWK(nil)
+		assert.NoError(t, err)
+		assert.Empty(t, privKeys)
+		assert.Empty(t, didJWKs)
+
+		privKeys, didJWKs, err = BatchGenerateDIDJWK([]crypto.KeyType{})
+		assert.NoError(t, err)
+		assert.Empty(t, privKeys)
+		assert.Empty(t, didJWKs)
+	})
+
+	t.Run("unsupported key type", func(t *testing.T) {
+		privKeys, didJWKs, err := BatchGenerateDIDJWK([]crypto.KeyType{crypto.Ed25519, crypto.P224, crypto.RSA})
+		assert.Error(t, err)
+		assert.Contains(t, err.Error(), "unsupported did:jwk type<P-224> at index 1")
+		assert.Nil(t, privKeys)
+		assert.Nil(t, didJWKs)
+	})
+}
+
+func TestExpandDIDJWKWithPublicKeyMultibase(t *testing.T) {
+	t.Run("multibase matches did:key encoding", func(t *testing.T) {
+		for _, kt := range GetSupportedDIDJWKTypes() {
+			pubKey, _, err := crypto.GenerateKeyByKeyType(kt)
+			assert.NoError(t, err)
+
+			gotJWK, err := jwx.PublicKeyToJWK(pubKey)
+			assert.NoError(t, err)
+			didJWK, err := CreateDIDJWK(gotJWK)
+			assert.NoError(t, err)
+
+			doc, err := didJWK.ExpandWithOptions(WithPublicKeyMultibase())
+			assert.NoError(t, err)
+			assert.NoError(t, doc.IsValid())
+			assert.Equal(t, []string{KnownDIDContext, MultikeyContext}, doc.Context)
+
+			assert.Len(t, doc.VerificationMethod, 1)
+			vm := doc.VerificationMethod[0]
+			assert.Equal(t, cryptosuite.MultikeyType, vm.Type)
+			assert.Nil(t, vm.PublicKeyJWK)
+			assert.NotEmpty(t, vm.PublicKeyMultibase)
+			assert.Equal(t, didJWK.String()+"#0", vm.ID)
+
+			// the key type is taken from the JWK, since X25519 keys are currently converted to Ed25519 JWKs
+			decoded, err := didJWK.Decode()
+			assert.NoError(t, err)
+			jwkKT, err := keyTypeForJWK(*decoded)
+			assert.NoError(t, err)
+
+			pubKeyBytes, err := crypto.PubKeyToBytes(pubKey)
+			assert.NoError(t, err)
+			didKey, err := CreateDIDKey(jwkKT, pubKeyBytes)
+			assert.NoError(t, err)
+			suffix, err := didKey.Suffix()
+			assert.NoError(t, err)
+			assert.Equal(t, suffix, vm.PublicKeyMultibase, "key type: %s", kt)
+		}
+	})
+
+	t.Run("default is publicKeyJwk", func(t *testing.T) {
+		_, didJWK, err := GenerateDIDJWK(crypto.P256)
+		assert.NoError(t, err)
+
+		doc, err := didJWK.Expand()
+		assert.NoError(t, err)
+		assert.Equal(t, cryptosuite.JSONWebKey2020Type, doc.VerificationMethod[0].Type)
+		assert.NotNil(t, doc.VerificationMethod[0].PublicKeyJWK)
+		assert.Empty(t, doc.VerificationMethod[0].PublicKeyMultibase)
+	})
+
+	t.Run("resolver honors option", func(t *testing.T) {
+		_, didJWK, err := GenerateDIDJWK(crypto.Ed25519)
+		assert.NoError(t, err)
+
+		resolved, err := JWKResolver{}.Resolve(context.Background(), didJWK.String(), WithPublicKeyMultibase())
+		assert.NoError(t, err)
+		assert.Equal(t, cryptosuite.MultikeyType, resolved.Document.VerificationMethod[0].Type)
+	})
+}
+
+func TestJWKResolverMetadata(t *testing.T) {
+	_, didJWK, err := GenerateDIDJWK(crypto.Ed25519)
+	assert.NoError(t, err)
+
+	t.Run("default content type is did+ld+json", func(t *testing.T) {
+		resolved, err := JWKResolver{}.Resolve(context.Background(), didJWK.String())
+		assert.NoError(t, err)
+		assert.Equal(t, DIDJSONLDMediaType, resolved.ResolutionMetadata.ContentType)
+		assert.Nil(t, resolved.ResolutionMetadata.Error)
+		assert.Empty(t, resolved.DocumentMetadata)
+		assert.Equal(t, []string{KnownDIDContext, JWS2020Context}, resolved.Document.Context)
+	})
+
+	t.Run("accept did+ld+json", func(t *testing.T) {
+		resolved, err := JWKResolver{}.Resolve(context.Background(), didJWK.String(), WithAccept(DIDJSONLDMediaType))
+		assert.NoError(t, err)
+		assert.Equal(t, DIDJSONLDMediaType, resolved.ResolutionMetadata.ContentType)
+		assert.NotEmpty(t, resolved.Document.Context)
+
+		resolvedBytes, err := json.Marshal(resolved)
+		assert.NoError(t, err)
+		assert.Contains(t, string(resolvedBytes), `"didResolutionMetadata":{"contentType":"application/did+ld+json"}`)
+		assert.Contains(t, string(resolvedBytes), `"@context"`)
+	})
+
+	t.Run("accept did+json drops the context", func(t *testing.T) {
+		resolved, err := JWKResolver{}.Resolve(context.Background(), didJWK.String(), WithAccept(DIDJSONMediaType))
+		assert.NoError(t, err)
+		assert.Equal(t, DIDJSONMediaType, resolved.ResolutionMetadata.ContentType)
+		assert.Nil(t, resolved.Document.Context)
+		assert.Equal(t, didJWK.String(), resolved.Document.ID)
+
+		resolvedBytes, err := json.Marshal(resolved)
+		assert.NoError(t, err)
+		assert.Contains(t, string(resolvedBytes), `"didResolutionMetadata":{"contentType":"application/did+json"}`)
+		assert.NotContains(t, string(resolvedBytes), `"@context"`)
+	})
+
+	t.Run("expand honors accept", func(t *testing.T) {
+		doc, err := didJWK.ExpandWithOptions(WithAccept(DIDJSONMediaType))
+		assert.NoError(t, err)
+		assert.Nil(t, doc.Context)
+
+		doc, err = didJWK.ExpandWithOptions(WithAccept(DIDJSONLDMediaType))
+		assert.NoError(t, err)
+		assert.NotNil(t, doc.Context)
+
+		_, err = didJWK.ExpandWithOptions(WithAccept("application/xml"))
+		assert.Error(t, err)
+	})
+
+	t.Run("unsupported accept", func(t *testing.T) {
+		_, err := JWKResolver{}.Resolve(context.Background(), didJWK.String(), WithAccept("application/xml"))
+		assert.Error(t, err)
+		assert.Contains(t, err.Error(), "representation not supported")
+
+		var resolutionErr *ResolutionError
+		assert.ErrorAs(t, err, &resolutionErr)
+		assert.Equal(t, RepresentationNotSupportedErrorCode, resolutionErr.Code)
+	})
+}
+
+func TestJWKResolverErrors(t *testing.T) {
+	t.Run("invalid did:jwk", func(tt *testing.T) {
+		for _, d := range []string{"did:jwk:not-base64!", "did:jwk:" + base64.RawURLEncoding.EncodeToString([]byte(`{"kty":"oct"}`)), "did:key:123"} {
+			_, err := JWKResolver{}.Resolve(context.Background(), d)
+			assert.Error(tt, err)
+
+			var resolutionErr *ResolutionError
+			require.ErrorAs(tt, err, &resolutionErr, d)
+			assert.Equal(tt, InvalidDIDErrorCode, resolutionErr.Code, d)
+			assert.Equal(tt, d, resolutionErr.DID)
+			assert.True(tt, resolutionErr.InvalidDID)
+		}
+	})
+
+	t.Run("the method is recorded", func(tt *testing.T) {
+		_, err := JWKResolver{}.Resolve(context.Background(), "did:jwk:123")
+		var resolutionErr *ResolutionError
+		require.ErrorAs(tt, err, &resolutionErr)
+		assert.Equal(tt, JWKMethod, resolutionErr.Method)
+		assert.Contains(tt, err.Error(), "decoding did:jwk")
+	})
+}
+
+func TestExpandDIDJWKWithDerivedKeyAgreement(t *testing.T) {
+	pk, _, err := crypto.GenerateEd25519Key()
+	require.NoError(t, err)
+	pubKeyJWK, err := jwx.PublicKeyToPublicKeyJWK(pk)
+	require.NoError(t, err)
+	toDIDJWK := func(t *testing.T, pubKeyJWK jwx.PublicKeyJWK) DIDJWK {
+		gotJWK, err := jwx.JWKFromPublicKeyJWK(pubKeyJWK)
+		require.NoError(t, err)
+		didJWK, err := CreateDIDJWK(gotJWK)
+		require.NoError(t, err)
+		return *didJWK
+	}
+	didJWK := toDIDJWK(t, *pubKeyJWK)
+
+	t.Run("ed25519 key adds a derived x25519 method", func(t *testing.T) {
+		doc, err := didJWK.ExpandWithOptions(WithDerivedKeyAgreement())
+		require.NoError(t, err)
+		require.Len(t, doc.VerificationMethod, 2)
+
+		signingKeyID := didJWK.String() + "#0"
+		keyAgreementKeyID := didJWK.String() + "#1"
+		assert.Equal(t, signingKeyID, doc.VerificationMethod[0].ID)
+		assert.Equal(t, keyAgreementKeyID, doc.VerificationMethod[1].ID)
+		assert.Equal(t, cryptosuite.JSONWebKey2020Type, doc.VerificationMethod[1].Type)
+		assert.Equal(t, "X25519", doc.VerificationMethod[1].PublicKeyJWK.CRV)
+
+		assert.Equal(t, []VerificationMethodSet{signingKeyID}, doc.Authentication)
+		assert.Equal(t, []VerificationMethodSet{signingKeyID}, doc.AssertionMethod)
+		assert.Equal(t, []VerificationMethodSet{signingKeyID}, doc.CapabilityInvocation)
+		assert.Equal(t, []VerificationMethodSet{signingKeyID}, doc.CapabilityDelegation)
+		assert.Equal(t, []VerificationMethodSet{keyAgreementKeyID}, doc.KeyAgreement)
+	})
+
+	t.Run("derived key matches did:key", func(t *testing.T) {
+		doc, err := didJWK.ExpandWithOptions(WithDerivedKeyAgreement(), WithPublicKeyMultibase())
+		require.NoError(t, err)
+		require.Len(t, doc.VerificationMethod, 2)
+
+		didKey, err := CreateDIDKey(crypto.Ed25519, pk)
+		require.NoError(t, err)
+		didKeyDoc, err := didKey.Expand()
+		require.NoError(t, err)
+		require.Len(t, didKeyDoc.VerificationMethod, 2)
+		assert.Equal(t, didKeyDoc.VerificationMethod[1].PublicKeyMultibase, doc.VerificationMethod[1].PublicKeyMultibase)
+	})
+
+	t.Run("thumbprint fragment", func(t *testing.T) {
+		doc, err := didJWK.ExpandWithOptions(WithDerivedKeyAgreement(), WithJWKThumbprintFragment())
+		require.NoError(t, err)
+		require.Len(t, doc.VerificationMethod, 2)
+		thumbprint, err := doc.VerificationMethod[1].PublicKeyJWK.Thumbprint()
+		require.NoError(t, err)
+		assert.Equal(t, didJWK.String()+"#"+thumbprint, doc.VerificationMethod[1].ID)
+	})
+
+	t.Run("keys with a use or of other types are unchanged", func(t *testing.T) {
+		sigJWK := *pubKeyJWK
+		sigJWK.Use = jwx.JWKUseSignature
+		doc, err := toDIDJWK(t, sigJWK).ExpandWithOptions(WithDerivedKeyAgreement())
+		require.NoError(t, err)
+		assert.Len(t, doc.VerificationMethod, 1)
+		assert.Empty(t, doc.KeyAgreement)
+
+		_, p256DID, err := GenerateDIDJWK(crypto.P256)
+		require.NoError(t, err)
+		doc, err = p256DID.ExpandWithOptions(WithDerivedKeyAgreement())
+		require.NoError(t, err)
+		assert.Len(t, doc.VerificationMethod, 1)
+		assert.Equal(t, doc.Authentication, doc.KeyAgreement)
+	})
+
+	t.Run("resolver honors option", func(t *testing.T) {
+		result, err := JWKResolver{}.Resolve(context.Background(), didJWK.String(), WithDerivedKeyAgreement())
+		require.NoError(t, err)
+		assert.Len(t, result.Document.VerificationMethod, 2)
+	})
+}
+
+func TestDIDJWKLimits(t *testing.T) {
+	rsaDIDJWK := func(modulusBytes int) DIDJWK {
+		modulus := make([]byte, modulusBytes)
+		modulus[0] = 0x80
+		pubKeyJWK := jwx.PublicKeyJWK{KTY: "RSA", N: base64.RawURLEncoding.EncodeToString(modulus), E: "AQAB"}
+		jwkBytes, err := json.Marshal(pubKeyJWK)
+		require.NoError(t, err)
+		return DIDJWK(JWKPrefix + ":" + base64.RawURLEncoding.EncodeToString(jwkBytes))
+	}
+
+	t.Run("rsa modulus within the maximum", func(tt *testing.T) {
+		_, err := rsaDIDJWK(1024).Decode()
+		assert.NoError(tt, err)
+	})
+
+	t.Run("rsa modulus exceeding the maximum", func(tt *testing.T) {
+		_, err := rsaDIDJWK(1025).Decode()
+		assert.ErrorIs(tt, err, ErrKeyTooLarge)
+
+		_, err = JWKResolver{}.Resolve(context.Background(), rsaDIDJWK(1025).String())
+		assert.ErrorIs(tt, err, ErrKeyTooLarge)
+	})
+
+	t.Run("did exceeding the maximum length", func(tt *testing.T) {
+		_, err := DIDJWK(JWKPrefix + ":" + strings.Repeat("a", MaxDIDJWKLength+1)).Expand()
+		assert.ErrorIs(tt, err, ErrDIDTooLarge)
+	})
+
+	t.Run("adjusted bounds", func(tt *testing.T) {
+		maxLength, maxModulusBits := MaxDIDJWKLength, MaxDIDJWKRSAModulusBits
+		defer func() {
+			MaxDIDJWKLength, MaxDIDJWKRSAModulusBits = maxLength, maxModulusBits
+		}()
+
+		MaxDIDJWKRSAModulusBits = 2048
+		_, err := rsaDIDJWK(512).Decode()
+		assert.ErrorIs(tt, err, ErrKeyTooLarge)
+
+		MaxDIDJWKRSAModulusBits = 0
+		_, err = rsaDIDJWK(2048).Decode()
+		assert.NoError(tt, err)
+
+		_, didJWK, err := GenerateDIDJWK(crypto.Ed25519)
+		require.NoError(tt, err)
+		MaxDIDJWKLength = 16
+		_, err = didJWK.Expand()
+		assert.ErrorIs(tt, err, ErrDIDTooLarge)
+	})
+}
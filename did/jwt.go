@@ -0,0 +1,170 @@
+package did
+
+import (
+	"context"
+	"time"
+
+	"github.com/goccy/go-json"
+	"github.com/lestrrat-go/jwx/v2/jws"
+	"github.com/lestrrat-go/jwx/v2/jwt"
+	"github.com/pkg/errors"
+
+	"github.com/TBD54566975/ssi-sdk/crypto/jwx"
+	"github.com/TBD54566975/ssi-sdk/util"
+)
+
+const (
+	// DIDDocumentJWTProperty is the claim of a signed DID Document JWT containing the DID Document
+	DIDDocumentJWTProperty = "didDocument"
+)
+
+var (
+	// ErrDIDDocumentJWTExpired is returned when verifying a signed DID Document JWT whose exp is in the past
+	ErrDIDDocumentJWTExpired = errors.New("DID Document JWT is expired")
+	// ErrDIDDocumentJWTNotYetValid is returned when verifying a signed DID Document JWT whose nbf or iat is in the
+	// future
+	ErrDIDDocumentJWTNotYetValid = errors.New("DID Document JWT is not yet valid")
+)
+
+// SignAsJWT signs the DID Document as a JWT, with the document under the didDocument claim and the document's
+// id as the issuer. The signer's kid is set in the JWT header so verifiers can find the key in the document.
+func (d *Document) SignAsJWT(signer jwx.Signer) (string, error) {
+	if d.IsEmpty() || d.ID == "" {
+		return "", errors.New("document cannot be empty")
+	}
+	if signer.Key == nil || signer.KeyID() == "" {
+		return "", errors.New("signer must have a key with a kid")
+	}
+
+	t := jwt.New()
+	if err := t.Set(jwt.IssuerKey, d.ID); err != nil {
+		return "", errors.Wrap(err, "setting iss value")
+	}
+	if err := t.Set(jwt.IssuedAtKey, time.Now().Unix()); err != nil {
+		return "", errors.Wrap(err, "setting iat value")
+	}
+	if err := t.Set(DIDDocumentJWTProperty, d); err != nil {
+		return "", errors.Wrap(err, "setting did document value")
+	}
+
+	signed, err := jwt.Sign(t, jwt.WithKey(signer.SignatureAlgorithm, signer.Key))
+	if err != nil {
+		return "", errors.Wrap(err, "signing DID Document JWT")
+	}
+	return string(signed), nil
+}
+
+// DIDDocumentJWTOption configures how a DID Document JWT is verified
+type DIDDocumentJWTOption func(*didDocumentJWTOptions)
+
+type didDocumentJWTOptions struct {
+	clock util.Clock
+}
+
+// WithDIDDocumentJWTClock is a DIDDocumentJWTOption validating the exp, nbf, and iat claims of a DID Document JWT
+// against the given clock rather than the current time
+func WithDIDDocumentJWTClock(clock util.Clock) DIDDocumentJWTOption {
+	return func(o *didDocumentJWTOptions) {
+		o.clock = clock
+	}
+}
+
+// VerifyDIDDocumentJWT verifies a DID Document JWT produced by SignAsJWT, returning the signed DID Document.
+// The issuer DID is resolved with the provided resolver to find the key matching the kid in the JWT header.
+// Tokens outside their validity period, as of the current time or the clock given with WithDIDDocumentJWTClock, are
+// rejected with ErrDIDDocumentJWTExpired or ErrDIDDocumentJWTNotYetValid.
+func VerifyDIDDocumentJWT(token string, resolver Resolver, opts ...DIDDocumentJWTOption) (*Document, error) {
+	if token == "" {
+		return nil, errors.New("token cannot be empty")
+	}
+	if resolver == nil {
+		return nil, errors.New("resolver cannot be empty")
+	}
+	options := didDocumentJWTOptions{clock: util.RealClock{}}
+	for _, opt := range opts {
+		opt(&options)
+	}
+	parsed, err := jwt.Parse([]byte(token), jwt.WithValidate(false), jwt.WithVerify(false))
+	if err != nil {
+		return nil, errors.Wrap(err, "parsing JWT")
+	}
+	if err = validateDIDDocumentJWTTimes(parsed, options.clock.Now()); err != nil {
+		return nil, err
+	}
+	headers, err := jwx.GetJWSHeaders([]byte(token))
+	if err != nil {
+		return nil, errors.Wrap(err, "getting JWT headers")
+	}
+
+	doc, err := didDocumentFromJWT(parsed)
+	if err != nil {
+		return nil, err
+	}
+	issuer := parsed.Issuer()
+	if doc.ID != issuer {
+		return nil, errors.Errorf("document id<%s> does not match issuer<%s>", doc.ID, issuer)
+	}
+
+	if err = verifyDIDDocumentJWTSignature(token, headers, issuer, resolver, options.clock); err != nil {
+		return nil, err
+	}
+	return doc, nil
+}
+
+// validateDIDDocumentJWTTimes checks the token's exp, nbf, and iat claims against the given time
+func validateDIDDocumentJWTTimes(token jwt.Token, now time.Time) error {
+	if exp := token.Expiration(); !exp.IsZero() && !now.Before(exp) {
+		return errors.Wrapf(ErrDIDDocumentJWTExpired, "expired at %s", exp.Format(time.RFC3339))
+	}
+	if nbf := token.NotBefore(); !nbf.IsZero() && now.Before(nbf) {
+		return errors.Wrapf(ErrDIDDocumentJWTNotYetValid, "not valid before %s", nbf.Format(time.RFC3339))
+	}
+	if iat := token.IssuedAt(); !iat.IsZero() && now.Before(iat) {
+		return errors.Wrapf(ErrDIDDocumentJWTNotYetValid, "issued in the future at %s", iat.Format(time.RFC3339))
+	}
+	return nil
+}
+
+// didDocumentFromJWT reads the DID Document from the didDocument claim of the token
+func didDocumentFromJWT(token jwt.Token) (*Document, error) {
+	claim, ok := token.Get(DIDDocumentJWTProperty)
+	if !ok {
+		return nil, errors.Errorf("missing %s claim", DIDDocumentJWTProperty)
+	}
+	docBytes, err := json.Marshal(claim)
+	if err != nil {
+		return nil, errors.Wrap(err, "marshalling did document claim")
+	}
+	var doc Document
+	if err = json.Unmarshal(docBytes, &doc); err != nil {
+		return nil, errors.Wrap(err, "unmarshalling did document claim")
+	}
+	if doc.IsEmpty() {
+		return nil, errors.Errorf("empty %s claim", DIDDocumentJWTProperty)
+	}
+	return &doc, nil
+}
+
+// verifyDIDDocumentJWTSignature resolves the issuer and verifies the token with the key identified by its kid
+func verifyDIDDocumentJWTSignature(token string, headers jws.Headers, issuer string, resolver Resolver, clock util.Clock) error {
+	kid := headers.KeyID()
+	if kid == "" {
+		return errors.New("missing kid in header of DID Document JWT")
+	}
+	resolved, err := resolver.Resolve(context.Background(), issuer)
+	if err != nil {
+		return errors.Wrapf(err, "resolving issuer DID<%s>", issuer)
+	}
+	issuerKey, err := GetKeyFromVerificationMethod(resolved.Document, kid)
+	if err != nil {
+		return errors.Wrapf(err, "getting key to verify DID Document JWT from issuer<%s>", issuer)
+	}
+	verifier, err := jwx.NewJWXVerifier(issuer, issuerKey, jwx.WithClock(clock))
+	if err != nil {
+		return errors.Wrapf(err, "constructing verifier for issuer<%s>", issuer)
+	}
+	if err = verifier.Verify(token); err != nil {
+		return errors.Wrap(err, "verifying DID Document JWT")
+	}
+	return nil
+}
@@ -0,0 +1,121 @@
+package did
+
+import (
+	"testing"
+	"time"
+
+	"github.com/lestrrat-go/jwx/v2/jwt"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/TBD54566975/ssi-sdk/crypto"
+	"github.com/TBD54566975/ssi-sdk/crypto/jwx"
+	"github.com/TBD54566975/ssi-sdk/util"
+)
+
+func TestDIDDocumentJWT(t *testing.T) {
+	privKey, didJWK, err := GenerateDIDJWK(crypto.Ed25519)
+	require.NoError(t, err)
+	doc, err := didJWK.Expand()
+	require.NoError(t, err)
+	signer, err := jwx.NewJWXSigner(didJWK.String(), doc.VerificationMethod[0].ID, privKey)
+	require.NoError(t, err)
+
+	// signWithClaims signs the document along with the given time claims
+	signWithClaims := func(t *testing.T, claims map[string]any) string {
+		token := jwt.New()
+		require.NoError(t, token.Set(jwt.IssuerKey, doc.ID))
+		require.NoError(t, token.Set(DIDDocumentJWTProperty, doc))
+		for k, v := range claims {
+			require.NoError(t, token.Set(k, v))
+		}
+		signed, err := jwt.Sign(token, jwt.WithKey(signer.SignatureAlgorithm, signer.Key))
+		require.NoError(t, err)
+		return string(signed)
+	}
+
+	t.Run("sign and verify", func(tt *testing.T) {
+		token, err := doc.SignAsJWT(*signer)
+		assert.NoError(tt, err)
+
+		parsed, err := jwt.Parse([]byte(token), jwt.WithVerify(false))
+		assert.NoError(tt, err)
+		assert.Equal(tt, doc.ID, parsed.Issuer())
+		_, ok := parsed.Get(DIDDocumentJWTProperty)
+		assert.True(tt, ok)
+
+		verified, err := VerifyDIDDocumentJWT(token, JWKResolver{})
+		assert.NoError(tt, err)
+		assert.Equal(tt, doc.ID, verified.ID)
+		assert.Equal(tt, doc.VerificationMethod[0].ID, verified.VerificationMethod[0].ID)
+		assert.Equal(tt, doc.VerificationMethod[0].PublicKeyJWK, verified.VerificationMethod[0].PublicKeyJWK)
+	})
+
+	t.Run("signed by another key", func(tt *testing.T) {
+		otherKey, _, err := GenerateDIDJWK(crypto.Ed25519)
+		require.NoError(tt, err)
+		otherSigner, err := jwx.NewJWXSigner(didJWK.String(), doc.VerificationMethod[0].ID, otherKey)
+		require.NoError(tt, err)
+
+		token, err := doc.SignAsJWT(*otherSigner)
+		assert.NoError(tt, err)
+		_, err = VerifyDIDDocumentJWT(token, JWKResolver{})
+		assert.Error(tt, err)
+		assert.Contains(tt, err.Error(), "verifying DID Document JWT")
+	})
+
+	t.Run("expired", func(tt *testing.T) {
+		token := signWithClaims(tt, map[string]any{jwt.ExpirationKey: time.Now().Add(-time.Minute).Unix()})
+		_, err := VerifyDIDDocumentJWT(token, JWKResolver{})
+		assert.ErrorIs(tt, err, ErrDIDDocumentJWTExpired)
+	})
+
+	t.Run("not yet valid", func(tt *testing.T) {
+		token := signWithClaims(tt, map[string]any{jwt.NotBeforeKey: time.Now().Add(time.Hour).Unix()})
+		_, err := VerifyDIDDocumentJWT(token, JWKResolver{})
+		assert.ErrorIs(tt, err, ErrDIDDocumentJWTNotYetValid)
+
+		token = signWithClaims(tt, map[string]any{jwt.IssuedAtKey: time.Now().Add(time.Hour).Unix()})
+		_, err = VerifyDIDDocumentJWT(token, JWKResolver{})
+		assert.ErrorIs(tt, err, ErrDIDDocumentJWTNotYetValid)
+		assert.NotErrorIs(tt, err, ErrDIDDocumentJWTExpired)
+	})
+
+	t.Run("validated against the given clock", func(tt *testing.T) {
+		now := time.Now()
+		token := signWithClaims(tt, map[string]any{
+			jwt.IssuedAtKey:   now.Add(-2 * time.Hour).Unix(),
+			jwt.ExpirationKey: now.Add(-time.Hour).Unix(),
+		})
+		_, err := VerifyDIDDocumentJWT(token, JWKResolver{})
+		assert.ErrorIs(tt, err, ErrDIDDocumentJWTExpired)
+
+		verified, err := VerifyDIDDocumentJWT(token, JWKResolver{}, WithDIDDocumentJWTClock(util.FixedClock(now.Add(-90*time.Minute))))
+		assert.NoError(tt, err)
+		assert.Equal(tt, doc.ID, verified.ID)
+
+		_, err = VerifyDIDDocumentJWT(token, JWKResolver{}, WithDIDDocumentJWTClock(util.FixedClock(now.Add(-3*time.Hour))))
+		assert.ErrorIs(tt, err, ErrDIDDocumentJWTNotYetValid)
+	})
+
+	t.Run("issuer must match the document", func(tt *testing.T) {
+		token := signWithClaims(tt, map[string]any{jwt.IssuerKey: "did:example:123"})
+		_, err := VerifyDIDDocumentJWT(token, JWKResolver{})
+		assert.Error(tt, err)
+		assert.Contains(tt, err.Error(), "does not match issuer")
+	})
+
+	t.Run("bad input", func(tt *testing.T) {
+		_, err := (&Document{}).SignAsJWT(*signer)
+		assert.Error(tt, err)
+
+		_, err = VerifyDIDDocumentJWT("", JWKResolver{})
+		assert.Error(tt, err)
+
+		_, err = VerifyDIDDocumentJWT("not a jwt", JWKResolver{})
+		assert.Error(tt, err)
+
+		_, err = VerifyDIDDocumentJWT(signWithClaims(tt, nil), nil)
+		assert.Error(tt, err)
+	})
+}
@@ -15,10 +15,6 @@ import (
 	"github.com/pkg/errors"
 
 	"github.com/TBD54566975/ssi-sdk/crypto"
-
-	"github.com/multiformats/go-multibase"
-	"github.com/multiformats/go-multicodec"
-	"github.com/multiformats/go-varint"
 )
 
 type (
@@ -96,9 +92,7 @@ func CreateDIDKey(kt crypto.KeyType, publicKey []byte) (*DIDKey, error) {
 	if err != nil {
 		return nil, fmt.Errorf("could find mutlicodec for key type<%s> for did:key", kt)
 	}
-	prefix := varint.ToUvarint(uint64(multiCodec))
-	codec := append(prefix, publicKey...)
-	encoded, err := multibase.Encode(Base58BTCMultiBase, codec)
+	encoded, err := crypto.MultibaseEncode(Base58BTCMultiBase, crypto.MulticodecWrap(multiCodec, publicKey))
 	if err != nil {
 		return nil, errors.Wrap(err, "could not encode did:key")
 	}
@@ -116,7 +110,7 @@ func (d DIDKey) Decode() ([]byte, cryptosuite.LDKeyType, crypto.KeyType, error)
 		return nil, "", "", fmt.Errorf("could not decode did:key value: %s", string(d))
 	}
 
-	encoding, decoded, err := multibase.Decode(parsed)
+	encoding, decoded, err := crypto.MultibaseDecode(parsed)
 	if err != nil {
 		return nil, "", "", errors.Wrap(err, "could not decode did:key")
 	}
@@ -124,29 +118,22 @@ func (d DIDKey) Decode() ([]byte, cryptosuite.LDKeyType, crypto.KeyType, error)
 		return nil, "", "", fmt.Errorf("expected %d encoding but found %d", Base58BTCMultiBase, encoding)
 	}
 
-	// n = # bytes for the int, which we expect to be two from our multicodec
-	multiCodec, n, err := varint.FromUvarint(decoded)
+	multiCodec, pubKeyBytes, err := crypto.MulticodecUnwrap(decoded)
 	if err != nil {
-		return nil, "", "", err
-	}
-	if n != 2 {
-		return nil, "", "", errors.New("error parsing did:key varint")
+		return nil, "", "", errors.Wrap(err, "error parsing did:key varint")
 	}
-
-	pubKeyBytes := decoded[n:]
-	multiCodecValue := multicodec.Code(multiCodec)
-	ldKeyType, err := codecToLDKeyType(multiCodecValue)
+	ldKeyType, err := codecToLDKeyType(multiCodec)
 	if err != nil {
 		return nil, "", "", errors.Wrap(err, "determining LD key type")
 	}
-	cryptoKeyType, err := codecToKeyType(multiCodecValue)
+	cryptoKeyType, err := codecToKeyType(multiCodec)
 	if err != nil {
 		return nil, "", "", errors.Wrap(err, "determining key type")
 	}
 	return pubKeyBytes, ldKeyType, cryptoKeyType, nil
 }
 
-func codecToLDKeyType(codec multicodec.Code) (cryptosuite.LDKeyType, error) {
+func codecToLDKeyType(codec crypto.MulticodecCode) (cryptosuite.LDKeyType, error) {
 	switch codec {
 	case Ed25519MultiCodec:
 		return cryptosuite.Ed25519VerificationKey2018, nil
@@ -185,18 +172,54 @@ func (d DIDKey) Expand() (*Document, error) {
 		[]string{keyReference},
 	}
 
-	return &Document{
-		Context:              KnownDIDContext,
-		ID:                   id,
-		VerificationMethod:   []VerificationMethod{*verificationMethod},
-		Authentication:       verificationMethodSet,
-		AssertionMethod:      verificationMethodSet,
-		KeyAgreement:         verificationMethodSet,
-		CapabilityDelegation: verificationMethodSet,
+	doc := Document{
+		Context:            KnownDIDContext,
+		ID:                 id,
+		VerificationMethod: []VerificationMethod{*verificationMethod},
+		KeyAgreement:       verificationMethodSet,
+	}
+
+	// X25519 keys can only be used for key agreement, mirroring the did:jwk behavior for keys with an "enc" use
+	if cryptoKeyType != crypto.X25519 {
+		doc.Authentication = verificationMethodSet
+		doc.AssertionMethod = verificationMethodSet
+		doc.CapabilityInvocation = verificationMethodSet
+		doc.CapabilityDelegation = verificationMethodSet
+	}
+
+	// Ed25519 keys must not be used for key agreement directly, so we derive an X25519 key for that purpose
+	// https://w3c-ccg.github.io/did-method-key/#encryption-method-creation-algorithm
+	if cryptoKeyType == crypto.Ed25519 {
+		keyAgreementMethod, err := constructX25519KeyAgreementMethod(id, pubKey)
+		if err != nil {
+			return nil, errors.Wrap(err, "could not derive key agreement verification method")
+		}
+		doc.VerificationMethod = append(doc.VerificationMethod, *keyAgreementMethod)
+		doc.KeyAgreement = []VerificationMethodSet{[]string{keyAgreementMethod.ID}}
+	}
+	return &doc, nil
+}
+
+// constructX25519KeyAgreementMethod derives an X25519 key from the given Ed25519 public key and returns a
+// verification method for it, identified by its multibase encoding
+func constructX25519KeyAgreementMethod(id string, ed25519PubKey []byte) (*VerificationMethod, error) {
+	x25519PubKey, err := crypto.Ed25519PublicKeyToX25519(ed25519PubKey)
+	if err != nil {
+		return nil, errors.Wrap(err, "converting ed25519 key to x25519")
+	}
+	multibaseKey, err := encodePublicKeyWithKeyMultiCodecType(crypto.X25519, x25519PubKey)
+	if err != nil {
+		return nil, errors.Wrap(err, "encoding x25519 key")
+	}
+	return &VerificationMethod{
+		ID:                 "#" + multibaseKey,
+		Type:               cryptosuite.X25519KeyAgreementKey2020,
+		Controller:         id,
+		PublicKeyMultibase: multibaseKey,
 	}, nil
 }
 
-func codecToKeyType(codec multicodec.Code) (crypto.KeyType, error) {
+func codecToKeyType(codec crypto.MulticodecCode) (crypto.KeyType, error) {
 	var kt crypto.KeyType
 	switch codec {
 	case Ed25519MultiCodec:
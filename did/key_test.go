@@ -15,11 +15,6 @@ import (
 
 	"github.com/TBD54566975/ssi-sdk/cryptosuite"
 
-	"github.com/multiformats/go-multicodec"
-
-	"github.com/multiformats/go-multibase"
-	"github.com/multiformats/go-varint"
-
 	"github.com/TBD54566975/ssi-sdk/crypto"
 
 	"github.com/stretchr/testify/assert"
@@ -120,14 +115,14 @@ func TestGenerateDIDKey(t *testing.T) {
 
 			parsed, err := didKey.Suffix()
 			assert.NoError(t, err)
-			encoding, decoded, err := multibase.Decode(parsed)
+			encoding, decoded, err := crypto.MultibaseDecode(parsed)
 			assert.NoError(t, err)
 			assert.True(t, encoding == Base58BTCMultiBase)
 
-			multiCodec, n, err := varint.FromUvarint(decoded)
+			multiCodec, pubKeyBytes, err := crypto.MulticodecUnwrap(decoded)
 			assert.NoError(t, err)
-			assert.Equal(t, 2, n)
-			assert.Equal(t, codec, multicodec.Code(multiCodec))
+			assert.NotEmpty(t, pubKeyBytes)
+			assert.Equal(t, codec, multiCodec)
 		})
 	}
 }
@@ -356,24 +351,27 @@ func TestKnownTestVectors(t *testing.T) {
 		didDoc1, err := didKey1.Expand()
 		assert.NoError(tt, err)
 		assert.Equal(tt, did1, didDoc1.ID)
-		assert.Equal(tt, 1, len(didDoc1.VerificationMethod))
+		assert.Equal(tt, 2, len(didDoc1.VerificationMethod))
 		assert.Equal(tt, cryptosuite.Ed25519VerificationKey2018, didDoc1.VerificationMethod[0].Type)
+		assert.Equal(tt, cryptosuite.X25519KeyAgreementKey2020, didDoc1.VerificationMethod[1].Type)
 
 		did2 := "did:key:z6MkjchhfUsD6mmvni8mCdXHw216Xrm9bQe2mBH1P5RDjVJG"
 		didKey2 := DIDKey(did2)
 		didDoc2, err := didKey2.Expand()
 		assert.NoError(tt, err)
 		assert.Equal(tt, did2, didDoc2.ID)
-		assert.Equal(tt, 1, len(didDoc2.VerificationMethod))
+		assert.Equal(tt, 2, len(didDoc2.VerificationMethod))
 		assert.Equal(tt, cryptosuite.Ed25519VerificationKey2018, didDoc2.VerificationMethod[0].Type)
+		assert.Equal(tt, cryptosuite.X25519KeyAgreementKey2020, didDoc2.VerificationMethod[1].Type)
 
 		did3 := "did:key:z6MknGc3ocHs3zdPiJbnaaqDi58NGb4pk1Sp9WxWufuXSdxf"
 		didKey3 := DIDKey(did3)
 		didDoc3, err := didKey3.Expand()
 		assert.NoError(tt, err)
 		assert.Equal(tt, did3, didDoc3.ID)
-		assert.Equal(tt, 1, len(didDoc3.VerificationMethod))
+		assert.Equal(tt, 2, len(didDoc3.VerificationMethod))
 		assert.Equal(tt, cryptosuite.Ed25519VerificationKey2018, didDoc3.VerificationMethod[0].Type)
+		assert.Equal(tt, cryptosuite.X25519KeyAgreementKey2020, didDoc3.VerificationMethod[1].Type)
 	})
 
 	t.Run("X25519", func(tt *testing.T) {
@@ -492,3 +490,70 @@ func TestKnownTestVectors(t *testing.T) {
 		assert.Equal(tt, cryptosuite.JSONWebKey2020Type, didDoc1.VerificationMethod[0].Type)
 	})
 }
+
+func TestDIDKeyVerificationRelationships(t *testing.T) {
+	t.Run("X25519 is key agreement only", func(t *testing.T) {
+		didKey := DIDKey("did:key:z6LSeu9HkTHSfLLeUs2nnzUSNedgDUevfNQgQjQC23ZCit6F")
+		doc, err := didKey.Expand()
+		assert.NoError(t, err)
+		assert.NotEmpty(t, doc.KeyAgreement)
+		assert.Empty(t, doc.Authentication)
+		assert.Empty(t, doc.AssertionMethod)
+		assert.Empty(t, doc.CapabilityInvocation)
+		assert.Empty(t, doc.CapabilityDelegation)
+	})
+
+	t.Run("signing keys have all relationships", func(t *testing.T) {
+		for _, kt := range GetSupportedDIDKeyTypes() {
+			if kt == crypto.X25519 {
+				continue
+			}
+			_, didKey, err := GenerateDIDKey(kt)
+			assert.NoError(t, err)
+
+			doc, err := didKey.Expand()
+			assert.NoError(t, err)
+			assert.NoError(t, doc.IsValid())
+			assert.NotEmpty(t, doc.Authentication, "key type: %s", kt)
+			assert.NotEmpty(t, doc.AssertionMethod, "key type: %s", kt)
+			assert.NotEmpty(t, doc.KeyAgreement, "key type: %s", kt)
+			assert.NotEmpty(t, doc.CapabilityInvocation, "key type: %s", kt)
+			assert.NotEmpty(t, doc.CapabilityDelegation, "key type: %s", kt)
+		}
+	})
+
+	t.Run("resolves through the key resolver", func(t *testing.T) {
+		_, didKey, err := GenerateDIDKey(crypto.X25519)
+		assert.NoError(t, err)
+
+		resolved, err := KeyResolver{}.Resolve(context.Background(), didKey.String())
+		assert.NoError(t, err)
+		assert.Equal(t, didKey.String(), resolved.Document.ID)
+		assert.Empty(t, resolved.Document.Authentication)
+		assert.NotEmpty(t, resolved.Document.KeyAgreement)
+	})
+}
+
+func TestDIDKeyEd25519KeyAgreementDerivation(t *testing.T) {
+	// From https://w3c-ccg.github.io/did-method-key/#ed25519-x25519
+	didKey := DIDKey("did:key:z6MkhaXgBZDvotDkL5257faiztiGiC2QtKLGpbnnEGta2doK")
+	doc, err := didKey.Expand()
+	assert.NoError(t, err)
+	assert.NoError(t, doc.IsValid())
+	assert.Len(t, doc.VerificationMethod, 2)
+
+	signingKeyID := "#z6MkhaXgBZDvotDkL5257faiztiGiC2QtKLGpbnnEGta2doK"
+	keyAgreementKeyID := "#z6LSj72tK8brWgZja8NLRwPigth2T9QRiG1uH9oKZuKjdh9p"
+
+	assert.Equal(t, signingKeyID, doc.VerificationMethod[0].ID)
+	assert.Equal(t, cryptosuite.Ed25519VerificationKey2018, doc.VerificationMethod[0].Type)
+
+	assert.Equal(t, keyAgreementKeyID, doc.VerificationMethod[1].ID)
+	assert.Equal(t, cryptosuite.X25519KeyAgreementKey2020, doc.VerificationMethod[1].Type)
+	assert.Equal(t, didKey.String(), doc.VerificationMethod[1].Controller)
+	assert.Equal(t, "z6LSj72tK8brWgZja8NLRwPigth2T9QRiG1uH9oKZuKjdh9p", doc.VerificationMethod[1].PublicKeyMultibase)
+
+	assert.Equal(t, []VerificationMethodSet{[]string{keyAgreementKeyID}}, doc.KeyAgreement)
+	assert.Equal(t, []VerificationMethodSet{[]string{signingKeyID}}, doc.Authentication)
+	assert.Equal(t, []VerificationMethodSet{[]string{signingKeyID}}, doc.AssertionMethod)
+}
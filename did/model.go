@@ -1,12 +1,15 @@
 package did
 
 import (
+	"crypto/sha256"
 	"fmt"
 	"reflect"
+	"sort"
 
 	"github.com/TBD54566975/ssi-sdk/crypto/jwx"
-	"github.com/multiformats/go-multibase"
-	"github.com/multiformats/go-multicodec"
+	"github.com/goccy/go-json"
+	"github.com/multiformats/go-multihash"
+	"github.com/pkg/errors"
 
 	"github.com/TBD54566975/ssi-sdk/crypto"
 	"github.com/TBD54566975/ssi-sdk/cryptosuite"
@@ -17,19 +20,26 @@ import (
 const (
 	KnownDIDContext string = "https://www.w3.org/ns/did/v1"
 
-	// Base58BTCMultiBase Base58BTC https://github.com/multiformats/go-multibase/blob/master/multibase.go
-	Base58BTCMultiBase = multibase.Base58BTC
+	// DIDJSONMediaType is the media type of a DID Document without a JSON-LD @context
+	// https://www.w3.org/TR/did-spec-registries/#application-did-json
+	DIDJSONMediaType = "application/did+json"
+	// DIDJSONLDMediaType is the media type of a DID Document with a JSON-LD @context
+	// https://www.w3.org/TR/did-spec-registries/#application-did-ld-json
+	DIDJSONLDMediaType = "application/did+ld+json"
+
+	// Base58BTCMultiBase Base58BTC https://datatracker.ietf.org/doc/html/draft-multiformats-multibase
+	Base58BTCMultiBase = crypto.Base58BTCMultibase
 
 	// Multicodec reference https://github.com/multiformats/multicodec/blob/master/table.csv
 
-	Ed25519MultiCodec   = multicodec.Ed25519Pub
-	X25519MultiCodec    = multicodec.X25519Pub
-	SECP256k1MultiCodec = multicodec.Secp256k1Pub
-	P256MultiCodec      = multicodec.P256Pub
-	P384MultiCodec      = multicodec.P384Pub
-	P521MultiCodec      = multicodec.P521Pub
-	RSAMultiCodec       = multicodec.RsaPub
-	SHA256MultiCodec    = multicodec.Sha2_256
+	Ed25519MultiCodec   = crypto.Ed25519Multicodec
+	X25519MultiCodec    = crypto.X25519Multicodec
+	SECP256k1MultiCodec = crypto.SECP256k1Multicodec
+	P256MultiCodec      = crypto.P256Multicodec
+	P384MultiCodec      = crypto.P384Multicodec
+	P521MultiCodec      = crypto.P521Multicodec
+	RSAMultiCodec       = crypto.RSAMulticodec
+	SHA256MultiCodec    = crypto.MulticodecCode(0x12)
 )
 
 // ResolutionResult encapsulates the tuple of a DID resolution https://www.w3.org/TR/did-core/#did-resolution
@@ -44,7 +54,12 @@ func (r *ResolutionResult) IsEmpty() bool {
 	if r == nil {
 		return true
 	}
-	return reflect.DeepEqual(r, ResolutionResult{})
+	return reflect.DeepEqual(*r, ResolutionResult{})
+}
+
+// IsDeactivated returns whether the resolved DID has been deactivated, as given by its document metadata
+func (r *ResolutionResult) IsDeactivated() bool {
+	return r != nil && r.DocumentMetadata.Deactivated
 }
 
 // DocumentMetadata https://www.w3.org/TR/did-core/#did-document-metadata
@@ -63,18 +78,65 @@ func (s *DocumentMetadata) IsValid() bool {
 	return util.NewValidator().Struct(s) == nil
 }
 
+// DID Resolution error codes https://www.w3.org/TR/did-spec-registries/#error
+const (
+	InvalidDIDErrorCode                 = "invalidDid"
+	NotFoundErrorCode                   = "notFound"
+	RepresentationNotSupportedErrorCode = "representationNotSupported"
+	MethodNotSupportedErrorCode         = "methodNotSupported"
+	InternalErrorCode                   = "internalError"
+)
+
 // ResolutionError https://www.w3.org/TR/did-core/#did-resolution-metadata
+// It is also returned by resolvers as an error, so that callers can branch on the cause of a failed resolution with
+// errors.As. DID, Method, and Err describe the failure and are not part of the resolution metadata.
 type ResolutionError struct {
 	Code                       string `json:"code"`
 	InvalidDID                 bool   `json:"invalidDid"`
 	NotFound                   bool   `json:"notFound"`
 	RepresentationNotSupported bool   `json:"representationNotSupported"`
+
+	DID    string `json:"-"`
+	Method Method `json:"-"`
+	// Err is the underlying cause of the failure, if any
+	Err error `json:"-"`
+}
+
+// NewResolutionError creates a ResolutionError with the given code for a failure to resolve the given DID. The
+// DID's method is recorded when the DID has one.
+func NewResolutionError(code, did string, err error) *ResolutionError {
+	var method Method
+	if parsed, parseErr := ParseDID(did); parseErr == nil {
+		method = parsed.Method
+	}
+	return &ResolutionError{
+		Code:                       code,
+		InvalidDID:                 code == InvalidDIDErrorCode,
+		NotFound:                   code == NotFoundErrorCode,
+		RepresentationNotSupported: code == RepresentationNotSupportedErrorCode,
+		DID:                        did,
+		Method:                     method,
+		Err:                        err,
+	}
+}
+
+func (e *ResolutionError) Error() string {
+	if e.Err != nil {
+		return fmt.Sprintf("%s: resolving %s: %s", e.Code, e.DID, e.Err.Error())
+	}
+	return fmt.Sprintf("%s: resolving %s", e.Code, e.DID)
+}
+
+func (e *ResolutionError) Unwrap() error {
+	return e.Err
 }
 
 // ResolutionMetadata https://www.w3.org/TR/did-core/#did-resolution-metadata
 type ResolutionMetadata struct {
-	ContentType string
-	Error       *ResolutionError
+	ContentType string           `json:"contentType,omitempty"`
+	Error       *ResolutionError `json:"error,omitempty"`
+	// Warnings are non-fatal issues encountered during resolution
+	Warnings []string `json:"warnings,omitempty"`
 }
 
 // Document is a representation of the did core specification https://www.w3.org/TR/did-core
@@ -140,6 +202,254 @@ func (d *Document) IsValid() error {
 	return util.NewValidator().Struct(d)
 }
 
+// RelationshipType is a verification relationship of a DID Document
+// https://www.w3.org/TR/did-core/#verification-relationships
+type RelationshipType string
+
+const (
+	AuthenticationRelationship       RelationshipType = "authentication"
+	AssertionMethodRelationship      RelationshipType = "assertionMethod"
+	KeyAgreementRelationship         RelationshipType = "keyAgreement"
+	CapabilityInvocationRelationship RelationshipType = "capabilityInvocation"
+	CapabilityDelegationRelationship RelationshipType = "capabilityDelegation"
+)
+
+// relationship returns the entries of the DID Document for the given verification relationship
+func (d *Document) relationship(r RelationshipType) (*[]VerificationMethodSet, error) {
+	switch r {
+	case AuthenticationRelationship:
+		return &d.Authentication, nil
+	case AssertionMethodRelationship:
+		return &d.AssertionMethod, nil
+	case KeyAgreementRelationship:
+		return &d.KeyAgreement, nil
+	case CapabilityInvocationRelationship:
+		return &d.CapabilityInvocation, nil
+	case CapabilityDelegationRelationship:
+		return &d.CapabilityDelegation, nil
+	default:
+		return nil, fmt.Errorf("unknown verification relationship: %s", r)
+	}
+}
+
+// relationships returns the entries of each of the DID Document's verification relationships
+func (d *Document) relationships() []*[]VerificationMethodSet {
+	return []*[]VerificationMethodSet{
+		&d.Authentication,
+		&d.AssertionMethod,
+		&d.KeyAgreement,
+		&d.CapabilityInvocation,
+		&d.CapabilityDelegation,
+	}
+}
+
+// HasVerificationRelationship determines whether the DID Document lists the verification method with the given id in
+// the given verification relationship, whether by reference or embedded. Relative ids, such as #key-1, are compared
+// against the document's id.
+func (d *Document) HasVerificationRelationship(id string, r RelationshipType) (bool, error) {
+	relationship, err := d.relationship(r)
+	if err != nil {
+		return false, err
+	}
+	target := absoluteDIDURL(d.ID, id)
+	for _, entry := range *relationship {
+		embedded, err := embeddedVerificationMethod(entry)
+		if err != nil {
+			return false, err
+		}
+		var entryIDs []string
+		if embedded != nil {
+			entryIDs = []string{embedded.ID}
+		} else if entryIDs, err = verificationMethodSetIDs(entry); err != nil {
+			return false, err
+		}
+		for _, entryID := range entryIDs {
+			if absoluteDIDURL(d.ID, entryID) == target {
+				return true, nil
+			}
+		}
+	}
+	return false, nil
+}
+
+// AddVerificationMethod adds the verification method to the DID Document, and references it by id from each of the
+// given verification relationships. An error is returned if the document already has a verification method with the
+// same id, whether listed in its verificationMethod or embedded in a relationship, in which case the document is not
+// modified. Relative ids, such as #key-1, are compared against the document's id.
+// Note: Not thread safe
+func (d *Document) AddVerificationMethod(vm VerificationMethod, relationships ...RelationshipType) error {
+	if vm.ID == "" {
+		return errors.New("verification method id cannot be empty")
+	}
+	target := absoluteDIDURL(d.ID, vm.ID)
+	for _, existing := range d.VerificationMethod {
+		if absoluteDIDURL(d.ID, existing.ID) == target {
+			return fmt.Errorf("DID Document already has a verification method with id<%s>", vm.ID)
+		}
+	}
+	for _, relationship := range d.relationships() {
+		for _, entry := range *relationship {
+			embedded, err := embeddedVerificationMethod(entry)
+			if err != nil {
+				return err
+			}
+			if embedded != nil && absoluteDIDURL(d.ID, embedded.ID) == target {
+				return fmt.Errorf("DID Document already has a verification method with id<%s>", vm.ID)
+			}
+		}
+	}
+
+	// resolve every relationship before modifying the document, referencing the method once from each
+	var entries []*[]VerificationMethodSet
+	seen := make(map[RelationshipType]bool)
+	for _, r := range relationships {
+		if seen[r] {
+			continue
+		}
+		seen[r] = true
+		relationship, err := d.relationship(r)
+		if err != nil {
+			return err
+		}
+		entries = append(entries, relationship)
+	}
+
+	d.VerificationMethod = append(d.VerificationMethod, vm)
+	for _, relationship := range entries {
+		*relationship = append(*relationship, vm.ID)
+	}
+	return nil
+}
+
+// RemoveVerificationMethod removes the verification method with the given id from the DID Document, along with every
+// reference to it, or embedding of it, in the document's verification relationships. An error is returned if the
+// document has no such verification method.
+// Note: Not thread safe
+func (d *Document) RemoveVerificationMethod(id string) error {
+	target := absoluteDIDURL(d.ID, id)
+	found := false
+
+	var methods []VerificationMethod
+	for _, vm := range d.VerificationMethod {
+		if absoluteDIDURL(d.ID, vm.ID) == target {
+			found = true
+			continue
+		}
+		methods = append(methods, vm)
+	}
+
+	// entries are collected before any relationship is modified, so that a malformed entry leaves the document as is
+	remaining := make([][]VerificationMethodSet, 0, len(d.relationships()))
+	for _, relationship := range d.relationships() {
+		var entries []VerificationMethodSet
+		for _, entry := range *relationship {
+			entryID := ""
+			if reference, ok := entry.(string); ok {
+				entryID = reference
+			} else {
+				embedded, err := embeddedVerificationMethod(entry)
+				if err != nil {
+					return err
+				}
+				if embedded != nil {
+					entryID = embedded.ID
+				}
+			}
+			if entryID != "" && absoluteDIDURL(d.ID, entryID) == target {
+				found = true
+				continue
+			}
+			entries = append(entries, entry)
+		}
+		remaining = append(remaining, entries)
+	}
+	if !found {
+		return fmt.Errorf("DID Document has no verification method with id<%s>", id)
+	}
+
+	d.VerificationMethod = methods
+	for i, relationship := range d.relationships() {
+		*relationship = remaining[i]
+	}
+	return nil
+}
+
+// AddService adds the service to the DID Document. An error is returned if the document already has a service with
+// the same id, in which case the document is not modified.
+// Note: Not thread safe
+func (d *Document) AddService(s Service) error {
+	if s.ID == "" {
+		return errors.New("service id cannot be empty")
+	}
+	target := absoluteDIDURL(d.ID, s.ID)
+	for _, existing := range d.Services {
+		if absoluteDIDURL(d.ID, existing.ID) == target {
+			return fmt.Errorf("DID Document already has a service with id<%s>", s.ID)
+		}
+	}
+	d.Services = append(d.Services, s)
+	return nil
+}
+
+// unorderedDocumentProperties are the properties of a DID Document whose values are sets, so that the order of their
+// entries carries no meaning
+var unorderedDocumentProperties = []string{
+	"verificationMethod",
+	"authentication",
+	"assertionMethod",
+	"keyAgreement",
+	"capabilityInvocation",
+	"capabilityDelegation",
+	"service",
+}
+
+// Canonicalize serializes the DID Document deterministically, using the JSON Canonicalization Scheme (JCS) after
+// sorting the entries of its verification methods, verification relationships, and services. Documents that differ
+// only in the order of their members or of those entries serialize identically.
+// https://www.rfc-editor.org/rfc/rfc8785
+func (d *Document) Canonicalize() ([]byte, error) {
+	docJSON, err := util.ToJSONMap(d)
+	if err != nil {
+		return nil, errors.Wrap(err, "converting DID Document to JSON")
+	}
+	for _, property := range unorderedDocumentProperties {
+		entries, ok := docJSON[property].([]any)
+		if !ok {
+			continue
+		}
+		canonicalEntries := make([]string, 0, len(entries))
+		for _, entry := range entries {
+			canonicalEntry, err := util.CanonicalJSON(entry)
+			if err != nil {
+				return nil, errors.Wrapf(err, "canonicalizing %s", property)
+			}
+			canonicalEntries = append(canonicalEntries, string(canonicalEntry))
+		}
+		sort.Strings(canonicalEntries)
+		sorted := make([]any, 0, len(canonicalEntries))
+		for _, canonicalEntry := range canonicalEntries {
+			sorted = append(sorted, json.RawMessage(canonicalEntry))
+		}
+		docJSON[property] = sorted
+	}
+	return util.CanonicalJSON(docJSON)
+}
+
+// ContentHash returns the SHA-256 hash of the canonical serialization of the DID Document, from Canonicalize, as a
+// base58btc multibase encoded multihash
+func (d *Document) ContentHash() (string, error) {
+	canonical, err := d.Canonicalize()
+	if err != nil {
+		return "", errors.Wrap(err, "canonicalizing DID Document")
+	}
+	hash := sha256.Sum256(canonical)
+	multiHashed, err := multihash.Encode(hash[:], multihash.SHA2_256)
+	if err != nil {
+		return "", errors.Wrap(err, "encoding multihash")
+	}
+	return crypto.MultibaseEncode(Base58BTCMultiBase, multiHashed)
+}
+
 // KeyTypeToLDKeyType converts crypto.KeyType to cryptosuite.LDKeyType
 func KeyTypeToLDKeyType(kt crypto.KeyType) (cryptosuite.LDKeyType, error) {
 	switch kt {
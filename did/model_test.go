@@ -2,6 +2,8 @@ package did
 
 import (
 	"embed"
+	"errors"
+	"strings"
 	"testing"
 	"time"
 
@@ -104,3 +106,272 @@ func TestKeyTypeToLDKeyType(t *testing.T) {
 	assert.Error(t, err)
 	assert.Contains(t, err.Error(), "keyType bad failed to convert to LDKeyType")
 }
+
+func TestResolutionError(t *testing.T) {
+	t.Run("describes the failure", func(tt *testing.T) {
+		cause := errors.New("bad suffix")
+		err := NewResolutionError(InvalidDIDErrorCode, "did:jwk:123", cause)
+		assert.Equal(tt, InvalidDIDErrorCode, err.Code)
+		assert.Equal(tt, "did:jwk:123", err.DID)
+		assert.Equal(tt, JWKMethod, err.Method)
+		assert.True(tt, err.InvalidDID)
+		assert.False(tt, err.NotFound)
+		assert.ErrorIs(tt, err, cause)
+		assert.Equal(tt, "invalidDid: resolving did:jwk:123: bad suffix", err.Error())
+
+		err = NewResolutionError(NotFoundErrorCode, "not-a-did", nil)
+		assert.Empty(tt, err.Method)
+		assert.True(tt, err.NotFound)
+		assert.Equal(tt, "notFound: resolving not-a-did", err.Error())
+	})
+
+	t.Run("resolution metadata is unchanged", func(tt *testing.T) {
+		metadata := ResolutionMetadata{Error: NewResolutionError(RepresentationNotSupportedErrorCode, "did:jwk:123", errors.New("cause"))}
+		metadataBytes, err := json.Marshal(metadata)
+		assert.NoError(tt, err)
+		assert.JSONEq(tt, `{"error":{"code":"representationNotSupported","invalidDid":false,"notFound":false,"representationNotSupported":true}}`, string(metadataBytes))
+	})
+}
+
+func TestDocumentContentHash(t *testing.T) {
+	docJSON := `{
+		"@context": ["https://www.w3.org/ns/did/v1", "https://w3id.org/security/suites/jws-2020/v1"],
+		"id": "did:example:123",
+		"verificationMethod": [
+			{"id": "did:example:123#key-1", "type": "JsonWebKey2020", "controller": "did:example:123",
+				"publicKeyJwk": {"kty": "OKP", "crv": "Ed25519", "x": "11qYAYKxCrfVS_7TyWQHOg7hcvPapiMlrwIaaPcHURo"}},
+			{"id": "did:example:123#key-2", "type": "JsonWebKey2020", "controller": "did:example:123",
+				"publicKeyJwk": {"kty": "OKP", "crv": "X25519", "x": "hSDwCYkwp1R0i33ctD73Wg2_Og0mOBr066SpjqqbTmo"}}
+		],
+		"authentication": [
+			"did:example:123#key-1",
+			{"id": "did:example:123#key-3", "type": "Ed25519VerificationKey2018", "controller": "did:example:123",
+				"publicKeyBase58": "H3C2AVvLMv6gmMNam3uVAjZpfkcJCwDwnZn6z3wXmqPV"}
+		],
+		"assertionMethod": ["did:example:123#key-1"],
+		"keyAgreement": ["did:example:123#key-2"],
+		"service": [
+			{"id": "did:example:123#dwn", "type": "DecentralizedWebNode", "serviceEndpoint": "https://example.com/dwn"},
+			{"id": "did:example:123#linked-domain", "type": "LinkedDomains", "serviceEndpoint": "https://example.com"}
+		]
+	}`
+	// the same document, with its members and the entries of its sets in a different order
+	reorderedDocJSON := `{
+		"service": [
+			{"type": "LinkedDomains", "serviceEndpoint": "https://example.com", "id": "did:example:123#linked-domain"},
+			{"serviceEndpoint": "https://example.com/dwn", "id": "did:example:123#dwn", "type": "DecentralizedWebNode"}
+		],
+		"keyAgreement": ["did:example:123#key-2"],
+		"authentication": [
+			{"publicKeyBase58": "H3C2AVvLMv6gmMNam3uVAjZpfkcJCwDwnZn6z3wXmqPV", "controller": "did:example:123",
+				"type": "Ed25519VerificationKey2018", "id": "did:example:123#key-3"},
+			"did:example:123#key-1"
+		],
+		"assertionMethod": ["did:example:123#key-1"],
+		"verificationMethod": [
+			{"publicKeyJwk": {"x": "hSDwCYkwp1R0i33ctD73Wg2_Og0mOBr066SpjqqbTmo", "crv": "X25519", "kty": "OKP"},
+				"controller": "did:example:123", "type": "JsonWebKey2020", "id": "did:example:123#key-2"},
+			{"type": "JsonWebKey2020", "id": "did:example:123#key-1", "controller": "did:example:123",
+				"publicKeyJwk": {"x": "11qYAYKxCrfVS_7TyWQHOg7hcvPapiMlrwIaaPcHURo", "kty": "OKP", "crv": "Ed25519"}}
+		],
+		"id": "did:example:123",
+		"@context": ["https://www.w3.org/ns/did/v1", "https://w3id.org/security/suites/jws-2020/v1"]
+	}`
+
+	var doc, reorderedDoc Document
+	assert.NoError(t, json.Unmarshal([]byte(docJSON), &doc))
+	assert.NoError(t, json.Unmarshal([]byte(reorderedDocJSON), &reorderedDoc))
+
+	t.Run("semantically equal documents hash identically", func(tt *testing.T) {
+		canonical, err := doc.Canonicalize()
+		assert.NoError(tt, err)
+		reorderedCanonical, err := reorderedDoc.Canonicalize()
+		assert.NoError(tt, err)
+		assert.Equal(tt, string(canonical), string(reorderedCanonical))
+
+		hash, err := doc.ContentHash()
+		assert.NoError(tt, err)
+		reorderedHash, err := reorderedDoc.ContentHash()
+		assert.NoError(tt, err)
+		assert.Equal(tt, hash, reorderedHash)
+
+		// a base58btc encoded sha2-256 multihash
+		assert.True(tt, strings.HasPrefix(hash, "zQm"), hash)
+	})
+
+	t.Run("the serialization is canonical", func(tt *testing.T) {
+		canonical, err := doc.Canonicalize()
+		assert.NoError(tt, err)
+		assert.True(tt, strings.HasPrefix(string(canonical), `{"@context":["https://www.w3.org/ns/did/v1",`), string(canonical))
+		assert.NotContains(tt, string(canonical), " ")
+		assert.NotContains(tt, string(canonical), "\n")
+
+		// the context is ordered
+		reorderedContext := doc
+		reorderedContext.Context = []any{"https://w3id.org/security/suites/jws-2020/v1", "https://www.w3.org/ns/did/v1"}
+		reorderedContextHash, err := reorderedContext.ContentHash()
+		assert.NoError(tt, err)
+		hash, err := doc.ContentHash()
+		assert.NoError(tt, err)
+		assert.NotEqual(tt, hash, reorderedContextHash)
+	})
+
+	t.Run("different documents hash differently", func(tt *testing.T) {
+		changed := doc
+		changed.AssertionMethod = []VerificationMethodSet{"did:example:123#key-2"}
+		hash, err := doc.ContentHash()
+		assert.NoError(tt, err)
+		changedHash, err := changed.ContentHash()
+		assert.NoError(tt, err)
+		assert.NotEqual(tt, hash, changedHash)
+	})
+}
+
+func TestDocumentMutation(t *testing.T) {
+	newVM := func(id string) VerificationMethod {
+		return VerificationMethod{
+			ID:                 id,
+			Type:               cryptosuite.MultikeyType,
+			Controller:         "did:example:123",
+			PublicKeyMultibase: "z6MkhaXgBZDvotDkL5257faiztiGiC2QtKLGpbnnEGta2doK",
+		}
+	}
+
+	t.Run("relationships reference the added method", func(tt *testing.T) {
+		doc := Document{ID: "did:example:123"}
+		err := doc.AddVerificationMethod(newVM("#key-1"), AuthenticationRelationship, AssertionMethodRelationship, AuthenticationRelationship)
+		assert.NoError(tt, err)
+		err = doc.AddVerificationMethod(newVM("did:example:123#key-2"), KeyAgreementRelationship)
+		assert.NoError(tt, err)
+		err = doc.AddVerificationMethod(newVM("#key-3"))
+		assert.NoError(tt, err)
+
+		assert.Len(tt, doc.VerificationMethod, 3)
+		assert.Equal(tt, []VerificationMethodSet{"#key-1"}, doc.Authentication)
+		assert.Equal(tt, []VerificationMethodSet{"#key-1"}, doc.AssertionMethod)
+		assert.Equal(tt, []VerificationMethodSet{"did:example:123#key-2"}, doc.KeyAgreement)
+		assert.Empty(tt, doc.CapabilityInvocation)
+		assert.Empty(tt, doc.CapabilityDelegation)
+		assert.NoError(tt, doc.IsValid())
+
+		// each method can be dereferenced through the document
+		result := ResolutionResult{Document: doc}
+		vm, err := result.Dereference("did:example:123#key-2")
+		assert.NoError(tt, err)
+		assert.Equal(tt, "did:example:123#key-2", vm.ID)
+	})
+
+	t.Run("duplicate verification method ids", func(tt *testing.T) {
+		doc := Document{ID: "did:example:123"}
+		assert.NoError(tt, doc.AddVerificationMethod(newVM("#key-1"), AuthenticationRelationship))
+
+		// relative and absolute ids of the same method are duplicates
+		err := doc.AddVerificationMethod(newVM("did:example:123#key-1"), AssertionMethodRelationship)
+		assert.Error(tt, err)
+		assert.Contains(tt, err.Error(), "already has a verification method with id<did:example:123#key-1>")
+
+		// as are methods embedded in a relationship
+		doc.CapabilityInvocation = []VerificationMethodSet{newVM("#key-2")}
+		err = doc.AddVerificationMethod(newVM("#key-2"))
+		assert.Error(tt, err)
+		assert.Contains(tt, err.Error(), "already has a verification method with id<#key-2>")
+
+		// the document is left unchanged
+		assert.Len(tt, doc.VerificationMethod, 1)
+		assert.Equal(tt, []VerificationMethodSet{"#key-1"}, doc.Authentication)
+		assert.Empty(tt, doc.AssertionMethod)
+
+		err = doc.AddVerificationMethod(VerificationMethod{})
+		assert.Error(tt, err)
+		assert.Contains(tt, err.Error(), "verification method id cannot be empty")
+	})
+
+	t.Run("unknown relationship", func(tt *testing.T) {
+		doc := Document{ID: "did:example:123"}
+		err := doc.AddVerificationMethod(newVM("#key-1"), AuthenticationRelationship, "service")
+		assert.Error(tt, err)
+		assert.Contains(tt, err.Error(), "unknown verification relationship: service")
+		assert.Empty(tt, doc.VerificationMethod)
+		assert.Empty(tt, doc.Authentication)
+	})
+
+	t.Run("removal strips every relationship", func(tt *testing.T) {
+		doc := Document{ID: "did:example:123"}
+		assert.NoError(tt, doc.AddVerificationMethod(newVM("#key-1"), AuthenticationRelationship, AssertionMethodRelationship, CapabilityInvocationRelationship))
+		assert.NoError(tt, doc.AddVerificationMethod(newVM("#key-2"), AuthenticationRelationship))
+		doc.AssertionMethod = append(doc.AssertionMethod, "did:example:123#key-1")
+		doc.KeyAgreement = []VerificationMethodSet{newVM("#key-3")}
+
+		assert.NoError(tt, doc.RemoveVerificationMethod("did:example:123#key-1"))
+		assert.Equal(tt, []VerificationMethod{newVM("#key-2")}, doc.VerificationMethod)
+		assert.Equal(tt, []VerificationMethodSet{"#key-2"}, doc.Authentication)
+		assert.Empty(tt, doc.AssertionMethod)
+		assert.Empty(tt, doc.CapabilityInvocation)
+		assert.Len(tt, doc.KeyAgreement, 1)
+
+		// embedded methods are removed too
+		assert.NoError(tt, doc.RemoveVerificationMethod("#key-3"))
+		assert.Empty(tt, doc.KeyAgreement)
+
+		err := doc.RemoveVerificationMethod("#key-1")
+		assert.Error(tt, err)
+		assert.Contains(tt, err.Error(), "has no verification method with id<#key-1>")
+	})
+
+	t.Run("duplicate service ids", func(tt *testing.T) {
+		doc := Document{ID: "did:example:123"}
+		service := Service{ID: "#dwn", Type: "DecentralizedWebNode", ServiceEndpoint: "https://example.com/dwn"}
+		assert.NoError(tt, doc.AddService(service))
+		assert.NoError(tt, doc.AddService(Service{ID: "#linked-domain", Type: "LinkedDomains", ServiceEndpoint: "https://example.com"}))
+
+		service.ID = "did:example:123#dwn"
+		err := doc.AddService(service)
+		assert.Error(tt, err)
+		assert.Contains(tt, err.Error(), "already has a service with id<did:example:123#dwn>")
+		assert.Len(tt, doc.Services, 2)
+
+		err = doc.AddService(Service{Type: "LinkedDomains"})
+		assert.Error(tt, err)
+		assert.Contains(tt, err.Error(), "service id cannot be empty")
+	})
+}
+
+func TestHasVerificationRelationship(t *testing.T) {
+	docJSON := `{
+		"id": "did:example:123",
+		"verificationMethod": [
+			{"id": "#key-1", "type": "Multikey", "controller": "did:example:123", "publicKeyMultibase": "z6MkhaXgBZDvotDkL5257faiztiGiC2QtKLGpbnnEGta2doK"},
+			{"id": "did:example:123#key-2", "type": "Multikey", "controller": "did:example:123", "publicKeyMultibase": "z6MkhaXgBZDvotDkL5257faiztiGiC2QtKLGpbnnEGta2doK"}
+		],
+		"authentication": [
+			"did:example:123#key-1",
+			{"id": "#key-3", "type": "Multikey", "controller": "did:example:123", "publicKeyMultibase": "z6MkhaXgBZDvotDkL5257faiztiGiC2QtKLGpbnnEGta2doK"}
+		],
+		"assertionMethod": ["#key-2"]
+	}`
+	var doc Document
+	assert.NoError(t, json.Unmarshal([]byte(docJSON), &doc))
+
+	vectors := []struct {
+		id           string
+		relationship RelationshipType
+		listed       bool
+	}{
+		{id: "did:example:123#key-1", relationship: AuthenticationRelationship, listed: true},
+		{id: "#key-1", relationship: AuthenticationRelationship, listed: true},
+		{id: "#key-1", relationship: AssertionMethodRelationship, listed: false},
+		{id: "did:example:123#key-2", relationship: AssertionMethodRelationship, listed: true},
+		{id: "did:example:123#key-2", relationship: AuthenticationRelationship, listed: false},
+		{id: "did:example:123#key-3", relationship: AuthenticationRelationship, listed: true},
+		{id: "did:example:123#key-1", relationship: KeyAgreementRelationship, listed: false},
+		{id: "did:example:456#key-1", relationship: AuthenticationRelationship, listed: false},
+	}
+	for _, v := range vectors {
+		listed, err := doc.HasVerificationRelationship(v.id, v.relationship)
+		assert.NoError(t, err)
+		assert.Equal(t, v.listed, listed, "%s in %s", v.id, v.relationship)
+	}
+
+	_, err := doc.HasVerificationRelationship("#key-1", "controller")
+	assert.ErrorContains(t, err, "unknown verification relationship: controller")
+}
@@ -135,6 +135,20 @@ func (DIDPeer) generateKeyByType(kt crypto.KeyType) (gocrypto.PublicKey, gocrypt
 	return crypto.GenerateKeyByKeyType(kt)
 }
 
+// GenerateDIDPeer0 generates a new key of the given type and constructs a did:peer:0 DID from its public key, whose
+// suffix is the multibase encoded public key, as with did:key
+func GenerateDIDPeer0(kt crypto.KeyType) (gocrypto.PrivateKey, *DIDPeer, error) {
+	pubKey, privKey, err := DIDPeer("").generateKeyByType(kt)
+	if err != nil {
+		return nil, nil, errors.Wrap(err, "could not generate key for did:peer")
+	}
+	didPeer, err := PeerMethod0{kt: kt}.Generate(kt, pubKey)
+	if err != nil {
+		return nil, nil, errors.Wrap(err, "could not create did:peer")
+	}
+	return privKey, didPeer, nil
+}
+
 func (PeerMethod0) Generate(kt crypto.KeyType, publicKey gocrypto.PublicKey) (*DIDPeer, error) {
 	var did DIDPeer
 	encoded, err := encodePublicKeyWithKeyMultiCodecType(kt, publicKey)
@@ -202,6 +216,9 @@ func (PeerMethod0) resolve(did DID, _ ResolutionOption) (*ResolutionResult, erro
 	if err != nil {
 		return nil, err
 	}
+	if !strings.HasPrefix(v, string(rune(Base58BTCMultiBase))) {
+		return nil, fmt.Errorf("did:peer:0 key must be multibase encoded with the %q prefix", Base58BTCMultiBase)
+	}
 
 	pubKey, keyType, cryptoKeyType, err := decodeEncodedKey(v)
 	if err != nil {
@@ -221,13 +238,18 @@ func (PeerMethod0) resolve(did DID, _ ResolutionOption) (*ResolutionResult, erro
 	}
 
 	document := Document{
-		Context:              KnownDIDContext,
-		ID:                   id,
-		VerificationMethod:   []VerificationMethod{*verificationMethod},
-		Authentication:       verificationMethodSet,
-		AssertionMethod:      verificationMethodSet,
-		KeyAgreement:         verificationMethodSet,
-		CapabilityDelegation: verificationMethodSet,
+		Context:            KnownDIDContext,
+		ID:                 id,
+		VerificationMethod: []VerificationMethod{*verificationMethod},
+		KeyAgreement:       verificationMethodSet,
+	}
+
+	// as with did:key, X25519 keys can only be used for key agreement
+	if cryptoKeyType != crypto.X25519 {
+		document.Authentication = verificationMethodSet
+		document.AssertionMethod = verificationMethodSet
+		document.CapabilityInvocation = verificationMethodSet
+		document.CapabilityDelegation = verificationMethodSet
 	}
 	return &ResolutionResult{Document: document}, nil
 }
@@ -287,6 +309,9 @@ func (PeerMethod2) resolve(did DID, _ ResolutionOption) (*ResolutionResult, erro
 	}
 
 	for _, entry := range entries {
+		if entry == "" {
+			continue
+		}
 		serviceType := PurposeType(entry[0])
 		switch serviceType {
 		case PeerPurposeCapabilityServiceCode:
@@ -294,7 +319,7 @@ func (PeerMethod2) resolve(did DID, _ ResolutionOption) (*ResolutionResult, erro
 			if err != nil {
 				return nil, err
 			}
-			service.ID = string(d) + "#didcommmessaging-0"
+			service.ID = fmt.Sprintf("%s#didcommmessaging-%d", d, len(doc.Services))
 			doc.Services = append(doc.Services, *service)
 		case PeerPurposeEncryptionCode:
 			vm, err := d.buildVerificationMethod(entry[1:], string(d))
@@ -388,12 +413,63 @@ func (m PeerMethod2) Generate() (*DIDPeer, error) {
 	return &did, nil
 }
 
+// GenerateDIDPeer2 generates a key for each of the given authentication and key agreement key types, and constructs
+// a did:peer:2 DID encoding them with the V and E purpose codes respectively, followed by the optional service.
+// The private keys are returned in the same order as the provided key types.
+// https://identity.foundation/peer-did-method-spec/#generation-method
+func GenerateDIDPeer2(auth []crypto.KeyType, agreement []crypto.KeyType, service *Service) (authKeys []gocrypto.PrivateKey, agreementKeys []gocrypto.PrivateKey, didPeer *DIDPeer, err error) {
+	if len(auth) == 0 && len(agreement) == 0 {
+		return nil, nil, nil, errors.New("at least one key is required for did:peer:2")
+	}
+
+	var d DIDPeer
+	var encoded strings.Builder
+	generateKeys := func(purpose PurposeType, kts []crypto.KeyType) ([]gocrypto.PrivateKey, error) {
+		privKeys := make([]gocrypto.PrivateKey, 0, len(kts))
+		for i, kt := range kts {
+			pubKey, privKey, err := d.generateKeyByType(kt)
+			if err != nil {
+				return nil, errors.Wrapf(err, "generating key<%s> at index %d", kt, i)
+			}
+			enc, err := encodePublicKeyWithKeyMultiCodecType(kt, pubKey)
+			if err != nil {
+				return nil, errors.Wrapf(err, "encoding key<%s> at index %d", kt, i)
+			}
+			encoded.WriteString("." + string(purpose) + enc)
+			privKeys = append(privKeys, privKey)
+		}
+		return privKeys, nil
+	}
+
+	if authKeys, err = generateKeys(PeerPurposeVerificationCode, auth); err != nil {
+		return nil, nil, nil, errors.Wrap(err, "generating authentication keys for did:peer:2")
+	}
+	if agreementKeys, err = generateKeys(PeerPurposeEncryptionCode, agreement); err != nil {
+		return nil, nil, nil, errors.Wrap(err, "generating key agreement keys for did:peer:2")
+	}
+
+	// the service id is not encoded, since it is assigned on expansion
+	if service != nil {
+		if service.Type == "" || service.ServiceEndpoint == nil {
+			return nil, nil, nil, errors.New("service must have a type and service endpoint")
+		}
+		enc, err := d.encodeService(*service)
+		if err != nil {
+			return nil, nil, nil, errors.Wrap(err, "could not encode service for did:peer")
+		}
+		encoded.WriteString("." + string(PeerPurposeCapabilityServiceCode) + enc)
+	}
+
+	did := buildDIDPeerFromEncoded(2, encoded.String())
+	return authKeys, agreementKeys, &did, nil
+}
+
 // PeerServiceBlockEncoded Remaps the service block for encoding
 type PeerServiceBlockEncoded struct {
 	ServiceType     string   `json:"t"`
 	ServiceEndpoint string   `json:"s"`
-	RoutingKeys     []string `json:"r"`
-	Accept          []string `json:"a"`
+	RoutingKeys     []string `json:"r,omitempty"`
+	Accept          []string `json:"a,omitempty"`
 }
 
 // Start with the JSON structure for your service.
@@ -405,10 +481,14 @@ func (DIDPeer) encodeService(p Service) (string, error) {
 	if p.ServiceEndpoint == nil {
 		return "", errors.Wrap(util.UndefinedError, "service endpoint is not defined")
 	}
+	endpoint, ok := p.ServiceEndpoint.(string)
+	if !ok {
+		return "", errors.Wrap(util.UnsupportedError, "encoding a non-string service endpoint")
+	}
 
 	serviceBlock := PeerServiceBlockEncoded{
 		ServiceType:     p.Type,
-		ServiceEndpoint: p.ServiceEndpoint.(string),
+		ServiceEndpoint: endpoint,
 		RoutingKeys:     p.RoutingKeys,
 		Accept:          p.Accept,
 	}
@@ -511,6 +591,15 @@ func peerMethodAvailable(m string) bool {
 	}
 }
 
+// Expand turns the did:peer into a DID Document, for the supported numalgos 0 and 2
+func (d DIDPeer) Expand() (*Document, error) {
+	resolved, err := PeerResolver{}.Resolve(context.Background(), d.String())
+	if err != nil {
+		return nil, errors.Wrap(err, "could not expand did:peer")
+	}
+	return &resolved.Document, nil
+}
+
 type PeerResolver struct{}
 
 var _ Resolver = (*PeerResolver)(nil)
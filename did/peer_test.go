@@ -2,9 +2,12 @@ package did
 
 import (
 	"context"
+	"crypto/ed25519"
+	"strings"
 	"testing"
 
 	"github.com/TBD54566975/ssi-sdk/crypto"
+	"github.com/TBD54566975/ssi-sdk/cryptosuite"
 	"github.com/stretchr/testify/assert"
 )
 
@@ -195,6 +198,53 @@ func TestPeerMethod0(t *testing.T) {
 	assert.Equal(t, testDoc.Context, resolved.Document.Context)
 }
 
+func TestGenerateDIDPeer0(t *testing.T) {
+	t.Run("round trips through expansion", func(tt *testing.T) {
+		for _, kt := range GetSupportedDIDKeyTypes() {
+			privKey, didPeer, err := GenerateDIDPeer0(kt)
+			assert.NoError(tt, err, "key type: %s", kt)
+			assert.NotEmpty(tt, privKey)
+			assert.True(tt, strings.HasPrefix(didPeer.String(), DIDPeerPrefix+":0z"))
+
+			doc, err := didPeer.Expand()
+			assert.NoError(tt, err, "key type: %s", kt)
+			assert.Equal(tt, didPeer.String(), doc.ID)
+			assert.Len(tt, doc.VerificationMethod, 1)
+			assert.NotEmpty(tt, doc.KeyAgreement)
+			if kt == crypto.X25519 {
+				assert.Empty(tt, doc.Authentication)
+				assert.Empty(tt, doc.AssertionMethod)
+			} else {
+				assert.NotEmpty(tt, doc.Authentication)
+				assert.NotEmpty(tt, doc.AssertionMethod)
+				assert.NotEmpty(tt, doc.CapabilityInvocation)
+				assert.NotEmpty(tt, doc.CapabilityDelegation)
+			}
+		}
+	})
+
+	t.Run("matches did:key encoding", func(tt *testing.T) {
+		_, didPeer, err := GenerateDIDPeer0(crypto.Ed25519)
+		assert.NoError(tt, err)
+		suffix, err := didPeer.Suffix()
+		assert.NoError(tt, err)
+
+		_, _, _, err = DIDKey(KeyPrefix + ":" + suffix).Decode()
+		assert.NoError(tt, err)
+	})
+
+	t.Run("unsupported key type", func(tt *testing.T) {
+		_, _, err := GenerateDIDPeer0(crypto.KeyType("bad"))
+		assert.Error(tt, err)
+	})
+
+	t.Run("rejects non base58btc suffix", func(tt *testing.T) {
+		_, err := DIDPeer("did:peer:0m6MkpTHR8VNsBxYAAWHut2Geadd9jSwuBV8xRoAnwWsdvktH").Expand()
+		assert.Error(tt, err)
+		assert.Contains(tt, err.Error(), "multibase encoded with the 'z' prefix")
+	})
+}
+
 func TestPeerMethod2(t *testing.T) {
 	var d DIDPeer
 	kt := crypto.Ed25519
@@ -217,6 +267,78 @@ func TestPeerMethod2(t *testing.T) {
 	assert.True(t, did.IsValid())
 }
 
+func TestGenerateDIDPeer2(t *testing.T) {
+	t.Run("round trips keys and service through expansion", func(tt *testing.T) {
+		service := Service{
+			Type:            PeerDIDCommMessaging,
+			ServiceEndpoint: "https://example.com/endpoint",
+			RoutingKeys:     []string{"did:example:somemediator#somekey"},
+			Accept:          []string{"didcomm/v2"},
+		}
+		authKeys, agreementKeys, didPeer, err := GenerateDIDPeer2([]crypto.KeyType{crypto.Ed25519, crypto.Ed25519}, []crypto.KeyType{crypto.X25519}, &service)
+		assert.NoError(tt, err)
+		assert.Len(tt, authKeys, 2)
+		assert.Len(tt, agreementKeys, 1)
+		assert.True(tt, didPeer.IsValid())
+
+		doc, err := didPeer.Expand()
+		assert.NoError(tt, err)
+		assert.Equal(tt, didPeer.String(), doc.ID)
+
+		assert.Len(tt, doc.Authentication, 2)
+		for i, authKey := range authKeys {
+			vm := doc.Authentication[i].(VerificationMethod)
+			assert.Equal(tt, cryptosuite.Ed25519VerificationKey2020, vm.Type)
+			assert.Equal(tt, didPeer.String(), vm.Controller)
+
+			pubKeyBytes, err := crypto.PubKeyToBytes(authKey.(ed25519.PrivateKey).Public())
+			assert.NoError(tt, err)
+			decoded, err := multibaseToPubKeyBytes(vm.PublicKeyMultibase)
+			assert.NoError(tt, err)
+			assert.Equal(tt, pubKeyBytes, decoded)
+		}
+
+		assert.Len(tt, doc.KeyAgreement, 1)
+		assert.Equal(tt, cryptosuite.X25519KeyAgreementKey2020, doc.KeyAgreement[0].(VerificationMethod).Type)
+
+		assert.Len(tt, doc.Services, 1)
+		assert.Equal(tt, didPeer.String()+"#didcommmessaging-0", doc.Services[0].ID)
+		assert.Equal(tt, PeerDIDCommMessaging, doc.Services[0].Type)
+		assert.Equal(tt, service.ServiceEndpoint, doc.Services[0].ServiceEndpoint)
+		assert.Equal(tt, service.RoutingKeys, doc.Services[0].RoutingKeys)
+		assert.Equal(tt, service.Accept, doc.Services[0].Accept)
+	})
+
+	t.Run("service is optional", func(tt *testing.T) {
+		_, _, didPeer, err := GenerateDIDPeer2([]crypto.KeyType{crypto.Ed25519}, nil, nil)
+		assert.NoError(tt, err)
+
+		doc, err := didPeer.Expand()
+		assert.NoError(tt, err)
+		assert.Len(tt, doc.Authentication, 1)
+		assert.Empty(tt, doc.KeyAgreement)
+		assert.Empty(tt, doc.Services)
+	})
+
+	t.Run("requires a key", func(tt *testing.T) {
+		_, _, _, err := GenerateDIDPeer2(nil, nil, nil)
+		assert.Error(tt, err)
+		assert.Contains(tt, err.Error(), "at least one key is required")
+	})
+
+	t.Run("invalid service", func(tt *testing.T) {
+		_, _, _, err := GenerateDIDPeer2([]crypto.KeyType{crypto.Ed25519}, nil, &Service{Type: PeerDIDCommMessaging})
+		assert.Error(tt, err)
+		assert.Contains(tt, err.Error(), "service must have a type and service endpoint")
+	})
+
+	t.Run("unsupported key type", func(tt *testing.T) {
+		_, _, _, err := GenerateDIDPeer2(nil, []crypto.KeyType{"bad"}, nil)
+		assert.Error(tt, err)
+		assert.Contains(tt, err.Error(), "generating key agreement keys")
+	})
+}
+
 func TestPeerMethod1(t *testing.T) {
 	var m1 PeerMethod1
 	_, err := m1.Generate()
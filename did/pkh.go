@@ -1,15 +1,21 @@
 package did
 
 import (
+	"bytes"
 	"context"
+	"crypto/sha256"
 	"embed"
+	"encoding/hex"
 	"fmt"
 	"regexp"
 	"strings"
 
+	"github.com/mr-tron/base58"
+	"github.com/pkg/errors"
+	"golang.org/x/crypto/sha3"
+
 	"github.com/TBD54566975/ssi-sdk/cryptosuite"
 	"github.com/TBD54566975/ssi-sdk/util"
-	"github.com/pkg/errors"
 )
 
 type (
@@ -34,6 +40,12 @@ const (
 	Polygon  Network = "Polygon"
 )
 
+// CAIP-2 namespaces with address validation https://github.com/ChainAgnostic/namespaces
+const (
+	EIP155Namespace = "eip155"
+	BIP122Namespace = "bip122"
+)
+
 const (
 	BitcoinNetworkPrefix  = "bip122:000000000019d6689c085ae165831e93"
 	EthereumNetworkPrefix = "eip155:1"
@@ -61,12 +73,16 @@ func CreateDIDPKHFromNetwork(network Network, address string) (*DIDPKH, error) {
 
 // CreateDIDPKH constructs a did:pkh from a namespace, reference, and account address.
 // Reference: did:pkh:namespace:reference:account_address
+// Addresses in the eip155 and bip122 namespaces are additionally validated against their checksums.
 func CreateDIDPKH(namespace, reference, address string) (*DIDPKH, error) {
 	did := DIDPKH(fmt.Sprintf("%s:%s:%s:%s", DIDPKHPrefix, namespace, reference, address))
 
 	if !IsValidPKH(did) {
 		return nil, fmt.Errorf("PKH DID is not valid: %s", string(did))
 	}
+	if err := validatePKHAddress(namespace, address); err != nil {
+		return nil, errors.Wrapf(err, "PKH DID is not valid: %s", string(did))
+	}
 
 	return &did, nil
 }
@@ -138,6 +154,15 @@ func GetVerificationTypeForNetwork(n Network) (string, error) {
 	return "", fmt.Errorf("unsupported did:pkh network: %s", n)
 }
 
+// GetVerificationTypeForNamespace returns the verification key type for a given CAIP-2 namespace
+func GetVerificationTypeForNamespace(namespace string) (string, error) {
+	switch namespace {
+	case EIP155Namespace, BIP122Namespace:
+		return ECDSASECP256k1RecoveryMethod2020, nil
+	}
+	return "", fmt.Errorf("unsupported did:pkh namespace: %s", namespace)
+}
+
 func GetSupportedPKHNetworks() []Network {
 	return []Network{Bitcoin, Ethereum, Polygon}
 }
@@ -181,25 +206,24 @@ func (d DIDPKH) Expand() (*Document, error) {
 
 func constructPKHVerificationMethod(did DIDPKH) (*VerificationMethod, error) {
 	if !IsValidPKH(did) {
-		parsed, err := did.Suffix()
-		if err != nil || parsed == "" {
-			return nil, errors.Wrap(err, "PKH DID is not valid")
-		}
+		return nil, fmt.Errorf("PKH DID is not valid: %s", string(did))
 	}
 
-	network, err := GetDIDPKHNetworkForDID(did.String())
+	// the suffix is the CAIP-10 account id of the form namespace:reference:account_address
+	suffix, err := did.Suffix()
 	if err != nil {
-		return nil, errors.Wrap(err, "could not find network")
+		return nil, err
+	}
+	accountID := strings.Split(suffix, ":")
+	namespace, address := accountID[0], accountID[2]
+	if err = validatePKHAddress(namespace, address); err != nil {
+		return nil, errors.Wrap(err, "PKH DID is not valid")
 	}
-	verificationType, err := GetVerificationTypeForNetwork(network)
+	verificationType, err := GetVerificationTypeForNamespace(namespace)
 	if err != nil {
 		return nil, errors.Wrap(err, "could not find verification type")
 	}
 
-	suffix, err := did.Suffix()
-	if err != nil {
-		return nil, err
-	}
 	return &VerificationMethod{
 		ID:                  string(did) + "#blockchainAccountId",
 		Type:                cryptosuite.LDKeyType(verificationType),
@@ -223,26 +247,74 @@ func IsValidPKH(did DIDPKH) bool {
 	if len(split) != 5 || (split[0]+":"+split[1]) != DIDPKHPrefix {
 		return false
 	}
+	return pkhNamespaceRegex.MatchString(split[2]) &&
+		pkhReferenceRegex.MatchString(split[3]) &&
+		pkhAddressRegex.MatchString(split[4])
+}
 
-	// namespace
-	matched, err := regexp.MatchString(`[-a-z0-9]{3,8}`, split[2])
-	if !matched || err != nil {
-		return false
+var (
+	pkhNamespaceRegex  = regexp.MustCompile(`^[-a-z0-9]{3,8}$`)
+	pkhReferenceRegex  = regexp.MustCompile(`^[-a-zA-Z0-9]{1,32}$`)
+	pkhAddressRegex    = regexp.MustCompile(`^[a-zA-Z0-9]{1,64}$`)
+	eip155AddressRegex = regexp.MustCompile(`^0x[0-9a-fA-F]{40}$`)
+	bech32AddressRegex = regexp.MustCompile(`^[a-z0-9]+1[qpzry9x8gf2tvdw0s3jn54khce6mua7l]{6,}$`)
+)
+
+// validatePKHAddress checks the account address is well-formed for the namespaces that support validation,
+// accepting any address that matches the CAIP-10 grammar for other namespaces
+func validatePKHAddress(namespace, address string) error {
+	switch namespace {
+	case EIP155Namespace:
+		return validateEIP155Address(address)
+	case BIP122Namespace:
+		return validateBIP122Address(address)
 	}
+	return nil
+}
 
-	// reference
-	matched, err = regexp.MatchString(`[-a-zA-Z0-9]{1,32}`, split[3])
-	if !matched || err != nil {
-		return false
+// validateEIP155Address checks for a hex encoded 20 byte address, verifying its checksum if it is mixed-case
+// https://eips.ethereum.org/EIPS/eip-55
+func validateEIP155Address(address string) error {
+	if !eip155AddressRegex.MatchString(address) {
+		return fmt.Errorf("invalid eip155 address: %s", address)
+	}
+	hexAddress := address[2:]
+	if hexAddress == strings.ToLower(hexAddress) || hexAddress == strings.ToUpper(hexAddress) {
+		return nil
 	}
 
-	// account_address
-	matched, err = regexp.MatchString(`[a-zA-Z0-9]{1,64}`, split[4])
-	if !matched || err != nil {
-		return false
+	hash := sha3.NewLegacyKeccak256()
+	hash.Write([]byte(strings.ToLower(hexAddress)))
+	digest := hex.EncodeToString(hash.Sum(nil))
+	for i, c := range hexAddress {
+		if c >= '0' && c <= '9' {
+			continue
+		}
+		// a letter is uppercase if and only if the corresponding nibble of the hash is 8 or greater
+		if upper := c >= 'A' && c <= 'F'; upper != (digest[i] >= '8') {
+			return fmt.Errorf("invalid eip155 address checksum: %s", address)
+		}
 	}
+	return nil
+}
 
-	return true
+// validateBIP122Address checks for either a bech32 address or a base58check encoded address with a valid checksum
+// https://en.bitcoin.it/wiki/Base58Check_encoding
+func validateBIP122Address(address string) error {
+	if bech32AddressRegex.MatchString(address) {
+		return nil
+	}
+	decoded, err := base58.Decode(address)
+	if err != nil || len(decoded) < 5 {
+		return fmt.Errorf("invalid bip122 address: %s", address)
+	}
+	payload, checksum := decoded[:len(decoded)-4], decoded[len(decoded)-4:]
+	first := sha256.Sum256(payload)
+	second := sha256.Sum256(first[:])
+	if !bytes.Equal(second[:4], checksum) {
+		return fmt.Errorf("invalid bip122 address checksum: %s", address)
+	}
+	return nil
 }
 
 type PKHResolver struct{}
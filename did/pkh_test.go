@@ -1,12 +1,15 @@
 package did
 
 import (
+	"context"
 	"embed"
 	"strings"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
 
+	"github.com/TBD54566975/ssi-sdk/cryptosuite"
+
 	"github.com/goccy/go-json"
 )
 
@@ -113,6 +116,63 @@ func TestCreateDIDPKH(t *testing.T) {
 		assert.Error(tt, err)
 		assert.Contains(tt, err.Error(), "unsupported did:pkh network: bad")
 	})
+
+	t.Run("Test EIP155 Checksum Address", func(tt *testing.T) {
+		// from https://eips.ethereum.org/EIPS/eip-55#test-cases
+		address := "0x5aAeb6053F3E94C9b9A09f33669435E7Ef1BeAed"
+		didPKH, err := CreateDIDPKH(EIP155Namespace, "10", address)
+		assert.NoError(tt, err)
+
+		didDoc, err := didPKH.Expand()
+		assert.NoError(tt, err)
+		assert.Len(tt, didDoc.VerificationMethod, 1)
+		vm := didDoc.VerificationMethod[0]
+		assert.Equal(tt, cryptosuite.LDKeyType(ECDSASECP256k1RecoveryMethod2020), vm.Type)
+		assert.Equal(tt, strings.Join([]string{EIP155Namespace, "10", address}, ":"), vm.BlockchainAccountID)
+
+		resolved, err := PKHResolver{}.Resolve(context.Background(), didPKH.String())
+		assert.NoError(tt, err)
+		assert.Equal(tt, didPKH.String(), resolved.Document.ID)
+	})
+
+	t.Run("Test EIP155 Bad Checksum", func(tt *testing.T) {
+		address := "0x5aaeb6053F3E94C9b9A09f33669435E7Ef1BeAed"
+		_, err := CreateDIDPKH(EIP155Namespace, "1", address)
+		assert.Error(tt, err)
+		assert.Contains(tt, err.Error(), "invalid eip155 address checksum")
+
+		_, err = PKHResolver{}.Resolve(context.Background(), "did:pkh:eip155:1:"+address)
+		assert.Error(tt, err)
+		assert.Contains(tt, err.Error(), "invalid eip155 address checksum")
+	})
+
+	t.Run("Test EIP155 Malformed Address", func(tt *testing.T) {
+		_, err := CreateDIDPKH(EIP155Namespace, "1", "0x1234")
+		assert.Error(tt, err)
+		assert.Contains(tt, err.Error(), "invalid eip155 address")
+	})
+
+	t.Run("Test BIP122 Addresses", func(tt *testing.T) {
+		didPKH, err := CreateDIDPKHFromNetwork(Bitcoin, "128Lkh3S7CkDTBZ8W7BbpsN3YYizJMp8p6")
+		assert.NoError(tt, err)
+		_, err = didPKH.Expand()
+		assert.NoError(tt, err)
+
+		_, err = CreateDIDPKHFromNetwork(Bitcoin, "bc1qar0srrr7xfkvy5l643lydnw9re59gtzzwf5mdq")
+		assert.NoError(tt, err)
+
+		_, err = CreateDIDPKHFromNetwork(Bitcoin, "128Lkh3S7CkDTBZ8W7BbpsN3YYizJMp8p7")
+		assert.Error(tt, err)
+		assert.Contains(tt, err.Error(), "invalid bip122 address checksum")
+	})
+
+	t.Run("Test Unsupported Namespace Expansion", func(tt *testing.T) {
+		didPKH, err := CreateDIDPKH("solana", "4sGjMW1sUnHzSxGspuhpqLDx6wiyjNtZ", "CKg5d12Jhpej1JqtmxLJgaFqqeYjxgPqToJ4LBdvG9Ev")
+		assert.NoError(tt, err)
+		_, err = didPKH.Expand()
+		assert.Error(tt, err)
+		assert.Contains(tt, err.Error(), "unsupported did:pkh namespace: solana")
+	})
 }
 
 func TestIsValidPKH(t *testing.T) {
@@ -133,12 +193,20 @@ func TestIsValidPKH(t *testing.T) {
 	assert.False(t, IsValidPKH("did:pkh:eip155:1:"))
 	assert.False(t, IsValidPKH("did:pkh:eip155::0xb9c5714089478a327f09197987f16f9e5d936e8a"))
 	assert.False(t, IsValidPKH("did:pkh:NOCAP:1:0xb9c5714089478a327f09197987f16f9e5d936e8a"))
+	assert.False(t, IsValidPKH("did:pkh:eip155:1:0xb9c5714089478a327f09197987f16f9e5d936e8a!"))
+	assert.False(t, IsValidPKH("did:pkh:toolongnamespace:1:0xb9c5714089478a327f09197987f16f9e5d936e8a"))
 }
 
 func TestGetNetwork(t *testing.T) {
 	t.Run("Test Known Networks", func(tt *testing.T) {
+		// addresses must be valid for their network
+		addresses := map[Network]string{
+			Bitcoin:  "128Lkh3S7CkDTBZ8W7BbpsN3YYizJMp8p6",
+			Ethereum: "0xb9c5714089478a327f09197987f16f9e5d936e8a",
+			Polygon:  "0xb9c5714089478a327f09197987f16f9e5d936e8a",
+		}
 		for network := range pkhTestVectors {
-			didPKH, err := CreateDIDPKHFromNetwork(network, "dummyaddress")
+			didPKH, err := CreateDIDPKHFromNetwork(network, addresses[network])
 			assert.NoError(t, err)
 
 			n, err := GetDIDPKHNetworkForDID(didPKH.String())
@@ -3,7 +3,8 @@ package did
 import (
 	"context"
 	"fmt"
-	"strings"
+	"sync"
+	"time"
 
 	"github.com/goccy/go-json"
 	"github.com/pkg/errors"
@@ -20,17 +21,68 @@ type Resolver interface {
 	Methods() []Method
 }
 
-// MultiMethodResolver resolves a DID. The current implementation ssk-sdk does not have a universal resolver:
+// ErrMethodNotSupported is returned when resolving a DID whose method has no registered resolver
+var ErrMethodNotSupported = errors.New("unsupported method")
+
+// ErrMethodNotPermitted is returned when resolving a DID whose method is excluded by the policy of a MultiResolver
+var ErrMethodNotPermitted = errors.New("method not permitted")
+
+// MultiResolver resolves a DID. The current implementation ssk-sdk does not have a universal resolver:
 // https://github.com/decentralized-identity/universal-resolver
 // In its place, this method attempts to resolve DID methods that can be resolved without relying on additional services.
-type MultiMethodResolver struct {
+// Each DID is dispatched to the resolver registered for its method.
+type MultiResolver struct {
 	resolvers map[Method]Resolver
 	methods   []Method
+	// allowed and denied are the method policy of the resolver; an empty allowed set permits every method
+	allowed map[Method]bool
+	denied  map[Method]bool
+}
+
+// MultiMethodResolver is the previous name of MultiResolver.
+// Deprecated: use MultiResolver instead.
+type MultiMethodResolver = MultiResolver
+
+var _ Resolver = (*MultiResolver)(nil)
+
+// MultiResolverOption configures a MultiResolver
+type MultiResolverOption func(*MultiResolver)
+
+// WithAllowedMethods restricts the resolver to DIDs of the given methods, in addition to any methods denied with
+// WithDeniedMethods. Every registered method is allowed by default.
+func WithAllowedMethods(methods ...Method) MultiResolverOption {
+	return func(r *MultiResolver) {
+		if r.allowed == nil {
+			r.allowed = make(map[Method]bool, len(methods))
+		}
+		for _, m := range methods {
+			r.allowed[m] = true
+		}
+	}
 }
 
-var _ Resolver = (*MultiMethodResolver)(nil)
+// WithDeniedMethods prevents the resolver from resolving DIDs of the given methods, even if they are allowed with
+// WithAllowedMethods
+func WithDeniedMethods(methods ...Method) MultiResolverOption {
+	return func(r *MultiResolver) {
+		if r.denied == nil {
+			r.denied = make(map[Method]bool, len(methods))
+		}
+		for _, m := range methods {
+			r.denied[m] = true
+		}
+	}
+}
 
-func NewResolver(resolvers ...Resolver) (*MultiMethodResolver, error) {
+// NewMultiResolver creates a MultiResolver indexed by the methods each of the given resolvers advertises.
+// An error is returned if more than one resolver is registered for the same method.
+func NewMultiResolver(resolvers ...Resolver) (*MultiResolver, error) {
+	return NewMultiResolverWithOptions(resolvers)
+}
+
+// NewMultiResolverWithOptions creates a MultiResolver as NewMultiResolver does, configured with the given options,
+// such as a policy of the DID methods it may resolve
+func NewMultiResolverWithOptions(resolvers []Resolver, opts ...MultiResolverOption) (*MultiResolver, error) {
 	r := make(map[Method]Resolver)
 	var methods []Method
 	for _, resolver := range resolvers {
@@ -43,32 +95,217 @@ func NewResolver(resolvers ...Resolver) (*MultiMethodResolver, error) {
 			methods = append(methods, m)
 		}
 	}
-	return &MultiMethodResolver{resolvers: r, methods: methods}, nil
+	multiResolver := MultiResolver{resolvers: r, methods: methods}
+	for _, opt := range opts {
+		opt(&multiResolver)
+	}
+	return &multiResolver, nil
 }
 
-// Resolve attempts to resolve a DID for a given method
-func (dr MultiMethodResolver) Resolve(ctx context.Context, did string, opts ...ResolutionOption) (*ResolutionResult, error) {
+// NewResolver creates a MultiResolver for the given resolvers. It is equivalent to NewMultiResolver.
+func NewResolver(resolvers ...Resolver) (*MultiResolver, error) {
+	return NewMultiResolver(resolvers...)
+}
+
+// Resolve attempts to resolve a DID for a given method. If the DID is not valid, a *ResolutionError with code
+// InvalidDIDErrorCode is returned. If the DID's method is not permitted by the resolver's policy, a *ResolutionError
+// with code MethodNotSupportedErrorCode wrapping ErrMethodNotPermitted is returned, before any resolver is called.
+// If no resolver is registered for the DID's method, a *ResolutionError with code MethodNotSupportedErrorCode
+// wrapping ErrMethodNotSupported is returned.
+func (dr MultiResolver) Resolve(ctx context.Context, did string, opts ...ResolutionOption) (*ResolutionResult, error) {
 	method, err := GetMethodForDID(did)
 	if err != nil {
-		return nil, errors.Wrap(err, "failed to get method for DID before resolving")
+		return nil, NewResolutionError(InvalidDIDErrorCode, did, errors.Wrap(err, "failed to get method for DID before resolving"))
+	}
+	if !dr.isPermitted(method) {
+		return nil, NewResolutionError(MethodNotSupportedErrorCode, did, fmt.Errorf("%w: %s", ErrMethodNotPermitted, method))
 	}
 	if resolver, ok := dr.resolvers[method]; ok {
-		return resolver.Resolve(ctx, did, opts)
+		return resolver.Resolve(ctx, did, opts...)
 	}
-	return nil, fmt.Errorf("unsupported method: %s", method)
+	return nil, NewResolutionError(MethodNotSupportedErrorCode, did, fmt.Errorf("%w: %s", ErrMethodNotSupported, method))
 }
 
-func (dr MultiMethodResolver) Methods() []Method {
+func (dr MultiResolver) Methods() []Method {
 	return dr.methods
 }
 
+// isPermitted determines whether the resolver's policy permits resolving DIDs of the method. Denied methods take
+// precedence over allowed ones.
+func (dr MultiResolver) isPermitted(method Method) bool {
+	if dr.denied[method] {
+		return false
+	}
+	return len(dr.allowed) == 0 || dr.allowed[method]
+}
+
+// ResolveBatch resolves each of the given DIDs with the resolver, using at most concurrency resolutions at a time.
+// Each DID is resolved once, no matter how many times it appears in the input. The results and errors are keyed by
+// DID, so that a DID that fails to resolve does not fail the batch. If the context is done before a DID is resolved,
+// its error is the context's error.
+func ResolveBatch(ctx context.Context, r Resolver, dids []string, concurrency int) (map[string]*ResolutionResult, map[string]error) {
+	results := make(map[string]*ResolutionResult)
+	errs := make(map[string]error)
+	if r == nil {
+		for _, d := range dids {
+			errs[d] = errors.New("resolver cannot be nil")
+		}
+		return results, errs
+	}
+	if concurrency < 1 {
+		concurrency = 1
+	}
+
+	var unique []string
+	seen := make(map[string]bool)
+	for _, d := range dids {
+		if !seen[d] {
+			seen[d] = true
+			unique = append(unique, d)
+		}
+	}
+
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+	queue := make(chan string)
+	for i := 0; i < concurrency && i < len(unique); i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for d := range queue {
+				var result *ResolutionResult
+				err := ctx.Err()
+				if err == nil {
+					result, err = r.Resolve(ctx, d)
+				}
+				mu.Lock()
+				if err != nil {
+					errs[d] = err
+				} else {
+					results[d] = result
+				}
+				mu.Unlock()
+			}
+		}()
+	}
+
+	// stop handing out DIDs once the context is done, and mark those left with the context's error
+dispatch:
+	for i, d := range unique {
+		select {
+		case queue <- d:
+		case <-ctx.Done():
+			mu.Lock()
+			for _, remaining := range unique[i:] {
+				errs[remaining] = ctx.Err()
+			}
+			mu.Unlock()
+			break dispatch
+		}
+	}
+	close(queue)
+	wg.Wait()
+	return results, errs
+}
+
+// ResolverHooks observes the resolutions of a resolver wrapped with InstrumentResolver, such as to record metrics or
+// tracing spans. Hooks are called synchronously, so they should not block.
+type ResolverHooks interface {
+	// OnStart is called before a DID is resolved
+	OnStart(did string)
+	// OnSuccess is called after a DID is resolved, with the duration of its resolution
+	OnSuccess(did string, d time.Duration)
+	// OnError is called after a DID fails to resolve, with the duration of its resolution and the error returned
+	OnError(did string, d time.Duration, err error)
+}
+
+// instrumentedResolver is a resolver calling hooks around each resolution of the resolver it wraps
+type instrumentedResolver struct {
+	resolver Resolver
+	hooks    ResolverHooks
+}
+
+var _ Resolver = (*instrumentedResolver)(nil)
+
+// InstrumentResolver wraps a resolver so that the hooks are called around each of its resolutions. The context and
+// resolution options are passed through to the wrapped resolver, and its methods are preserved.
+func InstrumentResolver(r Resolver, hooks ResolverHooks) Resolver {
+	if hooks == nil {
+		return r
+	}
+	return &instrumentedResolver{resolver: r, hooks: hooks}
+}
+
+func (ir *instrumentedResolver) Resolve(ctx context.Context, did string, opts ...ResolutionOption) (*ResolutionResult, error) {
+	ir.hooks.OnStart(did)
+	start := time.Now()
+	result, err := ir.resolver.Resolve(ctx, did, opts...)
+	if err != nil {
+		ir.hooks.OnError(did, time.Since(start), err)
+		return nil, err
+	}
+	ir.hooks.OnSuccess(did, time.Since(start))
+	return result, nil
+}
+
+func (ir *instrumentedResolver) Methods() []Method {
+	return ir.resolver.Methods()
+}
+
+// acceptOption is the ResolutionOption returned by WithAccept
+type acceptOption string
+
+// WithAccept is a ResolutionOption specifying the media type of the preferred representation of the DID Document
+// https://www.w3.org/TR/did-spec-registries/#accept
+func WithAccept(mediaType string) ResolutionOption {
+	return acceptOption(mediaType)
+}
+
+// ResolutionOptions are the standard resolution options shared by all resolvers
+// https://www.w3.org/TR/did-spec-registries/#did-resolution-options
+type ResolutionOptions struct {
+	// Accept is the media type of the requested representation, which defaults to DIDJSONLDMediaType
+	Accept string
+}
+
+// IncludeContext reports whether the requested representation carries a JSON-LD @context
+func (o ResolutionOptions) IncludeContext() bool {
+	return o.Accept != DIDJSONMediaType
+}
+
+// ParseResolutionOptions extracts the standard resolution options from the given options, ignoring any options
+// specific to a method. When an option is given more than once the last value wins. An error is returned if the
+// requested representation is neither DIDJSONMediaType nor DIDJSONLDMediaType.
+func ParseResolutionOptions(opts []ResolutionOption) (*ResolutionOptions, error) {
+	options := ResolutionOptions{Accept: DIDJSONLDMediaType}
+	for _, opt := range opts {
+		if a, ok := opt.(acceptOption); ok {
+			options.Accept = string(a)
+		}
+	}
+	if options.Accept != DIDJSONLDMediaType && options.Accept != DIDJSONMediaType {
+		return nil, fmt.Errorf("representation not supported: %s", options.Accept)
+	}
+	return &options, nil
+}
+
+// hasResolutionOption checks whether the target option is present in the provided resolution options
+func hasResolutionOption(opts []ResolutionOption, target ResolutionOption) bool {
+	for _, opt := range opts {
+		if opt == target {
+			return true
+		}
+	}
+	return false
+}
+
 // GetMethodForDID provides the method for the given did string
 func GetMethodForDID(did string) (Method, error) {
-	split := strings.Split(did, ":")
-	if len(split) < 3 {
-		return "", fmt.Errorf("not a valid did: %s", did)
+	parsed, err := ParseDID(did)
+	if err != nil {
+		return "", errors.Wrap(err, "not a valid did")
 	}
-	return Method(split[1]), nil
+	return parsed.Method, nil
 }
 
 // ParseDIDResolution attempts to parse a DID Resolution Result or a DID Document
@@ -77,13 +314,10 @@ func ParseDIDResolution(resolvedDID []byte) (*ResolutionResult, error) {
 		return nil, errors.New("cannot parse empty resolved DID")
 	}
 
-	// first try to parse as a DID Resolver Result
+	// first try to parse as a DID Resolver Result, which is empty if the input is a bare DID Document
 	var result ResolutionResult
-	if err := json.Unmarshal(resolvedDID, &result); err == nil {
-		if result.IsEmpty() {
-			return nil, errors.New("empty DID Resolution Result")
-		}
-		return &result, err
+	if err := json.Unmarshal(resolvedDID, &result); err == nil && !result.IsEmpty() {
+		return &result, nil
 	}
 
 	// next try to parse as a DID Document
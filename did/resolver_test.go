@@ -2,10 +2,14 @@ package did
 
 import (
 	"context"
+	"strings"
+	"sync"
 	"testing"
+	"time"
 
 	"github.com/TBD54566975/ssi-sdk/crypto"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 	"gopkg.in/h2non/gock.v1"
 )
 
@@ -56,6 +60,322 @@ func TestResolveDID(t *testing.T) {
 	assert.NotEmpty(t, doc)
 }
 
+// stubResolver resolves any DID of its method to a document containing only the DID's identifier
+type stubResolver struct {
+	method Method
+}
+
+func (s stubResolver) Resolve(_ context.Context, did string, _ ...ResolutionOption) (*ResolutionResult, error) {
+	return &ResolutionResult{Document: Document{ID: did}}, nil
+}
+
+func (s stubResolver) Methods() []Method {
+	return []Method{s.method}
+}
+
+func TestMultiResolver(t *testing.T) {
+	t.Run("dispatches by method", func(tt *testing.T) {
+		resolver, err := NewMultiResolver(JWKResolver{}, stubResolver{method: "stub"})
+		assert.NoError(tt, err)
+		assert.ElementsMatch(tt, []Method{JWKMethod, "stub"}, resolver.Methods())
+
+		_, didJWK, err := GenerateDIDJWK(crypto.Ed25519)
+		assert.NoError(tt, err)
+		resolved, err := resolver.Resolve(context.Background(), didJWK.String())
+		assert.NoError(tt, err)
+		assert.Equal(tt, didJWK.String(), resolved.Document.ID)
+		assert.NotEmpty(tt, resolved.Document.VerificationMethod)
+
+		resolved, err = resolver.Resolve(context.Background(), "did:stub:123")
+		assert.NoError(tt, err)
+		assert.Equal(tt, "did:stub:123", resolved.Document.ID)
+		assert.Empty(tt, resolved.Document.VerificationMethod)
+	})
+
+	t.Run("rejects duplicate methods", func(tt *testing.T) {
+		_, err := NewMultiResolver(stubResolver{method: JWKMethod}, JWKResolver{})
+		assert.Error(tt, err)
+		assert.Contains(tt, err.Error(), "duplicate resolver for method: jwk")
+	})
+
+	t.Run("unregistered method", func(tt *testing.T) {
+		resolver, err := NewMultiResolver(JWKResolver{})
+		assert.NoError(tt, err)
+
+		_, err = resolver.Resolve(context.Background(), "did:stub:123")
+		assert.Error(tt, err)
+		assert.ErrorIs(tt, err, ErrMethodNotSupported)
+		assert.Contains(tt, err.Error(), "unsupported method: stub")
+
+		var resolutionErr *ResolutionError
+		assert.ErrorAs(tt, err, &resolutionErr)
+		assert.Equal(tt, MethodNotSupportedErrorCode, resolutionErr.Code)
+		assert.Equal(tt, Method("stub"), resolutionErr.Method)
+		assert.Equal(tt, "did:stub:123", resolutionErr.DID)
+	})
+
+	t.Run("invalid did", func(tt *testing.T) {
+		resolver, err := NewMultiResolver(JWKResolver{})
+		assert.NoError(tt, err)
+
+		_, err = resolver.Resolve(context.Background(), "not-a-did")
+		assert.Error(tt, err)
+		assert.NotErrorIs(tt, err, ErrMethodNotSupported)
+		assert.ErrorIs(tt, err, ErrInvalidDID)
+
+		var resolutionErr *ResolutionError
+		assert.ErrorAs(tt, err, &resolutionErr)
+		assert.Equal(tt, InvalidDIDErrorCode, resolutionErr.Code)
+	})
+}
+
+func TestMultiResolverMethodPolicy(t *testing.T) {
+	_, didJWK, err := GenerateDIDJWK(crypto.Ed25519)
+	require.NoError(t, err)
+	_, didKey, err := GenerateDIDKey(crypto.Ed25519)
+	require.NoError(t, err)
+	newResolver := func(tt *testing.T, opts ...MultiResolverOption) (*MultiResolver, *countingResolver) {
+		web := &countingResolver{Resolver: stubResolver{method: WebMethod}, calls: make(map[string]int)}
+		resolver, err := NewMultiResolverWithOptions([]Resolver{JWKResolver{}, KeyResolver{}, web}, opts...)
+		require.NoError(tt, err)
+		return resolver, web
+	}
+	assertNotPermitted := func(tt *testing.T, err error, method Method) {
+		assert.ErrorIs(tt, err, ErrMethodNotPermitted)
+		var resolutionErr *ResolutionError
+		require.ErrorAs(tt, err, &resolutionErr)
+		assert.Equal(tt, MethodNotSupportedErrorCode, resolutionErr.Code)
+		assert.Equal(tt, method, resolutionErr.Method)
+	}
+
+	t.Run("allowed methods", func(tt *testing.T) {
+		resolver, web := newResolver(tt, WithAllowedMethods(JWKMethod, KeyMethod))
+		_, err := resolver.Resolve(context.Background(), didJWK.String())
+		assert.NoError(tt, err)
+		_, err = resolver.Resolve(context.Background(), didKey.String())
+		assert.NoError(tt, err)
+
+		// the web resolver is never called
+		_, err = resolver.Resolve(context.Background(), "did:web:example.com")
+		assertNotPermitted(tt, err, WebMethod)
+		assert.Empty(tt, web.calls)
+	})
+
+	t.Run("denied methods", func(tt *testing.T) {
+		resolver, web := newResolver(tt, WithDeniedMethods(WebMethod))
+		_, err := resolver.Resolve(context.Background(), didJWK.String())
+		assert.NoError(tt, err)
+
+		_, err = resolver.Resolve(context.Background(), "did:web:example.com")
+		assertNotPermitted(tt, err, WebMethod)
+		assert.Empty(tt, web.calls)
+	})
+
+	t.Run("denied methods take precedence over allowed methods", func(tt *testing.T) {
+		resolver, _ := newResolver(tt, WithAllowedMethods(JWKMethod, KeyMethod), WithDeniedMethods(KeyMethod))
+		_, err := resolver.Resolve(context.Background(), didJWK.String())
+		assert.NoError(tt, err)
+
+		_, err = resolver.Resolve(context.Background(), didKey.String())
+		assertNotPermitted(tt, err, KeyMethod)
+	})
+
+	t.Run("not registered methods", func(tt *testing.T) {
+		resolver, _ := newResolver(tt, WithAllowedMethods(JWKMethod, PeerMethod))
+
+		// allowed, but without a resolver
+		_, err := resolver.Resolve(context.Background(), "did:peer:0z6MkqRYqQiSgvZQdnBytw86Qbs2ZWUkGv22od935YF4s8M7V")
+		assert.ErrorIs(tt, err, ErrMethodNotSupported)
+		assert.NotErrorIs(tt, err, ErrMethodNotPermitted)
+
+		// neither allowed nor registered
+		_, err = resolver.Resolve(context.Background(), "did:stub:123")
+		assertNotPermitted(tt, err, "stub")
+	})
+
+	t.Run("every method is permitted by default", func(tt *testing.T) {
+		resolver, web := newResolver(tt)
+		_, err := resolver.Resolve(context.Background(), "did:web:example.com")
+		assert.NoError(tt, err)
+		assert.Equal(tt, 1, web.calls["did:web:example.com"])
+	})
+}
+
+// countingResolver resolves with the wrapped resolver, recording how many times each DID is resolved and the most
+// resolutions in flight at once
+type countingResolver struct {
+	Resolver
+	mu          sync.Mutex
+	calls       map[string]int
+	inFlight    int
+	maxInFlight int
+}
+
+func (c *countingResolver) Resolve(ctx context.Context, did string, opts ...ResolutionOption) (*ResolutionResult, error) {
+	c.mu.Lock()
+	c.calls[did]++
+	c.inFlight++
+	if c.inFlight > c.maxInFlight {
+		c.maxInFlight = c.inFlight
+	}
+	c.mu.Unlock()
+
+	// give the other workers a chance to pick up a DID
+	time.Sleep(10 * time.Millisecond)
+
+	c.mu.Lock()
+	c.inFlight--
+	c.mu.Unlock()
+	return c.Resolver.Resolve(ctx, did, opts...)
+}
+
+func TestResolveBatch(t *testing.T) {
+	t.Run("resolvable and unresolvable DIDs", func(tt *testing.T) {
+		multi, err := NewResolver(KeyResolver{}, JWKResolver{})
+		assert.NoError(tt, err)
+		resolver := &countingResolver{Resolver: multi, calls: make(map[string]int)}
+
+		_, didKey, err := GenerateDIDKey(crypto.Ed25519)
+		assert.NoError(tt, err)
+		_, didJWK, err := GenerateDIDJWK(crypto.Ed25519)
+		assert.NoError(tt, err)
+		_, otherDIDKey, err := GenerateDIDKey(crypto.SECP256k1)
+		assert.NoError(tt, err)
+
+		dids := []string{didKey.String(), "did:example:123", didJWK.String(), "not-a-did", otherDIDKey.String(), didKey.String()}
+		results, errs := ResolveBatch(context.Background(), resolver, dids, 2)
+
+		assert.Len(tt, results, 3)
+		for _, d := range []string{didKey.String(), didJWK.String(), otherDIDKey.String()} {
+			assert.Contains(tt, results, d)
+			assert.Equal(tt, d, results[d].Document.ID)
+			assert.NotContains(tt, errs, d)
+		}
+
+		assert.Len(tt, errs, 2)
+		assert.ErrorIs(tt, errs["did:example:123"], ErrMethodNotSupported)
+		assert.Error(tt, errs["not-a-did"])
+
+		// each DID is resolved once, and no more than two at a time
+		assert.Len(tt, resolver.calls, 5)
+		for d, calls := range resolver.calls {
+			assert.Equal(tt, 1, calls, d)
+		}
+		assert.LessOrEqual(tt, resolver.maxInFlight, 2)
+	})
+
+	t.Run("cancelled context", func(tt *testing.T) {
+		resolver := &countingResolver{Resolver: stubResolver{method: "stub"}, calls: make(map[string]int)}
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+
+		results, errs := ResolveBatch(ctx, resolver, []string{"did:stub:1", "did:stub:2", "did:stub:3"}, 2)
+		assert.Empty(tt, results)
+		assert.Len(tt, errs, 3)
+		for _, err := range errs {
+			assert.ErrorIs(tt, err, context.Canceled)
+		}
+		assert.Empty(tt, resolver.calls)
+	})
+
+	t.Run("no DIDs", func(tt *testing.T) {
+		results, errs := ResolveBatch(context.Background(), stubResolver{method: "stub"}, nil, 2)
+		assert.Empty(tt, results)
+		assert.Empty(tt, errs)
+	})
+
+	t.Run("nil resolver", func(tt *testing.T) {
+		results, errs := ResolveBatch(context.Background(), nil, []string{"did:stub:1"}, 2)
+		assert.Empty(tt, results)
+		assert.Contains(tt, errs["did:stub:1"].Error(), "resolver cannot be nil")
+	})
+}
+
+// slowResolver resolves DIDs after a delay, failing those of other methods, and records the context value and
+// options each resolution receives
+type slowResolver struct {
+	delay    time.Duration
+	ctxValue any
+	opts     []ResolutionOption
+}
+
+type slowResolverKey struct{}
+
+func (s *slowResolver) Resolve(ctx context.Context, did string, opts ...ResolutionOption) (*ResolutionResult, error) {
+	time.Sleep(s.delay)
+	s.ctxValue = ctx.Value(slowResolverKey{})
+	s.opts = opts
+	if !strings.HasPrefix(did, "did:slow:") {
+		return nil, NewResolutionError(MethodNotSupportedErrorCode, did, ErrMethodNotSupported)
+	}
+	return &ResolutionResult{Document: Document{ID: did}}, nil
+}
+
+func (s *slowResolver) Methods() []Method {
+	return []Method{"slow"}
+}
+
+// recordingHooks records each hook call
+type recordingHooks struct {
+	started   []string
+	succeeded map[string]time.Duration
+	failed    map[string]time.Duration
+	errs      map[string]error
+}
+
+func (h *recordingHooks) OnStart(did string) {
+	h.started = append(h.started, did)
+}
+
+func (h *recordingHooks) OnSuccess(did string, d time.Duration) {
+	h.succeeded[did] = d
+}
+
+func (h *recordingHooks) OnError(did string, d time.Duration, err error) {
+	h.failed[did] = d
+	h.errs[did] = err
+}
+
+func TestInstrumentResolver(t *testing.T) {
+	delay := 20 * time.Millisecond
+	stub := &slowResolver{delay: delay}
+	hooks := &recordingHooks{
+		succeeded: make(map[string]time.Duration),
+		failed:    make(map[string]time.Duration),
+		errs:      make(map[string]error),
+	}
+	resolver := InstrumentResolver(stub, hooks)
+	assert.Equal(t, []Method{"slow"}, resolver.Methods())
+
+	t.Run("success", func(tt *testing.T) {
+		ctx := context.WithValue(context.Background(), slowResolverKey{}, "value")
+		result, err := resolver.Resolve(ctx, "did:slow:123", WithAccept(DIDJSONMediaType))
+		require.NoError(tt, err)
+		assert.Equal(tt, "did:slow:123", result.Document.ID)
+
+		assert.Equal(tt, "value", stub.ctxValue)
+		assert.Equal(tt, []ResolutionOption{WithAccept(DIDJSONMediaType)}, stub.opts)
+
+		assert.Contains(tt, hooks.started, "did:slow:123")
+		assert.GreaterOrEqual(tt, hooks.succeeded["did:slow:123"], delay)
+		assert.NotContains(tt, hooks.failed, "did:slow:123")
+	})
+
+	t.Run("error", func(tt *testing.T) {
+		_, err := resolver.Resolve(context.Background(), "did:other:123")
+		assert.ErrorIs(tt, err, ErrMethodNotSupported)
+
+		assert.Contains(tt, hooks.started, "did:other:123")
+		assert.GreaterOrEqual(tt, hooks.failed["did:other:123"], delay)
+		assert.Equal(tt, err, hooks.errs["did:other:123"])
+		assert.NotContains(tt, hooks.succeeded, "did:other:123")
+	})
+
+	t.Run("nil hooks", func(tt *testing.T) {
+		assert.Equal(tt, Resolver(stub), InstrumentResolver(stub, nil))
+	})
+}
+
 func TestParseDIDResolution(t *testing.T) {
 	t.Run("bad response", func(tt *testing.T) {
 		_, err := ParseDIDResolution([]byte("bad response"))
@@ -77,4 +397,33 @@ func TestParseDIDResolution(t *testing.T) {
 		assert.False(tt, resolutionResult.Document.IsEmpty())
 		assert.Equal(tt, "did:ion:test", resolutionResult.Document.ID)
 	})
+
+	t.Run("bare did document", func(tt *testing.T) {
+		resolutionResult, err := ParseDIDResolution([]byte(`{"id": "did:web:example.com"}`))
+		assert.NoError(tt, err)
+		assert.Equal(tt, "did:web:example.com", resolutionResult.Document.ID)
+	})
+}
+
+func TestParseResolutionOptions(t *testing.T) {
+	t.Run("defaults to did+ld+json", func(tt *testing.T) {
+		options, err := ParseResolutionOptions(nil)
+		assert.NoError(tt, err)
+		assert.Equal(tt, DIDJSONLDMediaType, options.Accept)
+		assert.True(tt, options.IncludeContext())
+	})
+
+	t.Run("last accept wins and other options are ignored", func(tt *testing.T) {
+		opts := []ResolutionOption{WithAccept(DIDJSONLDMediaType), WithJWKThumbprintFragment(), WithAccept(DIDJSONMediaType)}
+		options, err := ParseResolutionOptions(opts)
+		assert.NoError(tt, err)
+		assert.Equal(tt, DIDJSONMediaType, options.Accept)
+		assert.False(tt, options.IncludeContext())
+	})
+
+	t.Run("unsupported accept", func(tt *testing.T) {
+		_, err := ParseResolutionOptions([]ResolutionOption{WithAccept("application/xml")})
+		assert.Error(tt, err)
+		assert.Contains(tt, err.Error(), "representation not supported: application/xml")
+	})
 }
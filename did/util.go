@@ -4,16 +4,17 @@ import (
 	"context"
 	gocrypto "crypto"
 	"fmt"
+	"net/url"
+	"strings"
 
 	"github.com/TBD54566975/ssi-sdk/crypto"
 	"github.com/TBD54566975/ssi-sdk/cryptosuite"
 	"github.com/TBD54566975/ssi-sdk/util"
 	"github.com/goccy/go-json"
+	"github.com/lestrrat-go/jwx/v2/jwa"
 	"github.com/lestrrat-go/jwx/v2/jwk"
+	"github.com/lestrrat-go/jwx/v2/x25519"
 	"github.com/mr-tron/base58"
-	"github.com/multiformats/go-multibase"
-	"github.com/multiformats/go-multicodec"
-	"github.com/multiformats/go-varint"
 	"github.com/pkg/errors"
 )
 
@@ -35,6 +36,84 @@ func ResolveKeyForDID(ctx context.Context, resolver Resolver, did, kid string) (
 	return pubKey, err
 }
 
+// ProofPurposeAuthorizer authorizes the verification methods of data integrity proofs for their proof purpose, as
+// cryptosuite.WithExpectedProofPurpose requires, by resolving the DID Document of each method's controller
+type ProofPurposeAuthorizer struct {
+	resolver Resolver
+}
+
+var _ cryptosuite.VerificationMethodAuthorizer = (*ProofPurposeAuthorizer)(nil)
+
+// NewProofPurposeAuthorizer creates an authorizer resolving controllers with the given resolver
+func NewProofPurposeAuthorizer(resolver Resolver) *ProofPurposeAuthorizer {
+	return &ProofPurposeAuthorizer{resolver: resolver}
+}
+
+// IsAuthorized dereferences the verification method, a DID URL such as did:example:123#key-1, and determines whether
+// the DID Document of its controller lists it in the verification relationship named by the purpose
+func (a *ProofPurposeAuthorizer) IsAuthorized(ctx context.Context, verificationMethod string, purpose cryptosuite.ProofPurpose) (bool, error) {
+	if a.resolver == nil {
+		return false, errors.New("resolver cannot be empty")
+	}
+	parsed, err := ParseDID(verificationMethod)
+	if err != nil {
+		return false, errors.Wrapf(err, "parsing verification method: %s", verificationMethod)
+	}
+	resolved, err := a.resolver.Resolve(ctx, parsed.DID())
+	if err != nil {
+		return false, errors.Wrapf(err, "resolving DID: %s", parsed.DID())
+	}
+	vm, err := resolved.Dereference(verificationMethod)
+	if err != nil {
+		return false, err
+	}
+
+	// the method may be controlled by a DID other than the one whose document it is listed in
+	controllerDoc := resolved.Document
+	if vm.Controller != "" && vm.Controller != controllerDoc.ID {
+		controller, err := a.resolver.Resolve(ctx, vm.Controller)
+		if err != nil {
+			return false, errors.Wrapf(err, "resolving controller: %s", vm.Controller)
+		}
+		controllerDoc = controller.Document
+	}
+	return controllerDoc.HasVerificationRelationship(verificationMethod, RelationshipType(purpose))
+}
+
+// SameSubjectKey determines whether two DIDs, which may be of different methods such as did:jwk and did:key, refer to
+// the same public key. Each DID is resolved, and its primary public key, that of the first verification method of
+// its document, is compared.
+func SameSubjectKey(a, b string, resolve Resolver) (bool, error) {
+	if resolve == nil {
+		return false, errors.New("resolver cannot be empty")
+	}
+	aKey, err := primaryPublicKey(resolve, a)
+	if err != nil {
+		return false, err
+	}
+	bKey, err := primaryPublicKey(resolve, b)
+	if err != nil {
+		return false, err
+	}
+	return crypto.PublicKeysEqual(aKey, bKey), nil
+}
+
+// primaryPublicKey resolves a DID and returns the public key of the first verification method of its document
+func primaryPublicKey(resolver Resolver, did string) (gocrypto.PublicKey, error) {
+	resolved, err := resolver.Resolve(context.Background(), did)
+	if err != nil {
+		return nil, errors.Wrapf(err, "resolving DID: %s", did)
+	}
+	if len(resolved.Document.VerificationMethod) == 0 {
+		return nil, errors.Errorf("did<%s> has no verification methods", did)
+	}
+	pubKey, err := extractKeyFromVerificationMethod(resolved.Document.VerificationMethod[0])
+	if err != nil {
+		return nil, errors.Wrapf(err, "getting primary public key of DID: %s", did)
+	}
+	return pubKey, nil
+}
+
 // GetKeyFromVerificationMethod resolves a DID and provides a kid and public key needed for data verification
 // it is possible that a DID has multiple verification methods, in which case a kid must be provided, otherwise
 // resolution will fail.
@@ -63,6 +142,193 @@ func GetKeyFromVerificationMethod(did Document, kid string) (gocrypto.PublicKey,
 	return nil, errors.Errorf("did<%s> has no verification methods with kid: %s", did.ID, kid)
 }
 
+// ErrFragmentNotFound is returned when a DID URL's fragment does not identify a verification method in the document
+var ErrFragmentNotFound = errors.New("fragment not found")
+
+// Dereference returns the verification method identified by the fragment of the DID URL (e.g. did:example:123#key-1).
+// Methods listed in the document's verificationMethod are matched first, followed by methods embedded in its
+// verification relationships. An error wrapping ErrFragmentNotFound is returned if no method matches.
+func (r *ResolutionResult) Dereference(didURL string) (*VerificationMethod, error) {
+	parsed, err := ParseDID(didURL)
+	if err != nil {
+		return nil, errors.Wrapf(err, "parsing DID URL: %s", didURL)
+	}
+	did := r.Document.ID
+	if parsed.DID() != did {
+		return nil, fmt.Errorf("DID URL<%s> does not belong to DID<%s>", didURL, did)
+	}
+	if parsed.Fragment == "" {
+		return nil, fmt.Errorf("DID URL<%s> has no fragment", didURL)
+	}
+
+	target := did + "#" + parsed.Fragment
+	for _, method := range r.Document.VerificationMethod {
+		if absoluteDIDURL(did, method.ID) == target {
+			vm := method
+			return &vm, nil
+		}
+	}
+
+	// relationships may reference one of the methods above by id, or embed a method of their own
+	relationships := [][]VerificationMethodSet{
+		r.Document.Authentication,
+		r.Document.AssertionMethod,
+		r.Document.KeyAgreement,
+		r.Document.CapabilityInvocation,
+		r.Document.CapabilityDelegation,
+	}
+	for _, relationship := range relationships {
+		for _, entry := range relationship {
+			vm, err := embeddedVerificationMethod(entry)
+			if err != nil {
+				return nil, err
+			}
+			if vm != nil && absoluteDIDURL(did, vm.ID) == target {
+				return vm, nil
+			}
+		}
+	}
+	return nil, fmt.Errorf("%w: %s", ErrFragmentNotFound, didURL)
+}
+
+// DID parameters for dereferencing services https://www.w3.org/TR/did-core/#did-parameters
+const (
+	ServiceDIDParameter     = "service"
+	RelativeRefDIDParameter = "relativeRef"
+)
+
+// ServiceDIDURL constructs a DID URL identifying the DID's service with the given id, which is the fragment of the
+// service's id, and optionally a reference relative to the service's endpoint
+// (e.g. did:example:123?relativeRef=%2Fresume.pdf&service=files)
+func ServiceDIDURL(did, service, relativeRef string) string {
+	query := url.Values{ServiceDIDParameter: []string{service}}
+	if relativeRef != "" {
+		query.Set(RelativeRefDIDParameter, relativeRef)
+	}
+	return did + "?" + query.Encode()
+}
+
+// DereferenceService returns the URL that the DID URL identifies through one of the document's services. The service
+// is named by the DID URL's service parameter, or by its fragment when it has none, and is matched against the
+// fragment of each service's id. When the DID URL has a relativeRef parameter, it is resolved against the service's
+// endpoint as a relative reference according to RFC 3986, and the DID URL's fragment, if any, is carried over.
+// https://w3c-ccg.github.io/did-resolution/#dereferencing-algorithm-primary
+func (d *Document) DereferenceService(didURL string) (string, error) {
+	parsed, err := ParseDID(didURL)
+	if err != nil {
+		return "", errors.Wrapf(err, "parsing DID URL: %s", didURL)
+	}
+	if parsed.DID() != d.ID {
+		return "", fmt.Errorf("DID URL<%s> does not belong to DID<%s>", didURL, d.ID)
+	}
+
+	name := parsed.Query.Get(ServiceDIDParameter)
+	fragment := parsed.Fragment
+	if name == "" {
+		name, fragment = parsed.Fragment, ""
+	}
+	if name == "" {
+		return "", fmt.Errorf("DID URL<%s> does not identify a service", didURL)
+	}
+
+	var service *Service
+	for i := range d.Services {
+		if absoluteDIDURL(d.ID, d.Services[i].ID) == d.ID+"#"+name {
+			service = &d.Services[i]
+			break
+		}
+	}
+	if service == nil {
+		return "", fmt.Errorf("DID<%s> has no service<%s>", d.ID, name)
+	}
+
+	endpoint, err := serviceEndpointURL(service.ServiceEndpoint)
+	if err != nil {
+		return "", errors.Wrapf(err, "getting endpoint of service<%s>", name)
+	}
+	relativeRef := parsed.Query.Get(RelativeRefDIDParameter)
+	if relativeRef == "" && fragment == "" {
+		return endpoint, nil
+	}
+
+	base, err := url.Parse(endpoint)
+	if err != nil {
+		return "", errors.Wrapf(err, "parsing endpoint of service<%s>", name)
+	}
+	reference, err := url.Parse(relativeRef)
+	if err != nil {
+		return "", errors.Wrapf(err, "parsing relativeRef: %s", relativeRef)
+	}
+	resolved := base.ResolveReference(reference)
+	if fragment != "" && resolved.Fragment == "" {
+		resolved.Fragment = fragment
+	}
+	return resolved.String(), nil
+}
+
+// serviceEndpointURL returns the URL of a service endpoint, which is either a URI, a map with a uri member, or a set
+// of these, in which case the first is used
+func serviceEndpointURL(endpoint any) (string, error) {
+	switch e := endpoint.(type) {
+	case string:
+		if e == "" {
+			return "", errors.New("service endpoint is empty")
+		}
+		return e, nil
+	case []string:
+		if len(e) == 0 {
+			return "", errors.New("service endpoint is empty")
+		}
+		return serviceEndpointURL(e[0])
+	case []any:
+		if len(e) == 0 {
+			return "", errors.New("service endpoint is empty")
+		}
+		return serviceEndpointURL(e[0])
+	case map[string]any:
+		if uri, ok := e["uri"].(string); ok {
+			return serviceEndpointURL(uri)
+		}
+		return "", errors.New("service endpoint has no uri")
+	case nil:
+		return "", errors.New("service has no endpoint")
+	default:
+		return "", fmt.Errorf("unsupported service endpoint type: %T", endpoint)
+	}
+}
+
+// absoluteDIDURL resolves an id, which may be relative to the DID (e.g. #key-1), against the DID
+func absoluteDIDURL(did, id string) string {
+	if strings.HasPrefix(id, "#") {
+		return did + id
+	}
+	return id
+}
+
+// embeddedVerificationMethod returns the verification method embedded in a relationship entry, or nil if the entry
+// references a method by id
+func embeddedVerificationMethod(entry VerificationMethodSet) (*VerificationMethod, error) {
+	switch e := entry.(type) {
+	case VerificationMethod:
+		return &e, nil
+	case *VerificationMethod:
+		return e, nil
+	case map[string]any:
+		// embedded methods are decoded as maps when unmarshalling a document
+		methodBytes, err := json.Marshal(e)
+		if err != nil {
+			return nil, errors.Wrap(err, "marshalling embedded verification method")
+		}
+		var vm VerificationMethod
+		if err = json.Unmarshal(methodBytes, &vm); err != nil {
+			return nil, errors.Wrap(err, "unmarshalling embedded verification method")
+		}
+		return &vm, nil
+	default:
+		return nil, nil
+	}
+}
+
 // matchesKIDConstruction checks if the targetID matches possible combinations of the did and kid
 func matchesKIDConstruction(did, kid, targetID string) bool {
 	maybeKID1 := kid                            // the kid == the kid
@@ -97,6 +363,10 @@ func extractKeyFromVerificationMethod(method VerificationMethod) (gocrypto.Publi
 		}
 		var pubKey gocrypto.PublicKey
 		if err := parsed.Raw(&pubKey); err != nil {
+			// keys our jwx library can parse but not convert, such as Ed448, are converted by our own JWK type
+			if convertedKey, convertErr := method.PublicKeyJWK.ToPublicKey(); convertErr == nil {
+				return convertedKey, nil
+			}
 			return nil, errors.Wrap(err, "getting raw jwk")
 		}
 		return pubKey, nil
@@ -104,13 +374,149 @@ func extractKeyFromVerificationMethod(method VerificationMethod) (gocrypto.Publi
 	return nil, errors.New("no public key found in verification method")
 }
 
+// Algorithm returns the JOSE signature algorithm for the verification method's key, determined by its public key
+// JWK when present, and by its type otherwise. An alg set on the JWK is returned as is. Multikey methods are typed
+// by the multicodec of their multibase key. RSA keys may be used with any RS or PS algorithm, and PS256 is returned
+// for them since it is the algorithm this library signs RSA JWTs with. Key agreement keys have no signature algorithm.
+func (vm VerificationMethod) Algorithm() (string, error) {
+	var kt crypto.KeyType
+	switch {
+	case vm.PublicKeyJWK != nil:
+		if vm.PublicKeyJWK.Alg != "" {
+			return vm.PublicKeyJWK.Alg, nil
+		}
+		jwkKT, err := keyTypeForJWK(*vm.PublicKeyJWK)
+		if err != nil {
+			return "", errors.Wrap(err, "determining key type of verification method jwk")
+		}
+		kt = jwkKT
+	case vm.Type == cryptosuite.MultikeyType:
+		_, _, multikeyKT, err := decodeEncodedKey(vm.PublicKeyMultibase)
+		if err != nil {
+			return "", errors.Wrap(err, "decoding multikey")
+		}
+		kt = multikeyKT
+	case vm.Type == cryptosuite.Ed25519VerificationKey2018, vm.Type == cryptosuite.Ed25519VerificationKey2020:
+		kt = crypto.Ed25519
+	case vm.Type == cryptosuite.ECDSASECP256k1VerificationKey2019:
+		kt = crypto.SECP256k1
+	case vm.Type == cryptosuite.X25519KeyAgreementKey2019, vm.Type == cryptosuite.X25519KeyAgreementKey2020:
+		kt = crypto.X25519
+	default:
+		return "", fmt.Errorf("cannot determine algorithm of verification method<%s> of type<%s>", vm.ID, vm.Type)
+	}
+
+	switch kt {
+	case crypto.Ed25519, crypto.Ed448:
+		return jwa.EdDSA.String(), nil
+	case crypto.SECP256k1:
+		return jwa.ES256K.String(), nil
+	case crypto.P256:
+		return jwa.ES256.String(), nil
+	case crypto.P384:
+		return jwa.ES384.String(), nil
+	case crypto.P521:
+		return jwa.ES512.String(), nil
+	case crypto.RSA:
+		return jwa.PS256.String(), nil
+	default:
+		return "", fmt.Errorf("verification method<%s> has a %s key, which has no signature algorithm", vm.ID, kt)
+	}
+}
+
+// ErrNoPublicKeyMaterial is returned when a verification method has none of publicKeyJwk, publicKeyMultibase, or
+// publicKeyBase58 set
+var ErrNoPublicKeyMaterial = errors.New("no public key material")
+
+// PublicKey decodes the public key of the verification method, from whichever of its publicKeyJwk,
+// publicKeyMultibase, or publicKeyBase58 is set, into a Go key along with its key type. Multibase keys are typed by
+// their multicodec prefix. Base58 keys are typed by the type of the verification method, or by their multicodec
+// prefix when the type does not determine the key type. An error wrapping ErrNoPublicKeyMaterial is returned if the
+// verification method has no key.
+func (vm VerificationMethod) PublicKey() (gocrypto.PublicKey, crypto.KeyType, error) {
+	var keyBytes []byte
+	var kt crypto.KeyType
+	switch {
+	case vm.PublicKeyJWK != nil:
+		jwkKT, err := keyTypeForJWK(*vm.PublicKeyJWK)
+		if err != nil {
+			return nil, "", errors.Wrap(err, "determining key type of verification method jwk")
+		}
+		pubKey, err := vm.PublicKeyJWK.ToPublicKey()
+		if err != nil {
+			return nil, "", errors.Wrap(err, "converting verification method jwk")
+		}
+		return pubKey, jwkKT, nil
+	case vm.PublicKeyMultibase != "":
+		encoding, decoded, err := crypto.MultibaseDecode(vm.PublicKeyMultibase)
+		if err != nil {
+			return nil, "", errors.Wrap(err, "decoding multibase key")
+		}
+		if encoding != Base58BTCMultiBase {
+			return nil, "", fmt.Errorf("expected %d encoding but found %d", Base58BTCMultiBase, encoding)
+		}
+		if keyBytes, kt, err = decodeMultiCodecKey(decoded); err != nil {
+			return nil, "", errors.Wrap(err, "decoding multibase key")
+		}
+	case vm.PublicKeyBase58 != "":
+		decoded, err := base58.Decode(vm.PublicKeyBase58)
+		if err != nil {
+			return nil, "", errors.Wrap(err, "decoding base58 key")
+		}
+		switch vm.Type {
+		case cryptosuite.Ed25519VerificationKey2018, cryptosuite.Ed25519VerificationKey2020:
+			keyBytes, kt = decoded, crypto.Ed25519
+		case cryptosuite.X25519KeyAgreementKey2019, cryptosuite.X25519KeyAgreementKey2020:
+			keyBytes, kt = decoded, crypto.X25519
+		case cryptosuite.ECDSASECP256k1VerificationKey2019:
+			keyBytes, kt = decoded, crypto.SECP256k1
+		case cryptosuite.BLS12381G2Key2020:
+			keyBytes, kt = decoded, crypto.BLS12381G2
+		default:
+			if keyBytes, kt, err = decodeMultiCodecKey(decoded); err != nil {
+				return nil, "", errors.Wrapf(err, "decoding base58 key of verification method type<%s>", vm.Type)
+			}
+		}
+	default:
+		return nil, "", errors.Wrapf(ErrNoPublicKeyMaterial, "verification method<%s>", vm.ID)
+	}
+
+	var pubKey gocrypto.PublicKey
+	var err error
+	switch kt {
+	case crypto.X25519:
+		pubKey = x25519.PublicKey(keyBytes)
+	case crypto.SECP256k1, crypto.P256, crypto.P384, crypto.P521:
+		pubKey, err = crypto.ParseECPublicKey(kt, keyBytes)
+	default:
+		pubKey, err = crypto.BytesToPubKey(keyBytes, kt)
+	}
+	if err != nil {
+		return nil, "", errors.Wrapf(err, "converting %s key", kt)
+	}
+	return pubKey, kt, nil
+}
+
+// decodeMultiCodecKey splits multicodec prefixed key bytes into the key bytes and the key type of the multicodec
+func decodeMultiCodecKey(decoded []byte) ([]byte, crypto.KeyType, error) {
+	multiCodec, keyBytes, err := crypto.MulticodecUnwrap(decoded)
+	if err != nil {
+		return nil, "", errors.Wrap(err, "parsing multicodec varint")
+	}
+	kt, err := codecToKeyType(multiCodec)
+	if err != nil {
+		return nil, "", errors.Wrap(err, "determining key type")
+	}
+	return keyBytes, kt, nil
+}
+
 // multibaseToPubKey converts a multibase encoded public key to public key bytes for known multibase encodings
 func multibaseToPubKeyBytes(mb string) ([]byte, error) {
 	if mb == "" {
 		return nil, errors.New("multibase key cannot be empty")
 	}
 
-	encoding, decoded, err := multibase.Decode(mb)
+	encoding, decoded, err := crypto.MultibaseDecode(mb)
 	if err != nil {
 		return nil, errors.Wrap(err, "decoding multibase key")
 	}
@@ -118,15 +524,10 @@ func multibaseToPubKeyBytes(mb string) ([]byte, error) {
 		return nil, fmt.Errorf("expected %d encoding but found %d", Base58BTCMultiBase, encoding)
 	}
 
-	// n = # bytes for the int, which we expect to be two from our multicodec
-	_, n, err := varint.FromUvarint(decoded)
+	_, pubKeyBytes, err := crypto.MulticodecUnwrap(decoded)
 	if err != nil {
 		return nil, errors.Wrap(err, "error parsing multibase varint")
 	}
-	if n != 2 {
-		return nil, errors.New("error parsing multibase varint")
-	}
-	pubKeyBytes := decoded[n:]
 	return pubKeyBytes, nil
 }
 
@@ -147,9 +548,7 @@ func encodePublicKeyWithKeyMultiCodecType(kt crypto.KeyType, pubKey gocrypto.Pub
 		return "", err
 	}
 
-	prefix := varint.ToUvarint(uint64(multiCodec))
-	codec := append(prefix, publicKey...)
-	encoded, err := multibase.Encode(PeerEncNumBasis, codec)
+	encoded, err := crypto.MultibaseEncode(PeerEncNumBasis, crypto.MulticodecWrap(multiCodec, publicKey))
 	if err != nil {
 		return "", err
 	}
@@ -158,7 +557,7 @@ func encodePublicKeyWithKeyMultiCodecType(kt crypto.KeyType, pubKey gocrypto.Pub
 }
 
 func decodeEncodedKey(d string) ([]byte, cryptosuite.LDKeyType, crypto.KeyType, error) {
-	encoding, decoded, err := multibase.Decode(d)
+	encoding, decoded, err := crypto.MultibaseDecode(d)
 	if err != nil {
 		return nil, "", "", err
 	}
@@ -167,23 +566,17 @@ func decodeEncodedKey(d string) ([]byte, cryptosuite.LDKeyType, crypto.KeyType,
 		return nil, "", "", fmt.Errorf("expected %d encoding but found %d", Base58BTCMultiBase, encoding)
 	}
 
-	// n = # bytes for the int, which we expect to be two from our multicodec
-	multiCodec, n, err := varint.FromUvarint(decoded)
+	multiCodec, pubKeyBytes, err := crypto.MulticodecUnwrap(decoded)
 	if err != nil {
-		return nil, "", "", err
-	}
-	if n != 2 {
-		return nil, "", "", errors.New("error parsing did:key varint")
+		return nil, "", "", errors.Wrap(err, "error parsing did:key varint")
 	}
 
-	pubKeyBytes := decoded[n:]
-	multiCodecValue := multicodec.Code(multiCodec)
-	ldKeyType, err := codecToLDKeyType(multiCodecValue)
+	ldKeyType, err := codecToLDKeyType(multiCodec)
 	if err != nil {
 		return nil, "", "", errors.Wrap(err, "codec to ld key type")
 	}
 
-	cryptoKeyType, err := codecToKeyType(multiCodecValue)
+	cryptoKeyType, err := codecToKeyType(multiCodec)
 	if err != nil {
 		return nil, "", "", errors.Wrap(err, "codec to key type")
 	}
@@ -193,7 +586,7 @@ func decodeEncodedKey(d string) ([]byte, cryptosuite.LDKeyType, crypto.KeyType,
 
 // decode public key with type
 func decodePublicKeyWithType(data []byte) ([]byte, cryptosuite.LDKeyType, error) {
-	encoding, decoded, err := multibase.Decode(string(data))
+	encoding, decoded, err := crypto.MultibaseDecode(string(data))
 	if err != nil {
 		return nil, "", errors.Wrap(err, "failed to decode public key")
 	}
@@ -203,15 +596,12 @@ func decodePublicKeyWithType(data []byte) ([]byte, cryptosuite.LDKeyType, error)
 		return nil, "", err
 	}
 
-	// n = # bytes for the int, which we expect to be two from our multicodec
-	multiCodec, n, err := varint.FromUvarint(decoded)
+	multiCodec, pubKeyBytes, err := crypto.MulticodecUnwrap(decoded)
 	if err != nil {
 		return nil, "", errors.Wrap(err, "failed to decode public key from varint")
 	}
 
-	pubKeyBytes := decoded[n:]
-	multiCodecValue := multicodec.Code(multiCodec)
-	switch multiCodecValue {
+	switch multiCodec {
 	case Ed25519MultiCodec:
 		return pubKeyBytes, cryptosuite.Ed25519VerificationKey2020, nil
 	case X25519MultiCodec:
@@ -223,11 +613,11 @@ func decodePublicKeyWithType(data []byte) ([]byte, cryptosuite.LDKeyType, error)
 	case P256MultiCodec, P384MultiCodec, P521MultiCodec, RSAMultiCodec:
 		return pubKeyBytes, cryptosuite.JSONWebKey2020Type, nil
 	default:
-		return nil, "", fmt.Errorf("unknown multicodec for did:peer: %d", multiCodecValue)
+		return nil, "", fmt.Errorf("unknown multicodec for did:peer: %d", multiCodec)
 	}
 }
 
-func keyTypeToMultiCodec(kt crypto.KeyType) (multicodec.Code, error) {
+func keyTypeToMultiCodec(kt crypto.KeyType) (crypto.MulticodecCode, error) {
 	switch kt {
 	case crypto.Ed25519:
 		return Ed25519MultiCodec, nil
@@ -7,6 +7,8 @@ import (
 
 	"github.com/TBD54566975/ssi-sdk/crypto"
 	"github.com/TBD54566975/ssi-sdk/crypto/jwx"
+	"github.com/TBD54566975/ssi-sdk/cryptosuite"
+	"github.com/goccy/go-json"
 	"github.com/mr-tron/base58"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
@@ -71,6 +73,140 @@ func TestResolveKeyForDID(t *testing.T) {
 	})
 }
 
+func TestSameSubjectKey(t *testing.T) {
+	resolver, err := NewResolver([]Resolver{KeyResolver{}, JWKResolver{}}...)
+	require.NoError(t, err)
+
+	t.Run("empty resolver", func(tt *testing.T) {
+		_, err := SameSubjectKey("did:key:test", "did:jwk:test", nil)
+		assert.Error(tt, err)
+		assert.Contains(tt, err.Error(), "resolver cannot be empty")
+	})
+
+	t.Run("did:jwk and did:key of the same Ed25519 key", func(tt *testing.T) {
+		privKey, didJWK, err := GenerateDIDJWK(crypto.Ed25519)
+		require.NoError(tt, err)
+		pubKey := privKey.(ed25519.PrivateKey).Public().(ed25519.PublicKey)
+		didKey, err := CreateDIDKey(crypto.Ed25519, pubKey)
+		require.NoError(tt, err)
+
+		same, err := SameSubjectKey(didJWK.String(), didKey.String(), resolver)
+		assert.NoError(tt, err)
+		assert.True(tt, same)
+
+		same, err = SameSubjectKey(didKey.String(), didJWK.String(), resolver)
+		assert.NoError(tt, err)
+		assert.True(tt, same)
+	})
+
+	t.Run("did:jwk and did:key of different keys", func(tt *testing.T) {
+		_, didJWK, err := GenerateDIDJWK(crypto.Ed25519)
+		require.NoError(tt, err)
+		_, didKey, err := GenerateDIDKey(crypto.Ed25519)
+		require.NoError(tt, err)
+
+		same, err := SameSubjectKey(didJWK.String(), didKey.String(), resolver)
+		assert.NoError(tt, err)
+		assert.False(tt, same)
+	})
+
+	t.Run("unresolvable did", func(tt *testing.T) {
+		_, didKey, err := GenerateDIDKey(crypto.Ed25519)
+		require.NoError(tt, err)
+
+		_, err = SameSubjectKey(didKey.String(), "did:example:test", resolver)
+		assert.Error(tt, err)
+		assert.Contains(tt, err.Error(), "resolving DID: did:example:test")
+	})
+}
+
+// documentResolver resolves DIDs to fixed documents
+type documentResolver map[string]Document
+
+func (r documentResolver) Resolve(_ context.Context, did string, _ ...ResolutionOption) (*ResolutionResult, error) {
+	doc, ok := r[did]
+	if !ok {
+		return nil, NewResolutionError(NotFoundErrorCode, did, nil)
+	}
+	return &ResolutionResult{Document: doc}, nil
+}
+
+func (r documentResolver) Methods() []Method {
+	return []Method{"example"}
+}
+
+func TestProofPurposeAuthorizer(t *testing.T) {
+	pubKey, privKey, err := crypto.GenerateEd25519Key()
+	require.NoError(t, err)
+	multibaseKey, err := encodePublicKeyWithKeyMultiCodecType(crypto.Ed25519, pubKey)
+	require.NoError(t, err)
+
+	// the issuer's key is only authorized for assertions, while the key it delegates to its controller is only
+	// authorized by the controller for authentication
+	issuer := Document{ID: "did:example:issuer"}
+	require.NoError(t, issuer.AddVerificationMethod(VerificationMethod{
+		ID:                 "#key-1",
+		Type:               cryptosuite.MultikeyType,
+		Controller:         "did:example:issuer",
+		PublicKeyMultibase: multibaseKey,
+	}, AssertionMethodRelationship))
+	require.NoError(t, issuer.AddVerificationMethod(VerificationMethod{
+		ID:                 "#key-2",
+		Type:               cryptosuite.MultikeyType,
+		Controller:         "did:example:controller",
+		PublicKeyMultibase: multibaseKey,
+	}, AssertionMethodRelationship, AuthenticationRelationship))
+	controller := Document{ID: "did:example:controller", Authentication: []VerificationMethodSet{"did:example:issuer#key-2"}}
+	authorizer := NewProofPurposeAuthorizer(documentResolver{issuer.ID: issuer, controller.ID: controller})
+
+	proofOptions := cryptosuite.WithProofOptions(cryptosuite.ProofOptions{Challenge: "abc"})
+	sign := func(tt *testing.T, kid string, purpose cryptosuite.ProofPurpose) cryptosuite.GenericProvable {
+		doc := cryptosuite.GenericProvable{"type": []any{"VerifiableCredential"}, "issuer": issuer.ID}
+		require.NoError(tt, cryptosuite.GetEdDSAJCS2022Suite(proofOptions).Sign(cryptosuite.NewEd25519Signer(kid, privKey, purpose), &doc))
+		return doc
+	}
+	verify := func(kid string, doc cryptosuite.GenericProvable, expectedPurpose cryptosuite.ProofPurpose) error {
+		suite := cryptosuite.GetEdDSAJCS2022Suite(proofOptions, cryptosuite.WithExpectedProofPurpose(context.Background(), expectedPurpose, authorizer))
+		return suite.Verify(cryptosuite.NewEd25519Verifier(kid, pubKey), &doc)
+	}
+
+	t.Run("assertion key is accepted for an assertion proof", func(tt *testing.T) {
+		doc := sign(tt, "did:example:issuer#key-1", cryptosuite.AssertionMethod)
+		assert.NoError(tt, verify("did:example:issuer#key-1", doc, cryptosuite.AssertionMethod))
+	})
+
+	t.Run("assertion key is rejected for an authentication proof", func(tt *testing.T) {
+		doc := sign(tt, "did:example:issuer#key-1", cryptosuite.Authentication)
+		err := verify("did:example:issuer#key-1", doc, cryptosuite.Authentication)
+		assert.ErrorIs(tt, err, cryptosuite.ErrUnauthorizedProofPurpose)
+
+		authorized, err := authorizer.IsAuthorized(context.Background(), "did:example:issuer#key-1", cryptosuite.Authentication)
+		assert.NoError(tt, err)
+		assert.False(tt, authorized)
+	})
+
+	t.Run("relationships of the controller's document authorize the key", func(tt *testing.T) {
+		doc := sign(tt, "did:example:issuer#key-2", cryptosuite.Authentication)
+		assert.NoError(tt, verify("did:example:issuer#key-2", doc, cryptosuite.Authentication))
+
+		// the issuer's own relationships do not authorize a key it does not control
+		doc = sign(tt, "did:example:issuer#key-2", cryptosuite.AssertionMethod)
+		err := verify("did:example:issuer#key-2", doc, cryptosuite.AssertionMethod)
+		assert.ErrorIs(tt, err, cryptosuite.ErrUnauthorizedProofPurpose)
+	})
+
+	t.Run("unknown verification method", func(tt *testing.T) {
+		_, err := authorizer.IsAuthorized(context.Background(), "did:example:issuer#key-3", cryptosuite.AssertionMethod)
+		assert.ErrorIs(tt, err, ErrFragmentNotFound)
+
+		_, err = authorizer.IsAuthorized(context.Background(), "did:example:other#key-1", cryptosuite.AssertionMethod)
+		assert.ErrorContains(tt, err, "resolving DID: did:example:other")
+
+		_, err = NewProofPurposeAuthorizer(nil).IsAuthorized(context.Background(), "did:example:issuer#key-1", cryptosuite.AssertionMethod)
+		assert.ErrorContains(tt, err, "resolver cannot be empty")
+	})
+}
+
 func TestGetKeyFromVerificationInformation(t *testing.T) {
 	t.Run("empty doc", func(tt *testing.T) {
 		_, err := GetKeyFromVerificationMethod(Document{}, "test-kid")
@@ -284,6 +420,344 @@ func TestGetKeyFromVerificationInformation(t *testing.T) {
 	})
 }
 
+func TestVerificationMethodAlgorithm(t *testing.T) {
+	jwkFor := func(t *testing.T, kt crypto.KeyType) *jwx.PublicKeyJWK {
+		pubKey, _, err := crypto.GenerateKeyByKeyType(kt)
+		require.NoError(t, err)
+		pubKeyJWK, err := jwx.PublicKeyToPublicKeyJWK(pubKey)
+		require.NoError(t, err)
+		return pubKeyJWK
+	}
+	multikeyFor := func(t *testing.T, kt crypto.KeyType) string {
+		pubKey, _, err := crypto.GenerateKeyByKeyType(kt)
+		require.NoError(t, err)
+		multibase, err := encodePublicKeyWithKeyMultiCodecType(kt, pubKey)
+		require.NoError(t, err)
+		return multibase
+	}
+
+	x25519JWK := jwkFor(t, crypto.X25519)
+	x25519JWK.CRV = "X25519"
+	ed448JWK := jwkFor(t, crypto.Ed448)
+	algJWK := jwkFor(t, crypto.RSA)
+	algJWK.Alg = "RS256"
+
+	tests := []struct {
+		name        string
+		vm          VerificationMethod
+		expectedAlg string
+		expectedErr string
+	}{
+		{
+			name:        "Ed25519VerificationKey2018",
+			vm:          VerificationMethod{Type: cryptosuite.Ed25519VerificationKey2018, PublicKeyBase58: "key"},
+			expectedAlg: "EdDSA",
+		},
+		{
+			name:        "Ed25519VerificationKey2020",
+			vm:          VerificationMethod{Type: cryptosuite.Ed25519VerificationKey2020, PublicKeyMultibase: "key"},
+			expectedAlg: "EdDSA",
+		},
+		{
+			name:        "EcdsaSecp256k1VerificationKey2019",
+			vm:          VerificationMethod{Type: cryptosuite.ECDSASECP256k1VerificationKey2019, PublicKeyJWK: jwkFor(t, crypto.SECP256k1)},
+			expectedAlg: "ES256K",
+		},
+		{
+			name:        "X25519KeyAgreementKey2019",
+			vm:          VerificationMethod{Type: cryptosuite.X25519KeyAgreementKey2019, PublicKeyBase58: "key"},
+			expectedErr: "has a X25519 key, which has no signature algorithm",
+		},
+		{
+			name:        "X25519KeyAgreementKey2020",
+			vm:          VerificationMethod{Type: cryptosuite.X25519KeyAgreementKey2020, PublicKeyMultibase: "key"},
+			expectedErr: "has a X25519 key, which has no signature algorithm",
+		},
+		{
+			name:        "JsonWebKey2020 Ed25519",
+			vm:          VerificationMethod{Type: cryptosuite.JSONWebKey2020Type, PublicKeyJWK: jwkFor(t, crypto.Ed25519)},
+			expectedAlg: "EdDSA",
+		},
+		{
+			name:        "JsonWebKey2020 Ed448",
+			vm:          VerificationMethod{Type: cryptosuite.JSONWebKey2020Type, PublicKeyJWK: ed448JWK},
+			expectedAlg: "EdDSA",
+		},
+		{
+			name:        "JsonWebKey2020 secp256k1",
+			vm:          VerificationMethod{Type: cryptosuite.JSONWebKey2020Type, PublicKeyJWK: jwkFor(t, crypto.SECP256k1)},
+			expectedAlg: "ES256K",
+		},
+		{
+			name:        "JsonWebKey2020 P-256",
+			vm:          VerificationMethod{Type: cryptosuite.JSONWebKey2020Type, PublicKeyJWK: jwkFor(t, crypto.P256)},
+			expectedAlg: "ES256",
+		},
+		{
+			name:        "JsonWebKey2020 P-384",
+			vm:          VerificationMethod{Type: cryptosuite.JSONWebKey2020Type, PublicKeyJWK: jwkFor(t, crypto.P384)},
+			expectedAlg: "ES384",
+		},
+		{
+			name:        "JsonWebKey2020 P-521",
+			vm:          VerificationMethod{Type: cryptosuite.JSONWebKey2020Type, PublicKeyJWK: jwkFor(t, crypto.P521)},
+			expectedAlg: "ES512",
+		},
+		{
+			name:        "JsonWebKey2020 RSA defaults to PS256",
+			vm:          VerificationMethod{Type: cryptosuite.JSONWebKey2020Type, PublicKeyJWK: jwkFor(t, crypto.RSA)},
+			expectedAlg: "PS256",
+		},
+		{
+			name:        "JsonWebKey2020 with alg",
+			vm:          VerificationMethod{Type: cryptosuite.JSONWebKey2020Type, PublicKeyJWK: algJWK},
+			expectedAlg: "RS256",
+		},
+		{
+			name:        "JsonWebKey2020 X25519",
+			vm:          VerificationMethod{Type: cryptosuite.JSONWebKey2020Type, PublicKeyJWK: x25519JWK},
+			expectedErr: "has a X25519 key, which has no signature algorithm",
+		},
+		{
+			name:        "JsonWebKey2020 without a JWK",
+			vm:          VerificationMethod{Type: cryptosuite.JSONWebKey2020Type, PublicKeyMultibase: "key"},
+			expectedErr: "cannot determine algorithm of verification method",
+		},
+		{
+			name:        "Multikey Ed25519",
+			vm:          VerificationMethod{Type: cryptosuite.MultikeyType, PublicKeyMultibase: multikeyFor(t, crypto.Ed25519)},
+			expectedAlg: "EdDSA",
+		},
+		{
+			name:        "Multikey P-256",
+			vm:          VerificationMethod{Type: cryptosuite.MultikeyType, PublicKeyMultibase: multikeyFor(t, crypto.P256)},
+			expectedAlg: "ES256",
+		},
+		{
+			name:        "Multikey X25519",
+			vm:          VerificationMethod{Type: cryptosuite.MultikeyType, PublicKeyMultibase: multikeyFor(t, crypto.X25519)},
+			expectedErr: "has a X25519 key, which has no signature algorithm",
+		},
+		{
+			name:        "Multikey that is not multibase",
+			vm:          VerificationMethod{Type: cryptosuite.MultikeyType, PublicKeyMultibase: "key"},
+			expectedErr: "decoding multikey",
+		},
+		{
+			name:        "Bls12381G2Key2020",
+			vm:          VerificationMethod{Type: cryptosuite.BLS12381G2Key2020, PublicKeyBase58: "key"},
+			expectedErr: "cannot determine algorithm of verification method",
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(tt *testing.T) {
+			alg, err := test.vm.Algorithm()
+			if test.expectedErr != "" {
+				assert.Error(tt, err)
+				assert.Contains(tt, err.Error(), test.expectedErr)
+				return
+			}
+			assert.NoError(tt, err)
+			assert.Equal(tt, test.expectedAlg, alg)
+		})
+	}
+}
+
+func TestVerificationMethodPublicKey(t *testing.T) {
+	t.Run("jwk", func(tt *testing.T) {
+		for _, kt := range []crypto.KeyType{crypto.Ed25519, crypto.SECP256k1, crypto.P256, crypto.RSA} {
+			_, didJWK, err := GenerateDIDJWK(kt)
+			require.NoError(tt, err)
+			doc, err := didJWK.Expand()
+			require.NoError(tt, err)
+			decoded, err := didJWK.Decode()
+			require.NoError(tt, err)
+			expectedKey, err := decoded.ToPublicKey()
+			require.NoError(tt, err)
+
+			pubKey, gotKT, err := doc.VerificationMethod[0].PublicKey()
+			assert.NoError(tt, err)
+			assert.Equal(tt, kt, gotKT)
+			assert.True(tt, crypto.PublicKeysEqual(expectedKey, pubKey), kt)
+		}
+	})
+
+	t.Run("multibase", func(tt *testing.T) {
+		for _, kt := range []crypto.KeyType{crypto.Ed25519, crypto.X25519, crypto.SECP256k1, crypto.P256, crypto.P384} {
+			expectedKey, _, err := crypto.GenerateKeyByKeyType(kt)
+			require.NoError(tt, err)
+			multibaseKey, err := encodePublicKeyWithKeyMultiCodecType(kt, expectedKey)
+			require.NoError(tt, err)
+			vm := VerificationMethod{ID: "#key-1", Type: cryptosuite.MultikeyType, PublicKeyMultibase: multibaseKey}
+
+			pubKey, gotKT, err := vm.PublicKey()
+			assert.NoError(tt, err)
+			assert.Equal(tt, kt, gotKT)
+			assert.True(tt, crypto.PublicKeysEqual(expectedKey, pubKey), kt)
+		}
+
+		// the multicodec determines the key type of a did:key
+		_, didKey, err := GenerateDIDKey(crypto.SECP256k1)
+		require.NoError(tt, err)
+		doc, err := didKey.Expand()
+		require.NoError(tt, err)
+		_, gotKT, err := doc.VerificationMethod[0].PublicKey()
+		assert.NoError(tt, err)
+		assert.Equal(tt, crypto.SECP256k1, gotKT)
+	})
+
+	t.Run("base58", func(tt *testing.T) {
+		expectedKey, _, err := crypto.GenerateEd25519Key()
+		require.NoError(tt, err)
+		vm := VerificationMethod{
+			ID:              "#key-1",
+			Type:            cryptosuite.Ed25519VerificationKey2018,
+			PublicKeyBase58: base58.Encode(expectedKey),
+		}
+
+		pubKey, gotKT, err := vm.PublicKey()
+		assert.NoError(tt, err)
+		assert.Equal(tt, crypto.Ed25519, gotKT)
+		assert.Equal(tt, expectedKey, pubKey)
+
+		// without a type that determines the key type, the key must be multicodec prefixed
+		vm.Type = cryptosuite.JSONWebKey2020Type
+		_, _, err = vm.PublicKey()
+		assert.Error(tt, err)
+		assert.Contains(tt, err.Error(), "decoding base58 key of verification method type<JsonWebKey2020>")
+	})
+
+	t.Run("no public key material", func(tt *testing.T) {
+		_, _, err := VerificationMethod{ID: "#key-1", Type: cryptosuite.JSONWebKey2020Type}.PublicKey()
+		assert.ErrorIs(tt, err, ErrNoPublicKeyMaterial)
+	})
+
+	t.Run("bad multibase", func(tt *testing.T) {
+		_, _, err := VerificationMethod{ID: "#key-1", PublicKeyMultibase: "not multibase"}.PublicKey()
+		assert.Error(tt, err)
+		assert.Contains(tt, err.Error(), "decoding multibase key")
+	})
+}
+
+func TestDereference(t *testing.T) {
+	t.Run("verification method by fragment", func(tt *testing.T) {
+		_, didJWK, err := GenerateDIDJWK(crypto.Ed25519)
+		require.NoError(tt, err)
+		resolved, err := JWKResolver{}.Resolve(context.Background(), didJWK.String())
+		require.NoError(tt, err)
+
+		vm, err := resolved.Dereference(didJWK.String() + "#0")
+		assert.NoError(tt, err)
+		assert.Equal(tt, resolved.Document.VerificationMethod[0], *vm)
+
+		_, err = resolved.Dereference(didJWK.String() + "#1")
+		assert.ErrorIs(tt, err, ErrFragmentNotFound)
+	})
+
+	t.Run("relative ids and embedded methods", func(tt *testing.T) {
+		docJSON := `{
+			"id": "did:example:123",
+			"verificationMethod": [{"id": "#key-1", "type": "Ed25519VerificationKey2018", "controller": "did:example:123", "publicKeyBase58": "H3C2AVvLMv6gmMNam3uVAjZpfkcJCwDwnZn6z3wXmqPV"}],
+			"authentication": ["#key-1", {"id": "did:example:123#key-2", "type": "Ed25519VerificationKey2018", "controller": "did:example:123", "publicKeyBase58": "GycSSui454dpYRKiFdsQ5uaE8Gy3ac6dSMPcAoQsk8yq"}]
+		}`
+		var resolved ResolutionResult
+		require.NoError(tt, json.Unmarshal([]byte(docJSON), &resolved.Document))
+
+		vm, err := resolved.Dereference("did:example:123#key-1")
+		assert.NoError(tt, err)
+		assert.Equal(tt, "#key-1", vm.ID)
+		assert.Equal(tt, "H3C2AVvLMv6gmMNam3uVAjZpfkcJCwDwnZn6z3wXmqPV", vm.PublicKeyBase58)
+
+		vm, err = resolved.Dereference("did:example:123#key-2")
+		assert.NoError(tt, err)
+		assert.Equal(tt, "did:example:123#key-2", vm.ID)
+		assert.Equal(tt, "GycSSui454dpYRKiFdsQ5uaE8Gy3ac6dSMPcAoQsk8yq", vm.PublicKeyBase58)
+
+		_, err = resolved.Dereference("did:example:123#key-3")
+		assert.ErrorIs(tt, err, ErrFragmentNotFound)
+	})
+
+	t.Run("invalid DID URLs", func(tt *testing.T) {
+		resolved := ResolutionResult{Document: Document{ID: "did:example:123"}}
+
+		_, err := resolved.Dereference("did:example:456#key-1")
+		assert.Error(tt, err)
+		assert.Contains(tt, err.Error(), "does not belong to DID")
+
+		_, err = resolved.Dereference("did:example:123")
+		assert.Error(tt, err)
+		assert.Contains(tt, err.Error(), "has no fragment")
+
+		_, err = resolved.Dereference("not a did")
+		assert.ErrorIs(tt, err, ErrInvalidDID)
+	})
+}
+
+func TestDereferenceService(t *testing.T) {
+	doc := Document{
+		ID: "did:web:example.com",
+		Services: []Service{
+			{ID: "#files", Type: "FileService", ServiceEndpoint: "https://example.com"},
+			{ID: "did:web:example.com#docs", Type: "FileService", ServiceEndpoint: []any{"https://example.com/docs/", "https://mirror.example.com/docs/"}},
+			{ID: "#messaging", Type: "DIDCommMessaging", ServiceEndpoint: map[string]any{"uri": "https://example.com/didcomm"}},
+			{ID: "#empty", Type: "FileService", ServiceEndpoint: []any{}},
+			{ID: "#none", Type: "FileService"},
+		},
+	}
+
+	t.Run("service with relative ref", func(tt *testing.T) {
+		endpoint, err := doc.DereferenceService("did:web:example.com?service=files&relativeRef=/resume.pdf")
+		assert.NoError(tt, err)
+		assert.Equal(tt, "https://example.com/resume.pdf", endpoint)
+	})
+
+	t.Run("constructed service DID URL", func(tt *testing.T) {
+		didURL := ServiceDIDURL(doc.ID, "files", "/resume.pdf")
+		assert.Equal(tt, "did:web:example.com?relativeRef=%2Fresume.pdf&service=files", didURL)
+
+		endpoint, err := doc.DereferenceService(didURL)
+		assert.NoError(tt, err)
+		assert.Equal(tt, "https://example.com/resume.pdf", endpoint)
+	})
+
+	t.Run("relative ref resolved against endpoint path and fragment carried over", func(tt *testing.T) {
+		endpoint, err := doc.DereferenceService("did:web:example.com?service=docs&relativeRef=guide%3Fversion%3Dlatest#intro")
+		assert.NoError(tt, err)
+		assert.Equal(tt, "https://example.com/docs/guide?version=latest#intro", endpoint)
+	})
+
+	t.Run("service by fragment", func(tt *testing.T) {
+		endpoint, err := doc.DereferenceService("did:web:example.com#messaging")
+		assert.NoError(tt, err)
+		assert.Equal(tt, "https://example.com/didcomm", endpoint)
+	})
+
+	t.Run("errors", func(tt *testing.T) {
+		_, err := doc.DereferenceService("did:web:example.com?service=unknown")
+		assert.Error(tt, err)
+		assert.Contains(tt, err.Error(), "has no service<unknown>")
+
+		_, err = doc.DereferenceService("did:web:example.com?service=empty")
+		assert.Error(tt, err)
+		assert.Contains(tt, err.Error(), "service endpoint is empty")
+
+		_, err = doc.DereferenceService("did:web:example.com?service=none")
+		assert.Error(tt, err)
+		assert.Contains(tt, err.Error(), "service has no endpoint")
+
+		_, err = doc.DereferenceService("did:web:example.com")
+		assert.Error(tt, err)
+		assert.Contains(tt, err.Error(), "does not identify a service")
+
+		_, err = doc.DereferenceService("did:web:other.com?service=files")
+		assert.Error(tt, err)
+		assert.Contains(tt, err.Error(), "does not belong to DID")
+
+		_, err = doc.DereferenceService("not a did")
+		assert.ErrorIs(tt, err, ErrInvalidDID)
+	})
+}
+
 func TestEncodePublicKeyWithKeyMultiCodecType(t *testing.T) {
 	// unsupported type
 	_, err := encodePublicKeyWithKeyMultiCodecType(crypto.KeyType("unsupported"), nil)
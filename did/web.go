@@ -2,15 +2,20 @@ package did
 
 import (
 	"context"
+	"crypto/tls"
 	"fmt"
 	"io"
+	"net"
 	"net/http"
 	"net/url"
 	"strings"
+	"sync"
+	"time"
 
 	"github.com/goccy/go-json"
 
 	"github.com/TBD54566975/ssi-sdk/crypto"
+	"github.com/TBD54566975/ssi-sdk/cryptosuite"
 	"github.com/TBD54566975/ssi-sdk/util"
 	"github.com/pkg/errors"
 )
@@ -26,6 +31,10 @@ const (
 	WebWellKnownURLPath = ".well-known/"
 	WebDIDDocFilename   = "did.json"
 	WebPrefix           = "did:web"
+
+	// webMaxDocSize is the maximum size of a DID Document fetched from a did:web host, so that a hostile host may not
+	// exhaust memory with an unbounded response
+	webMaxDocSize = 1 << 20
 )
 
 func (d DIDWeb) IsValid() bool {
@@ -88,6 +97,53 @@ func (d DIDWeb) CreateDocBytes(kt crypto.KeyType, publicKey []byte) ([]byte, err
 	return json.Marshal(doc)
 }
 
+// CreateDIDWeb constructs a did:web DID from a domain and an optional slash-separated path, along with a Document
+// containing the given verification methods, each of which must be controlled by the resulting DID. Key agreement
+// methods are referenced from keyAgreement, and all other methods from authentication and assertionMethod.
+// The returned Document is expected to be hosted as did.json at the URL given by GetDocURL.
+// specification: https://w3c-ccg.github.io/did-method-web/#create-register
+func CreateDIDWeb(domain, path string, keys []VerificationMethod) (*DIDWeb, *Document, error) {
+	if domain == "" {
+		return nil, nil, errors.New("domain is required")
+	}
+	if strings.Contains(domain, "://") {
+		return nil, nil, fmt.Errorf("domain<%s> must not contain a scheme", domain)
+	}
+	if strings.Contains(domain, "/") {
+		return nil, nil, fmt.Errorf("domain<%s> must not contain a slash", domain)
+	}
+	if len(keys) == 0 {
+		return nil, nil, errors.New("at least one verification method is required")
+	}
+
+	// a port in the domain must be percent encoded, as must any colons in the path segments
+	segments := []string{WebPrefix, url.QueryEscape(domain)}
+	for _, segment := range strings.Split(strings.Trim(path, "/"), "/") {
+		if segment != "" {
+			segments = append(segments, url.QueryEscape(segment))
+		}
+	}
+	didWeb := DIDWeb(strings.Join(segments, ":"))
+
+	doc := Document{
+		Context: KnownDIDContext,
+		ID:      didWeb.String(),
+	}
+	for i, key := range keys {
+		if key.Controller != didWeb.String() {
+			return nil, nil, fmt.Errorf("verification method<%s> at index %d has controller<%s>, expected<%s>", key.ID, i, key.Controller, didWeb)
+		}
+		doc.VerificationMethod = append(doc.VerificationMethod, key)
+		if key.Type == cryptosuite.X25519KeyAgreementKey2019 || key.Type == cryptosuite.X25519KeyAgreementKey2020 {
+			doc.KeyAgreement = append(doc.KeyAgreement, key.ID)
+			continue
+		}
+		doc.Authentication = append(doc.Authentication, key.ID)
+		doc.AssertionMethod = append(doc.AssertionMethod, key.ID)
+	}
+	return &didWeb, &doc, nil
+}
+
 // GetDocURL returns the expected URL of the DID Document where https:// prefix is required by the specification
 // optional path supported
 func (d DIDWeb) GetDocURL() (string, error) {
@@ -138,8 +194,14 @@ func (d DIDWeb) GetDocURL() (string, error) {
 	return sb.String(), nil
 }
 
+// Resolve fetches the did:web DID Document using the default HTTP client
 func (d DIDWeb) Resolve() (*Document, error) {
-	docBytes, err := d.resolveDocBytes()
+	return d.resolve(context.Background(), http.DefaultClient)
+}
+
+// resolve fetches the DID Document with the given client and validates that its id matches the DID
+func (d DIDWeb) resolve(ctx context.Context, client *http.Client) (*Document, error) {
+	docBytes, err := d.fetchDocBytes(ctx, client)
 	if err != nil {
 		return nil, errors.Wrapf(err, "resolving did:web DID<%s>", d)
 	}
@@ -156,6 +218,12 @@ func (d DIDWeb) Resolve() (*Document, error) {
 // resolveDocBytes simply performs a http.Get on the expected URL of the DID Document from GetDocURL
 // and returns the bytes of the fetched file
 func (d DIDWeb) resolveDocBytes() ([]byte, error) {
+	return d.fetchDocBytes(context.Background(), http.DefaultClient)
+}
+
+// fetchDocBytes performs a GET request with the given client on the expected URL of the DID Document from GetDocURL
+// and returns the bytes of the fetched file. Failures to fetch the document are returned as a *WebResolutionError.
+func (d DIDWeb) fetchDocBytes(ctx context.Context, client *http.Client) ([]byte, error) {
 	docURL, err := d.GetDocURL()
 	if err != nil {
 		return nil, errors.Wrapf(err, "getting doc url %+v", d)
@@ -163,36 +231,397 @@ func (d DIDWeb) resolveDocBytes() ([]byte, error) {
 	// Specification https://w3c-ccg.github.io/did-method-web/#read-resolve
 	// 6. Perform an HTTP GET request to the URL using an agent that can successfully negotiate a secure HTTPS
 	// connection, which enforces the security requirements as described in 2.5 Security and privacy considerations.
-	resp, err := http.Get(docURL) // #nosec
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, docURL, nil)
+	if err != nil {
+		return nil, errors.Wrapf(err, "creating request for doc %+v", docURL)
+	}
+	resp, err := client.Do(req) // #nosec
 	if err != nil {
-		return nil, errors.Wrapf(err, "getting doc %+v", docURL)
+		return nil, &WebResolutionError{Code: InternalErrorCode, URL: docURL, Err: err}
 	}
 	defer resp.Body.Close()
-	body, err := io.ReadAll(resp.Body)
+	if resp.StatusCode == http.StatusNotFound {
+		return nil, &WebResolutionError{Code: NotFoundErrorCode, URL: docURL, StatusCode: resp.StatusCode}
+	}
+	if resp.StatusCode < http.StatusOK || resp.StatusCode >= http.StatusMultipleChoices {
+		return nil, &WebResolutionError{Code: InternalErrorCode, URL: docURL, StatusCode: resp.StatusCode}
+	}
+	// read a byte past the limit to tell a document of exactly the maximum size from a larger one
+	body, err := io.ReadAll(io.LimitReader(resp.Body, webMaxDocSize+1))
 	if err != nil {
 		return nil, errors.Wrapf(err, "reading response %+v", resp)
 	}
+	if len(body) > webMaxDocSize {
+		return nil, &WebResolutionError{
+			Code:       InternalErrorCode,
+			URL:        docURL,
+			StatusCode: resp.StatusCode,
+			Err:        fmt.Errorf("document exceeds the maximum size of %d bytes", webMaxDocSize),
+		}
+	}
 	return body, nil
 }
 
-type WebResolver struct{}
+// WebResolutionError is returned when a did:web DID Document cannot be fetched. Code is NotFoundErrorCode when the
+// server responds with a 404, and InternalErrorCode for other unsuccessful responses and transport failures.
+type WebResolutionError struct {
+	Code string
+	URL  string
+	// StatusCode is the HTTP status code of the response, or zero if no response was received
+	StatusCode int
+	// Err is the underlying transport error, or the reason an oversized response was rejected, if any
+	Err error
+}
+
+func (e *WebResolutionError) Error() string {
+	if e.Err != nil {
+		return fmt.Sprintf("%s: fetching doc %s: %s", e.Code, e.URL, e.Err.Error())
+	}
+	return fmt.Sprintf("%s: fetching doc %s: received status code %d", e.Code, e.URL, e.StatusCode)
+}
+
+func (e *WebResolutionError) Unwrap() error {
+	return e.Err
+}
+
+// IsNotFound returns true if the DID Document does not exist at its expected URL
+func (e *WebResolutionError) IsNotFound() bool {
+	return e.Code == NotFoundErrorCode
+}
+
+// DefaultWebResolverTimeout is the timeout applied to did:web resolution requests unless otherwise configured
+const DefaultWebResolverTimeout = 10 * time.Second
+
+// WebResolver resolves did:web DIDs over HTTPS. The zero value uses http.DefaultClient and DefaultWebResolverTimeout;
+// use NewWebResolver to configure the client, timeout, or TLS settings.
+type WebResolver struct {
+	client         *http.Client
+	timeout        time.Duration
+	tlsConfig      *tls.Config
+	cacheTTL       time.Duration
+	cache          *webDocumentCache
+	denyPrivateIPs bool
+	allowedHosts   map[string]bool
+	// lookupIPAddr resolves the addresses of a host when private IPs are denied
+	lookupIPAddr func(ctx context.Context, host string) ([]net.IPAddr, error)
+}
+
+// ErrHostNotPermitted is returned when resolving a did:web DID whose host is not allowed by the resolver, or resolves
+// to a private address while private IPs are denied
+var ErrHostNotPermitted = errors.New("host not permitted")
 
 var _ Resolver = (*WebResolver)(nil)
 
+// WebResolverOption configures a WebResolver
+type WebResolverOption func(*WebResolver)
+
+// WithHTTPClient sets the client used to fetch DID Documents
+func WithHTTPClient(client *http.Client) WebResolverOption {
+	return func(r *WebResolver) {
+		r.client = client
+	}
+}
+
+// WithTimeout sets the maximum duration of a single resolution request. A non-positive timeout disables the limit.
+func WithTimeout(timeout time.Duration) WebResolverOption {
+	return func(r *WebResolver) {
+		r.timeout = timeout
+	}
+}
+
+// WithTLSConfig sets the TLS configuration used when fetching DID Documents, such as custom root CAs or a
+// minimum TLS version. It is applied to a copy of the client's transport, leaving the provided client untouched.
+func WithTLSConfig(config *tls.Config) WebResolverOption {
+	return func(r *WebResolver) {
+		r.tlsConfig = config
+	}
+}
+
+// WithCacheTTL enables an in-memory cache of resolved DID Documents, each kept for the given duration.
+// Concurrent resolutions of the same DID are deduplicated into a single request while the cache is enabled.
+// The cache is disabled by default.
+func WithCacheTTL(ttl time.Duration) WebResolverOption {
+	return func(r *WebResolver) {
+		r.cacheTTL = ttl
+	}
+}
+
+// WithDenyPrivateIPs refuses to resolve DIDs whose host resolves to a loopback, link-local, unspecified, or private
+// (RFC 1918 or RFC 4193) address, guarding against server-side request forgery. The addresses of the host are
+// checked when connecting, and the connection is made to a checked address, so that a host cannot resolve to a
+// different address between the check and the request.
+func WithDenyPrivateIPs() WebResolverOption {
+	return func(r *WebResolver) {
+		r.denyPrivateIPs = true
+	}
+}
+
+// WithHostAllowlist restricts resolution to DIDs of the given hosts, such as example.com, without their port.
+// Redirects to other hosts are refused as well.
+func WithHostAllowlist(hosts ...string) WebResolverOption {
+	return func(r *WebResolver) {
+		if r.allowedHosts == nil {
+			r.allowedHosts = make(map[string]bool, len(hosts))
+		}
+		for _, host := range hosts {
+			r.allowedHosts[strings.ToLower(host)] = true
+		}
+	}
+}
+
+// NewWebResolver creates a WebResolver with the given options
+func NewWebResolver(opts ...WebResolverOption) (*WebResolver, error) {
+	r := WebResolver{
+		client:       http.DefaultClient,
+		timeout:      DefaultWebResolverTimeout,
+		lookupIPAddr: net.DefaultResolver.LookupIPAddr,
+	}
+	for _, opt := range opts {
+		opt(&r)
+	}
+	if r.client == nil {
+		return nil, errors.New("client cannot be nil")
+	}
+	guarded := r.denyPrivateIPs || len(r.allowedHosts) > 0
+	if r.tlsConfig != nil || guarded {
+		transport, ok := http.DefaultTransport.(*http.Transport)
+		if r.client.Transport != nil {
+			transport, ok = r.client.Transport.(*http.Transport)
+		}
+		if !ok {
+			return nil, errors.New("cannot apply TLS config or host restrictions to a client without a *http.Transport")
+		}
+		transport = transport.Clone()
+		if r.tlsConfig != nil {
+			transport.TLSClientConfig = r.tlsConfig
+		}
+		if guarded {
+			// a proxy would connect to the host on our behalf, bypassing the checks of the dialer
+			transport.Proxy = nil
+			transport.DialContext = guardedDialContext(transport.DialContext, r.allowedHosts, r.denyPrivateIPs, r.lookupIPAddr)
+		}
+		client := *r.client
+		client.Transport = transport
+		r.client = &client
+	}
+	if r.cacheTTL > 0 {
+		r.cache = newWebDocumentCache(r.cacheTTL)
+	}
+	return &r, nil
+}
+
+// Purge evicts the cached DID Document for the given DID, if any, so that the next resolution fetches it again.
+// This is useful when a document is known to have been updated, such as after a key rotation.
+func (r *WebResolver) Purge(did string) {
+	if r.cache != nil {
+		r.cache.purge(did)
+	}
+}
+
 func (WebResolver) Methods() []Method {
 	return []Method{WebMethod}
 }
 
-// Resolve fetches and returns the Document from the expected URL
+// Resolve fetches and returns the Document from the expected URL. A DID whose document is gone, with a 410 response,
+// is resolved as deactivated. Failures are returned as a *ResolutionError, with code NotFoundErrorCode if there is no
+// document at the URL, wrapping the *WebResolutionError of a failed fetch.
 // specification: https://w3c-ccg.github.io/did-method-web/#read-resolve
-func (WebResolver) Resolve(_ context.Context, did string, _ ...ResolutionOption) (*ResolutionResult, error) {
+func (r WebResolver) Resolve(ctx context.Context, did string, _ ...ResolutionOption) (*ResolutionResult, error) {
 	if !strings.HasPrefix(did, WebPrefix) {
-		return nil, fmt.Errorf("not a did:web DID: %s", did)
+		return nil, NewResolutionError(InvalidDIDErrorCode, did, fmt.Errorf("not a did:web DID: %s", did))
 	}
+
+	client := r.client
+	if client == nil {
+		client = http.DefaultClient
+	}
+	// a zero value resolver falls back to the default timeout, as it was not constructed with NewWebResolver
+	timeout := r.timeout
+	if r.client == nil && timeout == 0 {
+		timeout = DefaultWebResolverTimeout
+	}
+
 	didWeb := DIDWeb(did)
-	doc, err := didWeb.Resolve()
+	// refuse hosts that are not allowed before any network access; the dialer checks them again for redirects
+	if len(r.allowedHosts) > 0 {
+		docURL, err := didWeb.GetDocURL()
+		if err != nil {
+			return nil, NewResolutionError(InvalidDIDErrorCode, did, err)
+		}
+		parsed, err := url.Parse(docURL)
+		if err != nil {
+			return nil, NewResolutionError(InvalidDIDErrorCode, did, err)
+		}
+		if !r.allowedHosts[strings.ToLower(parsed.Hostname())] {
+			return nil, NewResolutionError(InternalErrorCode, did, errors.Wrapf(ErrHostNotPermitted, "host<%s> is not allowed", parsed.Hostname()))
+		}
+	}
+	resolve := func(ctx context.Context) (*Document, error) {
+		if timeout > 0 {
+			var cancel context.CancelFunc
+			ctx, cancel = context.WithTimeout(ctx, timeout)
+			defer cancel()
+		}
+		return didWeb.resolve(ctx, client)
+	}
+	var doc *Document
+	var err error
+	if r.cache != nil {
+		// a fetch is shared by every caller awaiting it, so it is not bound by the context of any one of them
+		doc, err = r.cache.get(ctx, did, func() (*Document, error) {
+			return resolve(context.Background())
+		})
+	} else {
+		doc, err = resolve(ctx)
+	}
 	if err != nil {
-		return nil, errors.Wrapf(err, "cresolving did:web DID: %s", did)
+		// a document that has been removed, and is reported as gone, is that of a deactivated DID
+		var webErr *WebResolutionError
+		if !errors.As(err, &webErr) {
+			return nil, NewResolutionError(InternalErrorCode, did, err)
+		}
+		if webErr.StatusCode == http.StatusGone {
+			return &ResolutionResult{Document: Document{ID: did}, DocumentMetadata: DocumentMetadata{Deactivated: true}}, nil
+		}
+		return nil, NewResolutionError(webErr.Code, did, err)
 	}
 	return &ResolutionResult{Document: *doc}, nil
 }
+
+// guardedDialContext wraps a dial function so that it only connects to allowed hosts, if any are given, and, if
+// private IPs are denied, only to the checked addresses of the host, none of which may be private
+func guardedDialContext(dial func(ctx context.Context, network, addr string) (net.Conn, error), allowedHosts map[string]bool,
+	denyPrivateIPs bool, lookupIPAddr func(ctx context.Context, host string) ([]net.IPAddr, error)) func(ctx context.Context, network, addr string) (net.Conn, error) {
+	if dial == nil {
+		dial = (&net.Dialer{Timeout: 30 * time.Second, KeepAlive: 30 * time.Second}).DialContext
+	}
+	return func(ctx context.Context, network, addr string) (net.Conn, error) {
+		host, port, err := net.SplitHostPort(addr)
+		if err != nil {
+			return nil, err
+		}
+		if len(allowedHosts) > 0 && !allowedHosts[strings.ToLower(host)] {
+			return nil, errors.Wrapf(ErrHostNotPermitted, "host<%s> is not allowed", host)
+		}
+		if !denyPrivateIPs {
+			return dial(ctx, network, addr)
+		}
+
+		ipAddrs, err := lookupIPAddr(ctx, host)
+		if err != nil {
+			return nil, errors.Wrapf(err, "looking up host<%s>", host)
+		}
+		if len(ipAddrs) == 0 {
+			return nil, fmt.Errorf("no addresses found for host<%s>", host)
+		}
+		for _, ipAddr := range ipAddrs {
+			if isPrivateIP(ipAddr.IP) {
+				return nil, errors.Wrapf(ErrHostNotPermitted, "host<%s> resolves to private address<%s>", host, ipAddr.IP)
+			}
+		}
+		// dial the checked addresses rather than the host, which could resolve differently on a second lookup
+		var dialErr error
+		for _, ipAddr := range ipAddrs {
+			conn, err := dial(ctx, network, net.JoinHostPort(ipAddr.IP.String(), port))
+			if err == nil {
+				return conn, nil
+			}
+			dialErr = err
+		}
+		return nil, dialErr
+	}
+}
+
+// isPrivateIP determines whether an address is loopback, link-local, unspecified, or in a private range
+func isPrivateIP(ip net.IP) bool {
+	return ip.IsLoopback() || ip.IsLinkLocalUnicast() || ip.IsLinkLocalMulticast() || ip.IsInterfaceLocalMulticast() ||
+		ip.IsUnspecified() || ip.IsPrivate()
+}
+
+// webDocumentCache caches resolved DID Documents by DID, deduplicating concurrent fetches of the same DID
+type webDocumentCache struct {
+	ttl      time.Duration
+	mu       sync.Mutex
+	entries  map[string]webCacheEntry
+	inflight map[string]*webFetch
+}
+
+type webCacheEntry struct {
+	doc       *Document
+	expiresAt time.Time
+}
+
+// webFetch is a fetch in progress, whose result is shared with all callers waiting on done
+type webFetch struct {
+	done chan struct{}
+	doc  *Document
+	err  error
+}
+
+func newWebDocumentCache(ttl time.Duration) *webDocumentCache {
+	return &webDocumentCache{
+		ttl:      ttl,
+		entries:  make(map[string]webCacheEntry),
+		inflight: make(map[string]*webFetch),
+	}
+}
+
+// get returns a copy of the cached document for the DID, lazily evicting it if expired. Otherwise, the document is
+// fetched, or, if a fetch for the DID is already in progress, its result is awaited. The fetch runs on its own, so
+// that it completes for the other callers awaiting it if the context of the caller that started it is done. Failed
+// fetches are not cached.
+func (c *webDocumentCache) get(ctx context.Context, did string, fetch func() (*Document, error)) (*Document, error) {
+	c.mu.Lock()
+	if entry, ok := c.entries[did]; ok {
+		if time.Now().Before(entry.expiresAt) {
+			c.mu.Unlock()
+			return copyDocument(entry.doc)
+		}
+		delete(c.entries, did)
+	}
+	f, ok := c.inflight[did]
+	if !ok {
+		f = &webFetch{done: make(chan struct{})}
+		c.inflight[did] = f
+		go c.fetch(did, f, fetch)
+	}
+	c.mu.Unlock()
+
+	select {
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	case <-f.done:
+	}
+	if f.err != nil {
+		return nil, f.err
+	}
+	return copyDocument(f.doc)
+}
+
+// fetch runs the fetch for the DID, caching its document if it succeeds, and signals its callers when it is done
+func (c *webDocumentCache) fetch(did string, f *webFetch, fetch func() (*Document, error)) {
+	f.doc, f.err = fetch()
+
+	c.mu.Lock()
+	if f.err == nil {
+		c.entries[did] = webCacheEntry{doc: f.doc, expiresAt: time.Now().Add(c.ttl)}
+	}
+	delete(c.inflight, did)
+	c.mu.Unlock()
+	close(f.done)
+}
+
+// copyDocument returns a deep copy of a cached document, so that callers modifying it do not modify the cache
+func copyDocument(doc *Document) (*Document, error) {
+	var docCopy Document
+	if err := util.Copy(doc, &docCopy); err != nil {
+		return nil, errors.Wrap(err, "copying cached document")
+	}
+	return &docCopy, nil
+}
+
+func (c *webDocumentCache) purge(did string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	delete(c.entries, did)
+}
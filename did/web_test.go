@@ -1,12 +1,26 @@
 package did
 
 import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"sync"
+	"sync/atomic"
 	"testing"
+	"time"
 
 	"gopkg.in/h2non/gock.v1"
 
 	"github.com/TBD54566975/ssi-sdk/crypto"
+	"github.com/TBD54566975/ssi-sdk/cryptosuite"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 const (
@@ -120,6 +134,81 @@ func TestDIDWebCreateDoc(t *testing.T) {
 	})
 }
 
+func TestCreateDIDWeb(t *testing.T) {
+	newKey := func(t *testing.T, controller, id string) VerificationMethod {
+		pk, _, err := crypto.GenerateEd25519Key()
+		assert.NoError(t, err)
+		vm, err := constructVerificationMethod(controller, controller+id, pk, cryptosuite.Ed25519VerificationKey2018, crypto.Ed25519)
+		assert.NoError(t, err)
+		return *vm
+	}
+
+	t.Run("Happy Path - Bare Domain", func(tt *testing.T) {
+		key := newKey(tt, string(didWebBasic), "#key-1")
+		didWeb, doc, err := CreateDIDWeb("example.com", "", []VerificationMethod{key})
+		assert.NoError(tt, err)
+		assert.Equal(tt, didWebBasic, *didWeb)
+		assert.Equal(tt, string(didWebBasic), doc.ID)
+		assert.NoError(tt, doc.IsValid())
+		assert.Equal(tt, []VerificationMethod{key}, doc.VerificationMethod)
+		assert.Equal(tt, []VerificationMethodSet{key.ID}, doc.Authentication)
+		assert.Equal(tt, []VerificationMethodSet{key.ID}, doc.AssertionMethod)
+		assert.Empty(tt, doc.KeyAgreement)
+
+		docURL, err := didWeb.GetDocURL()
+		assert.NoError(tt, err)
+		assert.Equal(tt, "https://example.com/.well-known/did.json", docURL)
+	})
+
+	t.Run("Happy Path - Port and Path", func(tt *testing.T) {
+		controller := "did:web:localhost%3A8443:user:alice"
+		signingKey := newKey(tt, controller, "#key-1")
+		agreementKey := VerificationMethod{
+			ID:                 controller + "#key-2",
+			Type:               cryptosuite.X25519KeyAgreementKey2020,
+			Controller:         controller,
+			PublicKeyMultibase: "z6LSeu9HkTHSfLLeUs2nnzUSNedgDUevfNQgQjQC23ZCit6F",
+		}
+		didWeb, doc, err := CreateDIDWeb("localhost:8443", "/user/alice/", []VerificationMethod{signingKey, agreementKey})
+		assert.NoError(tt, err)
+		assert.Equal(tt, controller, didWeb.String())
+		assert.Equal(tt, []VerificationMethodSet{signingKey.ID}, doc.Authentication)
+		assert.Equal(tt, []VerificationMethodSet{agreementKey.ID}, doc.KeyAgreement)
+
+		docURL, err := didWeb.GetDocURL()
+		assert.NoError(tt, err)
+		assert.Equal(tt, "https://localhost:8443/user/alice/did.json", docURL)
+	})
+
+	t.Run("Invalid Domains", func(tt *testing.T) {
+		key := newKey(tt, string(didWebBasic), "#key-1")
+		_, _, err := CreateDIDWeb("https://example.com", "", []VerificationMethod{key})
+		assert.Error(tt, err)
+		assert.Contains(tt, err.Error(), "must not contain a scheme")
+
+		_, _, err = CreateDIDWeb("example.com/", "", []VerificationMethod{key})
+		assert.Error(tt, err)
+		assert.Contains(tt, err.Error(), "must not contain a slash")
+
+		_, _, err = CreateDIDWeb("", "", []VerificationMethod{key})
+		assert.Error(tt, err)
+		assert.Contains(tt, err.Error(), "domain is required")
+	})
+
+	t.Run("Mismatched Controller", func(tt *testing.T) {
+		key := newKey(tt, string(didWebOptionalPath), "#key-1")
+		_, _, err := CreateDIDWeb("example.com", "", []VerificationMethod{key})
+		assert.Error(tt, err)
+		assert.Contains(tt, err.Error(), "expected<did:web:example.com>")
+	})
+
+	t.Run("No Keys", func(tt *testing.T) {
+		_, _, err := CreateDIDWeb("example.com", "", nil)
+		assert.Error(tt, err)
+		assert.Contains(tt, err.Error(), "at least one verification method is required")
+	})
+}
+
 func TestDIDWebCreateDocFileBytes(t *testing.T) {
 	t.Run("Happy Path - Create DID", func(tt *testing.T) {
 		pk, _, err := crypto.GenerateEd25519Key()
@@ -134,3 +223,391 @@ func TestDIDWebCreateDocFileBytes(t *testing.T) {
 		assert.Error(tt, err)
 	})
 }
+
+func TestWebResolver(t *testing.T) {
+	// newTestServer serves the given DID Document at the expected path for the returned did:web DID
+	newTestServer := func(t *testing.T, handler http.HandlerFunc) (*httptest.Server, string) {
+		server := httptest.NewTLSServer(handler)
+		t.Cleanup(server.Close)
+		serverURL, err := url.Parse(server.URL)
+		assert.NoError(t, err)
+		return server, "did:web:" + url.QueryEscape(serverURL.Host)
+	}
+
+	t.Run("resolves with an injected client and percent-encoded port", func(tt *testing.T) {
+		var didWeb string
+		server, didWeb := newTestServer(tt, func(w http.ResponseWriter, r *http.Request) {
+			assert.Equal(tt, "/.well-known/did.json", r.URL.Path)
+			_, _ = w.Write([]byte(`{"id": "` + didWeb + `"}`))
+		})
+		assert.True(tt, strings.Contains(didWeb, "%3A"))
+
+		resolver, err := NewWebResolver(WithHTTPClient(server.Client()))
+		assert.NoError(tt, err)
+		resolved, err := resolver.Resolve(context.Background(), didWeb)
+		assert.NoError(tt, err)
+		assert.Equal(tt, didWeb, resolved.Document.ID)
+	})
+
+	t.Run("resolves with a TLS config", func(tt *testing.T) {
+		var didWeb string
+		server, didWeb := newTestServer(tt, func(w http.ResponseWriter, _ *http.Request) {
+			_, _ = w.Write([]byte(`{"id": "` + didWeb + `"}`))
+		})
+		roots := x509.NewCertPool()
+		roots.AddCert(server.Certificate())
+
+		resolver, err := NewWebResolver(WithHTTPClient(&http.Client{}), WithTLSConfig(&tls.Config{RootCAs: roots, MinVersion: tls.VersionTLS12}))
+		assert.NoError(tt, err)
+		resolved, err := resolver.Resolve(context.Background(), didWeb)
+		assert.NoError(tt, err)
+		assert.Equal(tt, didWeb, resolved.Document.ID)
+	})
+
+	t.Run("rejects a mismatched document id", func(tt *testing.T) {
+		server, didWeb := newTestServer(tt, func(w http.ResponseWriter, _ *http.Request) {
+			_, _ = w.Write([]byte(`{"id": "did:web:example.com"}`))
+		})
+
+		resolver, err := NewWebResolver(WithHTTPClient(server.Client()))
+		assert.NoError(tt, err)
+		_, err = resolver.Resolve(context.Background(), didWeb)
+		assert.Error(tt, err)
+		assert.Contains(tt, err.Error(), "does not match did:web value")
+	})
+
+	t.Run("not found", func(tt *testing.T) {
+		server, didWeb := newTestServer(tt, http.NotFound)
+
+		resolver, err := NewWebResolver(WithHTTPClient(server.Client()))
+		assert.NoError(tt, err)
+		_, err = resolver.Resolve(context.Background(), didWeb+":user:alice")
+		assert.Error(tt, err)
+
+		var resolutionErr *WebResolutionError
+		assert.True(tt, errors.As(err, &resolutionErr))
+		assert.True(tt, resolutionErr.IsNotFound())
+		assert.Equal(tt, http.StatusNotFound, resolutionErr.StatusCode)
+		assert.True(tt, strings.HasSuffix(resolutionErr.URL, "/user/alice/did.json"))
+
+		// it is a not found resolution error, as with every resolver
+		var didResolutionErr *ResolutionError
+		assert.True(tt, errors.As(err, &didResolutionErr))
+		assert.Equal(tt, NotFoundErrorCode, didResolutionErr.Code)
+		assert.True(tt, didResolutionErr.NotFound)
+	})
+
+	t.Run("gone is deactivated", func(tt *testing.T) {
+		server, didWeb := newTestServer(tt, func(w http.ResponseWriter, _ *http.Request) {
+			w.WriteHeader(http.StatusGone)
+		})
+
+		resolver, err := NewWebResolver(WithHTTPClient(server.Client()))
+		assert.NoError(tt, err)
+		resolved, err := resolver.Resolve(context.Background(), didWeb)
+		assert.NoError(tt, err)
+		assert.True(tt, resolved.IsDeactivated())
+		assert.Equal(tt, didWeb, resolved.Document.ID)
+	})
+
+	t.Run("rejects an oversized document", func(tt *testing.T) {
+		var didWeb string
+		server, didWeb := newTestServer(tt, func(w http.ResponseWriter, _ *http.Request) {
+			_, _ = w.Write([]byte(`{"id": "` + didWeb + `", "padding": "`))
+			_, _ = w.Write([]byte(strings.Repeat("a", webMaxDocSize)))
+			_, _ = w.Write([]byte(`"}`))
+		})
+
+		resolver, err := NewWebResolver(WithHTTPClient(server.Client()))
+		assert.NoError(tt, err)
+		_, err = resolver.Resolve(context.Background(), didWeb)
+		assert.ErrorContains(tt, err, "document exceeds the maximum size of 1048576 bytes")
+
+		var resolutionErr *WebResolutionError
+		assert.True(tt, errors.As(err, &resolutionErr))
+		assert.Equal(tt, InternalErrorCode, resolutionErr.Code)
+	})
+
+	t.Run("timeout is a transport failure", func(tt *testing.T) {
+		server, didWeb := newTestServer(tt, func(w http.ResponseWriter, r *http.Request) {
+			select {
+			case <-r.Context().Done():
+			case <-time.After(time.Second):
+			}
+		})
+
+		resolver, err := NewWebResolver(WithHTTPClient(server.Client()), WithTimeout(50*time.Millisecond))
+		assert.NoError(tt, err)
+		_, err = resolver.Resolve(context.Background(), didWeb)
+		assert.Error(tt, err)
+
+		var resolutionErr *WebResolutionError
+		assert.True(tt, errors.As(err, &resolutionErr))
+		assert.False(tt, resolutionErr.IsNotFound())
+		assert.Equal(tt, InternalErrorCode, resolutionErr.Code)
+		assert.ErrorIs(tt, err, context.DeadlineExceeded)
+	})
+
+	t.Run("nil client", func(tt *testing.T) {
+		_, err := NewWebResolver(WithHTTPClient(nil))
+		assert.Error(tt, err)
+		assert.Contains(tt, err.Error(), "client cannot be nil")
+	})
+}
+
+func TestWebResolverHostRestrictions(t *testing.T) {
+	// newCountingServer serves a DID Document for the did:web DID of the given host at the server's port, counting the
+	// requests it receives
+	newCountingServer := func(t *testing.T, host string) (*httptest.Server, string, *atomic.Int32) {
+		var hits atomic.Int32
+		var didWeb string
+		server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+			hits.Add(1)
+			_, _ = w.Write([]byte(`{"id": "` + didWeb + `"}`))
+		}))
+		t.Cleanup(server.Close)
+		serverURL, err := url.Parse(server.URL)
+		require.NoError(t, err)
+		if host == "" {
+			host = serverURL.Hostname()
+		}
+		didWeb = "did:web:" + url.QueryEscape(net.JoinHostPort(host, serverURL.Port()))
+		return server, didWeb, &hits
+	}
+	// withLookupIPAddr stubs DNS resolution, resolving every host to the given address
+	withLookupIPAddr := func(ip string) WebResolverOption {
+		return func(r *WebResolver) {
+			r.lookupIPAddr = func(context.Context, string) ([]net.IPAddr, error) {
+				return []net.IPAddr{{IP: net.ParseIP(ip)}}, nil
+			}
+		}
+	}
+
+	t.Run("private IPs are allowed by default", func(tt *testing.T) {
+		server, didWeb, hits := newCountingServer(tt, "")
+		resolver, err := NewWebResolver(WithHTTPClient(server.Client()))
+		require.NoError(tt, err)
+		_, err = resolver.Resolve(context.Background(), didWeb)
+		assert.NoError(tt, err)
+		assert.EqualValues(tt, 1, hits.Load())
+	})
+
+	t.Run("deny private IPs blocks loopback", func(tt *testing.T) {
+		server, didWeb, hits := newCountingServer(tt, "")
+		resolver, err := NewWebResolver(WithHTTPClient(server.Client()), WithDenyPrivateIPs())
+		require.NoError(tt, err)
+		_, err = resolver.Resolve(context.Background(), didWeb)
+		assert.ErrorIs(tt, err, ErrHostNotPermitted)
+		assert.Contains(tt, err.Error(), "resolves to private address<127.0.0.1>")
+		assert.Zero(tt, hits.Load())
+	})
+
+	t.Run("deny private IPs checks the resolved address of a host", func(tt *testing.T) {
+		server, didWeb, hits := newCountingServer(tt, "did.example.com")
+		resolver, err := NewWebResolver(WithHTTPClient(server.Client()), WithDenyPrivateIPs(), withLookupIPAddr("127.0.0.1"))
+		require.NoError(tt, err)
+		_, err = resolver.Resolve(context.Background(), didWeb)
+		assert.ErrorIs(tt, err, ErrHostNotPermitted)
+		assert.Contains(tt, err.Error(), "host<did.example.com> resolves to private address<127.0.0.1>")
+		assert.Zero(tt, hits.Load())
+	})
+
+	t.Run("host allowlist", func(tt *testing.T) {
+		server, didWeb, hits := newCountingServer(tt, "")
+		resolver, err := NewWebResolver(WithHTTPClient(server.Client()), WithHostAllowlist("127.0.0.1"))
+		require.NoError(tt, err)
+		_, err = resolver.Resolve(context.Background(), didWeb)
+		assert.NoError(tt, err)
+		assert.EqualValues(tt, 1, hits.Load())
+
+		resolver, err = NewWebResolver(WithHTTPClient(server.Client()), WithHostAllowlist("example.com"))
+		require.NoError(tt, err)
+		_, err = resolver.Resolve(context.Background(), didWeb)
+		assert.ErrorIs(tt, err, ErrHostNotPermitted)
+		assert.Contains(tt, err.Error(), "host<127.0.0.1> is not allowed")
+		assert.EqualValues(tt, 1, hits.Load())
+	})
+
+	t.Run("redirect to a host that is not allowed", func(tt *testing.T) {
+		server, _, hits := newCountingServer(tt, "")
+		redirect := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			http.Redirect(w, r, server.URL+r.URL.Path, http.StatusFound)
+		}))
+		tt.Cleanup(redirect.Close)
+		redirectURL, err := url.Parse(redirect.URL)
+		require.NoError(tt, err)
+		redirectDID := "did:web:example.com%3A" + redirectURL.Port()
+
+		// route example.com to the redirecting server, beneath the resolver's host checks
+		client := redirect.Client()
+		transport := client.Transport.(*http.Transport).Clone()
+		transport.DialContext = func(ctx context.Context, network, addr string) (net.Conn, error) {
+			return (&net.Dialer{}).DialContext(ctx, network, strings.Replace(addr, "example.com", redirectURL.Hostname(), 1))
+		}
+		client.Transport = transport
+
+		resolver, err := NewWebResolver(WithHTTPClient(client), WithHostAllowlist("example.com"))
+		require.NoError(tt, err)
+		_, err = resolver.Resolve(context.Background(), redirectDID)
+		assert.ErrorIs(tt, err, ErrHostNotPermitted)
+		assert.Contains(tt, err.Error(), "host<127.0.0.1> is not allowed")
+		assert.Zero(tt, hits.Load())
+	})
+
+	t.Run("private addresses", func(tt *testing.T) {
+		for _, ip := range []string{"127.0.0.1", "10.1.2.3", "172.16.0.1", "192.168.1.1", "169.254.169.254", "0.0.0.0", "::1", "fe80::1", "fd00::1", "::ffff:127.0.0.1"} {
+			assert.True(tt, isPrivateIP(net.ParseIP(ip)), ip)
+		}
+		for _, ip := range []string{"8.8.8.8", "172.32.0.1", "2606:4700:4700::1111"} {
+			assert.False(tt, isPrivateIP(net.ParseIP(ip)), ip)
+		}
+	})
+}
+
+func TestWebResolverCache(t *testing.T) {
+	// newCountingServer serves a DID Document for the returned did:web DID, counting the requests it receives
+	newCountingServer := func(t *testing.T, release <-chan struct{}) (*httptest.Server, string, *atomic.Int32) {
+		var hits atomic.Int32
+		var didWeb string
+		server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+			hits.Add(1)
+			if release != nil {
+				<-release
+			}
+			_, _ = w.Write([]byte(`{"id": "` + didWeb + `"}`))
+		}))
+		t.Cleanup(server.Close)
+		serverURL, err := url.Parse(server.URL)
+		assert.NoError(t, err)
+		didWeb = "did:web:" + url.QueryEscape(serverURL.Host)
+		return server, didWeb, &hits
+	}
+
+	t.Run("disabled by default", func(tt *testing.T) {
+		server, didWeb, hits := newCountingServer(tt, nil)
+		resolver, err := NewWebResolver(WithHTTPClient(server.Client()))
+		assert.NoError(tt, err)
+
+		for i := 0; i < 3; i++ {
+			_, err = resolver.Resolve(context.Background(), didWeb)
+			assert.NoError(tt, err)
+		}
+		assert.Equal(tt, int32(3), hits.Load())
+	})
+
+	t.Run("caches until the ttl expires", func(tt *testing.T) {
+		server, didWeb, hits := newCountingServer(tt, nil)
+		resolver, err := NewWebResolver(WithHTTPClient(server.Client()), WithCacheTTL(100*time.Millisecond))
+		assert.NoError(tt, err)
+
+		for i := 0; i < 3; i++ {
+			resolved, err := resolver.Resolve(context.Background(), didWeb)
+			assert.NoError(tt, err)
+			assert.Equal(tt, didWeb, resolved.Document.ID)
+		}
+		assert.Equal(tt, int32(1), hits.Load())
+
+		time.Sleep(150 * time.Millisecond)
+		_, err = resolver.Resolve(context.Background(), didWeb)
+		assert.NoError(tt, err)
+		assert.Equal(tt, int32(2), hits.Load())
+	})
+
+	t.Run("purge forces a fetch", func(tt *testing.T) {
+		server, didWeb, hits := newCountingServer(tt, nil)
+		resolver, err := NewWebResolver(WithHTTPClient(server.Client()), WithCacheTTL(time.Hour))
+		assert.NoError(tt, err)
+
+		_, err = resolver.Resolve(context.Background(), didWeb)
+		assert.NoError(tt, err)
+		resolver.Purge(didWeb)
+		_, err = resolver.Resolve(context.Background(), didWeb)
+		assert.NoError(tt, err)
+		assert.Equal(tt, int32(2), hits.Load())
+	})
+
+	t.Run("concurrent resolutions make one request", func(tt *testing.T) {
+		release := make(chan struct{})
+		server, didWeb, hits := newCountingServer(tt, release)
+		resolver, err := NewWebResolver(WithHTTPClient(server.Client()), WithCacheTTL(time.Hour))
+		assert.NoError(tt, err)
+
+		var wg sync.WaitGroup
+		for i := 0; i < 10; i++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				resolved, err := resolver.Resolve(context.Background(), didWeb)
+				assert.NoError(tt, err)
+				assert.Equal(tt, didWeb, resolved.Document.ID)
+			}()
+		}
+
+		// wait for the first request to arrive, giving the others time to join it, before responding
+		assert.Eventually(tt, func() bool { return hits.Load() == 1 }, time.Second, time.Millisecond)
+		time.Sleep(50 * time.Millisecond)
+		close(release)
+		wg.Wait()
+		assert.Equal(tt, int32(1), hits.Load())
+	})
+
+	t.Run("a caller whose context is done stops waiting without failing the others", func(tt *testing.T) {
+		release := make(chan struct{})
+		server, didWeb, hits := newCountingServer(tt, release)
+		resolver, err := NewWebResolver(WithHTTPClient(server.Client()), WithCacheTTL(time.Hour))
+		assert.NoError(tt, err)
+
+		// the first caller starts the fetch, and gives up before it completes
+		ctx, cancel := context.WithCancel(context.Background())
+		firstDone := make(chan error)
+		go func() {
+			_, err := resolver.Resolve(ctx, didWeb)
+			firstDone <- err
+		}()
+		assert.Eventually(tt, func() bool { return hits.Load() == 1 }, time.Second, time.Millisecond)
+
+		secondDone := make(chan error)
+		go func() {
+			_, err := resolver.Resolve(context.Background(), didWeb)
+			secondDone <- err
+		}()
+
+		cancel()
+		assert.ErrorIs(tt, <-firstDone, context.Canceled)
+
+		close(release)
+		assert.NoError(tt, <-secondDone)
+		assert.Equal(tt, int32(1), hits.Load())
+	})
+
+	t.Run("callers cannot modify the cached document", func(tt *testing.T) {
+		server, didWeb, hits := newCountingServer(tt, nil)
+		resolver, err := NewWebResolver(WithHTTPClient(server.Client()), WithCacheTTL(time.Hour))
+		assert.NoError(tt, err)
+
+		resolved, err := resolver.Resolve(context.Background(), didWeb)
+		assert.NoError(tt, err)
+		resolved.Document.ID = "did:example:modified"
+		resolved.Document.Controller = "did:example:modified"
+
+		resolved, err = resolver.Resolve(context.Background(), didWeb)
+		assert.NoError(tt, err)
+		assert.Equal(tt, didWeb, resolved.Document.ID)
+		assert.Empty(tt, resolved.Document.Controller)
+		assert.Equal(tt, int32(1), hits.Load())
+	})
+
+	t.Run("failures are not cached", func(tt *testing.T) {
+		server := httptest.NewTLSServer(http.HandlerFunc(http.NotFound))
+		tt.Cleanup(server.Close)
+		serverURL, err := url.Parse(server.URL)
+		assert.NoError(tt, err)
+		didWeb := "did:web:" + url.QueryEscape(serverURL.Host)
+
+		resolver, err := NewWebResolver(WithHTTPClient(server.Client()), WithCacheTTL(time.Hour))
+		assert.NoError(tt, err)
+		_, err = resolver.Resolve(context.Background(), didWeb)
+		assert.Error(tt, err)
+		assert.Empty(tt, resolver.cache.entries)
+	})
+}
@@ -0,0 +1,24 @@
+package util
+
+import "time"
+
+// Clock provides the current time to time-based validation, such as of the validity period of a credential or the
+// exp and nbf claims of a JWT, so that validation may be performed as of another time. The created time of a data
+// integrity proof is not validated against any clock, so is unaffected.
+type Clock interface {
+	Now() time.Time
+}
+
+// RealClock is a Clock reporting the current time, used wherever no other Clock is given
+type RealClock struct{}
+
+func (RealClock) Now() time.Time {
+	return time.Now()
+}
+
+// FixedClock is a Clock frozen at a point in time, such as to verify a credential as of a time in its past
+type FixedClock time.Time
+
+func (c FixedClock) Now() time.Time {
+	return time.Time(c)
+}
@@ -30,7 +30,19 @@ func IsValidStruct(data any) error {
 	return NewValidator().Struct(data)
 }
 
-func NewLDProcessor() LDProcessor {
+// LDOption configures the JSON-LD processing run by the LD helpers
+type LDOption func(*ld.JsonLdOptions)
+
+// WithLDDocumentLoader sets the loader used to retrieve remote contexts, in place of the default caching loader
+func WithLDDocumentLoader(loader ld.DocumentLoader) LDOption {
+	return func(o *ld.JsonLdOptions) {
+		if loader != nil {
+			o.DocumentLoader = loader
+		}
+	}
+}
+
+func NewLDProcessor(opts ...LDOption) LDProcessor {
 	// JSON LD processing
 	proc := ld.NewJsonLdProcessor()
 	// Initialize a new doc loader with caching capability
@@ -42,6 +54,9 @@ func NewLDProcessor() LDProcessor {
 	options.ProcessingMode = ld.JsonLd_1_1
 	options.ProduceGeneralizedRdf = true
 	options.DocumentLoader = docLoader
+	for _, opt := range opts {
+		opt(options)
+	}
 	return LDProcessor{
 		JsonLdProcessor: proc,
 		JsonLdOptions:   options,
@@ -68,13 +83,13 @@ func (l LDProcessor) GetContextFromMap(dataMap map[string]any) (*ld.Context, err
 	return activeCtx, nil
 }
 
-func LDNormalize(document any) (any, error) {
-	processor := NewLDProcessor()
+func LDNormalize(document any, opts ...LDOption) (any, error) {
+	processor := NewLDProcessor(opts...)
 	return processor.Normalize(document, processor.GetOptions())
 }
 
 // LDFrame runs https://www.w3.org/TR/json-ld11-framing/ to transform the data in a document according to its frame
-func LDFrame(document any, frame any) (any, error) {
+func LDFrame(document any, frame any, opts ...LDOption) (any, error) {
 	docAny := document
 	var err error
 	if _, ok := document.(map[string]any); !ok {
@@ -91,15 +106,15 @@ func LDFrame(document any, frame any) (any, error) {
 			return nil, err
 		}
 	}
-	docLoader := ld.NewRFC7324CachingDocumentLoader(nil)
+	docLoader := NewLDProcessor(opts...).DocumentLoader
 	// use the aries processor for special framing logic necessary for blank nodes
 	return jsonld.Default().Frame(docAny.(map[string]any),
 		frameAny.(map[string]any), jsonld.WithDocumentLoader(docLoader), jsonld.WithFrameBlankNodes())
 }
 
 // LDCompact runs https://www.w3.org/TR/json-ld-api/#compaction-algorithms which shortens IRIs in the document
-func LDCompact(document any, context string) (map[string]any, error) {
-	processor := NewLDProcessor()
+func LDCompact(document any, context string, opts ...LDOption) (map[string]any, error) {
+	processor := NewLDProcessor(opts...)
 	contextsMap := map[string]any{
 		"@context": context,
 	}
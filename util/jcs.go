@@ -0,0 +1,139 @@
+package util
+
+import (
+	"bytes"
+	"fmt"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode/utf16"
+
+	"github.com/pkg/errors"
+)
+
+// CanonicalJSON serializes the data as JSON according to the JSON Canonicalization Scheme (JCS), so that data with
+// the same contents always serializes to the same bytes, regardless of the order of its object members
+// https://www.rfc-editor.org/rfc/rfc8785
+func CanonicalJSON(data any) ([]byte, error) {
+	generic, err := AnyToJSONInterface(data)
+	if err != nil {
+		return nil, errors.Wrap(err, "converting data to JSON")
+	}
+	var buf bytes.Buffer
+	if err = writeCanonicalJSON(&buf, generic); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+func writeCanonicalJSON(buf *bytes.Buffer, value any) error {
+	switch v := value.(type) {
+	case nil:
+		buf.WriteString("null")
+	case bool:
+		buf.WriteString(strconv.FormatBool(v))
+	case float64:
+		number, err := canonicalNumber(v)
+		if err != nil {
+			return err
+		}
+		buf.WriteString(number)
+	case string:
+		writeCanonicalString(buf, v)
+	case []any:
+		buf.WriteByte('[')
+		for i, element := range v {
+			if i > 0 {
+				buf.WriteByte(',')
+			}
+			if err := writeCanonicalJSON(buf, element); err != nil {
+				return err
+			}
+		}
+		buf.WriteByte(']')
+	case map[string]any:
+		// members are sorted by the UTF-16 code units of their names
+		names := make([]string, 0, len(v))
+		for name := range v {
+			names = append(names, name)
+		}
+		sort.Slice(names, func(i, j int) bool {
+			return lessUTF16(names[i], names[j])
+		})
+		buf.WriteByte('{')
+		for i, name := range names {
+			if i > 0 {
+				buf.WriteByte(',')
+			}
+			writeCanonicalString(buf, name)
+			buf.WriteByte(':')
+			if err := writeCanonicalJSON(buf, v[name]); err != nil {
+				return err
+			}
+		}
+		buf.WriteByte('}')
+	default:
+		return fmt.Errorf("unsupported JSON value type: %T", value)
+	}
+	return nil
+}
+
+// canonicalNumber serializes a number as ECMAScript does https://www.rfc-editor.org/rfc/rfc8785#section-3.2.2.3
+func canonicalNumber(f float64) (string, error) {
+	if math.IsNaN(f) || math.IsInf(f, 0) {
+		return "", fmt.Errorf("number cannot be represented in JSON: %v", f)
+	}
+	if f == 0 {
+		// negative zero is serialized as zero
+		return "0", nil
+	}
+	if abs := math.Abs(f); abs >= 1e21 || abs < 1e-6 {
+		// Go pads the exponent to two digits, which ECMAScript does not
+		mantissa, exponent, _ := strings.Cut(strconv.FormatFloat(f, 'e', -1, 64), "e")
+		return mantissa + "e" + exponent[:1] + strings.TrimLeft(exponent[1:], "0"), nil
+	}
+	return strconv.FormatFloat(f, 'f', -1, 64), nil
+}
+
+// writeCanonicalString escapes only the characters JSON requires to be escaped, using the short escapes where they
+// exist https://www.rfc-editor.org/rfc/rfc8785#section-3.2.2.2
+func writeCanonicalString(buf *bytes.Buffer, s string) {
+	buf.WriteByte('"')
+	for _, r := range s {
+		switch r {
+		case '"':
+			buf.WriteString(`\"`)
+		case '\\':
+			buf.WriteString(`\\`)
+		case '\b':
+			buf.WriteString(`\b`)
+		case '\f':
+			buf.WriteString(`\f`)
+		case '\n':
+			buf.WriteString(`\n`)
+		case '\r':
+			buf.WriteString(`\r`)
+		case '\t':
+			buf.WriteString(`\t`)
+		default:
+			if r < 0x20 {
+				fmt.Fprintf(buf, `\u%04x`, r)
+			} else {
+				buf.WriteRune(r)
+			}
+		}
+	}
+	buf.WriteByte('"')
+}
+
+// lessUTF16 compares two strings by their UTF-16 code units
+func lessUTF16(a, b string) bool {
+	aUnits, bUnits := utf16.Encode([]rune(a)), utf16.Encode([]rune(b))
+	for i := 0; i < len(aUnits) && i < len(bUnits); i++ {
+		if aUnits[i] != bUnits[i] {
+			return aUnits[i] < bUnits[i]
+		}
+	}
+	return len(aUnits) < len(bUnits)
+}
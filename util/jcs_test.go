@@ -0,0 +1,68 @@
+package util
+
+import (
+	"math"
+	"testing"
+
+	"github.com/goccy/go-json"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCanonicalJSON(t *testing.T) {
+	t.Run("example from the specification", func(tt *testing.T) {
+		// https://www.rfc-editor.org/rfc/rfc8785#section-3.2.4
+		input := `{
+			"numbers": [333333333.33333329, 1E30, 4.50, 2e-3, 0.000000000000000000000000001],
+			"string": "\u20ac$\u000F\u000aA'\u0042\u0022\u005c\\\"\/",
+			"literals": [null, true, false]
+		}`
+		canonical, err := CanonicalJSON(json.RawMessage(input))
+		assert.NoError(tt, err)
+		expected := `{"literals":[null,true,false],"numbers":[333333333.3333333,1e+30,4.5,0.002,1e-27],"string":"€$\u000f\nA'B\"\\\\\"/"}`
+		assert.Equal(tt, expected, string(canonical))
+	})
+
+	t.Run("members are sorted by UTF-16 code units", func(tt *testing.T) {
+		// https://www.rfc-editor.org/rfc/rfc8785#section-3.2.3
+		input := `{"€": "Euro Sign", "\r": "Carriage Return", "דּ": "Hebrew Letter Dalet With Dagesh",
+			"1": "One", "😀": "Emoji: Grinning Face", "\u0080": "Control", "ö": "Latin Small Letter O With Diaeresis"}`
+		canonical, err := CanonicalJSON(json.RawMessage(input))
+		assert.NoError(tt, err)
+		expected := `{"\r":"Carriage Return","1":"One","` + "\u0080" + `":"Control","ö":"Latin Small Letter O With Diaeresis",` +
+			`"€":"Euro Sign","😀":"Emoji: Grinning Face","דּ":"Hebrew Letter Dalet With Dagesh"}`
+		assert.Equal(tt, expected, string(canonical))
+	})
+
+	t.Run("member order does not matter", func(tt *testing.T) {
+		a, err := CanonicalJSON(map[string]any{"b": []any{1, "two"}, "a": map[string]any{"y": true, "x": nil}})
+		assert.NoError(tt, err)
+		b, err := CanonicalJSON(json.RawMessage(`{"a": {"x": null, "y": true}, "b": [1, "two"]}`))
+		assert.NoError(tt, err)
+		assert.Equal(tt, `{"a":{"x":null,"y":true},"b":[1,"two"]}`, string(a))
+		assert.Equal(tt, a, b)
+	})
+
+	t.Run("numbers", func(tt *testing.T) {
+		for number, expected := range map[float64]string{
+			0:                       "0",
+			math.Copysign(0, -1):    "0",
+			1:                       "1",
+			-1.5:                    "-1.5",
+			1000000:                 "1000000",
+			1e20:                    "100000000000000000000",
+			1e21:                    "1e+21",
+			0.000001:                "0.000001",
+			0.0000001:               "1e-7",
+			-1.7976931348623157e308: "-1.7976931348623157e+308",
+		} {
+			canonical, err := CanonicalJSON(number)
+			assert.NoError(tt, err)
+			assert.Equal(tt, expected, string(canonical))
+		}
+
+		_, err := canonicalNumber(math.NaN())
+		assert.Error(tt, err)
+		_, err = canonicalNumber(math.Inf(1))
+		assert.Error(tt, err)
+	})
+}